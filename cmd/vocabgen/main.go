@@ -0,0 +1,297 @@
+// Command vocabgen reads an RDFS/OWL ontology written in Turtle and writes a
+// Go package of rdf.IRI constants for the terms it defines, so code can use
+// foaf.Knows rather than typing out the IRI.
+//
+// It is meant to be run from go:generate:
+//
+//	//go:generate go run github.com/b1scuit/solid/cmd/vocabgen -in ontologies/foaf.ttl -out foaf/foaf.go -package foaf -namespace http://xmlns.com/foaf/0.1/
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/parser"
+)
+
+const (
+	rdfsLabel   rdf.IRI = "http://www.w3.org/2000/01/rdf-schema#label"
+	rdfsComment rdf.IRI = "http://www.w3.org/2000/01/rdf-schema#comment"
+	rdfsClass   rdf.IRI = "http://www.w3.org/2000/01/rdf-schema#Class"
+	owlClass    rdf.IRI = "http://www.w3.org/2002/07/owl#Class"
+	rdfsDt      rdf.IRI = "http://www.w3.org/2000/01/rdf-schema#Datatype"
+)
+
+func main() {
+	var in, out, pkg, namespace, title string
+
+	flag.StringVar(&in, "in", "", "Turtle file containing the ontology")
+	flag.StringVar(&out, "out", "", "Go file to write, stdout if empty")
+	flag.StringVar(&pkg, "package", "", "Name of the generated package")
+	flag.StringVar(&namespace, "namespace", "", "Namespace IRI of the terms to generate, defaults to the prefix named after the package")
+	flag.StringVar(&title, "title", "", "Name of the vocabulary used in the package comment")
+	flag.Parse()
+
+	if in == "" || pkg == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	l := slog.With(slog.String("file name", in))
+
+	g, err := loadOntology(in)
+	if err != nil {
+		l.Error("Error parsing ontology", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	if namespace == "" {
+		ns, ok := g.Prefixes[pkg]
+		if !ok {
+			l.Error("No namespace given and no prefix named after the package", slog.String("package", pkg))
+			os.Exit(1)
+		}
+
+		namespace = string(ns)
+	}
+
+	if title == "" {
+		title = pkg
+	}
+
+	src, err := generate(g, pkg, rdf.IRI(namespace), title, filepath.Base(in))
+	if err != nil {
+		l.Error("Error generating code", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	if out == "" {
+		os.Stdout.Write(src)
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(out), 0o755); err != nil {
+		l.Error("Error creating output directory", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	if err := os.WriteFile(out, src, 0o644); err != nil {
+		l.Error("Error writing output", slog.Any("error", err))
+		os.Exit(1)
+	}
+}
+
+func loadOntology(fileName string) (*rdf.Graph, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	p, err := parser.New()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.Do(file); err != nil {
+		return nil, err
+	}
+
+	return p.GetGraph(), nil
+}
+
+type term struct {
+	iri   rdf.IRI
+	local string
+	name  string
+	doc   string
+}
+
+// generate writes the Go source for every IRI subject in the namespace
+func generate(g *rdf.Graph, pkg string, namespace rdf.IRI, title, source string) ([]byte, error) {
+	terms := collectTerms(g, namespace)
+
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "// Code generated by vocabgen from %v. DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(&b, "// Package %v holds the IRIs of the %v vocabulary, %v\n", pkg, title, string(namespace))
+	fmt.Fprintf(&b, "package %v\n\n", pkg)
+	fmt.Fprintf(&b, "import \"github.com/b1scuit/solid/rdf\"\n\n")
+	fmt.Fprintf(&b, "// Namespace is the IRI every term in the vocabulary starts with\n")
+	fmt.Fprintf(&b, "const Namespace rdf.IRI = %q\n\n", string(namespace))
+	fmt.Fprintf(&b, "// Term returns the IRI of a term in the vocabulary by its local name\n")
+	fmt.Fprintf(&b, "func Term(local string) rdf.IRI {\n\treturn Namespace + rdf.IRI(local)\n}\n\n")
+	fmt.Fprintf(&b, "const (\n")
+
+	for i, t := range terms {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		for _, line := range wrap(t.doc, 72) {
+			fmt.Fprintf(&b, "\t// %v\n", line)
+		}
+
+		fmt.Fprintf(&b, "\t%v rdf.IRI = %q\n", t.name, string(t.iri))
+	}
+
+	b.WriteString(")\n")
+
+	return format.Source(b.Bytes())
+}
+
+func collectTerms(g *rdf.Graph, namespace rdf.IRI) []term {
+	seen := make(map[rdf.IRI]bool)
+	var terms []term
+
+	for _, t := range g.Triples() {
+		iri, ok := t.Subject.(rdf.IRI)
+		if !ok || seen[iri] || !strings.HasPrefix(string(iri), string(namespace)) || iri == namespace {
+			continue
+		}
+
+		seen[iri] = true
+		local := string(iri[len(namespace):])
+
+		if strings.ContainsAny(local, "/#?") {
+			continue
+		}
+
+		terms = append(terms, term{iri: iri, local: local})
+	}
+
+	// Classes and properties can differ only by case, like schema:Brand and
+	// schema:brand, the lower case term gets a suffix describing what it is
+	names := make(map[string]int)
+	for _, t := range terms {
+		names[identifier(t.local)]++
+	}
+
+	for i := range terms {
+		t := &terms[i]
+		t.name = identifier(t.local)
+
+		if names[t.name] > 1 && !unicode.IsUpper([]rune(t.local)[0]) {
+			if isClass(g, t.iri) {
+				t.name += "Class"
+			} else {
+				t.name += "Property"
+			}
+		}
+
+		t.doc = documentation(g, t)
+	}
+
+	sort.Slice(terms, func(i, j int) bool { return terms[i].name < terms[j].name })
+
+	return terms
+}
+
+func isClass(g *rdf.Graph, iri rdf.IRI) bool {
+	for _, class := range []rdf.IRI{rdfsClass, owlClass, rdfsDt} {
+		if g.Has(rdf.NewTriple(iri, rdf.RDFType, class)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func documentation(g *rdf.Graph, t *term) string {
+	// The identifier only needs explaining when it isn't the local name
+	doc := t.name
+	if t.name != t.local {
+		doc += " is " + t.local
+	}
+
+	if label := englishText(g, t.iri, rdfsLabel); label != "" && !strings.EqualFold(label, t.local) {
+		doc += " (" + label + ")"
+	}
+
+	if comment := englishText(g, t.iri, rdfsComment); comment != "" {
+		comment = strings.Join(strings.Fields(comment), " ")
+		doc += ": " + comment
+	}
+
+	return doc
+}
+
+// englishText picks the English or untagged value of a property
+func englishText(g *rdf.Graph, subject rdf.Term, predicate rdf.IRI) string {
+	var fallback string
+
+	for _, t := range g.Match(subject, predicate, nil) {
+		l, ok := t.Object.(rdf.Literal)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case l.Language == "en" || strings.HasPrefix(l.Language, "en-"):
+			return l.Lexical
+		case l.Language == "" && fallback == "":
+			fallback = l.Lexical
+		}
+	}
+
+	return fallback
+}
+
+// identifier turns a local name into an exported Go identifier, dropping
+// characters Go doesn't allow and capitalising what follows them
+func identifier(local string) string {
+	var b strings.Builder
+	upper := true
+
+	for _, r := range local {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+
+		b.WriteRune(r)
+	}
+
+	name := b.String()
+	if name == "" || unicode.IsDigit([]rune(name)[0]) {
+		name = "T" + name
+	}
+
+	return name
+}
+
+func wrap(s string, width int) []string {
+	var lines []string
+	var line string
+
+	for _, word := range strings.Fields(s) {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = word
+			continue
+		}
+
+		if line != "" {
+			line += " "
+		}
+
+		line += word
+	}
+
+	return append(lines, line)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/b1scuit/solid/rdf"
+)
+
+// prefixFlags collects the prefixes a subcommand can use, the built in
+// table, those declared in a Turtle file and any given with -prefix
+type prefixFlags struct {
+	declared []string
+	file     string
+	base     string
+	noCommon bool
+}
+
+func (p *prefixFlags) register(fs *flag.FlagSet) {
+	fs.Func("prefix", "Declare a prefix as name=IRI, can be repeated", func(s string) error {
+		p.declared = append(p.declared, s)
+		return nil
+	})
+	fs.StringVar(&p.file, "file", "", "Turtle file whose prefix declarations are used")
+	fs.StringVar(&p.base, "base", "", "Base IRI to resolve relative IRIs in the file against")
+	fs.BoolVar(&p.noCommon, "no-common", false, "Don't use the built in table of well known prefixes")
+}
+
+// prefixes builds the map, later sources win over earlier ones
+func (p *prefixFlags) prefixes() (rdf.PrefixMap, error) {
+	m := make(rdf.PrefixMap)
+
+	if !p.noCommon {
+		m = rdf.CommonPrefixes()
+	}
+
+	if p.file != "" {
+		g, err := loadGraph(p.file, p.base)
+		if err != nil {
+			return nil, err
+		}
+
+		m.Merge(g.Prefixes)
+	}
+
+	for _, d := range p.declared {
+		name, iri, ok := strings.Cut(d, "=")
+		if !ok {
+			return nil, fmt.Errorf("prefix %q should be name=IRI", d)
+		}
+
+		if err := m.Set(strings.TrimSuffix(name, ":"), rdf.IRI(strings.Trim(iri, "<>"))); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// runExpand prints the full IRI of each CURIE given
+func runExpand(args []string) int {
+	return runCurie("expand", args, func(m rdf.PrefixMap, arg string) (string, error) {
+		iri, err := m.Expand(arg)
+		return string(iri), err
+	})
+}
+
+// runCompact prints each IRI given as a CURIE where a prefix fits
+func runCompact(args []string) int {
+	return runCurie("compact", args, func(m rdf.PrefixMap, arg string) (string, error) {
+		return m.Compact(rdf.IRI(strings.Trim(arg, "<>"))), nil
+	})
+}
+
+func runCurie(name string, args []string, convert func(rdf.PrefixMap, string) (string, error)) int {
+	var p prefixFlags
+
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	p.register(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: solid %v [-prefix name=IRI] [-file doc.ttl] [-no-common] term...\n", name)
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	m, err := p.prefixes()
+	if err != nil {
+		slog.Error("Error loading prefixes", slog.Any("error", err))
+		return 2
+	}
+
+	code := 0
+
+	for _, arg := range fs.Args() {
+		out, err := convert(m, arg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = 1
+			continue
+		}
+
+		fmt.Println(out)
+	}
+
+	return code
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/parser"
+	"github.com/b1scuit/solid/rdf/serializer"
+)
+
+// runDiff compares two Turtle files and prints the triples that were removed
+// and added going from the first to the second. Like diff(1) it exits with 1
+// when the files differ
+func runDiff(args []string) int {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	base := fs.String("base", "", "Base IRI to resolve relative IRIs against")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: solid diff [-base IRI] old.ttl new.ttl")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+
+	from, err := loadGraph(fs.Arg(0), *base)
+	if err != nil {
+		slog.Error("Error loading graph", slog.String("file name", fs.Arg(0)), slog.Any("error", err))
+		return 2
+	}
+
+	to, err := loadGraph(fs.Arg(1), *base)
+	if err != nil {
+		slog.Error("Error loading graph", slog.String("file name", fs.Arg(1)), slog.Any("error", err))
+		return 2
+	}
+
+	delta := rdf.Diff(from, to)
+	if delta.Empty() {
+		return 0
+	}
+
+	s := serializer.MustNew(
+		serializer.WithPrefixes(from.Prefixes),
+		serializer.WithPrefixes(to.Prefixes),
+	)
+
+	prefixes := s.Prefixes(rdf.NewGraph(append(delta.Removed, delta.Added...)...))
+
+	names := prefixes.Names()
+
+	for _, name := range names {
+		fmt.Printf("@prefix %v: %v .\n", name, prefixes[name].String())
+	}
+
+	for _, t := range delta.Removed {
+		fmt.Println("- " + s.FormatTriple(t))
+	}
+
+	for _, t := range delta.Added {
+		fmt.Println("+ " + s.FormatTriple(t))
+	}
+
+	return 1
+}
+
+// loadGraph parses a Turtle file into a graph
+func loadGraph(fileName, base string) (*rdf.Graph, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	p, err := parser.New(parser.WithBase(base))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.Do(file); err != nil {
+		return nil, err
+	}
+
+	return p.GetGraph(), nil
+}
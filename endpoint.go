@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/sparql"
+	"github.com/b1scuit/solid/rdf/sparql/endpoint"
+)
+
+// runEndpoint serves Turtle files over the SPARQL Protocol and the Graph
+// Store Protocol, each -graph file is loaded as a named graph and the rest
+// are merged into the default graph
+func runEndpoint(args []string) int {
+	var named []string
+
+	fs := flag.NewFlagSet("endpoint", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:3030", "Address to listen on")
+	base := fs.String("base", "", "Base IRI to resolve relative IRIs against")
+	readOnly := fs.Bool("read-only", false, "Turn away updates and graph store writes")
+	fs.Func("graph", "Load a file as a named graph as IRI=file, can be repeated", func(s string) error {
+		named = append(named, s)
+		return nil
+	})
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: solid endpoint [-addr host:port] [-base IRI] [-read-only] [-graph IRI=file] data.ttl...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	ds := rdf.NewDataset(nil)
+	prefixes := make(rdf.PrefixMap)
+
+	for _, fileName := range fs.Args() {
+		g, err := loadGraph(fileName, *base)
+		if err != nil {
+			slog.Error("Error loading graph", slog.String("file name", fileName), slog.Any("error", err))
+			return 2
+		}
+
+		ds.Default.Add(g.Triples()...)
+		prefixes.Merge(g.Prefixes)
+	}
+
+	for _, n := range named {
+		iri, fileName, ok := cutGraphFlag(n)
+		if !ok {
+			slog.Error("Graph should be IRI=file", slog.String("graph", n))
+			return 2
+		}
+
+		g, err := loadGraph(fileName, iri)
+		if err != nil {
+			slog.Error("Error loading graph", slog.String("file name", fileName), slog.Any("error", err))
+			return 2
+		}
+
+		ds.SetGraph(rdf.IRI(iri), g)
+		prefixes.Merge(g.Prefixes)
+	}
+
+	opts := []endpoint.ServerOption{
+		endpoint.WithSparql(sparql.MustNew(sparql.WithBase(*base), sparql.WithPrefixes(prefixes))),
+		endpoint.WithPrefixes(prefixes),
+	}
+
+	if *readOnly {
+		opts = append(opts, endpoint.WithReadOnly())
+	}
+
+	slog.Info("Serving SPARQL endpoint", slog.String("address", *addr), slog.Int("triples", len(ds.Quads())))
+
+	if err := http.ListenAndServe(*addr, endpoint.MustNew(ds, opts...)); err != nil {
+		slog.Error("Error serving", slog.Any("error", err))
+		return 1
+	}
+
+	return 0
+}
+
+// cutGraphFlag splits IRI=file on the last =, as IRIs can have their own
+func cutGraphFlag(s string) (string, string, bool) {
+	i := strings.LastIndex(s, "=")
+
+	return s[:max(i, 0)], s[i+1:], i > 0 && i < len(s)-1
+}
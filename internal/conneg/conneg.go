@@ -0,0 +1,81 @@
+// Package conneg picks the media type a response is written in from the
+// request's Accept header
+//
+// https://www.rfc-editor.org/rfc/rfc9110#name-accept
+package conneg
+
+import (
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Negotiate picks the offer the Accept header likes most, earlier offers
+// win ties. An empty header accepts the first offer and "" means nothing
+// offered is acceptable
+func Negotiate(accept string, offers []string) string {
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+
+	type choice struct {
+		offer       string
+		q           float64
+		specificity int
+	}
+
+	var choices []choice
+
+	for _, offer := range offers {
+		best := choice{offer: offer}
+
+		for _, part := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+			if err != nil {
+				continue
+			}
+
+			q := 1.0
+			if v, ok := params["q"]; ok {
+				if q, err = strconv.ParseFloat(v, 64); err != nil {
+					continue
+				}
+			}
+
+			typ, sub, _ := strings.Cut(mediaType, "/")
+			offerType, _, _ := strings.Cut(offer, "/")
+
+			// The most specific range that matches sets the quality
+			var specificity int
+			switch {
+			case mediaType == offer:
+				specificity = 3
+			case typ == offerType && sub == "*":
+				specificity = 2
+			case mediaType == "*/*":
+				specificity = 1
+			default:
+				continue
+			}
+
+			if specificity > best.specificity {
+				best.q, best.specificity = q, specificity
+			}
+		}
+
+		if best.q > 0 {
+			choices = append(choices, best)
+		}
+	}
+
+	if len(choices) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(choices, func(i, j int) bool {
+		return choices[i].q > choices[j].q
+	})
+
+	return choices[0].offer
+}
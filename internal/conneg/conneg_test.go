@@ -0,0 +1,29 @@
+package conneg
+
+import "testing"
+
+type negotiateTest struct {
+	Name     string
+	Accept   string
+	Offers   []string
+	Expected string
+}
+
+var negotiateTests = []negotiateTest{
+	{"No header", "", []string{"text/turtle", "application/ld+json"}, "text/turtle"},
+	{"Exact", "application/ld+json", []string{"text/turtle", "application/ld+json"}, "application/ld+json"},
+	{"Quality", "text/turtle;q=0.5, application/ld+json", []string{"text/turtle", "application/ld+json"}, "application/ld+json"},
+	{"Refused", "application/ld+json;q=0, text/turtle", []string{"application/ld+json", "text/turtle"}, "text/turtle"},
+	{"Most specific range wins", "text/*;q=0, text/turtle", []string{"text/html", "text/turtle"}, "text/turtle"},
+	{"Wildcard", "*/*", []string{"text/turtle", "application/ld+json"}, "text/turtle"},
+	{"Invalid quality skipped", "text/turtle;q=high, application/ld+json;q=0.1", []string{"text/turtle", "application/ld+json"}, "application/ld+json"},
+	{"Nothing acceptable", "image/png", []string{"text/turtle"}, ""},
+}
+
+func TestNegotiate(t *testing.T) {
+	for _, tc := range negotiateTests {
+		if got := Negotiate(tc.Accept, tc.Offers); got != tc.Expected {
+			t.Errorf("%v test fail: got %q, expected %q", tc.Name, got, tc.Expected)
+		}
+	}
+}
@@ -10,7 +10,26 @@ import (
 	"github.com/olekukonko/tablewriter"
 )
 
+// A command is a subcommand of the CLI, given the arguments after its name
+// and returning the exit code
+type command func(args []string) int
+
+var commands = map[string]command{
+	"compact":  runCompact,
+	"diff":     runDiff,
+	"endpoint": runEndpoint,
+	"expand":   runExpand,
+	"query":    runQuery,
+	"serve":    runServe,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			os.Exit(cmd(os.Args[2:]))
+		}
+	}
+
 	slog.Info("Running RDF Parser")
 
 	var fileName string
@@ -43,7 +62,7 @@ func main() {
 	table.SetHeader([]string{"Prefix name", "IRI"})
 
 	for k, v := range p.GetPrefixMap() {
-		table.Append([]string{k, v.Value})
+		table.Append([]string{k, string(v)})
 	}
 
 	table.Render()
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/serializer"
+	"github.com/b1scuit/solid/rdf/sparql"
+)
+
+// runQuery runs a SPARQL query over Turtle files merged into the default
+// graph. SELECT and ASK results are printed as a table or in one of the
+// results formats, CONSTRUCT and DESCRIBE print Turtle
+func runQuery(args []string) int {
+	fs := flag.NewFlagSet("query", flag.ExitOnError)
+	base := fs.String("base", "", "Base IRI to resolve relative IRIs against")
+	query := fs.String("query", "", "The query to run")
+	queryFile := fs.String("query-file", "", "File to read the query from")
+	format := fs.String("format", "table", "Output format, one of table, json, xml, csv or tsv")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: solid query [-base IRI] [-format name] (-query text | -query-file file) data.ttl...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *queryFile != "" {
+		b, err := os.ReadFile(*queryFile)
+		if err != nil {
+			slog.Error("Error reading query", slog.String("file name", *queryFile), slog.Any("error", err))
+			return 2
+		}
+
+		*query = string(b)
+	}
+
+	if *query == "" {
+		fs.Usage()
+		return 2
+	}
+
+	ds := rdf.NewDataset(nil)
+	prefixes := make(rdf.PrefixMap)
+
+	for _, fileName := range fs.Args() {
+		g, err := loadGraph(fileName, *base)
+		if err != nil {
+			slog.Error("Error loading graph", slog.String("file name", fileName), slog.Any("error", err))
+			return 2
+		}
+
+		ds.Default.Add(g.Triples()...)
+		prefixes.Merge(g.Prefixes)
+	}
+
+	c := sparql.MustNew(sparql.WithBase(*base), sparql.WithPrefixes(prefixes))
+
+	q, err := c.Parse(*query)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	r, err := c.Exec(ds, q)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	prefixes.Merge(q.Prefixes)
+
+	if r.Graph != nil {
+		err = serializer.MustNew(serializer.WithPrefixes(prefixes)).Turtle(os.Stdout, r.Graph)
+	} else if *format == "table" {
+		err = sparql.WriteTable(os.Stdout, r, prefixes)
+	} else if f, ok := sparql.LookupResultFormat(*format); ok {
+		err = f.Write(os.Stdout, r)
+	} else {
+		err = fmt.Errorf("unknown format %q", *format)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	return 0
+}
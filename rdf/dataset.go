@@ -0,0 +1,125 @@
+package rdf
+
+import "sort"
+
+// A Quad is a triple along with the graph it belongs to, a nil Graph is
+// the default graph
+type Quad struct {
+	Triple
+	Graph Term
+}
+
+// A Dataset is a default graph and any number of named graphs, named by
+// an IRI or a blank node. It isn't safe for concurrent use, a Memory
+// store is a dataset that is
+type Dataset struct {
+	Default *Graph
+
+	named map[string]*Graph
+	names map[string]Term
+}
+
+// NewDataset creates a dataset using g as the default graph, or an empty
+// graph if g is nil
+func NewDataset(g *Graph) *Dataset {
+	if g == nil {
+		g = NewGraph()
+	}
+
+	return &Dataset{
+		Default: g,
+		named:   make(map[string]*Graph),
+		names:   make(map[string]Term),
+	}
+}
+
+// Graph returns the named graph, or the default graph for a nil name. It
+// returns nil if there is no graph with that name
+func (d *Dataset) Graph(name Term) *Graph {
+	if name == nil {
+		return d.Default
+	}
+
+	return d.named[name.String()]
+}
+
+// HasGraph reports whether a graph with the name exists, even if empty
+func (d *Dataset) HasGraph(name Term) bool {
+	return d.Graph(name) != nil
+}
+
+// CreateGraph returns the named graph, adding an empty one if it doesn't
+// exist yet
+func (d *Dataset) CreateGraph(name Term) *Graph {
+	if g := d.Graph(name); g != nil {
+		return g
+	}
+
+	g := NewGraph()
+	d.SetGraph(name, g)
+
+	return g
+}
+
+// SetGraph adds or replaces a named graph, a nil name replaces the
+// default graph
+func (d *Dataset) SetGraph(name Term, g *Graph) {
+	if name == nil {
+		d.Default = g
+		return
+	}
+
+	d.named[name.String()] = g
+	d.names[name.String()] = name
+}
+
+// RemoveGraph drops a named graph, the default graph is emptied instead
+func (d *Dataset) RemoveGraph(name Term) {
+	if name == nil {
+		d.Default = NewGraph()
+		return
+	}
+
+	delete(d.named, name.String())
+	delete(d.names, name.String())
+}
+
+// Names returns the names of the named graphs in order
+func (d *Dataset) Names() []Term {
+	names := make([]Term, 0, len(d.names))
+	for _, n := range d.names {
+		names = append(names, n)
+	}
+
+	sort.Slice(names, func(i, j int) bool { return names[i].String() < names[j].String() })
+
+	return names
+}
+
+// Quads returns every triple of every graph, default graph first
+func (d *Dataset) Quads() []Quad {
+	var quads []Quad
+
+	for _, t := range d.Default.Triples() {
+		quads = append(quads, Quad{Triple: t})
+	}
+
+	for _, n := range d.Names() {
+		for _, t := range d.Graph(n).Triples() {
+			quads = append(quads, Quad{Triple: t, Graph: n})
+		}
+	}
+
+	return quads
+}
+
+// Clone returns a deep copy of the dataset
+func (d *Dataset) Clone() *Dataset {
+	c := NewDataset(d.Default.Clone())
+
+	for _, n := range d.Names() {
+		c.SetGraph(n, d.Graph(n).Clone())
+	}
+
+	return c
+}
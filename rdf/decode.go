@@ -73,10 +73,10 @@ func (l *lukeFilewalker) ParsePrefix(line string) {
 	prefix := strings.Split(prefixRegex.ReplaceAllString(line, "$1,$2"), ",")
 
 	if l.graph.Prefixes == nil {
-		l.graph.Prefixes = make(map[string]Prefix)
+		l.graph.Prefixes = make(map[string]IRI)
 	}
 
-	l.graph.Prefixes[prefix[0]] = Prefix(prefix[1])
+	l.graph.Prefixes[prefix[0]] = IRI(prefix[1])
 }
 
 type InvalidUnmarshalError struct {
@@ -1,9 +1,222 @@
 package rdf
 
-type Prefix Objector
+import (
+	"fmt"
+	"sort"
+)
 
 // An RDF Graph is a collection of RDF Triples
 type Graph struct {
-	Prefixes map[string]Prefix
-	Triples  map[string]Triple
+	Prefixes PrefixMap
+
+	triples map[string]Triple
+
+	// Each position keeps a term key -> triple keys index so that
+	// pattern matches don't have to scan the whole graph
+	subjects   map[string]map[string]struct{}
+	predicates map[string]map[string]struct{}
+	objects    map[string]map[string]struct{}
+}
+
+func NewGraph(triples ...Triple) *Graph {
+	g := &Graph{}
+	g.Add(triples...)
+
+	return g
+}
+
+func (g *Graph) init() {
+	if g.triples != nil {
+		return
+	}
+
+	g.triples = make(map[string]Triple)
+	g.subjects = make(map[string]map[string]struct{})
+	g.predicates = make(map[string]map[string]struct{})
+	g.objects = make(map[string]map[string]struct{})
+}
+
+// Add inserts triples into the graph, triples already present are ignored
+func (g *Graph) Add(triples ...Triple) {
+	g.init()
+
+	for _, t := range triples {
+		k := t.String()
+		if _, ok := g.triples[k]; ok {
+			continue
+		}
+
+		g.triples[k] = t
+		index(g.subjects, t.Subject.String(), k)
+		index(g.predicates, t.Predicate.String(), k)
+		index(g.objects, t.Object.String(), k)
+	}
+}
+
+// Remove deletes a triple from the graph, returning false if it wasn't there
+func (g *Graph) Remove(t Triple) bool {
+	k := t.String()
+	if _, ok := g.triples[k]; !ok {
+		return false
+	}
+
+	delete(g.triples, k)
+	unindex(g.subjects, t.Subject.String(), k)
+	unindex(g.predicates, t.Predicate.String(), k)
+	unindex(g.objects, t.Object.String(), k)
+
+	return true
+}
+
+func (g *Graph) Has(t Triple) bool {
+	_, ok := g.triples[t.String()]
+	return ok
+}
+
+func (g *Graph) Len() int {
+	return len(g.triples)
+}
+
+// Triples returns every triple in the graph, ordered by their N-Triples form
+func (g *Graph) Triples() []Triple {
+	return g.sorted(g.triples)
+}
+
+// Match returns the triples matching the pattern, nil terms match anything
+func (g *Graph) Match(s, p, o Term) []Triple {
+	var candidates map[string]struct{}
+
+	for _, pos := range []struct {
+		term  Term
+		index map[string]map[string]struct{}
+	}{{s, g.subjects}, {p, g.predicates}, {o, g.objects}} {
+		if pos.term == nil {
+			continue
+		}
+
+		keys := pos.index[pos.term.String()]
+		if len(keys) == 0 {
+			return nil
+		}
+
+		if candidates == nil || len(keys) < len(candidates) {
+			candidates = keys
+		}
+	}
+
+	if candidates == nil {
+		return g.Triples()
+	}
+
+	matched := make(map[string]Triple, len(candidates))
+	for k := range candidates {
+		t := g.triples[k]
+
+		if (s == nil || Equal(s, t.Subject)) &&
+			(p == nil || Equal(p, t.Predicate)) &&
+			(o == nil || Equal(o, t.Object)) {
+			matched[k] = t
+		}
+	}
+
+	return g.sorted(matched)
+}
+
+// Object returns the first object for the subject and predicate, or nil
+func (g *Graph) Object(s, p Term) Term {
+	if m := g.Match(s, p, nil); len(m) > 0 {
+		return m[0].Object
+	}
+
+	return nil
+}
+
+// List reads the members of the RDF collection starting at head, failing
+// on lists that branch, end early or loop back on themselves
+func (g *Graph) List(head Term) ([]Term, error) {
+	var members []Term
+	seen := make(map[string]bool)
+
+	for node := head; !Equal(node, RDFNil); {
+		if node == nil || seen[node.String()] {
+			return nil, fmt.Errorf("rdf: %v is not a well formed list", head)
+		}
+
+		seen[node.String()] = true
+
+		first, rest := g.Match(node, RDFFirst, nil), g.Match(node, RDFRest, nil)
+		if len(first) != 1 || len(rest) != 1 {
+			return nil, fmt.Errorf("rdf: %v is not a well formed list", head)
+		}
+
+		members = append(members, first[0].Object)
+		node = rest[0].Object
+	}
+
+	return members, nil
+}
+
+// Clone returns a copy of the graph that can be modified independently
+func (g *Graph) Clone() *Graph {
+	c := NewGraph(g.Triples()...)
+
+	if g.Prefixes != nil {
+		c.Prefixes = g.Prefixes.Clone()
+	}
+
+	return c
+}
+
+// BlankNodes returns every blank node used in the graph
+func (g *Graph) BlankNodes() []BlankNode {
+	seen := make(map[BlankNode]struct{})
+
+	for _, t := range g.triples {
+		for _, term := range t.Terms() {
+			for _, b := range BlankNodesOf(term) {
+				seen[b] = struct{}{}
+			}
+		}
+	}
+
+	nodes := make([]BlankNode, 0, len(seen))
+	for b := range seen {
+		nodes = append(nodes, b)
+	}
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i] < nodes[j] })
+
+	return nodes
+}
+
+func (g *Graph) sorted(m map[string]Triple) []Triple {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	triples := make([]Triple, len(keys))
+	for i, k := range keys {
+		triples[i] = m[k]
+	}
+
+	return triples
+}
+
+func index(idx map[string]map[string]struct{}, term, key string) {
+	if idx[term] == nil {
+		idx[term] = make(map[string]struct{})
+	}
+
+	idx[term][key] = struct{}{}
+}
+
+func unindex(idx map[string]map[string]struct{}, term, key string) {
+	delete(idx[term], key)
+
+	if len(idx[term]) == 0 {
+		delete(idx, term)
+	}
 }
@@ -0,0 +1,143 @@
+package rdf
+
+import "strings"
+
+// ResolveIRI resolves a reference against a base IRI following RFC 3986
+// section 5.2. It works on the strings directly, rather than going through
+// net/url, so non ASCII characters and empty fragments survive untouched
+func ResolveIRI(base, ref string) IRI {
+	if base == "" || hasScheme(ref) {
+		return IRI(ref)
+	}
+
+	bScheme, bAuthority, bPath, bQuery, _ := splitIRI(base)
+	_, rAuthority, rPath, rQuery, rFragment := splitIRI("x:" + ref)
+
+	hasAuthority := strings.HasPrefix(ref, "//")
+	hasQuery := strings.Contains(strings.SplitN(ref, "#", 2)[0], "?")
+
+	var authority, path, query string
+	withQuery := hasQuery
+
+	switch {
+	case hasAuthority:
+		authority, path, query = rAuthority, removeDotSegments(rPath), rQuery
+	case rPath == "":
+		authority, path = bAuthority, bPath
+
+		query = bQuery
+		if hasQuery {
+			query = rQuery
+		} else {
+			withQuery = strings.Contains(strings.SplitN(base, "#", 2)[0], "?")
+		}
+	default:
+		authority, query = bAuthority, rQuery
+
+		if strings.HasPrefix(rPath, "/") {
+			path = removeDotSegments(rPath)
+		} else {
+			path = removeDotSegments(mergePaths(bAuthority != "" || strings.HasPrefix(base, bScheme+"://"), bPath, rPath))
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(bScheme + ":")
+
+	if hasAuthority || strings.HasPrefix(base, bScheme+"://") {
+		b.WriteString("//" + authority)
+	}
+
+	b.WriteString(path)
+
+	if withQuery {
+		b.WriteString("?" + query)
+	}
+
+	if strings.Contains(ref, "#") {
+		b.WriteString("#" + rFragment)
+	}
+
+	return IRI(b.String())
+}
+
+func hasScheme(s string) bool {
+	for i, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case i > 0 && (c >= '0' && c <= '9' || c == '+' || c == '-' || c == '.'):
+		case i > 0 && c == ':':
+			return true
+		default:
+			return false
+		}
+	}
+
+	return false
+}
+
+// splitIRI breaks an absolute IRI into its components
+func splitIRI(s string) (scheme, authority, path, query, fragment string) {
+	s, fragment, _ = strings.Cut(s, "#")
+	s, query, _ = strings.Cut(s, "?")
+	scheme, s, _ = strings.Cut(s, ":")
+
+	if strings.HasPrefix(s, "//") {
+		s = s[2:]
+
+		if i := strings.Index(s, "/"); i >= 0 {
+			authority, path = s[:i], s[i:]
+		} else {
+			authority = s
+		}
+
+		return
+	}
+
+	return scheme, "", s, query, fragment
+}
+
+func mergePaths(baseHasAuthority bool, basePath, refPath string) string {
+	if baseHasAuthority && basePath == "" {
+		return "/" + refPath
+	}
+
+	if i := strings.LastIndex(basePath, "/"); i >= 0 {
+		return basePath[:i+1] + refPath
+	}
+
+	return refPath
+}
+
+func removeDotSegments(path string) string {
+	var out []string
+	segments := strings.Split(path, "/")
+
+	for i, seg := range segments {
+		last := i == len(segments)-1
+
+		switch seg {
+		case ".":
+			if last {
+				out = append(out, "")
+			}
+		case "..":
+			if len(out) > 1 || len(out) == 1 && out[0] != "" {
+				out = out[:len(out)-1]
+			}
+
+			if last {
+				out = append(out, "")
+			}
+		default:
+			out = append(out, seg)
+		}
+	}
+
+	result := strings.Join(out, "/")
+	if strings.HasPrefix(path, "/") && !strings.HasPrefix(result, "/") {
+		result = "/" + result
+	}
+
+	return result
+}
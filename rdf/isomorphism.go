@@ -0,0 +1,464 @@
+package rdf
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+// Isomorphic reports whether the two graphs are the same once blank node
+// labels are ignored
+func Isomorphic(a, b *Graph) bool {
+	_, ok := BlankNodeMapping(a, b)
+	return ok
+}
+
+// BlankNodeMapping finds a bijection from the blank nodes of a to those of b
+// that makes the graphs equal, if there is one
+//
+// Blank nodes are first coloured by repeatedly hashing their neighbourhood,
+// which in practice leaves very few candidates for each node, then the
+// remaining choices are searched with backtracking
+func BlankNodeMapping(a, b *Graph) (map[BlankNode]BlankNode, bool) {
+	if a.Len() != b.Len() {
+		return nil, false
+	}
+
+	colorsA, colorsB := refineColors(a, b)
+
+	if len(colorsA) != len(colorsB) {
+		return nil, false
+	}
+
+	// Ground triples must match exactly, and every colour has to appear
+	// the same number of times in both graphs
+	for _, t := range a.Triples() {
+		if !hasBlankNode(t) && !b.Has(t) {
+			return nil, false
+		}
+	}
+
+	classes := make(map[uint64][]BlankNode)
+	for n, c := range colorsB {
+		classes[c] = append(classes[c], n)
+	}
+
+	counts := make(map[uint64]int)
+	for _, c := range colorsA {
+		counts[c]++
+	}
+
+	for c, n := range counts {
+		if len(classes[c]) != n {
+			return nil, false
+		}
+	}
+
+	for _, nodes := range classes {
+		sort.Slice(nodes, func(i, j int) bool { return nodes[i] < nodes[j] })
+	}
+
+	// Most constrained nodes first keeps the search shallow
+	order := make([]BlankNode, 0, len(colorsA))
+	for n := range colorsA {
+		order = append(order, n)
+	}
+
+	sort.Slice(order, func(i, j int) bool {
+		ci, cj := counts[colorsA[order[i]]], counts[colorsA[order[j]]]
+		if ci != cj {
+			return ci < cj
+		}
+
+		return order[i] < order[j]
+	})
+
+	s := &isoSearch{
+		a:        a,
+		b:        b,
+		mentions: mentions(a),
+		order:    order,
+		colors:   colorsA,
+		classes:  classes,
+		mapping:  make(map[BlankNode]BlankNode, len(order)),
+		used:     make(map[BlankNode]bool, len(order)),
+	}
+
+	if !s.search(0) {
+		return nil, false
+	}
+
+	return s.mapping, true
+}
+
+type isoSearch struct {
+	a, b     *Graph
+	mentions map[BlankNode][]Triple
+	order    []BlankNode
+	colors   map[BlankNode]uint64
+	classes  map[uint64][]BlankNode
+	mapping  map[BlankNode]BlankNode
+	used     map[BlankNode]bool
+}
+
+func (s *isoSearch) search(i int) bool {
+	if i == len(s.order) {
+		return true
+	}
+
+	n := s.order[i]
+
+	for _, candidate := range s.classes[s.colors[n]] {
+		if s.used[candidate] {
+			continue
+		}
+
+		s.mapping[n] = candidate
+		s.used[candidate] = true
+
+		if s.consistent(n) && s.search(i+1) {
+			return true
+		}
+
+		delete(s.mapping, n)
+		s.used[candidate] = false
+	}
+
+	return false
+}
+
+// consistent checks every triple of n whose blank nodes are all mapped
+// exists in the other graph
+func (s *isoSearch) consistent(n BlankNode) bool {
+	for _, t := range s.mentions[n] {
+		mapped, ok := mapTriple(t, s.mapping)
+		if ok && !s.b.Has(mapped) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// A Delta is the difference between two graphs, blank nodes in both
+// Removed and Added use the labels of the newer graph where they could be
+// aligned
+type Delta struct {
+	Added   []Triple
+	Removed []Triple
+	Mapping map[BlankNode]BlankNode
+}
+
+func (d *Delta) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0
+}
+
+// Diff works out what changed going from graph a to graph b. When the graphs
+// are isomorphic the delta is empty, otherwise blank nodes are paired up by
+// their neighbourhood so that a change to one property of a blank node
+// doesn't show up as the whole node being replaced
+func Diff(a, b *Graph) *Delta {
+	if mapping, ok := BlankNodeMapping(a, b); ok {
+		return &Delta{Mapping: mapping}
+	}
+
+	mapping := alignBlankNodes(a, b)
+
+	// Blank nodes that couldn't be aligned keep their label unless it
+	// is already taken in b
+	taken := make(map[BlankNode]bool)
+	for _, n := range b.BlankNodes() {
+		taken[n] = true
+	}
+	for _, n := range mapping {
+		taken[n] = true
+	}
+
+	for _, n := range a.BlankNodes() {
+		if _, ok := mapping[n]; ok {
+			continue
+		}
+
+		label := n
+		for i := 1; taken[label]; i++ {
+			label = n + BlankNode(strconv.Itoa(i))
+		}
+
+		mapping[n] = label
+		taken[label] = true
+	}
+
+	d := &Delta{Mapping: mapping}
+	mapped := NewGraph()
+
+	for _, t := range a.Triples() {
+		m, _ := mapTriple(t, mapping)
+		mapped.Add(m)
+
+		if !b.Has(m) {
+			d.Removed = append(d.Removed, m)
+		}
+	}
+
+	for _, t := range b.Triples() {
+		if !mapped.Has(t) {
+			d.Added = append(d.Added, t)
+		}
+	}
+
+	return d
+}
+
+// alignBlankNodes pairs blank nodes of a with those of b. Nodes with a
+// unique matching colour are paired first, the rest are paired greedily by
+// how many ground statements they share
+func alignBlankNodes(a, b *Graph) map[BlankNode]BlankNode {
+	colorsA, colorsB := refineColors(a, b)
+	mapping := make(map[BlankNode]BlankNode)
+	used := make(map[BlankNode]bool)
+
+	byColor := make(map[uint64][]BlankNode)
+	for n, c := range colorsB {
+		byColor[c] = append(byColor[c], n)
+	}
+
+	countA := make(map[uint64]int)
+	for _, c := range colorsA {
+		countA[c]++
+	}
+
+	for n, c := range colorsA {
+		if countA[c] == 1 && len(byColor[c]) == 1 {
+			mapping[n] = byColor[c][0]
+			used[byColor[c][0]] = true
+		}
+	}
+
+	type pair struct {
+		from, to BlankNode
+		score    float64
+	}
+
+	var pairs []pair
+	mentionsA, mentionsB := mentions(a), mentions(b)
+
+	for _, n := range a.BlankNodes() {
+		if _, ok := mapping[n]; ok {
+			continue
+		}
+
+		sigA := groundSignature(mentionsA[n], n)
+
+		for _, m := range b.BlankNodes() {
+			if used[m] {
+				continue
+			}
+
+			if score := jaccard(sigA, groundSignature(mentionsB[m], m)); score > 0 {
+				pairs = append(pairs, pair{n, m, score})
+			}
+		}
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].score != pairs[j].score {
+			return pairs[i].score > pairs[j].score
+		}
+
+		if pairs[i].from != pairs[j].from {
+			return pairs[i].from < pairs[j].from
+		}
+
+		return pairs[i].to < pairs[j].to
+	})
+
+	for _, p := range pairs {
+		if _, ok := mapping[p.from]; ok || used[p.to] {
+			continue
+		}
+
+		mapping[p.from] = p.to
+		used[p.to] = true
+	}
+
+	return mapping
+}
+
+// groundSignature describes a blank node by the statements it is part of,
+// with any other blank node reduced to a placeholder
+func groundSignature(triples []Triple, n BlankNode) map[string]struct{} {
+	sig := make(map[string]struct{})
+
+	for _, t := range triples {
+		sig[signature(t, func(b BlankNode) string {
+			if b == n {
+				return "@self"
+			}
+
+			return "_"
+		})] = struct{}{}
+	}
+
+	return sig
+}
+
+func jaccard(a, b map[string]struct{}) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for k := range a {
+		if _, ok := b[k]; ok {
+			shared++
+		}
+	}
+
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// refineColors hashes every blank node of both graphs by its
+// neighbourhood, repeating until the partition stops getting finer. Both
+// graphs go through the same number of rounds so their colours can be
+// compared
+func refineColors(a, b *Graph) (map[BlankNode]uint64, map[BlankNode]uint64) {
+	mentionsA, mentionsB := mentions(a), mentions(b)
+	colorsA, colorsB := initialColors(a), initialColors(b)
+	classes := distinct(colorsA) + distinct(colorsB)
+
+	for {
+		nextA, nextB := refine(mentionsA, colorsA), refine(mentionsB, colorsB)
+		n := distinct(nextA) + distinct(nextB)
+
+		if n <= classes {
+			return nextA, nextB
+		}
+
+		colorsA, colorsB, classes = nextA, nextB, n
+	}
+}
+
+func initialColors(g *Graph) map[BlankNode]uint64 {
+	colors := make(map[BlankNode]uint64)
+	for _, n := range g.BlankNodes() {
+		colors[n] = 0
+	}
+
+	return colors
+}
+
+func refine(mentions map[BlankNode][]Triple, colors map[BlankNode]uint64) map[BlankNode]uint64 {
+	next := make(map[BlankNode]uint64, len(colors))
+
+	for n, triples := range mentions {
+		sigs := make([]uint64, 0, len(triples))
+
+		for _, t := range triples {
+			sigs = append(sigs, hashStrings(signature(t, func(b BlankNode) string {
+				if b == n {
+					return "@self"
+				}
+
+				return "_:" + strconv.FormatUint(colors[b], 16)
+			})))
+		}
+
+		sort.Slice(sigs, func(i, j int) bool { return sigs[i] < sigs[j] })
+
+		parts := []string{strconv.FormatUint(colors[n], 16)}
+		for _, s := range sigs {
+			parts = append(parts, strconv.FormatUint(s, 16))
+		}
+
+		next[n] = hashStrings(parts...)
+	}
+
+	return next
+}
+
+// signature writes a triple with each blank node, including those inside
+// quoted triples, replaced by the given label
+func signature(t Triple, label func(BlankNode) string) string {
+	var term func(Term) string
+	term = func(x Term) string {
+		switch v := x.(type) {
+		case BlankNode:
+			return label(v)
+		case QuotedTriple:
+			return "<< " + term(v.Subject) + " " + term(v.Predicate) + " " + term(v.Object) + " >>"
+		}
+
+		return x.String()
+	}
+
+	return term(t.Subject) + " " + term(t.Predicate) + " " + term(t.Object)
+}
+
+// mentions indexes the triples of a graph by the blank nodes they contain
+func mentions(g *Graph) map[BlankNode][]Triple {
+	m := make(map[BlankNode][]Triple)
+
+	for _, t := range g.Triples() {
+		seen := make(map[BlankNode]bool)
+
+		for _, term := range t.Terms() {
+			for _, b := range BlankNodesOf(term) {
+				if !seen[b] {
+					m[b] = append(m[b], t)
+					seen[b] = true
+				}
+			}
+		}
+	}
+
+	return m
+}
+
+func hashStrings(parts ...string) uint64 {
+	h := fnv.New64a()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+
+	return h.Sum64()
+}
+
+func distinct(colors map[BlankNode]uint64) int {
+	seen := make(map[uint64]struct{})
+	for _, c := range colors {
+		seen[c] = struct{}{}
+	}
+
+	return len(seen)
+}
+
+func hasBlankNode(t Triple) bool {
+	return len(BlankNodesOf(t.Subject)) > 0 || len(BlankNodesOf(t.Object)) > 0
+}
+
+// mapTriple relabels the blank nodes of a triple, reporting false if one of
+// them has no mapping
+func mapTriple(t Triple, mapping map[BlankNode]BlankNode) (Triple, bool) {
+	ok := true
+
+	var relabel func(Term) Term
+	relabel = func(term Term) Term {
+		switch v := term.(type) {
+		case BlankNode:
+			m, found := mapping[v]
+			if !found {
+				ok = false
+				return term
+			}
+
+			return m
+		case QuotedTriple:
+			return QuotedTriple{relabel(v.Subject), v.Predicate, relabel(v.Object)}
+		}
+
+		return term
+	}
+
+	return NewTriple(relabel(t.Subject), t.Predicate, relabel(t.Object)), ok
+}
@@ -0,0 +1,124 @@
+package rdf
+
+import "testing"
+
+var (
+	ex    = func(s string) IRI { return IRI("http://example.org/" + s) }
+	knows = ex("knows")
+	name  = ex("name")
+)
+
+type isoTest struct {
+	Name     string
+	A, B     []Triple
+	Expected bool
+}
+
+var isoTests = []isoTest{
+	{"Empty", nil, nil, true},
+	{
+		"Ground equal",
+		[]Triple{NewTriple(ex("a"), knows, ex("b"))},
+		[]Triple{NewTriple(ex("a"), knows, ex("b"))},
+		true,
+	},
+	{
+		"Relabelled blank nodes",
+		[]Triple{NewTriple(ex("a"), knows, BlankNode("x")), NewTriple(BlankNode("x"), name, NewLiteral("Bob"))},
+		[]Triple{NewTriple(ex("a"), knows, BlankNode("y")), NewTriple(BlankNode("y"), name, NewLiteral("Bob"))},
+		true,
+	},
+	{
+		"Different literal",
+		[]Triple{NewTriple(BlankNode("x"), name, NewLiteral("Bob"))},
+		[]Triple{NewTriple(BlankNode("y"), name, NewLiteral("Rob"))},
+		false,
+	},
+	{
+		// Two 3 cycles against a 6 cycle, every node looks the same locally
+		"Cycles",
+		[]Triple{
+			NewTriple(BlankNode("a"), knows, BlankNode("b")), NewTriple(BlankNode("b"), knows, BlankNode("c")), NewTriple(BlankNode("c"), knows, BlankNode("a")),
+			NewTriple(BlankNode("d"), knows, BlankNode("e")), NewTriple(BlankNode("e"), knows, BlankNode("f")), NewTriple(BlankNode("f"), knows, BlankNode("d")),
+		},
+		[]Triple{
+			NewTriple(BlankNode("1"), knows, BlankNode("2")), NewTriple(BlankNode("2"), knows, BlankNode("3")), NewTriple(BlankNode("3"), knows, BlankNode("4")),
+			NewTriple(BlankNode("4"), knows, BlankNode("5")), NewTriple(BlankNode("5"), knows, BlankNode("6")), NewTriple(BlankNode("6"), knows, BlankNode("1")),
+		},
+		false,
+	},
+	{
+		"Blank nodes in quoted triples",
+		[]Triple{NewTriple(QuotedTriple{BlankNode("a"), knows, ex("b")}, name, BlankNode("a"))},
+		[]Triple{NewTriple(QuotedTriple{BlankNode("z"), knows, ex("b")}, name, BlankNode("z"))},
+		true,
+	},
+	{
+		"Blank nodes in quoted triples differ",
+		[]Triple{NewTriple(QuotedTriple{BlankNode("a"), knows, ex("b")}, name, BlankNode("a"))},
+		[]Triple{NewTriple(QuotedTriple{BlankNode("z"), knows, ex("b")}, name, BlankNode("y"))},
+		false,
+	},
+	{
+		"Symmetric",
+		[]Triple{NewTriple(BlankNode("a"), knows, BlankNode("b")), NewTriple(BlankNode("b"), knows, BlankNode("a"))},
+		[]Triple{NewTriple(BlankNode("y"), knows, BlankNode("x")), NewTriple(BlankNode("x"), knows, BlankNode("y"))},
+		true,
+	},
+}
+
+func TestIsomorphic(t *testing.T) {
+	for _, tc := range isoTests {
+		if Isomorphic(NewGraph(tc.A...), NewGraph(tc.B...)) != tc.Expected {
+			t.Errorf("%v test fail", tc.Name)
+		}
+	}
+}
+
+func TestDiffAlignsBlankNodes(t *testing.T) {
+	a := NewGraph(
+		NewTriple(ex("a"), knows, BlankNode("x")),
+		NewTriple(BlankNode("x"), name, NewLiteral("Bob")),
+		NewTriple(BlankNode("x"), ex("age"), NewTypedLiteral("42", XSDInteger)),
+	)
+	b := NewGraph(
+		NewTriple(ex("a"), knows, BlankNode("y")),
+		NewTriple(BlankNode("y"), name, NewLiteral("Bob")),
+		NewTriple(BlankNode("y"), ex("age"), NewTypedLiteral("43", XSDInteger)),
+	)
+
+	d := Diff(a, b)
+
+	if len(d.Added) != 1 || len(d.Removed) != 1 {
+		t.Fatalf("expected one added and one removed triple, got %v and %v", d.Added, d.Removed)
+	}
+
+	if !Equal(d.Removed[0].Subject, BlankNode("y")) || !Equal(d.Added[0].Subject, BlankNode("y")) {
+		t.Errorf("blank node not aligned: %v %v", d.Removed[0], d.Added[0])
+	}
+
+	if !Diff(a, a.Clone()).Empty() {
+		t.Error("diff of a graph against itself not empty")
+	}
+}
+
+func TestResolveIRI(t *testing.T) {
+	base := "http://a/b/c/d;p?q"
+
+	for ref, expected := range map[string]IRI{
+		"g:h":     "g:h",
+		"g":       "http://a/b/c/g",
+		"../g":    "http://a/b/g",
+		"/g":      "http://a/g",
+		"//g":     "http://g",
+		"?y":      "http://a/b/c/d;p?y",
+		"#s":      "http://a/b/c/d;p?q#s",
+		"":        "http://a/b/c/d;p?q",
+		"../../g": "http://a/g",
+		"g/../h":  "http://a/b/c/h",
+	} {
+		if got := ResolveIRI(base, ref); got != expected {
+			t.Errorf("%q resolved to %v, expected %v", ref, got, expected)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package jsonld
+
+import (
+	"embed"
+	"encoding/json"
+	"strings"
+)
+
+// Copies of the contexts Solid notifications are written with, so they
+// can be read without fetching anything
+//
+//go:embed contexts
+var contexts embed.FS
+
+var contextFiles = map[string]string{
+	"https://www.w3.org/ns/activitystreams":       "contexts/activitystreams.jsonld",
+	"https://www.w3.org/ns/solid/notification/v1": "contexts/notification-v1.jsonld",
+}
+
+// bundledContext returns the local context of a bundled context document,
+// false if the URL isn't one. ActivityStreams is also referred to over
+// http and with its .jsonld extension
+func bundledContext(url string) (any, bool) {
+	url = strings.TrimSuffix(url, ".jsonld")
+	if rest, ok := strings.CutPrefix(url, "http://"); ok {
+		url = "https://" + rest
+	}
+
+	name, ok := contextFiles[url]
+	if !ok {
+		return nil, false
+	}
+
+	f, err := contexts.Open(name)
+	if err != nil {
+		return nil, false
+	}
+
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	dec.UseNumber()
+
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, false
+	}
+
+	return doc["@context"], true
+}
@@ -0,0 +1,792 @@
+// Package jsonld reads and writes graphs as JSON-LD, as much of it as
+// Solid apps exchange: inline contexts with prefixes, terms, @vocab and
+// type coercion, language maps, node and value objects, @reverse and
+// lists. Remote contexts aren't fetched, though the ActivityStreams and
+// Solid notification contexts are bundled, and named graphs are merged
+// into the one graph
+//
+// https://www.w3.org/TR/json-ld11/
+package jsonld
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/b1scuit/solid/rdf"
+)
+
+var (
+	ErrInvalid       = errors.New("jsonld: invalid document")
+	ErrRemoteContext = errors.New("jsonld: remote contexts aren't supported")
+)
+
+// A definition is what a context says a term means
+type definition struct {
+	id       string
+	reverse  bool
+	typ      string
+	language *string
+	list     bool
+
+	// languageMap is a term whose values are keyed by language
+	languageMap bool
+}
+
+type context struct {
+	base     string
+	vocab    string
+	language string
+	terms    map[string]definition
+}
+
+func (c *context) clone() *context {
+	n := *c
+	n.terms = make(map[string]definition, len(c.terms))
+
+	for k, v := range c.terms {
+		n.terms[k] = v
+	}
+
+	return &n
+}
+
+type reader struct {
+	graph  *rdf.Graph
+	base   string
+	labels map[string]rdf.BlankNode
+	count  int
+}
+
+// Parse reads a JSON-LD document, relative IRIs are resolved against the
+// base, usually the IRI of the document
+func Parse(r io.Reader, base string) (*rdf.Graph, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	rd := &reader{graph: rdf.NewGraph(), base: base, labels: map[string]rdf.BlankNode{}}
+
+	if err := rd.top(&context{base: base, terms: map[string]definition{}}, doc); err != nil {
+		return nil, err
+	}
+
+	return rd.graph, nil
+}
+
+// top reads the nodes of a document or a @graph
+func (rd *reader) top(cx *context, v any) error {
+	switch v := v.(type) {
+	case []any:
+		for _, item := range v {
+			if err := rd.top(cx, item); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case map[string]any:
+		_, err := rd.node(cx, v)
+		return err
+	}
+
+	return fmt.Errorf("%w: expected a node object, got %T", ErrInvalid, v)
+}
+
+// node adds the triples of a node object, returning its subject
+func (rd *reader) node(cx *context, m map[string]any) (rdf.Term, error) {
+	if local, ok := m["@context"]; ok {
+		var err error
+		if cx, err = rd.context(cx, local); err != nil {
+			return nil, err
+		}
+	}
+
+	m = unalias(cx, m)
+
+	var subject rdf.Term
+
+	if id, ok := m["@id"]; !ok {
+		subject = rd.blankNode("")
+	} else {
+		s, ok := id.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: @id isn't a string", ErrInvalid)
+		}
+
+		if subject = rd.reference(expand(cx, s, false, true)); subject == nil {
+			return nil, fmt.Errorf("%w: @id %q isn't an IRI", ErrInvalid, s)
+		}
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := m[key]
+
+		switch key {
+		case "@context", "@id", "@index":
+		case "@type":
+			for _, t := range array(value) {
+				s, ok := t.(string)
+				if !ok {
+					return nil, fmt.Errorf("%w: @type isn't a string", ErrInvalid)
+				}
+
+				if typ := rd.reference(expand(cx, s, true, true)); typ != nil {
+					rd.graph.Add(rdf.NewTriple(subject, rdf.RDFType, typ))
+				}
+			}
+		case "@graph", "@included":
+			if err := rd.top(cx, value); err != nil {
+				return nil, err
+			}
+		case "@reverse":
+			props, ok := value.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("%w: @reverse isn't an object", ErrInvalid)
+			}
+
+			for k, v := range props {
+				def := cx.terms[k]
+				def.reverse = !def.reverse
+
+				if err := rd.property(cx, subject, k, def, v); err != nil {
+					return nil, err
+				}
+			}
+		default:
+			if strings.HasPrefix(key, "@") {
+				continue
+			}
+
+			if err := rd.property(cx, subject, key, cx.terms[key], value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return subject, nil
+}
+
+func (rd *reader) property(cx *context, subject rdf.Term, key string, def definition, value any) error {
+	iri := expand(cx, key, true, false)
+	if !isIRI(iri) {
+		return nil
+	}
+
+	objects, err := rd.values(cx, def, value)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range objects {
+		if !def.reverse {
+			rd.graph.Add(rdf.NewTriple(subject, rdf.IRI(iri), o))
+		} else if _, ok := o.(rdf.Literal); !ok {
+			rd.graph.Add(rdf.NewTriple(o, rdf.IRI(iri), subject))
+		}
+	}
+
+	return nil
+}
+
+// values reads the objects of a property, a term with a @list container
+// making a list of an array
+func (rd *reader) values(cx *context, def definition, v any) ([]rdf.Term, error) {
+	if items, ok := v.([]any); ok {
+		if def.list {
+			l, err := rd.list(cx, def, items)
+			return []rdf.Term{l}, err
+		}
+
+		var terms []rdf.Term
+
+		for _, item := range items {
+			t, err := rd.values(cx, def, item)
+			if err != nil {
+				return nil, err
+			}
+
+			terms = append(terms, t...)
+		}
+
+		return terms, nil
+	}
+
+	m, isMap := v.(map[string]any)
+
+	if set, ok := m["@set"]; isMap && ok {
+		return rd.values(cx, def, set)
+	}
+
+	if _, ok := m["@value"]; def.languageMap && isMap && !ok {
+		return rd.languageMap(m)
+	}
+
+	if _, ok := m["@list"]; def.list && !ok {
+		l, err := rd.list(cx, def, []any{v})
+		return []rdf.Term{l}, err
+	}
+
+	t, err := rd.value(cx, def, v)
+	if t == nil || err != nil {
+		return nil, err
+	}
+
+	return []rdf.Term{t}, nil
+}
+
+// unalias replaces the keys of an object that the context defines as
+// keywords, like "id" for @id, with the keywords
+func unalias(cx *context, m map[string]any) map[string]any {
+	var n map[string]any
+
+	for k, v := range m {
+		def, ok := cx.terms[k]
+		if !ok || !strings.HasPrefix(def.id, "@") {
+			continue
+		}
+
+		if n == nil {
+			n = make(map[string]any, len(m))
+			for k, v := range m {
+				n[k] = v
+			}
+		}
+
+		delete(n, k)
+		n[def.id] = v
+	}
+
+	if n == nil {
+		return m
+	}
+
+	return n
+}
+
+// languageMap reads the strings of a language map, each tagged with the
+// language it is under
+func (rd *reader) languageMap(m map[string]any) ([]rdf.Term, error) {
+	langs := make([]string, 0, len(m))
+	for lang := range m {
+		langs = append(langs, lang)
+	}
+
+	sort.Strings(langs)
+
+	var terms []rdf.Term
+
+	for _, lang := range langs {
+		for _, v := range array(m[lang]) {
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: language map value is a %T", ErrInvalid, v)
+			}
+
+			if lang == "@none" {
+				terms = append(terms, rdf.NewLiteral(s))
+			} else {
+				terms = append(terms, rdf.NewLangLiteral(s, strings.ToLower(lang)))
+			}
+		}
+	}
+
+	return terms, nil
+}
+
+// value reads a single object, nil when there's nothing to add
+func (rd *reader) value(cx *context, def definition, v any) (rdf.Term, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		switch def.typ {
+		case "@id":
+			return rd.reference(expand(cx, v, false, true)), nil
+		case "@vocab":
+			return rd.reference(expand(cx, v, true, true)), nil
+		case "":
+			lang := cx.language
+			if def.language != nil {
+				lang = *def.language
+			}
+
+			if lang != "" {
+				return rdf.NewLangLiteral(v, lang), nil
+			}
+
+			return rdf.NewLiteral(v), nil
+		}
+
+		return rdf.NewTypedLiteral(v, rdf.IRI(def.typ)), nil
+	case json.Number, bool:
+		return literal(v, def.typ), nil
+	case map[string]any:
+		v = unalias(cx, v)
+
+		if value, ok := v["@value"]; ok {
+			return rd.valueObject(cx, v, value)
+		}
+
+		if items, ok := v["@list"]; ok {
+			list, _ := items.([]any)
+			if items != nil && list == nil {
+				list = []any{items}
+			}
+
+			return rd.list(cx, definition{typ: def.typ, language: def.language}, list)
+		}
+
+		return rd.node(cx, v)
+	}
+
+	return nil, fmt.Errorf("%w: unexpected %T", ErrInvalid, v)
+}
+
+func (rd *reader) valueObject(cx *context, m map[string]any, value any) (rdf.Term, error) {
+	var typ string
+
+	if t, ok := m["@type"]; ok {
+		s, ok := t.(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: @type of a value isn't a string", ErrInvalid)
+		}
+
+		typ = expand(cx, s, true, true)
+	}
+
+	switch value := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		if lang, ok := m["@language"].(string); ok {
+			return rdf.NewLangLiteral(value, lang), nil
+		}
+
+		if typ != "" {
+			return rdf.NewTypedLiteral(value, rdf.IRI(typ)), nil
+		}
+
+		return rdf.NewLiteral(value), nil
+	case json.Number, bool:
+		return literal(value, typ), nil
+	}
+
+	return nil, fmt.Errorf("%w: @value is a %T", ErrInvalid, value)
+}
+
+// list writes the items as an rdf:first/rdf:rest list, returning its head
+func (rd *reader) list(cx *context, def definition, items []any) (rdf.Term, error) {
+	def.list = false
+
+	var terms []rdf.Term
+
+	for _, item := range items {
+		if nested, ok := item.([]any); ok {
+			l, err := rd.list(cx, def, nested)
+			if err != nil {
+				return nil, err
+			}
+
+			terms = append(terms, l)
+
+			continue
+		}
+
+		t, err := rd.value(cx, def, item)
+		if err != nil {
+			return nil, err
+		}
+
+		if t != nil {
+			terms = append(terms, t)
+		}
+	}
+
+	var head rdf.Term = rdf.RDFNil
+
+	for i := len(terms) - 1; i >= 0; i-- {
+		node := rd.blankNode("")
+		rd.graph.Add(rdf.NewTriple(node, rdf.RDFFirst, terms[i]), rdf.NewTriple(node, rdf.RDFRest, head))
+		head = node
+	}
+
+	return head, nil
+}
+
+// reference turns an expanded IRI or blank node label into a term, nil if
+// it is neither
+func (rd *reader) reference(iri string) rdf.Term {
+	if label, ok := strings.CutPrefix(iri, "_:"); ok {
+		return rd.blankNode(label)
+	}
+
+	if !isIRI(iri) {
+		return nil
+	}
+
+	return rdf.IRI(iri)
+}
+
+// blankNode gives every label of the document a fresh node, so they can't
+// clash with the unlabelled ones
+func (rd *reader) blankNode(label string) rdf.BlankNode {
+	if b, ok := rd.labels[label]; ok && label != "" {
+		return b
+	}
+
+	rd.count++
+	b := rdf.BlankNode("b" + strconv.Itoa(rd.count))
+
+	if label != "" {
+		rd.labels[label] = b
+	}
+
+	return b
+}
+
+// context applies a local context to the active one
+func (rd *reader) context(active *context, local any) (*context, error) {
+	switch local := local.(type) {
+	case nil:
+		return &context{base: rd.base, terms: map[string]definition{}}, nil
+	case string:
+		bundled, ok := bundledContext(local)
+		if !ok {
+			return nil, fmt.Errorf("%w: %v", ErrRemoteContext, local)
+		}
+
+		return rd.context(active, bundled)
+	case []any:
+		var err error
+
+		for _, c := range local {
+			if active, err = rd.context(active, c); err != nil {
+				return nil, err
+			}
+		}
+
+		return active, nil
+	case map[string]any:
+		cx := active.clone()
+
+		if _, ok := local["@import"]; ok {
+			return nil, fmt.Errorf("%w: @import", ErrRemoteContext)
+		}
+
+		if base, ok := local["@base"]; ok {
+			s, _ := base.(string)
+			cx.base = string(rdf.ResolveIRI(cx.base, s))
+		}
+
+		if vocab, ok := local["@vocab"]; ok {
+			s, _ := vocab.(string)
+			cx.vocab = ""
+
+			if s != "" {
+				cx.vocab = expand(cx, s, true, true)
+			}
+		}
+
+		if lang, ok := local["@language"]; ok {
+			s, _ := lang.(string)
+			cx.language = strings.ToLower(s)
+		}
+
+		defined := map[string]bool{}
+
+		for term := range local {
+			if err := define(cx, local, term, defined); err != nil {
+				return nil, err
+			}
+		}
+
+		return cx, nil
+	}
+
+	return nil, fmt.Errorf("%w: context is a %T", ErrInvalid, local)
+}
+
+// define adds a term of the local context, first defining the terms its
+// IRI is built from
+func define(cx *context, local map[string]any, term string, defined map[string]bool) error {
+	if strings.HasPrefix(term, "@") || defined[term] {
+		return nil
+	}
+
+	defined[term] = true
+
+	// Compact IRIs and terms used by this definition come first
+	use := func(s string) error {
+		if prefix, _, ok := strings.Cut(s, ":"); ok && prefix != term {
+			if _, ok := local[prefix]; ok {
+				return define(cx, local, prefix, defined)
+			}
+		} else if _, ok := local[s]; ok && s != term {
+			return define(cx, local, s, defined)
+		}
+
+		return nil
+	}
+
+	var def definition
+
+	switch v := local[term].(type) {
+	case nil:
+		cx.terms[term] = definition{}
+		return nil
+	case string:
+		if err := use(v); err != nil {
+			return err
+		}
+
+		def.id = expand(cx, v, true, false)
+	case map[string]any:
+		id, hasID := v["@id"].(string)
+		if reverse, ok := v["@reverse"].(string); ok {
+			id, hasID, def.reverse = reverse, true, true
+		}
+
+		if hasID {
+			if err := use(id); err != nil {
+				return err
+			}
+
+			def.id = expand(cx, id, true, false)
+		} else {
+			if err := use(term); err != nil {
+				return err
+			}
+
+			def.id = expand(cx, term, true, false)
+		}
+
+		if t, ok := v["@type"].(string); ok {
+			if err := use(t); err != nil {
+				return err
+			}
+
+			def.typ = expand(cx, t, true, false)
+		}
+
+		if lang, ok := v["@language"]; ok {
+			s, _ := lang.(string)
+			s = strings.ToLower(s)
+			def.language = &s
+		}
+
+		for _, c := range array(v["@container"]) {
+			switch c {
+			case "@list":
+				def.list = true
+			case "@language":
+				def.languageMap = true
+			}
+		}
+	default:
+		return fmt.Errorf("%w: definition of %q is a %T", ErrInvalid, term, v)
+	}
+
+	cx.terms[term] = def
+
+	return nil
+}
+
+// expand turns a term, compact IRI or relative IRI into an IRI. Terms are
+// only looked up and @vocab only applied for vocab, relative IRIs are only
+// resolved against the base for documentRelative
+func expand(cx *context, s string, vocab, documentRelative bool) string {
+	if strings.HasPrefix(s, "@") {
+		return s
+	}
+
+	if vocab {
+		if def, ok := cx.terms[s]; ok {
+			return def.id
+		}
+	}
+
+	if prefix, suffix, ok := strings.Cut(s, ":"); ok {
+		if prefix == "_" || strings.HasPrefix(suffix, "//") {
+			return s
+		}
+
+		if def, ok := cx.terms[prefix]; ok && def.id != "" {
+			return def.id + suffix
+		}
+
+		return s
+	}
+
+	if vocab && cx.vocab != "" {
+		return cx.vocab + s
+	}
+
+	if documentRelative {
+		return string(rdf.ResolveIRI(cx.base, s))
+	}
+
+	return s
+}
+
+func isIRI(s string) bool {
+	return strings.Contains(s, ":") && !strings.HasPrefix(s, "@") && !strings.HasPrefix(s, "_:")
+}
+
+func array(v any) []any {
+	if a, ok := v.([]any); ok {
+		return a
+	}
+
+	if v == nil {
+		return nil
+	}
+
+	return []any{v}
+}
+
+// literal converts a native JSON number or boolean, integers being
+// xsd:integer and other numbers xsd:double unless coerced
+func literal(v any, typ string) rdf.Literal {
+	var l rdf.Literal
+
+	switch v := v.(type) {
+	case bool:
+		l = rdf.NewTypedLiteral(strconv.FormatBool(v), rdf.XSDBoolean)
+	case json.Number:
+		if i, err := v.Int64(); err == nil && !strings.ContainsAny(string(v), ".eE") && typ != string(rdf.XSDDouble) {
+			l, _ = rdf.LiteralOf(i)
+		} else {
+			f, _ := v.Float64()
+			l, _ = rdf.LiteralOf(f)
+		}
+	}
+
+	if typ != "" && typ != "@id" && typ != "@vocab" {
+		l.Datatype = rdf.IRI(typ)
+	}
+
+	return l
+}
+
+// Write writes the graph flattened, one node object per subject in a
+// @graph, with the prefixes as the context IRIs are compacted with.
+// Literals keep their lexical form as value objects
+func Write(w io.Writer, g *rdf.Graph, prefixes rdf.PrefixMap) error {
+	used := make(rdf.PrefixMap)
+	cx := map[string]any{}
+
+	for name, ns := range prefixes {
+		if name != "" {
+			used[name] = ns
+			cx[name] = string(ns)
+		}
+	}
+
+	compact := func(iri rdf.IRI) string {
+		if name, local, ok := used.Split(iri); ok {
+			return name + ":" + local
+		}
+
+		return string(iri)
+	}
+
+	ref := func(t rdf.Term) (string, error) {
+		switch t := t.(type) {
+		case rdf.IRI:
+			return compact(t), nil
+		case rdf.BlankNode:
+			return "_:" + string(t), nil
+		}
+
+		return "", fmt.Errorf("jsonld: can't write %v as a node", t)
+	}
+
+	var nodes []any
+
+	triples := g.Triples()
+
+	for i := 0; i < len(triples); {
+		subject := triples[i].Subject
+
+		id, err := ref(subject)
+		if err != nil {
+			return err
+		}
+
+		node := map[string]any{"@id": id}
+
+		for ; i < len(triples) && rdf.Equal(triples[i].Subject, subject); i++ {
+			t := triples[i]
+
+			p, ok := t.Predicate.(rdf.IRI)
+			if !ok {
+				return fmt.Errorf("jsonld: can't write %v as a property", t.Predicate)
+			}
+
+			if typ, ok := t.Object.(rdf.IRI); ok && p == rdf.RDFType {
+				types, _ := node["@type"].([]any)
+				node["@type"] = append(types, compact(typ))
+
+				continue
+			}
+
+			var object any
+
+			switch o := t.Object.(type) {
+			case rdf.Literal:
+				switch {
+				case o.Language != "":
+					object = map[string]any{"@value": o.Lexical, "@language": o.Language}
+				case o.Datatype == "" || o.Datatype == rdf.XSDString:
+					object = o.Lexical
+				default:
+					object = map[string]any{"@value": o.Lexical, "@type": compact(o.Datatype)}
+				}
+			default:
+				id, err := ref(o)
+				if err != nil {
+					return err
+				}
+
+				object = map[string]any{"@id": id}
+			}
+
+			key := compact(p)
+			values, _ := node[key].([]any)
+			node[key] = append(values, object)
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	doc := map[string]any{"@graph": nodes}
+	if len(cx) > 0 {
+		doc["@context"] = cx
+	}
+
+	if nodes == nil {
+		doc["@graph"] = []any{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(doc)
+}
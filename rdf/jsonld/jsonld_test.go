@@ -0,0 +1,154 @@
+package jsonld
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/parser"
+)
+
+const testBase = "https://pod.example/doc"
+
+func turtle(t *testing.T, doc string) *rdf.Graph {
+	p := parser.MustNew(parser.WithBase(testBase))
+
+	if err := p.Do(strings.NewReader(doc)); err != nil {
+		t.Fatalf("parsing test graph: %v", err)
+	}
+
+	return p.GetGraph()
+}
+
+type parseTest struct {
+	Name     string
+	Document string
+
+	// The graph as Turtle, or the error reading fails with
+	Expected string
+	Err      error
+}
+
+var parseTests = []parseTest{
+	{"Prefixes and terms",
+		`{"@context": {"foaf": "http://xmlns.com/foaf/0.1/", "name": "foaf:name",
+			"knows": {"@id": "foaf:knows", "@type": "@id"}},
+		  "@id": "#me", "@type": "foaf:Person", "name": "Alice", "knows": "https://bob.example/#me"}`,
+		`@prefix foaf: <http://xmlns.com/foaf/0.1/> .
+		<#me> a foaf:Person ; foaf:name "Alice" ; foaf:knows <https://bob.example/#me> .`,
+		nil},
+	{"Vocab, languages and native values",
+		`{"@context": {"@vocab": "http://schema.org/", "@language": "en"},
+		  "@id": "#book", "name": "Dune", "pages": 412, "rating": 4.5, "inPrint": true,
+		  "alternateName": {"@value": "Dune", "@language": "fr"}}`,
+		`@prefix s: <http://schema.org/> .
+		<#book> s:name "Dune"@en ; s:pages 412 ; s:rating 4.5E0 ; s:inPrint true ; s:alternateName "Dune"@fr .`,
+		nil},
+	{"Typed values and nested nodes",
+		`{"@context": {"ex": "http://example.org/", "xsd": "http://www.w3.org/2001/XMLSchema#",
+			"born": {"@id": "ex:born", "@type": "xsd:date"}},
+		  "@id": "ex:alice", "born": "1990-01-01",
+		  "ex:address": {"ex:city": "Leeds"}}`,
+		`@prefix ex: <http://example.org/> .
+		@prefix xsd: <http://www.w3.org/2001/XMLSchema#> .
+		ex:alice ex:born "1990-01-01"^^xsd:date ; ex:address [ ex:city "Leeds" ] .`,
+		nil},
+	{"Graphs, labelled blank nodes and reverse",
+		`{"@context": {"ex": "http://example.org/", "parent": {"@reverse": "ex:child"}},
+		  "@graph": [
+			{"@id": "_:a", "ex:name": "A"},
+			{"@id": "ex:b", "ex:knows": {"@id": "_:a"}, "parent": {"@id": "ex:c"}}
+		  ]}`,
+		`@prefix ex: <http://example.org/> .
+		_:x ex:name "A" . ex:b ex:knows _:x . ex:c ex:child ex:b .`,
+		nil},
+	{"Lists",
+		`{"@context": {"ex": "http://example.org/", "items": {"@id": "ex:items", "@container": "@list"}},
+		  "@id": "ex:l", "items": [1, 2], "ex:other": {"@list": ["a"]}, "ex:none": {"@list": []}}`,
+		`@prefix ex: <http://example.org/> .
+		ex:l ex:items (1 2) ; ex:other ("a") ; ex:none () .`,
+		nil},
+	{"Unmapped keys are dropped",
+		`{"@id": "#x", "name": "no vocab", "http://example.org/p": "kept"}`,
+		`<#x> <http://example.org/p> "kept" .`,
+		nil},
+	{"ActivityStreams context",
+		`{"@context": "https://www.w3.org/ns/activitystreams",
+		  "id": "#n", "type": "Announce", "actor": "https://alice.example/#me", "object": "/article",
+		  "summaryMap": {"en": "Read this", "FR": "Lisez ceci"}, "published": "2024-01-02T03:04:05Z", "unknown": "dropped"}`,
+		`@prefix as: <https://www.w3.org/ns/activitystreams#> .
+		@prefix xsd: <http://www.w3.org/2001/XMLSchema#> .
+		<#n> a as:Announce ; as:actor <https://alice.example/#me> ; as:object </article> ;
+			as:summary "Read this"@en, "Lisez ceci"@fr ;
+			as:published "2024-01-02T03:04:05Z"^^xsd:dateTime .`,
+		nil},
+	{"Notification contexts",
+		`{"@context": ["http://www.w3.org/ns/activitystreams.jsonld", "https://www.w3.org/ns/solid/notification/v1"],
+		  "id": "urn:uuid:1", "type": "Update", "object": "/doc", "state": "abc"}`,
+		`@prefix as: <https://www.w3.org/ns/activitystreams#> .
+		<urn:uuid:1> a as:Update ; as:object </doc> ; <http://www.w3.org/ns/solid/notifications#state> "abc" .`,
+		nil},
+	{"Remote context",
+		`{"@context": "https://example.org/context.jsonld", "type": "Update"}`,
+		``, ErrRemoteContext},
+	{"Not JSON",
+		`<#x> a <#y> .`,
+		``, ErrInvalid},
+}
+
+func TestParse(t *testing.T) {
+	for _, tc := range parseTests {
+		g, err := Parse(strings.NewReader(tc.Document), testBase)
+
+		if tc.Err != nil {
+			if !errors.Is(err, tc.Err) {
+				t.Errorf("%v test fail: expected %v, got %v", tc.Name, tc.Err, err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%v test fail: %v", tc.Name, err)
+			continue
+		}
+
+		if expected := turtle(t, tc.Expected); !rdf.Isomorphic(g, expected) {
+			t.Errorf("%v test fail: got %v", tc.Name, g.Triples())
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	g := turtle(t, `@prefix foaf: <http://xmlns.com/foaf/0.1/> .
+		@prefix xsd: <http://www.w3.org/2001/XMLSchema#> .
+		<#me> a foaf:Person ; foaf:name "Alice", "Alicia"@es ; foaf:age 30 ;
+			foaf:birthday "01-01"^^xsd:string ; foaf:knows [ foaf:name "Bob" ], <https://carol.example/#me> ;
+			foaf:interest ( <#a> <#b> ) .`)
+
+	var buf bytes.Buffer
+	if err := Write(&buf, g, rdf.PrefixMap{"foaf": "http://xmlns.com/foaf/0.1/", "xsd": "http://www.w3.org/2001/XMLSchema#"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), `"foaf:name"`) {
+		t.Errorf("IRIs not compacted: %v", buf.String())
+	}
+
+	read, err := Parse(&buf, testBase)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !rdf.Isomorphic(g, read) {
+		t.Errorf("round trip changed the graph: %v", read.Triples())
+	}
+
+	// Terms JSON-LD can't write
+	buf.Reset()
+	if err := Write(&buf, rdf.NewGraph(rdf.NewTriple(rdf.IRI("http://example.org/s"), rdf.IRI("http://example.org/p"), rdf.Formula{})), nil); err == nil {
+		t.Errorf("formula written: %v", buf.String())
+	}
+}
@@ -33,8 +33,15 @@ type Lexer struct {
 	Width int
 }
 
+/*
+Sets the input to lex and rewinds the lexer so it can be run again.
+*/
 func (l *Lexer) SetInput(b string) {
 	l.Input = b
+	l.Start = 0
+	l.Pos = 0
+	l.Width = 0
+	l.Tokens = make(chan lexertoken.Token)
 }
 
 func New(opts ...LexerOption) (*Lexer, error) {
@@ -80,13 +87,22 @@ func (l *Lexer) Emit(tokenType lexertoken.TokenType) {
 	l.Start = l.Pos
 }
 
+/*
+Puts a token onto the token channel with a value that has already been
+processed, such as an unescaped string, rather than the raw input.
+*/
+func (l *Lexer) EmitValue(tokenType lexertoken.TokenType, value string) {
+	l.Tokens <- lexertoken.Token{Type: tokenType, Value: value}
+	l.Start = l.Pos
+}
+
 /*
 Returns a token with error information.
 */
 func (l *Lexer) Errorf(format string, args ...interface{}) LexFn {
 	l.Tokens <- lexertoken.Token{
 		Type:  lexertoken.TOKEN_ERROR,
-		Value: fmt.Sprintf(format, args...),
+		Value: fmt.Sprintf("line %d: ", l.Line()) + fmt.Sprintf(format, args...),
 	}
 
 	return nil
@@ -101,13 +117,15 @@ func (l *Lexer) Ignore() {
 }
 
 /*
-Increment the position
+Increment the position by one rune
 */
 func (l *Lexer) Inc() {
-	l.Pos++
-	if l.Pos >= utf8.RuneCountInString(l.Input) {
-		l.Emit(lexertoken.TOKEN_EOF)
+	if l.IsEOF() {
+		return
 	}
+
+	_, width := utf8.DecodeRuneInString(l.Input[l.Pos:])
+	l.Pos += width
 }
 
 /*
@@ -126,6 +144,13 @@ func (l *Lexer) IsEOF() bool {
 	return l.Pos >= len(l.Input)
 }
 
+/*
+Returns the line number of the current position, starting at 1
+*/
+func (l *Lexer) Line() int {
+	return strings.Count(l.Input[:l.Pos], lexertoken.NEWLINE) + 1
+}
+
 /*
 Returns true/false if then next character is whitespace
 */
@@ -139,7 +164,7 @@ Reads the next rune (character) from the input stream
 and advances the lexer position.
 */
 func (l *Lexer) Next() rune {
-	if l.Pos >= utf8.RuneCountInString(l.Input) {
+	if l.IsEOF() {
 		l.Width = 0
 		return lexertoken.EOF
 	}
@@ -215,17 +240,7 @@ func (l *Lexer) Shutdown() {
 Skips whitespace until we get something meaningful.
 */
 func (l *Lexer) SkipWhitespace() {
-	for {
-		ch := l.Next()
-
-		if !unicode.IsSpace(ch) {
-			l.Dec()
-			break
-		}
-
-		if ch == lexertoken.EOF {
-			l.Emit(lexertoken.TOKEN_EOF)
-			break
-		}
+	for !l.IsEOF() && l.IsWhitespace() {
+		l.Next()
 	}
 }
@@ -23,21 +23,77 @@ const (
 	TOKEN_BLANK_NODE
 	TOKEN_LITERAL
 	TOKEN_NEWLINE
+
+	TOKEN_OBJECT
+	TOKEN_LANGTAG
+	TOKEN_DATATYPE
+	TOKEN_INTEGER
+	TOKEN_DECIMAL
+	TOKEN_DOUBLE
+	TOKEN_BOOLEAN
+	TOKEN_START_BLANK_NODE_PROPERTY_LIST
+	TOKEN_END_BLANK_NODE_PROPERTY_LIST
+	TOKEN_START_COLLECTION
+	TOKEN_END_COLLECTION
+
+	TOKEN_START_QUOTED_TRIPLE
+	TOKEN_END_QUOTED_TRIPLE
+	TOKEN_START_ANNOTATION
+	TOKEN_END_ANNOTATION
+
+	TOKEN_VAR
+	TOKEN_KEYWORD
+	TOKEN_OPERATOR
+	TOKEN_START_GROUP
+	TOKEN_END_GROUP
+
+	TOKEN_REGEXP
+	TOKEN_CODE
 )
 
 const (
-	EOF           rune = 0
-	START_IRI          = "<"
-	END_IRI            = ">"
-	PREFIX             = "@prefix"
-	SPARQL_PREFIX      = "PREFIX"
-	SPARQL_BASE        = "BASE"
-	BASE               = "@base"
-	OBJECT_LIST        = ";"
-	END_TRIPLE         = "."
-	PREFIX_END         = ":"
-	COMMENT            = "#"
-	OBJECT             = ","
+	EOF              rune = 0
+	START_IRI             = "<"
+	END_IRI               = ">"
+	PREFIX                = "@prefix"
+	SPARQL_PREFIX         = "PREFIX"
+	SPARQL_BASE           = "BASE"
+	BASE                  = "@base"
+	OBJECT_LIST           = ";"
+	END_TRIPLE            = "."
+	PREFIX_END            = ":"
+	COMMENT               = "#"
+	OBJECT                = ","
+	LANGTAG               = "@"
+	DATATYPE              = "^^"
+	BLANK_NODE_LABEL      = "_:"
+	A                     = "a"
+
+	START_BLANK_NODE_PROPERTY_LIST = "["
+	END_BLANK_NODE_PROPERTY_LIST   = "]"
+	START_COLLECTION               = "("
+	END_COLLECTION                 = ")"
+
+	START_QUOTED_TRIPLE = "<<"
+	END_QUOTED_TRIPLE   = ">>"
+	START_ANNOTATION    = "{|"
+	END_ANNOTATION      = "|}"
+
+	VAR         = "?"
+	VAR_ALT     = "$"
+	START_GROUP = "{"
+	END_GROUP   = "}"
+
+	REGEXP        = "/"
+	START_CODE    = "%"
+	END_CODE      = "%}"
+	START_COMMENT = "/*"
+	END_COMMENT   = "*/"
+	ANNOTATION    = "//"
+
+	IMPLIES    = "=>"
+	IMPLIED_BY = "<="
+	SAME_AS    = "="
 
 	NEWLINE = "\n"
 )
@@ -58,6 +114,30 @@ var TokenMap = map[TokenType]string{
 	TOKEN_LITERAL:       "Literal",
 	TOKEN_NEWLINE:       "New Line (\n)",
 	TOKEN_PREFIXED_NAME: "Prefixed Name",
+	TOKEN_PREFIX:        "Prefix",
+
+	TOKEN_OBJECT:                         "Object (,)",
+	TOKEN_LANGTAG:                        "Language Tag",
+	TOKEN_DATATYPE:                       "Datatype (^^)",
+	TOKEN_INTEGER:                        "Integer",
+	TOKEN_DECIMAL:                        "Decimal",
+	TOKEN_DOUBLE:                         "Double",
+	TOKEN_BOOLEAN:                        "Boolean",
+	TOKEN_START_BLANK_NODE_PROPERTY_LIST: "Start Blank Node Property List ([)",
+	TOKEN_END_BLANK_NODE_PROPERTY_LIST:   "End Blank Node Property List (])",
+	TOKEN_START_COLLECTION:               "Start Collection (()",
+	TOKEN_END_COLLECTION:                 "End Collection ())",
+	TOKEN_START_QUOTED_TRIPLE:            "Start Quoted Triple (<<)",
+	TOKEN_END_QUOTED_TRIPLE:              "End Quoted Triple (>>)",
+	TOKEN_START_ANNOTATION:               "Start Annotation ({|)",
+	TOKEN_END_ANNOTATION:                 "End Annotation (|})",
+	TOKEN_VAR:                            "Variable",
+	TOKEN_KEYWORD:                        "Keyword",
+	TOKEN_OPERATOR:                       "Operator",
+	TOKEN_START_GROUP:                    "Start Group ({)",
+	TOKEN_END_GROUP:                      "End Group (})",
+	TOKEN_REGEXP:                         "Regular Expression",
+	TOKEN_CODE:                           "Code",
 }
 
 type Token struct {
@@ -7,22 +7,18 @@ import (
 	"github.com/b1scuit/solid/rdf/lexer/lexertoken"
 )
 
+// A comment runs from the # to the end of the line, or the end of the input
+// if it's the last line of the file
 func LexComment(lexer *lexer.Lexer) lexer.LexFn {
 	// Remove the # at the start
 	lexer.Pos += len(lexertoken.COMMENT)
-	lexer.SkipWhitespace()
-	lexer.Start = lexer.Pos
-
-	for {
-		if strings.HasPrefix(lexer.InputToEnd(), lexertoken.NEWLINE) {
-			lexer.Emit(lexertoken.TOKEN_COMMENT)
-			return LexStatement
-		}
+	lexer.Ignore()
 
+	for !lexer.IsEOF() && !strings.HasPrefix(lexer.InputToEnd(), lexertoken.NEWLINE) {
 		lexer.Inc()
-
-		if lexer.IsEOF() {
-			return lexer.Errorf(LEXER_ERROR_UNEXPECTED_EOF)
-		}
 	}
+
+	lexer.Emit(lexertoken.TOKEN_COMMENT)
+
+	return LexStatement
 }
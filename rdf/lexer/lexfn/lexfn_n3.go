@@ -0,0 +1,94 @@
+package lexfn
+
+import (
+	"strings"
+
+	"github.com/b1scuit/solid/rdf/lexer"
+	"github.com/b1scuit/solid/rdf/lexer/lexertoken"
+)
+
+// Operators in the order they have to be tried, longest first
+var n3Operators = []string{lexertoken.IMPLIES, lexertoken.IMPLIED_BY, lexertoken.SAME_AS}
+
+// https://w3c.github.io/N3/spec/#grammar
+//
+// Notation3 is Turtle with formulae in braces, universal variables like ?x
+// and the => <= and = shorthands, which are emitted as operators. The
+// Turtle lex funcs are reused for everything else
+func LexN3(lex *lexer.Lexer) lexer.LexFn {
+	lex.SkipWhitespace()
+	lex.Ignore()
+
+	if lex.IsEOF() {
+		lex.Emit(lexertoken.TOKEN_EOF)
+		return nil
+	}
+
+	l := lex.InputToEnd()
+
+	switch {
+	case isComment(l):
+		return then(LexComment, LexN3)
+	case isKeyword(l, lexertoken.PREFIX, false):
+		return then(LexPrefixId, lexPrefixName, LexN3)
+	case isKeyword(l, lexertoken.BASE, false):
+		return then(LexBase, LexN3)
+	case isKeyword(l, lexertoken.SPARQL_PREFIX, true):
+		return then(LexSparqlPrefix, lexPrefixName, LexN3)
+	case isKeyword(l, lexertoken.SPARQL_BASE, true):
+		return then(LexSparqlBase, LexN3)
+	case strings.HasPrefix(l, lexertoken.VAR) && isVar(l):
+		return then(LexVar, LexN3)
+	case isLangTag(l):
+		return then(LexLangTag, LexN3)
+	case strings.HasPrefix(l, lexertoken.DATATYPE):
+		return n3Punctuation(lexertoken.DATATYPE, lexertoken.TOKEN_DATATYPE)
+	case strings.HasPrefix(l, lexertoken.IMPLIED_BY):
+		// Checked before IRIs as both start with <
+		return n3Punctuation(lexertoken.IMPLIED_BY, lexertoken.TOKEN_OPERATOR)
+	case isIriRef(l):
+		return then(LexIriRef, LexN3)
+	case isString(l):
+		return then(LexString, LexN3)
+	case isBlankNodeLabel(l):
+		return then(LexBlankNode, LexN3)
+	case strings.HasPrefix(l, lexertoken.START_GROUP):
+		return n3Punctuation(lexertoken.START_GROUP, lexertoken.TOKEN_START_GROUP)
+	case strings.HasPrefix(l, lexertoken.END_GROUP):
+		return n3Punctuation(lexertoken.END_GROUP, lexertoken.TOKEN_END_GROUP)
+	case strings.HasPrefix(l, lexertoken.START_BLANK_NODE_PROPERTY_LIST):
+		return n3Punctuation(lexertoken.START_BLANK_NODE_PROPERTY_LIST, lexertoken.TOKEN_START_BLANK_NODE_PROPERTY_LIST)
+	case strings.HasPrefix(l, lexertoken.END_BLANK_NODE_PROPERTY_LIST):
+		return n3Punctuation(lexertoken.END_BLANK_NODE_PROPERTY_LIST, lexertoken.TOKEN_END_BLANK_NODE_PROPERTY_LIST)
+	case strings.HasPrefix(l, lexertoken.START_COLLECTION):
+		return n3Punctuation(lexertoken.START_COLLECTION, lexertoken.TOKEN_START_COLLECTION)
+	case strings.HasPrefix(l, lexertoken.END_COLLECTION):
+		return n3Punctuation(lexertoken.END_COLLECTION, lexertoken.TOKEN_END_COLLECTION)
+	case strings.HasPrefix(l, lexertoken.OBJECT_LIST):
+		return n3Punctuation(lexertoken.OBJECT_LIST, lexertoken.TOKEN_OBJECT_LIST)
+	case strings.HasPrefix(l, lexertoken.OBJECT):
+		return n3Punctuation(lexertoken.OBJECT, lexertoken.TOKEN_OBJECT)
+	case startsNumber(l):
+		return then(LexNumericLiteral, LexN3)
+	case strings.HasPrefix(l, lexertoken.END_TRIPLE):
+		return n3Punctuation(lexertoken.END_TRIPLE, lexertoken.TOKEN_END_TRIPLE)
+	case isKeyword(l, "true", false), isKeyword(l, "false", false):
+		return then(LexBooleanLiteral, LexN3)
+	case isKeyword(l, lexertoken.A, false):
+		return n3Punctuation(lexertoken.A, lexertoken.TOKEN_PREDICATE)
+	case isPrefixedNameStart(l):
+		return then(LexPrefixedName, LexN3)
+	}
+
+	for _, op := range n3Operators {
+		if strings.HasPrefix(l, op) {
+			return n3Punctuation(op, lexertoken.TOKEN_OPERATOR)
+		}
+	}
+
+	return lex.Errorf("unexpected input: %v", firstWord(l))
+}
+
+func n3Punctuation(s string, tokenType lexertoken.TokenType) lexer.LexFn {
+	return then(lexPunctuation(s, tokenType), LexN3)
+}
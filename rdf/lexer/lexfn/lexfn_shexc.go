@@ -0,0 +1,244 @@
+package lexfn
+
+import (
+	"strings"
+
+	"github.com/b1scuit/solid/rdf/lexer"
+	"github.com/b1scuit/solid/rdf/lexer/lexertoken"
+)
+
+// Operators in the order they have to be tried, longest first
+var shexcOperators = []string{lexertoken.ANNOTATION, "$", "&", "|", "*", "+", "?", "^", "~", "-", "=", "_"}
+
+// https://shex.io/shex-semantics/#shexc
+//
+// ShExC borrows its terms from Turtle and its directives from SPARQL. The
+// @ of a shape reference is emitted as an operator when it is followed by
+// a label, otherwise it starts a language tag. Keywords are emitted upper
+// cased like SPARQL's
+func LexShExC(lex *lexer.Lexer) lexer.LexFn {
+	lex.SkipWhitespace()
+	lex.Ignore()
+
+	if lex.IsEOF() {
+		lex.Emit(lexertoken.TOKEN_EOF)
+		return nil
+	}
+
+	l := lex.InputToEnd()
+
+	switch {
+	case isComment(l):
+		return then(LexComment, LexShExC)
+	case strings.HasPrefix(l, lexertoken.START_COMMENT):
+		return LexBlockComment
+	case isKeyword(l, lexertoken.SPARQL_PREFIX, true):
+		return then(LexSparqlPrefix, lexPrefixName, LexShExC)
+	case isKeyword(l, lexertoken.SPARQL_BASE, true):
+		return then(LexSparqlBase, LexShExC)
+	case strings.HasPrefix(l, lexertoken.LANGTAG) && isShapeLabel(l[len(lexertoken.LANGTAG):]):
+		return shexcPunctuation(lexertoken.LANGTAG, lexertoken.TOKEN_OPERATOR)
+	case isLangTag(l) && len(l) > 1 && isPnCharsBase(l[1:]):
+		return then(LexLangTag, LexShExC)
+	case strings.HasPrefix(l, lexertoken.LANGTAG):
+		return shexcPunctuation(lexertoken.LANGTAG, lexertoken.TOKEN_OPERATOR)
+	case strings.HasPrefix(l, lexertoken.DATATYPE):
+		return shexcPunctuation(lexertoken.DATATYPE, lexertoken.TOKEN_DATATYPE)
+	case isIriRef(l):
+		return then(LexIriRef, LexShExC)
+	case isString(l):
+		return then(LexString, LexShExC)
+	case isBlankNodeLabel(l):
+		return then(LexBlankNode, LexShExC)
+	case strings.HasPrefix(l, lexertoken.ANNOTATION):
+		return shexcPunctuation(lexertoken.ANNOTATION, lexertoken.TOKEN_OPERATOR)
+	case strings.HasPrefix(l, lexertoken.REGEXP):
+		return LexRegexp
+	case strings.HasPrefix(l, lexertoken.START_CODE):
+		return LexCode
+	case strings.HasPrefix(l, lexertoken.START_GROUP):
+		return shexcPunctuation(lexertoken.START_GROUP, lexertoken.TOKEN_START_GROUP)
+	case strings.HasPrefix(l, lexertoken.END_GROUP):
+		return shexcPunctuation(lexertoken.END_GROUP, lexertoken.TOKEN_END_GROUP)
+	case strings.HasPrefix(l, lexertoken.START_BLANK_NODE_PROPERTY_LIST):
+		return shexcPunctuation(lexertoken.START_BLANK_NODE_PROPERTY_LIST, lexertoken.TOKEN_START_BLANK_NODE_PROPERTY_LIST)
+	case strings.HasPrefix(l, lexertoken.END_BLANK_NODE_PROPERTY_LIST):
+		return shexcPunctuation(lexertoken.END_BLANK_NODE_PROPERTY_LIST, lexertoken.TOKEN_END_BLANK_NODE_PROPERTY_LIST)
+	case strings.HasPrefix(l, lexertoken.START_COLLECTION):
+		return shexcPunctuation(lexertoken.START_COLLECTION, lexertoken.TOKEN_START_COLLECTION)
+	case strings.HasPrefix(l, lexertoken.END_COLLECTION):
+		return shexcPunctuation(lexertoken.END_COLLECTION, lexertoken.TOKEN_END_COLLECTION)
+	case strings.HasPrefix(l, lexertoken.OBJECT_LIST):
+		return shexcPunctuation(lexertoken.OBJECT_LIST, lexertoken.TOKEN_OBJECT_LIST)
+	case strings.HasPrefix(l, lexertoken.OBJECT):
+		return shexcPunctuation(lexertoken.OBJECT, lexertoken.TOKEN_OBJECT)
+	case startsNumber(l):
+		return then(LexNumericLiteral, LexShExC)
+	case strings.HasPrefix(l, lexertoken.END_TRIPLE):
+		return shexcPunctuation(lexertoken.END_TRIPLE, lexertoken.TOKEN_END_TRIPLE)
+	case isKeyword(l, lexertoken.A, false):
+		return shexcPunctuation(lexertoken.A, lexertoken.TOKEN_PREDICATE)
+	case isPrefixedNameStart(l) && hasPrefixName(firstName(l)):
+		return then(LexPrefixedName, LexShExC)
+	case isPnCharsBase(l):
+		return then(LexKeyword, LexShExC)
+	}
+
+	for _, op := range shexcOperators {
+		if strings.HasPrefix(l, op) {
+			return shexcPunctuation(op, lexertoken.TOKEN_OPERATOR)
+		}
+	}
+
+	return lex.Errorf("unexpected input: %v", firstWord(l))
+}
+
+// A block comment runs from /* to */ and isn't emitted
+func LexBlockComment(lex *lexer.Lexer) lexer.LexFn {
+	end := strings.Index(lex.InputToEnd(), lexertoken.END_COMMENT)
+	if end < 0 {
+		return lex.Errorf(LEXER_ERROR_UNEXPECTED_EOF)
+	}
+
+	lex.Pos += end + len(lexertoken.END_COMMENT)
+	lex.Ignore()
+
+	return LexShExC
+}
+
+// REGEXP	::=	'/' ([^/\\\n\r] | '\\' [nrt\\|.?*+(){}$-\[\]^/] | UCHAR)+ '/' [smix]*
+//
+// Emitted as the pattern and its flags separated by the last /. An escaped
+// / is unescaped, other escapes are left for the regular expression
+func LexRegexp(lex *lexer.Lexer) lexer.LexFn {
+	lex.Pos += len(lexertoken.REGEXP)
+
+	var b strings.Builder
+
+	for {
+		if lex.IsEOF() {
+			return lex.Errorf(LEXER_ERROR_UNEXPECTED_EOF)
+		}
+
+		l := lex.InputToEnd()
+
+		switch {
+		case isUChar(l):
+			r, ok := readUChar(lex)
+			if !ok {
+				return lex.Errorf("invalid unicode escape in regular expression")
+			}
+
+			b.WriteRune(r)
+			continue
+		case strings.HasPrefix(l, `\/`):
+			lex.Pos += 2
+			b.WriteString(lexertoken.REGEXP)
+			continue
+		case strings.HasPrefix(l, `\`) && len(l) > 1:
+			b.WriteString(l[:2])
+			lex.Pos += 2
+			continue
+		}
+
+		r := lex.Next()
+
+		if r == '\n' || r == '\r' {
+			return lex.Errorf("unterminated regular expression")
+		}
+
+		if r == '/' {
+			break
+		}
+
+		b.WriteRune(r)
+	}
+
+	if b.Len() == 0 {
+		return lex.Errorf("empty regular expression")
+	}
+
+	b.WriteString(lexertoken.REGEXP)
+
+	for !lex.IsEOF() && strings.ContainsRune("smix", lex.Peek()) {
+		b.WriteRune(lex.Next())
+	}
+
+	lex.EmitValue(lexertoken.TOKEN_REGEXP, b.String())
+
+	return LexShExC
+}
+
+// codeDecl	::=	'%' iri (CODE | '%')
+// CODE	::=	'{' ([^%\\] | '\\' [%\\] | UCHAR)* '%' '}'
+//
+// Emitted as the % operator, the IRI, then the code which is empty when
+// there isn't any
+func LexCode(lex *lexer.Lexer) lexer.LexFn {
+	lex.Pos += len(lexertoken.START_CODE)
+	lex.Emit(lexertoken.TOKEN_OPERATOR)
+	lex.SkipWhitespace()
+	lex.Ignore()
+
+	l := lex.InputToEnd()
+
+	switch {
+	case isIriRef(l):
+		return then(LexIriRef, lexCodeBody)
+	case isPrefixedNameStart(l) && hasPrefixName(firstName(l)):
+		return then(LexPrefixedName, lexCodeBody)
+	}
+
+	return lex.Errorf("semantic action missing name: %v", firstWord(l))
+}
+
+func lexCodeBody(lex *lexer.Lexer) lexer.LexFn {
+	lex.SkipWhitespace()
+	lex.Ignore()
+
+	if strings.HasPrefix(lex.InputToEnd(), lexertoken.START_CODE) {
+		lex.Pos += len(lexertoken.START_CODE)
+		lex.EmitValue(lexertoken.TOKEN_CODE, "")
+
+		return LexShExC
+	}
+
+	if !strings.HasPrefix(lex.InputToEnd(), lexertoken.START_GROUP) {
+		return lex.Errorf("semantic action missing code")
+	}
+
+	lex.Pos += len(lexertoken.START_GROUP)
+
+	var b strings.Builder
+
+	for {
+		if lex.IsEOF() {
+			return lex.Errorf(LEXER_ERROR_UNEXPECTED_EOF)
+		}
+
+		l := lex.InputToEnd()
+
+		switch {
+		case strings.HasPrefix(l, lexertoken.END_CODE):
+			lex.Pos += len(lexertoken.END_CODE)
+			lex.EmitValue(lexertoken.TOKEN_CODE, b.String())
+
+			return LexShExC
+		case strings.HasPrefix(l, `\%`), strings.HasPrefix(l, `\\`):
+			b.WriteByte(l[1])
+			lex.Pos += 2
+		default:
+			b.WriteRune(lex.Next())
+		}
+	}
+}
+
+func shexcPunctuation(s string, tokenType lexertoken.TokenType) lexer.LexFn {
+	return then(lexPunctuation(s, tokenType), LexShExC)
+}
+
+// isShapeLabel checks what follows an @ is a shape label rather than a
+// language tag, which can't contain a colon
+func isShapeLabel(s string) bool {
+	return isIriRef(s) || isBlankNodeLabel(s) || isPrefixedNameStart(s) && hasPrefixName(firstName(s))
+}
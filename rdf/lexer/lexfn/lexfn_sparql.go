@@ -0,0 +1,202 @@
+package lexfn
+
+import (
+	"strings"
+
+	"github.com/b1scuit/solid/rdf/lexer"
+	"github.com/b1scuit/solid/rdf/lexer/lexertoken"
+)
+
+// Operators in the order they have to be tried, longest first
+var sparqlOperators = []string{"&&", "||", "!=", "<=", ">=", "=", "<", ">", "!", "+", "-", "*", "/", "^", "|", "?"}
+
+// https://www.w3.org/TR/sparql11-query/#rQueryUnit
+//
+// SPARQL shares its terms with Turtle, so the Turtle lex funcs are reused
+// for IRIs, prefixed names, strings and numbers. On top of those it has
+// variables, keywords, operators and groups. Keywords are emitted upper
+// cased since SPARQL doesn't care about their case
+func LexSparql(lex *lexer.Lexer) lexer.LexFn {
+	lex.SkipWhitespace()
+	lex.Ignore()
+
+	if lex.IsEOF() {
+		lex.Emit(lexertoken.TOKEN_EOF)
+		return nil
+	}
+
+	l := lex.InputToEnd()
+
+	switch {
+	case isComment(l):
+		return then(LexComment, LexSparql)
+	case isKeyword(l, lexertoken.SPARQL_PREFIX, true):
+		return then(LexSparqlPrefix, lexPrefixName, LexSparql)
+	case isKeyword(l, lexertoken.SPARQL_BASE, true):
+		return then(LexSparqlBase, LexSparql)
+	case isVar(l):
+		return LexVar
+	case isLangTag(l):
+		return then(LexLangTag, LexSparql)
+	case strings.HasPrefix(l, lexertoken.DATATYPE):
+		return sparqlPunctuation(lexertoken.DATATYPE, lexertoken.TOKEN_DATATYPE)
+	case isSparqlIriRef(l):
+		return then(LexIriRef, LexSparql)
+	case strings.HasPrefix(l, lexertoken.START_QUOTED_TRIPLE):
+		return sparqlPunctuation(lexertoken.START_QUOTED_TRIPLE, lexertoken.TOKEN_START_QUOTED_TRIPLE)
+	case strings.HasPrefix(l, lexertoken.END_QUOTED_TRIPLE):
+		return sparqlPunctuation(lexertoken.END_QUOTED_TRIPLE, lexertoken.TOKEN_END_QUOTED_TRIPLE)
+	case isString(l):
+		return then(LexString, LexSparql)
+	case isBlankNodeLabel(l):
+		return then(LexBlankNode, LexSparql)
+	case strings.HasPrefix(l, lexertoken.START_GROUP):
+		return sparqlPunctuation(lexertoken.START_GROUP, lexertoken.TOKEN_START_GROUP)
+	case strings.HasPrefix(l, lexertoken.END_GROUP):
+		return sparqlPunctuation(lexertoken.END_GROUP, lexertoken.TOKEN_END_GROUP)
+	case strings.HasPrefix(l, lexertoken.START_BLANK_NODE_PROPERTY_LIST):
+		return sparqlPunctuation(lexertoken.START_BLANK_NODE_PROPERTY_LIST, lexertoken.TOKEN_START_BLANK_NODE_PROPERTY_LIST)
+	case strings.HasPrefix(l, lexertoken.END_BLANK_NODE_PROPERTY_LIST):
+		return sparqlPunctuation(lexertoken.END_BLANK_NODE_PROPERTY_LIST, lexertoken.TOKEN_END_BLANK_NODE_PROPERTY_LIST)
+	case strings.HasPrefix(l, lexertoken.START_COLLECTION):
+		return sparqlPunctuation(lexertoken.START_COLLECTION, lexertoken.TOKEN_START_COLLECTION)
+	case strings.HasPrefix(l, lexertoken.END_COLLECTION):
+		return sparqlPunctuation(lexertoken.END_COLLECTION, lexertoken.TOKEN_END_COLLECTION)
+	case strings.HasPrefix(l, lexertoken.OBJECT_LIST):
+		return sparqlPunctuation(lexertoken.OBJECT_LIST, lexertoken.TOKEN_OBJECT_LIST)
+	case strings.HasPrefix(l, lexertoken.OBJECT):
+		return sparqlPunctuation(lexertoken.OBJECT, lexertoken.TOKEN_OBJECT)
+	case startsUnsignedNumber(l):
+		return then(LexNumericLiteral, LexSparql)
+	case strings.HasPrefix(l, lexertoken.END_TRIPLE):
+		return sparqlPunctuation(lexertoken.END_TRIPLE, lexertoken.TOKEN_END_TRIPLE)
+	case isKeyword(l, lexertoken.A, false):
+		return sparqlPunctuation(lexertoken.A, lexertoken.TOKEN_PREDICATE)
+	case isPrefixedNameStart(l) && hasPrefixName(firstName(l)):
+		return then(LexPrefixedName, LexSparql)
+	case isPnCharsBase(l):
+		return LexKeyword
+	}
+
+	for _, op := range sparqlOperators {
+		if strings.HasPrefix(l, op) {
+			return sparqlPunctuation(op, lexertoken.TOKEN_OPERATOR)
+		}
+	}
+
+	return lex.Errorf("unexpected input: %v", firstWord(l))
+}
+
+// VAR1	::=	'?' VARNAME
+// VAR2	::=	'$' VARNAME
+//
+// Emitted without the ? or $
+func LexVar(lex *lexer.Lexer) lexer.LexFn {
+	lex.Pos += 1
+	lex.Ignore()
+
+	for !lex.IsEOF() && isPnChar(lex.Peek()) {
+		lex.Next()
+	}
+
+	lex.Emit(lexertoken.TOKEN_VAR)
+
+	return LexSparql
+}
+
+// Anything that looks like a word and isn't a prefixed name is a keyword,
+// including the names of built in functions. true and false are the
+// exception as they are boolean literals
+func LexKeyword(lex *lexer.Lexer) lexer.LexFn {
+	for !lex.IsEOF() && (isPnChar(lex.Peek()) && lex.Peek() != '-') {
+		lex.Next()
+	}
+
+	word := strings.ToUpper(lex.CurrentInput())
+
+	if word == "TRUE" || word == "FALSE" {
+		lex.EmitValue(lexertoken.TOKEN_BOOLEAN, strings.ToLower(word))
+		return LexSparql
+	}
+
+	lex.EmitValue(lexertoken.TOKEN_KEYWORD, word)
+
+	return LexSparql
+}
+
+// then runs lex funcs one after the other before carrying on with next,
+// which lets SPARQL borrow the Turtle lex funcs without returning to the
+// Turtle statement state. A nil state means an error was emitted
+func then(fns ...lexer.LexFn) lexer.LexFn {
+	next := fns[len(fns)-1]
+
+	return func(lex *lexer.Lexer) lexer.LexFn {
+		for _, fn := range fns[:len(fns)-1] {
+			if fn(lex) == nil {
+				return nil
+			}
+		}
+
+		return next
+	}
+}
+
+func sparqlPunctuation(s string, tokenType lexertoken.TokenType) lexer.LexFn {
+	return then(lexPunctuation(s, tokenType), LexSparql)
+}
+
+func isVar(s string) bool {
+	if !strings.HasPrefix(s, lexertoken.VAR) && !strings.HasPrefix(s, lexertoken.VAR_ALT) {
+		return false
+	}
+
+	r := []rune(s[1:])
+
+	return len(r) > 0 && isPnChar(r[0]) && r[0] != '-'
+}
+
+// In SPARQL a < could be a comparison, it's only an IRI if there is a
+// closing > before anything that can't be in an IRI
+func isSparqlIriRef(s string) bool {
+	if !isIriRef(s) || strings.HasPrefix(s, lexertoken.START_QUOTED_TRIPLE) {
+		return false
+	}
+
+	for _, r := range s[1:] {
+		switch {
+		case r == '>':
+			return true
+		case r <= 0x20 || strings.ContainsRune(`<"{}|^`+"`", r):
+			return false
+		}
+	}
+
+	return false
+}
+
+func startsUnsignedNumber(s string) bool {
+	return s != "" && s[0] != '+' && s[0] != '-' && startsNumber(s)
+}
+
+// firstName returns the input up to the first character that can't be in
+// a prefixed name
+func firstName(s string) string {
+	if i := strings.IndexFunc(s, func(r rune) bool {
+		return !isPnChar(r) && r != ':' && r != '.' && r != '\\' && r != '%'
+	}); i >= 0 {
+		return s[:i]
+	}
+
+	return s
+}
+
+// A prefixed name needs a colon after the prefix, words without one are
+// keywords
+func hasPrefixName(s string) bool {
+	i := strings.Index(s, lexertoken.PREFIX_END)
+	if i < 0 {
+		return false
+	}
+
+	return strings.IndexFunc(s[:i], func(r rune) bool { return !isPnChar(r) && r != '.' }) < 0
+}
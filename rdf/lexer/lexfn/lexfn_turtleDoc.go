@@ -2,6 +2,7 @@ package lexfn
 
 import (
 	"strings"
+	"unicode"
 
 	"github.com/b1scuit/solid/rdf/lexer"
 	"github.com/b1scuit/solid/rdf/lexer/lexertoken"
@@ -15,52 +16,76 @@ var LEXER_ERROR_UNEXPECTED_EOF = "Unexpected EOF"
 // What this means for the lex is keep restarting the LexStatement
 // until you hit EOF and finish
 func LexTurtleDoc(lex *lexer.Lexer) lexer.LexFn {
-	for {
-		return LexStatement
-	}
+	return LexStatement
 }
 
 // https://www.w3.org/TR/2014/REC-turtle-20140225/#grammar-production-statement
 // statement	::=	directive | triples '.'
-// A Statement is either a directive (@prefix, BASE stuff like that) or a triple that ends in a .
 //
-// This lexer.Lexer function does nothing except direct towards a directive or triple lex func
-// I've included lexing a line wide comment here, however a comment may appear on any line
-// till the end of it
+// The lexer only splits the document into terms and punctuation, working
+// out which of those form a triple is left to the parser. So this function
+// looks at the start of the remaining input and hands off to the lex func
+// for whatever term is there
 func LexStatement(lex *lexer.Lexer) lexer.LexFn {
-	for {
-		lex.SkipWhitespace()
-
-		if lex.IsEOF() {
-			// This will also kill the processing
-			lex.Emit(lexertoken.TOKEN_EOF)
-		}
-
-		l := lex.InputToEnd()
-
-		if isComment(l) {
-			return LexComment
-		}
+	lex.SkipWhitespace()
+	lex.Ignore()
 
-		if isDirective(l) {
-			return LexDirective
-		}
+	if lex.IsEOF() {
+		lex.Emit(lexertoken.TOKEN_EOF)
+		return nil
+	}
 
-		// If the start of the statement is an IRIREF
-		// high chance this is a triple ahead
-		// It is also the responsibility of the statement
-		// to lex the "." at the end of a triple
-		if isTriples(l) {
-			lex.State = LexTriples(lex)
-			lex.SkipWhitespace()
-			lex.Pos += len(lexertoken.END_TRIPLE)
-			lex.Ignore()
-
-			return lex.State
-		}
+	l := lex.InputToEnd()
 
-		lex.Inc()
+	switch {
+	case isComment(l):
+		return LexComment
+	case isKeyword(l, lexertoken.PREFIX, false), isKeyword(l, lexertoken.BASE, false),
+		isKeyword(l, lexertoken.SPARQL_PREFIX, true), isKeyword(l, lexertoken.SPARQL_BASE, true):
+		return LexDirective
+	case isLangTag(l):
+		return LexLangTag
+	case strings.HasPrefix(l, lexertoken.DATATYPE):
+		return lexPunctuation(lexertoken.DATATYPE, lexertoken.TOKEN_DATATYPE)
+	case strings.HasPrefix(l, lexertoken.START_QUOTED_TRIPLE):
+		return lexPunctuation(lexertoken.START_QUOTED_TRIPLE, lexertoken.TOKEN_START_QUOTED_TRIPLE)
+	case strings.HasPrefix(l, lexertoken.END_QUOTED_TRIPLE):
+		return lexPunctuation(lexertoken.END_QUOTED_TRIPLE, lexertoken.TOKEN_END_QUOTED_TRIPLE)
+	case strings.HasPrefix(l, lexertoken.START_ANNOTATION):
+		return lexPunctuation(lexertoken.START_ANNOTATION, lexertoken.TOKEN_START_ANNOTATION)
+	case strings.HasPrefix(l, lexertoken.END_ANNOTATION):
+		return lexPunctuation(lexertoken.END_ANNOTATION, lexertoken.TOKEN_END_ANNOTATION)
+	case isIriRef(l):
+		return LexIriRef
+	case isString(l):
+		return LexString
+	case isBlankNodeLabel(l):
+		return LexBlankNode
+	case strings.HasPrefix(l, lexertoken.START_BLANK_NODE_PROPERTY_LIST):
+		return lexPunctuation(lexertoken.START_BLANK_NODE_PROPERTY_LIST, lexertoken.TOKEN_START_BLANK_NODE_PROPERTY_LIST)
+	case strings.HasPrefix(l, lexertoken.END_BLANK_NODE_PROPERTY_LIST):
+		return lexPunctuation(lexertoken.END_BLANK_NODE_PROPERTY_LIST, lexertoken.TOKEN_END_BLANK_NODE_PROPERTY_LIST)
+	case strings.HasPrefix(l, lexertoken.START_COLLECTION):
+		return lexPunctuation(lexertoken.START_COLLECTION, lexertoken.TOKEN_START_COLLECTION)
+	case strings.HasPrefix(l, lexertoken.END_COLLECTION):
+		return lexPunctuation(lexertoken.END_COLLECTION, lexertoken.TOKEN_END_COLLECTION)
+	case strings.HasPrefix(l, lexertoken.OBJECT_LIST):
+		return lexPunctuation(lexertoken.OBJECT_LIST, lexertoken.TOKEN_OBJECT_LIST)
+	case strings.HasPrefix(l, lexertoken.OBJECT):
+		return lexPunctuation(lexertoken.OBJECT, lexertoken.TOKEN_OBJECT)
+	case startsNumber(l):
+		return LexNumericLiteral
+	case strings.HasPrefix(l, lexertoken.END_TRIPLE):
+		return lexPunctuation(lexertoken.END_TRIPLE, lexertoken.TOKEN_END_TRIPLE)
+	case isKeyword(l, "true", false), isKeyword(l, "false", false):
+		return LexBooleanLiteral
+	case isKeyword(l, lexertoken.A, false):
+		return lexPunctuation(lexertoken.A, lexertoken.TOKEN_PREDICATE)
+	case isPrefixedNameStart(l):
+		return LexPrefixedName
 	}
+
+	return lex.Errorf("unexpected input: %v", firstWord(l))
 }
 
 // Directive
@@ -71,344 +96,458 @@ func LexStatement(lex *lexer.Lexer) lexer.LexFn {
 func LexDirective(lex *lexer.Lexer) lexer.LexFn {
 	l := lex.InputToEnd()
 
-	if isPrefixID(l) {
+	if isKeyword(l, lexertoken.PREFIX, false) {
 		return LexPrefixId
 	}
 
-	if isBase(l) {
+	if isKeyword(l, lexertoken.BASE, false) {
 		return LexBase
 	}
 
-	if isSparqlPrefix(l) {
+	if isKeyword(l, lexertoken.SPARQL_PREFIX, true) {
 		return LexSparqlPrefix
 	}
 
-	if isSparqlBase(l) {
+	if isKeyword(l, lexertoken.SPARQL_BASE, true) {
 		return LexSparqlBase
 	}
 
-	return lex.Errorf("lexdirective wasn't given a tutle directive: %v", lex.InputToEnd())
+	return lex.Errorf("lexdirective wasn't given a tutle directive: %v", firstWord(l))
 }
 
 // prefixID	::=	'@prefix' PNAME_NS IRIREF '.'
+//
+// The keyword is emitted as a TOKEN_PREFIX so the parser knows whether to
+// expect the trailing '.', which is then lexed as a normal end of triple
 func LexPrefixId(lex *lexer.Lexer) lexer.LexFn {
-	// Move the Pos counter over the length of @prefix
-	// Then Ignore() sets start == pos, omitting the prefix keyword
 	lex.Pos += len(lexertoken.PREFIX)
-	lex.SkipWhitespace()
-	lex.Ignore()
+	lex.Emit(lexertoken.TOKEN_PREFIX)
 
-	for {
-		if strings.HasPrefix(lex.InputToEnd(), lexertoken.PREFIX_END) {
-			lex.Emit(lexertoken.TOKEN_PREFIX_NAME)
-			lex.Pos += len(lexertoken.PREFIX_END)
+	return lexPrefixName
+}
 
-			// Lex IRI and knock off the . at the end
-			lex.State = LexIriRef(lex)
-			lex.SkipWhitespace()
-			lex.Pos += len(lexertoken.END_TRIPLE)
-			lex.Ignore()
+// sparqlPrefix	::=	"PREFIX" PNAME_NS IRIREF
+func LexSparqlPrefix(lex *lexer.Lexer) lexer.LexFn {
+	lex.Pos += len(lexertoken.SPARQL_PREFIX)
+	lex.EmitValue(lexertoken.TOKEN_PREFIX, lexertoken.SPARQL_PREFIX)
 
-			return lex.State
-		}
+	return lexPrefixName
+}
 
-		lex.Inc()
+// base	::=	'@base' IRIREF '.'
+func LexBase(lex *lexer.Lexer) lexer.LexFn {
+	lex.Pos += len(lexertoken.BASE)
+	lex.Emit(lexertoken.TOKEN_BASE)
 
-		if lex.IsEOF() {
-			return lex.Errorf("Unexpected EOF")
-		}
-	}
+	return LexStatement
 }
 
-func LexBase(lex *lexer.Lexer) lexer.LexFn {
+// sparqlBase	::=	"BASE" IRIREF
+func LexSparqlBase(lex *lexer.Lexer) lexer.LexFn {
+	lex.Pos += len(lexertoken.SPARQL_BASE)
+	lex.EmitValue(lexertoken.TOKEN_BASE, lexertoken.SPARQL_BASE)
+
+	return LexStatement
+}
+
+// PNAME_NS	::=	PN_PREFIX? ':'
+//
+// Emits the prefix without the trailing colon, the empty prefix is valid
+func lexPrefixName(lex *lexer.Lexer) lexer.LexFn {
 	lex.SkipWhitespace()
-	lex.Pos += len(lexertoken.BASE)
-	for {
-		if strings.HasPrefix(lex.InputToEnd(), " ") {
-			lex.Pos += len(" ")
-			lex.Emit(lexertoken.TOKEN_BASE)
-
-			// Lex IRI and knock off the . at the end
-			lex.State = LexIriRef(lex)
-			lex.SkipWhitespace()
-			lex.Pos += len(lexertoken.END_TRIPLE)
-			lex.SkipWhitespace()
-			lex.Ignore()
-
-			if isComment(lex.InputToEnd()) {
-				return LexComment
-			}
+	lex.Ignore()
 
-			return lex.State
+	for !strings.HasPrefix(lex.InputToEnd(), lexertoken.PREFIX_END) {
+		if lex.IsEOF() {
+			return lex.Errorf(LEXER_ERROR_UNEXPECTED_EOF)
+		}
+
+		if lex.IsWhitespace() {
+			return lex.Errorf("prefix name missing ':': %v", lex.CurrentInput())
 		}
 
 		lex.Inc()
+	}
+
+	lex.Emit(lexertoken.TOKEN_PREFIX_NAME)
+	lex.Pos += len(lexertoken.PREFIX_END)
+	lex.Ignore()
+
+	return LexStatement
+}
+
+// IRIREF	::=	'<' ([^#x00-#x20<>"{}|^`\] | UCHAR)* '>'
+func LexIriRef(lex *lexer.Lexer) lexer.LexFn {
+	lex.Pos += len(lexertoken.START_IRI)
+	lex.Ignore()
 
+	var b strings.Builder
+
+	for {
 		if lex.IsEOF() {
 			return lex.Errorf(LEXER_ERROR_UNEXPECTED_EOF)
 		}
+
+		if strings.HasPrefix(lex.InputToEnd(), lexertoken.END_IRI) {
+			lex.Pos += len(lexertoken.END_IRI)
+			lex.EmitValue(lexertoken.TOKEN_IRIREF, b.String())
+
+			return LexStatement
+		}
+
+		if isUChar(lex.InputToEnd()) {
+			r, ok := readUChar(lex)
+			if !ok {
+				return lex.Errorf("invalid unicode escape in IRI")
+			}
+
+			b.WriteRune(r)
+			continue
+		}
+
+		r := lex.Next()
+		if r <= 0x20 || strings.ContainsRune(`<"{}|^`+"`"+`\`, r) {
+			return lex.Errorf("invalid character %q in IRI", r)
+		}
+
+		b.WriteRune(r)
 	}
 }
 
-func LexSparqlBase(lex *lexer.Lexer) lexer.LexFn {
-	lex.SkipWhitespace()
-	lex.Pos += len(lexertoken.SPARQL_BASE)
-	for {
-		if strings.HasPrefix(lex.InputToEnd(), " ") {
-			lex.Pos += len(" ")
-			lex.Emit(lexertoken.TOKEN_BASE)
-
-			// Lex IRI and knock off the . at the end
-			lex.State = LexIriRef(lex)
-			lex.SkipWhitespace()
-			lex.Pos += len(lexertoken.NEWLINE)
-			lex.Ignore()
-
-			if isComment(lex.InputToEnd()) {
-				return LexComment
-			}
+// PrefixedName	::=	PNAME_LN | PNAME_NS
+//
+// Local names may contain dots but can't end with one, so a trailing dot is
+// left for the end of triple
+func LexPrefixedName(lex *lexer.Lexer) lexer.LexFn {
+	var b strings.Builder
+
+	for !lex.IsEOF() && isPnChar(lex.Peek()) {
+		b.WriteRune(lex.Next())
+	}
+
+	if !strings.HasPrefix(lex.InputToEnd(), lexertoken.PREFIX_END) {
+		return lex.Errorf("unexpected input: %v", firstWord(lex.CurrentInput()+lex.InputToEnd()))
+	}
 
-			return lex.State
+	b.WriteString(lexertoken.PREFIX_END)
+	lex.Pos += len(lexertoken.PREFIX_END)
+
+	for !lex.IsEOF() {
+		l := lex.InputToEnd()
+
+		if isPnLocalEsc(l) && len(l) > 1 {
+			lex.Pos += 1
+			b.WriteRune(lex.Next())
+			continue
 		}
 
-		lex.Inc()
+		r := lex.Peek()
 
-		if lex.IsEOF() {
-			return lex.Errorf(LEXER_ERROR_UNEXPECTED_EOF)
+		if r == '.' {
+			// Only part of the name if more name follows
+			if len(l) < 2 || !(isPnChar(rune(l[1])) || l[1] == ':' || l[1] == '%') {
+				break
+			}
+		} else if !isPnChar(r) && r != ':' && r != '%' {
+			break
 		}
+
+		b.WriteRune(lex.Next())
 	}
+
+	lex.EmitValue(lexertoken.TOKEN_PREFIXED_NAME, b.String())
+
+	return LexStatement
 }
 
-func LexSparqlPrefix(lex *lexer.Lexer) lexer.LexFn {
-	// Move the Pos counter over the length of @prefix
-	// Then Ignore() sets start == pos, omitting the prefix keyword
-	lex.Pos += len(lexertoken.SPARQL_PREFIX)
+// BLANK_NODE_LABEL	::=	'_:' (PN_CHARS_U | [0-9]) ((PN_CHARS | '.')* PN_CHARS)?
+//
+// Emitted without the leading _:
+func LexBlankNode(lex *lexer.Lexer) lexer.LexFn {
+	lex.Pos += len(lexertoken.BLANK_NODE_LABEL)
 	lex.Ignore()
 
-	for {
-		if strings.HasPrefix(lex.InputToEnd(), lexertoken.PREFIX_END) {
-			lex.Emit(lexertoken.TOKEN_PREFIX_NAME)
-			lex.Pos += len(lexertoken.PREFIX_END)
-
-			lex.State = LexIriRef(lex)
-			lex.SkipWhitespace()
-			lex.Pos += len(lexertoken.END_TRIPLE)
-			lex.SkipWhitespace()
-			lex.Ignore()
-
-			if isComment(lex.InputToEnd()) {
-				return LexComment
-			}
+	for !lex.IsEOF() {
+		l := lex.InputToEnd()
+		r := lex.Peek()
 
-			return lex.State
+		if r == '.' && (len(l) < 2 || !isPnChar(rune(l[1]))) {
+			break
 		}
 
-		lex.Inc()
-
-		if lex.IsEOF() {
-			return lex.Errorf("Unexpected EOF")
+		if r != '.' && !isPnChar(r) {
+			break
 		}
+
+		lex.Next()
 	}
+
+	if lex.Pos == lex.Start {
+		return lex.Errorf("blank node missing label")
+	}
+
+	lex.Emit(lexertoken.TOKEN_BLANK_NODE)
+
+	return LexStatement
 }
 
-// Triples
-// ::=	subject predicateObjectList | blankNodePropertyList predicateObjectList?
-func LexTriples(lex *lexer.Lexer) lexer.LexFn {
-	l := lex.InputToEnd()
+// LANGTAG	::=	'@' [a-zA-Z]+ ('-' [a-zA-Z0-9]+)*
+//
+// Emitted without the @
+func LexLangTag(lex *lexer.Lexer) lexer.LexFn {
+	lex.Pos += len(lexertoken.LANGTAG)
+	lex.Ignore()
 
-	if isSubject(l) {
-		// On a triple the subject is always followed by a predicateObjectList
-		lex.State = LexSubject(lex)
-		lex.SkipWhitespace()
-		lex.State = LexPredicateObjectList(lex)
-	} else if isBlanknodePropertyList(l) {
-		lex.State = LexBlankNodePropertyList(lex)
-		lex.SkipWhitespace()
-
-		if isPredicateObjectList(lex.InputToEnd()) {
-			lex.State = LexPredicateObjectList(lex)
+	for !lex.IsEOF() {
+		r := lex.Peek()
+		if !(r == '-' || r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r))) {
+			break
 		}
-	} else {
-		lex.State = lex.Errorf("input to LexTriples was not a triple")
+
+		lex.Next()
+	}
+
+	if lex.Pos == lex.Start {
+		return lex.Errorf("empty language tag")
 	}
 
-	return lex.State
+	lex.Emit(lexertoken.TOKEN_LANGTAG)
+
+	return LexStatement
 }
 
-// predicateObjectList
-// ::=	verb objectList (';' (verb objectList)?)*
-func LexPredicateObjectList(lex *lexer.Lexer) lexer.LexFn {
+// String	::=	STRING_LITERAL_QUOTE | STRING_LITERAL_SINGLE_QUOTE | STRING_LITERAL_LONG_SINGLE_QUOTE | STRING_LITERAL_LONG_QUOTE
+//
+// The emitted value has its escapes already processed
+func LexString(lex *lexer.Lexer) lexer.LexFn {
+	l := lex.InputToEnd()
+
+	quote := l[:1]
+	long := isStringLiteralLongQuote(l) || isStringLiteralLongSingleQuote(l)
+	if long {
+		quote = strings.Repeat(quote, 3)
+	}
+
+	lex.Pos += len(quote)
+
+	var b strings.Builder
 
 	for {
-		lex.SkipWhitespace()
-		lex.Ignore()
+		if lex.IsEOF() {
+			return lex.Errorf(LEXER_ERROR_UNEXPECTED_EOF)
+		}
 
-		if isVerb(lex.CurrentInput()) {
-			lex.State = LexVerb(lex)
+		l := lex.InputToEnd()
+
+		if strings.HasPrefix(l, quote) {
+			lex.Pos += len(quote)
+			lex.EmitValue(lexertoken.TOKEN_LITERAL, b.String())
+
+			return LexStatement
 		}
 
-		if isObjectList(lex.InputToEnd()) {
-			lex.State = LexObjectList(lex)
+		if isUChar(l) {
+			r, ok := readUChar(lex)
+			if !ok {
+				return lex.Errorf("invalid unicode escape in string")
+			}
+
+			b.WriteRune(r)
+			continue
 		}
 
-		if strings.HasPrefix(lex.InputToEnd(), lexertoken.OBJECT_LIST) {
-			lex.Pos += len(lexertoken.OBJECT_LIST)
-			lex.Ignore()
+		if isEChar(l) {
+			lex.Pos += 1
+			r, ok := echars[lex.Next()]
+			if !ok {
+				return lex.Errorf("invalid escape in string")
+			}
+
+			b.WriteRune(r)
+			continue
 		}
 
-		if strings.HasPrefix(lex.InputToEnd(), lexertoken.END_TRIPLE) {
-			return lex.State
+		r := lex.Next()
+		if !long && (r == '\n' || r == '\r') {
+			return lex.Errorf("new line in short string")
 		}
 
-		lex.Inc()
+		b.WriteRune(r)
 	}
 }
 
-func LexObjectList(lex *lexer.Lexer) lexer.LexFn {
-	for {
-		lex.SkipWhitespace()
+// NumericLiteral	::=	INTEGER | DECIMAL | DOUBLE
+func LexNumericLiteral(lex *lexer.Lexer) lexer.LexFn {
+	tokenType := lexertoken.TOKEN_INTEGER
 
-		if strings.HasPrefix(lex.InputToEnd(), lexertoken.END_TRIPLE) || strings.HasPrefix(lexertoken.OBJECT_LIST, lex.InputToEnd()) {
-			return lex.State
-		}
+	if r := lex.Peek(); r == '+' || r == '-' {
+		lex.Next()
+	}
+
+	digits := acceptDigits(lex)
 
-		if isObject(lex.InputToEnd()) {
-			lex.State = LexObject(lex)
+	if l := lex.InputToEnd(); strings.HasPrefix(l, ".") && len(l) > 1 && unicode.IsDigit(rune(l[1])) {
+		lex.Next()
+		digits += acceptDigits(lex)
+		tokenType = lexertoken.TOKEN_DECIMAL
+	}
+
+	if digits == 0 {
+		return lex.Errorf("invalid number: %v", lex.CurrentInput())
+	}
+
+	if r := lex.Peek(); r == 'e' || r == 'E' {
+		lex.Next()
+
+		if r := lex.Peek(); r == '+' || r == '-' {
+			lex.Next()
 		}
 
-		if strings.HasPrefix(lex.InputToEnd(), lexertoken.OBJECT) {
-			lex.Pos += len(lexertoken.OBJECT)
-			lex.Ignore()
+		if acceptDigits(lex) == 0 {
+			return lex.Errorf("invalid exponent: %v", lex.CurrentInput())
 		}
 
-		lex.Inc()
+		tokenType = lexertoken.TOKEN_DOUBLE
 	}
-}
 
-func LexVerb(lex *lexer.Lexer) lexer.LexFn {
-	lex.SkipWhitespace()
+	lex.Emit(tokenType)
 
-	if isPredicate(lex.InputToEnd()) {
-		lex.State = LexPredicate(lex)
-		return lex.State
-	}
+	return LexStatement
+}
 
-	if strings.HasPrefix(lex.InputToEnd(), "a") {
-		lex.Pos += len("a")
-		lex.Emit(lexertoken.TOKEN_PREDICATE)
+// BooleanLiteral	::=	'true' | 'false'
+func LexBooleanLiteral(lex *lexer.Lexer) lexer.LexFn {
+	if strings.HasPrefix(lex.InputToEnd(), "true") {
+		lex.Pos += len("true")
+	} else {
+		lex.Pos += len("false")
 	}
 
-	return lex.Errorf("value passed to LexVerb unknown")
+	lex.Emit(lexertoken.TOKEN_BOOLEAN)
+
+	return LexStatement
 }
 
-// Subject
-// ::=	iri | BlankNode | collection
-func LexSubject(lex *lexer.Lexer) lexer.LexFn {
-	l := lex.InputToEnd()
+func lexPunctuation(s string, tokenType lexertoken.TokenType) lexer.LexFn {
+	return func(lex *lexer.Lexer) lexer.LexFn {
+		lex.Pos += len(s)
+		lex.Emit(tokenType)
 
-	if isIri(l) {
-		lex.State = LexIri(lex)
-	} else if isBlankNode(l) {
-		lex.State = LexBlankNode(lex)
-	} else if isCollection(l) {
-		lex.State = LexCollection(lex)
-	} else {
-		lex.State = lex.Errorf("input to LexSubject was not a RDF subject")
+		return LexStatement
 	}
-
-	return lex.State
 }
 
-func LexPredicate(lex *lexer.Lexer) lexer.LexFn {
-	return LexIri
+var echars = map[rune]rune{
+	't':  '\t',
+	'b':  '\b',
+	'n':  '\n',
+	'r':  '\r',
+	'f':  '\f',
+	'"':  '"',
+	'\'': '\'',
+	'\\': '\\',
 }
 
-func LexObject(lex *lexer.Lexer) lexer.LexFn {
+// UCHAR	::=	'\u' HEX HEX HEX HEX | '\U' HEX HEX HEX HEX HEX HEX HEX HEX
+func readUChar(lex *lexer.Lexer) (rune, bool) {
 	l := lex.InputToEnd()
 
-	if isIri(l) {
-		lex.State = LexIri(lex)
-	} else if isBlankNode(l) {
-		lex.State = LexBlankNode(lex)
-	} else if isCollection(l) {
-		lex.State = LexCollection(lex)
-	} else if isBlanknodePropertyList(l) {
-		lex.State = LexBlankNodePropertyList(lex)
-	} else if isLiteral(l) {
-		lex.State = LexLiteral(lex)
-	} else {
-		lex.State = lex.Errorf("invalid input passed to LexObject")
+	size := 4
+	if l[1] == 'U' {
+		size = 8
 	}
 
-	return lex.State
-}
+	if len(l) < size+2 {
+		return 0, false
+	}
 
-func LexLiteral(lex *lexer.Lexer) lexer.LexFn {
-	return lex.Errorf("literal unimplemented")
-}
+	var r rune
+	for _, c := range l[2 : size+2] {
+		v, ok := hexValue(c)
+		if !ok {
+			return 0, false
+		}
 
-func LexBlankNodePropertyList(lex *lexer.Lexer) lexer.LexFn {
-	return lex.Errorf("blankNodePropertyList unimplemented")
-}
+		r = r<<4 | v
+	}
 
-func LexCollection(lex *lexer.Lexer) lexer.LexFn {
-	return lex.Errorf("collection unimplemented")
-}
+	lex.Pos += size + 2
 
-func LexNumericLiteral(lex *lexer.Lexer) lexer.LexFn {
-	return lex.Errorf("NumericLiteral unimplemented")
+	return r, true
 }
 
-func LexRDFLiteral(lex *lexer.Lexer) lexer.LexFn {
-	return lex.Errorf("RDFLiteral unimplemented")
-}
+func hexValue(c rune) (rune, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
 
-func LexBooleanLiteral(lex *lexer.Lexer) lexer.LexFn {
-	return lex.Errorf("BooleanLiteral unimplemented")
+	return 0, false
 }
 
-func LexString(lex *lexer.Lexer) lexer.LexFn {
-	return lex.Errorf("String unimplemented")
+func acceptDigits(lex *lexer.Lexer) int {
+	n := 0
+	for !lex.IsEOF() && unicode.IsDigit(lex.Peek()) {
+		lex.Next()
+		n++
+	}
+
+	return n
 }
 
-func LexIri(lex *lexer.Lexer) lexer.LexFn {
-	if isIriRef(lex.InputToEnd()) {
-		lex.State = LexIriRef(lex)
-	} else if isPrefixedName(lex.InputToEnd()) {
-		lex.State = LexPrefixedName(lex)
-	} else {
-		lex.State = lex.Errorf("input to LexIRI was not an RDF IRI")
+func startsNumber(s string) bool {
+	if s == "" {
+		return false
 	}
 
-	return lex.State
-}
+	if s[0] == '+' || s[0] == '-' {
+		s = s[1:]
+	}
 
-func LexPrefixedName(lex *lexer.Lexer) lexer.LexFn {
-	return lex.Errorf("PrefixedName unimplemented")
-}
+	if strings.HasPrefix(s, ".") {
+		s = s[1:]
+	}
 
-func LexBlankNode(lex *lexer.Lexer) lexer.LexFn {
-	return lex.Errorf("BlankNode unimplemented")
+	return s != "" && s[0] >= '0' && s[0] <= '9'
 }
 
-func LexIriRef(lex *lexer.Lexer) lexer.LexFn {
-	lex.SkipWhitespace()
-	lex.Pos += len(lexertoken.START_IRI)
-	lex.Ignore()
+// isKeyword checks the input starts with the keyword as a whole word
+func isKeyword(s, keyword string, foldCase bool) bool {
+	if len(s) < len(keyword) {
+		return false
+	}
 
-	for {
-		if strings.HasPrefix(lex.InputToEnd(), lexertoken.END_IRI) {
-			lex.Emit(lexertoken.TOKEN_IRIREF)
-			lex.Pos += len(lexertoken.END_IRI)
-			lex.Ignore()
+	if foldCase && !strings.EqualFold(s[:len(keyword)], keyword) ||
+		!foldCase && s[:len(keyword)] != keyword {
+		return false
+	}
 
-			return LexStatement
-		}
+	if len(s) == len(keyword) {
+		return true
+	}
 
-		lex.Inc()
+	next := rune(s[len(keyword)])
 
-		if lex.IsEOF() {
-			return lex.Errorf(LEXER_ERROR_UNEXPECTED_EOF)
-		}
+	return !isPnChar(next) && next != ':'
+}
+
+func isPrefixedNameStart(s string) bool {
+	return strings.HasPrefix(s, lexertoken.PREFIX_END) || isPNPrefix(s)
+}
+
+// PN_CHARS, a close enough match of the unicode ranges in the grammar
+func isPnChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' || r == 0xB7
+}
+
+func firstWord(s string) string {
+	if i := strings.IndexFunc(s, unicode.IsSpace); i > 0 {
+		return s[:i]
 	}
+
+	return s
 }
@@ -0,0 +1,325 @@
+// Package n3patch reads and applies N3 Patch documents, the text/n3 PATCH
+// bodies of the Solid Protocol
+//
+// https://solidproject.org/TR/protocol#n3-patch
+package n3patch
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/parser"
+	"github.com/b1scuit/solid/rdf/vocab/solid"
+)
+
+var (
+	// ErrInvalid is a patch that breaks the rules of N3 Patch, which a
+	// server answers with 422 Unprocessable Entity
+	ErrInvalid = errors.New("n3patch: invalid patch")
+
+	// ErrConflict is a patch that can't be applied to the document, which
+	// a server answers with 409 Conflict
+	ErrConflict = errors.New("n3patch: patch doesn't apply")
+)
+
+// A Patch deletes and inserts triples for the one binding of the variables
+// in Where found in the document. Missing formulae are empty
+type Patch struct {
+	Where   rdf.Formula
+	Inserts rdf.Formula
+	Deletes rdf.Formula
+}
+
+// Parse reads an N3 Patch document, relative IRIs are resolved against the
+// base, usually the IRI of the document being patched
+func Parse(r io.Reader, base string) (*Patch, error) {
+	p, err := parser.New(parser.WithN3(), parser.WithBase(base))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.Do(r); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	return FromGraph(p.GetGraph())
+}
+
+// FromGraph finds the patch resource in a parsed N3 document and checks it
+// follows the rules for one
+func FromGraph(g *rdf.Graph) (*Patch, error) {
+	resources := g.Match(nil, rdf.RDFType, solid.InsertDeletePatch)
+
+	switch len(resources) {
+	case 0:
+		return nil, fmt.Errorf("%w: no %v", ErrInvalid, solid.InsertDeletePatch)
+	case 1:
+	default:
+		return nil, fmt.Errorf("%w: more than one patch resource", ErrInvalid)
+	}
+
+	node := resources[0].Subject
+	p := &Patch{}
+
+	for _, f := range []struct {
+		predicate rdf.IRI
+		formula   *rdf.Formula
+	}{
+		{solid.Where, &p.Where},
+		{solid.Inserts, &p.Inserts},
+		{solid.Deletes, &p.Deletes},
+	} {
+		objects := g.Match(node, f.predicate, nil)
+
+		if len(objects) > 1 {
+			return nil, fmt.Errorf("%w: more than one %v", ErrInvalid, f.predicate)
+		}
+
+		if len(objects) == 0 {
+			continue
+		}
+
+		formula, ok := objects[0].Object.(rdf.Formula)
+		if !ok {
+			return nil, fmt.Errorf("%w: %v isn't a formula", ErrInvalid, f.predicate)
+		}
+
+		for _, t := range formula {
+			for _, term := range t.Terms() {
+				if term.Kind() == rdf.TermFormula {
+					return nil, fmt.Errorf("%w: %v has a nested formula", ErrInvalid, f.predicate)
+				}
+			}
+		}
+
+		*f.formula = formula
+	}
+
+	return p, p.check()
+}
+
+// String writes the patch as an N3 Patch document, the body of a text/n3
+// PATCH request. Empty formulae are left out
+func (p *Patch) String() string {
+	var b strings.Builder
+
+	b.WriteString("_:patch " + rdf.RDFType.String() + " " + solid.InsertDeletePatch.String())
+
+	for _, f := range []struct {
+		predicate rdf.IRI
+		formula   rdf.Formula
+	}{
+		{solid.Where, p.Where},
+		{solid.Inserts, p.Inserts},
+		{solid.Deletes, p.Deletes},
+	} {
+		if len(f.formula) > 0 {
+			b.WriteString(" ;\n  " + f.predicate.String() + " " + f.formula.String())
+		}
+	}
+
+	b.WriteString(" .\n")
+
+	return b.String()
+}
+
+// check enforces what N3 Patch asks of the formulae, deletes and where
+// can't have blank nodes, and inserts and deletes can only use the
+// variables that where binds
+func (p *Patch) check() error {
+	if len(rdf.BlankNodesOf(p.Deletes)) > 0 {
+		return fmt.Errorf("%w: deletes has blank nodes", ErrInvalid)
+	}
+
+	if len(rdf.BlankNodesOf(p.Where)) > 0 {
+		return fmt.Errorf("%w: where has blank nodes", ErrInvalid)
+	}
+
+	bound := make(map[rdf.Variable]bool)
+	for _, v := range variables(p.Where) {
+		bound[v] = true
+	}
+
+	for name, f := range map[string]rdf.Formula{"inserts": p.Inserts, "deletes": p.Deletes} {
+		for _, v := range variables(f) {
+			if !bound[v] {
+				return fmt.Errorf("%w: %v uses %v, which where doesn't bind", ErrInvalid, name, v)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Apply patches the graph, which is left as it was when an error is
+// returned. Where has to match exactly once, and every triple to delete
+// has to be in the graph
+func (p *Patch) Apply(g *rdf.Graph) error {
+	solutions := match(g, p.Where, binding{}, nil)
+
+	switch len(solutions) {
+	case 0:
+		return fmt.Errorf("%w: where doesn't match", ErrConflict)
+	case 1:
+	default:
+		return fmt.Errorf("%w: where matches more than once", ErrConflict)
+	}
+
+	b := solutions[0]
+
+	var deletes []rdf.Triple
+	for _, t := range p.Deletes {
+		t = b.apply(t)
+
+		if !g.Has(t) {
+			return fmt.Errorf("%w: %v isn't in the document to delete", ErrConflict, t)
+		}
+
+		deletes = append(deletes, t)
+	}
+
+	for _, t := range deletes {
+		g.Remove(t)
+	}
+
+	// Each blank node in inserts is a new node in the document
+	fresh := newBlankNodes(g)
+
+	for _, t := range p.Inserts {
+		t = b.apply(t)
+
+		var terms [3]rdf.Term
+		for i, term := range t.Terms() {
+			if bnode, ok := term.(rdf.BlankNode); ok {
+				term = fresh(bnode)
+			}
+
+			terms[i] = term
+		}
+
+		g.Add(rdf.NewTriple(terms[0], terms[1], terms[2]))
+	}
+
+	return nil
+}
+
+type binding map[rdf.Variable]rdf.Term
+
+func (b binding) term(t rdf.Term) rdf.Term {
+	if v, ok := t.(rdf.Variable); ok {
+		if value, ok := b[v]; ok {
+			return value
+		}
+	}
+
+	return t
+}
+
+func (b binding) apply(t rdf.Triple) rdf.Triple {
+	return rdf.NewTriple(b.term(t.Subject), b.term(t.Predicate), b.term(t.Object))
+}
+
+// match finds the bindings under which every pattern is in the graph. It
+// stops once there are two, as more than one is already a conflict
+func match(g *rdf.Graph, patterns []rdf.Triple, b binding, found []binding) []binding {
+	if len(patterns) == 0 {
+		return append(found, b)
+	}
+
+	pattern := b.apply(patterns[0])
+
+	wildcard := func(t rdf.Term) rdf.Term {
+		if t.Kind() == rdf.TermVariable {
+			return nil
+		}
+
+		return t
+	}
+
+	for _, t := range g.Match(wildcard(pattern.Subject), wildcard(pattern.Predicate), wildcard(pattern.Object)) {
+		next, ok := b.extend(pattern, t)
+		if !ok {
+			continue
+		}
+
+		if found = match(g, patterns[1:], next, found); len(found) > 1 {
+			return found
+		}
+	}
+
+	return found
+}
+
+// extend binds the variables of a pattern to the terms of a triple, failing
+// when a variable used twice would need two values
+func (b binding) extend(pattern, t rdf.Triple) (binding, bool) {
+	next := make(binding, len(b)+3)
+	for k, v := range b {
+		next[k] = v
+	}
+
+	terms := t.Terms()
+
+	for i, term := range pattern.Terms() {
+		v, ok := term.(rdf.Variable)
+		if !ok {
+			continue
+		}
+
+		if value, ok := next[v]; ok && !rdf.Equal(value, terms[i]) {
+			return nil, false
+		}
+
+		next[v] = terms[i]
+	}
+
+	return next, true
+}
+
+func variables(f rdf.Formula) []rdf.Variable {
+	var vars []rdf.Variable
+
+	for _, t := range f {
+		for _, term := range t.Terms() {
+			if v, ok := term.(rdf.Variable); ok {
+				vars = append(vars, v)
+			}
+		}
+	}
+
+	return vars
+}
+
+// newBlankNodes maps the blank nodes of a patch to ones whose labels
+// aren't used in the graph
+func newBlankNodes(g *rdf.Graph) func(rdf.BlankNode) rdf.BlankNode {
+	used := make(map[rdf.BlankNode]bool)
+	for _, b := range g.BlankNodes() {
+		used[b] = true
+	}
+
+	mapped := make(map[rdf.BlankNode]rdf.BlankNode)
+	n := 0
+
+	return func(b rdf.BlankNode) rdf.BlankNode {
+		if m, ok := mapped[b]; ok {
+			return m
+		}
+
+		for {
+			n++
+			label := rdf.BlankNode("b" + strconv.Itoa(n))
+
+			if !used[label] {
+				used[label] = true
+				mapped[b] = label
+
+				return label
+			}
+		}
+	}
+}
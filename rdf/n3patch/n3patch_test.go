@@ -0,0 +1,125 @@
+package n3patch
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/parser"
+)
+
+const testPrefixes = `
+@prefix solid: <http://www.w3.org/ns/solid/terms#> .
+@prefix ex: <http://example.org/> .
+`
+
+const testDocument = testPrefixes + `
+ex:alice ex:name "Alice" ; ex:age 30 ; ex:knows ex:bob, ex:carol .
+ex:bob ex:name "Bob" .
+ex:carol ex:name "Carol" .
+`
+
+func testGraph(t *testing.T, doc string) *rdf.Graph {
+	p := parser.MustNew()
+
+	if err := p.Do(strings.NewReader(doc)); err != nil {
+		t.Fatalf("parsing test graph: %v", err)
+	}
+
+	return p.GetGraph()
+}
+
+type patchTest struct {
+	Name  string
+	Patch string
+
+	// The document after the patch, or the error it fails with
+	Expected string
+	Err      error
+}
+
+var patchTests = []patchTest{
+	{"Insert and delete",
+		`_:p a solid:InsertDeletePatch ;
+			solid:where { ?p ex:name "Alice" ; ex:age ?age } ;
+			solid:deletes { ?p ex:age ?age } ;
+			solid:inserts { ?p ex:age 31 } .`,
+		`ex:alice ex:name "Alice" ; ex:age 31 ; ex:knows ex:bob, ex:carol .
+		ex:bob ex:name "Bob" . ex:carol ex:name "Carol" .`,
+		nil},
+	{"Insert only, with a new blank node",
+		`<#patch> a solid:InsertDeletePatch ; solid:inserts { ex:dave ex:name "Dave" ; ex:address [ ex:city "Leeds" ] } .`,
+		`ex:alice ex:name "Alice" ; ex:age 30 ; ex:knows ex:bob, ex:carol .
+		ex:bob ex:name "Bob" . ex:carol ex:name "Carol" .
+		ex:dave ex:name "Dave" ; ex:address [ ex:city "Leeds" ] .`,
+		nil},
+	{"Where matches nothing",
+		`_:p a solid:InsertDeletePatch ; solid:where { ?p ex:name "Zoe" } ; solid:inserts { ?p ex:age 1 } .`,
+		``, ErrConflict},
+	{"Where matches more than once",
+		`_:p a solid:InsertDeletePatch ; solid:where { ex:alice ex:knows ?friend } ; solid:deletes { ex:alice ex:knows ?friend } .`,
+		``, ErrConflict},
+	{"Deleting a missing triple",
+		`_:p a solid:InsertDeletePatch ; solid:deletes { ex:alice ex:age 40 } ; solid:inserts { ex:alice ex:age 41 } .`,
+		``, ErrConflict},
+	{"No patch resource",
+		`_:p solid:inserts { ex:a ex:b ex:c } .`,
+		``, ErrInvalid},
+	{"Two inserts",
+		`_:p a solid:InsertDeletePatch ; solid:inserts { ex:a ex:b ex:c }, { ex:a ex:b ex:d } .`,
+		``, ErrInvalid},
+	{"Unbound variable",
+		`_:p a solid:InsertDeletePatch ; solid:where { ?a ex:name "Bob" } ; solid:inserts { ?b ex:age 1 } .`,
+		``, ErrInvalid},
+	{"Blank node in deletes",
+		`_:p a solid:InsertDeletePatch ; solid:deletes { ex:alice ex:knows [] } .`,
+		``, ErrInvalid},
+	{"Nested formula",
+		`_:p a solid:InsertDeletePatch ; solid:inserts { ex:a ex:b { ex:c ex:d ex:e } } .`,
+		``, ErrInvalid},
+	{"Not a formula",
+		`_:p a solid:InsertDeletePatch ; solid:inserts ex:a .`,
+		``, ErrInvalid},
+	{"Not N3",
+		`_:p a solid:InsertDeletePatch ; solid:inserts { ex:a ex:b `,
+		``, ErrInvalid},
+}
+
+func TestApply(t *testing.T) {
+	for _, tc := range patchTests {
+		g := testGraph(t, testDocument)
+
+		p, err := Parse(strings.NewReader(testPrefixes+tc.Patch), "http://example.org/doc")
+		if err == nil {
+			// Written out and read back the patch is the same
+			written, werr := Parse(strings.NewReader(p.String()), "")
+			if werr != nil || written.Where.String() != p.Where.String() || written.Inserts.String() != p.Inserts.String() || written.Deletes.String() != p.Deletes.String() {
+				t.Errorf("%v test fail: wrote %v, read back %v, %v", tc.Name, p, written, werr)
+			}
+
+			err = p.Apply(g)
+		}
+
+		if tc.Err != nil {
+			if !errors.Is(err, tc.Err) {
+				t.Errorf("%v test fail: expected %v, got %v", tc.Name, tc.Err, err)
+			}
+
+			if !rdf.Isomorphic(g, testGraph(t, testDocument)) {
+				t.Errorf("%v test fail: failed patch changed the document", tc.Name)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%v test fail: %v", tc.Name, err)
+			continue
+		}
+
+		if !rdf.Isomorphic(g, testGraph(t, testPrefixes+tc.Expected)) {
+			t.Errorf("%v test fail: got %v", tc.Name, g.Triples())
+		}
+	}
+}
@@ -4,13 +4,17 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
-	"strings"
 
+	"github.com/b1scuit/solid/rdf"
 	"github.com/b1scuit/solid/rdf/lexer"
 	"github.com/b1scuit/solid/rdf/lexer/lexertoken"
 	"github.com/b1scuit/solid/rdf/lexer/lexfn"
 )
 
+// LogImplies is the predicate N3 writes as =>, linking the formulae of a
+// rule
+const LogImplies rdf.IRI = "http://www.w3.org/2000/10/swap/log#implies"
+
 type Lexeror interface {
 	SetInput(string)
 	NextToken() chan lexertoken.Token
@@ -25,24 +29,76 @@ func WithLexeror(le Lexeror) ClientOption {
 	}
 }
 
+// WithBase sets the IRI relative IRIs in the document are resolved against,
+// usually the location the document was fetched from
+func WithBase(base string) ClientOption {
+	return func(c *Client) {
+		c.base = base
+	}
+}
+
+// WithPrefixes declares prefixes before the document is read, so input
+// that leaves out its own declarations, like a CLI argument, can use them
+func WithPrefixes(prefixes rdf.PrefixMap) ClientOption {
+	return func(c *Client) {
+		c.prefixes.Merge(prefixes)
+	}
+}
+
+// WithStrictPrefixes makes a document that rebinds a prefix to a different
+// IRI an error. Turtle allows it, the later declaration wins, but it is
+// usually a mistake
+func WithStrictPrefixes() ClientOption {
+	return func(c *Client) {
+		c.strictPrefixes = true
+	}
+}
+
+// WithN3 reads Notation3 instead of Turtle, adding formulae, variables
+// and the => <= and = shorthands
+//
+// https://w3c.github.io/N3/spec/
+func WithN3() ClientOption {
+	return func(c *Client) {
+		c.n3 = true
+	}
+}
+
 type Client struct {
 	l       Lexeror
 	lexemes []lexertoken.Token
 
-	prefixMap map[string]lexertoken.Token
+	prefixes       rdf.PrefixMap
+	strictPrefixes bool
+	n3             bool
+
+	base  string
+	graph *rdf.Graph
+
+	// Parser state while walking the lexemes
+	pos        int
+	blankNodes int
+	labels     map[string]struct{}
 }
 
 func New(opts ...ClientOption) (*Client, error) {
-	c := &Client{}
+	c := &Client{
+		prefixes: make(rdf.PrefixMap),
+	}
 
 	for _, f := range opts {
 		f(c)
 	}
 
 	if c.l == nil {
+		state := lexfn.LexTurtleDoc
+		if c.n3 {
+			state = lexfn.LexN3
+		}
+
 		lex, err := lexer.New(
 			lexer.WihInitalState(
-				lexfn.LexTurtleDoc,
+				state,
 			),
 		)
 
@@ -73,21 +129,29 @@ func (c *Client) Do(file io.Reader) error {
 	}
 
 	c.l.SetInput(string(b))
+	c.lexemes = nil
 
 	if err := c.CollectTokens(); err != nil {
 		slog.Error("Error in collecting tokens", slog.Any("error", err))
-		//return err
+		return err
 	}
 
-	c.ParsePrefixes()
-
-	//c.SwapPrefixForIRI()
+	return c.ParseTriples()
+}
 
-	return nil
+// GetGraph returns the triples parsed by Do, along with the prefixes the
+// document declared
+func (c *Client) GetGraph() *rdf.Graph {
+	return c.graph
 }
 
-func (c *Client) GetPrefixMap() map[string]lexertoken.Token {
-	return c.prefixMap
+// GetPrefixMap returns the prefixes in scope at the end of the document
+func (c *Client) GetPrefixMap() rdf.PrefixMap {
+	if c.graph == nil {
+		return c.prefixes
+	}
+
+	return c.graph.Prefixes
 }
 
 func (c *Client) GetLexemes() []lexertoken.Token {
@@ -112,37 +176,4 @@ func (c *Client) CollectTokens() error {
 	return nil
 }
 
-func (c *Client) ParsePrefixes() {
-	if c.prefixMap == nil {
-		c.prefixMap = make(map[string]lexertoken.Token)
-	}
-
-	// Loop through the tokens
-	// if we find a prefix, we know 100% the next token is the IRI for the prefix
-	// add that in and skip processing it
-	for i := 0; i < len(c.lexemes); i++ {
-		if c.lexemes[i].Type == lexertoken.TOKEN_PREFIX_NAME {
-			c.prefixMap[c.lexemes[i].Value] = c.lexemes[i+1]
-			i = i + 1
-		}
-	}
-}
-
-func (c *Client) SwapPrefixForIRI() {
-	for i := 0; i < len(c.lexemes); i++ {
-
-		if c.lexemes[i].Type == lexertoken.TOKEN_PREFIX_NAME {
-			// Does this match anything in the prefix table
-			prefix := strings.Split(c.lexemes[i].Value, ":")
-
-			if iri, ok := c.prefixMap[prefix[0]]; ok {
-				c.lexemes[i] = lexertoken.Token{
-					Type:  lexertoken.TOKEN_IRI,
-					Value: strings.Replace(c.lexemes[i].Value, prefix[0]+":", iri.Value, -1),
-				}
-			}
-		}
-	}
-}
-
 func (c *Client) FlattenPrefixObjectLists() {}
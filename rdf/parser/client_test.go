@@ -0,0 +1,186 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/b1scuit/solid/rdf"
+)
+
+type parseTest struct {
+	Name     string
+	Input    string
+	Expected []rdf.Triple
+}
+
+var (
+	alice = rdf.IRI("http://example.org/alice")
+	foaf  = func(s string) rdf.IRI { return rdf.IRI("http://xmlns.com/foaf/0.1/" + s) }
+
+	parseTests = []parseTest{
+		{
+			"Prefixes and a",
+			"@prefix foaf: <http://xmlns.com/foaf/0.1/> .\n<http://example.org/alice> a foaf:Person .",
+			[]rdf.Triple{rdf.NewTriple(alice, rdf.RDFType, foaf("Person"))},
+		},
+		{
+			"SPARQL prefix and base",
+			"BASE <http://example.org/>\nPREFIX foaf: <http://xmlns.com/foaf/0.1/>\n<alice> foaf:name \"Alice\"@en, 'Al' .",
+			[]rdf.Triple{
+				rdf.NewTriple(alice, foaf("name"), rdf.NewLangLiteral("Alice", "en")),
+				rdf.NewTriple(alice, foaf("name"), rdf.NewLiteral("Al")),
+			},
+		},
+		{
+			"Numbers, booleans and comments",
+			"<http://example.org/alice> <http://example.org/n> 1, -2.5, 1e3, true . # done",
+			[]rdf.Triple{
+				rdf.NewTriple(alice, rdf.IRI("http://example.org/n"), rdf.NewTypedLiteral("1", rdf.XSDInteger)),
+				rdf.NewTriple(alice, rdf.IRI("http://example.org/n"), rdf.NewTypedLiteral("-2.5", rdf.XSDDecimal)),
+				rdf.NewTriple(alice, rdf.IRI("http://example.org/n"), rdf.NewTypedLiteral("1e3", rdf.XSDDouble)),
+				rdf.NewTriple(alice, rdf.IRI("http://example.org/n"), rdf.NewTypedLiteral("true", rdf.XSDBoolean)),
+			},
+		},
+		{
+			"Blank node property list",
+			"<http://example.org/alice> <http://xmlns.com/foaf/0.1/knows> [ <http://xmlns.com/foaf/0.1/name> \"Bob\\n\" ] .",
+			[]rdf.Triple{
+				rdf.NewTriple(alice, foaf("knows"), rdf.BlankNode("b1")),
+				rdf.NewTriple(rdf.BlankNode("b1"), foaf("name"), rdf.NewLiteral("Bob\n")),
+			},
+		},
+		{
+			"Collection",
+			"<http://example.org/alice> <http://example.org/l> ( 1 ) .",
+			[]rdf.Triple{
+				rdf.NewTriple(alice, rdf.IRI("http://example.org/l"), rdf.BlankNode("b1")),
+				rdf.NewTriple(rdf.BlankNode("b1"), rdf.RDFFirst, rdf.NewTypedLiteral("1", rdf.XSDInteger)),
+				rdf.NewTriple(rdf.BlankNode("b1"), rdf.RDFRest, rdf.RDFNil),
+			},
+		},
+		{
+			"Quoted triple with annotation",
+			"PREFIX : <http://example.org/>\n:alice :knows :bob {| :source << _:s :p 1 >> |} .",
+			[]rdf.Triple{
+				rdf.NewTriple(alice, rdf.IRI("http://example.org/knows"), rdf.IRI("http://example.org/bob")),
+				rdf.NewTriple(
+					rdf.QuotedTriple{Subject: alice, Predicate: rdf.IRI("http://example.org/knows"), Object: rdf.IRI("http://example.org/bob")},
+					rdf.IRI("http://example.org/source"),
+					rdf.QuotedTriple{Subject: rdf.BlankNode("x"), Predicate: rdf.IRI("http://example.org/p"), Object: rdf.NewTypedLiteral("1", rdf.XSDInteger)},
+				),
+			},
+		},
+	}
+)
+
+func TestParseTriples(t *testing.T) {
+	for _, tc := range parseTests {
+		p := MustNew()
+
+		if err := p.Do(strings.NewReader(tc.Input)); err != nil {
+			t.Errorf("%v test fail: %v", tc.Name, err)
+			continue
+		}
+
+		if !rdf.Isomorphic(p.GetGraph(), rdf.NewGraph(tc.Expected...)) {
+			t.Errorf("%v test fail: got %v", tc.Name, p.GetGraph().Triples())
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, input := range []string{
+		"ex:a ex:b ex:c .",
+		"<http://example.org/a> <http://example.org/b> .",
+		"<http://example.org/a> <http://example.org/b> \"unterminated",
+	} {
+		if err := MustNew().Do(strings.NewReader(input)); err == nil {
+			t.Errorf("expected error parsing %q", input)
+		}
+	}
+}
+
+func TestParsePrefixRedefinition(t *testing.T) {
+	input := "@prefix ex: <http://example.org/> .\n@prefix ex: <http://example.com/> .\nex:a ex:b ex:c ."
+
+	p := MustNew()
+	if err := p.Do(strings.NewReader(input)); err != nil {
+		t.Errorf("redefinition should be allowed: %v", err)
+	}
+
+	if p.GetPrefixMap()["ex"] != "http://example.com/" {
+		t.Errorf("later declaration should win, got %v", p.GetPrefixMap()["ex"])
+	}
+
+	if err := MustNew(WithStrictPrefixes()).Do(strings.NewReader(input)); err == nil {
+		t.Errorf("strict client should reject the redefinition")
+	}
+
+	p = MustNew(WithPrefixes(rdf.PrefixMap{"ex": "http://example.org/"}))
+	if err := p.Do(strings.NewReader("ex:a ex:b ex:c .")); err != nil || p.GetGraph().Len() != 1 {
+		t.Errorf("predeclared prefix not used: %v", err)
+	}
+}
+
+var (
+	ex = func(s string) rdf.IRI { return rdf.IRI("http://example.org/" + s) }
+
+	n3Tests = []parseTest{
+		{
+			"Rule",
+			"@prefix : <http://example.org/> .\n{ ?x :parent ?y . ?y :parent ?z } => { ?x :grandparent ?z } .",
+			[]rdf.Triple{rdf.NewTriple(
+				rdf.Formula{
+					rdf.NewTriple(rdf.Variable("x"), ex("parent"), rdf.Variable("y")),
+					rdf.NewTriple(rdf.Variable("y"), ex("parent"), rdf.Variable("z")),
+				},
+				LogImplies,
+				rdf.Formula{rdf.NewTriple(rdf.Variable("x"), ex("grandparent"), rdf.Variable("z"))},
+			)},
+		},
+		{
+			"Implied by and same as",
+			"PREFIX : <http://example.org/>\n{ :a :p :b . } <= {} .\n:alice = :alicia ; ?p 1 .",
+			[]rdf.Triple{
+				rdf.NewTriple(rdf.Formula{}, LogImplies, rdf.Formula{rdf.NewTriple(ex("a"), ex("p"), ex("b"))}),
+				rdf.NewTriple(alice, rdf.IRI("http://www.w3.org/2002/07/owl#sameAs"), ex("alicia")),
+				rdf.NewTriple(alice, rdf.Variable("p"), rdf.NewTypedLiteral("1", rdf.XSDInteger)),
+			},
+		},
+		{
+			"Patch",
+			"@prefix solid: <http://www.w3.org/ns/solid/terms#> .\n@prefix : <http://example.org/> .\n" +
+				"_:patch a solid:InsertDeletePatch ; solid:where { ?a :knows [ :name \"Bob\" ] } ; solid:inserts { ?a :age 30 } .",
+			[]rdf.Triple{
+				rdf.NewTriple(rdf.BlankNode("patch"), rdf.RDFType, rdf.IRI("http://www.w3.org/ns/solid/terms#InsertDeletePatch")),
+				rdf.NewTriple(rdf.BlankNode("patch"), rdf.IRI("http://www.w3.org/ns/solid/terms#where"), rdf.Formula{
+					rdf.NewTriple(rdf.Variable("a"), ex("knows"), rdf.BlankNode("b1")),
+					rdf.NewTriple(rdf.BlankNode("b1"), ex("name"), rdf.NewLiteral("Bob")),
+				}),
+				rdf.NewTriple(rdf.BlankNode("patch"), rdf.IRI("http://www.w3.org/ns/solid/terms#inserts"), rdf.Formula{
+					rdf.NewTriple(rdf.Variable("a"), ex("age"), rdf.NewTypedLiteral("30", rdf.XSDInteger)),
+				}),
+			},
+		},
+	}
+)
+
+func TestParseN3(t *testing.T) {
+	for _, tc := range n3Tests {
+		p := MustNew(WithN3())
+
+		if err := p.Do(strings.NewReader(tc.Input)); err != nil {
+			t.Errorf("%v test fail: %v", tc.Name, err)
+			continue
+		}
+
+		if !rdf.Isomorphic(p.GetGraph(), rdf.NewGraph(tc.Expected...)) {
+			t.Errorf("%v test fail: got %v", tc.Name, p.GetGraph().Triples())
+		}
+
+		// None of it is Turtle
+		if err := MustNew().Do(strings.NewReader(tc.Input)); err == nil {
+			t.Errorf("%v test fail: parsed as Turtle", tc.Name)
+		}
+	}
+}
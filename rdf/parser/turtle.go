@@ -0,0 +1,561 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/lexer/lexertoken"
+	"github.com/b1scuit/solid/rdf/vocab/owl"
+)
+
+// ParseTriples walks the collected lexemes following the Turtle grammar
+// and builds the graph they describe
+//
+// https://www.w3.org/TR/2014/REC-turtle-20140225/#sec-grammar-grammar
+func (c *Client) ParseTriples() error {
+	c.graph = rdf.NewGraph()
+	c.graph.Prefixes = c.prefixes.Clone()
+	c.pos = 0
+	c.blankNodes = 0
+	c.labels = make(map[string]struct{})
+
+	for _, t := range c.lexemes {
+		if t.Type == lexertoken.TOKEN_BLANK_NODE {
+			c.labels[t.Value] = struct{}{}
+		}
+	}
+
+	for c.peek().Type != lexertoken.TOKEN_EOF {
+		if err := c.parseStatement(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// statement	::=	directive | triples '.'
+func (c *Client) parseStatement() error {
+	switch t := c.peek(); t.Type {
+	case lexertoken.TOKEN_PREFIX:
+		c.next()
+
+		name, err := c.expect(lexertoken.TOKEN_PREFIX_NAME)
+		if err != nil {
+			return err
+		}
+
+		iri, err := c.expect(lexertoken.TOKEN_IRIREF)
+		if err != nil {
+			return err
+		}
+
+		if err := c.declarePrefix(name.Value, c.resolve(iri.Value)); err != nil {
+			return err
+		}
+
+		if t.Value == lexertoken.PREFIX {
+			_, err = c.expect(lexertoken.TOKEN_END_TRIPLE)
+		}
+
+		return err
+	case lexertoken.TOKEN_BASE:
+		c.next()
+
+		iri, err := c.expect(lexertoken.TOKEN_IRIREF)
+		if err != nil {
+			return err
+		}
+
+		c.base = string(c.resolve(iri.Value))
+
+		if t.Value == lexertoken.BASE {
+			_, err = c.expect(lexertoken.TOKEN_END_TRIPLE)
+		}
+
+		return err
+	}
+
+	if err := c.parseTriples(); err != nil {
+		return err
+	}
+
+	_, err := c.expect(lexertoken.TOKEN_END_TRIPLE)
+
+	return err
+}
+
+// triples	::=	subject predicateObjectList | blankNodePropertyList predicateObjectList?
+func (c *Client) parseTriples() error {
+	if c.peek().Type == lexertoken.TOKEN_START_BLANK_NODE_PROPERTY_LIST {
+		subject, err := c.parseBlankNodePropertyList()
+		if err != nil {
+			return err
+		}
+
+		if c.peek().Type == lexertoken.TOKEN_END_TRIPLE {
+			return nil
+		}
+
+		return c.parsePredicateObjectList(subject)
+	}
+
+	subject, err := c.parseSubject()
+	if err != nil {
+		return err
+	}
+
+	return c.parsePredicateObjectList(subject)
+}
+
+// subject	::=	iri | BlankNode | collection | quotedTriple
+func (c *Client) parseSubject() (rdf.Term, error) {
+	switch t := c.peek(); t.Type {
+	case lexertoken.TOKEN_START_QUOTED_TRIPLE:
+		return c.parseQuotedTriple()
+	case lexertoken.TOKEN_IRIREF, lexertoken.TOKEN_PREFIXED_NAME:
+		return c.parseIri()
+	case lexertoken.TOKEN_BLANK_NODE:
+		c.next()
+		return rdf.BlankNode(t.Value), nil
+	case lexertoken.TOKEN_START_COLLECTION:
+		return c.parseCollection()
+	case lexertoken.TOKEN_START_GROUP:
+		return c.parseFormula()
+	case lexertoken.TOKEN_VAR:
+		c.next()
+		return rdf.Variable(t.Value), nil
+	default:
+		return nil, c.unexpected(t, "subject")
+	}
+}
+
+// predicateObjectList	::=	verb objectList (';' (verb objectList)?)*
+func (c *Client) parsePredicateObjectList(subject rdf.Term) error {
+	for {
+		// N3's <= swaps the subject and object of log:implies
+		reverse := c.acceptOperator(lexertoken.IMPLIED_BY)
+
+		verb := rdf.Term(LogImplies)
+		if !reverse {
+			var err error
+			if verb, err = c.parseVerb(); err != nil {
+				return err
+			}
+		}
+
+		if err := c.parseObjectList(subject, verb, reverse); err != nil {
+			return err
+		}
+
+		if c.peek().Type != lexertoken.TOKEN_OBJECT_LIST {
+			return nil
+		}
+
+		for c.peek().Type == lexertoken.TOKEN_OBJECT_LIST {
+			c.next()
+		}
+
+		switch c.peek().Type {
+		case lexertoken.TOKEN_PREDICATE, lexertoken.TOKEN_IRIREF, lexertoken.TOKEN_PREFIXED_NAME,
+			lexertoken.TOKEN_VAR, lexertoken.TOKEN_OPERATOR:
+		default:
+			return nil
+		}
+	}
+}
+
+// objectList	::=	object annotation? (',' object annotation?)*
+//
+// With reverse set the objects are the subjects of the triples
+func (c *Client) parseObjectList(subject, predicate rdf.Term, reverse bool) error {
+	for {
+		object, err := c.parseObject()
+		if err != nil {
+			return err
+		}
+
+		triple := rdf.NewTriple(subject, predicate, object)
+		if reverse {
+			triple = rdf.NewTriple(object, predicate, subject)
+		}
+
+		c.graph.Add(triple)
+
+		if c.peek().Type == lexertoken.TOKEN_START_ANNOTATION {
+			if err := c.parseAnnotation(rdf.Quote(triple)); err != nil {
+				return err
+			}
+		}
+
+		if c.peek().Type != lexertoken.TOKEN_OBJECT {
+			return nil
+		}
+
+		c.next()
+	}
+}
+
+// verb	::=	predicate | 'a'
+//
+// N3 adds variables, => for log:implies and = for owl:sameAs
+func (c *Client) parseVerb() (rdf.Term, error) {
+	switch t := c.peek(); t.Type {
+	case lexertoken.TOKEN_PREDICATE:
+		c.next()
+		return rdf.RDFType, nil
+	case lexertoken.TOKEN_VAR:
+		c.next()
+		return rdf.Variable(t.Value), nil
+	case lexertoken.TOKEN_OPERATOR:
+		switch c.next().Value {
+		case lexertoken.IMPLIES:
+			return LogImplies, nil
+		case lexertoken.SAME_AS:
+			return owl.SameAs, nil
+		}
+
+		return nil, c.unexpected(t, "predicate")
+	}
+
+	if t := c.peek(); t.Type != lexertoken.TOKEN_IRIREF && t.Type != lexertoken.TOKEN_PREFIXED_NAME {
+		return nil, c.unexpected(t, "predicate")
+	}
+
+	return c.parseIri()
+}
+
+// object	::=	iri | BlankNode | collection | blankNodePropertyList | literal | quotedTriple
+func (c *Client) parseObject() (rdf.Term, error) {
+	switch t := c.peek(); t.Type {
+	case lexertoken.TOKEN_START_QUOTED_TRIPLE:
+		return c.parseQuotedTriple()
+	case lexertoken.TOKEN_IRIREF, lexertoken.TOKEN_PREFIXED_NAME:
+		return c.parseIri()
+	case lexertoken.TOKEN_BLANK_NODE:
+		c.next()
+		return rdf.BlankNode(t.Value), nil
+	case lexertoken.TOKEN_START_COLLECTION:
+		return c.parseCollection()
+	case lexertoken.TOKEN_START_BLANK_NODE_PROPERTY_LIST:
+		return c.parseBlankNodePropertyList()
+	case lexertoken.TOKEN_LITERAL, lexertoken.TOKEN_INTEGER, lexertoken.TOKEN_DECIMAL,
+		lexertoken.TOKEN_DOUBLE, lexertoken.TOKEN_BOOLEAN:
+		return c.parseLiteral()
+	case lexertoken.TOKEN_START_GROUP:
+		return c.parseFormula()
+	case lexertoken.TOKEN_VAR:
+		c.next()
+		return rdf.Variable(t.Value), nil
+	default:
+		return nil, c.unexpected(t, "object")
+	}
+}
+
+// literal	::=	RDFLiteral | NumericLiteral | BooleanLiteral
+// RDFLiteral	::=	String (LANGTAG | '^^' iri)?
+func (c *Client) parseLiteral() (rdf.Term, error) {
+	t := c.next()
+
+	switch t.Type {
+	case lexertoken.TOKEN_INTEGER:
+		return rdf.NewTypedLiteral(t.Value, rdf.XSDInteger), nil
+	case lexertoken.TOKEN_DECIMAL:
+		return rdf.NewTypedLiteral(t.Value, rdf.XSDDecimal), nil
+	case lexertoken.TOKEN_DOUBLE:
+		return rdf.NewTypedLiteral(t.Value, rdf.XSDDouble), nil
+	case lexertoken.TOKEN_BOOLEAN:
+		return rdf.NewTypedLiteral(t.Value, rdf.XSDBoolean), nil
+	}
+
+	switch c.peek().Type {
+	case lexertoken.TOKEN_LANGTAG:
+		return rdf.NewLangLiteral(t.Value, c.next().Value), nil
+	case lexertoken.TOKEN_DATATYPE:
+		c.next()
+
+		datatype, err := c.parseIri()
+		if err != nil {
+			return nil, err
+		}
+
+		return rdf.NewTypedLiteral(t.Value, datatype.(rdf.IRI)), nil
+	}
+
+	return rdf.NewLiteral(t.Value), nil
+}
+
+// quotedTriple	::=	'<<' qtSubject verb qtObject '>>'
+// qtSubject	::=	iri | BlankNode | quotedTriple
+// qtObject	::=	iri | BlankNode | literal | quotedTriple
+//
+// https://www.w3.org/2021/12/rdf-star.html#turtle-star-grammar
+func (c *Client) parseQuotedTriple() (rdf.Term, error) {
+	if _, err := c.expect(lexertoken.TOKEN_START_QUOTED_TRIPLE); err != nil {
+		return nil, err
+	}
+
+	subject, err := c.parseQuotedTerm(false)
+	if err != nil {
+		return nil, err
+	}
+
+	verb, err := c.parseVerb()
+	if err != nil {
+		return nil, err
+	}
+
+	object, err := c.parseQuotedTerm(true)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := c.expect(lexertoken.TOKEN_END_QUOTED_TRIPLE); err != nil {
+		return nil, err
+	}
+
+	return rdf.QuotedTriple{Subject: subject, Predicate: verb, Object: object}, nil
+}
+
+func (c *Client) parseQuotedTerm(object bool) (rdf.Term, error) {
+	switch t := c.peek(); t.Type {
+	case lexertoken.TOKEN_IRIREF, lexertoken.TOKEN_PREFIXED_NAME:
+		return c.parseIri()
+	case lexertoken.TOKEN_BLANK_NODE:
+		c.next()
+		return rdf.BlankNode(t.Value), nil
+	case lexertoken.TOKEN_START_QUOTED_TRIPLE:
+		return c.parseQuotedTriple()
+	case lexertoken.TOKEN_START_BLANK_NODE_PROPERTY_LIST:
+		// Only the empty ANON form is allowed inside a quoted triple
+		c.next()
+		_, err := c.expect(lexertoken.TOKEN_END_BLANK_NODE_PROPERTY_LIST)
+
+		return c.newBlankNode(), err
+	case lexertoken.TOKEN_LITERAL, lexertoken.TOKEN_INTEGER, lexertoken.TOKEN_DECIMAL,
+		lexertoken.TOKEN_DOUBLE, lexertoken.TOKEN_BOOLEAN:
+		if object {
+			return c.parseLiteral()
+		}
+
+		return nil, c.unexpected(t, "quoted triple subject")
+	default:
+		return nil, c.unexpected(t, "quoted triple term")
+	}
+}
+
+// annotation	::=	'{|' predicateObjectList '|}'
+//
+// The annotated triple has already been asserted, the annotation adds
+// statements about it with the quoted triple as their subject
+func (c *Client) parseAnnotation(quoted rdf.QuotedTriple) error {
+	if _, err := c.expect(lexertoken.TOKEN_START_ANNOTATION); err != nil {
+		return err
+	}
+
+	if err := c.parsePredicateObjectList(quoted); err != nil {
+		return err
+	}
+
+	_, err := c.expect(lexertoken.TOKEN_END_ANNOTATION)
+
+	return err
+}
+
+// blankNodePropertyList	::=	'[' predicateObjectList ']'
+//
+// An empty pair of brackets is the ANON blank node
+func (c *Client) parseBlankNodePropertyList() (rdf.Term, error) {
+	if _, err := c.expect(lexertoken.TOKEN_START_BLANK_NODE_PROPERTY_LIST); err != nil {
+		return nil, err
+	}
+
+	node := c.newBlankNode()
+
+	if c.peek().Type != lexertoken.TOKEN_END_BLANK_NODE_PROPERTY_LIST {
+		if err := c.parsePredicateObjectList(node); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err := c.expect(lexertoken.TOKEN_END_BLANK_NODE_PROPERTY_LIST)
+
+	return node, err
+}
+
+// collection	::=	'(' object* ')'
+//
+// Expanded into the rdf:first/rdf:rest chain, the empty collection is rdf:nil
+func (c *Client) parseCollection() (rdf.Term, error) {
+	if _, err := c.expect(lexertoken.TOKEN_START_COLLECTION); err != nil {
+		return nil, err
+	}
+
+	var head, tail rdf.Term = rdf.RDFNil, nil
+
+	for c.peek().Type != lexertoken.TOKEN_END_COLLECTION {
+		object, err := c.parseObject()
+		if err != nil {
+			return nil, err
+		}
+
+		node := c.newBlankNode()
+
+		if tail == nil {
+			head = node
+		} else {
+			c.graph.Add(rdf.NewTriple(tail, rdf.RDFRest, node))
+		}
+
+		c.graph.Add(rdf.NewTriple(node, rdf.RDFFirst, object))
+		tail = node
+	}
+
+	c.next()
+
+	if tail != nil {
+		c.graph.Add(rdf.NewTriple(tail, rdf.RDFRest, rdf.RDFNil))
+	}
+
+	return head, nil
+}
+
+// formula	::=	'{' formulaContent? '}'
+// formulaContent	::=	n3Statement ('.' formulaContent?)?
+//
+// The triples inside are collected into their own graph rather than the
+// document's, and make up the formula term
+//
+// https://w3c.github.io/N3/spec/#grammar-production-formula
+func (c *Client) parseFormula() (rdf.Term, error) {
+	if _, err := c.expect(lexertoken.TOKEN_START_GROUP); err != nil {
+		return nil, err
+	}
+
+	outer := c.graph
+
+	c.graph = rdf.NewGraph()
+	c.graph.Prefixes = outer.Prefixes
+
+	defer func() { c.graph = outer }()
+
+	for c.peek().Type != lexertoken.TOKEN_END_GROUP {
+		if err := c.parseTriples(); err != nil {
+			return nil, err
+		}
+
+		if c.peek().Type != lexertoken.TOKEN_END_TRIPLE {
+			break
+		}
+
+		c.next()
+	}
+
+	if _, err := c.expect(lexertoken.TOKEN_END_GROUP); err != nil {
+		return nil, err
+	}
+
+	return rdf.Formula(c.graph.Triples()), nil
+}
+
+func (c *Client) acceptOperator(op string) bool {
+	if t := c.peek(); t.Type == lexertoken.TOKEN_OPERATOR && t.Value == op {
+		c.next()
+		return true
+	}
+
+	return false
+}
+
+// iri	::=	IRIREF | PrefixedName
+func (c *Client) parseIri() (rdf.Term, error) {
+	t := c.next()
+
+	switch t.Type {
+	case lexertoken.TOKEN_IRIREF:
+		return c.resolve(t.Value), nil
+	case lexertoken.TOKEN_PREFIXED_NAME:
+		return c.graph.Prefixes.Expand(t.Value)
+	}
+
+	return nil, c.unexpected(t, "IRI")
+}
+
+// declarePrefix binds a prefix, a rebinding replaces the old namespace
+// unless the client is strict about it
+func (c *Client) declarePrefix(name string, ns rdf.IRI) error {
+	err := c.graph.Prefixes.Declare(name, ns)
+
+	var perr *rdf.PrefixError
+	if !errors.As(err, &perr) || !perr.Conflict() || c.strictPrefixes {
+		return err
+	}
+
+	slog.Warn("Prefix redefined", slog.String("prefix", name), slog.String("old", string(perr.Existing)), slog.String("new", string(ns)))
+
+	return c.graph.Prefixes.Set(name, ns)
+}
+
+// resolve turns a possibly relative IRI reference into an absolute IRI
+// using the current base
+func (c *Client) resolve(ref string) rdf.IRI {
+	return rdf.ResolveIRI(c.base, ref)
+}
+
+// newBlankNode mints a blank node whose label doesn't clash with any label
+// used in the document
+func (c *Client) newBlankNode() rdf.BlankNode {
+	for {
+		c.blankNodes++
+		label := "b" + strconv.Itoa(c.blankNodes)
+
+		if _, ok := c.labels[label]; !ok {
+			return rdf.BlankNode(label)
+		}
+	}
+}
+
+// peek returns the next meaningful lexeme without consuming it, comments
+// can appear anywhere so they are skipped here
+func (c *Client) peek() lexertoken.Token {
+	for c.pos < len(c.lexemes) && c.lexemes[c.pos].Type == lexertoken.TOKEN_COMMENT {
+		c.pos++
+	}
+
+	if c.pos >= len(c.lexemes) {
+		return lexertoken.Token{Type: lexertoken.TOKEN_EOF}
+	}
+
+	return c.lexemes[c.pos]
+}
+
+func (c *Client) next() lexertoken.Token {
+	t := c.peek()
+
+	if c.pos < len(c.lexemes) {
+		c.pos++
+	}
+
+	return t
+}
+
+func (c *Client) expect(tokenType lexertoken.TokenType) (lexertoken.Token, error) {
+	t := c.next()
+
+	if t.Type != tokenType {
+		return t, c.unexpected(t, lexertoken.TokenMap[tokenType])
+	}
+
+	return t, nil
+}
+
+func (c *Client) unexpected(t lexertoken.Token, wanted string) error {
+	if t.Type == lexertoken.TOKEN_EOF {
+		return fmt.Errorf("expected %v, found end of input", wanted)
+	}
+
+	return fmt.Errorf("expected %v, found %v %q", wanted, lexertoken.TokenMap[t.Type], t.Value)
+}
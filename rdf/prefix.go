@@ -0,0 +1,202 @@
+package rdf
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// PN_PREFIX from the Turtle grammar
+	prefixNameRegexp = regexp.MustCompile(`^(\p{L}([\p{L}\p{N}_.-]*[\p{L}\p{N}_-])?)?$`)
+
+	// A local name that can be written without escapes
+	localNameRegexp = regexp.MustCompile(`^([\p{L}\p{N}_:]([\p{L}\p{N}_.:-]*[\p{L}\p{N}_:-])?)?$`)
+
+	localEscaper = strings.NewReplacer(`\`, ``)
+)
+
+var ErrUndeclaredPrefix = errors.New("rdf: undeclared prefix")
+
+// A PrefixError is returned when a prefix declaration isn't valid, or
+// rebinds a name that is already declared to something else
+type PrefixError struct {
+	Name     string
+	IRI      IRI
+	Existing IRI
+}
+
+func (e *PrefixError) Error() string {
+	if e.Existing != "" {
+		return fmt.Sprintf("rdf: prefix %q is already bound to %v, not %v", e.Name, e.Existing, e.IRI)
+	}
+
+	return fmt.Sprintf("rdf: invalid prefix declaration %q: %v", e.Name, e.IRI)
+}
+
+// Conflict reports whether the declaration failed because the name was
+// already bound
+func (e *PrefixError) Conflict() bool {
+	return e.Existing != ""
+}
+
+// A PrefixMap binds prefix names, without the colon, to namespace IRIs
+type PrefixMap map[string]IRI
+
+// Declare binds a name to a namespace. Declaring the same binding twice is
+// fine, binding a name to a different namespace returns a *PrefixError
+// that reports Conflict and leaves the map alone
+func (m PrefixMap) Declare(name string, ns IRI) error {
+	if err := validatePrefix(name, ns); err != nil {
+		return err
+	}
+
+	if existing, ok := m[name]; ok && existing != ns {
+		return &PrefixError{Name: name, IRI: ns, Existing: existing}
+	}
+
+	m[name] = ns
+
+	return nil
+}
+
+// Set binds a name to a namespace, replacing whatever it was bound to
+func (m PrefixMap) Set(name string, ns IRI) error {
+	if err := validatePrefix(name, ns); err != nil {
+		return err
+	}
+
+	m[name] = ns
+
+	return nil
+}
+
+func validatePrefix(name string, ns IRI) error {
+	if !prefixNameRegexp.MatchString(name) || !hasScheme(string(ns)) {
+		return &PrefixError{Name: name, IRI: ns}
+	}
+
+	return nil
+}
+
+// Merge copies every binding of other into the map, other wins where both
+// declare the same name
+func (m PrefixMap) Merge(other PrefixMap) {
+	for k, v := range other {
+		m[k] = v
+	}
+}
+
+func (m PrefixMap) Clone() PrefixMap {
+	c := make(PrefixMap, len(m))
+	c.Merge(m)
+
+	return c
+}
+
+// Names returns the declared names in order
+func (m PrefixMap) Names() []string {
+	names := make([]string, 0, len(m))
+	for k := range m {
+		names = append(names, k)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Expand turns a CURIE such as foaf:knows into the IRI it stands for. An
+// IRI written in angle brackets is returned as it is, so user input can
+// mix both forms
+func (m PrefixMap) Expand(curie string) (IRI, error) {
+	if strings.HasPrefix(curie, "<") && strings.HasSuffix(curie, ">") {
+		return IRI(curie[1 : len(curie)-1]), nil
+	}
+
+	name, local, ok := strings.Cut(curie, ":")
+	if !ok {
+		return "", fmt.Errorf("rdf: %q is not a CURIE", curie)
+	}
+
+	ns, ok := m[name]
+	if !ok {
+		return "", fmt.Errorf("%w %q in %v", ErrUndeclaredPrefix, name, curie)
+	}
+
+	return ns + IRI(localEscaper.Replace(local)), nil
+}
+
+// Split finds the longest namespace the IRI starts with that leaves a local
+// name which can be written without escapes, ties go to the alphabetically
+// first name
+func (m PrefixMap) Split(iri IRI) (string, string, bool) {
+	var best string
+	found := false
+
+	for name, ns := range m {
+		if !strings.HasPrefix(string(iri), string(ns)) {
+			continue
+		}
+
+		if !localNameRegexp.MatchString(string(iri[len(ns):])) {
+			continue
+		}
+
+		if !found || len(ns) > len(m[best]) || len(ns) == len(m[best]) && name < best {
+			best, found = name, true
+		}
+	}
+
+	if !found {
+		return "", "", false
+	}
+
+	return best, string(iri[len(m[best]):]), true
+}
+
+// Compact writes the IRI as a CURIE if a namespace fits, and in angle
+// brackets otherwise
+func (m PrefixMap) Compact(iri IRI) string {
+	if name, local, ok := m.Split(iri); ok {
+		return name + ":" + local
+	}
+
+	return iri.String()
+}
+
+// commonPrefixes is a small offline copy of the most used prefix.cc entries
+var commonPrefixes = PrefixMap{
+	"acl":     "http://www.w3.org/ns/auth/acl#",
+	"acp":     "http://www.w3.org/ns/solid/acp#",
+	"as":      "https://www.w3.org/ns/activitystreams#",
+	"cert":    "http://www.w3.org/ns/auth/cert#",
+	"dc":      "http://purl.org/dc/elements/1.1/",
+	"dcat":    "http://www.w3.org/ns/dcat#",
+	"dcterms": "http://purl.org/dc/terms/",
+	"foaf":    "http://xmlns.com/foaf/0.1/",
+	"geo":     "http://www.w3.org/2003/01/geo/wgs84_pos#",
+	"ldp":     "http://www.w3.org/ns/ldp#",
+	"notify":  "http://www.w3.org/ns/solid/notifications#",
+	"owl":     "http://www.w3.org/2002/07/owl#",
+	"prov":    "http://www.w3.org/ns/prov#",
+	"rdf":     "http://www.w3.org/1999/02/22-rdf-syntax-ns#",
+	"rdfs":    "http://www.w3.org/2000/01/rdf-schema#",
+	"schema":  "http://schema.org/",
+	"sh":      "http://www.w3.org/ns/shacl#",
+	"sioc":    "http://rdfs.org/sioc/ns#",
+	"skos":    "http://www.w3.org/2004/02/skos/core#",
+	"solid":   "http://www.w3.org/ns/solid/terms#",
+	"space":   "http://www.w3.org/ns/pim/space#",
+	"vcard":   "http://www.w3.org/2006/vcard/ns#",
+	"void":    "http://rdfs.org/ns/void#",
+	"xsd":     "http://www.w3.org/2001/XMLSchema#",
+}
+
+// CommonPrefixes returns a fresh copy of the built in table of well known
+// prefixes, safe to modify
+func CommonPrefixes() PrefixMap {
+	return commonPrefixes.Clone()
+}
@@ -0,0 +1,68 @@
+package rdf
+
+import (
+	"errors"
+	"testing"
+)
+
+type compactTest struct {
+	Name     string
+	IRI      IRI
+	Expected string
+}
+
+var compactTests = []compactTest{
+	{"Longest namespace wins", "http://example.org/people/alice", "people:alice"},
+	{"Shorter namespace", "http://example.org/thing", "ex:thing"},
+	{"Local name needing escapes", "http://example.org/a/b", "<http://example.org/a/b>"},
+	{"No namespace", "http://other.org/x", "<http://other.org/x>"},
+	{"Empty local name", "http://example.org/", "ex:"},
+}
+
+func TestPrefixMapCompact(t *testing.T) {
+	m := PrefixMap{"ex": "http://example.org/", "people": "http://example.org/people/"}
+
+	for _, tc := range compactTests {
+		if got := m.Compact(tc.IRI); got != tc.Expected {
+			t.Errorf("%v test fail: got %v", tc.Name, got)
+		}
+	}
+}
+
+func TestPrefixMapDeclare(t *testing.T) {
+	m := make(PrefixMap)
+
+	if err := m.Declare("ex", "http://example.org/"); err != nil {
+		t.Errorf("declare failed: %v", err)
+	}
+
+	if err := m.Declare("ex", "http://example.org/"); err != nil {
+		t.Errorf("same declaration twice failed: %v", err)
+	}
+
+	var perr *PrefixError
+
+	if err := m.Declare("ex", "http://example.com/"); !errors.As(err, &perr) || !perr.Conflict() {
+		t.Errorf("redefinition not reported: %v", err)
+	}
+
+	if m["ex"] != "http://example.org/" {
+		t.Errorf("conflicting declaration changed the map")
+	}
+
+	if err := m.Declare("1x", "http://example.org/"); err == nil {
+		t.Errorf("invalid name accepted")
+	}
+
+	if err := m.Declare("rel", "relative/"); err == nil {
+		t.Errorf("relative namespace accepted")
+	}
+
+	if iri, err := m.Expand(`ex:a\.b`); err != nil || iri != "http://example.org/a.b" {
+		t.Errorf("expand failed: %v %v", iri, err)
+	}
+
+	if _, err := m.Expand("nope:a"); !errors.Is(err, ErrUndeclaredPrefix) {
+		t.Errorf("undeclared prefix not reported: %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+// Package reason materialises the triples entailed by RDFS and a subset of
+// OWL 2 RL into a graph with forward chaining
+//
+// https://www.w3.org/TR/rdf11-mt/#rdfs-entailment
+// https://www.w3.org/TR/owl2-profiles/#Reasoning_in_OWL_2_RL_and_RDF_Graphs_using_Rules
+package reason
+
+import "github.com/b1scuit/solid/rdf"
+
+// A Rule derives triples from a triple newly added to a graph, joined with
+// the triples already in it. Each rule has to consider the new triple in
+// every position of its premises, as it is only given each triple once
+type Rule struct {
+	Name  string
+	Apply func(g *rdf.Graph, t rdf.Triple) []rdf.Triple
+}
+
+type ReasonerOption func(*Reasoner)
+
+// WithRules sets the rules to apply instead of OWLRL, such as RDFS alone or
+// a set with extra rules of its own
+func WithRules(rules ...Rule) ReasonerOption {
+	return func(r *Reasoner) {
+		r.rules = rules
+	}
+}
+
+// A Reasoner keeps a graph closed under its rules, adding what they infer
+// into the graph itself
+type Reasoner struct {
+	g        *rdf.Graph
+	rules    []Rule
+	inferred *rdf.Graph
+}
+
+func New(g *rdf.Graph, opts ...ReasonerOption) (*Reasoner, error) {
+	r := &Reasoner{
+		g:        g,
+		rules:    OWLRL,
+		inferred: rdf.NewGraph(),
+	}
+
+	for _, f := range opts {
+		f(r)
+	}
+
+	return r, nil
+}
+
+func MustNew(g *rdf.Graph, opts ...ReasonerOption) *Reasoner {
+	r, err := New(g, opts...)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return r
+}
+
+// Graph is the graph being reasoned over, with the inferred triples
+func (r *Reasoner) Graph() *rdf.Graph {
+	return r.g
+}
+
+// Inferred holds the triples the rules added that weren't asserted
+func (r *Reasoner) Inferred() *rdf.Graph {
+	return r.inferred
+}
+
+// Materialise applies the rules to every triple in the graph until nothing
+// new follows, returning how many triples were inferred
+func (r *Reasoner) Materialise() int {
+	return r.infer(r.g.Triples())
+}
+
+// Add asserts triples and infers only what follows from them, so a graph
+// that was materialised stays closed without starting over. It returns how
+// many triples were inferred
+func (r *Reasoner) Add(triples ...rdf.Triple) int {
+	var added []rdf.Triple
+
+	for _, t := range triples {
+		// An inferred triple that is asserted isn't inferred anymore
+		if r.inferred.Remove(t) || r.g.Has(t) {
+			continue
+		}
+
+		r.g.Add(t)
+		added = append(added, t)
+	}
+
+	return r.infer(added)
+}
+
+// infer is semi-naive, each triple goes through the rules once. Triples
+// are in the graph before their turn comes, so whichever premise of a rule
+// is added last finds the others
+func (r *Reasoner) infer(queue []rdf.Triple) int {
+	n := 0
+
+	for len(queue) > 0 {
+		t := queue[0]
+		queue = queue[1:]
+
+		for _, rule := range r.rules {
+			for _, c := range rule.Apply(r.g, t) {
+				if !valid(c) || r.g.Has(c) {
+					continue
+				}
+
+				r.g.Add(c)
+				r.inferred.Add(c)
+				queue = append(queue, c)
+				n++
+			}
+		}
+	}
+
+	return n
+}
+
+// valid leaves out what rules can derive but RDF can't say, literals as
+// subjects and predicates that aren't IRIs
+func valid(t rdf.Triple) bool {
+	return t.Subject.Kind() != rdf.TermLiteral && t.Predicate.Kind() == rdf.TermIRI
+}
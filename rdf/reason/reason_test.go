@@ -0,0 +1,145 @@
+package reason
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/parser"
+)
+
+const testPrefixes = `
+@prefix ex: <http://example.org/> .
+@prefix foaf: <http://xmlns.com/foaf/0.1/> .
+@prefix rdfs: <http://www.w3.org/2000/01/rdf-schema#> .
+@prefix owl: <http://www.w3.org/2002/07/owl#> .
+`
+
+func testGraph(t *testing.T, doc string) *rdf.Graph {
+	p := parser.MustNew()
+
+	if err := p.Do(strings.NewReader(testPrefixes + doc)); err != nil {
+		t.Fatalf("parsing test graph: %v", err)
+	}
+
+	return p.GetGraph()
+}
+
+type reasonTest struct {
+	Name string
+	Data string
+
+	// Triples that have to be inferred, and ones that mustn't be
+	Entailed    string
+	NotEntailed string
+}
+
+var reasonTests = []reasonTest{
+	{"Subclass",
+		`foaf:Person rdfs:subClassOf foaf:Agent . foaf:Agent rdfs:subClassOf ex:Thing . ex:alice a foaf:Person .`,
+		`ex:alice a foaf:Agent, ex:Thing . foaf:Person rdfs:subClassOf ex:Thing .`,
+		``},
+	{"Subproperty",
+		`ex:mother rdfs:subPropertyOf ex:parent . ex:parent rdfs:subPropertyOf ex:relative . ex:alice ex:mother ex:carol .`,
+		`ex:alice ex:parent ex:carol ; ex:relative ex:carol . ex:mother rdfs:subPropertyOf ex:relative .`,
+		``},
+	{"Domain and range",
+		`ex:knows rdfs:domain foaf:Person ; rdfs:range foaf:Agent . ex:name rdfs:range ex:Name .
+		ex:alice ex:knows ex:bob ; ex:name "Alice" .`,
+		`ex:alice a foaf:Person . ex:bob a foaf:Agent .`,
+		``},
+	{"Range through a subproperty and subclass",
+		`ex:mother rdfs:subPropertyOf ex:parent . ex:parent rdfs:range ex:Parent . ex:Parent rdfs:subClassOf foaf:Person .
+		ex:alice ex:mother ex:carol .`,
+		`ex:carol a ex:Parent, foaf:Person .`,
+		`ex:alice a ex:Parent .`},
+	{"Same as",
+		`ex:alice owl:sameAs ex:alicia . ex:alicia owl:sameAs ex:ali . ex:alice foaf:name "Alice" . ex:bob foaf:knows ex:alicia .`,
+		`ex:ali owl:sameAs ex:alice . ex:alice owl:sameAs ex:ali . ex:ali foaf:name "Alice" . ex:bob foaf:knows ex:alice, ex:ali .`,
+		``},
+	{"Same as on properties",
+		`ex:knows owl:sameAs foaf:knows . ex:alice ex:knows ex:bob .`,
+		`ex:alice foaf:knows ex:bob .`,
+		``},
+	{"Inverse",
+		`ex:parent owl:inverseOf ex:child . ex:alice ex:parent ex:bob . ex:carol ex:child ex:dave .`,
+		`ex:bob ex:child ex:alice . ex:dave ex:parent ex:carol .`,
+		``},
+	{"Symmetric and transitive",
+		`ex:spouse a owl:SymmetricProperty . ex:ancestor a owl:TransitiveProperty .
+		ex:alice ex:spouse ex:bob . ex:a ex:ancestor ex:b . ex:b ex:ancestor ex:c . ex:c ex:ancestor ex:d .`,
+		`ex:bob ex:spouse ex:alice . ex:a ex:ancestor ex:c, ex:d . ex:b ex:ancestor ex:d .`,
+		`ex:b ex:ancestor ex:a .`},
+	{"Equivalent class and property",
+		`ex:Human owl:equivalentClass foaf:Person . ex:alice a ex:Human . ex:bob a foaf:Person .
+		ex:fullName owl:equivalentProperty foaf:name . ex:alice ex:fullName "Alice" .`,
+		`ex:alice a foaf:Person . ex:bob a ex:Human . ex:alice foaf:name "Alice" .`,
+		``},
+}
+
+func TestMaterialise(t *testing.T) {
+	for _, tc := range reasonTests {
+		r := MustNew(testGraph(t, tc.Data))
+		n := r.Materialise()
+
+		if n != r.Inferred().Len() {
+			t.Errorf("%v test fail: %v inferred, %v in the inferred graph", tc.Name, n, r.Inferred().Len())
+		}
+
+		for _, e := range testGraph(t, tc.Entailed).Triples() {
+			if !r.Graph().Has(e) {
+				t.Errorf("%v test fail: %v not inferred", tc.Name, e)
+			}
+		}
+
+		for _, e := range testGraph(t, tc.NotEntailed).Triples() {
+			if r.Graph().Has(e) {
+				t.Errorf("%v test fail: %v inferred", tc.Name, e)
+			}
+		}
+
+		for _, i := range r.Inferred().Triples() {
+			if i.Subject.Kind() == rdf.TermLiteral {
+				t.Errorf("%v test fail: literal subject in %v", tc.Name, i)
+			}
+		}
+	}
+}
+
+// Adding triples one at a time has to reach the same closure as
+// materialising them all at once
+func TestAdd(t *testing.T) {
+	for _, tc := range reasonTests {
+		all := MustNew(testGraph(t, tc.Data))
+		all.Materialise()
+
+		r := MustNew(rdf.NewGraph())
+		for _, tr := range testGraph(t, tc.Data).Triples() {
+			r.Add(tr)
+		}
+
+		if !rdf.Isomorphic(r.Graph(), all.Graph()) {
+			t.Errorf("%v test fail: got %v triples, expected %v", tc.Name, r.Graph().Len(), all.Graph().Len())
+		}
+	}
+
+	r := MustNew(testGraph(t, `ex:alice a foaf:Person .`), WithRules(RDFS...))
+	r.Materialise()
+
+	person := rdf.Triple{Subject: rdf.IRI("http://example.org/bob"), Predicate: rdf.RDFType, Object: rdf.IRI("http://xmlns.com/foaf/0.1/Person")}
+	sub := rdf.Triple{Subject: rdf.IRI("http://xmlns.com/foaf/0.1/Person"), Predicate: rdf.IRI("http://www.w3.org/2000/01/rdf-schema#subClassOf"), Object: rdf.IRI("http://xmlns.com/foaf/0.1/Agent")}
+
+	if n := r.Add(sub); n != 1 {
+		t.Errorf("add test fail: %v inferred from a subclass, expected 1", n)
+	}
+
+	if n := r.Add(person); n != 1 {
+		t.Errorf("add test fail: %v inferred from a type, expected 1", n)
+	}
+
+	// Asserting what was inferred makes it asserted
+	agent := rdf.Triple{Subject: rdf.IRI("http://example.org/bob"), Predicate: rdf.RDFType, Object: rdf.IRI("http://xmlns.com/foaf/0.1/Agent")}
+	if r.Add(agent); r.Inferred().Has(agent) || !r.Graph().Has(agent) {
+		t.Errorf("add test fail: asserted triple still counted as inferred")
+	}
+}
@@ -0,0 +1,249 @@
+package reason
+
+import (
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/vocab/owl"
+	"github.com/b1scuit/solid/rdf/vocab/rdfs"
+)
+
+// RDFS is the RDFS entailment rules for subclasses, subproperties, domains
+// and ranges
+//
+// https://www.w3.org/TR/rdf11-mt/#patterns-of-rdfs-entailment-informative
+var RDFS = []Rule{
+	{"rdfs2", typeFrom(rdfs.Domain, func(t rdf.Triple) rdf.Term { return t.Subject })},
+	{"rdfs3", typeFrom(rdfs.Range, func(t rdf.Triple) rdf.Term { return t.Object })},
+	{"rdfs5", transitive(rdfs.SubPropertyOf)},
+	{"rdfs7", subProperty},
+	{"rdfs9", subClass},
+	{"rdfs11", transitive(rdfs.SubClassOf)},
+}
+
+// OWLRL is RDFS with the OWL 2 RL rules for equality, inverse, symmetric
+// and transitive properties, and equivalent classes and properties
+//
+// https://www.w3.org/TR/owl2-profiles/#Reasoning_in_OWL_2_RL_and_RDF_Graphs_using_Rules
+var OWLRL = append(append([]Rule{}, RDFS...), []Rule{
+	{"eq-sym", symmetric(owl.SameAs)},
+	{"eq-trans", transitive(owl.SameAs)},
+	{"eq-rep", sameAs},
+	{"prp-inv", inverse},
+	{"prp-symp", symmetricProperty},
+	{"prp-trp", transitiveProperty},
+	{"scm-eqc", equivalent(owl.EquivalentClass, rdfs.SubClassOf)},
+	{"scm-eqp", equivalent(owl.EquivalentProperty, rdfs.SubPropertyOf)},
+}...)
+
+func is(t rdf.Triple, p rdf.IRI) bool {
+	return rdf.Equal(t.Predicate, p)
+}
+
+func isType(t rdf.Triple, class rdf.IRI) bool {
+	return is(t, rdf.RDFType) && rdf.Equal(t.Object, class)
+}
+
+// typeFrom types the subject or object of a property's triples with the
+// classes given by a domain or range
+func typeFrom(p rdf.IRI, node func(rdf.Triple) rdf.Term) func(*rdf.Graph, rdf.Triple) []rdf.Triple {
+	return func(g *rdf.Graph, t rdf.Triple) []rdf.Triple {
+		var out []rdf.Triple
+
+		if is(t, p) {
+			for _, u := range g.Match(nil, t.Subject, nil) {
+				out = append(out, rdf.Triple{Subject: node(u), Predicate: rdf.RDFType, Object: t.Object})
+			}
+		}
+
+		for _, d := range g.Match(t.Predicate, p, nil) {
+			out = append(out, rdf.Triple{Subject: node(t), Predicate: rdf.RDFType, Object: d.Object})
+		}
+
+		return out
+	}
+}
+
+// transitive joins a triple with the property on either side of it
+func transitive(p rdf.IRI) func(*rdf.Graph, rdf.Triple) []rdf.Triple {
+	return func(g *rdf.Graph, t rdf.Triple) []rdf.Triple {
+		if !is(t, p) {
+			return nil
+		}
+
+		return chain(g, t, t.Predicate)
+	}
+}
+
+// chain gives x p z for the triples x p y after t and before it
+func chain(g *rdf.Graph, t rdf.Triple, p rdf.Term) []rdf.Triple {
+	var out []rdf.Triple
+
+	for _, u := range g.Match(t.Object, p, nil) {
+		out = append(out, rdf.Triple{Subject: t.Subject, Predicate: p, Object: u.Object})
+	}
+
+	for _, u := range g.Match(nil, p, t.Subject) {
+		out = append(out, rdf.Triple{Subject: u.Subject, Predicate: p, Object: t.Object})
+	}
+
+	return out
+}
+
+func symmetric(p rdf.IRI) func(*rdf.Graph, rdf.Triple) []rdf.Triple {
+	return func(g *rdf.Graph, t rdf.Triple) []rdf.Triple {
+		if !is(t, p) {
+			return nil
+		}
+
+		return []rdf.Triple{{Subject: t.Object, Predicate: p, Object: t.Subject}}
+	}
+}
+
+// equivalent states an equivalence as subclasses or subproperties both
+// ways, which the RDFS rules then apply
+func equivalent(p, sub rdf.IRI) func(*rdf.Graph, rdf.Triple) []rdf.Triple {
+	return func(g *rdf.Graph, t rdf.Triple) []rdf.Triple {
+		if !is(t, p) {
+			return nil
+		}
+
+		return []rdf.Triple{
+			{Subject: t.Subject, Predicate: sub, Object: t.Object},
+			{Subject: t.Object, Predicate: sub, Object: t.Subject},
+		}
+	}
+}
+
+// rdfs7: p subPropertyOf q, x p y gives x q y
+func subProperty(g *rdf.Graph, t rdf.Triple) []rdf.Triple {
+	var out []rdf.Triple
+
+	if is(t, rdfs.SubPropertyOf) {
+		for _, u := range g.Match(nil, t.Subject, nil) {
+			out = append(out, rdf.Triple{Subject: u.Subject, Predicate: t.Object, Object: u.Object})
+		}
+	}
+
+	for _, sp := range g.Match(t.Predicate, rdfs.SubPropertyOf, nil) {
+		out = append(out, rdf.Triple{Subject: t.Subject, Predicate: sp.Object, Object: t.Object})
+	}
+
+	return out
+}
+
+// rdfs9: c subClassOf d, x a c gives x a d
+func subClass(g *rdf.Graph, t rdf.Triple) []rdf.Triple {
+	var out []rdf.Triple
+
+	if is(t, rdfs.SubClassOf) {
+		for _, u := range g.Match(nil, rdf.RDFType, t.Subject) {
+			out = append(out, rdf.Triple{Subject: u.Subject, Predicate: rdf.RDFType, Object: t.Object})
+		}
+	}
+
+	if is(t, rdf.RDFType) {
+		for _, sc := range g.Match(t.Object, rdfs.SubClassOf, nil) {
+			out = append(out, rdf.Triple{Subject: t.Subject, Predicate: rdf.RDFType, Object: sc.Object})
+		}
+	}
+
+	return out
+}
+
+// eq-rep-s, eq-rep-p and eq-rep-o: x sameAs y lets y stand for x in any
+// position of a triple
+func sameAs(g *rdf.Graph, t rdf.Triple) []rdf.Triple {
+	var out []rdf.Triple
+
+	if is(t, owl.SameAs) {
+		x, y := t.Subject, t.Object
+
+		for _, u := range g.Match(x, nil, nil) {
+			out = append(out, rdf.Triple{Subject: y, Predicate: u.Predicate, Object: u.Object})
+		}
+
+		for _, u := range g.Match(nil, x, nil) {
+			out = append(out, rdf.Triple{Subject: u.Subject, Predicate: y, Object: u.Object})
+		}
+
+		for _, u := range g.Match(nil, nil, x) {
+			out = append(out, rdf.Triple{Subject: u.Subject, Predicate: u.Predicate, Object: y})
+		}
+	}
+
+	for _, s := range g.Match(t.Subject, owl.SameAs, nil) {
+		out = append(out, rdf.Triple{Subject: s.Object, Predicate: t.Predicate, Object: t.Object})
+	}
+
+	for _, s := range g.Match(t.Predicate, owl.SameAs, nil) {
+		out = append(out, rdf.Triple{Subject: t.Subject, Predicate: s.Object, Object: t.Object})
+	}
+
+	for _, s := range g.Match(t.Object, owl.SameAs, nil) {
+		out = append(out, rdf.Triple{Subject: t.Subject, Predicate: t.Predicate, Object: s.Object})
+	}
+
+	return out
+}
+
+// prp-inv1 and prp-inv2: p inverseOf q, x p y gives y q x and the same the
+// other way round
+func inverse(g *rdf.Graph, t rdf.Triple) []rdf.Triple {
+	var out []rdf.Triple
+
+	flip := func(p rdf.Term, q rdf.Term) {
+		for _, u := range g.Match(nil, p, nil) {
+			out = append(out, rdf.Triple{Subject: u.Object, Predicate: q, Object: u.Subject})
+		}
+	}
+
+	if is(t, owl.InverseOf) {
+		flip(t.Subject, t.Object)
+		flip(t.Object, t.Subject)
+	}
+
+	for _, inv := range g.Match(t.Predicate, owl.InverseOf, nil) {
+		out = append(out, rdf.Triple{Subject: t.Object, Predicate: inv.Object, Object: t.Subject})
+	}
+
+	for _, inv := range g.Match(nil, owl.InverseOf, t.Predicate) {
+		out = append(out, rdf.Triple{Subject: t.Object, Predicate: inv.Subject, Object: t.Subject})
+	}
+
+	return out
+}
+
+// prp-symp: p a SymmetricProperty, x p y gives y p x
+func symmetricProperty(g *rdf.Graph, t rdf.Triple) []rdf.Triple {
+	var out []rdf.Triple
+
+	if isType(t, owl.SymmetricProperty) {
+		for _, u := range g.Match(nil, t.Subject, nil) {
+			out = append(out, rdf.Triple{Subject: u.Object, Predicate: u.Predicate, Object: u.Subject})
+		}
+	}
+
+	if g.Has(rdf.Triple{Subject: t.Predicate, Predicate: rdf.RDFType, Object: owl.SymmetricProperty}) {
+		out = append(out, rdf.Triple{Subject: t.Object, Predicate: t.Predicate, Object: t.Subject})
+	}
+
+	return out
+}
+
+// prp-trp: p a TransitiveProperty, x p y, y p z gives x p z
+func transitiveProperty(g *rdf.Graph, t rdf.Triple) []rdf.Triple {
+	var out []rdf.Triple
+
+	if isType(t, owl.TransitiveProperty) {
+		for _, u := range g.Match(nil, t.Subject, nil) {
+			for _, v := range g.Match(u.Object, t.Subject, nil) {
+				out = append(out, rdf.Triple{Subject: u.Subject, Predicate: t.Subject, Object: v.Object})
+			}
+		}
+	}
+
+	if g.Has(rdf.Triple{Subject: t.Predicate, Predicate: rdf.RDFType, Object: owl.TransitiveProperty}) {
+		out = append(out, chain(g, t, t.Predicate)...)
+	}
+
+	return out
+}
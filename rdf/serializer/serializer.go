@@ -0,0 +1,257 @@
+package serializer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/jsonld"
+)
+
+var (
+	integerRegexp = regexp.MustCompile(`^[+-]?[0-9]+$`)
+	decimalRegexp = regexp.MustCompile(`^[+-]?[0-9]*\.[0-9]+$`)
+	doubleRegexp  = regexp.MustCompile(`^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)[eE][+-]?[0-9]+$`)
+)
+
+type SerializerOption func(*Serializer)
+
+// WithPrefixes adds prefixes used to shorten IRIs in Turtle output, these are
+// merged with any prefixes the graph itself carries
+func WithPrefixes(prefixes rdf.PrefixMap) SerializerOption {
+	return func(s *Serializer) {
+		s.prefixes.Merge(prefixes)
+	}
+}
+
+// WithCommonPrefixes shortens IRIs with the built in table of well known
+// prefixes as well, only those that are used end up in the output
+func WithCommonPrefixes() SerializerOption {
+	return func(s *Serializer) {
+		for k, v := range rdf.CommonPrefixes() {
+			if _, ok := s.prefixes[k]; !ok {
+				s.prefixes[k] = v
+			}
+		}
+	}
+}
+
+type Serializer struct {
+	prefixes rdf.PrefixMap
+}
+
+func New(opts ...SerializerOption) (*Serializer, error) {
+	s := &Serializer{
+		prefixes: make(rdf.PrefixMap),
+	}
+
+	for _, f := range opts {
+		f(s)
+	}
+
+	return s, nil
+}
+
+func MustNew(opts ...SerializerOption) *Serializer {
+	s, err := New(opts...)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+// NTriples writes one triple per line in N-Triples syntax, quoted triples
+// are written in the N-Triples-star << s p o >> form
+func (s *Serializer) NTriples(w io.Writer, g *rdf.Graph) error {
+	bw := bufio.NewWriter(w)
+
+	for _, t := range g.Triples() {
+		if _, err := bw.WriteString(t.String() + "\n"); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// JSONLD writes the graph as flattened JSON-LD, compacting IRIs with the
+// prefixes that are used
+func (s *Serializer) JSONLD(w io.Writer, g *rdf.Graph) error {
+	return jsonld.Write(w, g, s.Prefixes(g))
+}
+
+// Turtle writes the graph grouped by subject, with the prefix declarations
+// that are needed at the top
+func (s *Serializer) Turtle(w io.Writer, g *rdf.Graph) error {
+	prefixes := s.Prefixes(g)
+	bw := bufio.NewWriter(w)
+
+	names := prefixes.Names()
+
+	for _, name := range names {
+		fmt.Fprintf(bw, "@prefix %v: %v .\n", name, prefixes[name].String())
+	}
+
+	if len(names) > 0 {
+		bw.WriteString("\n")
+	}
+
+	triples := g.Triples()
+
+	for i := 0; i < len(triples); {
+		subject := triples[i].Subject
+
+		j := i
+		for j < len(triples) && rdf.Equal(triples[j].Subject, subject) {
+			j++
+		}
+
+		s.writeSubject(bw, prefixes, triples[i:j])
+		i = j
+	}
+
+	return bw.Flush()
+}
+
+func (s *Serializer) writeSubject(w *bufio.Writer, prefixes rdf.PrefixMap, triples []rdf.Triple) {
+	// rdf:type reads best first, written as "a"
+	sort.SliceStable(triples, func(i, j int) bool {
+		return rdf.Equal(triples[i].Predicate, rdf.RDFType) && !rdf.Equal(triples[j].Predicate, rdf.RDFType)
+	})
+
+	w.WriteString(formatTerm(prefixes, triples[0].Subject))
+
+	for i := 0; i < len(triples); {
+		predicate := triples[i].Predicate
+
+		var objects []string
+		for ; i < len(triples) && rdf.Equal(triples[i].Predicate, predicate); i++ {
+			objects = append(objects, formatTerm(prefixes, triples[i].Object))
+		}
+
+		verb := formatTerm(prefixes, predicate)
+		if rdf.Equal(predicate, rdf.RDFType) {
+			verb = "a"
+		}
+
+		w.WriteString("\n    " + verb + " " + strings.Join(objects, ", "))
+
+		if i < len(triples) {
+			w.WriteString(" ;")
+		}
+	}
+
+	w.WriteString(" .\n\n")
+}
+
+// Prefixes returns the configured and graph prefixes that are used by at
+// least one term in the graph
+func (s *Serializer) Prefixes(g *rdf.Graph) rdf.PrefixMap {
+	all := make(rdf.PrefixMap, len(s.prefixes)+len(g.Prefixes))
+	all.Merge(g.Prefixes)
+	all.Merge(s.prefixes)
+
+	used := make(rdf.PrefixMap)
+
+	for _, t := range g.Triples() {
+		for _, term := range t.Terms() {
+			for _, iri := range iris(term) {
+				if name, _, ok := all.Split(iri); ok {
+					used[name] = all[name]
+				}
+			}
+		}
+	}
+
+	return used
+}
+
+// FormatTerm writes a single term in Turtle syntax using the serializer's
+// prefixes
+func (s *Serializer) FormatTerm(t rdf.Term) string {
+	return formatTerm(s.prefixes, t)
+}
+
+// FormatTriple writes a single triple in Turtle syntax, ending with " ."
+func (s *Serializer) FormatTriple(t rdf.Triple) string {
+	return s.FormatTerm(t.Subject) + " " + s.FormatTerm(t.Predicate) + " " + s.FormatTerm(t.Object) + " ."
+}
+
+func formatTerm(prefixes rdf.PrefixMap, t rdf.Term) string {
+	switch v := t.(type) {
+	case rdf.QuotedTriple:
+		return "<< " + formatTerm(prefixes, v.Subject) + " " + formatTerm(prefixes, v.Predicate) + " " + formatTerm(prefixes, v.Object) + " >>"
+	case rdf.Formula:
+		if len(v) == 0 {
+			return "{}"
+		}
+
+		var b strings.Builder
+		b.WriteString("{")
+
+		for _, t := range v {
+			b.WriteString(" " + formatTerm(prefixes, t.Subject) + " " + formatTerm(prefixes, t.Predicate) + " " + formatTerm(prefixes, t.Object) + " .")
+		}
+
+		b.WriteString(" }")
+
+		return b.String()
+	case rdf.IRI:
+		return prefixes.Compact(v)
+	case rdf.Literal:
+		switch v.Datatype {
+		case rdf.XSDInteger:
+			if integerRegexp.MatchString(v.Lexical) {
+				return v.Lexical
+			}
+		case rdf.XSDDecimal:
+			if decimalRegexp.MatchString(v.Lexical) {
+				return v.Lexical
+			}
+		case rdf.XSDDouble:
+			if doubleRegexp.MatchString(v.Lexical) {
+				return v.Lexical
+			}
+		case rdf.XSDBoolean:
+			if v.Lexical == "true" || v.Lexical == "false" {
+				return v.Lexical
+			}
+		}
+
+		if v.Language == "" && v.Datatype != "" && v.Datatype != rdf.XSDString {
+			return `"` + rdf.EscapeString(v.Lexical) + `"^^` + formatTerm(prefixes, v.Datatype)
+		}
+	}
+
+	return t.String()
+}
+
+func iris(t rdf.Term) []rdf.IRI {
+	switch v := t.(type) {
+	case rdf.QuotedTriple:
+		return append(append(iris(v.Subject), iris(v.Predicate)...), iris(v.Object)...)
+	case rdf.Formula:
+		var all []rdf.IRI
+		for _, t := range v {
+			all = append(all, iris(t.Subject)...)
+			all = append(all, iris(t.Predicate)...)
+			all = append(all, iris(t.Object)...)
+		}
+
+		return all
+	case rdf.IRI:
+		return []rdf.IRI{v}
+	case rdf.Literal:
+		if v.Language == "" && v.Datatype != "" && v.Datatype != rdf.XSDString {
+			return []rdf.IRI{v.Datatype}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,733 @@
+package shacl
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/vocab/rdfs"
+	"github.com/b1scuit/solid/rdf/vocab/sh"
+)
+
+// constraintReaders build the constraints of a shape from its parameters,
+// one for each of the core constraint components
+//
+// https://www.w3.org/TR/shacl/#core-components
+var constraintReaders []func(p *shapeParser, s *shape) ([]constraint, error)
+
+// Set in init as the readers for shape references read shapes themselves
+func init() {
+	constraintReaders = []func(p *shapeParser, s *shape) ([]constraint, error){
+		readClass,
+		readDatatype,
+		readNodeKind,
+		readCount,
+		readRange,
+		readLength,
+		readPattern,
+		readLanguageIn,
+		readUniqueLang,
+		readPropertyPairs,
+		readNot,
+		readLogical,
+		readNode,
+		readQualified,
+		readClosed,
+		readHasValue,
+		readIn,
+	}
+}
+
+// each makes a constraint that checks value nodes one at a time
+func each(component rdf.IRI, ok func(v *validation, value rdf.Term) bool) constraint {
+	return constraint{component: component, check: func(v *validation, focus rdf.Term, values []rdf.Term) []Result {
+		var results []Result
+
+		for _, value := range values {
+			if !ok(v, value) {
+				results = append(results, Result{Value: value})
+			}
+		}
+
+		return results
+	}}
+}
+
+func readClass(p *shapeParser, s *shape) ([]constraint, error) {
+	var cs []constraint
+
+	for _, class := range objects(p.g, s.node, sh.Class) {
+		class := class
+
+		cs = append(cs, each(sh.ClassConstraintComponent, func(v *validation, value rdf.Term) bool {
+			return value.Kind() != rdf.TermLiteral && v.isInstance(value, class)
+		}))
+	}
+
+	return cs, nil
+}
+
+func readDatatype(p *shapeParser, s *shape) ([]constraint, error) {
+	var cs []constraint
+
+	for _, datatype := range objects(p.g, s.node, sh.Datatype) {
+		datatype, ok := datatype.(rdf.IRI)
+		if !ok {
+			return nil, fmt.Errorf("sh:datatype must be an IRI")
+		}
+
+		cs = append(cs, each(sh.DatatypeConstraintComponent, func(v *validation, value rdf.Term) bool {
+			l, ok := value.(rdf.Literal)
+			if !ok || literalDatatype(l) != datatype {
+				return false
+			}
+
+			return l.Validate() == nil
+		}))
+	}
+
+	return cs, nil
+}
+
+func literalDatatype(l rdf.Literal) rdf.IRI {
+	if l.Datatype == "" {
+		return rdf.XSDString
+	}
+
+	return l.Datatype
+}
+
+// nodeKinds are the kinds of term each sh:nodeKind allows
+var nodeKinds = map[rdf.IRI][]rdf.TermKind{
+	sh.IRI:                {rdf.TermIRI},
+	sh.BlankNode:          {rdf.TermBlankNode},
+	sh.Literal:            {rdf.TermLiteral},
+	sh.BlankNodeOrIRI:     {rdf.TermBlankNode, rdf.TermIRI},
+	sh.BlankNodeOrLiteral: {rdf.TermBlankNode, rdf.TermLiteral},
+	sh.IRIOrLiteral:       {rdf.TermIRI, rdf.TermLiteral},
+}
+
+func readNodeKind(p *shapeParser, s *shape) ([]constraint, error) {
+	var cs []constraint
+
+	for _, kind := range objects(p.g, s.node, sh.NodeKindProperty) {
+		iri, _ := kind.(rdf.IRI)
+
+		kinds, ok := nodeKinds[iri]
+		if !ok {
+			return nil, fmt.Errorf("%v is not a node kind", kind)
+		}
+
+		cs = append(cs, each(sh.NodeKindConstraintComponent, func(v *validation, value rdf.Term) bool {
+			for _, k := range kinds {
+				if value.Kind() == k {
+					return true
+				}
+			}
+
+			return false
+		}))
+	}
+
+	return cs, nil
+}
+
+// readCount reads sh:minCount and sh:maxCount, which report once for the
+// focus node rather than per value
+func readCount(p *shapeParser, s *shape) ([]constraint, error) {
+	var cs []constraint
+
+	for _, param := range []struct {
+		predicate, component rdf.IRI
+		ok                   func(count, limit int) bool
+	}{
+		{sh.MinCount, sh.MinCountConstraintComponent, func(count, limit int) bool { return count >= limit }},
+		{sh.MaxCount, sh.MaxCountConstraintComponent, func(count, limit int) bool { return count <= limit }},
+	} {
+		for _, t := range objects(p.g, s.node, param.predicate) {
+			limit, err := integerParam(t)
+			if err != nil {
+				return nil, err
+			}
+
+			ok := param.ok
+			cs = append(cs, constraint{component: param.component, check: func(v *validation, focus rdf.Term, values []rdf.Term) []Result {
+				if ok(len(values), limit) {
+					return nil
+				}
+
+				return []Result{{}}
+			}})
+		}
+	}
+
+	return cs, nil
+}
+
+// readRange reads sh:minExclusive, sh:minInclusive, sh:maxExclusive and
+// sh:maxInclusive, values that can't be compared with the limit fail
+func readRange(p *shapeParser, s *shape) ([]constraint, error) {
+	var cs []constraint
+
+	for _, param := range []struct {
+		predicate, component rdf.IRI
+		ok                   func(c int) bool
+	}{
+		{sh.MinExclusive, sh.MinExclusiveConstraintComponent, func(c int) bool { return c > 0 }},
+		{sh.MinInclusive, sh.MinInclusiveConstraintComponent, func(c int) bool { return c >= 0 }},
+		{sh.MaxExclusive, sh.MaxExclusiveConstraintComponent, func(c int) bool { return c < 0 }},
+		{sh.MaxInclusive, sh.MaxInclusiveConstraintComponent, func(c int) bool { return c <= 0 }},
+	} {
+		for _, t := range objects(p.g, s.node, param.predicate) {
+			limit, isLiteral := t.(rdf.Literal)
+			if !isLiteral {
+				return nil, fmt.Errorf("%v is not a literal", t)
+			}
+
+			ok := param.ok
+			cs = append(cs, each(param.component, func(v *validation, value rdf.Term) bool {
+				l, isLiteral := value.(rdf.Literal)
+				if !isLiteral {
+					return false
+				}
+
+				c, err := rdf.Compare(l, limit)
+
+				return err == nil && ok(c)
+			}))
+		}
+	}
+
+	return cs, nil
+}
+
+// str is the string form of a value for the string based constraints,
+// blank nodes don't have one
+func str(t rdf.Term) (string, bool) {
+	switch x := t.(type) {
+	case rdf.IRI:
+		return string(x), true
+	case rdf.Literal:
+		return x.Lexical, true
+	}
+
+	return "", false
+}
+
+func readLength(p *shapeParser, s *shape) ([]constraint, error) {
+	var cs []constraint
+
+	for _, param := range []struct {
+		predicate, component rdf.IRI
+		ok                   func(length, limit int) bool
+	}{
+		{sh.MinLength, sh.MinLengthConstraintComponent, func(length, limit int) bool { return length >= limit }},
+		{sh.MaxLength, sh.MaxLengthConstraintComponent, func(length, limit int) bool { return length <= limit }},
+	} {
+		for _, t := range objects(p.g, s.node, param.predicate) {
+			limit, err := integerParam(t)
+			if err != nil {
+				return nil, err
+			}
+
+			ok := param.ok
+			cs = append(cs, each(param.component, func(v *validation, value rdf.Term) bool {
+				s, hasStr := str(value)
+				return hasStr && ok(utf8.RuneCountInString(s), limit)
+			}))
+		}
+	}
+
+	return cs, nil
+}
+
+// readPattern compiles sh:pattern with the flags Go's regexp has, i, s and
+// m. The XPath x and q flags aren't supported
+func readPattern(p *shapeParser, s *shape) ([]constraint, error) {
+	var cs []constraint
+
+	flags := ""
+	if f, ok := p.g.Object(s.node, sh.Flags).(rdf.Literal); ok {
+		for _, r := range f.Lexical {
+			if !strings.ContainsRune("ism", r) {
+				return nil, fmt.Errorf("unsupported sh:flags %q", f.Lexical)
+			}
+		}
+
+		if f.Lexical != "" {
+			flags = "(?" + f.Lexical + ")"
+		}
+	}
+
+	for _, t := range objects(p.g, s.node, sh.Pattern) {
+		pattern, ok := t.(rdf.Literal)
+		if !ok {
+			return nil, fmt.Errorf("sh:pattern must be a literal")
+		}
+
+		re, err := regexp.Compile(flags + pattern.Lexical)
+		if err != nil {
+			return nil, err
+		}
+
+		cs = append(cs, each(sh.PatternConstraintComponent, func(v *validation, value rdf.Term) bool {
+			s, hasStr := str(value)
+			return hasStr && re.MatchString(s)
+		}))
+	}
+
+	return cs, nil
+}
+
+func readLanguageIn(p *shapeParser, s *shape) ([]constraint, error) {
+	var cs []constraint
+
+	for _, list := range objects(p.g, s.node, sh.LanguageIn) {
+		ranges, err := p.g.List(list)
+		if err != nil {
+			return nil, err
+		}
+
+		cs = append(cs, each(sh.LanguageInConstraintComponent, func(v *validation, value rdf.Term) bool {
+			l, ok := value.(rdf.Literal)
+			if !ok || l.Language == "" {
+				return false
+			}
+
+			for _, r := range ranges {
+				if r, ok := r.(rdf.Literal); ok && langMatches(l.Language, r.Lexical) {
+					return true
+				}
+			}
+
+			return false
+		}))
+	}
+
+	return cs, nil
+}
+
+// langMatches is basic filtering of a language tag against a range
+//
+// https://www.rfc-editor.org/rfc/rfc4647#section-3.3.1
+func langMatches(tag, lang string) bool {
+	tag, lang = strings.ToLower(tag), strings.ToLower(lang)
+
+	if lang == "*" {
+		return tag != ""
+	}
+
+	return tag == lang || strings.HasPrefix(tag, lang+"-")
+}
+
+// readUniqueLang reports each language more than one value uses
+func readUniqueLang(p *shapeParser, s *shape) ([]constraint, error) {
+	t := p.g.Object(s.node, sh.UniqueLang)
+	if t == nil {
+		return nil, nil
+	}
+
+	unique, err := booleanParam(t)
+	if err != nil || !unique {
+		return nil, err
+	}
+
+	return []constraint{{component: sh.UniqueLangConstraintComponent, check: func(v *validation, focus rdf.Term, values []rdf.Term) []Result {
+		var results []Result
+		counts := make(map[string]int)
+
+		for _, value := range values {
+			if l, ok := value.(rdf.Literal); ok && l.Language != "" {
+				if counts[l.Language]++; counts[l.Language] == 2 {
+					results = append(results, Result{})
+				}
+			}
+		}
+
+		return results
+	}}}, nil
+}
+
+// readPropertyPairs reads sh:equals, sh:disjoint, sh:lessThan and
+// sh:lessThanOrEquals, comparing the value nodes with the focus node's
+// values of another property
+func readPropertyPairs(p *shapeParser, s *shape) ([]constraint, error) {
+	var cs []constraint
+
+	property := func(t rdf.Term) (rdf.IRI, error) {
+		iri, ok := t.(rdf.IRI)
+		if !ok {
+			return "", fmt.Errorf("%v is not a property", t)
+		}
+
+		return iri, nil
+	}
+
+	for _, t := range objects(p.g, s.node, sh.Equals) {
+		other, err := property(t)
+		if err != nil {
+			return nil, err
+		}
+
+		cs = append(cs, constraint{component: sh.EqualsConstraintComponent, check: func(v *validation, focus rdf.Term, values []rdf.Term) []Result {
+			var results []Result
+			others := predicatePath{other}.values(v.data, focus)
+
+			for _, value := range values {
+				if !containsTerm(others, value) {
+					results = append(results, Result{Value: value})
+				}
+			}
+
+			for _, o := range others {
+				if !containsTerm(values, o) {
+					results = append(results, Result{Value: o})
+				}
+			}
+
+			return results
+		}})
+	}
+
+	for _, t := range objects(p.g, s.node, sh.Disjoint) {
+		other, err := property(t)
+		if err != nil {
+			return nil, err
+		}
+
+		cs = append(cs, constraint{component: sh.DisjointConstraintComponent, check: func(v *validation, focus rdf.Term, values []rdf.Term) []Result {
+			var results []Result
+			others := predicatePath{other}.values(v.data, focus)
+
+			for _, value := range values {
+				if containsTerm(others, value) {
+					results = append(results, Result{Value: value})
+				}
+			}
+
+			return results
+		}})
+	}
+
+	for _, param := range []struct {
+		predicate, component rdf.IRI
+		ok                   func(c int) bool
+	}{
+		{sh.LessThan, sh.LessThanConstraintComponent, func(c int) bool { return c < 0 }},
+		{sh.LessThanOrEquals, sh.LessThanOrEqualsConstraintComponent, func(c int) bool { return c <= 0 }},
+	} {
+		for _, t := range objects(p.g, s.node, param.predicate) {
+			other, err := property(t)
+			if err != nil {
+				return nil, err
+			}
+
+			if s.path == nil {
+				return nil, fmt.Errorf("%v can only be used on property shapes", param.predicate)
+			}
+
+			ok := param.ok
+			cs = append(cs, constraint{component: param.component, check: func(v *validation, focus rdf.Term, values []rdf.Term) []Result {
+				var results []Result
+				others := predicatePath{other}.values(v.data, focus)
+
+				for _, value := range values {
+					for _, o := range others {
+						a, isLiteral := value.(rdf.Literal)
+						b, otherIsLiteral := o.(rdf.Literal)
+
+						var c int
+						err := rdf.ErrIncomparable
+						if isLiteral && otherIsLiteral {
+							c, err = rdf.Compare(a, b)
+						}
+
+						if err != nil || !ok(c) {
+							results = append(results, Result{Value: value})
+							break
+						}
+					}
+				}
+
+				return results
+			}})
+		}
+	}
+
+	return cs, nil
+}
+
+func readNot(p *shapeParser, s *shape) ([]constraint, error) {
+	var cs []constraint
+
+	for _, t := range objects(p.g, s.node, sh.Not) {
+		not, err := p.shape(t)
+		if err != nil {
+			return nil, err
+		}
+
+		cs = append(cs, each(sh.NotConstraintComponent, func(v *validation, value rdf.Term) bool {
+			return !v.conforms(not, value)
+		}))
+	}
+
+	return cs, nil
+}
+
+// readLogical reads sh:and, sh:or and sh:xone, which need all, at least
+// one or exactly one of their shapes to conform
+func readLogical(p *shapeParser, s *shape) ([]constraint, error) {
+	var cs []constraint
+
+	for _, param := range []struct {
+		predicate, component rdf.IRI
+		ok                   func(conforming, total int) bool
+	}{
+		{sh.And, sh.AndConstraintComponent, func(conforming, total int) bool { return conforming == total }},
+		{sh.Or, sh.OrConstraintComponent, func(conforming, total int) bool { return conforming > 0 }},
+		{sh.Xone, sh.XoneConstraintComponent, func(conforming, total int) bool { return conforming == 1 }},
+	} {
+		for _, list := range objects(p.g, s.node, param.predicate) {
+			members, err := p.g.List(list)
+			if err != nil {
+				return nil, err
+			}
+
+			shapes := make([]*shape, len(members))
+			for i, m := range members {
+				if shapes[i], err = p.shape(m); err != nil {
+					return nil, err
+				}
+			}
+
+			ok := param.ok
+			cs = append(cs, each(param.component, func(v *validation, value rdf.Term) bool {
+				conforming := 0
+				for _, s := range shapes {
+					if v.conforms(s, value) {
+						conforming++
+					}
+				}
+
+				return ok(conforming, len(shapes))
+			}))
+		}
+	}
+
+	return cs, nil
+}
+
+func readNode(p *shapeParser, s *shape) ([]constraint, error) {
+	var cs []constraint
+
+	for _, t := range objects(p.g, s.node, sh.Node) {
+		node, err := p.shape(t)
+		if err != nil {
+			return nil, err
+		}
+
+		cs = append(cs, each(sh.NodeConstraintComponent, func(v *validation, value rdf.Term) bool {
+			return v.conforms(node, value)
+		}))
+	}
+
+	return cs, nil
+}
+
+// readQualified reads sh:qualifiedValueShape, counting the values that
+// conform to it. With sh:qualifiedValueShapesDisjoint values that also
+// conform to a sibling's qualified value shape don't count
+func readQualified(p *shapeParser, s *shape) ([]constraint, error) {
+	t := p.g.Object(s.node, sh.QualifiedValueShape)
+	if t == nil {
+		return nil, nil
+	}
+
+	if s.path == nil {
+		return nil, fmt.Errorf("sh:qualifiedValueShape can only be used on property shapes")
+	}
+
+	qualified, err := p.shape(t)
+	if err != nil {
+		return nil, err
+	}
+
+	var siblings []*shape
+
+	if d := p.g.Object(s.node, sh.QualifiedValueShapesDisjoint); d != nil {
+		disjoint, err := booleanParam(d)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, parent := range p.g.Match(nil, sh.Property, s.node) {
+			for _, sibling := range objects(p.g, parent.Subject, sh.Property) {
+				q := p.g.Object(sibling, sh.QualifiedValueShape)
+				if !disjoint || q == nil || rdf.Equal(sibling, s.node) {
+					continue
+				}
+
+				ss, err := p.shape(q)
+				if err != nil {
+					return nil, err
+				}
+
+				siblings = append(siblings, ss)
+			}
+		}
+	}
+
+	count := func(v *validation, values []rdf.Term) int {
+		n := 0
+
+	values:
+		for _, value := range values {
+			if !v.conforms(qualified, value) {
+				continue
+			}
+
+			for _, sibling := range siblings {
+				if v.conforms(sibling, value) {
+					continue values
+				}
+			}
+
+			n++
+		}
+
+		return n
+	}
+
+	var cs []constraint
+
+	for _, param := range []struct {
+		predicate, component rdf.IRI
+		ok                   func(count, limit int) bool
+	}{
+		{sh.QualifiedMinCount, sh.QualifiedMinCountConstraintComponent, func(count, limit int) bool { return count >= limit }},
+		{sh.QualifiedMaxCount, sh.QualifiedMaxCountConstraintComponent, func(count, limit int) bool { return count <= limit }},
+	} {
+		for _, t := range objects(p.g, s.node, param.predicate) {
+			limit, err := integerParam(t)
+			if err != nil {
+				return nil, err
+			}
+
+			ok := param.ok
+			cs = append(cs, constraint{component: param.component, check: func(v *validation, focus rdf.Term, values []rdf.Term) []Result {
+				if ok(count(v, values), limit) {
+					return nil
+				}
+
+				return []Result{{}}
+			}})
+		}
+	}
+
+	return cs, nil
+}
+
+// readClosed reads sh:closed, only the paths of the shape's property
+// shapes and sh:ignoredProperties may be used on a value node
+func readClosed(p *shapeParser, s *shape) ([]constraint, error) {
+	t := p.g.Object(s.node, sh.Closed)
+	if t == nil {
+		return nil, nil
+	}
+
+	closed, err := booleanParam(t)
+	if err != nil || !closed {
+		return nil, err
+	}
+
+	allowed := make(map[string]bool)
+
+	for _, ps := range objects(p.g, s.node, sh.Property) {
+		if iri, ok := p.g.Object(ps, sh.Path).(rdf.IRI); ok {
+			allowed[iri.String()] = true
+		}
+	}
+
+	if list := p.g.Object(s.node, sh.IgnoredProperties); list != nil {
+		ignored, err := p.g.List(list)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, i := range ignored {
+			allowed[i.String()] = true
+		}
+	}
+
+	return []constraint{{component: sh.ClosedConstraintComponent, check: func(v *validation, focus rdf.Term, values []rdf.Term) []Result {
+		var results []Result
+
+		for _, value := range values {
+			for _, t := range v.data.Match(value, nil, nil) {
+				if !allowed[t.Predicate.String()] {
+					results = append(results, Result{Value: t.Object, Path: t.Predicate})
+				}
+			}
+		}
+
+		return results
+	}}}, nil
+}
+
+// readHasValue reports once for the focus node when none of its values is
+// the one required
+func readHasValue(p *shapeParser, s *shape) ([]constraint, error) {
+	var cs []constraint
+
+	for _, want := range objects(p.g, s.node, sh.HasValue) {
+		want := want
+
+		cs = append(cs, constraint{component: sh.HasValueConstraintComponent, check: func(v *validation, focus rdf.Term, values []rdf.Term) []Result {
+			if containsTerm(values, want) {
+				return nil
+			}
+
+			return []Result{{}}
+		}})
+	}
+
+	return cs, nil
+}
+
+func readIn(p *shapeParser, s *shape) ([]constraint, error) {
+	var cs []constraint
+
+	for _, list := range objects(p.g, s.node, sh.In) {
+		allowed, err := p.g.List(list)
+		if err != nil {
+			return nil, err
+		}
+
+		cs = append(cs, each(sh.InConstraintComponent, func(v *validation, value rdf.Term) bool {
+			return containsTerm(allowed, value)
+		}))
+	}
+
+	return cs, nil
+}
+
+// isInstance reports whether the node has the class as its type or a
+// superclass of its type in the data graph
+//
+// https://www.w3.org/TR/shacl/#dfn-shacl-instance
+func (v *validation) isInstance(node, class rdf.Term) bool {
+	for _, t := range v.data.Match(node, rdf.RDFType, nil) {
+		if containsTerm(v.superClasses(t.Object), class) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// superClasses lists the class and everything it is an rdfs:subClassOf of
+func (v *validation) superClasses(class rdf.Term) []rdf.Term {
+	return repeatPath{path: predicatePath{rdfs.SubClassOf}, zero: true, many: true}.values(v.data, class)
+}
@@ -0,0 +1,232 @@
+package shacl
+
+import (
+	"fmt"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/vocab/sh"
+)
+
+// A path is a SHACL property path, giving the value nodes reached from a
+// focus node
+//
+// https://www.w3.org/TR/shacl/#property-paths
+type path interface {
+	values(g *rdf.Graph, node rdf.Term) []rdf.Term
+}
+
+type predicatePath struct {
+	iri rdf.IRI
+}
+
+type inversePath struct {
+	path path
+}
+
+type sequencePath struct {
+	paths []path
+}
+
+type alternativePath struct {
+	paths []path
+}
+
+// repeatPath is sh:zeroOrOnePath, sh:zeroOrMorePath and sh:oneOrMorePath
+type repeatPath struct {
+	path       path
+	zero, many bool
+}
+
+func (p predicatePath) values(g *rdf.Graph, node rdf.Term) []rdf.Term {
+	var out []rdf.Term
+	for _, t := range g.Match(node, p.iri, nil) {
+		out = append(out, t.Object)
+	}
+
+	return out
+}
+
+func (p inversePath) values(g *rdf.Graph, node rdf.Term) []rdf.Term {
+	// Only a predicate can be followed backwards through the index, others
+	// are turned around by checking every node
+	if pp, ok := p.path.(predicatePath); ok {
+		var out []rdf.Term
+		for _, t := range g.Match(nil, pp.iri, node) {
+			out = append(out, t.Subject)
+		}
+
+		return out
+	}
+
+	var out []rdf.Term
+	for _, n := range graphNodes(g) {
+		if containsTerm(p.path.values(g, n), node) {
+			out = append(out, n)
+		}
+	}
+
+	return out
+}
+
+func (p sequencePath) values(g *rdf.Graph, node rdf.Term) []rdf.Term {
+	nodes := []rdf.Term{node}
+
+	for _, step := range p.paths {
+		var next []rdf.Term
+		for _, n := range nodes {
+			next = append(next, step.values(g, n)...)
+		}
+
+		nodes = distinct(next)
+	}
+
+	return nodes
+}
+
+func (p alternativePath) values(g *rdf.Graph, node rdf.Term) []rdf.Term {
+	var out []rdf.Term
+	for _, alt := range p.paths {
+		out = append(out, alt.values(g, node)...)
+	}
+
+	return distinct(out)
+}
+
+// values walks breadth first, each node is visited once so cycles in the
+// data end the walk
+func (p repeatPath) values(g *rdf.Graph, node rdf.Term) []rdf.Term {
+	var out []rdf.Term
+	seen := make(map[string]bool)
+
+	if p.zero {
+		out = append(out, node)
+		seen[node.String()] = true
+	}
+
+	queue := []rdf.Term{node}
+
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+
+		for _, v := range p.path.values(g, n) {
+			if seen[v.String()] {
+				continue
+			}
+
+			seen[v.String()] = true
+			out = append(out, v)
+
+			if p.many {
+				queue = append(queue, v)
+			}
+		}
+	}
+
+	return out
+}
+
+// parsePath reads the path at node in the shapes graph
+func parsePath(g *rdf.Graph, node rdf.Term) (path, error) {
+	if iri, ok := node.(rdf.IRI); ok {
+		return predicatePath{iri: iri}, nil
+	}
+
+	if g.Object(node, rdf.RDFFirst) != nil {
+		members, err := g.List(node)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(members) < 2 {
+			return nil, fmt.Errorf("shacl: sequence path %v needs at least two members", node)
+		}
+
+		paths, err := parsePaths(g, members)
+
+		return sequencePath{paths: paths}, err
+	}
+
+	if alt := g.Object(node, sh.AlternativePath); alt != nil {
+		members, err := g.List(alt)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(members) < 2 {
+			return nil, fmt.Errorf("shacl: alternative path %v needs at least two members", node)
+		}
+
+		paths, err := parsePaths(g, members)
+
+		return alternativePath{paths: paths}, err
+	}
+
+	for _, kind := range []struct {
+		predicate  rdf.IRI
+		zero, many bool
+	}{
+		{sh.ZeroOrMorePath, true, true},
+		{sh.OneOrMorePath, false, true},
+		{sh.ZeroOrOnePath, true, false},
+	} {
+		if inner := g.Object(node, kind.predicate); inner != nil {
+			p, err := parsePath(g, inner)
+			return repeatPath{path: p, zero: kind.zero, many: kind.many}, err
+		}
+	}
+
+	if inner := g.Object(node, sh.InversePath); inner != nil {
+		p, err := parsePath(g, inner)
+		return inversePath{path: p}, err
+	}
+
+	return nil, fmt.Errorf("shacl: %v is not a path", node)
+}
+
+func parsePaths(g *rdf.Graph, nodes []rdf.Term) ([]path, error) {
+	paths := make([]path, len(nodes))
+
+	for i, n := range nodes {
+		var err error
+		if paths[i], err = parsePath(g, n); err != nil {
+			return nil, err
+		}
+	}
+
+	return paths, nil
+}
+
+// graphNodes lists every subject and object in the graph
+func graphNodes(g *rdf.Graph) []rdf.Term {
+	var nodes []rdf.Term
+	for _, t := range g.Triples() {
+		nodes = append(nodes, t.Subject, t.Object)
+	}
+
+	return distinct(nodes)
+}
+
+func distinct(terms []rdf.Term) []rdf.Term {
+	var out []rdf.Term
+	seen := make(map[string]bool)
+
+	for _, t := range terms {
+		if !seen[t.String()] {
+			seen[t.String()] = true
+			out = append(out, t)
+		}
+	}
+
+	return out
+}
+
+func containsTerm(terms []rdf.Term, t rdf.Term) bool {
+	for _, x := range terms {
+		if rdf.Equal(x, t) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,96 @@
+package shacl
+
+import (
+	"strconv"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/vocab/sh"
+)
+
+// A Report is the outcome of validating a data graph, it conforms when
+// there are no results of any severity
+//
+// https://www.w3.org/TR/shacl/#validation-report
+type Report struct {
+	Conforms bool
+	Results  []Result
+
+	// Paths that are blank nodes are copied from here into the report graph
+	shapes *rdf.Graph
+}
+
+// A Result is one problem found with a focus node. Path is the path of the
+// property shape, or the predicate for sh:closed, and Value is nil for
+// constraints on all of a node's values like sh:minCount
+type Result struct {
+	FocusNode                 rdf.Term
+	Path                      rdf.Term
+	Value                     rdf.Term
+	SourceShape               rdf.Term
+	SourceConstraintComponent rdf.IRI
+	Severity                  rdf.IRI
+	Messages                  []rdf.Term
+}
+
+// Graph writes the report in the SHACL vocabulary
+func (r *Report) Graph() *rdf.Graph {
+	g := rdf.NewGraph()
+	g.Prefixes = rdf.PrefixMap{"sh": sh.Namespace}
+
+	report := rdf.BlankNode("report")
+	g.Add(
+		rdf.NewTriple(report, rdf.RDFType, sh.ValidationReport),
+		rdf.NewTriple(report, sh.Conforms, rdf.NewTypedLiteral(strconv.FormatBool(r.Conforms), rdf.XSDBoolean)),
+	)
+
+	copied := make(map[string]rdf.BlankNode)
+
+	for i, res := range r.Results {
+		node := rdf.BlankNode("result" + strconv.Itoa(i+1))
+
+		g.Add(
+			rdf.NewTriple(report, sh.Result, node),
+			rdf.NewTriple(node, rdf.RDFType, sh.ValidationResult),
+			rdf.NewTriple(node, sh.FocusNode, res.FocusNode),
+			rdf.NewTriple(node, sh.ResultSeverity, res.Severity),
+			rdf.NewTriple(node, sh.SourceConstraintComponent, res.SourceConstraintComponent),
+			rdf.NewTriple(node, sh.SourceShape, r.copyNode(g, res.SourceShape, copied)),
+		)
+
+		if res.Path != nil {
+			g.Add(rdf.NewTriple(node, sh.ResultPath, r.copyNode(g, res.Path, copied)))
+		}
+
+		if res.Value != nil {
+			g.Add(rdf.NewTriple(node, sh.Value, res.Value))
+		}
+
+		for _, m := range res.Messages {
+			g.Add(rdf.NewTriple(node, sh.ResultMessage, m))
+		}
+	}
+
+	return g
+}
+
+// copyNode brings a blank node from the shapes graph into the report along
+// with everything it describes, under a new label so it can't clash
+func (r *Report) copyNode(g *rdf.Graph, t rdf.Term, copied map[string]rdf.BlankNode) rdf.Term {
+	b, ok := t.(rdf.BlankNode)
+	if !ok || r.shapes == nil {
+		return t
+	}
+
+	if c, ok := copied[b.String()]; ok {
+		return c
+	}
+
+	c := rdf.BlankNode("shape" + strconv.Itoa(len(copied)+1))
+	copied[b.String()] = c
+
+	for _, triple := range r.shapes.Match(b, nil, nil) {
+		g.Add(rdf.NewTriple(c, triple.Predicate, r.copyNode(g, triple.Object, copied)))
+	}
+
+	return c
+}
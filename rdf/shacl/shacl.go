@@ -0,0 +1,163 @@
+// Package shacl validates graphs against SHACL Core shapes
+//
+// https://www.w3.org/TR/shacl/
+package shacl
+
+import "github.com/b1scuit/solid/rdf"
+
+// A Validator checks data graphs against the shapes it was made with
+type Validator struct {
+	shapes *rdf.Graph
+	all    []*shape
+}
+
+// New reads the shapes in a shapes graph, returning an error for shapes
+// that are malformed, such as a bad list, path or regular expression
+func New(shapes *rdf.Graph) (*Validator, error) {
+	nodes, err := findShapes(shapes)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &shapeParser{g: shapes, shapes: make(map[string]*shape)}
+	v := &Validator{shapes: shapes}
+
+	for _, n := range nodes {
+		s, err := p.shape(n)
+		if err != nil {
+			return nil, err
+		}
+
+		v.all = append(v.all, s)
+	}
+
+	return v, nil
+}
+
+func MustNew(shapes *rdf.Graph) *Validator {
+	v, err := New(shapes)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return v
+}
+
+// Validate checks every target of every shape in the data graph
+func (v *Validator) Validate(data *rdf.Graph) *Report {
+	x := &validation{data: data, active: make(map[string]bool)}
+	r := &Report{Conforms: true, shapes: v.shapes}
+
+	for _, s := range v.all {
+		for _, focus := range x.targets(s) {
+			r.Results = append(r.Results, x.validate(s, focus)...)
+		}
+	}
+
+	r.Conforms = len(r.Results) == 0
+
+	return r
+}
+
+// Conforms checks a single node against a shape, whatever the shape's
+// targets are. The shape has to be one the validator read
+func (v *Validator) Conforms(data *rdf.Graph, node, shape rdf.Term) bool {
+	x := &validation{data: data, active: make(map[string]bool)}
+
+	for _, s := range v.all {
+		if rdf.Equal(s.node, shape) {
+			return x.conforms(s, node)
+		}
+	}
+
+	return false
+}
+
+// validation is one run over a data graph
+type validation struct {
+	data *rdf.Graph
+
+	// Shape and focus node pairs being validated, shapes that refer back
+	// to themselves are taken to conform rather than recursing forever
+	active map[string]bool
+}
+
+// targets lists the focus nodes a shape's target declarations select
+//
+// https://www.w3.org/TR/shacl/#targets
+func (v *validation) targets(s *shape) []rdf.Term {
+	focus := append([]rdf.Term{}, s.targetNodes...)
+
+	for _, class := range s.targetClasses {
+		for _, t := range v.data.Match(nil, rdf.RDFType, nil) {
+			if containsTerm(v.superClasses(t.Object), class) {
+				focus = append(focus, t.Subject)
+			}
+		}
+	}
+
+	for _, p := range s.targetSubjectsOf {
+		for _, t := range v.data.Match(nil, p, nil) {
+			focus = append(focus, t.Subject)
+		}
+	}
+
+	for _, p := range s.targetObjectsOf {
+		for _, t := range v.data.Match(nil, p, nil) {
+			focus = append(focus, t.Object)
+		}
+	}
+
+	return distinct(focus)
+}
+
+// validate checks one focus node against a shape and its property shapes
+func (v *validation) validate(s *shape, focus rdf.Term) []Result {
+	if s.deactivated {
+		return nil
+	}
+
+	key := s.node.String() + " " + focus.String()
+	if v.active[key] {
+		return nil
+	}
+
+	v.active[key] = true
+	defer delete(v.active, key)
+
+	values := []rdf.Term{focus}
+	if s.path != nil {
+		values = distinct(s.path.values(v.data, focus))
+	}
+
+	var results []Result
+
+	for _, c := range s.constraints {
+		for _, r := range c.check(v, focus, values) {
+			r.FocusNode = focus
+			r.SourceShape = s.node
+			r.SourceConstraintComponent = c.component
+			r.Severity = s.severity
+			r.Messages = s.messages
+
+			if r.Path == nil {
+				r.Path = s.pathNode
+			}
+
+			results = append(results, r)
+		}
+	}
+
+	for _, ps := range s.properties {
+		for _, value := range values {
+			results = append(results, v.validate(ps, value)...)
+		}
+	}
+
+	return results
+}
+
+func (v *validation) conforms(s *shape, focus rdf.Term) bool {
+	return len(v.validate(s, focus)) == 0
+}
@@ -0,0 +1,183 @@
+package shacl
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/parser"
+	"github.com/b1scuit/solid/rdf/vocab/sh"
+)
+
+const testPrefixes = `
+@prefix ex: <http://example.org/> .
+@prefix sh: <http://www.w3.org/ns/shacl#> .
+@prefix xsd: <http://www.w3.org/2001/XMLSchema#> .
+@prefix rdfs: <http://www.w3.org/2000/01/rdf-schema#> .
+`
+
+func testGraph(t *testing.T, doc string) *rdf.Graph {
+	p := parser.MustNew()
+
+	if err := p.Do(strings.NewReader(testPrefixes + doc)); err != nil {
+		t.Fatalf("parsing test graph: %v", err)
+	}
+
+	return p.GetGraph()
+}
+
+type validateTest struct {
+	Name   string
+	Shapes string
+	Data   string
+
+	// Each result is written as the focus node's local name and the
+	// constraint component's, sorted
+	Expected []string
+}
+
+var validateTests = []validateTest{
+	{"Min count",
+		`ex:PersonShape a sh:NodeShape ; sh:targetClass ex:Person ;
+			sh:property [ sh:path ex:name ; sh:minCount 1 ; sh:maxCount 1 ] .`,
+		`ex:alice a ex:Person ; ex:name "Alice" .
+		ex:bob a ex:Person .
+		ex:carol a ex:Person ; ex:name "Carol", "Caz" .`,
+		[]string{"bob MinCountConstraintComponent", "carol MaxCountConstraintComponent"}},
+	{"Datatype and range",
+		`ex:S sh:targetSubjectsOf ex:age ;
+			sh:property [ sh:path ex:age ; sh:datatype xsd:integer ; sh:minInclusive 0 ; sh:maxExclusive 150 ] .`,
+		`ex:a ex:age 30 . ex:b ex:age "thirty" . ex:c ex:age -1 . ex:d ex:age 150 .`,
+		[]string{"b DatatypeConstraintComponent", "b MaxExclusiveConstraintComponent", "b MinInclusiveConstraintComponent",
+			"c MinInclusiveConstraintComponent", "d MaxExclusiveConstraintComponent"}},
+	{"Class through subclasses",
+		`ex:S sh:targetNode ex:a, ex:b ; sh:property [ sh:path ex:pet ; sh:class ex:Animal ] .`,
+		`ex:Dog rdfs:subClassOf ex:Animal . ex:rex a ex:Dog . ex:rock a ex:Mineral .
+		ex:a ex:pet ex:rex . ex:b ex:pet ex:rock .`,
+		[]string{"b ClassConstraintComponent"}},
+	{"Node kind, pattern and length",
+		`ex:S sh:targetSubjectsOf ex:code ;
+			sh:property [ sh:path ex:code ; sh:nodeKind sh:Literal ; sh:pattern "^[a-z]+$" ; sh:flags "i" ; sh:maxLength 4 ] .`,
+		`ex:a ex:code "AbC" . ex:b ex:code "ab1" . ex:c ex:code ex:x . ex:d ex:code "abcde" .`,
+		[]string{"b PatternConstraintComponent", "c MaxLengthConstraintComponent", "c NodeKindConstraintComponent", "c PatternConstraintComponent",
+			"d MaxLengthConstraintComponent"}},
+	{"In and has value",
+		`ex:S sh:targetSubjectsOf ex:colour ;
+			sh:property [ sh:path ex:colour ; sh:in ( ex:red ex:green ) ] ;
+			sh:property [ sh:path ex:tag ; sh:hasValue "ok" ] .`,
+		`ex:a ex:colour ex:red ; ex:tag "ok" . ex:b ex:colour ex:blue ; ex:tag "no" .`,
+		[]string{"b HasValueConstraintComponent", "b InConstraintComponent"}},
+	{"Closed",
+		`ex:S sh:targetClass ex:Thing ; sh:closed true ; sh:ignoredProperties ( <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> ) ;
+			sh:property [ sh:path ex:name ] .`,
+		`ex:a a ex:Thing ; ex:name "A" . ex:b a ex:Thing ; ex:name "B" ; ex:extra 1 .`,
+		[]string{"b ClosedConstraintComponent"}},
+	{"Logical",
+		`ex:HasName sh:property [ sh:path ex:name ; sh:minCount 1 ] .
+		ex:HasNick sh:property [ sh:path ex:nick ; sh:minCount 1 ] .
+		ex:S sh:targetClass ex:Person ; sh:or ( ex:HasName ex:HasNick ) ; sh:not [ sh:property [ sh:path ex:banned ; sh:hasValue true ] ] .`,
+		`ex:a a ex:Person ; ex:name "A" . ex:b a ex:Person ; ex:nick "B" . ex:c a ex:Person .
+		ex:d a ex:Person ; ex:name "D" ; ex:banned true .`,
+		[]string{"c OrConstraintComponent", "d NotConstraintComponent"}},
+	{"Node and path",
+		`ex:Address sh:property [ sh:path ex:city ; sh:minCount 1 ] .
+		ex:S sh:targetClass ex:Person ; sh:property [ sh:path ( ex:home ex:address ) ; sh:node ex:Address ] .`,
+		`ex:a a ex:Person ; ex:home [ ex:address [ ex:city "Paris" ] ] .
+		ex:b a ex:Person ; ex:home [ ex:address [ ex:street "Main" ] ] .`,
+		[]string{"b NodeConstraintComponent"}},
+	{"Qualified count",
+		`ex:S sh:targetClass ex:Hand ;
+			sh:property [ sh:path ex:digit ; sh:qualifiedValueShape [ sh:class ex:Thumb ] ; sh:qualifiedMinCount 1 ; sh:qualifiedMaxCount 1 ] .`,
+		`ex:t1 a ex:Thumb . ex:t2 a ex:Thumb . ex:f a ex:Finger .
+		ex:a a ex:Hand ; ex:digit ex:t1, ex:f . ex:b a ex:Hand ; ex:digit ex:f . ex:c a ex:Hand ; ex:digit ex:t1, ex:t2 .`,
+		[]string{"b QualifiedMinCountConstraintComponent", "c QualifiedMaxCountConstraintComponent"}},
+	{"Recursive shape",
+		`ex:S sh:targetNode ex:a ; sh:property [ sh:path ex:knows ; sh:node ex:S ] ; sh:property [ sh:path ex:name ; sh:minCount 1 ] .`,
+		`ex:a ex:name "A" ; ex:knows ex:b . ex:b ex:name "B" ; ex:knows ex:a .`,
+		nil},
+	{"Deactivated",
+		`ex:S sh:targetNode ex:a ; sh:deactivated true ; sh:property [ sh:path ex:name ; sh:minCount 1 ] .`,
+		`ex:a ex:knows ex:b .`,
+		nil},
+}
+
+func localName(t rdf.Term) string {
+	s := t.String()
+	s = strings.Trim(s[strings.LastIndexAny(s, "/#")+1:], "<>")
+
+	return s
+}
+
+func TestValidate(t *testing.T) {
+	for _, tc := range validateTests {
+		v, err := New(testGraph(t, tc.Shapes))
+		if err != nil {
+			t.Errorf("%v test fail: %v", tc.Name, err)
+			continue
+		}
+
+		r := v.Validate(testGraph(t, tc.Data))
+
+		var got []string
+		for _, res := range r.Results {
+			got = append(got, localName(res.FocusNode)+" "+localName(res.SourceConstraintComponent))
+		}
+
+		sort.Strings(got)
+
+		if strings.Join(got, "\n") != strings.Join(tc.Expected, "\n") {
+			t.Errorf("%v test fail: got %q", tc.Name, got)
+		}
+
+		if r.Conforms != (len(tc.Expected) == 0) {
+			t.Errorf("%v test fail: conforms is %v", tc.Name, r.Conforms)
+		}
+	}
+}
+
+func TestReportGraph(t *testing.T) {
+	v := MustNew(testGraph(t, `ex:S sh:targetNode ex:a ;
+		sh:property [ sh:path [ sh:inversePath ex:parent ] ; sh:minCount 1 ; sh:message "needs a child" ] .`))
+
+	g := v.Validate(testGraph(t, `ex:b ex:parent ex:c .`)).Graph()
+
+	report := rdf.BlankNode("report")
+	if c, ok := g.Object(report, sh.Conforms).(rdf.Literal); !ok || c.Lexical != "false" {
+		t.Fatalf("report graph test fail: conforms is %v", g.Object(report, sh.Conforms))
+	}
+
+	result := g.Object(report, sh.Result)
+	if result == nil {
+		t.Fatalf("report graph test fail: no result")
+	}
+
+	if !rdf.Equal(g.Object(result, sh.FocusNode), rdf.IRI("http://example.org/a")) {
+		t.Errorf("report graph test fail: focus node is %v", g.Object(result, sh.FocusNode))
+	}
+
+	if !rdf.Equal(g.Object(result, sh.ResultSeverity), sh.Violation) {
+		t.Errorf("report graph test fail: severity is %v", g.Object(result, sh.ResultSeverity))
+	}
+
+	// The blank node path is copied across with the report's own label
+	if inverse := g.Object(g.Object(result, sh.ResultPath), sh.InversePath); !rdf.Equal(inverse, rdf.IRI("http://example.org/parent")) {
+		t.Errorf("report graph test fail: result path inverse is %v", inverse)
+	}
+
+	if m, ok := g.Object(result, sh.ResultMessage).(rdf.Literal); !ok || m.Lexical != "needs a child" {
+		t.Errorf("report graph test fail: message is %v", g.Object(result, sh.ResultMessage))
+	}
+}
+
+func TestMalformedShapes(t *testing.T) {
+	for _, doc := range []string{
+		`ex:S sh:targetNode ex:a ; sh:property [ sh:minCount 1 ] .`,
+		`ex:S sh:targetNode ex:a ; sh:pattern "(" .`,
+		`ex:S sh:targetNode ex:a ; sh:minCount "many" .`,
+	} {
+		if _, err := New(testGraph(t, doc)); err == nil {
+			t.Errorf("malformed shapes test fail: no error for %q", doc)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package shacl
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/vocab/owl"
+	"github.com/b1scuit/solid/rdf/vocab/rdfs"
+	"github.com/b1scuit/solid/rdf/vocab/sh"
+)
+
+// A shape is a node or property shape read from the shapes graph
+//
+// https://www.w3.org/TR/shacl/#shapes
+type shape struct {
+	node rdf.Term
+
+	// Only property shapes have a path
+	path     path
+	pathNode rdf.Term
+
+	deactivated bool
+	severity    rdf.IRI
+	messages    []rdf.Term
+
+	targetClasses    []rdf.Term
+	targetNodes      []rdf.Term
+	targetSubjectsOf []rdf.Term
+	targetObjectsOf  []rdf.Term
+
+	properties  []*shape
+	constraints []constraint
+}
+
+// A constraint checks the value nodes of a focus node, returning a result
+// for each problem with at least Value set where there is one
+type constraint struct {
+	component rdf.IRI
+	check     func(v *validation, focus rdf.Term, values []rdf.Term) []Result
+}
+
+// shapeReferences are the predicates whose objects are shapes
+var shapeReferences = []rdf.IRI{sh.Property, sh.Node, sh.Not, sh.QualifiedValueShape}
+
+// shapeLists are the predicates whose objects are lists of shapes
+var shapeLists = []rdf.IRI{sh.And, sh.Or, sh.Xone}
+
+// findShapes lists every node in the shapes graph that is a shape, by
+// type, by having targets or a path, or by being used as a shape
+func findShapes(g *rdf.Graph) ([]rdf.Term, error) {
+	var nodes []rdf.Term
+
+	for _, class := range []rdf.IRI{sh.NodeShape, sh.PropertyShape} {
+		for _, t := range g.Match(nil, rdf.RDFType, class) {
+			nodes = append(nodes, t.Subject)
+		}
+	}
+
+	for _, p := range []rdf.IRI{sh.TargetClass, sh.TargetNode, sh.TargetSubjectsOf, sh.TargetObjectsOf, sh.Path} {
+		for _, t := range g.Match(nil, p, nil) {
+			nodes = append(nodes, t.Subject)
+		}
+	}
+
+	for _, p := range shapeReferences {
+		for _, t := range g.Match(nil, p, nil) {
+			nodes = append(nodes, t.Object)
+		}
+	}
+
+	for _, p := range shapeLists {
+		for _, t := range g.Match(nil, p, nil) {
+			members, err := g.List(t.Object)
+			if err != nil {
+				return nil, err
+			}
+
+			nodes = append(nodes, members...)
+		}
+	}
+
+	return distinct(nodes), nil
+}
+
+// shapeParser reads shapes, each node once so shapes can refer to each
+// other and themselves
+type shapeParser struct {
+	g      *rdf.Graph
+	shapes map[string]*shape
+}
+
+func (p *shapeParser) shape(node rdf.Term) (*shape, error) {
+	if s, ok := p.shapes[node.String()]; ok {
+		return s, nil
+	}
+
+	g := p.g
+	s := &shape{node: node, severity: sh.Violation}
+	p.shapes[node.String()] = s
+
+	if pathNode := g.Object(node, sh.Path); pathNode != nil {
+		var err error
+		if s.path, err = parsePath(g, pathNode); err != nil {
+			return nil, err
+		}
+
+		s.pathNode = pathNode
+	}
+
+	if b, ok := g.Object(node, sh.Deactivated).(rdf.Literal); ok {
+		s.deactivated = b.Lexical == "true" || b.Lexical == "1"
+	}
+
+	if severity, ok := g.Object(node, sh.SeverityProperty).(rdf.IRI); ok {
+		s.severity = severity
+	}
+
+	s.messages = objects(g, node, sh.Message)
+	s.targetClasses = objects(g, node, sh.TargetClass)
+	s.targetNodes = objects(g, node, sh.TargetNode)
+	s.targetSubjectsOf = objects(g, node, sh.TargetSubjectsOf)
+	s.targetObjectsOf = objects(g, node, sh.TargetObjectsOf)
+
+	// A shape that is also a class targets its instances
+	if len(g.Match(node, rdf.RDFType, rdfs.Class)) > 0 || len(g.Match(node, rdf.RDFType, owl.Class)) > 0 {
+		s.targetClasses = append(s.targetClasses, node)
+	}
+
+	for _, n := range objects(g, node, sh.Property) {
+		ps, err := p.shape(n)
+		if err != nil {
+			return nil, err
+		}
+
+		if ps.path == nil {
+			return nil, fmt.Errorf("shacl: property shape %v has no sh:path", n)
+		}
+
+		s.properties = append(s.properties, ps)
+	}
+
+	for _, read := range constraintReaders {
+		cs, err := read(p, s)
+		if err != nil {
+			return nil, fmt.Errorf("shacl: shape %v: %w", node, err)
+		}
+
+		s.constraints = append(s.constraints, cs...)
+	}
+
+	return s, nil
+}
+
+func objects(g *rdf.Graph, s rdf.Term, p rdf.IRI) []rdf.Term {
+	var out []rdf.Term
+	for _, t := range g.Match(s, p, nil) {
+		out = append(out, t.Object)
+	}
+
+	return out
+}
+
+// integerParam reads a parameter that has to be a non negative integer
+func integerParam(t rdf.Term) (int, error) {
+	if l, ok := t.(rdf.Literal); ok {
+		if v, err := l.Value(); err == nil {
+			if i, ok := v.(*big.Int); ok && i.Sign() >= 0 && i.IsInt64() {
+				return int(i.Int64()), nil
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("%v is not a non negative integer", t)
+}
+
+func booleanParam(t rdf.Term) (bool, error) {
+	if l, ok := t.(rdf.Literal); ok {
+		if v, err := l.Value(); err == nil {
+			if b, ok := v.(bool); ok {
+				return b, nil
+			}
+		}
+	}
+
+	return false, fmt.Errorf("%v is not a boolean", t)
+}
@@ -0,0 +1,182 @@
+package shex
+
+import (
+	"github.com/b1scuit/solid/rdf"
+)
+
+// Unbounded is the maximum of a cardinality without one, like * and +
+const Unbounded = -1
+
+// A Schema is a set of labelled shape expressions, following the
+// structure of ShExJ
+//
+// https://shex.io/shex-semantics/#shapes-schemas
+type Schema struct {
+	Start  ShapeExpr
+	Shapes []*ShapeDecl
+
+	// Imports are recorded but not followed
+	Imports []rdf.IRI
+
+	Prefixes rdf.PrefixMap
+}
+
+// A ShapeDecl gives a shape expression a label, an IRI or blank node, so
+// it can be referred to
+type ShapeDecl struct {
+	Label rdf.Term
+	Expr  ShapeExpr
+}
+
+// A ShapeExpr is one of ShapeOr, ShapeAnd, ShapeNot, NodeConstraint,
+// Shape, ShapeExternal or ShapeRef
+type ShapeExpr interface {
+	shapeExpr()
+}
+
+type ShapeOr struct {
+	Exprs []ShapeExpr
+}
+
+type ShapeAnd struct {
+	Exprs []ShapeExpr
+}
+
+type ShapeNot struct {
+	Expr ShapeExpr
+}
+
+// A ShapeRef refers to the shape declared with the label
+type ShapeRef struct {
+	Label rdf.Term
+}
+
+// A ShapeExternal is defined outside the schema, it can't be validated
+type ShapeExternal struct{}
+
+// Node kinds of a NodeConstraint
+const (
+	NodeKindIRI        = "iri"
+	NodeKindBNode      = "bnode"
+	NodeKindNonLiteral = "nonliteral"
+	NodeKindLiteral    = "literal"
+)
+
+// A NodeConstraint checks a node on its own, the zero value allows any
+// node. Facets that aren't set are nil
+//
+// https://shex.io/shex-semantics/#node-constraints
+type NodeConstraint struct {
+	NodeKind string
+	Datatype rdf.IRI
+	Values   []ValueSetValue
+
+	Length    *int
+	MinLength *int
+	MaxLength *int
+	Pattern   string
+	Flags     string
+
+	MinInclusive *rdf.Literal
+	MinExclusive *rdf.Literal
+	MaxInclusive *rdf.Literal
+	MaxExclusive *rdf.Literal
+
+	TotalDigits    *int
+	FractionDigits *int
+}
+
+// A Shape checks the triples around a node against its triple expression
+//
+// https://shex.io/shex-semantics/#shapes-and-TEs
+type Shape struct {
+	Closed     bool
+	Extra      []rdf.IRI
+	Expression TripleExpr
+}
+
+func (ShapeOr) shapeExpr()         {}
+func (ShapeAnd) shapeExpr()        {}
+func (ShapeNot) shapeExpr()        {}
+func (ShapeRef) shapeExpr()        {}
+func (ShapeExternal) shapeExpr()   {}
+func (*NodeConstraint) shapeExpr() {}
+func (*Shape) shapeExpr()          {}
+
+// A TripleExpr is one of EachOf, OneOf, TripleConstraint or
+// TripleExprRef. Each of them can be given a label to be included
+// elsewhere
+type TripleExpr interface {
+	tripleExpr()
+}
+
+// EachOf needs all of its expressions to match, Min and Max are how many
+// times the group repeats
+type EachOf struct {
+	Label    rdf.Term
+	Exprs    []TripleExpr
+	Min, Max int
+}
+
+// OneOf needs exactly one of its expressions to match
+type OneOf struct {
+	Label    rdf.Term
+	Exprs    []TripleExpr
+	Min, Max int
+}
+
+// A TripleConstraint matches triples with the predicate whose objects, or
+// subjects when Inverse is set, conform to ValueExpr. A nil ValueExpr
+// allows any value
+type TripleConstraint struct {
+	Label     rdf.Term
+	Inverse   bool
+	Predicate rdf.IRI
+	ValueExpr ShapeExpr
+	Min, Max  int
+}
+
+// A TripleExprRef includes the triple expression with the label
+type TripleExprRef struct {
+	Label rdf.Term
+}
+
+func (*EachOf) tripleExpr()           {}
+func (*OneOf) tripleExpr()            {}
+func (*TripleConstraint) tripleExpr() {}
+func (TripleExprRef) tripleExpr()     {}
+
+// A ValueSetValue is one of ObjectValue, Language or Stem
+//
+// https://shex.io/shex-semantics/#values
+type ValueSetValue interface {
+	matches(t rdf.Term) bool
+}
+
+// An ObjectValue matches exactly the IRI or literal
+type ObjectValue struct {
+	Term rdf.Term
+}
+
+// A Language matches literals with the language tag, compared without
+// case
+type Language struct {
+	Tag string
+}
+
+// Kinds of Stem
+const (
+	StemIRI      = "iri"
+	StemLiteral  = "literal"
+	StemLanguage = "language"
+)
+
+// A Stem matches IRIs or literals starting with the stem, or literals
+// whose language tag is the stem or a subtag of it. A wildcard matches
+// every node of the kind, both can have exclusions
+type Stem struct {
+	Kind       string
+	Stem       string
+	Wildcard   bool
+	Exclusions []ValueSetValue
+}
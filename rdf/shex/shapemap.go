@@ -0,0 +1,209 @@
+package shex
+
+import (
+	"strings"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/lexer/lexertoken"
+)
+
+// A ShapeMap says which nodes to check against which shapes
+//
+// https://shexspec.github.io/shape-map/
+type ShapeMap []Association
+
+// An Association pairs a node, or the nodes a selector picks, with the
+// label of a shape. A nil Shape is the schema's start shape
+type Association struct {
+	Node     rdf.Term
+	Selector *Selector
+	Shape    rdf.Term
+}
+
+// A Selector picks the subjects of triples with the predicate, and the
+// object if Node is set, or with Inverse the objects of triples with the
+// predicate and the subject if Node is set
+type Selector struct {
+	Predicate rdf.IRI
+	Node      rdf.Term
+	Inverse   bool
+}
+
+// A Result is the outcome of checking one node against one shape, the
+// reason says why a node doesn't conform
+type Result struct {
+	Node       rdf.Term
+	Shape      rdf.Term
+	Conformant bool
+	Reason     string
+}
+
+func (a Association) nodes(g *rdf.Graph) []rdf.Term {
+	if a.Selector == nil {
+		return []rdf.Term{a.Node}
+	}
+
+	var nodes []rdf.Term
+	seen := make(map[string]bool)
+
+	add := func(t rdf.Term) {
+		if !seen[t.String()] {
+			seen[t.String()] = true
+			nodes = append(nodes, t)
+		}
+	}
+
+	s := a.Selector
+
+	if s.Inverse {
+		for _, t := range g.Match(s.Node, s.Predicate, nil) {
+			add(t.Object)
+		}
+	} else {
+		for _, t := range g.Match(nil, s.Predicate, s.Node) {
+			add(t.Subject)
+		}
+	}
+
+	return nodes
+}
+
+// ParseShapeMap reads a shape map such as <node>@<Shape>, with query
+// selectors like {FOCUS a foaf:Person}@START. Prefixed names are expanded
+// with the prefixes, usually the schema's
+func ParseShapeMap(input string, prefixes rdf.PrefixMap) (ShapeMap, error) {
+	p, err := newShexcParser(input, "")
+	if err != nil {
+		return nil, err
+	}
+
+	p.prefixes = prefixes.Clone()
+
+	var m ShapeMap
+
+	for p.peek().Type != lexertoken.TOKEN_EOF {
+		if len(m) > 0 {
+			if _, err := p.expect(lexertoken.TOKEN_OBJECT); err != nil {
+				return nil, err
+			}
+		}
+
+		a, err := p.parseShapeAssociation()
+		if err != nil {
+			return nil, err
+		}
+
+		m = append(m, a)
+	}
+
+	return m, nil
+}
+
+// shapeAssociation	::=	nodeSelector shapeLabel
+// shapeLabel	::=	'@' (iri | 'START')
+func (p *shexcParser) parseShapeAssociation() (Association, error) {
+	var a Association
+
+	if p.peek().Type == lexertoken.TOKEN_START_GROUP {
+		s, err := p.parseSelector()
+		if err != nil {
+			return a, err
+		}
+
+		a.Selector = s
+	} else {
+		node, err := p.parseObjectTerm()
+		if err != nil {
+			return a, err
+		}
+
+		a.Node = node
+	}
+
+	// @START is lexed as a language tag
+	if t := p.peek(); t.Type == lexertoken.TOKEN_LANGTAG && strings.EqualFold(t.Value, "START") {
+		p.next()
+		return a, nil
+	}
+
+	if !p.acceptOperator("@") {
+		return a, p.unexpected(p.peek(), "@ and a shape label")
+	}
+
+	if p.acceptKeyword("START") {
+		return a, nil
+	}
+
+	label, err := p.parseLabel()
+	a.Shape = label
+
+	return a, err
+}
+
+// triplePattern	::=	'{' 'FOCUS' predicate (objectTerm | '_') '}'
+// | '{' (subjectTerm | '_') predicate 'FOCUS' '}'
+func (p *shexcParser) parseSelector() (*Selector, error) {
+	p.next()
+
+	s := &Selector{}
+
+	if !p.acceptKeyword("FOCUS") {
+		s.Inverse = true
+
+		if !p.acceptOperator("_") {
+			node, err := p.parseObjectTerm()
+			if err != nil {
+				return nil, err
+			}
+
+			s.Node = node
+		}
+	}
+
+	predicate, err := p.parsePredicate()
+	if err != nil {
+		return nil, err
+	}
+
+	s.Predicate = predicate
+
+	if s.Inverse {
+		if err := p.expectKeyword("FOCUS"); err != nil {
+			return nil, err
+		}
+	} else if !p.acceptOperator("_") {
+		node, err := p.parseObjectTerm()
+		if err != nil {
+			return nil, err
+		}
+
+		s.Node = node
+	}
+
+	_, err = p.expect(lexertoken.TOKEN_END_GROUP)
+
+	return s, err
+}
+
+func (p *shexcParser) parseObjectTerm() (rdf.Term, error) {
+	switch t := p.peek(); t.Type {
+	case lexertoken.TOKEN_IRIREF, lexertoken.TOKEN_PREFIXED_NAME, lexertoken.TOKEN_BLANK_NODE:
+		return p.parseLabel()
+	case lexertoken.TOKEN_LITERAL:
+		// The @START after a plain literal isn't its language
+		if next := p.peekAt(1); next.Type == lexertoken.TOKEN_LANGTAG && strings.EqualFold(next.Value, "START") {
+			p.next()
+			return rdf.NewLiteral(t.Value), nil
+		}
+	}
+
+	return p.parseLiteral()
+}
+
+func (p *shexcParser) expectKeyword(keyword string) error {
+	if !p.acceptKeyword(keyword) {
+		return p.unexpected(p.peek(), keyword)
+	}
+
+	return nil
+}
@@ -0,0 +1,240 @@
+package shex
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/parser"
+)
+
+const testSchema = `
+PREFIX ex: <http://example.org/>
+PREFIX foaf: <http://xmlns.com/foaf/0.1/>
+PREFIX xsd: <http://www.w3.org/2001/XMLSchema#>
+
+start = @ex:Person
+
+ex:Person {
+  a [foaf:Person] ? ;
+  foaf:name xsd:string MINLENGTH 1 ;
+  foaf:age xsd:integer MININCLUSIVE 0 MAXINCLUSIVE 150 ? ;
+  foaf:mbox IRI /^mailto:/ * ;
+  foaf:knows @ex:Person * ;
+  ( ex:nickname LITERAL | ex:alias LITERAL ) ?
+}
+
+ex:Employee @ex:Person AND {
+  ex:employer IRI ;
+  ex:status [ex:active ex:retired] ;
+  ^ex:manages @ex:Person ?
+}
+
+ex:Closed CLOSED EXTRA ex:tag {
+  ex:tag ["a" "b"] ;
+  ex:lang [@en~ - @en-gb] *
+}
+
+ex:NotBob NOT { foaf:name ["Bob"] }
+`
+
+const testData = `
+@prefix ex: <http://example.org/> .
+@prefix foaf: <http://xmlns.com/foaf/0.1/> .
+
+ex:alice a foaf:Person ; foaf:name "Alice" ; foaf:age 30 ; foaf:mbox <mailto:alice@example.org> ; foaf:knows ex:bob .
+ex:bob foaf:name "Bob" ; foaf:knows ex:alice ; ex:nickname "B" .
+ex:carol foaf:name "Carol" ; foaf:age 200 .
+ex:dave foaf:name "Dave", "David" .
+ex:erin foaf:name "Erin" ; foaf:mbox <http://example.org/erin> .
+ex:frank foaf:name "Frank" ; ex:nickname "F" ; ex:alias "Frankie" .
+ex:grace foaf:name "Grace" ; foaf:knows ex:dave .
+
+ex:alice ex:employer ex:acme ; ex:status ex:active .
+ex:carol ex:manages ex:alice .
+ex:bob ex:employer ex:acme ; ex:status ex:fired .
+
+ex:c1 ex:tag "a", "z" ; ex:lang "colour"@en-us .
+ex:c2 ex:tag "a" ; ex:lang "colour"@en-gb .
+ex:c3 ex:tag "b" ; ex:other 1 .
+`
+
+func testGraph(t *testing.T) *rdf.Graph {
+	p := parser.MustNew()
+
+	if err := p.Do(strings.NewReader(testData)); err != nil {
+		t.Fatalf("parsing test data: %v", err)
+	}
+
+	return p.GetGraph()
+}
+
+type validateTest struct {
+	Name     string
+	ShapeMap string
+
+	// Whether each selected node conforms, in order
+	Expected []bool
+}
+
+var validateTests = []validateTest{
+	{"Conforms", "ex:alice@ex:Person, ex:bob@ex:Person", []bool{true, true}},
+	{"Start", "ex:alice@START, ex:grace@START", []bool{true, false}},
+	{"Numeric facet", "ex:carol@ex:Person", []bool{false}},
+	{"Cardinality", "ex:dave@ex:Person", []bool{false}},
+	{"Pattern", "ex:erin@ex:Person", []bool{false}},
+	{"One of", "ex:frank@ex:Person", []bool{false}},
+	{"Recursion through a nonconforming node", "ex:grace@ex:Person", []bool{false}},
+	{"And with inverse", "ex:alice@ex:Employee, ex:bob@ex:Employee", []bool{false, false}},
+	{"Closed, extra and language stems", "ex:c1@ex:Closed, ex:c2@ex:Closed, ex:c3@ex:Closed", []bool{true, false, false}},
+	{"Not", "ex:alice@ex:NotBob, ex:bob@ex:NotBob", []bool{true, false}},
+	{"Query selector", "{FOCUS foaf:age _}@ex:Person", []bool{true, false}},
+	{"Inverse query selector", "{ex:alice foaf:knows FOCUS}@ex:Person", []bool{true}},
+}
+
+func TestValidate(t *testing.T) {
+	schema, err := ParseShExC(testSchema, "")
+	if err != nil {
+		t.Fatalf("parsing test schema: %v", err)
+	}
+
+	v := MustNew(schema)
+	g := testGraph(t)
+
+	for _, tc := range validateTests {
+		m, err := ParseShapeMap(tc.ShapeMap, schema.Prefixes)
+		if err != nil {
+			t.Errorf("%v test fail: %v", tc.Name, err)
+			continue
+		}
+
+		results := v.Validate(g, m)
+
+		var got []bool
+		for _, r := range results {
+			got = append(got, r.Conformant)
+
+			if !r.Conformant && r.Reason == "" {
+				t.Errorf("%v test fail: no reason for %v", tc.Name, r.Node)
+			}
+		}
+
+		if !reflect.DeepEqual(got, tc.Expected) {
+			t.Errorf("%v test fail: got %v, results %+v", tc.Name, got, results)
+		}
+	}
+}
+
+func TestReasons(t *testing.T) {
+	v := MustNew(mustParse(t, testSchema))
+	g := testGraph(t)
+
+	for node, reason := range map[string]string{
+		"http://example.org/dave":  `has 2 <http://xmlns.com/foaf/0.1/name>, expected exactly 1`,
+		"http://example.org/carol": `is not at most 150`,
+		"http://example.org/c3":    `closed shape doesn't allow <http://example.org/other>`,
+	} {
+		shape := rdf.IRI("http://example.org/Person")
+		if strings.HasSuffix(node, "c3") {
+			shape = "http://example.org/Closed"
+		}
+
+		results := v.Validate(g, ShapeMap{{Node: rdf.IRI(node), Shape: shape}})
+		if len(results) != 1 || !strings.Contains(results[0].Reason, reason) {
+			t.Errorf("reasons test fail: %v got %+v", node, results)
+		}
+	}
+}
+
+func mustParse(t *testing.T, s string) *Schema {
+	schema, err := ParseShExC(s, "")
+	if err != nil {
+		t.Fatalf("parsing schema: %v", err)
+	}
+
+	return schema
+}
+
+func TestShExJ(t *testing.T) {
+	const shexc = `
+PREFIX ex: <http://example.org/>
+PREFIX xsd: <http://www.w3.org/2001/XMLSchema#>
+ex:S CLOSED {
+  $ex:name ex:name LITERAL /^[A-Z]/i {1,3} ;
+  ex:score xsd:decimal MINEXCLUSIVE 0.5 ? ;
+  ( ex:a [ex:x~ - ex:xy] | ^ex:b @ex:T ) +
+}
+ex:T NOT BNODE OR IRI
+ex:U { &ex:name ; ex:lang [@fr "chat"@en . - "x"~] }
+`
+
+	const shexj = `{
+  "@context": "http://www.w3.org/ns/shex.jsonld",
+  "type": "Schema",
+  "shapes": [
+    {"type": "ShapeDecl", "id": "http://example.org/S", "shapeExpr": {
+      "type": "Shape", "closed": true, "expression": {
+        "type": "EachOf", "expressions": [
+          {"type": "TripleConstraint", "id": "http://example.org/name", "predicate": "http://example.org/name",
+           "valueExpr": {"type": "NodeConstraint", "nodeKind": "literal", "pattern": "^[A-Z]", "flags": "i"}, "min": 1, "max": 3},
+          {"type": "TripleConstraint", "predicate": "http://example.org/score",
+           "valueExpr": {"type": "NodeConstraint", "datatype": "http://www.w3.org/2001/XMLSchema#decimal", "minexclusive": 0.5}, "min": 0, "max": 1},
+          {"type": "OneOf", "min": 1, "max": -1, "expressions": [
+            {"type": "TripleConstraint", "predicate": "http://example.org/a",
+             "valueExpr": {"type": "NodeConstraint", "values": [
+               {"type": "IriStemRange", "stem": "http://example.org/x", "exclusions": ["http://example.org/xy"]}]}},
+            {"type": "TripleConstraint", "inverse": true, "predicate": "http://example.org/b", "valueExpr": "http://example.org/T"}
+          ]}
+        ]}}},
+    {"id": "http://example.org/T", "type": "ShapeOr", "shapeExprs": [
+      {"type": "ShapeNot", "shapeExpr": {"type": "NodeConstraint", "nodeKind": "bnode"}},
+      {"type": "NodeConstraint", "nodeKind": "iri"}]},
+    {"type": "ShapeDecl", "id": "http://example.org/U", "shapeExpr": {
+      "type": "Shape", "expression": {"type": "EachOf", "expressions": [
+        "http://example.org/name",
+        {"type": "TripleConstraint", "predicate": "http://example.org/lang", "valueExpr": {"type": "NodeConstraint", "values": [
+          {"type": "Language", "languageTag": "fr"},
+          {"value": "chat", "language": "en"},
+          {"type": "LiteralStemRange", "stem": {"type": "Wildcard"}, "exclusions": [{"type": "LiteralStem", "stem": "x"}]}
+        ]}}
+      ]}}}
+  ]
+}`
+
+	fromC := mustParse(t, shexc)
+
+	fromJ, err := ReadShExJ(strings.NewReader(shexj))
+	if err != nil {
+		t.Fatalf("ShExJ test fail: %v", err)
+	}
+
+	if !reflect.DeepEqual(fromC.Shapes, fromJ.Shapes) {
+		for i := range fromC.Shapes {
+			if !reflect.DeepEqual(fromC.Shapes[i], fromJ.Shapes[i]) {
+				t.Errorf("ShExJ test fail: shape %v differs\nShExC %#v\nShExJ %#v", fromC.Shapes[i].Label, fromC.Shapes[i].Expr, fromJ.Shapes[i].Expr)
+			}
+		}
+	}
+
+	if _, err := New(fromJ); err != nil {
+		t.Errorf("ShExJ test fail: %v", err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, s := range []string{
+		`PREFIX ex: <http://example.org/> ex:S { ex:p . `,
+		`PREFIX ex: <http://example.org/> ex:S { ex:p . {3,1} }`,
+		`ex:S { ex:p . }`,
+		`PREFIX ex: <http://example.org/> ex:S {} ex:S {}`,
+	} {
+		if _, err := ParseShExC(s, ""); err == nil {
+			t.Errorf("parse errors test fail: no error for %q", s)
+		}
+	}
+
+	if _, err := New(mustParse(t, `PREFIX ex: <http://example.org/> ex:S @ex:Missing`)); err == nil {
+		t.Errorf("parse errors test fail: no error for a missing shape")
+	}
+}
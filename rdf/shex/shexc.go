@@ -0,0 +1,1032 @@
+package shex
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/lexer"
+	"github.com/b1scuit/solid/rdf/lexer/lexertoken"
+	"github.com/b1scuit/solid/rdf/lexer/lexfn"
+)
+
+// ParseShExC reads a schema in the ShEx compact syntax. Semantic actions
+// and annotations are read but dropped
+//
+// https://shex.io/shex-semantics/#shexc
+func ParseShExC(input, base string) (*Schema, error) {
+	p, err := newShexcParser(input, base)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.parseSchema()
+}
+
+// shexcParser walks the lexemes of a ShExC document following its grammar
+type shexcParser struct {
+	lexemes []lexertoken.Token
+	pos     int
+
+	base     string
+	prefixes rdf.PrefixMap
+}
+
+func newShexcParser(input, base string) (*shexcParser, error) {
+	lex, err := lexer.New(lexer.WihInitalState(lexfn.LexShExC))
+	if err != nil {
+		return nil, err
+	}
+
+	lex.SetInput(input)
+
+	p := &shexcParser{base: base, prefixes: rdf.PrefixMap{}}
+
+	go lex.Run()
+
+	for t := range lex.NextToken() {
+		if t.Type == lexertoken.TOKEN_ERROR {
+			return nil, fmt.Errorf("shex: %v", t.Value)
+		}
+
+		if t.Type == lexertoken.TOKEN_EOF {
+			break
+		}
+
+		if t.Type != lexertoken.TOKEN_COMMENT {
+			p.lexemes = append(p.lexemes, t)
+		}
+	}
+
+	return p, nil
+}
+
+// shExDoc	::=	directive* ((notStartAction | startActions) statement*)?
+//
+// Directives can come between statements too, each applies to what
+// follows it
+func (p *shexcParser) parseSchema() (*Schema, error) {
+	s := &Schema{Prefixes: p.prefixes}
+	labels := make(map[string]bool)
+
+	for p.peek().Type != lexertoken.TOKEN_EOF {
+		switch t := p.peek(); {
+		case t.Type == lexertoken.TOKEN_BASE:
+			p.next()
+
+			iri, err := p.expect(lexertoken.TOKEN_IRIREF)
+			if err != nil {
+				return nil, err
+			}
+
+			p.base = string(p.resolve(iri.Value))
+		case t.Type == lexertoken.TOKEN_PREFIX:
+			p.next()
+
+			name, err := p.expect(lexertoken.TOKEN_PREFIX_NAME)
+			if err != nil {
+				return nil, err
+			}
+
+			iri, err := p.expect(lexertoken.TOKEN_IRIREF)
+			if err != nil {
+				return nil, err
+			}
+
+			if err := p.prefixes.Set(name.Value, p.resolve(iri.Value)); err != nil {
+				return nil, err
+			}
+		case p.acceptKeyword("IMPORT"):
+			iri, err := p.parseIri()
+			if err != nil {
+				return nil, err
+			}
+
+			s.Imports = append(s.Imports, iri)
+		case p.acceptKeyword("START"):
+			if !p.acceptOperator("=") {
+				return nil, p.unexpected(p.peek(), "=")
+			}
+
+			if s.Start != nil {
+				return nil, fmt.Errorf("shex: start declared twice")
+			}
+
+			expr, err := p.parseShapeExpression()
+			if err != nil {
+				return nil, err
+			}
+
+			s.Start = expr
+		case p.isOperator("%"):
+			if err := p.parseSemanticActions(); err != nil {
+				return nil, err
+			}
+		default:
+			d, err := p.parseShapeExprDecl()
+			if err != nil {
+				return nil, err
+			}
+
+			if labels[d.Label.String()] {
+				return nil, fmt.Errorf("shex: shape %v declared twice", d.Label)
+			}
+
+			labels[d.Label.String()] = true
+			s.Shapes = append(s.Shapes, d)
+		}
+	}
+
+	return s, nil
+}
+
+// shapeExprDecl	::=	shapeExprLabel (shapeExpression | "EXTERNAL")
+func (p *shexcParser) parseShapeExprDecl() (*ShapeDecl, error) {
+	label, err := p.parseLabel()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.acceptKeyword("EXTERNAL") {
+		return &ShapeDecl{Label: label, Expr: ShapeExternal{}}, nil
+	}
+
+	expr, err := p.parseShapeExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ShapeDecl{Label: label, Expr: expr}, nil
+}
+
+// shapeOr	::=	shapeAnd ("OR" shapeAnd)*
+func (p *shexcParser) parseShapeExpression() (ShapeExpr, error) {
+	first, err := p.parseShapeAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	exprs := []ShapeExpr{first}
+
+	for p.acceptKeyword("OR") {
+		expr, err := p.parseShapeAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		exprs = append(exprs, expr)
+	}
+
+	if len(exprs) == 1 {
+		return first, nil
+	}
+
+	return ShapeOr{Exprs: exprs}, nil
+}
+
+// shapeAnd	::=	shapeNot ("AND" shapeNot)*
+func (p *shexcParser) parseShapeAnd() (ShapeExpr, error) {
+	first, err := p.parseShapeNot()
+	if err != nil {
+		return nil, err
+	}
+
+	exprs := []ShapeExpr{first}
+
+	for p.acceptKeyword("AND") {
+		expr, err := p.parseShapeNot()
+		if err != nil {
+			return nil, err
+		}
+
+		exprs = append(exprs, expr)
+	}
+
+	if len(exprs) == 1 {
+		return first, nil
+	}
+
+	return ShapeAnd{Exprs: exprs}, nil
+}
+
+// shapeNot	::=	"NOT"? shapeAtom
+func (p *shexcParser) parseShapeNot() (ShapeExpr, error) {
+	if p.acceptKeyword("NOT") {
+		expr, err := p.parseShapeAtom()
+		if err != nil {
+			return nil, err
+		}
+
+		return ShapeNot{Expr: expr}, nil
+	}
+
+	return p.parseShapeAtom()
+}
+
+// shapeAtom	::=	nonLitNodeConstraint shapeOrRef?
+// | litNodeConstraint
+// | shapeOrRef nonLitNodeConstraint?
+// | '(' shapeExpression ')'
+// | '.'
+//
+// A node constraint next to a shape has to hold as well, so the pair is
+// read as a ShapeAnd
+func (p *shexcParser) parseShapeAtom() (ShapeExpr, error) {
+	switch t := p.peek(); {
+	case p.startsNonLitNodeConstraint():
+		nc, err := p.parseNonLitNodeConstraint()
+		if err != nil || !p.startsShapeOrRef() {
+			return nc, err
+		}
+
+		ref, err := p.parseShapeOrRef()
+		if err != nil {
+			return nil, err
+		}
+
+		return ShapeAnd{Exprs: []ShapeExpr{nc, ref}}, nil
+	case p.startsShapeOrRef():
+		ref, err := p.parseShapeOrRef()
+		if err != nil || !p.startsNonLitNodeConstraint() {
+			return ref, err
+		}
+
+		nc, err := p.parseNonLitNodeConstraint()
+		if err != nil {
+			return nil, err
+		}
+
+		return ShapeAnd{Exprs: []ShapeExpr{ref, nc}}, nil
+	case t.Type == lexertoken.TOKEN_START_COLLECTION:
+		p.next()
+
+		expr, err := p.parseShapeExpression()
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = p.expect(lexertoken.TOKEN_END_COLLECTION)
+
+		return expr, err
+	case t.Type == lexertoken.TOKEN_END_TRIPLE:
+		p.next()
+		return &NodeConstraint{}, nil
+	case p.isKeyword("LITERAL"), t.Type == lexertoken.TOKEN_IRIREF, t.Type == lexertoken.TOKEN_PREFIXED_NAME,
+		t.Type == lexertoken.TOKEN_START_BLANK_NODE_PROPERTY_LIST, p.isNumericFacet():
+		return p.parseLitNodeConstraint()
+	default:
+		return nil, p.unexpected(t, "shape expression")
+	}
+}
+
+func (p *shexcParser) startsShapeOrRef() bool {
+	t := p.peek()
+
+	return p.isOperator("@") || p.isKeyword("CLOSED") || p.isKeyword("EXTRA") ||
+		t.Type == lexertoken.TOKEN_START_GROUP && p.peekAt(1).Type != lexertoken.TOKEN_INTEGER
+}
+
+func (p *shexcParser) startsNonLitNodeConstraint() bool {
+	return p.isKeyword("IRI") || p.isKeyword("BNODE") || p.isKeyword("NONLITERAL") || p.isStringFacet()
+}
+
+func (p *shexcParser) isStringFacet() bool {
+	return p.isKeyword("LENGTH") || p.isKeyword("MINLENGTH") || p.isKeyword("MAXLENGTH") ||
+		p.peek().Type == lexertoken.TOKEN_REGEXP
+}
+
+func (p *shexcParser) isNumericFacet() bool {
+	for _, k := range []string{"MININCLUSIVE", "MINEXCLUSIVE", "MAXINCLUSIVE", "MAXEXCLUSIVE", "TOTALDIGITS", "FRACTIONDIGITS"} {
+		if p.isKeyword(k) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// shapeOrRef	::=	shapeDefinition | shapeRef
+// shapeRef	::=	ATPNAME_LN | ATPNAME_NS | '@' shapeExprLabel
+func (p *shexcParser) parseShapeOrRef() (ShapeExpr, error) {
+	if p.acceptOperator("@") {
+		label, err := p.parseLabel()
+		return ShapeRef{Label: label}, err
+	}
+
+	return p.parseShapeDefinition()
+}
+
+// shapeDefinition	::=	(extraPropertySet | "CLOSED")* '{' tripleExpression? '}' annotation* semanticActions
+func (p *shexcParser) parseShapeDefinition() (ShapeExpr, error) {
+	s := &Shape{}
+
+	for {
+		if p.acceptKeyword("CLOSED") {
+			s.Closed = true
+			continue
+		}
+
+		if !p.acceptKeyword("EXTRA") {
+			break
+		}
+
+		for p.startsPredicate() {
+			iri, err := p.parsePredicate()
+			if err != nil {
+				return nil, err
+			}
+
+			s.Extra = append(s.Extra, iri)
+		}
+	}
+
+	if _, err := p.expect(lexertoken.TOKEN_START_GROUP); err != nil {
+		return nil, err
+	}
+
+	if p.peek().Type != lexertoken.TOKEN_END_GROUP {
+		expr, err := p.parseTripleExpression()
+		if err != nil {
+			return nil, err
+		}
+
+		s.Expression = expr
+	}
+
+	if _, err := p.expect(lexertoken.TOKEN_END_GROUP); err != nil {
+		return nil, err
+	}
+
+	return s, p.parseAnnotationsAndActions()
+}
+
+// nonLitNodeConstraint	::=	nonLiteralKind stringFacet* | stringFacet+
+func (p *shexcParser) parseNonLitNodeConstraint() (ShapeExpr, error) {
+	nc := &NodeConstraint{}
+
+	for _, kind := range []string{"IRI", "BNODE", "NONLITERAL"} {
+		if p.acceptKeyword(kind) {
+			nc.NodeKind = strings.ToLower(kind)
+			break
+		}
+	}
+
+	for p.isStringFacet() {
+		if err := p.parseFacet(nc); err != nil {
+			return nil, err
+		}
+	}
+
+	return nc, nil
+}
+
+// litNodeConstraint	::=	"LITERAL" xsFacet* | datatype xsFacet* | valueSet xsFacet* | numericFacet+
+func (p *shexcParser) parseLitNodeConstraint() (ShapeExpr, error) {
+	nc := &NodeConstraint{}
+
+	switch t := p.peek(); {
+	case p.acceptKeyword("LITERAL"):
+		nc.NodeKind = NodeKindLiteral
+	case t.Type == lexertoken.TOKEN_IRIREF, t.Type == lexertoken.TOKEN_PREFIXED_NAME:
+		datatype, err := p.parseIri()
+		if err != nil {
+			return nil, err
+		}
+
+		nc.Datatype = datatype
+	case t.Type == lexertoken.TOKEN_START_BLANK_NODE_PROPERTY_LIST:
+		values, err := p.parseValueSet()
+		if err != nil {
+			return nil, err
+		}
+
+		nc.Values = values
+	}
+
+	for p.isStringFacet() || p.isNumericFacet() {
+		if err := p.parseFacet(nc); err != nil {
+			return nil, err
+		}
+	}
+
+	return nc, nil
+}
+
+// stringFacet	::=	stringLength INTEGER | REGEXP
+// numericFacet	::=	numericRange numericLiteral | numericLength INTEGER
+func (p *shexcParser) parseFacet(nc *NodeConstraint) error {
+	if t := p.peek(); t.Type == lexertoken.TOKEN_REGEXP {
+		p.next()
+
+		i := strings.LastIndex(t.Value, lexertoken.REGEXP)
+		nc.Pattern, nc.Flags = t.Value[:i], t.Value[i+1:]
+
+		return nil
+	}
+
+	keyword := p.next().Value
+
+	integer := func() (*int, error) {
+		t, err := p.expect(lexertoken.TOKEN_INTEGER)
+		if err != nil {
+			return nil, err
+		}
+
+		n, err := strconv.Atoi(t.Value)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("shex: %v needs a non negative integer", keyword)
+		}
+
+		return &n, nil
+	}
+
+	number := func() (*rdf.Literal, error) {
+		switch t := p.peek(); t.Type {
+		case lexertoken.TOKEN_INTEGER, lexertoken.TOKEN_DECIMAL, lexertoken.TOKEN_DOUBLE:
+			l, err := p.parseLiteral()
+			if err != nil {
+				return nil, err
+			}
+
+			lit := l.(rdf.Literal)
+
+			return &lit, nil
+		default:
+			return nil, p.unexpected(t, "number")
+		}
+	}
+
+	var err error
+
+	switch keyword {
+	case "LENGTH":
+		nc.Length, err = integer()
+	case "MINLENGTH":
+		nc.MinLength, err = integer()
+	case "MAXLENGTH":
+		nc.MaxLength, err = integer()
+	case "TOTALDIGITS":
+		nc.TotalDigits, err = integer()
+	case "FRACTIONDIGITS":
+		nc.FractionDigits, err = integer()
+	case "MININCLUSIVE":
+		nc.MinInclusive, err = number()
+	case "MINEXCLUSIVE":
+		nc.MinExclusive, err = number()
+	case "MAXINCLUSIVE":
+		nc.MaxInclusive, err = number()
+	case "MAXEXCLUSIVE":
+		nc.MaxExclusive, err = number()
+	}
+
+	return err
+}
+
+// valueSet	::=	'[' valueSetValue* ']'
+func (p *shexcParser) parseValueSet() ([]ValueSetValue, error) {
+	p.next()
+
+	var values []ValueSetValue
+
+	for p.peek().Type != lexertoken.TOKEN_END_BLANK_NODE_PROPERTY_LIST {
+		v, err := p.parseValueSetValue()
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, v)
+	}
+
+	p.next()
+
+	return values, nil
+}
+
+// valueSetValue	::=	iriRange | literalRange | languageRange | '.' exclusion+
+//
+// A wildcard takes its kind from its first exclusion
+func (p *shexcParser) parseValueSetValue() (ValueSetValue, error) {
+	switch t := p.peek(); t.Type {
+	case lexertoken.TOKEN_END_TRIPLE:
+		p.next()
+
+		if !p.isOperator("-") {
+			return nil, p.unexpected(p.peek(), "exclusion")
+		}
+
+		kind := StemLiteral
+		switch next := p.peekAt(1); {
+		case next.Type == lexertoken.TOKEN_IRIREF, next.Type == lexertoken.TOKEN_PREFIXED_NAME:
+			kind = StemIRI
+		case next.Type == lexertoken.TOKEN_LANGTAG, next.Type == lexertoken.TOKEN_OPERATOR && next.Value == "@":
+			kind = StemLanguage
+		}
+
+		exclusions, err := p.parseExclusions(kind)
+
+		return Stem{Kind: kind, Wildcard: true, Exclusions: exclusions}, err
+	case lexertoken.TOKEN_IRIREF, lexertoken.TOKEN_PREFIXED_NAME:
+		iri, err := p.parseIri()
+		if err != nil {
+			return nil, err
+		}
+
+		if !p.acceptOperator("~") {
+			return ObjectValue{Term: iri}, nil
+		}
+
+		exclusions, err := p.parseExclusions(StemIRI)
+
+		return Stem{Kind: StemIRI, Stem: string(iri), Exclusions: exclusions}, err
+	case lexertoken.TOKEN_LANGTAG:
+		p.next()
+
+		if !p.acceptOperator("~") {
+			return Language{Tag: t.Value}, nil
+		}
+
+		exclusions, err := p.parseExclusions(StemLanguage)
+
+		return Stem{Kind: StemLanguage, Stem: t.Value, Exclusions: exclusions}, err
+	case lexertoken.TOKEN_OPERATOR:
+		if !p.acceptOperator("@") || !p.acceptOperator("~") {
+			return nil, p.unexpected(t, "value")
+		}
+
+		exclusions, err := p.parseExclusions(StemLanguage)
+
+		return Stem{Kind: StemLanguage, Exclusions: exclusions}, err
+	}
+
+	l, err := p.parseLiteral()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.acceptOperator("~") {
+		return ObjectValue{Term: l}, nil
+	}
+
+	exclusions, err := p.parseExclusions(StemLiteral)
+
+	return Stem{Kind: StemLiteral, Stem: l.(rdf.Literal).Lexical, Exclusions: exclusions}, err
+}
+
+// iriExclusion	::=	'-' iri '~'?
+// literalExclusion	::=	'-' literal '~'?
+// languageExclusion	::=	'-' LANGTAG '~'?
+func (p *shexcParser) parseExclusions(kind string) ([]ValueSetValue, error) {
+	var exclusions []ValueSetValue
+
+	for p.acceptOperator("-") {
+		var value string
+		var term rdf.Term
+
+		switch kind {
+		case StemIRI:
+			iri, err := p.parseIri()
+			if err != nil {
+				return nil, err
+			}
+
+			value, term = string(iri), iri
+		case StemLanguage:
+			t, err := p.expect(lexertoken.TOKEN_LANGTAG)
+			if err != nil {
+				return nil, err
+			}
+
+			value = t.Value
+		default:
+			l, err := p.parseLiteral()
+			if err != nil {
+				return nil, err
+			}
+
+			value, term = l.(rdf.Literal).Lexical, rdf.NewLiteral(l.(rdf.Literal).Lexical)
+		}
+
+		switch {
+		case p.acceptOperator("~"):
+			exclusions = append(exclusions, Stem{Kind: kind, Stem: value})
+		case kind == StemLanguage:
+			exclusions = append(exclusions, Language{Tag: value})
+		default:
+			exclusions = append(exclusions, ObjectValue{Term: term})
+		}
+	}
+
+	return exclusions, nil
+}
+
+// oneOfTripleExpr	::=	groupTripleExpr ('|' groupTripleExpr)*
+func (p *shexcParser) parseTripleExpression() (TripleExpr, error) {
+	first, err := p.parseGroupTripleExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	exprs := []TripleExpr{first}
+
+	for p.acceptOperator("|") {
+		expr, err := p.parseGroupTripleExpr()
+		if err != nil {
+			return nil, err
+		}
+
+		exprs = append(exprs, expr)
+	}
+
+	if len(exprs) == 1 {
+		return first, nil
+	}
+
+	return &OneOf{Exprs: exprs, Min: 1, Max: 1}, nil
+}
+
+// groupTripleExpr	::=	unaryTripleExpr (';' unaryTripleExpr)* ';'?
+func (p *shexcParser) parseGroupTripleExpr() (TripleExpr, error) {
+	first, err := p.parseUnaryTripleExpr()
+	if err != nil {
+		return nil, err
+	}
+
+	exprs := []TripleExpr{first}
+
+	for p.peek().Type == lexertoken.TOKEN_OBJECT_LIST {
+		p.next()
+
+		if !p.startsUnaryTripleExpr() {
+			break
+		}
+
+		expr, err := p.parseUnaryTripleExpr()
+		if err != nil {
+			return nil, err
+		}
+
+		exprs = append(exprs, expr)
+	}
+
+	if len(exprs) == 1 {
+		return first, nil
+	}
+
+	return &EachOf{Exprs: exprs, Min: 1, Max: 1}, nil
+}
+
+func (p *shexcParser) startsUnaryTripleExpr() bool {
+	return p.isOperator("$") || p.isOperator("&") || p.isOperator("^") ||
+		p.peek().Type == lexertoken.TOKEN_START_COLLECTION || p.startsPredicate()
+}
+
+// unaryTripleExpr	::=	('$' tripleExprLabel)? (tripleConstraint | bracketedTripleExpr) | include
+// bracketedTripleExpr	::=	'(' tripleExpression ')' cardinality? annotation* semanticActions
+// tripleConstraint	::=	senseFlags? predicate inlineShapeExpression cardinality? annotation* semanticActions
+func (p *shexcParser) parseUnaryTripleExpr() (TripleExpr, error) {
+	if p.acceptOperator("&") {
+		label, err := p.parseLabel()
+		return TripleExprRef{Label: label}, err
+	}
+
+	var label rdf.Term
+
+	if p.acceptOperator("$") {
+		var err error
+		if label, err = p.parseLabel(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.peek().Type == lexertoken.TOKEN_START_COLLECTION {
+		p.next()
+
+		expr, err := p.parseTripleExpression()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := p.expect(lexertoken.TOKEN_END_COLLECTION); err != nil {
+			return nil, err
+		}
+
+		min, max, err := p.parseCardinality()
+		if err != nil {
+			return nil, err
+		}
+
+		// The cardinality goes on the group, a bracketed triple constraint
+		// or include is wrapped so its own cardinality isn't lost
+		if min != 1 || max != 1 {
+			switch e := expr.(type) {
+			case *EachOf:
+				if e.Min == 1 && e.Max == 1 {
+					e.Min, e.Max = min, max
+					break
+				}
+
+				expr = &EachOf{Exprs: []TripleExpr{expr}, Min: min, Max: max}
+			case *OneOf:
+				if e.Min == 1 && e.Max == 1 {
+					e.Min, e.Max = min, max
+					break
+				}
+
+				expr = &EachOf{Exprs: []TripleExpr{expr}, Min: min, Max: max}
+			default:
+				expr = &EachOf{Exprs: []TripleExpr{expr}, Min: min, Max: max}
+			}
+		}
+
+		if _, ok := expr.(TripleExprRef); ok && label != nil {
+			expr = &EachOf{Exprs: []TripleExpr{expr}, Min: 1, Max: 1}
+		}
+
+		setLabel(expr, label)
+
+		return expr, p.parseAnnotationsAndActions()
+	}
+
+	tc := &TripleConstraint{Label: label, Inverse: p.acceptOperator("^")}
+
+	predicate, err := p.parsePredicate()
+	if err != nil {
+		return nil, err
+	}
+
+	tc.Predicate = predicate
+
+	value, err := p.parseShapeExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	// A bare . allows anything
+	if nc, ok := value.(*NodeConstraint); !ok || !isEmpty(nc) {
+		tc.ValueExpr = value
+	}
+
+	if tc.Min, tc.Max, err = p.parseCardinality(); err != nil {
+		return nil, err
+	}
+
+	return tc, p.parseAnnotationsAndActions()
+}
+
+func setLabel(expr TripleExpr, label rdf.Term) {
+	if label == nil {
+		return
+	}
+
+	switch e := expr.(type) {
+	case *EachOf:
+		e.Label = label
+	case *OneOf:
+		e.Label = label
+	case *TripleConstraint:
+		e.Label = label
+	}
+}
+
+func isEmpty(nc *NodeConstraint) bool {
+	return nc.NodeKind == "" && nc.Datatype == "" && nc.Values == nil &&
+		nc.Length == nil && nc.MinLength == nil && nc.MaxLength == nil && nc.Pattern == "" &&
+		nc.MinInclusive == nil && nc.MinExclusive == nil && nc.MaxInclusive == nil && nc.MaxExclusive == nil &&
+		nc.TotalDigits == nil && nc.FractionDigits == nil
+}
+
+// cardinality	::=	'*' | '+' | '?' | REPEAT_RANGE
+// REPEAT_RANGE	::=	'{' INTEGER (',' (INTEGER | '*')?)? '}'
+//
+// No cardinality is exactly once
+func (p *shexcParser) parseCardinality() (int, int, error) {
+	switch {
+	case p.acceptOperator("*"):
+		return 0, Unbounded, nil
+	case p.acceptOperator("+"):
+		return 1, Unbounded, nil
+	case p.acceptOperator("?"):
+		return 0, 1, nil
+	case p.peek().Type != lexertoken.TOKEN_START_GROUP || p.peekAt(1).Type != lexertoken.TOKEN_INTEGER:
+		return 1, 1, nil
+	}
+
+	p.next()
+
+	min, _ := strconv.Atoi(p.next().Value)
+	max := min
+
+	if p.peek().Type == lexertoken.TOKEN_OBJECT {
+		p.next()
+
+		switch t := p.peek(); {
+		case t.Type == lexertoken.TOKEN_INTEGER:
+			p.next()
+			max, _ = strconv.Atoi(t.Value)
+		case p.acceptOperator("*"), t.Type == lexertoken.TOKEN_END_GROUP:
+			max = Unbounded
+		}
+	}
+
+	if _, err := p.expect(lexertoken.TOKEN_END_GROUP); err != nil {
+		return 0, 0, err
+	}
+
+	if max != Unbounded && max < min {
+		return 0, 0, fmt.Errorf("shex: cardinality {%v,%v} has its maximum below its minimum", min, max)
+	}
+
+	return min, max, nil
+}
+
+// annotation	::=	'//' predicate (iri | literal)
+// semanticActions	::=	codeDecl*
+func (p *shexcParser) parseAnnotationsAndActions() error {
+	for p.acceptOperator(lexertoken.ANNOTATION) {
+		if _, err := p.parsePredicate(); err != nil {
+			return err
+		}
+
+		var err error
+		if t := p.peek(); t.Type == lexertoken.TOKEN_IRIREF || t.Type == lexertoken.TOKEN_PREFIXED_NAME {
+			_, err = p.parseIri()
+		} else {
+			_, err = p.parseLiteral()
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return p.parseSemanticActions()
+}
+
+// codeDecl	::=	'%' iri (CODE | '%')
+func (p *shexcParser) parseSemanticActions() error {
+	for p.acceptOperator(lexertoken.START_CODE) {
+		if _, err := p.parseIri(); err != nil {
+			return err
+		}
+
+		if _, err := p.expect(lexertoken.TOKEN_CODE); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *shexcParser) startsPredicate() bool {
+	t := p.peek()
+	return t.Type == lexertoken.TOKEN_PREDICATE || t.Type == lexertoken.TOKEN_IRIREF || t.Type == lexertoken.TOKEN_PREFIXED_NAME
+}
+
+// predicate	::=	iri | 'a'
+func (p *shexcParser) parsePredicate() (rdf.IRI, error) {
+	if p.peek().Type == lexertoken.TOKEN_PREDICATE {
+		p.next()
+		return rdf.RDFType, nil
+	}
+
+	return p.parseIri()
+}
+
+// shapeExprLabel	::=	iri | blankNode
+func (p *shexcParser) parseLabel() (rdf.Term, error) {
+	if t := p.peek(); t.Type == lexertoken.TOKEN_BLANK_NODE {
+		p.next()
+		return rdf.BlankNode(t.Value), nil
+	}
+
+	return p.parseIri()
+}
+
+// literal	::=	rdfLiteral | numericLiteral | booleanLiteral
+//
+// Unlike SPARQL the sign of a number is lexed with it
+func (p *shexcParser) parseLiteral() (rdf.Term, error) {
+	t := p.next()
+
+	switch t.Type {
+	case lexertoken.TOKEN_INTEGER:
+		return rdf.NewTypedLiteral(t.Value, rdf.XSDInteger), nil
+	case lexertoken.TOKEN_DECIMAL:
+		return rdf.NewTypedLiteral(t.Value, rdf.XSDDecimal), nil
+	case lexertoken.TOKEN_DOUBLE:
+		return rdf.NewTypedLiteral(t.Value, rdf.XSDDouble), nil
+	case lexertoken.TOKEN_BOOLEAN:
+		return rdf.NewTypedLiteral(t.Value, rdf.XSDBoolean), nil
+	case lexertoken.TOKEN_LITERAL:
+	default:
+		return nil, p.unexpected(t, "literal")
+	}
+
+	switch p.peek().Type {
+	case lexertoken.TOKEN_LANGTAG:
+		return rdf.NewLangLiteral(t.Value, p.next().Value), nil
+	case lexertoken.TOKEN_DATATYPE:
+		p.next()
+
+		datatype, err := p.parseIri()
+		if err != nil {
+			return nil, err
+		}
+
+		return rdf.NewTypedLiteral(t.Value, datatype), nil
+	}
+
+	return rdf.NewLiteral(t.Value), nil
+}
+
+// iri	::=	IRIREF | PrefixedName
+func (p *shexcParser) parseIri() (rdf.IRI, error) {
+	t := p.next()
+
+	switch t.Type {
+	case lexertoken.TOKEN_IRIREF:
+		return p.resolve(t.Value), nil
+	case lexertoken.TOKEN_PREFIXED_NAME:
+		iri, err := p.prefixes.Expand(t.Value)
+		if err != nil {
+			return "", fmt.Errorf("shex: %w", err)
+		}
+
+		return iri, nil
+	}
+
+	return "", p.unexpected(t, "IRI")
+}
+
+func (p *shexcParser) resolve(ref string) rdf.IRI {
+	return rdf.ResolveIRI(p.base, ref)
+}
+
+func (p *shexcParser) peek() lexertoken.Token {
+	return p.peekAt(0)
+}
+
+func (p *shexcParser) peekAt(n int) lexertoken.Token {
+	if p.pos+n >= len(p.lexemes) {
+		return lexertoken.Token{Type: lexertoken.TOKEN_EOF}
+	}
+
+	return p.lexemes[p.pos+n]
+}
+
+func (p *shexcParser) next() lexertoken.Token {
+	t := p.peek()
+
+	if p.pos < len(p.lexemes) {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *shexcParser) isKeyword(keyword string) bool {
+	t := p.peek()
+	return t.Type == lexertoken.TOKEN_KEYWORD && t.Value == keyword
+}
+
+func (p *shexcParser) acceptKeyword(keyword string) bool {
+	if p.isKeyword(keyword) {
+		p.next()
+		return true
+	}
+
+	return false
+}
+
+func (p *shexcParser) isOperator(op string) bool {
+	t := p.peek()
+	return t.Type == lexertoken.TOKEN_OPERATOR && t.Value == op
+}
+
+func (p *shexcParser) acceptOperator(op string) bool {
+	if p.isOperator(op) {
+		p.next()
+		return true
+	}
+
+	return false
+}
+
+func (p *shexcParser) expect(tokenType lexertoken.TokenType) (lexertoken.Token, error) {
+	t := p.next()
+
+	if t.Type != tokenType {
+		return t, p.unexpected(t, lexertoken.TokenMap[tokenType])
+	}
+
+	return t, nil
+}
+
+func (p *shexcParser) unexpected(t lexertoken.Token, wanted string) error {
+	if t.Type == lexertoken.TOKEN_EOF {
+		return fmt.Errorf("shex: expected %v, found end of schema", wanted)
+	}
+
+	return fmt.Errorf("shex: expected %v, found %v %q", wanted, lexertoken.TokenMap[t.Type], strings.TrimSpace(t.Value))
+}
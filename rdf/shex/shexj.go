@@ -0,0 +1,375 @@
+package shex
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/b1scuit/solid/rdf"
+)
+
+// ReadShExJ reads a schema in the JSON syntax, both ShEx 2.0 schemas with
+// shape expressions in shapes and 2.1 ones with ShapeDecls
+//
+// https://shex.io/shex-semantics/#shexj
+func ReadShExJ(r io.Reader) (*Schema, error) {
+	d := json.NewDecoder(r)
+	d.UseNumber()
+
+	var doc map[string]any
+	if err := d.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("shex: %w", err)
+	}
+
+	if doc["type"] != "Schema" {
+		return nil, fmt.Errorf("shex: ShExJ document has type %v, not Schema", doc["type"])
+	}
+
+	s := &Schema{Prefixes: rdf.PrefixMap{}}
+
+	if start, ok := doc["start"]; ok {
+		e, err := readShapeExpr(start)
+		if err != nil {
+			return nil, err
+		}
+
+		s.Start = e
+	}
+
+	imports, _ := doc["imports"].([]any)
+	for _, i := range imports {
+		iri, ok := i.(string)
+		if !ok {
+			return nil, fmt.Errorf("shex: import %v is not an IRI", i)
+		}
+
+		s.Imports = append(s.Imports, rdf.IRI(iri))
+	}
+
+	shapes, _ := doc["shapes"].([]any)
+	for _, shape := range shapes {
+		obj, ok := shape.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("shex: shape %v is not an object", shape)
+		}
+
+		id, ok := obj["id"].(string)
+		if !ok {
+			return nil, fmt.Errorf("shex: shape has no id")
+		}
+
+		decl := &ShapeDecl{Label: label(id)}
+
+		var err error
+		if obj["type"] == "ShapeDecl" {
+			decl.Expr, err = readShapeExpr(obj["shapeExpr"])
+		} else {
+			decl.Expr, err = readShapeExpr(obj)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		s.Shapes = append(s.Shapes, decl)
+	}
+
+	return s, nil
+}
+
+// label reads a shape or triple expression label, blank nodes are written
+// with their _: prefix
+func label(id string) rdf.Term {
+	if strings.HasPrefix(id, "_:") {
+		return rdf.BlankNode(id[2:])
+	}
+
+	return rdf.IRI(id)
+}
+
+func readShapeExpr(v any) (ShapeExpr, error) {
+	if id, ok := v.(string); ok {
+		return ShapeRef{Label: label(id)}, nil
+	}
+
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("shex: %v is not a shape expression", v)
+	}
+
+	switch obj["type"] {
+	case "ShapeOr", "ShapeAnd":
+		list, _ := obj["shapeExprs"].([]any)
+
+		var exprs []ShapeExpr
+		for _, item := range list {
+			e, err := readShapeExpr(item)
+			if err != nil {
+				return nil, err
+			}
+
+			exprs = append(exprs, e)
+		}
+
+		if obj["type"] == "ShapeOr" {
+			return ShapeOr{Exprs: exprs}, nil
+		}
+
+		return ShapeAnd{Exprs: exprs}, nil
+	case "ShapeNot":
+		e, err := readShapeExpr(obj["shapeExpr"])
+		return ShapeNot{Expr: e}, err
+	case "ShapeExternal":
+		return ShapeExternal{}, nil
+	case "NodeConstraint":
+		return readNodeConstraint(obj)
+	case "Shape":
+		s := &Shape{}
+		s.Closed, _ = obj["closed"].(bool)
+
+		extra, _ := obj["extra"].([]any)
+		for _, e := range extra {
+			iri, _ := e.(string)
+			s.Extra = append(s.Extra, rdf.IRI(iri))
+		}
+
+		if expr, ok := obj["expression"]; ok {
+			e, err := readTripleExpr(expr)
+			if err != nil {
+				return nil, err
+			}
+
+			s.Expression = e
+		}
+
+		return s, nil
+	}
+
+	return nil, fmt.Errorf("shex: unknown shape expression type %v", obj["type"])
+}
+
+func readNodeConstraint(obj map[string]any) (*NodeConstraint, error) {
+	nc := &NodeConstraint{}
+
+	nc.NodeKind, _ = obj["nodeKind"].(string)
+
+	if datatype, ok := obj["datatype"].(string); ok {
+		nc.Datatype = rdf.IRI(datatype)
+	}
+
+	if values, ok := obj["values"].([]any); ok {
+		nc.Values = []ValueSetValue{}
+
+		for _, v := range values {
+			value, err := readValue(v)
+			if err != nil {
+				return nil, err
+			}
+
+			nc.Values = append(nc.Values, value)
+		}
+	}
+
+	nc.Pattern, _ = obj["pattern"].(string)
+	nc.Flags, _ = obj["flags"].(string)
+
+	for name, facet := range map[string]**int{
+		"length":         &nc.Length,
+		"minlength":      &nc.MinLength,
+		"maxlength":      &nc.MaxLength,
+		"totaldigits":    &nc.TotalDigits,
+		"fractiondigits": &nc.FractionDigits,
+	} {
+		if n, ok := obj[name].(json.Number); ok {
+			i, err := strconv.Atoi(n.String())
+			if err != nil || i < 0 {
+				return nil, fmt.Errorf("shex: %v needs a non negative integer", name)
+			}
+
+			*facet = &i
+		}
+	}
+
+	for name, facet := range map[string]**rdf.Literal{
+		"mininclusive": &nc.MinInclusive,
+		"minexclusive": &nc.MinExclusive,
+		"maxinclusive": &nc.MaxInclusive,
+		"maxexclusive": &nc.MaxExclusive,
+	} {
+		if n, ok := obj[name].(json.Number); ok {
+			l := numberLiteral(n)
+			*facet = &l
+		}
+	}
+
+	return nc, nil
+}
+
+// numberLiteral types a JSON number the way ShExC would type it written
+// the same way
+func numberLiteral(n json.Number) rdf.Literal {
+	s := n.String()
+
+	switch {
+	case strings.ContainsAny(s, "eE"):
+		return rdf.NewTypedLiteral(s, rdf.XSDDouble)
+	case strings.Contains(s, "."):
+		return rdf.NewTypedLiteral(s, rdf.XSDDecimal)
+	}
+
+	return rdf.NewTypedLiteral(s, rdf.XSDInteger)
+}
+
+func readTripleExpr(v any) (TripleExpr, error) {
+	if id, ok := v.(string); ok {
+		return TripleExprRef{Label: label(id)}, nil
+	}
+
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("shex: %v is not a triple expression", v)
+	}
+
+	var id rdf.Term
+	if s, ok := obj["id"].(string); ok {
+		id = label(s)
+	}
+
+	min, max, err := readCardinality(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	switch obj["type"] {
+	case "EachOf", "OneOf":
+		list, _ := obj["expressions"].([]any)
+
+		var exprs []TripleExpr
+		for _, item := range list {
+			e, err := readTripleExpr(item)
+			if err != nil {
+				return nil, err
+			}
+
+			exprs = append(exprs, e)
+		}
+
+		if obj["type"] == "EachOf" {
+			return &EachOf{Label: id, Exprs: exprs, Min: min, Max: max}, nil
+		}
+
+		return &OneOf{Label: id, Exprs: exprs, Min: min, Max: max}, nil
+	case "TripleConstraint":
+		predicate, ok := obj["predicate"].(string)
+		if !ok {
+			return nil, fmt.Errorf("shex: triple constraint has no predicate")
+		}
+
+		tc := &TripleConstraint{Label: id, Predicate: rdf.IRI(predicate), Min: min, Max: max}
+		tc.Inverse, _ = obj["inverse"].(bool)
+
+		if value, ok := obj["valueExpr"]; ok {
+			if tc.ValueExpr, err = readShapeExpr(value); err != nil {
+				return nil, err
+			}
+		}
+
+		return tc, nil
+	}
+
+	return nil, fmt.Errorf("shex: unknown triple expression type %v", obj["type"])
+}
+
+// readCardinality reads min and max, which are both 1 when missing and -1
+// for unbounded
+func readCardinality(obj map[string]any) (int, int, error) {
+	min, max := 1, 1
+
+	for name, n := range map[string]*int{"min": &min, "max": &max} {
+		if v, ok := obj[name].(json.Number); ok {
+			i, err := strconv.Atoi(v.String())
+			if err != nil {
+				return 0, 0, fmt.Errorf("shex: %v is not an integer", name)
+			}
+
+			*n = i
+		}
+	}
+
+	return min, max, nil
+}
+
+// ObjectLiteral	{ value: STRING, language: STRING?, type: STRING? }
+func readValue(v any) (ValueSetValue, error) {
+	if iri, ok := v.(string); ok {
+		return ObjectValue{Term: rdf.IRI(iri)}, nil
+	}
+
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("shex: %v is not a value", v)
+	}
+
+	if value, ok := obj["value"].(string); ok {
+		language, _ := obj["language"].(string)
+		datatype, _ := obj["type"].(string)
+
+		switch {
+		case language != "":
+			return ObjectValue{Term: rdf.NewLangLiteral(value, language)}, nil
+		case datatype != "" && rdf.IRI(datatype) != rdf.XSDString:
+			return ObjectValue{Term: rdf.NewTypedLiteral(value, rdf.IRI(datatype))}, nil
+		}
+
+		return ObjectValue{Term: rdf.NewLiteral(value)}, nil
+	}
+
+	kinds := map[string]string{
+		"IriStem": StemIRI, "IriStemRange": StemIRI,
+		"LiteralStem": StemLiteral, "LiteralStemRange": StemLiteral,
+		"LanguageStem": StemLanguage, "LanguageStemRange": StemLanguage,
+	}
+
+	t, _ := obj["type"].(string)
+
+	if t == "Language" {
+		tag, _ := obj["languageTag"].(string)
+		return Language{Tag: tag}, nil
+	}
+
+	kind, ok := kinds[t]
+	if !ok {
+		return nil, fmt.Errorf("shex: unknown value type %v", obj["type"])
+	}
+
+	stem := Stem{Kind: kind}
+
+	switch s := obj["stem"].(type) {
+	case string:
+		stem.Stem = s
+	case map[string]any:
+		stem.Wildcard = s["type"] == "Wildcard"
+	}
+
+	exclusions, _ := obj["exclusions"].([]any)
+	for _, e := range exclusions {
+		switch x := e.(type) {
+		case string:
+			switch kind {
+			case StemIRI:
+				stem.Exclusions = append(stem.Exclusions, ObjectValue{Term: rdf.IRI(x)})
+			case StemLiteral:
+				stem.Exclusions = append(stem.Exclusions, ObjectValue{Term: rdf.NewLiteral(x)})
+			default:
+				stem.Exclusions = append(stem.Exclusions, Language{Tag: x})
+			}
+		case map[string]any:
+			s, _ := x["stem"].(string)
+			stem.Exclusions = append(stem.Exclusions, Stem{Kind: kind, Stem: s})
+		}
+	}
+
+	return stem, nil
+}
@@ -0,0 +1,893 @@
+package shex
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/b1scuit/solid/rdf"
+)
+
+// ErrNoStart is the reason given for START in a shape map when the schema
+// doesn't declare a start shape
+var ErrNoStart = errors.New("shex: schema has no start shape")
+
+// A Validator checks nodes of data graphs against the shapes of a schema
+type Validator struct {
+	schema      *Schema
+	shapes      map[string]ShapeExpr
+	tripleExprs map[string]TripleExpr
+	patterns    map[*NodeConstraint]*regexp.Regexp
+}
+
+// New checks every shape and triple expression the schema refers to is
+// declared and compiles its patterns
+func New(schema *Schema) (*Validator, error) {
+	v := &Validator{
+		schema:      schema,
+		shapes:      make(map[string]ShapeExpr),
+		tripleExprs: make(map[string]TripleExpr),
+		patterns:    make(map[*NodeConstraint]*regexp.Regexp),
+	}
+
+	for _, d := range schema.Shapes {
+		v.shapes[d.Label.String()] = d.Expr
+	}
+
+	var refs []rdf.Term
+	var tripleRefs []rdf.Term
+
+	var walkTriples func(e TripleExpr) error
+	var walk func(e ShapeExpr) error
+
+	walk = func(e ShapeExpr) error {
+		switch x := e.(type) {
+		case ShapeOr:
+			for _, e := range x.Exprs {
+				if err := walk(e); err != nil {
+					return err
+				}
+			}
+		case ShapeAnd:
+			for _, e := range x.Exprs {
+				if err := walk(e); err != nil {
+					return err
+				}
+			}
+		case ShapeNot:
+			return walk(x.Expr)
+		case ShapeRef:
+			refs = append(refs, x.Label)
+		case *NodeConstraint:
+			return v.compile(x)
+		case *Shape:
+			if x.Expression != nil {
+				return walkTriples(x.Expression)
+			}
+		}
+
+		return nil
+	}
+
+	walkTriples = func(e TripleExpr) error {
+		switch x := e.(type) {
+		case *EachOf:
+			v.label(x.Label, x)
+			for _, e := range x.Exprs {
+				if err := walkTriples(e); err != nil {
+					return err
+				}
+			}
+		case *OneOf:
+			v.label(x.Label, x)
+			for _, e := range x.Exprs {
+				if err := walkTriples(e); err != nil {
+					return err
+				}
+			}
+		case *TripleConstraint:
+			v.label(x.Label, x)
+			if x.ValueExpr != nil {
+				return walk(x.ValueExpr)
+			}
+		case TripleExprRef:
+			tripleRefs = append(tripleRefs, x.Label)
+		}
+
+		return nil
+	}
+
+	if schema.Start != nil {
+		if err := walk(schema.Start); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, d := range schema.Shapes {
+		if err := walk(d.Expr); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, ref := range refs {
+		if _, ok := v.shapes[ref.String()]; !ok {
+			return nil, fmt.Errorf("shex: reference to undeclared shape %v", ref)
+		}
+	}
+
+	for _, ref := range tripleRefs {
+		if _, ok := v.tripleExprs[ref.String()]; !ok {
+			return nil, fmt.Errorf("shex: reference to undeclared triple expression %v", ref)
+		}
+	}
+
+	return v, nil
+}
+
+func MustNew(schema *Schema) *Validator {
+	v, err := New(schema)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return v
+}
+
+func (v *Validator) label(label rdf.Term, e TripleExpr) {
+	if label != nil {
+		v.tripleExprs[label.String()] = e
+	}
+}
+
+// compile turns a pattern and its flags into a Go regular expression,
+// which has no x flag
+func (v *Validator) compile(nc *NodeConstraint) error {
+	if nc.Pattern == "" {
+		return nil
+	}
+
+	flags := ""
+	if nc.Flags != "" {
+		if strings.ContainsRune(nc.Flags, 'x') {
+			return fmt.Errorf("shex: unsupported pattern flag x")
+		}
+
+		flags = "(?" + nc.Flags + ")"
+	}
+
+	re, err := regexp.Compile(flags + nc.Pattern)
+	if err != nil {
+		return fmt.Errorf("shex: %w", err)
+	}
+
+	v.patterns[nc] = re
+
+	return nil
+}
+
+// Validate checks each node the shape map selects against its shape
+func (v *Validator) Validate(g *rdf.Graph, m ShapeMap) []Result {
+	x := &validation{v: v, g: g, active: make(map[string]bool)}
+
+	var results []Result
+
+	for _, a := range m {
+		for _, node := range a.nodes(g) {
+			r := Result{Node: node, Shape: a.Shape, Conformant: true}
+
+			if err := x.check(node, a.Shape); err != nil {
+				r.Conformant = false
+				r.Reason = err.Error()
+			}
+
+			results = append(results, r)
+		}
+	}
+
+	return results
+}
+
+// Conforms checks a single node against the shape with the label, or the
+// start shape when the label is nil
+func (v *Validator) Conforms(g *rdf.Graph, node, label rdf.Term) bool {
+	x := &validation{v: v, g: g, active: make(map[string]bool)}
+	return x.check(node, label) == nil
+}
+
+// validation is one run over a data graph. Errors are the reasons a node
+// doesn't conform
+type validation struct {
+	v *Validator
+	g *rdf.Graph
+
+	// Node and shape pairs being checked, a shape that refers back to
+	// itself for the same node is assumed to hold
+	active map[string]bool
+}
+
+func (x *validation) check(node, label rdf.Term) error {
+	if label == nil {
+		if x.v.schema.Start == nil {
+			return ErrNoStart
+		}
+
+		return x.satisfies(node, x.v.schema.Start)
+	}
+
+	if _, ok := x.v.shapes[label.String()]; !ok {
+		return fmt.Errorf("shex: no shape %v", label)
+	}
+
+	return x.satisfiesLabel(node, label)
+}
+
+func (x *validation) satisfiesLabel(node, label rdf.Term) error {
+	key := node.String() + " " + label.String()
+	if x.active[key] {
+		return nil
+	}
+
+	x.active[key] = true
+	defer delete(x.active, key)
+
+	if err := x.satisfies(node, x.v.shapes[label.String()]); err != nil {
+		return fmt.Errorf("%v does not conform to %v: %w", node, label, err)
+	}
+
+	return nil
+}
+
+// satisfies checks a node against a shape expression
+//
+// https://shex.io/shex-semantics/#satisfies
+func (x *validation) satisfies(node rdf.Term, e ShapeExpr) error {
+	switch e := e.(type) {
+	case ShapeOr:
+		var reasons []string
+
+		for _, e := range e.Exprs {
+			err := x.satisfies(node, e)
+			if err == nil {
+				return nil
+			}
+
+			reasons = append(reasons, err.Error())
+		}
+
+		return fmt.Errorf("%v matches none of the alternatives (%v)", node, strings.Join(reasons, "; "))
+	case ShapeAnd:
+		for _, e := range e.Exprs {
+			if err := x.satisfies(node, e); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case ShapeNot:
+		if x.satisfies(node, e.Expr) == nil {
+			return fmt.Errorf("%v matches a shape it must not", node)
+		}
+
+		return nil
+	case ShapeRef:
+		return x.satisfiesLabel(node, e.Label)
+	case ShapeExternal:
+		return fmt.Errorf("external shapes can't be checked")
+	case *NodeConstraint:
+		return x.checkNodeConstraint(node, e)
+	case *Shape:
+		return x.checkShape(node, e)
+	}
+
+	return fmt.Errorf("unknown shape expression %T", e)
+}
+
+// https://shex.io/shex-semantics/#node-constraints
+func (x *validation) checkNodeConstraint(node rdf.Term, nc *NodeConstraint) error {
+	switch nc.NodeKind {
+	case NodeKindIRI:
+		if node.Kind() != rdf.TermIRI {
+			return fmt.Errorf("%v is not an IRI", node)
+		}
+	case NodeKindBNode:
+		if node.Kind() != rdf.TermBlankNode {
+			return fmt.Errorf("%v is not a blank node", node)
+		}
+	case NodeKindNonLiteral:
+		if node.Kind() == rdf.TermLiteral {
+			return fmt.Errorf("%v is a literal", node)
+		}
+	case NodeKindLiteral:
+		if node.Kind() != rdf.TermLiteral {
+			return fmt.Errorf("%v is not a literal", node)
+		}
+	}
+
+	if nc.Datatype != "" {
+		l, ok := node.(rdf.Literal)
+		if !ok || literalDatatype(l) != nc.Datatype {
+			return fmt.Errorf("%v is not a %v", node, nc.Datatype)
+		}
+
+		if err := l.Validate(); err != nil {
+			return fmt.Errorf("%v is not a valid %v", node, nc.Datatype)
+		}
+	}
+
+	if nc.Values != nil {
+		matched := false
+		for _, value := range nc.Values {
+			if value.matches(node) {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return fmt.Errorf("%v is not in the value set", node)
+		}
+	}
+
+	if err := x.checkStringFacets(node, nc); err != nil {
+		return err
+	}
+
+	return checkNumericFacets(node, nc)
+}
+
+func literalDatatype(l rdf.Literal) rdf.IRI {
+	switch {
+	case l.Language != "":
+		return rdf.RDFLangString
+	case l.Datatype == "":
+		return rdf.XSDString
+	}
+
+	return l.Datatype
+}
+
+// String facets apply to the lexical form of literals and to IRIs, blank
+// nodes don't have a string form
+func (x *validation) checkStringFacets(node rdf.Term, nc *NodeConstraint) error {
+	if nc.Length == nil && nc.MinLength == nil && nc.MaxLength == nil && nc.Pattern == "" {
+		return nil
+	}
+
+	var s string
+
+	switch t := node.(type) {
+	case rdf.IRI:
+		s = string(t)
+	case rdf.Literal:
+		s = t.Lexical
+	default:
+		return fmt.Errorf("%v has no string form for its facets", node)
+	}
+
+	n := utf8.RuneCountInString(s)
+
+	switch {
+	case nc.Length != nil && n != *nc.Length:
+		return fmt.Errorf("%v does not have length %v", node, *nc.Length)
+	case nc.MinLength != nil && n < *nc.MinLength:
+		return fmt.Errorf("%v is shorter than %v", node, *nc.MinLength)
+	case nc.MaxLength != nil && n > *nc.MaxLength:
+		return fmt.Errorf("%v is longer than %v", node, *nc.MaxLength)
+	}
+
+	if re, ok := x.v.patterns[nc]; ok && !re.MatchString(s) {
+		return fmt.Errorf("%v does not match /%v/", node, nc.Pattern)
+	}
+
+	return nil
+}
+
+func checkNumericFacets(node rdf.Term, nc *NodeConstraint) error {
+	if nc.MinInclusive == nil && nc.MinExclusive == nil && nc.MaxInclusive == nil && nc.MaxExclusive == nil &&
+		nc.TotalDigits == nil && nc.FractionDigits == nil {
+		return nil
+	}
+
+	l, ok := node.(rdf.Literal)
+	if !ok || !rdf.IsNumeric(l.Datatype) || l.Validate() != nil {
+		return fmt.Errorf("%v is not a number", node)
+	}
+
+	for _, limit := range []struct {
+		value *rdf.Literal
+		ok    func(c int) bool
+		name  string
+	}{
+		{nc.MinInclusive, func(c int) bool { return c >= 0 }, "at least"},
+		{nc.MinExclusive, func(c int) bool { return c > 0 }, "more than"},
+		{nc.MaxInclusive, func(c int) bool { return c <= 0 }, "at most"},
+		{nc.MaxExclusive, func(c int) bool { return c < 0 }, "less than"},
+	} {
+		if limit.value == nil {
+			continue
+		}
+
+		if c, err := rdf.Compare(l, *limit.value); err != nil || !limit.ok(c) {
+			return fmt.Errorf("%v is not %v %v", node, limit.name, limit.value.Lexical)
+		}
+	}
+
+	if nc.TotalDigits == nil && nc.FractionDigits == nil {
+		return nil
+	}
+
+	total, fraction, ok := digits(l)
+
+	switch {
+	case !ok:
+		return fmt.Errorf("%v is not a decimal", node)
+	case nc.TotalDigits != nil && total > *nc.TotalDigits:
+		return fmt.Errorf("%v has more than %v digits", node, *nc.TotalDigits)
+	case nc.FractionDigits != nil && fraction > *nc.FractionDigits:
+		return fmt.Errorf("%v has more than %v fraction digits", node, *nc.FractionDigits)
+	}
+
+	return nil
+}
+
+// digits counts the significant digits of a decimal or integer, the
+// floating point types don't have totalDigits or fractionDigits
+func digits(l rdf.Literal) (int, int, bool) {
+	if l.Datatype == rdf.XSDDouble || l.Datatype == rdf.XSDFloat {
+		return 0, 0, false
+	}
+
+	c, err := l.Canonical()
+	if err != nil {
+		return 0, 0, false
+	}
+
+	whole, fraction, _ := strings.Cut(strings.TrimLeft(c.Lexical, "+-"), ".")
+	whole = strings.TrimLeft(whole, "0")
+	fraction = strings.TrimRight(fraction, "0")
+
+	total := len(whole) + len(fraction)
+	if total == 0 {
+		total = 1
+	}
+
+	return total, len(fraction), true
+}
+
+func (v ObjectValue) matches(t rdf.Term) bool {
+	return rdf.Equal(v.Term, t)
+}
+
+func (v Language) matches(t rdf.Term) bool {
+	l, ok := t.(rdf.Literal)
+	return ok && l.Language != "" && strings.EqualFold(l.Language, v.Tag)
+}
+
+func (v Stem) matches(t rdf.Term) bool {
+	if !v.stemMatches(t) {
+		return false
+	}
+
+	for _, e := range v.Exclusions {
+		if excludes(v.Kind, e, t) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (v Stem) stemMatches(t rdf.Term) bool {
+	switch v.Kind {
+	case StemIRI:
+		iri, ok := t.(rdf.IRI)
+		return ok && (v.Wildcard || strings.HasPrefix(string(iri), v.Stem))
+	case StemLiteral:
+		l, ok := t.(rdf.Literal)
+		return ok && (v.Wildcard || strings.HasPrefix(l.Lexical, v.Stem))
+	case StemLanguage:
+		l, ok := t.(rdf.Literal)
+		if !ok || l.Language == "" {
+			return false
+		}
+
+		tag, stem := strings.ToLower(l.Language), strings.ToLower(v.Stem)
+
+		return v.Wildcard || stem == "" || tag == stem || strings.HasPrefix(tag, stem+"-")
+	}
+
+	return false
+}
+
+// excludes checks an exclusion of a stem, literal exclusions only compare
+// the lexical form
+func excludes(kind string, e ValueSetValue, t rdf.Term) bool {
+	if o, ok := e.(ObjectValue); ok && kind == StemLiteral {
+		l, isLiteral := t.(rdf.Literal)
+		ol, _ := o.Term.(rdf.Literal)
+
+		return isLiteral && l.Lexical == ol.Lexical
+	}
+
+	return e.matches(t)
+}
+
+// An arc is a triple in the neighbourhood of the focus node, with the
+// triple constraints its value satisfies
+type arc struct {
+	predicate rdf.IRI
+	value     rdf.Term
+	inverse   bool
+
+	candidates []*TripleConstraint
+
+	// An arc whose predicate is EXTRA can be left unassigned
+	extra bool
+}
+
+// checkShape looks for a way to assign the triples around the node to the
+// shape's triple constraints that matches its triple expression
+//
+// https://shex.io/shex-semantics/#shapes-and-TEs
+func (x *validation) checkShape(node rdf.Term, s *Shape) error {
+	tcs := x.v.constraints(s.Expression)
+
+	mentioned := make(map[string]bool)
+	for _, tc := range tcs {
+		mentioned[arcKey(tc.Predicate, tc.Inverse)] = true
+	}
+
+	extra := make(map[string]bool)
+	for _, p := range s.Extra {
+		extra[p.String()] = true
+	}
+
+	var arcs []*arc
+
+	neighbourhood := func(t rdf.Triple, inverse bool) error {
+		p, _ := t.Predicate.(rdf.IRI)
+
+		value := t.Object
+		if inverse {
+			value = t.Subject
+		}
+
+		if !mentioned[arcKey(p, inverse)] {
+			if s.Closed && !inverse && !extra[p.String()] {
+				return fmt.Errorf("closed shape doesn't allow %v on %v", p, node)
+			}
+
+			return nil
+		}
+
+		a := &arc{predicate: p, value: value, inverse: inverse, extra: extra[p.String()]}
+
+		var reasons []string
+
+		for _, tc := range tcs {
+			if tc.Predicate != p || tc.Inverse != inverse {
+				continue
+			}
+
+			if tc.ValueExpr == nil {
+				a.candidates = append(a.candidates, tc)
+				continue
+			}
+
+			if err := x.satisfies(value, tc.ValueExpr); err != nil {
+				reasons = append(reasons, err.Error())
+				continue
+			}
+
+			a.candidates = append(a.candidates, tc)
+		}
+
+		if len(a.candidates) == 0 {
+			if a.extra {
+				return nil
+			}
+
+			return fmt.Errorf("value %v of %v on %v doesn't match: %v", value, p, node, strings.Join(reasons, "; "))
+		}
+
+		arcs = append(arcs, a)
+
+		return nil
+	}
+
+	for _, t := range x.g.Match(node, nil, nil) {
+		if err := neighbourhood(t, false); err != nil {
+			return err
+		}
+	}
+
+	for _, t := range x.g.Match(nil, nil, node) {
+		if err := neighbourhood(t, true); err != nil {
+			return err
+		}
+	}
+
+	groups := groupArcs(arcs)
+	counts := make(map[*TripleConstraint]int)
+
+	if assign(groups, 0, counts, func() bool { return matches(x.v, s.Expression, counts) }) {
+		return nil
+	}
+
+	// Explain with every arc given to its first candidate, which is the
+	// only assignment when no triple could go to more than one constraint
+	for _, a := range arcs {
+		counts[a.candidates[0]]++
+	}
+
+	return x.v.explain(node, s.Expression, counts)
+}
+
+func arcKey(p rdf.IRI, inverse bool) string {
+	if inverse {
+		return "^" + p.String()
+	}
+
+	return p.String()
+}
+
+// constraints lists the triple constraints of an expression, following
+// includes
+func (v *Validator) constraints(e TripleExpr) []*TripleConstraint {
+	var out []*TripleConstraint
+	seen := make(map[TripleExpr]bool)
+
+	var walk func(e TripleExpr)
+	walk = func(e TripleExpr) {
+		switch x := e.(type) {
+		case *EachOf:
+			for _, e := range x.Exprs {
+				walk(e)
+			}
+		case *OneOf:
+			for _, e := range x.Exprs {
+				walk(e)
+			}
+		case *TripleConstraint:
+			if !seen[x] {
+				seen[x] = true
+				out = append(out, x)
+			}
+		case TripleExprRef:
+			walk(v.tripleExprs[x.Label.String()])
+		}
+	}
+
+	walk(e)
+
+	return out
+}
+
+// An arcGroup is arcs with the same choices, only how many go to each
+// choice matters so they are shared out rather than tried one by one
+type arcGroup struct {
+	n          int
+	candidates []*TripleConstraint
+	extra      bool
+}
+
+func groupArcs(arcs []*arc) []*arcGroup {
+	var groups []*arcGroup
+	byKey := make(map[string]*arcGroup)
+
+	for _, a := range arcs {
+		var b strings.Builder
+		for _, tc := range a.candidates {
+			fmt.Fprintf(&b, "%p ", tc)
+		}
+
+		b.WriteString(strconv.FormatBool(a.extra))
+
+		g, ok := byKey[b.String()]
+		if !ok {
+			g = &arcGroup{candidates: a.candidates, extra: a.extra}
+			byKey[b.String()] = g
+			groups = append(groups, g)
+		}
+
+		g.n++
+	}
+
+	return groups
+}
+
+// assign shares each group's arcs between its candidates, and leaves
+// EXTRA arcs unassigned, until ok accepts the counts
+func assign(groups []*arcGroup, i int, counts map[*TripleConstraint]int, ok func() bool) bool {
+	if i == len(groups) {
+		return ok()
+	}
+
+	g := groups[i]
+
+	choices := len(g.candidates)
+	if g.extra {
+		choices++
+	}
+
+	var share func(choice, left int) bool
+	share = func(choice, left int) bool {
+		if choice == choices-1 {
+			if choice < len(g.candidates) {
+				counts[g.candidates[choice]] += left
+				defer func() { counts[g.candidates[choice]] -= left }()
+			}
+
+			return assign(groups, i+1, counts, ok)
+		}
+
+		for n := left; n >= 0; n-- {
+			counts[g.candidates[choice]] += n
+			found := share(choice+1, left-n)
+			counts[g.candidates[choice]] -= n
+
+			if found {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return share(0, g.n)
+}
+
+// matches checks the counts of triples given to each constraint against
+// the expression. Expressions where each triple constraint appears once
+// can be checked with intervals, the counts match when the expression
+// can be matched once
+//
+// https://doi.org/10.1145/2594538.2594568
+func matches(v *Validator, e TripleExpr, counts map[*TripleConstraint]int) bool {
+	if e == nil {
+		return true
+	}
+
+	lo, hi := v.interval(e, counts)
+
+	return lo <= 1 && (hi == Unbounded || hi >= 1)
+}
+
+// interval is the range of how many times the expression matches the
+// counts, empty when lo is above hi
+func (v *Validator) interval(e TripleExpr, counts map[*TripleConstraint]int) (int, int) {
+	switch x := e.(type) {
+	case *TripleConstraint:
+		n := counts[x]
+		return repeat(n, n, x.Min, x.Max)
+	case *EachOf:
+		lo, hi := 0, Unbounded
+
+		for _, e := range x.Exprs {
+			l, h := v.interval(e, counts)
+
+			if l > lo {
+				lo = l
+			}
+
+			if hi == Unbounded || h != Unbounded && h < hi {
+				hi = h
+			}
+		}
+
+		if hi != Unbounded && lo > hi {
+			return 1, 0
+		}
+
+		return repeat(lo, hi, x.Min, x.Max)
+	case *OneOf:
+		lo, hi := 0, 0
+
+		for _, e := range x.Exprs {
+			l, h := v.interval(e, counts)
+
+			if h != Unbounded && l > h {
+				return 1, 0
+			}
+
+			lo += l
+
+			if hi != Unbounded {
+				if h == Unbounded {
+					hi = Unbounded
+				} else {
+					hi += h
+				}
+			}
+		}
+
+		return repeat(lo, hi, x.Min, x.Max)
+	case TripleExprRef:
+		return v.interval(v.tripleExprs[x.Label.String()], counts)
+	}
+
+	return 1, 0
+}
+
+// repeat is the interval of an expression with the interval lo to hi
+// repeated min to max times
+func repeat(lo, hi, min, max int) (int, int) {
+	if hi != Unbounded && lo > hi {
+		return 1, 0
+	}
+
+	var newLo, newHi int
+
+	switch {
+	case lo == 0:
+		newLo = 0
+	case max == Unbounded:
+		newLo = 1
+	default:
+		newLo = (lo + max - 1) / max
+	}
+
+	switch {
+	case min == 0, hi == Unbounded:
+		newHi = Unbounded
+	default:
+		newHi = hi / min
+	}
+
+	if newHi != Unbounded && newLo > newHi {
+		return 1, 0
+	}
+
+	return newLo, newHi
+}
+
+// explain finds the triple constraints whose own cardinality the counts
+// break, looking through groups that have to match exactly once
+func (v *Validator) explain(node rdf.Term, e TripleExpr, counts map[*TripleConstraint]int) error {
+	var reasons []string
+
+	var walk func(e TripleExpr)
+	walk = func(e TripleExpr) {
+		switch x := e.(type) {
+		case *EachOf:
+			if x.Min == 1 && x.Max == 1 {
+				for _, e := range x.Exprs {
+					walk(e)
+				}
+			}
+		case *TripleConstraint:
+			n := counts[x]
+			if n < x.Min || x.Max != Unbounded && n > x.Max {
+				reasons = append(reasons, fmt.Sprintf("%v has %v %v, expected %v", node, n, x.Predicate, cardinality(x.Min, x.Max)))
+			}
+		case TripleExprRef:
+			walk(v.tripleExprs[x.Label.String()])
+		}
+	}
+
+	walk(e)
+
+	if len(reasons) == 0 {
+		return fmt.Errorf("the triples of %v don't match the shape's triple expression", node)
+	}
+
+	sort.Strings(reasons)
+
+	return errors.New(strings.Join(reasons, "; "))
+}
+
+func cardinality(min, max int) string {
+	switch {
+	case max == Unbounded:
+		return fmt.Sprintf("at least %v", min)
+	case min == max:
+		return fmt.Sprintf("exactly %v", min)
+	}
+
+	return fmt.Sprintf("between %v and %v", min, max)
+}
@@ -0,0 +1,238 @@
+package sparql
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/b1scuit/solid/rdf"
+)
+
+// AggregateExpr is one of COUNT, SUM, MIN, MAX, AVG, SAMPLE or
+// GROUP_CONCAT. Arg is nil for COUNT(*)
+type AggregateExpr struct {
+	Name      string
+	Distinct  bool
+	Arg       Expr
+	Separator string
+}
+
+func (AggregateExpr) expr() {}
+
+// An Aggregate binds the result of an aggregate over each group to a
+// variable, the parser swaps the aggregates in SELECT, HAVING and ORDER BY
+// for these variables
+type Aggregate struct {
+	Var  rdf.Variable
+	Expr AggregateExpr
+}
+
+var aggregateNames = map[string]bool{
+	"COUNT":        true,
+	"SUM":          true,
+	"MIN":          true,
+	"MAX":          true,
+	"AVG":          true,
+	"SAMPLE":       true,
+	"GROUP_CONCAT": true,
+}
+
+// aggregate works out an aggregate over the solutions of one group.
+// Values that are unbound or errors are skipped
+//
+// https://www.w3.org/TR/sparql11-query/#aggregateAlgebra
+func (e *evaluator) aggregate(a AggregateExpr, group []Solution) (rdf.Term, error) {
+	if a.Arg == nil {
+		if a.Distinct {
+			group = distinctSolutions(group)
+		}
+
+		return numberLiteral(big.NewInt(int64(len(group))), rankInteger), nil
+	}
+
+	var values []rdf.Term
+	seen := make(map[string]bool)
+
+	for _, s := range group {
+		t, err := e.expr(a.Arg, s)
+		if err != nil {
+			continue
+		}
+
+		if a.Distinct {
+			if seen[t.String()] {
+				continue
+			}
+
+			seen[t.String()] = true
+		}
+
+		values = append(values, t)
+	}
+
+	switch a.Name {
+	case "COUNT":
+		return numberLiteral(big.NewInt(int64(len(values))), rankInteger), nil
+	case "SUM", "AVG":
+		var sum rdf.Term = numberLiteral(big.NewInt(0), rankInteger)
+
+		for _, v := range values {
+			var err error
+			if sum, err = arithmetic("+", sum, v); err != nil {
+				return nil, err
+			}
+		}
+
+		if a.Name == "SUM" || len(values) == 0 {
+			return sum, nil
+		}
+
+		return arithmetic("/", sum, numberLiteral(big.NewInt(int64(len(values))), rankInteger))
+	case "MIN", "MAX":
+		if len(values) == 0 {
+			return nil, ErrTypeError
+		}
+
+		best := values[0]
+
+		for _, v := range values[1:] {
+			c := rdf.CompareTerms(v, best)
+
+			if a.Name == "MIN" && c < 0 || a.Name == "MAX" && c > 0 {
+				best = v
+			}
+		}
+
+		return best, nil
+	case "SAMPLE":
+		if len(values) == 0 {
+			return nil, ErrTypeError
+		}
+
+		return values[0], nil
+	case "GROUP_CONCAT":
+		parts := make([]string, len(values))
+
+		for i, v := range values {
+			l, err := stringLiteral(v)
+			if err != nil {
+				return nil, err
+			}
+
+			parts[i] = l.Lexical
+		}
+
+		return rdf.NewLiteral(strings.Join(parts, a.Separator)), nil
+	}
+
+	return nil, ErrTypeError
+}
+
+// extractAggregates swaps each aggregate in an expression for a variable,
+// adding the aggregate to aggs
+func (p *queryParser) extractAggregates(x Expr, aggs *[]Aggregate) Expr {
+	switch y := x.(type) {
+	case AggregateExpr:
+		p.aggregates++
+		v := rdf.Variable("_:agg" + strconv.Itoa(p.aggregates))
+		*aggs = append(*aggs, Aggregate{Var: v, Expr: y})
+
+		return TermExpr{Term: v}
+	case UnaryExpr:
+		return UnaryExpr{Op: y.Op, Arg: p.extractAggregates(y.Arg, aggs)}
+	case BinaryExpr:
+		return BinaryExpr{Op: y.Op, Left: p.extractAggregates(y.Left, aggs), Right: p.extractAggregates(y.Right, aggs)}
+	case InExpr:
+		list := make([]Expr, len(y.List))
+		for i, item := range y.List {
+			list[i] = p.extractAggregates(item, aggs)
+		}
+
+		return InExpr{Not: y.Not, Arg: p.extractAggregates(y.Arg, aggs), List: list}
+	case CallExpr:
+		args := make([]Expr, len(y.Args))
+		for i, a := range y.Args {
+			args[i] = p.extractAggregates(a, aggs)
+		}
+
+		return CallExpr{Name: y.Name, Args: args}
+	}
+
+	return x
+}
+
+// checkGrouped makes sure a grouped query only projects what it grouped
+// by, aggregates and expressions over them. Anything else has no single
+// value in a group
+//
+// https://www.w3.org/TR/sparql11-query/#aggregateRestrictions
+func checkGrouped(projection []selectItem, m *modifiers) error {
+	if len(m.group) == 0 && len(m.aggregates) == 0 {
+		return nil
+	}
+
+	grouped := map[rdf.Variable]bool{}
+
+	for _, g := range m.group {
+		switch k := g.(type) {
+		case TermExpr:
+			if v, ok := k.Term.(rdf.Variable); ok {
+				grouped[v] = true
+			}
+		case groupAs:
+			grouped[k.Var] = true
+		}
+	}
+
+	for _, a := range m.aggregates {
+		grouped[a.Var] = true
+	}
+
+	for _, pr := range projection {
+		vars := []rdf.Variable{pr.Var}
+		if pr.Expr != nil {
+			vars = exprVars(pr.Expr)
+		}
+
+		for _, v := range vars {
+			if !grouped[v] {
+				return fmt.Errorf("sparql: %v is projected but not grouped by", v)
+			}
+		}
+
+		grouped[pr.Var] = true
+	}
+
+	return nil
+}
+
+// exprVars returns the variables an expression uses outside of EXISTS
+func exprVars(x Expr) []rdf.Variable {
+	switch y := x.(type) {
+	case TermExpr:
+		if v, ok := y.Term.(rdf.Variable); ok {
+			return []rdf.Variable{v}
+		}
+	case UnaryExpr:
+		return exprVars(y.Arg)
+	case BinaryExpr:
+		return append(exprVars(y.Left), exprVars(y.Right)...)
+	case InExpr:
+		vars := exprVars(y.Arg)
+		for _, item := range y.List {
+			vars = append(vars, exprVars(item)...)
+		}
+
+		return vars
+	case CallExpr:
+		var vars []rdf.Variable
+		for _, a := range y.Args {
+			vars = append(vars, exprVars(a)...)
+		}
+
+		return vars
+	}
+
+	return nil
+}
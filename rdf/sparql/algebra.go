@@ -0,0 +1,174 @@
+package sparql
+
+import "github.com/b1scuit/solid/rdf"
+
+// A Pattern is a node of the SPARQL algebra that the parser translates a
+// query into and the evaluator walks
+//
+// https://www.w3.org/TR/sparql11-query/#sparqlAlgebra
+type Pattern interface {
+	pattern()
+}
+
+// A TriplePattern is a triple where any position can be an rdf.Variable.
+// Blank nodes in a query pattern act as variables that can't be projected
+type TriplePattern struct {
+	Subject   rdf.Term
+	Predicate rdf.Term
+	Object    rdf.Term
+}
+
+// BGP is a basic graph pattern, a set of triple patterns that all have to
+// match
+type BGP struct {
+	Patterns []TriplePattern
+}
+
+type Join struct {
+	Left, Right Pattern
+}
+
+// LeftJoin is OPTIONAL, a filter inside the optional part is kept with it
+// so it can see the variables of both sides
+type LeftJoin struct {
+	Left, Right Pattern
+	Expr        Expr
+}
+
+type Union struct {
+	Left, Right Pattern
+}
+
+// Filter removes solutions the expression isn't true for, every FILTER in
+// a group applies to the whole group
+type Filter struct {
+	Expr    Expr
+	Pattern Pattern
+}
+
+// Extend binds a variable to an expression, from BIND or (expr AS ?v)
+type Extend struct {
+	Pattern Pattern
+	Var     rdf.Variable
+	Expr    Expr
+}
+
+type Minus struct {
+	Left, Right Pattern
+}
+
+// Graph evaluates a pattern against a named graph, Name is an IRI or a
+// variable ranging over every named graph
+type Graph struct {
+	Name    rdf.Term
+	Pattern Pattern
+}
+
+// Values is inline data, a nil term in a row is UNDEF
+type Values struct {
+	Vars []rdf.Variable
+	Rows [][]rdf.Term
+}
+
+// Group partitions solutions by the value of the key expressions and
+// works out the aggregates for each group. With no keys every solution is
+// in one group
+type Group struct {
+	Pattern    Pattern
+	Keys       []Expr
+	Aggregates []Aggregate
+}
+
+type OrderCondition struct {
+	Expr       Expr
+	Descending bool
+}
+
+type OrderBy struct {
+	Pattern    Pattern
+	Conditions []OrderCondition
+}
+
+type Project struct {
+	Pattern Pattern
+	Vars    []rdf.Variable
+}
+
+type Distinct struct {
+	Pattern Pattern
+}
+
+type Reduced struct {
+	Pattern Pattern
+}
+
+// Slice is OFFSET and LIMIT, a negative Limit means no limit
+type Slice struct {
+	Pattern Pattern
+	Offset  int
+	Limit   int
+}
+
+func (BGP) pattern()      {}
+func (Join) pattern()     {}
+func (LeftJoin) pattern() {}
+func (Union) pattern()    {}
+func (Filter) pattern()   {}
+func (Extend) pattern()   {}
+func (Minus) pattern()    {}
+func (Graph) pattern()    {}
+func (Values) pattern()   {}
+func (Group) pattern()    {}
+func (OrderBy) pattern()  {}
+func (Project) pattern()  {}
+func (Distinct) pattern() {}
+func (Reduced) pattern()  {}
+func (Slice) pattern()    {}
+
+// A Solution maps variables to the terms they are bound to
+type Solution map[rdf.Variable]rdf.Term
+
+// compatible reports whether the solutions agree on every variable they
+// both bind
+func (s Solution) compatible(o Solution) bool {
+	for v, t := range s {
+		if u, ok := o[v]; ok && !rdf.Equal(t, u) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// merge returns a new solution with the bindings of both
+func (s Solution) merge(o Solution) Solution {
+	m := make(Solution, len(s)+len(o))
+	for v, t := range s {
+		m[v] = t
+	}
+	for v, t := range o {
+		m[v] = t
+	}
+
+	return m
+}
+
+// key identifies the solution for DISTINCT and grouping
+func (s Solution) key(vars []rdf.Variable) string {
+	k := ""
+	for _, v := range vars {
+		if t, ok := s[v]; ok {
+			k += t.String()
+		}
+
+		k += "\x00"
+	}
+
+	return k
+}
+
+// isHidden reports whether the variable stands for a blank node in the
+// query, those are never part of the results
+func isHidden(v rdf.Variable) bool {
+	return len(v) > 1 && v[:2] == "_:"
+}
@@ -0,0 +1,266 @@
+package sparql
+
+import (
+	"strconv"
+
+	"github.com/b1scuit/solid/rdf"
+)
+
+type ClientOption func(*Client)
+
+// WithBase sets the IRI relative IRIs in a query are resolved against
+func WithBase(base string) ClientOption {
+	return func(c *Client) {
+		c.base = base
+	}
+}
+
+// WithPrefixes declares prefixes every query can use without its own
+// PREFIX lines
+func WithPrefixes(prefixes rdf.PrefixMap) ClientOption {
+	return func(c *Client) {
+		c.prefixes.Merge(prefixes)
+	}
+}
+
+// WithFunction registers an extension function, called in a query by its
+// IRI like ex:distance(?a, ?b)
+func WithFunction(iri rdf.IRI, fn Function) ClientOption {
+	return func(c *Client) {
+		c.functions[iri] = fn
+	}
+}
+
+// WithLoader sets how LOAD fetches documents, without one LOAD fails
+func WithLoader(l Loader) ClientOption {
+	return func(c *Client) {
+		c.loader = l
+	}
+}
+
+type Client struct {
+	base      string
+	prefixes  rdf.PrefixMap
+	functions map[rdf.IRI]Function
+	loader    Loader
+}
+
+func New(opts ...ClientOption) (*Client, error) {
+	c := &Client{
+		prefixes:  make(rdf.PrefixMap),
+		functions: make(map[rdf.IRI]Function),
+	}
+
+	for _, f := range opts {
+		f(c)
+	}
+
+	return c, nil
+}
+
+func MustNew(opts ...ClientOption) *Client {
+	c, err := New(opts...)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
+
+// Parse reads a query into its algebra
+func (c *Client) Parse(query string) (*Query, error) {
+	p, err := newQueryParser(query, c.base, c.prefixes)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.parseQuery()
+}
+
+// Query parses and runs a query against a dataset
+func (c *Client) Query(ds *rdf.Dataset, query string) (*Result, error) {
+	q, err := c.Parse(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Exec(ds, q)
+}
+
+// Exec runs a parsed query against a dataset
+func (c *Client) Exec(ds *rdf.Dataset, q *Query) (*Result, error) {
+	e := newEvaluator(ds, q, q.Base, c.functions)
+
+	solutions, err := e.eval(q.Algebra)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Result{Form: q.Form}
+
+	switch q.Form {
+	case SelectForm:
+		r.Variables = q.Variables
+		r.Solutions = solutions
+	case AskForm:
+		r.Boolean = len(solutions) > 0
+	case ConstructForm:
+		r.Graph = construct(q, solutions)
+	case DescribeForm:
+		r.Graph = describe(e.graph, q, solutions)
+	}
+
+	return r, nil
+}
+
+// construct builds the template for each solution, blank nodes in the
+// template are new for every solution. Triples with an unbound variable or
+// that aren't valid RDF are left out
+func construct(q *Query, solutions []Solution) *rdf.Graph {
+	g := rdf.NewGraph()
+	g.Prefixes = q.Prefixes.Clone()
+
+	// Keep clear of the blank nodes already in the solutions
+	mint := newBlankNodeMinter()
+	for _, s := range solutions {
+		for _, t := range s {
+			mint.use(t)
+		}
+	}
+
+	for _, s := range solutions {
+		bnodes := make(map[rdf.Variable]rdf.BlankNode)
+
+		for _, tp := range q.Template {
+			if t, ok := instantiateTriple(tp, s, bnodes, mint); ok {
+				g.Add(t)
+			}
+		}
+	}
+
+	return g
+}
+
+// blankNodeMinter hands out blank nodes with labels that aren't in use
+type blankNodeMinter struct {
+	used map[rdf.BlankNode]bool
+	n    int
+}
+
+func newBlankNodeMinter() *blankNodeMinter {
+	return &blankNodeMinter{used: make(map[rdf.BlankNode]bool)}
+}
+
+func (m *blankNodeMinter) use(terms ...rdf.Term) {
+	for _, t := range terms {
+		for _, b := range rdf.BlankNodesOf(t) {
+			m.used[b] = true
+		}
+	}
+}
+
+func (m *blankNodeMinter) fresh() rdf.BlankNode {
+	for {
+		m.n++
+
+		if b := rdf.BlankNode("c" + strconv.Itoa(m.n)); !m.used[b] {
+			m.used[b] = true
+			return b
+		}
+	}
+}
+
+// instantiateTriple fills in a template triple from a solution. Hidden
+// variables are the template's blank nodes, minted into bnodes the first
+// time they're seen, or left unbound without a minter
+func instantiateTriple(tp TriplePattern, s Solution, bnodes map[rdf.Variable]rdf.BlankNode, mint *blankNodeMinter) (rdf.Triple, bool) {
+	var instantiate func(rdf.Term) rdf.Term
+	instantiate = func(t rdf.Term) rdf.Term {
+		switch x := t.(type) {
+		case rdf.Variable:
+			if isHidden(x) && mint != nil {
+				if _, ok := bnodes[x]; !ok {
+					bnodes[x] = mint.fresh()
+				}
+
+				return bnodes[x]
+			}
+
+			return s[x]
+		case rdf.QuotedTriple:
+			q := rdf.QuotedTriple{Subject: instantiate(x.Subject), Predicate: instantiate(x.Predicate), Object: instantiate(x.Object)}
+
+			if !validTriple(q.Subject, q.Predicate, q.Object) {
+				return nil
+			}
+
+			return q
+		}
+
+		return t
+	}
+
+	subject, predicate, object := instantiate(tp.Subject), instantiate(tp.Predicate), instantiate(tp.Object)
+
+	if !validTriple(subject, predicate, object) {
+		return rdf.Triple{}, false
+	}
+
+	return rdf.NewTriple(subject, predicate, object), true
+}
+
+func validTriple(s, p, o rdf.Term) bool {
+	return s != nil && p != nil && o != nil &&
+		s.Kind() != rdf.TermLiteral && p.Kind() == rdf.TermIRI
+}
+
+// describe returns the concise bounded description of each resource, the
+// triples it is the subject of, following blank node objects
+func describe(g *rdf.Graph, q *Query, solutions []Solution) *rdf.Graph {
+	out := rdf.NewGraph()
+	out.Prefixes = q.Prefixes.Clone()
+
+	if g == nil {
+		return out
+	}
+
+	var resources []rdf.Term
+
+	for _, t := range q.Describe {
+		if _, ok := t.(rdf.Variable); !ok {
+			resources = append(resources, t)
+		}
+	}
+
+	for _, s := range solutions {
+		for _, v := range q.Variables {
+			if t, ok := s[v]; ok {
+				resources = append(resources, t)
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+
+	for len(resources) > 0 {
+		r := resources[0]
+		resources = resources[1:]
+
+		if seen[r.String()] || r.Kind() == rdf.TermLiteral {
+			continue
+		}
+
+		seen[r.String()] = true
+
+		for _, t := range g.Match(r, nil, nil) {
+			out.Add(t)
+
+			if rdf.IsBlankNode(t.Object) {
+				resources = append(resources, t.Object)
+			}
+		}
+	}
+
+	return out
+}
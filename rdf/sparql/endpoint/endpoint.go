@@ -0,0 +1,464 @@
+// Package endpoint serves a dataset over HTTP with the SPARQL 1.1 Protocol
+// and the Graph Store HTTP Protocol, for running against local data
+// without a triplestore
+//
+// https://www.w3.org/TR/sparql11-protocol/
+// https://www.w3.org/TR/sparql11-http-rdf-update/
+package endpoint
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+
+	"github.com/b1scuit/solid/internal/conneg"
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/parser"
+	"github.com/b1scuit/solid/rdf/serializer"
+	"github.com/b1scuit/solid/rdf/sparql"
+)
+
+const (
+	mediaTypeQuery     = "application/sparql-query"
+	mediaTypeUpdate    = "application/sparql-update"
+	mediaTypeForm      = "application/x-www-form-urlencoded"
+	mediaTypeTurtle    = "text/turtle"
+	mediaTypeNTriples  = "application/n-triples"
+	defaultQueryPath   = "/sparql"
+	defaultGraphsPath  = "/rdf-graph-store"
+	maxRequestBodySize = 10 << 20
+)
+
+// graphTypes are the RDF formats the graph store and CONSTRUCT results can
+// be written in, in order of preference
+var graphTypes = []string{mediaTypeTurtle, mediaTypeNTriples}
+
+type ServerOption func(*Server)
+
+// WithSparql sets the client queries and updates run with, to give it
+// prefixes, extension functions or a loader
+func WithSparql(c *sparql.Client) ServerOption {
+	return func(s *Server) {
+		s.sparql = c
+	}
+}
+
+// WithReadOnly turns away updates and graph store writes
+func WithReadOnly() ServerOption {
+	return func(s *Server) {
+		s.readOnly = true
+	}
+}
+
+// WithPaths sets where the query endpoint and graph store are served,
+// they default to /sparql and /rdf-graph-store
+func WithPaths(query, graphs string) ServerOption {
+	return func(s *Server) {
+		s.queryPath = query
+		s.graphsPath = graphs
+	}
+}
+
+// WithPrefixes sets the prefixes used when writing Turtle
+func WithPrefixes(prefixes rdf.PrefixMap) ServerOption {
+	return func(s *Server) {
+		s.prefixes.Merge(prefixes)
+	}
+}
+
+// A Server is an http.Handler for a dataset, reads can run together but
+// writes have the dataset to themselves
+type Server struct {
+	mu sync.RWMutex
+	ds *rdf.Dataset
+
+	sparql     *sparql.Client
+	prefixes   rdf.PrefixMap
+	readOnly   bool
+	queryPath  string
+	graphsPath string
+}
+
+func New(ds *rdf.Dataset, opts ...ServerOption) (*Server, error) {
+	if ds == nil {
+		return nil, errors.New("endpoint: no dataset")
+	}
+
+	s := &Server{
+		ds:         ds,
+		prefixes:   make(rdf.PrefixMap),
+		queryPath:  defaultQueryPath,
+		graphsPath: defaultGraphsPath,
+	}
+
+	for _, f := range opts {
+		f(s)
+	}
+
+	if s.sparql == nil {
+		s.sparql = sparql.MustNew()
+	}
+
+	return s, nil
+}
+
+func MustNew(ds *rdf.Dataset, opts ...ServerOption) *Server {
+	s, err := New(ds, opts...)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case s.queryPath:
+		s.serveSparql(w, r)
+	case s.graphsPath:
+		s.serveGraphStore(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serveSparql handles queries by GET, and queries and updates by POST
+// either directly or URL encoded
+func (s *Server) serveSparql(w http.ResponseWriter, r *http.Request) {
+	var query, update string
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		query = r.URL.Query().Get("query")
+	case http.MethodPost:
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+		switch mediaType {
+		case mediaTypeForm:
+			r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+
+			if err := r.ParseForm(); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			query, update = r.PostForm.Get("query"), r.PostForm.Get("update")
+		case mediaTypeQuery, mediaTypeUpdate:
+			b, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBodySize))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			if mediaType == mediaTypeQuery {
+				query = string(b)
+			} else {
+				update = string(b)
+			}
+		default:
+			http.Error(w, "unsupported content type "+mediaType, http.StatusUnsupportedMediaType)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, HEAD, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	switch {
+	case query != "" && update != "":
+		http.Error(w, "give either a query or an update, not both", http.StatusBadRequest)
+	case query != "":
+		s.query(w, r, query)
+	case update != "":
+		s.update(w, r, update)
+	default:
+		http.Error(w, "no query or update", http.StatusBadRequest)
+	}
+}
+
+func (s *Server) query(w http.ResponseWriter, r *http.Request, query string) {
+	q, err := s.sparql.Parse(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The protocol's dataset parameters win over FROM and FROM NAMED
+	params := protocolParams(r)
+
+	if from, named := params["default-graph-uri"], params["named-graph-uri"]; len(from) > 0 || len(named) > 0 {
+		q.From, q.FromNamed = toIRIs(from), toIRIs(named)
+	}
+
+	s.mu.RLock()
+	res, err := s.sparql.Exec(s.ds, q)
+
+	var buf bytes.Buffer
+	var contentType string
+
+	if err == nil {
+		contentType, err = s.writeResult(&buf, r.Header.Get("Accept"), res, q.Prefixes)
+	}
+	s.mu.RUnlock()
+
+	if errors.Is(err, errNotAcceptable) {
+		http.Error(w, err.Error(), http.StatusNotAcceptable)
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Vary", "Accept")
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+
+	if r.Method != http.MethodHead {
+		w.Write(buf.Bytes())
+	}
+}
+
+var errNotAcceptable = errors.New("endpoint: no acceptable format")
+
+// writeResult writes solutions and booleans in a results format and
+// graphs as RDF, whichever the client prefers
+func (s *Server) writeResult(w io.Writer, accept string, res *sparql.Result, prefixes rdf.PrefixMap) (string, error) {
+	if res.Graph != nil {
+		mediaType := conneg.Negotiate(accept, graphTypes)
+		if mediaType == "" {
+			return "", errNotAcceptable
+		}
+
+		return mediaType + "; charset=utf-8", s.writeGraph(w, mediaType, res.Graph, prefixes)
+	}
+
+	offers := make([]string, len(sparql.ResultFormats))
+	for i, f := range sparql.ResultFormats {
+		offers[i] = f.MediaType
+	}
+
+	mediaType := conneg.Negotiate(accept, offers)
+	if mediaType == "" {
+		return "", errNotAcceptable
+	}
+
+	f, _ := sparql.LookupResultFormat(mediaType)
+
+	return mediaType + "; charset=utf-8", f.Write(w, res)
+}
+
+func (s *Server) writeGraph(w io.Writer, mediaType string, g *rdf.Graph, prefixes rdf.PrefixMap) error {
+	ser := serializer.MustNew(serializer.WithPrefixes(s.prefixes), serializer.WithPrefixes(prefixes))
+
+	if mediaType == mediaTypeNTriples {
+		return ser.NTriples(w, g)
+	}
+
+	return ser.Turtle(w, g)
+}
+
+func (s *Server) update(w http.ResponseWriter, r *http.Request, update string) {
+	if s.readOnly {
+		http.Error(w, "updates are turned off", http.StatusForbidden)
+		return
+	}
+
+	u, err := s.sparql.ParseUpdate(update)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	params := protocolParams(r)
+
+	using, named := params["using-graph-uri"], params["using-named-graph-uri"]
+	if len(using) > 0 || len(named) > 0 {
+		for i, op := range u.Operations {
+			if m, ok := op.(sparql.Modify); ok {
+				m.Using, m.UsingNamed = toIRIs(using), toIRIs(named)
+				u.Operations[i] = m
+			}
+		}
+	}
+
+	s.mu.Lock()
+	err = s.sparql.ExecUpdate(s.ds, u)
+	s.mu.Unlock()
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// serveGraphStore reads and writes whole graphs, named by the graph
+// parameter or the default graph with the default parameter
+func (s *Server) serveGraphStore(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	var name rdf.Term
+
+	switch _, isDefault := params["default"]; {
+	case isDefault && params.Has("graph"):
+		http.Error(w, "give either default or graph, not both", http.StatusBadRequest)
+		return
+	case params.Get("graph") != "":
+		name = rdf.IRI(params.Get("graph"))
+	case !isDefault:
+		http.Error(w, "no graph given", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		s.getGraph(w, r, name)
+	case http.MethodPut, http.MethodPost, http.MethodDelete:
+		if s.readOnly {
+			http.Error(w, "the graph store is read only", http.StatusForbidden)
+			return
+		}
+
+		if r.Method == http.MethodDelete {
+			s.deleteGraph(w, name)
+		} else {
+			s.putGraph(w, r, name)
+		}
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) getGraph(w http.ResponseWriter, r *http.Request, name rdf.Term) {
+	mediaType := conneg.Negotiate(r.Header.Get("Accept"), graphTypes)
+	if mediaType == "" {
+		http.Error(w, errNotAcceptable.Error(), http.StatusNotAcceptable)
+		return
+	}
+
+	var (
+		buf bytes.Buffer
+		err error
+	)
+
+	s.mu.RLock()
+	g := s.ds.Graph(name)
+	if g != nil {
+		err = s.writeGraph(&buf, mediaType, g, g.Prefixes)
+	}
+	s.mu.RUnlock()
+
+	if g == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", mediaType+"; charset=utf-8")
+	w.Header().Set("Vary", "Accept")
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+
+	if r.Method != http.MethodHead {
+		w.Write(buf.Bytes())
+	}
+}
+
+// putGraph replaces the graph for PUT and adds to it for POST, either
+// creates it if it isn't there
+func (s *Server) putGraph(w http.ResponseWriter, r *http.Request, name rdf.Term) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != mediaTypeTurtle && mediaType != mediaTypeNTriples {
+		http.Error(w, "unsupported content type "+mediaType, http.StatusUnsupportedMediaType)
+		return
+	}
+
+	base := ""
+	if name != nil {
+		base = string(name.(rdf.IRI))
+	}
+
+	p, err := parser.New(parser.WithBase(base))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := p.Do(http.MaxBytesReader(w, r.Body, maxRequestBodySize)); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	created := !s.ds.HasGraph(name)
+	g := s.ds.CreateGraph(name)
+
+	if r.Method == http.MethodPut {
+		for _, t := range g.Triples() {
+			g.Remove(t)
+		}
+	}
+
+	g.Add(p.GetGraph().Triples()...)
+	s.mu.Unlock()
+
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	} else {
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// deleteGraph removes a named graph, the default graph is only emptied
+func (s *Server) deleteGraph(w http.ResponseWriter, name rdf.Term) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.ds.HasGraph(name) {
+		http.Error(w, "no such graph", http.StatusNotFound)
+		return
+	}
+
+	if name == nil {
+		for _, t := range s.ds.Default.Triples() {
+			s.ds.Default.Remove(t)
+		}
+	} else {
+		s.ds.RemoveGraph(name)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// protocolParams are the parameters from the URL, along with the form when
+// the request was URL encoded
+func protocolParams(r *http.Request) url.Values {
+	if r.Form != nil {
+		return r.Form
+	}
+
+	return r.URL.Query()
+}
+
+func toIRIs(values []string) []rdf.IRI {
+	iris := make([]rdf.IRI, len(values))
+	for i, v := range values {
+		iris[i] = rdf.IRI(v)
+	}
+
+	return iris
+}
@@ -0,0 +1,100 @@
+package endpoint
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/b1scuit/solid/rdf"
+)
+
+func testServer() *Server {
+	ds := rdf.NewDataset(rdf.NewGraph(
+		rdf.NewTriple(rdf.IRI("http://example.org/a"), rdf.IRI("http://example.org/p"), rdf.NewLiteral("A")),
+	))
+
+	return MustNew(ds)
+}
+
+type endpointTest struct {
+	Name        string
+	Method      string
+	Target      string
+	ContentType string
+	Accept      string
+	Body        string
+	Status      int
+	Contains    string
+}
+
+// The tests run in order against the same server
+var endpointTests = []endpointTest{
+	{"Query by GET", "GET", "/sparql?query=" + url.QueryEscape("SELECT ?o WHERE { ?s ?p ?o }"), "", "", "",
+		200, `"value":"A"`},
+	{"Query as XML", "GET", "/sparql?query=" + url.QueryEscape("ASK { ?s ?p ?o }"), "", "application/sparql-results+xml", "",
+		200, "<boolean>true</boolean>"},
+	{"Query preferring CSV", "POST", "/sparql", "application/sparql-query", "text/csv;q=0.9, application/json;q=0.1, */*;q=0.2", "SELECT ?o WHERE { ?s ?p ?o }",
+		200, "o\r\nA\r\n"},
+	{"Construct", "POST", "/sparql", "application/x-www-form-urlencoded", "application/n-triples",
+		"query=" + url.QueryEscape("CONSTRUCT WHERE { ?s ?p ?o }"), 200, `<http://example.org/a> <http://example.org/p> "A" .`},
+	{"Not acceptable", "GET", "/sparql?query=" + url.QueryEscape("ASK {}"), "", "image/png", "",
+		406, ""},
+	{"Bad query", "GET", "/sparql?query=" + url.QueryEscape("SELECT"), "", "", "",
+		400, "sparql:"},
+	{"Update", "POST", "/sparql", "application/sparql-update", "", "INSERT DATA { GRAPH <http://example.org/g> { <http://example.org/b> <http://example.org/p> 1 } }",
+		204, ""},
+	{"Get graph", "GET", "/rdf-graph-store?graph=" + url.QueryEscape("http://example.org/g"), "", "text/turtle", "",
+		200, "<http://example.org/b>"},
+	{"Put graph", "PUT", "/rdf-graph-store?graph=" + url.QueryEscape("http://example.org/h"), "text/turtle", "", "<#x> <#y> <#z> .",
+		201, ""},
+	{"Relative IRIs against the graph", "GET", "/rdf-graph-store?graph=" + url.QueryEscape("http://example.org/h"), "", "application/n-triples", "",
+		200, "<http://example.org/h#x>"},
+	{"Post default graph", "POST", "/rdf-graph-store?default", "text/turtle", "", "<http://example.org/c> <http://example.org/p> 2 .",
+		204, ""},
+	{"Query after post", "GET", "/sparql?query=" + url.QueryEscape("SELECT (COUNT(*) AS ?n) WHERE { ?s ?p ?o }"), "", "text/tab-separated-values", "",
+		200, `"2"^^<http://www.w3.org/2001/XMLSchema#integer>`},
+	{"Delete graph", "DELETE", "/rdf-graph-store?graph=" + url.QueryEscape("http://example.org/g"), "", "", "",
+		204, ""},
+	{"Get deleted graph", "GET", "/rdf-graph-store?graph=" + url.QueryEscape("http://example.org/g"), "", "", "",
+		404, ""},
+	{"Unknown path", "GET", "/elsewhere", "", "", "",
+		404, ""},
+}
+
+func TestEndpoint(t *testing.T) {
+	s := testServer()
+
+	for _, tc := range endpointTests {
+		r := httptest.NewRequest(tc.Method, tc.Target, strings.NewReader(tc.Body))
+		if tc.ContentType != "" {
+			r.Header.Set("Content-Type", tc.ContentType)
+		}
+
+		if tc.Accept != "" {
+			r.Header.Set("Accept", tc.Accept)
+		}
+
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+
+		if w.Code != tc.Status || !strings.Contains(w.Body.String(), tc.Contains) {
+			t.Errorf("%v test fail: %v %q", tc.Name, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	s := MustNew(rdf.NewDataset(nil), WithReadOnly())
+
+	r := httptest.NewRequest(http.MethodPut, "/rdf-graph-store?default", strings.NewReader(""))
+	r.Header.Set("Content-Type", "text/turtle")
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("read only test fail: %v", w.Code)
+	}
+}
@@ -0,0 +1,997 @@
+package sparql
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/b1scuit/solid/rdf"
+)
+
+// evaluator holds the state of one query as it runs
+type evaluator struct {
+	dataset *rdf.Dataset
+
+	// graph is the active graph, named the graphs GRAPH can see
+	graph *rdf.Graph
+	named []rdf.Term
+
+	base      string
+	functions map[rdf.IRI]Function
+	now       time.Time
+
+	// Blank nodes made by BNODE(str) for the current solution
+	bnodes     map[string]rdf.BlankNode
+	blankNodes int
+
+	regexps map[string]*regexp.Regexp
+}
+
+// newEvaluator sets up the dataset a query sees, FROM graphs are merged
+// into its default graph and FROM NAMED limits the named graphs. Graphs
+// that aren't in the dataset are left out
+func newEvaluator(ds *rdf.Dataset, q *Query, base string, functions map[rdf.IRI]Function) *evaluator {
+	if ds == nil {
+		ds = rdf.NewDataset(nil)
+	}
+
+	e := &evaluator{
+		dataset:   ds,
+		graph:     ds.Default,
+		named:     ds.Names(),
+		base:      base,
+		functions: functions,
+		now:       time.Now(),
+	}
+
+	if len(q.From) > 0 {
+		e.graph = rdf.NewGraph()
+
+		for _, iri := range q.From {
+			if g := ds.Graph(iri); g != nil {
+				e.graph.Add(g.Triples()...)
+			}
+		}
+	}
+
+	if len(q.FromNamed) > 0 {
+		e.named = nil
+
+		for _, iri := range q.FromNamed {
+			if ds.HasGraph(iri) {
+				e.named = append(e.named, iri)
+			}
+		}
+	}
+
+	return e
+}
+
+// eval runs a pattern against the active graph
+func (e *evaluator) eval(p Pattern) ([]Solution, error) {
+	switch x := p.(type) {
+	case BGP:
+		return e.evalBGP(x.Patterns, Solution{}), nil
+	case PathPattern:
+		return e.evalPath(x, Solution{}), nil
+	case Join:
+		return e.evalJoin(x)
+	case LeftJoin:
+		return e.evalLeftJoin(x)
+	case Union:
+		left, err := e.eval(x.Left)
+		if err != nil {
+			return nil, err
+		}
+
+		right, err := e.eval(x.Right)
+
+		return append(left, right...), err
+	case Filter:
+		solutions, err := e.eval(x.Pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		var out []Solution
+		for _, s := range solutions {
+			if e.test(x.Expr, s) {
+				out = append(out, s)
+			}
+		}
+
+		return out, nil
+	case Extend:
+		solutions, err := e.eval(x.Pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, s := range solutions {
+			e.bnodes = nil
+
+			// An error leaves the variable unbound
+			if t, err := e.expr(x.Expr, s); err == nil {
+				solutions[i] = s.merge(Solution{x.Var: t})
+			}
+		}
+
+		return solutions, nil
+	case Minus:
+		return e.evalMinus(x)
+	case Graph:
+		return e.evalGraph(x)
+	case Values:
+		var out []Solution
+
+		for _, row := range x.Rows {
+			s := make(Solution)
+
+			for i, t := range row {
+				if t != nil {
+					s[x.Vars[i]] = t
+				}
+			}
+
+			out = append(out, s)
+		}
+
+		return out, nil
+	case Group:
+		return e.evalGroup(x)
+	case OrderBy:
+		return e.evalOrderBy(x)
+	case Project:
+		solutions, err := e.eval(x.Pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, s := range solutions {
+			p := make(Solution, len(x.Vars))
+
+			for _, v := range x.Vars {
+				if t, ok := s[v]; ok {
+					p[v] = t
+				}
+			}
+
+			solutions[i] = p
+		}
+
+		return solutions, nil
+	case Distinct:
+		return e.evalDistinct(x.Pattern)
+	case Reduced:
+		return e.evalDistinct(x.Pattern)
+	case Slice:
+		solutions, err := e.eval(x.Pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		if x.Offset >= len(solutions) {
+			return nil, nil
+		}
+
+		solutions = solutions[x.Offset:]
+
+		if x.Limit >= 0 && x.Limit < len(solutions) {
+			solutions = solutions[:x.Limit]
+		}
+
+		return solutions, nil
+	}
+
+	return nil, fmt.Errorf("sparql: can't evaluate %T", p)
+}
+
+// evalBGP extends the seed solution by matching the triple patterns one
+// at a time, each time picking the pattern with the most positions already
+// bound so the graph indexes do the work
+func (e *evaluator) evalBGP(patterns []TriplePattern, seed Solution) []Solution {
+	solutions := []Solution{seed}
+	remaining := append([]TriplePattern{}, patterns...)
+
+	bound := make(map[rdf.Variable]bool)
+	for v := range seed {
+		bound[v] = true
+	}
+
+	for len(remaining) > 0 && len(solutions) > 0 {
+		best, score := 0, -1
+
+		for i, tp := range remaining {
+			if s := boundPositions(tp, bound); s > score {
+				best, score = i, s
+			}
+		}
+
+		tp := remaining[best]
+		remaining = append(remaining[:best], remaining[best+1:]...)
+
+		var next []Solution
+		for _, s := range solutions {
+			next = append(next, e.matchPattern(tp, s)...)
+		}
+
+		for _, t := range []rdf.Term{tp.Subject, tp.Predicate, tp.Object} {
+			for _, v := range termVars(t) {
+				bound[v] = true
+			}
+		}
+
+		solutions = next
+	}
+
+	return solutions
+}
+
+// boundPositions scores a triple pattern by how selective it will be,
+// subjects count for more than objects and objects more than predicates
+func boundPositions(tp TriplePattern, bound map[rdf.Variable]bool) int {
+	score := 0
+
+	for i, t := range []rdf.Term{tp.Subject, tp.Predicate, tp.Object} {
+		fixed := true
+		for _, v := range termVars(t) {
+			fixed = fixed && bound[v]
+		}
+
+		if fixed {
+			score += []int{4, 1, 2}[i]
+		}
+	}
+
+	return score
+}
+
+// matchPattern extends a solution with every way the pattern matches the
+// active graph
+func (e *evaluator) matchPattern(tp TriplePattern, s Solution) []Solution {
+	if e.graph == nil {
+		return nil
+	}
+
+	subject := substitute(tp.Subject, s)
+	predicate := substitute(tp.Predicate, s)
+	object := substitute(tp.Object, s)
+
+	var out []Solution
+
+	for _, t := range e.graph.Match(wildcard(subject), wildcard(predicate), wildcard(object)) {
+		m, ok := unify(subject, t.Subject, s)
+		if !ok {
+			continue
+		}
+
+		if m, ok = unify(predicate, t.Predicate, m); !ok {
+			continue
+		}
+
+		if m, ok = unify(object, t.Object, m); ok {
+			out = append(out, m)
+		}
+	}
+
+	return out
+}
+
+// substitute replaces the bound variables in a term, including inside
+// quoted triples
+func substitute(t rdf.Term, s Solution) rdf.Term {
+	switch x := t.(type) {
+	case rdf.Variable:
+		if b, ok := s[x]; ok {
+			return b
+		}
+	case rdf.QuotedTriple:
+		return rdf.QuotedTriple{
+			Subject:   substitute(x.Subject, s),
+			Predicate: substitute(x.Predicate, s),
+			Object:    substitute(x.Object, s),
+		}
+	}
+
+	return t
+}
+
+// wildcard turns a term that still has variables into nil for Match
+func wildcard(t rdf.Term) rdf.Term {
+	if len(termVars(t)) > 0 {
+		return nil
+	}
+
+	return t
+}
+
+// unify matches a pattern term against a data term, binding variables in
+// a copy of the solution
+func unify(pattern, t rdf.Term, s Solution) (Solution, bool) {
+	switch x := pattern.(type) {
+	case rdf.Variable:
+		if b, ok := s[x]; ok {
+			return s, rdf.Equal(b, t)
+		}
+
+		return s.merge(Solution{x: t}), true
+	case rdf.QuotedTriple:
+		q, ok := t.(rdf.QuotedTriple)
+		if !ok {
+			return s, false
+		}
+
+		if s, ok = unify(x.Subject, q.Subject, s); !ok {
+			return s, false
+		}
+
+		if s, ok = unify(x.Predicate, q.Predicate, s); !ok {
+			return s, false
+		}
+
+		return unify(x.Object, q.Object, s)
+	}
+
+	return s, rdf.Equal(pattern, t)
+}
+
+func (e *evaluator) evalJoin(j Join) ([]Solution, error) {
+	left, err := e.eval(j.Left)
+	if err != nil {
+		return nil, err
+	}
+
+	// Triple and path patterns are matched with each left solution already
+	// bound, rather than on their own, so a path like foaf:knows* doesn't
+	// have to start from every node
+	switch r := j.Right.(type) {
+	case BGP:
+		var out []Solution
+		for _, l := range left {
+			out = append(out, e.evalBGP(r.Patterns, l)...)
+		}
+
+		return out, nil
+	case PathPattern:
+		var out []Solution
+		for _, l := range left {
+			out = append(out, e.evalPath(r, l)...)
+		}
+
+		return out, nil
+	}
+
+	right, err := e.eval(j.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Solution
+
+	for _, l := range left {
+		for _, r := range right {
+			if l.compatible(r) {
+				out = append(out, l.merge(r))
+			}
+		}
+	}
+
+	return out, nil
+}
+
+func (e *evaluator) evalLeftJoin(j LeftJoin) ([]Solution, error) {
+	left, err := e.eval(j.Left)
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := e.eval(j.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Solution
+
+	for _, l := range left {
+		matched := false
+
+		for _, r := range right {
+			if !l.compatible(r) {
+				continue
+			}
+
+			m := l.merge(r)
+
+			if j.Expr == nil || e.test(j.Expr, m) {
+				out = append(out, m)
+				matched = true
+			}
+		}
+
+		if !matched {
+			out = append(out, l)
+		}
+	}
+
+	return out, nil
+}
+
+// evalMinus removes the left solutions that agree with a right solution
+// on at least one shared variable
+func (e *evaluator) evalMinus(m Minus) ([]Solution, error) {
+	left, err := e.eval(m.Left)
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := e.eval(m.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Solution
+
+	for _, l := range left {
+		removed := false
+
+		for _, r := range right {
+			if l.compatible(r) && sharesVariable(l, r) {
+				removed = true
+				break
+			}
+		}
+
+		if !removed {
+			out = append(out, l)
+		}
+	}
+
+	return out, nil
+}
+
+func sharesVariable(a, b Solution) bool {
+	for v := range a {
+		if _, ok := b[v]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// evalGraph runs the pattern against one named graph, or each one in turn
+// when the name is a variable
+func (e *evaluator) evalGraph(g Graph) ([]Solution, error) {
+	active := e.graph
+	defer func() { e.graph = active }()
+
+	v, isVar := g.Name.(rdf.Variable)
+
+	if !isVar {
+		if !e.inScope(g.Name) {
+			return nil, nil
+		}
+
+		e.graph = e.dataset.Graph(g.Name)
+
+		return e.eval(g.Pattern)
+	}
+
+	var out []Solution
+
+	for _, name := range e.named {
+		e.graph = e.dataset.Graph(name)
+
+		solutions, err := e.eval(g.Pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range solutions {
+			if m, ok := unify(v, name, s); ok {
+				out = append(out, m)
+			}
+		}
+	}
+
+	return out, nil
+}
+
+func (e *evaluator) inScope(name rdf.Term) bool {
+	for _, n := range e.named {
+		if rdf.Equal(n, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// evalGroup partitions the solutions by their keys, each group becomes one
+// solution binding the key variables and the aggregates
+func (e *evaluator) evalGroup(g Group) ([]Solution, error) {
+	solutions, err := e.eval(g.Pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var order []string
+	keys := make(map[string]Solution)
+	members := make(map[string][]Solution)
+
+	// Without keys there is one group, even when there are no solutions
+	if len(g.Keys) == 0 {
+		order = append(order, "")
+		keys[""] = Solution{}
+	}
+
+	for _, s := range solutions {
+		key := ""
+		out := make(Solution)
+
+		for _, k := range g.Keys {
+			expr, v := k, rdf.Variable("")
+
+			if as, ok := k.(groupAs); ok {
+				expr, v = as.Expr, as.Var
+			} else if t, ok := k.(TermExpr); ok {
+				v, _ = t.Term.(rdf.Variable)
+			}
+
+			t, err := e.expr(expr, s)
+			if err == nil {
+				key += t.String()
+
+				if v != "" {
+					out[v] = t
+				}
+			}
+
+			key += "\x00"
+		}
+
+		if _, ok := keys[key]; !ok {
+			order = append(order, key)
+			keys[key] = out
+		}
+
+		members[key] = append(members[key], s)
+	}
+
+	out := make([]Solution, len(order))
+
+	for i, key := range order {
+		out[i] = keys[key]
+
+		for _, a := range g.Aggregates {
+			// An error leaves the aggregate unbound
+			if t, err := e.aggregate(a.Expr, members[key]); err == nil {
+				out[i][a.Var] = t
+			}
+		}
+	}
+
+	return out, nil
+}
+
+func (e *evaluator) evalOrderBy(o OrderBy) ([]Solution, error) {
+	solutions, err := e.eval(o.Pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	// Work out each sort key once, an error sorts like unbound
+	keys := make([][]rdf.Term, len(solutions))
+	for i, s := range solutions {
+		keys[i] = make([]rdf.Term, len(o.Conditions))
+
+		for j, c := range o.Conditions {
+			if t, err := e.expr(c.Expr, s); err == nil {
+				keys[i][j] = t
+			}
+		}
+	}
+
+	index := make([]int, len(solutions))
+	for i := range index {
+		index[i] = i
+	}
+
+	sort.SliceStable(index, func(a, b int) bool {
+		for j, c := range o.Conditions {
+			cmp := rdf.CompareTerms(keys[index[a]][j], keys[index[b]][j])
+
+			if c.Descending {
+				cmp = -cmp
+			}
+
+			if cmp != 0 {
+				return cmp < 0
+			}
+		}
+
+		return false
+	})
+
+	out := make([]Solution, len(solutions))
+	for i, j := range index {
+		out[i] = solutions[j]
+	}
+
+	return out, nil
+}
+
+func (e *evaluator) evalDistinct(p Pattern) ([]Solution, error) {
+	solutions, err := e.eval(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return distinctSolutions(solutions), nil
+}
+
+func distinctSolutions(solutions []Solution) []Solution {
+	var out []Solution
+	seen := make(map[string]bool)
+
+	for _, s := range solutions {
+		vars := make([]rdf.Variable, 0, len(s))
+		for v := range s {
+			vars = append(vars, v)
+		}
+
+		sort.Slice(vars, func(i, j int) bool { return vars[i] < vars[j] })
+
+		key := ""
+		for _, v := range vars {
+			key += string(v) + "="
+		}
+
+		key += s.key(vars)
+
+		if !seen[key] {
+			seen[key] = true
+			out = append(out, s)
+		}
+	}
+
+	return out
+}
+
+// test is the effective boolean value of an expression, an error is false
+func (e *evaluator) test(x Expr, s Solution) bool {
+	t, err := e.expr(x, s)
+	if err != nil {
+		return false
+	}
+
+	b, err := ebv(t)
+
+	return err == nil && b
+}
+
+// expr evaluates an expression against a solution
+//
+// https://www.w3.org/TR/sparql11-query/#evaluation
+func (e *evaluator) expr(x Expr, s Solution) (rdf.Term, error) {
+	switch x := x.(type) {
+	case TermExpr:
+		if v, ok := x.Term.(rdf.Variable); ok {
+			if t, ok := s[v]; ok {
+				return t, nil
+			}
+
+			return nil, errUnbound
+		}
+
+		return x.Term, nil
+	case UnaryExpr:
+		t, err := e.expr(x.Arg, s)
+		if err != nil {
+			return nil, err
+		}
+
+		switch x.Op {
+		case "!":
+			b, err := ebv(t)
+			if err != nil {
+				return nil, err
+			}
+
+			return boolean(!b), nil
+		case "-":
+			return negate(t)
+		}
+
+		if _, _, err := number(t); err != nil {
+			return nil, err
+		}
+
+		return t, nil
+	case BinaryExpr:
+		return e.binary(x, s)
+	case InExpr:
+		return e.in(x, s)
+	case CallExpr:
+		return e.call(x, s)
+	case *ExistsExpr:
+		solutions, err := e.eval(substitutePattern(x.Pattern, s))
+		if err != nil {
+			return nil, err
+		}
+
+		return boolean((len(solutions) > 0) != x.Not), nil
+	case groupAs:
+		return e.expr(x.Expr, s)
+	}
+
+	return nil, fmt.Errorf("sparql: can't evaluate %T", x)
+}
+
+// binary evaluates the logical operators with the three valued logic of
+// the spec, where true || error is true and false && error is false
+func (e *evaluator) binary(x BinaryExpr, s Solution) (rdf.Term, error) {
+	if x.Op == "||" || x.Op == "&&" {
+		want := x.Op == "||"
+
+		l, errL := e.truth(x.Left, s)
+		if errL == nil && l == want {
+			return boolean(want), nil
+		}
+
+		r, errR := e.truth(x.Right, s)
+		if errR == nil && r == want {
+			return boolean(want), nil
+		}
+
+		if errL != nil {
+			return nil, errL
+		}
+
+		if errR != nil {
+			return nil, errR
+		}
+
+		return boolean(!want), nil
+	}
+
+	l, err := e.expr(x.Left, s)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := e.expr(x.Right, s)
+	if err != nil {
+		return nil, err
+	}
+
+	switch x.Op {
+	case "+", "-", "*", "/":
+		return arithmetic(x.Op, l, r)
+	}
+
+	b, err := compare(x.Op, l, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return boolean(b), nil
+}
+
+func (e *evaluator) truth(x Expr, s Solution) (bool, error) {
+	t, err := e.expr(x, s)
+	if err != nil {
+		return false, err
+	}
+
+	return ebv(t)
+}
+
+// in is true if any item equals the argument, an error only counts when
+// nothing matched
+func (e *evaluator) in(x InExpr, s Solution) (rdf.Term, error) {
+	t, err := e.expr(x.Arg, s)
+	if err != nil {
+		return nil, err
+	}
+
+	var failed error
+
+	for _, item := range x.List {
+		u, err := e.expr(item, s)
+		if err == nil {
+			var eq bool
+			if eq, err = compare("=", t, u); err == nil && eq {
+				return boolean(!x.Not), nil
+			}
+		}
+
+		if err != nil {
+			failed = err
+		}
+	}
+
+	if failed != nil {
+		return nil, failed
+	}
+
+	return boolean(x.Not), nil
+}
+
+// call runs a built in, a cast or an extension function
+func (e *evaluator) call(x CallExpr, s Solution) (rdf.Term, error) {
+	switch x.Name {
+	case "BOUND":
+		if t, ok := x.Args[0].(TermExpr); ok {
+			if v, ok := t.Term.(rdf.Variable); ok {
+				_, bound := s[v]
+				return boolean(bound), nil
+			}
+
+			// A variable EXISTS has substituted is bound
+			return boolean(true), nil
+		}
+
+		return nil, ErrTypeError
+	case "IF":
+		b, err := e.truth(x.Args[0], s)
+		if err != nil {
+			return nil, err
+		}
+
+		if b {
+			return e.expr(x.Args[1], s)
+		}
+
+		return e.expr(x.Args[2], s)
+	case "COALESCE":
+		for _, a := range x.Args {
+			if t, err := e.expr(a, s); err == nil {
+				return t, nil
+			}
+		}
+
+		return nil, ErrTypeError
+	}
+
+	args := make([]rdf.Term, len(x.Args))
+
+	for i, a := range x.Args {
+		t, err := e.expr(a, s)
+		if err != nil {
+			return nil, err
+		}
+
+		args[i] = t
+	}
+
+	if b, ok := builtins[x.Name]; ok {
+		return b.fn(e, args)
+	}
+
+	if fn, ok := e.functions[rdf.IRI(x.Name)]; ok {
+		t, err := fn(args...)
+		if err != nil {
+			return nil, ErrTypeError
+		}
+
+		return t, nil
+	}
+
+	if datatype := rdf.IRI(x.Name); len(args) == 1 && (rdf.IsNumeric(datatype) || castable[datatype]) {
+		return cast(args[0], datatype)
+	}
+
+	return nil, ErrTypeError
+}
+
+// Casts to these are supported besides the numeric types
+var castable = map[rdf.IRI]bool{
+	rdf.XSDString:   true,
+	rdf.XSDBoolean:  true,
+	rdf.XSDDateTime: true,
+	rdf.XSDDate:     true,
+	rdf.XSDTime:     true,
+	rdf.XSDDuration: true,
+}
+
+func (e *evaluator) newBlankNode() rdf.BlankNode {
+	e.blankNodes++
+	return rdf.BlankNode("b" + strconv.Itoa(e.blankNodes))
+}
+
+// regexp compiles a pattern once per query
+func (e *evaluator) regexp(pattern rdf.Term, flags string) (*regexp.Regexp, error) {
+	p, err := simpleString(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	key := flags + "/" + p
+
+	if re, ok := e.regexps[key]; ok {
+		return re, nil
+	}
+
+	re, err := compileRegexp(p, flags)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.regexps == nil {
+		e.regexps = make(map[string]*regexp.Regexp)
+	}
+
+	e.regexps[key] = re
+
+	return re, nil
+}
+
+// substitutePattern replaces the variables bound in s throughout a
+// pattern, which is how EXISTS sees the solution it is testing
+//
+// https://www.w3.org/TR/sparql11-query/#defn_substitute
+func substitutePattern(p Pattern, s Solution) Pattern {
+	switch x := p.(type) {
+	case BGP:
+		patterns := make([]TriplePattern, len(x.Patterns))
+
+		for i, tp := range x.Patterns {
+			patterns[i] = TriplePattern{substitute(tp.Subject, s), substitute(tp.Predicate, s), substitute(tp.Object, s)}
+		}
+
+		return BGP{Patterns: patterns}
+	case Join:
+		return Join{Left: substitutePattern(x.Left, s), Right: substitutePattern(x.Right, s)}
+	case LeftJoin:
+		return LeftJoin{Left: substitutePattern(x.Left, s), Right: substitutePattern(x.Right, s), Expr: substituteExpr(x.Expr, s)}
+	case Union:
+		return Union{Left: substitutePattern(x.Left, s), Right: substitutePattern(x.Right, s)}
+	case Filter:
+		return Filter{Expr: substituteExpr(x.Expr, s), Pattern: substitutePattern(x.Pattern, s)}
+	case Extend:
+		return Extend{Pattern: substitutePattern(x.Pattern, s), Var: x.Var, Expr: substituteExpr(x.Expr, s)}
+	case Minus:
+		return Minus{Left: substitutePattern(x.Left, s), Right: substitutePattern(x.Right, s)}
+	case Graph:
+		return Graph{Name: substitute(x.Name, s), Pattern: substitutePattern(x.Pattern, s)}
+	case PathPattern:
+		return PathPattern{Subject: substitute(x.Subject, s), Path: x.Path, Object: substitute(x.Object, s)}
+	}
+
+	// Sub-selects and inline data have their own scope
+	return p
+}
+
+func substituteExpr(x Expr, s Solution) Expr {
+	switch y := x.(type) {
+	case nil:
+		return nil
+	case TermExpr:
+		return TermExpr{Term: substitute(y.Term, s)}
+	case UnaryExpr:
+		return UnaryExpr{Op: y.Op, Arg: substituteExpr(y.Arg, s)}
+	case BinaryExpr:
+		return BinaryExpr{Op: y.Op, Left: substituteExpr(y.Left, s), Right: substituteExpr(y.Right, s)}
+	case InExpr:
+		list := make([]Expr, len(y.List))
+		for i, item := range y.List {
+			list[i] = substituteExpr(item, s)
+		}
+
+		return InExpr{Not: y.Not, Arg: substituteExpr(y.Arg, s), List: list}
+	case CallExpr:
+		args := make([]Expr, len(y.Args))
+		for i, a := range y.Args {
+			args[i] = substituteExpr(a, s)
+		}
+
+		return CallExpr{Name: y.Name, Args: args}
+	case *ExistsExpr:
+		return &ExistsExpr{Not: y.Not, Pattern: substitutePattern(y.Pattern, s)}
+	}
+
+	return x
+}
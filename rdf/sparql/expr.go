@@ -0,0 +1,57 @@
+package sparql
+
+import "github.com/b1scuit/solid/rdf"
+
+// An Expr is a FILTER, BIND, ORDER BY or projection expression
+type Expr interface {
+	expr()
+}
+
+// TermExpr is a constant term, or a variable when Term is an rdf.Variable
+type TermExpr struct {
+	Term rdf.Term
+}
+
+// UnaryExpr is one of ! + -
+type UnaryExpr struct {
+	Op  string
+	Arg Expr
+}
+
+// BinaryExpr is one of || && = != < > <= >= + - * /
+type BinaryExpr struct {
+	Op          string
+	Left, Right Expr
+}
+
+// InExpr is IN or NOT IN
+type InExpr struct {
+	Not  bool
+	Arg  Expr
+	List []Expr
+}
+
+// CallExpr calls a built in function by its upper case keyword, or a cast
+// or extension function by its IRI
+type CallExpr struct {
+	Name string
+	Args []Expr
+}
+
+// ExistsExpr is EXISTS or NOT EXISTS
+type ExistsExpr struct {
+	Not     bool
+	Pattern Pattern
+}
+
+func (TermExpr) expr()    {}
+func (UnaryExpr) expr()   {}
+func (BinaryExpr) expr()  {}
+func (InExpr) expr()      {}
+func (CallExpr) expr()    {}
+func (*ExistsExpr) expr() {}
+
+// A Function is an extension function, called in a query by its IRI with
+// the evaluated arguments. Returning an error makes the expression
+// unbound, like any other type error
+type Function func(args ...rdf.Term) (rdf.Term, error)
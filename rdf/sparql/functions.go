@@ -0,0 +1,1186 @@
+package sparql
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"math"
+	"math/big"
+	mrand "math/rand"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/b1scuit/solid/rdf"
+)
+
+var (
+	// ErrTypeError is the error an expression raises for arguments of the
+	// wrong type, a FILTER treats it as false and BIND leaves the variable
+	// unbound
+	ErrTypeError = errors.New("sparql: type error")
+
+	errUnbound = errors.New("sparql: unbound variable")
+)
+
+// A builtin is a function called by keyword, max is -1 for any number of
+// arguments. BOUND, IF and COALESCE have no fn as they see their arguments
+// before evaluation
+type builtin struct {
+	min, max int
+	fn       func(e *evaluator, args []rdf.Term) (rdf.Term, error)
+}
+
+// https://www.w3.org/TR/sparql11-query/#SparqlOps
+var builtins = map[string]builtin{
+	"BOUND":    {1, 1, nil},
+	"IF":       {3, 3, nil},
+	"COALESCE": {0, -1, nil},
+
+	"STR":         {1, 1, fnStr},
+	"LANG":        {1, 1, fnLang},
+	"LANGMATCHES": {2, 2, fnLangMatches},
+	"DATATYPE":    {1, 1, fnDatatype},
+	"IRI":         {1, 1, fnIRI},
+	"URI":         {1, 1, fnIRI},
+	"BNODE":       {0, 1, fnBNode},
+	"RAND":        {0, 0, fnRand},
+	"ABS":         {1, 1, fnAbs},
+	"CEIL":        {1, 1, fnCeil},
+	"FLOOR":       {1, 1, fnFloor},
+	"ROUND":       {1, 1, fnRound},
+
+	"CONCAT":         {0, -1, fnConcat},
+	"SUBSTR":         {2, 3, fnSubstr},
+	"STRLEN":         {1, 1, fnStrlen},
+	"REPLACE":        {3, 4, fnReplace},
+	"UCASE":          {1, 1, fnUcase},
+	"LCASE":          {1, 1, fnLcase},
+	"ENCODE_FOR_URI": {1, 1, fnEncodeForURI},
+	"CONTAINS":       {2, 2, fnContains},
+	"STRSTARTS":      {2, 2, fnStrStarts},
+	"STRENDS":        {2, 2, fnStrEnds},
+	"STRBEFORE":      {2, 2, fnStrBefore},
+	"STRAFTER":       {2, 2, fnStrAfter},
+
+	"YEAR":     {1, 1, fnYear},
+	"MONTH":    {1, 1, fnMonth},
+	"DAY":      {1, 1, fnDay},
+	"HOURS":    {1, 1, fnHours},
+	"MINUTES":  {1, 1, fnMinutes},
+	"SECONDS":  {1, 1, fnSeconds},
+	"TIMEZONE": {1, 1, fnTimezone},
+	"TZ":       {1, 1, fnTz},
+	"NOW":      {0, 0, fnNow},
+
+	"UUID":    {0, 0, fnUUID},
+	"STRUUID": {0, 0, fnStrUUID},
+	"MD5":     {1, 1, hashFunc(md5.New)},
+	"SHA1":    {1, 1, hashFunc(sha1.New)},
+	"SHA256":  {1, 1, hashFunc(sha256.New)},
+	"SHA384":  {1, 1, hashFunc(sha512.New384)},
+	"SHA512":  {1, 1, hashFunc(sha512.New)},
+
+	"STRLANG":   {2, 2, fnStrLang},
+	"STRDT":     {2, 2, fnStrDT},
+	"SAMETERM":  {2, 2, fnSameTerm},
+	"ISIRI":     {1, 1, fnIsIRI},
+	"ISURI":     {1, 1, fnIsIRI},
+	"ISBLANK":   {1, 1, fnIsBlank},
+	"ISLITERAL": {1, 1, fnIsLiteral},
+	"ISNUMERIC": {1, 1, fnIsNumeric},
+	"REGEX":     {2, 3, fnRegex},
+
+	"TRIPLE":    {3, 3, fnTriple},
+	"SUBJECT":   {1, 1, fnSubject},
+	"PREDICATE": {1, 1, fnPredicate},
+	"OBJECT":    {1, 1, fnObject},
+	"ISTRIPLE":  {1, 1, fnIsTriple},
+}
+
+// isFunctionName reports whether a keyword starts a function call in an
+// expression
+func isFunctionName(name string) bool {
+	_, ok := builtins[name]
+	return ok || aggregateNames[name] || name == "EXISTS" || name == "NOT"
+}
+
+func checkArity(name string, n int) error {
+	b := builtins[name]
+
+	if n < b.min || b.max >= 0 && n > b.max {
+		return fmt.Errorf("sparql: wrong number of arguments to %v", name)
+	}
+
+	return nil
+}
+
+var (
+	trueLiteral  = rdf.NewTypedLiteral("true", rdf.XSDBoolean)
+	falseLiteral = rdf.NewTypedLiteral("false", rdf.XSDBoolean)
+)
+
+func boolean(b bool) rdf.Term {
+	if b {
+		return trueLiteral
+	}
+
+	return falseLiteral
+}
+
+// ebv is the effective boolean value of a term
+//
+// https://www.w3.org/TR/sparql11-query/#ebv
+func ebv(t rdf.Term) (bool, error) {
+	l, ok := t.(rdf.Literal)
+	if !ok {
+		return false, ErrTypeError
+	}
+
+	if l.Language != "" {
+		return false, ErrTypeError
+	}
+
+	if isString(l) {
+		return l.Lexical != "", nil
+	}
+
+	if l.Datatype != rdf.XSDBoolean && !rdf.IsNumeric(l.Datatype) {
+		return false, ErrTypeError
+	}
+
+	v, err := l.Value()
+	if err != nil {
+		// An ill typed boolean or number is false
+		return false, nil
+	}
+
+	switch x := v.(type) {
+	case bool:
+		return x, nil
+	case *big.Int:
+		return x.Sign() != 0, nil
+	case *big.Rat:
+		return x.Sign() != 0, nil
+	case float64:
+		return x != 0 && !math.IsNaN(x), nil
+	}
+
+	return false, ErrTypeError
+}
+
+// Numeric type promotion, a result takes the highest rank of its operands
+const (
+	rankInteger = iota
+	rankDecimal
+	rankFloat
+	rankDouble
+)
+
+// number returns the value of a numeric literal and its rank
+func number(t rdf.Term) (any, int, error) {
+	l, ok := t.(rdf.Literal)
+	if !ok || l.Language != "" || !rdf.IsNumeric(l.Datatype) {
+		return nil, 0, ErrTypeError
+	}
+
+	v, err := l.Value()
+	if err != nil {
+		return nil, 0, ErrTypeError
+	}
+
+	switch l.Datatype {
+	case rdf.XSDDecimal:
+		return v, rankDecimal, nil
+	case rdf.XSDFloat:
+		return v, rankFloat, nil
+	case rdf.XSDDouble:
+		return v, rankDouble, nil
+	}
+
+	return v, rankInteger, nil
+}
+
+func toRat(v any) *big.Rat {
+	switch x := v.(type) {
+	case *big.Int:
+		return new(big.Rat).SetInt(x)
+	case *big.Rat:
+		return x
+	case float64:
+		if r := new(big.Rat); !math.IsNaN(x) && !math.IsInf(x, 0) {
+			return r.SetFloat64(x)
+		}
+	}
+
+	return nil
+}
+
+func toFloat(v any) float64 {
+	if f, ok := v.(float64); ok {
+		return f
+	}
+
+	f, _ := toRat(v).Float64()
+
+	return f
+}
+
+func toInt(v any) *big.Int {
+	switch x := v.(type) {
+	case *big.Int:
+		return x
+	case *big.Rat:
+		return new(big.Int).Quo(x.Num(), x.Denom())
+	case float64:
+		if r := toRat(x); r != nil {
+			return new(big.Int).Quo(r.Num(), r.Denom())
+		}
+	}
+
+	return nil
+}
+
+// numberLiteral writes a value as a literal of the type for the rank
+func numberLiteral(v any, rank int) rdf.Term {
+	var l rdf.Literal
+
+	switch rank {
+	case rankInteger:
+		l, _ = rdf.LiteralOf(toInt(v))
+	case rankDecimal:
+		l, _ = rdf.LiteralOf(toRat(v))
+	case rankFloat:
+		l, _ = rdf.LiteralOf(float32(toFloat(v)))
+	default:
+		l, _ = rdf.LiteralOf(toFloat(v))
+	}
+
+	return l
+}
+
+// arithmetic applies + - * / with the XPath promotion rules, dividing two
+// integers gives a decimal
+func arithmetic(op string, a, b rdf.Term) (rdf.Term, error) {
+	va, ra, err := number(a)
+	if err != nil {
+		return nil, err
+	}
+
+	vb, rb, err := number(b)
+	if err != nil {
+		return nil, err
+	}
+
+	rank := max(ra, rb)
+
+	if op == "/" && rank == rankInteger {
+		rank = rankDecimal
+	}
+
+	switch rank {
+	case rankInteger:
+		x, y := toInt(va), toInt(vb)
+
+		switch op {
+		case "+":
+			return numberLiteral(new(big.Int).Add(x, y), rank), nil
+		case "-":
+			return numberLiteral(new(big.Int).Sub(x, y), rank), nil
+		case "*":
+			return numberLiteral(new(big.Int).Mul(x, y), rank), nil
+		}
+	case rankDecimal:
+		x, y := toRat(va), toRat(vb)
+
+		switch op {
+		case "+":
+			return numberLiteral(new(big.Rat).Add(x, y), rank), nil
+		case "-":
+			return numberLiteral(new(big.Rat).Sub(x, y), rank), nil
+		case "*":
+			return numberLiteral(new(big.Rat).Mul(x, y), rank), nil
+		case "/":
+			if y.Sign() == 0 {
+				return nil, ErrTypeError
+			}
+
+			return numberLiteral(new(big.Rat).Quo(x, y), rank), nil
+		}
+	default:
+		x, y := toFloat(va), toFloat(vb)
+
+		switch op {
+		case "+":
+			return numberLiteral(x+y, rank), nil
+		case "-":
+			return numberLiteral(x-y, rank), nil
+		case "*":
+			return numberLiteral(x*y, rank), nil
+		case "/":
+			return numberLiteral(x/y, rank), nil
+		}
+	}
+
+	return nil, ErrTypeError
+}
+
+func negate(t rdf.Term) (rdf.Term, error) {
+	v, rank, err := number(t)
+	if err != nil {
+		return nil, err
+	}
+
+	switch x := v.(type) {
+	case *big.Int:
+		return numberLiteral(new(big.Int).Neg(x), rank), nil
+	case *big.Rat:
+		return numberLiteral(new(big.Rat).Neg(x), rank), nil
+	}
+
+	return numberLiteral(-toFloat(v), rank), nil
+}
+
+// compare applies one of = != < > <= >=. Terms compare by value, and only
+// = and != work on anything other than literals
+func compare(op string, a, b rdf.Term) (bool, error) {
+	if op == "=" || op == "!=" {
+		eq, err := rdf.ValueEqual(a, b)
+		if err != nil {
+			return false, ErrTypeError
+		}
+
+		return eq == (op == "="), nil
+	}
+
+	la, okA := a.(rdf.Literal)
+	lb, okB := b.(rdf.Literal)
+
+	if !okA || !okB {
+		return false, ErrTypeError
+	}
+
+	c, err := rdf.Compare(la, lb)
+	if err != nil {
+		return false, ErrTypeError
+	}
+
+	switch op {
+	case "<":
+		return c < 0, nil
+	case ">":
+		return c > 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">=":
+		return c >= 0, nil
+	}
+
+	return false, ErrTypeError
+}
+
+// isString reports whether the literal is a simple literal or xsd:string
+func isString(l rdf.Literal) bool {
+	return l.Language == "" && (l.Datatype == "" || l.Datatype == rdf.XSDString)
+}
+
+// stringLiteral returns a string literal argument, with or without a
+// language tag
+func stringLiteral(t rdf.Term) (rdf.Literal, error) {
+	l, ok := t.(rdf.Literal)
+	if !ok || !isString(l) && l.Language == "" {
+		return rdf.Literal{}, ErrTypeError
+	}
+
+	return l, nil
+}
+
+// simpleString returns the value of a simple literal or xsd:string
+func simpleString(t rdf.Term) (string, error) {
+	l, ok := t.(rdf.Literal)
+	if !ok || !isString(l) {
+		return "", ErrTypeError
+	}
+
+	return l.Lexical, nil
+}
+
+// compatibleArgs checks the two string arguments of CONTAINS and friends,
+// the second can't have a language tag the first doesn't share
+//
+// https://www.w3.org/TR/sparql11-query/#func-arg-compatibility
+func compatibleArgs(a, b rdf.Term) (rdf.Literal, rdf.Literal, error) {
+	x, err := stringLiteral(a)
+	if err != nil {
+		return x, x, err
+	}
+
+	y, err := stringLiteral(b)
+	if err != nil {
+		return x, y, err
+	}
+
+	if y.Language != "" && y.Language != x.Language {
+		return x, y, ErrTypeError
+	}
+
+	return x, y, nil
+}
+
+// like returns a literal with the text and the language tag or datatype of
+// the given literal
+func like(l rdf.Literal, text string) rdf.Literal {
+	l.Lexical = text
+	return l
+}
+
+func fnStr(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	switch t := args[0].(type) {
+	case rdf.IRI:
+		return rdf.NewLiteral(string(t)), nil
+	case rdf.Literal:
+		return rdf.NewLiteral(t.Lexical), nil
+	}
+
+	return nil, ErrTypeError
+}
+
+func fnLang(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	l, ok := args[0].(rdf.Literal)
+	if !ok {
+		return nil, ErrTypeError
+	}
+
+	return rdf.NewLiteral(l.Language), nil
+}
+
+// Basic filtering from RFC 4647, * matches any language
+func fnLangMatches(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	tag, err := simpleString(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	lang, err := simpleString(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	tag, lang = strings.ToLower(tag), strings.ToLower(lang)
+
+	if lang == "*" {
+		return boolean(tag != ""), nil
+	}
+
+	return boolean(tag == lang || strings.HasPrefix(tag, lang+"-")), nil
+}
+
+func fnDatatype(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	l, ok := args[0].(rdf.Literal)
+	if !ok {
+		return nil, ErrTypeError
+	}
+
+	switch {
+	case l.Language != "":
+		return rdf.RDFLangString, nil
+	case l.Datatype == "":
+		return rdf.XSDString, nil
+	}
+
+	return l.Datatype, nil
+}
+
+func fnIRI(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	if iri, ok := args[0].(rdf.IRI); ok {
+		return iri, nil
+	}
+
+	s, err := simpleString(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return rdf.ResolveIRI(e.base, s), nil
+}
+
+// BNODE() is a new blank node each call, BNODE(str) gives the same blank
+// node for the same string within one solution
+func fnBNode(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	if len(args) == 0 {
+		return e.newBlankNode(), nil
+	}
+
+	s, err := simpleString(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if b, ok := e.bnodes[s]; ok {
+		return b, nil
+	}
+
+	if e.bnodes == nil {
+		e.bnodes = make(map[string]rdf.BlankNode)
+	}
+
+	b := e.newBlankNode()
+	e.bnodes[s] = b
+
+	return b, nil
+}
+
+func fnRand(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	return numberLiteral(mrand.Float64(), rankDouble), nil
+}
+
+func fnAbs(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	v, rank, err := number(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	switch x := v.(type) {
+	case *big.Int:
+		return numberLiteral(new(big.Int).Abs(x), rank), nil
+	case *big.Rat:
+		return numberLiteral(new(big.Rat).Abs(x), rank), nil
+	}
+
+	return numberLiteral(math.Abs(toFloat(v)), rank), nil
+}
+
+// rounding applies a rounding function, decimals are rounded through
+// their floor so they stay exact
+func rounding(args []rdf.Term, f func(float64) float64, r func(*big.Rat) *big.Int) (rdf.Term, error) {
+	v, rank, err := number(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	switch x := v.(type) {
+	case *big.Int:
+		return args[0], nil
+	case *big.Rat:
+		return numberLiteral(new(big.Rat).SetInt(r(x)), rank), nil
+	}
+
+	return numberLiteral(f(toFloat(v)), rank), nil
+}
+
+// floorRat relies on the denominator being positive, so Euclidean
+// division rounds down
+func floorRat(x *big.Rat) *big.Int {
+	return new(big.Int).Div(x.Num(), x.Denom())
+}
+
+func fnCeil(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	return rounding(args, math.Ceil, func(x *big.Rat) *big.Int {
+		return new(big.Int).Neg(floorRat(new(big.Rat).Neg(x)))
+	})
+}
+
+func fnFloor(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	return rounding(args, math.Floor, floorRat)
+}
+
+// ROUND rounds halves towards positive infinity
+func fnRound(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	return rounding(args, func(f float64) float64 {
+		return math.Floor(f + 0.5)
+	}, func(x *big.Rat) *big.Int {
+		return floorRat(new(big.Rat).Add(x, big.NewRat(1, 2)))
+	})
+}
+
+// CONCAT keeps a language tag only if every argument has the same one
+func fnConcat(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	var b strings.Builder
+	lang := ""
+
+	for i, a := range args {
+		l, err := stringLiteral(a)
+		if err != nil {
+			return nil, err
+		}
+
+		if i == 0 {
+			lang = l.Language
+		} else if l.Language != lang {
+			lang = ""
+		}
+
+		b.WriteString(l.Lexical)
+	}
+
+	if lang != "" {
+		return rdf.NewLangLiteral(b.String(), lang), nil
+	}
+
+	return rdf.NewLiteral(b.String()), nil
+}
+
+// SUBSTR counts characters from 1, with the start and length rounded like
+// ROUND
+func fnSubstr(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	l, err := stringLiteral(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	position := func(t rdf.Term) (float64, error) {
+		v, _, err := number(t)
+		if err != nil {
+			return 0, err
+		}
+
+		return math.Floor(toFloat(v) + 0.5), nil
+	}
+
+	start, err := position(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	end := math.Inf(1)
+
+	if len(args) == 3 {
+		length, err := position(args[2])
+		if err != nil {
+			return nil, err
+		}
+
+		end = start + length
+	}
+
+	var b strings.Builder
+
+	for i, r := range []rune(l.Lexical) {
+		if p := float64(i + 1); p >= start && p < end {
+			b.WriteRune(r)
+		}
+	}
+
+	return like(l, b.String()), nil
+}
+
+func fnStrlen(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	l, err := stringLiteral(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return numberLiteral(big.NewInt(int64(utf8.RuneCountInString(l.Lexical))), rankInteger), nil
+}
+
+// XPath replacements refer to groups as $1, Go needs ${1} so a group
+// number isn't read as part of a longer name
+var xpathGroup = regexp.MustCompile(`\\\$|\$([0-9]+)`)
+
+func fnReplace(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	l, err := stringLiteral(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	replacement, err := simpleString(args[2])
+	if err != nil {
+		return nil, err
+	}
+
+	flags := ""
+	if len(args) == 4 {
+		if flags, err = simpleString(args[3]); err != nil {
+			return nil, err
+		}
+	}
+
+	re, err := e.regexp(args[1], flags)
+	if err != nil {
+		return nil, err
+	}
+
+	replacement = xpathGroup.ReplaceAllStringFunc(replacement, func(m string) string {
+		if m == `\$` {
+			return "$$"
+		}
+
+		return "${" + m[1:] + "}"
+	})
+
+	return like(l, re.ReplaceAllString(l.Lexical, replacement)), nil
+}
+
+func fnUcase(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	l, err := stringLiteral(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return like(l, strings.ToUpper(l.Lexical)), nil
+}
+
+func fnLcase(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	l, err := stringLiteral(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return like(l, strings.ToLower(l.Lexical)), nil
+}
+
+// ENCODE_FOR_URI percent encodes everything except the unreserved
+// characters of RFC 3986
+func fnEncodeForURI(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	l, err := stringLiteral(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+
+	for _, c := range []byte(l.Lexical) {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', strings.IndexByte("-_.~", c) >= 0:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return rdf.NewLiteral(b.String()), nil
+}
+
+func fnContains(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	x, y, err := compatibleArgs(args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return boolean(strings.Contains(x.Lexical, y.Lexical)), nil
+}
+
+func fnStrStarts(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	x, y, err := compatibleArgs(args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return boolean(strings.HasPrefix(x.Lexical, y.Lexical)), nil
+}
+
+func fnStrEnds(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	x, y, err := compatibleArgs(args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return boolean(strings.HasSuffix(x.Lexical, y.Lexical)), nil
+}
+
+// STRBEFORE and STRAFTER give an empty simple literal when there is no
+// match, otherwise the result keeps the tag of the first argument
+func fnStrBefore(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	x, y, err := compatibleArgs(args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	before, _, found := strings.Cut(x.Lexical, y.Lexical)
+	if !found {
+		return rdf.NewLiteral(""), nil
+	}
+
+	return like(x, before), nil
+}
+
+func fnStrAfter(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	x, y, err := compatibleArgs(args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	_, after, found := strings.Cut(x.Lexical, y.Lexical)
+	if !found {
+		return rdf.NewLiteral(""), nil
+	}
+
+	return like(x, after), nil
+}
+
+// dateTime returns the value of an xsd:dateTime, xsd:date or xsd:time
+func dateTime(t rdf.Term) (rdf.DateTime, error) {
+	l, ok := t.(rdf.Literal)
+	if !ok {
+		return rdf.DateTime{}, ErrTypeError
+	}
+
+	v, err := l.Value()
+	if err != nil {
+		return rdf.DateTime{}, ErrTypeError
+	}
+
+	dt, ok := v.(rdf.DateTime)
+	if !ok {
+		return rdf.DateTime{}, ErrTypeError
+	}
+
+	return dt, nil
+}
+
+func datePart(part func(rdf.DateTime) int) func(*evaluator, []rdf.Term) (rdf.Term, error) {
+	return func(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+		dt, err := dateTime(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return numberLiteral(big.NewInt(int64(part(dt))), rankInteger), nil
+	}
+}
+
+var (
+	fnYear    = datePart(func(dt rdf.DateTime) int { return dt.Time.Year() })
+	fnMonth   = datePart(func(dt rdf.DateTime) int { return int(dt.Time.Month()) })
+	fnDay     = datePart(func(dt rdf.DateTime) int { return dt.Time.Day() })
+	fnHours   = datePart(func(dt rdf.DateTime) int { return dt.Time.Hour() })
+	fnMinutes = datePart(func(dt rdf.DateTime) int { return dt.Time.Minute() })
+)
+
+func fnSeconds(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	dt, err := dateTime(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	s := new(big.Rat).SetFrac64(int64(dt.Time.Second())*1e9+int64(dt.Time.Nanosecond()), 1e9)
+
+	return numberLiteral(s, rankDecimal), nil
+}
+
+func fnTimezone(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	dt, err := dateTime(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if !dt.Timezone {
+		return nil, ErrTypeError
+	}
+
+	_, offset := dt.Time.Zone()
+	d := rdf.Duration{Seconds: big.NewRat(int64(offset), 1)}
+
+	return rdf.NewTypedLiteral(d.String(), rdf.XSDDayTimeDuration), nil
+}
+
+func fnTz(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	dt, err := dateTime(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if !dt.Timezone {
+		return rdf.NewLiteral(""), nil
+	}
+
+	_, offset := dt.Time.Zone()
+	if offset == 0 {
+		return rdf.NewLiteral("Z"), nil
+	}
+
+	return rdf.NewLiteral(dt.Time.Format("-07:00")), nil
+}
+
+// NOW is the same for every call while a query runs
+func fnNow(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	l, err := rdf.LiteralOf(e.now)
+	return l, err
+}
+
+func newUUID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	h := hex.EncodeToString(b)
+
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
+
+func fnUUID(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	return rdf.IRI("urn:uuid:" + newUUID()), nil
+}
+
+func fnStrUUID(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	return rdf.NewLiteral(newUUID()), nil
+}
+
+func hashFunc(h func() hash.Hash) func(*evaluator, []rdf.Term) (rdf.Term, error) {
+	return func(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+		s, err := simpleString(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		sum := h()
+		sum.Write([]byte(s))
+
+		return rdf.NewLiteral(hex.EncodeToString(sum.Sum(nil))), nil
+	}
+}
+
+func fnStrLang(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	s, err := simpleString(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	lang, err := simpleString(args[1])
+	if err != nil || lang == "" {
+		return nil, ErrTypeError
+	}
+
+	return rdf.NewLangLiteral(s, lang), nil
+}
+
+func fnStrDT(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	s, err := simpleString(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	dt, ok := args[1].(rdf.IRI)
+	if !ok {
+		return nil, ErrTypeError
+	}
+
+	return rdf.NewTypedLiteral(s, dt), nil
+}
+
+func fnSameTerm(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	return boolean(rdf.Equal(args[0], args[1])), nil
+}
+
+func fnIsIRI(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	return boolean(args[0].Kind() == rdf.TermIRI), nil
+}
+
+func fnIsBlank(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	return boolean(args[0].Kind() == rdf.TermBlankNode), nil
+}
+
+func fnIsLiteral(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	return boolean(args[0].Kind() == rdf.TermLiteral), nil
+}
+
+func fnIsNumeric(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	_, _, err := number(args[0])
+	return boolean(err == nil), nil
+}
+
+func fnRegex(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	l, err := stringLiteral(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	flags := ""
+	if len(args) == 3 {
+		if flags, err = simpleString(args[2]); err != nil {
+			return nil, err
+		}
+	}
+
+	re, err := e.regexp(args[1], flags)
+	if err != nil {
+		return nil, err
+	}
+
+	return boolean(re.MatchString(l.Lexical)), nil
+}
+
+// compileRegexp translates an XPath pattern and flags into a Go regexp.
+// x drops whitespace from the pattern and q matches it literally
+//
+// https://www.w3.org/TR/xpath-functions/#flags
+func compileRegexp(pattern, flags string) (*regexp.Regexp, error) {
+	prefix := ""
+
+	for _, f := range flags {
+		switch f {
+		case 'i', 's', 'm':
+			prefix += string(f)
+		case 'x':
+			pattern = strings.Join(strings.Fields(pattern), "")
+		case 'q':
+			pattern = regexp.QuoteMeta(pattern)
+		default:
+			return nil, ErrTypeError
+		}
+	}
+
+	if prefix != "" {
+		pattern = "(?" + prefix + ")" + pattern
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, ErrTypeError
+	}
+
+	return re, nil
+}
+
+func fnTriple(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	s, p, o := args[0], args[1], args[2]
+
+	if s.Kind() == rdf.TermLiteral || p.Kind() != rdf.TermIRI {
+		return nil, ErrTypeError
+	}
+
+	return rdf.QuotedTriple{Subject: s, Predicate: p, Object: o}, nil
+}
+
+func quoted(t rdf.Term) (rdf.QuotedTriple, error) {
+	q, ok := t.(rdf.QuotedTriple)
+	if !ok {
+		return q, ErrTypeError
+	}
+
+	return q, nil
+}
+
+func fnSubject(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	q, err := quoted(args[0])
+	return q.Subject, err
+}
+
+func fnPredicate(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	q, err := quoted(args[0])
+	return q.Predicate, err
+}
+
+func fnObject(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	q, err := quoted(args[0])
+	return q.Object, err
+}
+
+func fnIsTriple(e *evaluator, args []rdf.Term) (rdf.Term, error) {
+	return boolean(args[0].Kind() == rdf.TermTriple), nil
+}
+
+// cast converts a term to an XSD datatype, called like a function with
+// the datatype IRI
+//
+// https://www.w3.org/TR/sparql11-query/#FunctionMapping
+func cast(t rdf.Term, datatype rdf.IRI) (rdf.Term, error) {
+	if datatype == rdf.XSDString {
+		switch x := t.(type) {
+		case rdf.IRI:
+			return rdf.NewLiteral(string(x)), nil
+		case rdf.Literal:
+			if c, err := x.Canonical(); err == nil {
+				x = c
+			}
+
+			return rdf.NewLiteral(x.Lexical), nil
+		}
+
+		return nil, ErrTypeError
+	}
+
+	l, ok := t.(rdf.Literal)
+	if !ok || l.Language != "" {
+		return nil, ErrTypeError
+	}
+
+	// Strings are read as the lexical form of the target type
+	if isString(l) {
+		out := rdf.NewTypedLiteral(strings.TrimSpace(l.Lexical), datatype)
+
+		c, err := out.Canonical()
+		if err != nil {
+			return nil, ErrTypeError
+		}
+
+		return c, nil
+	}
+
+	v, err := l.Value()
+	if err != nil {
+		return nil, ErrTypeError
+	}
+
+	if b, ok := v.(bool); ok {
+		switch {
+		case datatype == rdf.XSDBoolean:
+			return l, nil
+		case rdf.IsNumeric(datatype):
+			v = big.NewInt(0)
+			if b {
+				v = big.NewInt(1)
+			}
+		default:
+			return nil, ErrTypeError
+		}
+	}
+
+	switch v.(type) {
+	case *big.Int, *big.Rat, float64:
+		return castNumber(v, datatype)
+	}
+
+	if l.Datatype == datatype {
+		return l, nil
+	}
+
+	// A dateTime can be cast down to its date
+	if dt, ok := v.(rdf.DateTime); ok && l.Datatype == rdf.XSDDateTime && datatype == rdf.XSDDate {
+		return rdf.NewTypedLiteral(dt.Time.Format("2006-01-02"), rdf.XSDDate), nil
+	}
+
+	return nil, ErrTypeError
+}
+
+func castNumber(v any, datatype rdf.IRI) (rdf.Term, error) {
+	switch datatype {
+	case rdf.XSDBoolean:
+		b, _ := ebv(numberLiteral(v, rankDouble))
+		return boolean(b), nil
+	case rdf.XSDDouble:
+		return numberLiteral(v, rankDouble), nil
+	case rdf.XSDFloat:
+		return numberLiteral(v, rankFloat), nil
+	case rdf.XSDDecimal:
+		if toRat(v) == nil {
+			return nil, ErrTypeError
+		}
+
+		return numberLiteral(v, rankDecimal), nil
+	}
+
+	if !rdf.IsNumeric(datatype) {
+		return nil, ErrTypeError
+	}
+
+	i := toInt(v)
+	if i == nil {
+		return nil, ErrTypeError
+	}
+
+	out := rdf.NewTypedLiteral(i.String(), datatype)
+	if err := out.Validate(); err != nil {
+		return nil, ErrTypeError
+	}
+
+	return out, nil
+}
@@ -0,0 +1,2374 @@
+package sparql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/lexer"
+	"github.com/b1scuit/solid/rdf/lexer/lexertoken"
+	"github.com/b1scuit/solid/rdf/lexer/lexfn"
+)
+
+// queryParser walks the lexemes of one query or update following the
+// SPARQL grammar, translating group graph patterns into the algebra as it
+// goes
+//
+// https://www.w3.org/TR/sparql11-query/#grammar
+type queryParser struct {
+	lexemes []lexertoken.Token
+	pos     int
+
+	base     string
+	prefixes rdf.PrefixMap
+
+	blankNodes int
+	aggregates int
+
+	// Inside a CONSTRUCT template, where paths aren't allowed
+	template bool
+
+	// Aggregates can only be used in SELECT, HAVING and ORDER BY
+	allowAggregates bool
+
+	// Property paths of the triples block being read, they aren't part of
+	// its BGP
+	paths []PathPattern
+}
+
+func newQueryParser(input, base string, prefixes rdf.PrefixMap) (*queryParser, error) {
+	lex, err := lexer.New(lexer.WihInitalState(lexfn.LexSparql))
+	if err != nil {
+		return nil, err
+	}
+
+	lex.SetInput(input)
+
+	p := &queryParser{base: base, prefixes: prefixes.Clone()}
+
+	go lex.Run()
+
+	for t := range lex.NextToken() {
+		if t.Type == lexertoken.TOKEN_ERROR {
+			return nil, fmt.Errorf("sparql: %v", t.Value)
+		}
+
+		if t.Type == lexertoken.TOKEN_EOF {
+			break
+		}
+
+		if t.Type != lexertoken.TOKEN_COMMENT {
+			p.lexemes = append(p.lexemes, t)
+		}
+	}
+
+	return p, nil
+}
+
+// Query	::=	Prologue ( SelectQuery | ConstructQuery | DescribeQuery | AskQuery ) ValuesClause
+func (p *queryParser) parseQuery() (*Query, error) {
+	if err := p.parsePrologue(); err != nil {
+		return nil, err
+	}
+
+	var q *Query
+	var err error
+
+	switch t := p.peek(); {
+	case p.isKeyword("SELECT"):
+		q, err = p.parseSelect(false)
+	case p.isKeyword("CONSTRUCT"):
+		q, err = p.parseConstruct()
+	case p.isKeyword("DESCRIBE"):
+		q, err = p.parseDescribe()
+	case p.isKeyword("ASK"):
+		q, err = p.parseAsk()
+	default:
+		return nil, p.unexpected(t, "SELECT, CONSTRUCT, DESCRIBE or ASK")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.Type != lexertoken.TOKEN_EOF {
+		return nil, p.unexpected(t, "end of query")
+	}
+
+	q.Base = p.base
+	q.Prefixes = p.prefixes
+
+	return q, nil
+}
+
+// Prologue	::=	( BaseDecl | PrefixDecl )*
+func (p *queryParser) parsePrologue() error {
+	for {
+		switch p.peek().Type {
+		case lexertoken.TOKEN_BASE:
+			p.next()
+
+			iri, err := p.expect(lexertoken.TOKEN_IRIREF)
+			if err != nil {
+				return err
+			}
+
+			p.base = string(p.resolve(iri.Value))
+		case lexertoken.TOKEN_PREFIX:
+			p.next()
+
+			name, err := p.expect(lexertoken.TOKEN_PREFIX_NAME)
+			if err != nil {
+				return err
+			}
+
+			iri, err := p.expect(lexertoken.TOKEN_IRIREF)
+			if err != nil {
+				return err
+			}
+
+			if err := p.prefixes.Set(name.Value, p.resolve(iri.Value)); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+}
+
+// SelectQuery	::=	SelectClause DatasetClause* WhereClause SolutionModifier
+// SubSelect	::=	SelectClause WhereClause SolutionModifier ValuesClause
+//
+// The SELECT expressions are returned separately so the solution
+// modifiers can be placed around them in the right order
+func (p *queryParser) parseSelect(sub bool) (*Query, error) {
+	p.next()
+
+	q := &Query{Form: SelectForm}
+
+	distinct, reduced := false, false
+	switch {
+	case p.acceptKeyword("DISTINCT"):
+		distinct = true
+	case p.acceptKeyword("REDUCED"):
+		reduced = true
+	}
+
+	var projection []selectItem
+	star := false
+
+	allowAggregates := p.allowAggregates
+	p.allowAggregates = true
+
+	if p.acceptOperator("*") {
+		star = true
+	} else {
+		for {
+			switch t := p.peek(); t.Type {
+			case lexertoken.TOKEN_VAR:
+				p.next()
+				projection = append(projection, selectItem{Var: rdf.Variable(t.Value)})
+				continue
+			case lexertoken.TOKEN_START_COLLECTION:
+				p.next()
+
+				e, err := p.parseExpression()
+				if err != nil {
+					return nil, err
+				}
+
+				if err := p.expectKeyword("AS"); err != nil {
+					return nil, err
+				}
+
+				v, err := p.expect(lexertoken.TOKEN_VAR)
+				if err != nil {
+					return nil, err
+				}
+
+				if _, err := p.expect(lexertoken.TOKEN_END_COLLECTION); err != nil {
+					return nil, err
+				}
+
+				projection = append(projection, selectItem{Var: rdf.Variable(v.Value), Expr: e})
+				continue
+			}
+
+			break
+		}
+
+		if len(projection) == 0 {
+			return nil, p.unexpected(p.peek(), "variable or (expression AS ?var)")
+		}
+	}
+
+	p.allowAggregates = allowAggregates
+
+	if !sub {
+		if err := p.parseDatasetClauses(q); err != nil {
+			return nil, err
+		}
+	}
+
+	where, err := p.parseWhereClause(false)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := p.parseSolutionModifier()
+	if err != nil {
+		return nil, err
+	}
+
+	if sub {
+		values, err := p.parseValuesClause()
+		if err != nil {
+			return nil, err
+		}
+
+		m.values = values
+	}
+
+	m.distinct, m.reduced = distinct, reduced
+
+	for i := range projection {
+		if projection[i].Expr != nil {
+			projection[i].Expr = p.extractAggregates(projection[i].Expr, &m.aggregates)
+		}
+	}
+
+	if star {
+		if len(m.group) > 0 || len(m.aggregates) > 0 {
+			return nil, fmt.Errorf("sparql: SELECT * can't be used with GROUP BY")
+		}
+
+		for _, v := range visibleVars(where) {
+			projection = append(projection, selectItem{Var: v})
+		}
+	}
+
+	if err := checkGrouped(projection, m); err != nil {
+		return nil, err
+	}
+
+	for _, pr := range projection {
+		q.Variables = append(q.Variables, pr.Var)
+	}
+
+	q.Algebra = m.apply(where, projection, q.Variables)
+
+	return q, nil
+}
+
+// ConstructQuery	::=	'CONSTRUCT' ( ConstructTemplate DatasetClause* WhereClause SolutionModifier | DatasetClause* 'WHERE' '{' TriplesTemplate? '}' SolutionModifier )
+func (p *queryParser) parseConstruct() (*Query, error) {
+	p.next()
+
+	q := &Query{Form: ConstructForm}
+	short := true
+
+	if p.peek().Type == lexertoken.TOKEN_START_GROUP {
+		short = false
+
+		template, err := p.parseTemplate()
+		if err != nil {
+			return nil, err
+		}
+
+		q.Template = template
+	}
+
+	if err := p.parseDatasetClauses(q); err != nil {
+		return nil, err
+	}
+
+	var where Pattern
+
+	if short {
+		if err := p.expectKeyword("WHERE"); err != nil {
+			return nil, err
+		}
+
+		template, err := p.parseTemplate()
+		if err != nil {
+			return nil, err
+		}
+
+		q.Template = template
+		where = BGP{Patterns: template}
+	} else {
+		w, err := p.parseWhereClause(false)
+		if err != nil {
+			return nil, err
+		}
+
+		where = w
+	}
+
+	m, err := p.parseSolutionModifier()
+	if err != nil {
+		return nil, err
+	}
+
+	if m.values, err = p.parseValuesClause(); err != nil {
+		return nil, err
+	}
+
+	q.Algebra = m.apply(where, nil, nil)
+
+	return q, nil
+}
+
+// DescribeQuery	::=	'DESCRIBE' ( VarOrIri+ | '*' ) DatasetClause* WhereClause? SolutionModifier
+func (p *queryParser) parseDescribe() (*Query, error) {
+	p.next()
+
+	q := &Query{Form: DescribeForm}
+
+	if !p.acceptOperator("*") {
+		for {
+			t := p.peek()
+
+			if t.Type == lexertoken.TOKEN_VAR {
+				p.next()
+				q.Describe = append(q.Describe, rdf.Variable(t.Value))
+				continue
+			}
+
+			if t.Type == lexertoken.TOKEN_IRIREF || t.Type == lexertoken.TOKEN_PREFIXED_NAME {
+				iri, err := p.parseIri()
+				if err != nil {
+					return nil, err
+				}
+
+				q.Describe = append(q.Describe, iri)
+				continue
+			}
+
+			break
+		}
+
+		if len(q.Describe) == 0 {
+			return nil, p.unexpected(p.peek(), "variable, IRI or *")
+		}
+	}
+
+	if err := p.parseDatasetClauses(q); err != nil {
+		return nil, err
+	}
+
+	var where Pattern = BGP{}
+
+	if p.isKeyword("WHERE") || p.peek().Type == lexertoken.TOKEN_START_GROUP {
+		w, err := p.parseWhereClause(false)
+		if err != nil {
+			return nil, err
+		}
+
+		where = w
+	}
+
+	m, err := p.parseSolutionModifier()
+	if err != nil {
+		return nil, err
+	}
+
+	if m.values, err = p.parseValuesClause(); err != nil {
+		return nil, err
+	}
+
+	var vars []rdf.Variable
+	for _, t := range q.Describe {
+		if v, ok := t.(rdf.Variable); ok {
+			vars = append(vars, v)
+		}
+	}
+
+	if len(q.Describe) == 0 {
+		vars = visibleVars(where)
+	}
+
+	var projection []selectItem
+	for _, v := range vars {
+		projection = append(projection, selectItem{Var: v})
+	}
+
+	q.Variables = vars
+	q.Algebra = m.apply(where, projection, vars)
+
+	return q, nil
+}
+
+// AskQuery	::=	'ASK' DatasetClause* WhereClause SolutionModifier
+func (p *queryParser) parseAsk() (*Query, error) {
+	p.next()
+
+	q := &Query{Form: AskForm}
+
+	if err := p.parseDatasetClauses(q); err != nil {
+		return nil, err
+	}
+
+	where, err := p.parseWhereClause(false)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := p.parseSolutionModifier()
+	if err != nil {
+		return nil, err
+	}
+
+	if m.values, err = p.parseValuesClause(); err != nil {
+		return nil, err
+	}
+
+	q.Algebra = m.apply(where, nil, nil)
+
+	return q, nil
+}
+
+// DatasetClause	::=	'FROM' ( DefaultGraphClause | NamedGraphClause )
+func (p *queryParser) parseDatasetClauses(q *Query) error {
+	for p.acceptKeyword("FROM") {
+		named := p.acceptKeyword("NAMED")
+
+		iri, err := p.parseIri()
+		if err != nil {
+			return err
+		}
+
+		if named {
+			q.FromNamed = append(q.FromNamed, iri)
+		} else {
+			q.From = append(q.From, iri)
+		}
+	}
+
+	return nil
+}
+
+// WhereClause	::=	'WHERE'? GroupGraphPattern
+func (p *queryParser) parseWhereClause(required bool) (Pattern, error) {
+	if !p.acceptKeyword("WHERE") && required {
+		return nil, p.unexpected(p.peek(), "WHERE")
+	}
+
+	return p.parseGroupGraphPattern()
+}
+
+// Update	::=	Prologue ( Update1 ( ';' Update )? )?
+//
+// https://www.w3.org/TR/sparql11-update/#grammar
+func (p *queryParser) parseUpdate() (*Update, error) {
+	u := &Update{}
+
+	for {
+		if err := p.parsePrologue(); err != nil {
+			return nil, err
+		}
+
+		if p.peek().Type == lexertoken.TOKEN_EOF {
+			break
+		}
+
+		op, err := p.parseUpdate1()
+		if err != nil {
+			return nil, err
+		}
+
+		u.Operations = append(u.Operations, op)
+
+		if p.peek().Type != lexertoken.TOKEN_OBJECT_LIST {
+			break
+		}
+
+		p.next()
+	}
+
+	if t := p.peek(); t.Type != lexertoken.TOKEN_EOF {
+		return nil, p.unexpected(t, "end of update")
+	}
+
+	u.Base = p.base
+	u.Prefixes = p.prefixes
+
+	return u, nil
+}
+
+// Update1	::=	Load | Clear | Drop | Add | Move | Copy | Create | InsertData | DeleteData | DeleteWhere | Modify
+func (p *queryParser) parseUpdate1() (Operation, error) {
+	t := p.peek()
+	if t.Type != lexertoken.TOKEN_KEYWORD {
+		return nil, p.unexpected(t, "an update operation")
+	}
+
+	data := p.peekAt(1).Type == lexertoken.TOKEN_KEYWORD && p.peekAt(1).Value == "DATA"
+
+	switch {
+	case t.Value == "INSERT" && data:
+		p.next()
+		p.next()
+
+		quads, err := p.parseQuads(false)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := groundQuads(quads); err != nil {
+			return nil, err
+		}
+
+		return InsertData{Quads: quads}, nil
+	case t.Value == "DELETE" && data:
+		p.next()
+		p.next()
+
+		quads, err := p.parseQuads(true)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := groundQuads(quads); err != nil {
+			return nil, err
+		}
+
+		return DeleteData{Quads: quads}, nil
+	case t.Value == "DELETE" && p.peekAt(1).Type == lexertoken.TOKEN_KEYWORD && p.peekAt(1).Value == "WHERE":
+		p.next()
+		p.next()
+
+		quads, err := p.parseQuads(true)
+		if err != nil {
+			return nil, err
+		}
+
+		return Modify{Delete: quads, Where: quadsPattern(quads)}, nil
+	case t.Value == "INSERT", t.Value == "DELETE", t.Value == "WITH":
+		return p.parseModify()
+	case t.Value == "LOAD":
+		p.next()
+
+		o := Load{Silent: p.acceptKeyword("SILENT")}
+
+		var err error
+		if o.Source, err = p.parseIri(); err != nil {
+			return nil, err
+		}
+
+		if p.acceptKeyword("INTO") {
+			if err := p.expectKeyword("GRAPH"); err != nil {
+				return nil, err
+			}
+
+			iri, err := p.parseIri()
+			if err != nil {
+				return nil, err
+			}
+
+			o.Into = iri
+		}
+
+		return o, nil
+	case t.Value == "CLEAR", t.Value == "DROP":
+		p.next()
+
+		silent := p.acceptKeyword("SILENT")
+
+		target, err := p.parseGraphRefAll()
+		if err != nil {
+			return nil, err
+		}
+
+		if t.Value == "CLEAR" {
+			return Clear{Silent: silent, Target: target}, nil
+		}
+
+		return Drop{Silent: silent, Target: target}, nil
+	case t.Value == "CREATE":
+		p.next()
+
+		o := Create{Silent: p.acceptKeyword("SILENT")}
+
+		if err := p.expectKeyword("GRAPH"); err != nil {
+			return nil, err
+		}
+
+		var err error
+		o.Graph, err = p.parseIri()
+
+		return o, err
+	case t.Value == "ADD", t.Value == "MOVE", t.Value == "COPY":
+		p.next()
+
+		o := Transfer{Op: t.Value, Silent: p.acceptKeyword("SILENT")}
+
+		var err error
+		if o.From, err = p.parseGraphOrDefault(); err != nil {
+			return nil, err
+		}
+
+		if err := p.expectKeyword("TO"); err != nil {
+			return nil, err
+		}
+
+		o.To, err = p.parseGraphOrDefault()
+
+		return o, err
+	}
+
+	return nil, p.unexpected(t, "an update operation")
+}
+
+// Modify	::=	( 'WITH' iri )? ( DeleteClause InsertClause? | InsertClause ) UsingClause* 'WHERE' GroupGraphPattern
+func (p *queryParser) parseModify() (Operation, error) {
+	var o Modify
+	var err error
+
+	if p.acceptKeyword("WITH") {
+		if o.With, err = p.parseIri(); err != nil {
+			return nil, err
+		}
+	}
+
+	deletes := p.acceptKeyword("DELETE")
+	if deletes {
+		if o.Delete, err = p.parseQuads(true); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.acceptKeyword("INSERT") {
+		if o.Insert, err = p.parseQuads(false); err != nil {
+			return nil, err
+		}
+	} else if !deletes {
+		return nil, p.unexpected(p.peek(), "DELETE or INSERT")
+	}
+
+	for p.acceptKeyword("USING") {
+		named := p.acceptKeyword("NAMED")
+
+		iri, err := p.parseIri()
+		if err != nil {
+			return nil, err
+		}
+
+		if named {
+			o.UsingNamed = append(o.UsingNamed, iri)
+		} else {
+			o.Using = append(o.Using, iri)
+		}
+	}
+
+	if o.Where, err = p.parseWhereClause(true); err != nil {
+		return nil, err
+	}
+
+	return o, nil
+}
+
+// QuadPattern	::=	'{' Quads '}'
+// Quads	::=	TriplesTemplate? ( QuadsNotTriples '.'? TriplesTemplate? )*
+// QuadsNotTriples	::=	'GRAPH' VarOrIri '{' TriplesTemplate? '}'
+//
+// Deleting can't use blank nodes, which the parser has turned into hidden
+// variables
+func (p *queryParser) parseQuads(deleting bool) ([]QuadPattern, error) {
+	if _, err := p.expect(lexertoken.TOKEN_START_GROUP); err != nil {
+		return nil, err
+	}
+
+	var quads []QuadPattern
+
+	triples := func(graph rdf.Term) error {
+		if !p.startsTriples() {
+			return nil
+		}
+
+		p.template = true
+		patterns, err := p.parseTriplesBlock()
+		p.template = false
+
+		if err != nil {
+			return err
+		}
+
+		for _, tp := range patterns {
+			for _, v := range append(append(termVars(tp.Subject), termVars(tp.Predicate)...), termVars(tp.Object)...) {
+				if deleting && isHidden(v) {
+					return fmt.Errorf("sparql: blank nodes can't be used when deleting")
+				}
+			}
+
+			quads = append(quads, QuadPattern{TriplePattern: tp, Graph: graph})
+		}
+
+		return nil
+	}
+
+	if err := triples(nil); err != nil {
+		return nil, err
+	}
+
+	for p.acceptKeyword("GRAPH") {
+		graph, err := p.parseVarOrIri()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := p.expect(lexertoken.TOKEN_START_GROUP); err != nil {
+			return nil, err
+		}
+
+		if err := triples(graph); err != nil {
+			return nil, err
+		}
+
+		if _, err := p.expect(lexertoken.TOKEN_END_GROUP); err != nil {
+			return nil, err
+		}
+
+		if p.peek().Type == lexertoken.TOKEN_END_TRIPLE {
+			p.next()
+		}
+
+		if err := triples(nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := p.expect(lexertoken.TOKEN_END_GROUP); err != nil {
+		return nil, err
+	}
+
+	return quads, nil
+}
+
+// GraphRefAll	::=	GraphRef | 'DEFAULT' | 'NAMED' | 'ALL'
+func (p *queryParser) parseGraphRefAll() (GraphTarget, error) {
+	switch {
+	case p.acceptKeyword("DEFAULT"):
+		return GraphTarget{Kind: TargetDefault}, nil
+	case p.acceptKeyword("NAMED"):
+		return GraphTarget{Kind: TargetNamed}, nil
+	case p.acceptKeyword("ALL"):
+		return GraphTarget{Kind: TargetAll}, nil
+	}
+
+	if err := p.expectKeyword("GRAPH"); err != nil {
+		return GraphTarget{}, err
+	}
+
+	iri, err := p.parseIri()
+
+	return GraphTarget{Kind: TargetGraph, Graph: iri}, err
+}
+
+// GraphOrDefault	::=	'DEFAULT' | 'GRAPH'? iri
+func (p *queryParser) parseGraphOrDefault() (rdf.Term, error) {
+	if p.acceptKeyword("DEFAULT") {
+		return nil, nil
+	}
+
+	p.acceptKeyword("GRAPH")
+
+	return p.parseIri()
+}
+
+// groundQuads checks INSERT DATA and DELETE DATA quads have no variables
+func groundQuads(quads []QuadPattern) error {
+	for _, q := range quads {
+		vars := append(append(termVars(q.Subject), termVars(q.Predicate)...), termVars(q.Object)...)
+
+		for _, v := range append(vars, termVars(q.Graph)...) {
+			if !isHidden(v) {
+				return fmt.Errorf("sparql: variable %v can't be used in data", v)
+			}
+		}
+	}
+
+	return nil
+}
+
+// quadsPattern is the WHERE pattern of DELETE WHERE, matching the quads
+// themselves
+func quadsPattern(quads []QuadPattern) Pattern {
+	var where Pattern = BGP{}
+	var names []rdf.Term
+	graphs := make(map[string][]TriplePattern)
+
+	for _, q := range quads {
+		if q.Graph == nil {
+			where = join(where, BGP{Patterns: []TriplePattern{q.TriplePattern}})
+			continue
+		}
+
+		key := q.Graph.String()
+		if _, ok := graphs[key]; !ok {
+			names = append(names, q.Graph)
+		}
+
+		graphs[key] = append(graphs[key], q.TriplePattern)
+	}
+
+	for _, n := range names {
+		where = join(where, Graph{Name: n, Pattern: BGP{Patterns: graphs[n.String()]}})
+	}
+
+	return where
+}
+
+// selectItem is one SELECT item, Expr is nil for a plain variable
+type selectItem struct {
+	Var  rdf.Variable
+	Expr Expr
+}
+
+// modifiers holds the solution modifiers of a query until the WHERE
+// pattern can be wrapped in them
+type modifiers struct {
+	group      []Expr
+	aggregates []Aggregate
+	having     []Expr
+	order      []OrderCondition
+	values     *Values
+	distinct   bool
+	reduced    bool
+	offset     int
+	limit      int
+}
+
+// apply wraps the pattern in the algebra for the modifiers, following
+// the order in section 18.2.4 and 18.2.5 of the spec
+func (m *modifiers) apply(where Pattern, projection []selectItem, vars []rdf.Variable) Pattern {
+	p := where
+
+	// Any aggregate groups the solutions, into one group if there is no
+	// GROUP BY
+	if len(m.group) > 0 || len(m.aggregates) > 0 {
+		p = Group{Pattern: p, Keys: m.group, Aggregates: m.aggregates}
+	}
+
+	for _, h := range m.having {
+		p = Filter{Expr: h, Pattern: p}
+	}
+
+	if m.values != nil {
+		p = join(p, *m.values)
+	}
+
+	for _, pr := range projection {
+		if pr.Expr != nil {
+			p = Extend{Pattern: p, Var: pr.Var, Expr: pr.Expr}
+		}
+	}
+
+	if len(m.order) > 0 {
+		p = OrderBy{Pattern: p, Conditions: m.order}
+	}
+
+	if vars != nil {
+		p = Project{Pattern: p, Vars: vars}
+	}
+
+	if m.distinct {
+		p = Distinct{Pattern: p}
+	}
+
+	if m.reduced {
+		p = Reduced{Pattern: p}
+	}
+
+	if m.offset > 0 || m.limit >= 0 {
+		p = Slice{Pattern: p, Offset: m.offset, Limit: m.limit}
+	}
+
+	return p
+}
+
+// SolutionModifier	::=	GroupClause? HavingClause? OrderClause? LimitOffsetClauses?
+func (p *queryParser) parseSolutionModifier() (*modifiers, error) {
+	m := &modifiers{limit: -1}
+
+	if p.acceptKeyword("GROUP") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+
+		for {
+			e, ok, err := p.parseGroupCondition()
+			if err != nil {
+				return nil, err
+			}
+
+			if !ok {
+				break
+			}
+
+			m.group = append(m.group, e)
+		}
+
+		if len(m.group) == 0 {
+			return nil, p.unexpected(p.peek(), "group condition")
+		}
+	}
+
+	allowAggregates := p.allowAggregates
+	p.allowAggregates = true
+
+	defer func() { p.allowAggregates = allowAggregates }()
+
+	if p.acceptKeyword("HAVING") {
+		for {
+			e, ok, err := p.parseConstraint()
+			if err != nil {
+				return nil, err
+			}
+
+			if !ok {
+				break
+			}
+
+			m.having = append(m.having, p.extractAggregates(e, &m.aggregates))
+		}
+
+		if len(m.having) == 0 {
+			return nil, p.unexpected(p.peek(), "having condition")
+		}
+	}
+
+	if p.acceptKeyword("ORDER") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+
+		for {
+			c, ok, err := p.parseOrderCondition()
+			if err != nil {
+				return nil, err
+			}
+
+			if !ok {
+				break
+			}
+
+			c.Expr = p.extractAggregates(c.Expr, &m.aggregates)
+			m.order = append(m.order, c)
+		}
+
+		if len(m.order) == 0 {
+			return nil, p.unexpected(p.peek(), "order condition")
+		}
+	}
+
+	for {
+		switch {
+		case p.acceptKeyword("LIMIT"):
+			n, err := p.parseCount()
+			if err != nil {
+				return nil, err
+			}
+
+			m.limit = n
+		case p.acceptKeyword("OFFSET"):
+			n, err := p.parseCount()
+			if err != nil {
+				return nil, err
+			}
+
+			m.offset = n
+		default:
+			return m, nil
+		}
+	}
+}
+
+func (p *queryParser) parseCount() (int, error) {
+	t, err := p.expect(lexertoken.TOKEN_INTEGER)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(t.Value)
+}
+
+// GroupCondition	::=	BuiltInCall | FunctionCall | '(' Expression ( 'AS' Var )? ')' | Var
+//
+// (expr AS ?v) is returned as an Extend marker the caller turns into a
+// binding before grouping
+func (p *queryParser) parseGroupCondition() (Expr, bool, error) {
+	switch t := p.peek(); t.Type {
+	case lexertoken.TOKEN_VAR:
+		p.next()
+		return TermExpr{Term: rdf.Variable(t.Value)}, true, nil
+	case lexertoken.TOKEN_START_COLLECTION:
+		p.next()
+
+		e, err := p.parseExpression()
+		if err != nil {
+			return nil, false, err
+		}
+
+		if p.acceptKeyword("AS") {
+			v, err := p.expect(lexertoken.TOKEN_VAR)
+			if err != nil {
+				return nil, false, err
+			}
+
+			e = groupAs{Expr: e, Var: rdf.Variable(v.Value)}
+		}
+
+		_, err = p.expect(lexertoken.TOKEN_END_COLLECTION)
+
+		return e, true, err
+	case lexertoken.TOKEN_KEYWORD, lexertoken.TOKEN_IRIREF, lexertoken.TOKEN_PREFIXED_NAME:
+		if t.Type == lexertoken.TOKEN_KEYWORD && !isFunctionName(t.Value) {
+			return nil, false, nil
+		}
+
+		e, err := p.parsePrimary()
+		return e, err == nil, err
+	}
+
+	return nil, false, nil
+}
+
+// groupAs is a GROUP BY (expr AS ?v) key
+type groupAs struct {
+	Expr Expr
+	Var  rdf.Variable
+}
+
+func (groupAs) expr() {}
+
+// OrderCondition	::=	( ( 'ASC' | 'DESC' ) BrackettedExpression ) | ( Constraint | Var )
+func (p *queryParser) parseOrderCondition() (OrderCondition, bool, error) {
+	if p.isKeyword("ASC") || p.isKeyword("DESC") {
+		desc := p.next().Value == "DESC"
+
+		e, err := p.parseBrackettedExpression()
+		return OrderCondition{Expr: e, Descending: desc}, err == nil, err
+	}
+
+	if t := p.peek(); t.Type == lexertoken.TOKEN_VAR {
+		p.next()
+		return OrderCondition{Expr: TermExpr{Term: rdf.Variable(t.Value)}}, true, nil
+	}
+
+	e, ok, err := p.parseConstraint()
+
+	return OrderCondition{Expr: e}, ok, err
+}
+
+// Constraint	::=	BrackettedExpression | BuiltInCall | FunctionCall
+func (p *queryParser) parseConstraint() (Expr, bool, error) {
+	switch t := p.peek(); t.Type {
+	case lexertoken.TOKEN_START_COLLECTION:
+		e, err := p.parseBrackettedExpression()
+		return e, err == nil, err
+	case lexertoken.TOKEN_KEYWORD:
+		if !isFunctionName(t.Value) {
+			return nil, false, nil
+		}
+
+		e, err := p.parsePrimary()
+		return e, err == nil, err
+	case lexertoken.TOKEN_IRIREF, lexertoken.TOKEN_PREFIXED_NAME:
+		e, err := p.parsePrimary()
+		return e, err == nil, err
+	}
+
+	return nil, false, nil
+}
+
+// ValuesClause	::=	( 'VALUES' DataBlock )?
+func (p *queryParser) parseValuesClause() (*Values, error) {
+	if !p.acceptKeyword("VALUES") {
+		return nil, nil
+	}
+
+	v, err := p.parseDataBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+// DataBlock	::=	InlineDataOneVar | InlineDataFull
+func (p *queryParser) parseDataBlock() (Values, error) {
+	var v Values
+
+	if t := p.peek(); t.Type == lexertoken.TOKEN_VAR {
+		p.next()
+		v.Vars = []rdf.Variable{rdf.Variable(t.Value)}
+
+		if _, err := p.expect(lexertoken.TOKEN_START_GROUP); err != nil {
+			return v, err
+		}
+
+		for p.peek().Type != lexertoken.TOKEN_END_GROUP {
+			term, err := p.parseDataBlockValue()
+			if err != nil {
+				return v, err
+			}
+
+			v.Rows = append(v.Rows, []rdf.Term{term})
+		}
+
+		p.next()
+
+		return v, nil
+	}
+
+	if _, err := p.expect(lexertoken.TOKEN_START_COLLECTION); err != nil {
+		return v, err
+	}
+
+	for p.peek().Type == lexertoken.TOKEN_VAR {
+		v.Vars = append(v.Vars, rdf.Variable(p.next().Value))
+	}
+
+	if _, err := p.expect(lexertoken.TOKEN_END_COLLECTION); err != nil {
+		return v, err
+	}
+
+	if _, err := p.expect(lexertoken.TOKEN_START_GROUP); err != nil {
+		return v, err
+	}
+
+	for p.peek().Type != lexertoken.TOKEN_END_GROUP {
+		if _, err := p.expect(lexertoken.TOKEN_START_COLLECTION); err != nil {
+			return v, err
+		}
+
+		var row []rdf.Term
+
+		for p.peek().Type != lexertoken.TOKEN_END_COLLECTION {
+			term, err := p.parseDataBlockValue()
+			if err != nil {
+				return v, err
+			}
+
+			row = append(row, term)
+		}
+
+		p.next()
+
+		if len(row) != len(v.Vars) {
+			return v, fmt.Errorf("sparql: VALUES row has %v values for %v variables", len(row), len(v.Vars))
+		}
+
+		v.Rows = append(v.Rows, row)
+	}
+
+	p.next()
+
+	return v, nil
+}
+
+// DataBlockValue	::=	iri | RDFLiteral | NumericLiteral | BooleanLiteral | 'UNDEF'
+func (p *queryParser) parseDataBlockValue() (rdf.Term, error) {
+	if p.acceptKeyword("UNDEF") {
+		return nil, nil
+	}
+
+	switch t := p.peek(); t.Type {
+	case lexertoken.TOKEN_IRIREF, lexertoken.TOKEN_PREFIXED_NAME:
+		return p.parseIri()
+	case lexertoken.TOKEN_LITERAL, lexertoken.TOKEN_INTEGER, lexertoken.TOKEN_DECIMAL,
+		lexertoken.TOKEN_DOUBLE, lexertoken.TOKEN_BOOLEAN, lexertoken.TOKEN_OPERATOR:
+		return p.parseLiteral()
+	default:
+		return nil, p.unexpected(t, "data value")
+	}
+}
+
+// GroupGraphPattern	::=	'{' ( SubSelect | GroupGraphPatternSub ) '}'
+func (p *queryParser) parseGroupGraphPattern() (Pattern, error) {
+	if _, err := p.expect(lexertoken.TOKEN_START_GROUP); err != nil {
+		return nil, err
+	}
+
+	if p.isKeyword("SELECT") {
+		q, err := p.parseSelect(true)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = p.expect(lexertoken.TOKEN_END_GROUP)
+
+		return q.Algebra, err
+	}
+
+	pattern, err := p.parseGroupGraphPatternSub()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.expect(lexertoken.TOKEN_END_GROUP)
+
+	return pattern, err
+}
+
+// GroupGraphPatternSub	::=	TriplesBlock? ( GraphPatternNotTriples '.'? TriplesBlock? )*
+//
+// Translated as in section 18.2.2.6 of the spec, with the filters of the
+// group applied last
+func (p *queryParser) parseGroupGraphPatternSub() (Pattern, error) {
+	var g Pattern = BGP{}
+	var filters []Expr
+
+	for {
+		switch t := p.peek(); {
+		case t.Type == lexertoken.TOKEN_END_GROUP || t.Type == lexertoken.TOKEN_EOF:
+			for _, f := range filters {
+				g = Filter{Expr: f, Pattern: g}
+			}
+
+			return g, nil
+		case t.Type == lexertoken.TOKEN_END_TRIPLE:
+			p.next()
+		case p.isKeyword("OPTIONAL"):
+			p.next()
+
+			a, err := p.parseGroupGraphPattern()
+			if err != nil {
+				return nil, err
+			}
+
+			if f, ok := a.(Filter); ok {
+				g = LeftJoin{Left: g, Right: f.Pattern, Expr: conjunction(f)}
+			} else {
+				g = LeftJoin{Left: g, Right: a}
+			}
+		case p.isKeyword("MINUS"):
+			p.next()
+
+			a, err := p.parseGroupGraphPattern()
+			if err != nil {
+				return nil, err
+			}
+
+			g = Minus{Left: g, Right: a}
+		case p.isKeyword("GRAPH"):
+			p.next()
+
+			name, err := p.parseVarOrIri()
+			if err != nil {
+				return nil, err
+			}
+
+			a, err := p.parseGroupGraphPattern()
+			if err != nil {
+				return nil, err
+			}
+
+			g = join(g, Graph{Name: name, Pattern: a})
+		case p.isKeyword("FILTER"):
+			p.next()
+
+			e, ok, err := p.parseConstraint()
+			if err != nil {
+				return nil, err
+			}
+
+			if !ok {
+				return nil, p.unexpected(p.peek(), "filter expression")
+			}
+
+			filters = append(filters, e)
+		case p.isKeyword("BIND"):
+			p.next()
+
+			if _, err := p.expect(lexertoken.TOKEN_START_COLLECTION); err != nil {
+				return nil, err
+			}
+
+			e, err := p.parseExpression()
+			if err != nil {
+				return nil, err
+			}
+
+			if err := p.expectKeyword("AS"); err != nil {
+				return nil, err
+			}
+
+			v, err := p.expect(lexertoken.TOKEN_VAR)
+			if err != nil {
+				return nil, err
+			}
+
+			if _, err := p.expect(lexertoken.TOKEN_END_COLLECTION); err != nil {
+				return nil, err
+			}
+
+			for _, in := range visibleVars(g) {
+				if in == rdf.Variable(v.Value) {
+					return nil, fmt.Errorf("sparql: BIND to ?%v which is already in scope", v.Value)
+				}
+			}
+
+			g = Extend{Pattern: g, Var: rdf.Variable(v.Value), Expr: e}
+		case p.isKeyword("VALUES"):
+			p.next()
+
+			v, err := p.parseDataBlock()
+			if err != nil {
+				return nil, err
+			}
+
+			g = join(g, v)
+		case p.isKeyword("SERVICE"):
+			return nil, fmt.Errorf("sparql: SERVICE is not supported")
+		case t.Type == lexertoken.TOKEN_START_GROUP:
+			// GroupOrUnionGraphPattern	::=	GroupGraphPattern ( 'UNION' GroupGraphPattern )*
+			a, err := p.parseGroupGraphPattern()
+			if err != nil {
+				return nil, err
+			}
+
+			for p.acceptKeyword("UNION") {
+				b, err := p.parseGroupGraphPattern()
+				if err != nil {
+					return nil, err
+				}
+
+				a = Union{Left: a, Right: b}
+			}
+
+			g = join(g, a)
+		default:
+			patterns, err := p.parseTriplesBlock()
+			if err != nil {
+				return nil, err
+			}
+
+			g = join(g, BGP{Patterns: patterns})
+
+			for _, path := range p.paths {
+				g = join(g, path)
+			}
+
+			p.paths = nil
+		}
+	}
+}
+
+// TriplesBlock	::=	TriplesSameSubjectPath ( '.' TriplesBlock? )?
+func (p *queryParser) parseTriplesBlock() ([]TriplePattern, error) {
+	var patterns []TriplePattern
+
+	for {
+		more, err := p.parseTriplesSameSubject()
+		if err != nil {
+			return nil, err
+		}
+
+		patterns = append(patterns, more...)
+
+		if p.peek().Type != lexertoken.TOKEN_END_TRIPLE {
+			return patterns, nil
+		}
+
+		p.next()
+
+		if !p.startsTriples() {
+			return patterns, nil
+		}
+	}
+}
+
+// ConstructTemplate	::=	'{' ConstructTriples? '}'
+func (p *queryParser) parseTemplate() ([]TriplePattern, error) {
+	if _, err := p.expect(lexertoken.TOKEN_START_GROUP); err != nil {
+		return nil, err
+	}
+
+	var patterns []TriplePattern
+
+	if p.startsTriples() {
+		p.template = true
+		t, err := p.parseTriplesBlock()
+		p.template = false
+
+		if err != nil {
+			return nil, err
+		}
+
+		patterns = t
+	}
+
+	_, err := p.expect(lexertoken.TOKEN_END_GROUP)
+
+	return patterns, err
+}
+
+func (p *queryParser) startsTriples() bool {
+	switch p.peek().Type {
+	case lexertoken.TOKEN_VAR, lexertoken.TOKEN_IRIREF, lexertoken.TOKEN_PREFIXED_NAME,
+		lexertoken.TOKEN_BLANK_NODE, lexertoken.TOKEN_START_BLANK_NODE_PROPERTY_LIST,
+		lexertoken.TOKEN_START_COLLECTION, lexertoken.TOKEN_LITERAL, lexertoken.TOKEN_INTEGER,
+		lexertoken.TOKEN_DECIMAL, lexertoken.TOKEN_DOUBLE, lexertoken.TOKEN_BOOLEAN,
+		lexertoken.TOKEN_START_QUOTED_TRIPLE:
+		return true
+	}
+
+	return false
+}
+
+// TriplesSameSubjectPath	::=	VarOrTerm PropertyListPathNotEmpty | TriplesNodePath PropertyListPath
+func (p *queryParser) parseTriplesSameSubject() ([]TriplePattern, error) {
+	var patterns []TriplePattern
+
+	subject, err := p.parseNode(&patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	// A blank node property list or collection can stand on its own
+	if p.peek().Type == lexertoken.TOKEN_END_TRIPLE || p.peek().Type == lexertoken.TOKEN_END_GROUP {
+		if _, ok := subject.(rdf.Variable); ok && len(patterns) > 0 {
+			return patterns, nil
+		}
+	}
+
+	if err := p.parsePropertyList(subject, &patterns); err != nil {
+		return nil, err
+	}
+
+	return patterns, nil
+}
+
+// PropertyListPathNotEmpty	::=	( VerbPath | VerbSimple ) ObjectListPath ( ';' ( ( VerbPath | VerbSimple ) ObjectList )? )*
+func (p *queryParser) parsePropertyList(subject rdf.Term, patterns *[]TriplePattern) error {
+	for {
+		verb, path, err := p.parseVerbPath()
+		if err != nil {
+			return err
+		}
+
+		for {
+			object, err := p.parseNode(patterns)
+			if err != nil {
+				return err
+			}
+
+			if path != nil {
+				p.addPath(subject, path, object, patterns)
+			} else {
+				*patterns = append(*patterns, TriplePattern{subject, verb, object})
+			}
+
+			if p.peek().Type != lexertoken.TOKEN_OBJECT {
+				break
+			}
+
+			p.next()
+		}
+
+		if p.peek().Type != lexertoken.TOKEN_OBJECT_LIST {
+			return nil
+		}
+
+		for p.peek().Type == lexertoken.TOKEN_OBJECT_LIST {
+			p.next()
+		}
+
+		if !p.startsVerb() {
+			return nil
+		}
+	}
+}
+
+func (p *queryParser) startsVerb() bool {
+	switch t := p.peek(); t.Type {
+	case lexertoken.TOKEN_VAR, lexertoken.TOKEN_IRIREF, lexertoken.TOKEN_PREFIXED_NAME, lexertoken.TOKEN_PREDICATE,
+		lexertoken.TOKEN_START_COLLECTION:
+		return true
+	case lexertoken.TOKEN_OPERATOR:
+		return t.Value == "^" || t.Value == "!"
+	}
+
+	return false
+}
+
+// VerbPath	::=	Path
+//
+// A path that is just a predicate is returned as the verb, anything else
+// as a path
+func (p *queryParser) parseVerbPath() (rdf.Term, Path, error) {
+	if t := p.peek(); t.Type == lexertoken.TOKEN_VAR {
+		p.next()
+		return rdf.Variable(t.Value), nil, nil
+	}
+
+	path, err := p.parsePath()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if link, ok := path.(LinkPath); ok {
+		return link.IRI, nil, nil
+	}
+
+	if p.template {
+		return nil, nil, fmt.Errorf("sparql: property paths can't be used in a template")
+	}
+
+	return nil, path, nil
+}
+
+// addPath adds a path between two nodes, following section 18.2.2.4 of
+// the spec. Inverse predicates and sequences become triple patterns
+// joined by hidden variables, other paths are kept for evaluation
+func (p *queryParser) addPath(subject rdf.Term, path Path, object rdf.Term, patterns *[]TriplePattern) {
+	switch x := path.(type) {
+	case LinkPath:
+		*patterns = append(*patterns, TriplePattern{subject, x.IRI, object})
+	case InversePath:
+		if link, ok := x.Path.(LinkPath); ok {
+			*patterns = append(*patterns, TriplePattern{object, link.IRI, subject})
+			return
+		}
+
+		p.paths = append(p.paths, PathPattern{subject, path, object})
+	case SequencePath:
+		v := p.newBlankNode()
+
+		p.addPath(subject, x.Left, v, patterns)
+		p.addPath(v, x.Right, object, patterns)
+	default:
+		p.paths = append(p.paths, PathPattern{subject, path, object})
+	}
+}
+
+// Path	::=	PathAlternative
+// PathAlternative	::=	PathSequence ( '|' PathSequence )*
+func (p *queryParser) parsePath() (Path, error) {
+	left, err := p.parsePathSequence()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.acceptOperator("|") {
+		right, err := p.parsePathSequence()
+		if err != nil {
+			return nil, err
+		}
+
+		left = AlternativePath{Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+// PathSequence	::=	PathEltOrInverse ( '/' PathEltOrInverse )*
+func (p *queryParser) parsePathSequence() (Path, error) {
+	left, err := p.parsePathEltOrInverse()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.acceptOperator("/") {
+		right, err := p.parsePathEltOrInverse()
+		if err != nil {
+			return nil, err
+		}
+
+		left = SequencePath{Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+// PathEltOrInverse	::=	PathElt | '^' PathElt
+// PathElt	::=	PathPrimary PathMod?
+func (p *queryParser) parsePathEltOrInverse() (Path, error) {
+	inverse := p.acceptOperator("^")
+
+	path, err := p.parsePathPrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case p.acceptOperator("?"):
+		path = ZeroOrOnePath{Path: path}
+	case p.acceptOperator("*"):
+		path = ZeroOrMorePath{Path: path}
+	case p.acceptOperator("+"):
+		path = OneOrMorePath{Path: path}
+	}
+
+	if inverse {
+		path = InversePath{Path: path}
+	}
+
+	return path, nil
+}
+
+// PathPrimary	::=	iri | 'a' | '!' PathNegatedPropertySet | '(' Path ')'
+func (p *queryParser) parsePathPrimary() (Path, error) {
+	switch t := p.peek(); {
+	case t.Type == lexertoken.TOKEN_PREDICATE:
+		p.next()
+		return LinkPath{IRI: rdf.RDFType}, nil
+	case t.Type == lexertoken.TOKEN_IRIREF || t.Type == lexertoken.TOKEN_PREFIXED_NAME:
+		iri, err := p.parseIri()
+		return LinkPath{IRI: iri}, err
+	case t.Type == lexertoken.TOKEN_START_COLLECTION:
+		p.next()
+
+		path, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = p.expect(lexertoken.TOKEN_END_COLLECTION)
+
+		return path, err
+	case p.acceptOperator("!"):
+		return p.parseNegatedPropertySet()
+	default:
+		return nil, p.unexpected(t, "predicate or property path")
+	}
+}
+
+// PathNegatedPropertySet	::=	PathOneInPropertySet | '(' ( PathOneInPropertySet ( '|' PathOneInPropertySet )* )? ')'
+// PathOneInPropertySet	::=	iri | 'a' | '^' ( iri | 'a' )
+func (p *queryParser) parseNegatedPropertySet() (Path, error) {
+	var set NegatedPropertySet
+
+	one := func() error {
+		inverse := p.acceptOperator("^")
+
+		var iri rdf.IRI
+
+		if p.peek().Type == lexertoken.TOKEN_PREDICATE {
+			p.next()
+			iri = rdf.RDFType
+		} else {
+			var err error
+			if iri, err = p.parseIri(); err != nil {
+				return err
+			}
+		}
+
+		if inverse {
+			set.Inverse = append(set.Inverse, iri)
+		} else {
+			set.IRIs = append(set.IRIs, iri)
+		}
+
+		return nil
+	}
+
+	if p.peek().Type != lexertoken.TOKEN_START_COLLECTION {
+		return set, one()
+	}
+
+	p.next()
+
+	for p.peek().Type != lexertoken.TOKEN_END_COLLECTION {
+		if len(set.IRIs)+len(set.Inverse) > 0 {
+			if !p.acceptOperator("|") {
+				return nil, p.unexpected(p.peek(), "|")
+			}
+		}
+
+		if err := one(); err != nil {
+			return nil, err
+		}
+	}
+
+	p.next()
+
+	return set, nil
+}
+
+// VerbSimple	::=	Var
+// Verb	::=	VarOrIri | 'a'
+func (p *queryParser) parseVerb() (rdf.Term, error) {
+	switch t := p.peek(); t.Type {
+	case lexertoken.TOKEN_PREDICATE:
+		p.next()
+		return rdf.RDFType, nil
+	case lexertoken.TOKEN_VAR:
+		p.next()
+		return rdf.Variable(t.Value), nil
+	case lexertoken.TOKEN_IRIREF, lexertoken.TOKEN_PREFIXED_NAME:
+		return p.parseIri()
+	default:
+		return nil, p.unexpected(t, "predicate")
+	}
+}
+
+// GraphNode	::=	VarOrTerm | TriplesNode
+//
+// Blank node property lists and collections add their triples to
+// patterns and return the node that stands for them
+func (p *queryParser) parseNode(patterns *[]TriplePattern) (rdf.Term, error) {
+	switch t := p.peek(); t.Type {
+	case lexertoken.TOKEN_VAR:
+		p.next()
+		return rdf.Variable(t.Value), nil
+	case lexertoken.TOKEN_IRIREF, lexertoken.TOKEN_PREFIXED_NAME:
+		return p.parseIri()
+	case lexertoken.TOKEN_BLANK_NODE:
+		p.next()
+		return rdf.Variable("_:" + t.Value), nil
+	case lexertoken.TOKEN_START_BLANK_NODE_PROPERTY_LIST:
+		p.next()
+
+		node := p.newBlankNode()
+
+		if p.peek().Type != lexertoken.TOKEN_END_BLANK_NODE_PROPERTY_LIST {
+			if err := p.parsePropertyList(node, patterns); err != nil {
+				return nil, err
+			}
+		}
+
+		_, err := p.expect(lexertoken.TOKEN_END_BLANK_NODE_PROPERTY_LIST)
+
+		return node, err
+	case lexertoken.TOKEN_START_COLLECTION:
+		p.next()
+
+		var head, tail rdf.Term = rdf.RDFNil, nil
+
+		for p.peek().Type != lexertoken.TOKEN_END_COLLECTION {
+			item, err := p.parseNode(patterns)
+			if err != nil {
+				return nil, err
+			}
+
+			node := p.newBlankNode()
+
+			if tail == nil {
+				head = node
+			} else {
+				*patterns = append(*patterns, TriplePattern{tail, rdf.RDFRest, node})
+			}
+
+			*patterns = append(*patterns, TriplePattern{node, rdf.RDFFirst, item})
+			tail = node
+		}
+
+		p.next()
+
+		if tail != nil {
+			*patterns = append(*patterns, TriplePattern{tail, rdf.RDFRest, rdf.RDFNil})
+		}
+
+		return head, nil
+	case lexertoken.TOKEN_START_QUOTED_TRIPLE:
+		p.next()
+
+		s, err := p.parseNode(patterns)
+		if err != nil {
+			return nil, err
+		}
+
+		v, err := p.parseVerb()
+		if err != nil {
+			return nil, err
+		}
+
+		o, err := p.parseNode(patterns)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = p.expect(lexertoken.TOKEN_END_QUOTED_TRIPLE)
+
+		return rdf.QuotedTriple{Subject: s, Predicate: v, Object: o}, err
+	case lexertoken.TOKEN_LITERAL, lexertoken.TOKEN_INTEGER, lexertoken.TOKEN_DECIMAL,
+		lexertoken.TOKEN_DOUBLE, lexertoken.TOKEN_BOOLEAN, lexertoken.TOKEN_OPERATOR:
+		return p.parseLiteral()
+	default:
+		return nil, p.unexpected(t, "term")
+	}
+}
+
+func (p *queryParser) parseVarOrIri() (rdf.Term, error) {
+	if t := p.peek(); t.Type == lexertoken.TOKEN_VAR {
+		p.next()
+		return rdf.Variable(t.Value), nil
+	}
+
+	return p.parseIri()
+}
+
+// RDFLiteral	::=	String ( LANGTAG | ( '^^' iri ) )?
+// NumericLiteral	::=	NumericLiteralUnsigned | NumericLiteralPositive | NumericLiteralNegative
+//
+// The lexer leaves signs as operators, a sign straight before a number is
+// part of the literal
+func (p *queryParser) parseLiteral() (rdf.Term, error) {
+	t := p.next()
+
+	sign := ""
+	if t.Type == lexertoken.TOKEN_OPERATOR && (t.Value == "+" || t.Value == "-") {
+		sign = t.Value
+		t = p.next()
+	}
+
+	switch t.Type {
+	case lexertoken.TOKEN_INTEGER:
+		return rdf.NewTypedLiteral(sign+t.Value, rdf.XSDInteger), nil
+	case lexertoken.TOKEN_DECIMAL:
+		return rdf.NewTypedLiteral(sign+t.Value, rdf.XSDDecimal), nil
+	case lexertoken.TOKEN_DOUBLE:
+		return rdf.NewTypedLiteral(sign+t.Value, rdf.XSDDouble), nil
+	}
+
+	if sign != "" {
+		return nil, p.unexpected(t, "number")
+	}
+
+	switch t.Type {
+	case lexertoken.TOKEN_BOOLEAN:
+		return rdf.NewTypedLiteral(t.Value, rdf.XSDBoolean), nil
+	case lexertoken.TOKEN_LITERAL:
+	default:
+		return nil, p.unexpected(t, "literal")
+	}
+
+	switch p.peek().Type {
+	case lexertoken.TOKEN_LANGTAG:
+		return rdf.NewLangLiteral(t.Value, p.next().Value), nil
+	case lexertoken.TOKEN_DATATYPE:
+		p.next()
+
+		datatype, err := p.parseIri()
+		if err != nil {
+			return nil, err
+		}
+
+		return rdf.NewTypedLiteral(t.Value, datatype), nil
+	}
+
+	return rdf.NewLiteral(t.Value), nil
+}
+
+// iri	::=	IRIREF | PrefixedName
+func (p *queryParser) parseIri() (rdf.IRI, error) {
+	t := p.next()
+
+	switch t.Type {
+	case lexertoken.TOKEN_IRIREF:
+		return p.resolve(t.Value), nil
+	case lexertoken.TOKEN_PREFIXED_NAME:
+		return p.prefixes.Expand(t.Value)
+	}
+
+	return "", p.unexpected(t, "IRI")
+}
+
+// Expression	::=	ConditionalOrExpression
+func (p *queryParser) parseExpression() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.acceptOperator("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = BinaryExpr{Op: "||", Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+// ConditionalAndExpression	::=	ValueLogical ( '&&' ValueLogical )*
+func (p *queryParser) parseAnd() (Expr, error) {
+	left, err := p.parseRelational()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.acceptOperator("&&") {
+		right, err := p.parseRelational()
+		if err != nil {
+			return nil, err
+		}
+
+		left = BinaryExpr{Op: "&&", Left: left, Right: right}
+	}
+
+	return left, nil
+}
+
+// RelationalExpression	::=	NumericExpression ( '=' NumericExpression | '!=' NumericExpression | '<' NumericExpression | '>' NumericExpression | '<=' NumericExpression | '>=' NumericExpression | 'IN' ExpressionList | 'NOT' 'IN' ExpressionList )?
+func (p *queryParser) parseRelational() (Expr, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	if t := p.peek(); t.Type == lexertoken.TOKEN_OPERATOR {
+		switch t.Value {
+		case "=", "!=", "<", ">", "<=", ">=":
+			p.next()
+
+			right, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+
+			return BinaryExpr{Op: t.Value, Left: left, Right: right}, nil
+		}
+	}
+
+	not := false
+	if p.isKeyword("NOT") && p.peekAt(1).Type == lexertoken.TOKEN_KEYWORD && p.peekAt(1).Value == "IN" {
+		p.next()
+		not = true
+	}
+
+	if p.acceptKeyword("IN") {
+		list, err := p.parseExpressionList()
+		if err != nil {
+			return nil, err
+		}
+
+		return InExpr{Not: not, Arg: left, List: list}, nil
+	}
+
+	return left, nil
+}
+
+// AdditiveExpression	::=	MultiplicativeExpression ( '+' MultiplicativeExpression | '-' MultiplicativeExpression )*
+func (p *queryParser) parseAdditive() (Expr, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := p.peek()
+
+		if t.Type != lexertoken.TOKEN_OPERATOR || t.Value != "+" && t.Value != "-" {
+			return left, nil
+		}
+
+		p.next()
+
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+
+		left = BinaryExpr{Op: t.Value, Left: left, Right: right}
+	}
+}
+
+// MultiplicativeExpression	::=	UnaryExpression ( '*' UnaryExpression | '/' UnaryExpression )*
+func (p *queryParser) parseMultiplicative() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := p.peek()
+
+		if t.Type != lexertoken.TOKEN_OPERATOR || t.Value != "*" && t.Value != "/" {
+			return left, nil
+		}
+
+		p.next()
+
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		left = BinaryExpr{Op: t.Value, Left: left, Right: right}
+	}
+}
+
+// UnaryExpression	::=	'!' PrimaryExpression | '+' PrimaryExpression | '-' PrimaryExpression | PrimaryExpression
+func (p *queryParser) parseUnary() (Expr, error) {
+	if t := p.peek(); t.Type == lexertoken.TOKEN_OPERATOR && (t.Value == "!" || t.Value == "+" || t.Value == "-") {
+		p.next()
+
+		arg, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return UnaryExpr{Op: t.Value, Arg: arg}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+// PrimaryExpression	::=	BrackettedExpression | BuiltInCall | iriOrFunction | RDFLiteral | NumericLiteral | BooleanLiteral | Var
+func (p *queryParser) parsePrimary() (Expr, error) {
+	switch t := p.peek(); t.Type {
+	case lexertoken.TOKEN_START_COLLECTION:
+		return p.parseBrackettedExpression()
+	case lexertoken.TOKEN_VAR:
+		p.next()
+		return TermExpr{Term: rdf.Variable(t.Value)}, nil
+	case lexertoken.TOKEN_LITERAL, lexertoken.TOKEN_INTEGER, lexertoken.TOKEN_DECIMAL,
+		lexertoken.TOKEN_DOUBLE, lexertoken.TOKEN_BOOLEAN:
+		l, err := p.parseLiteral()
+		return TermExpr{Term: l}, err
+	case lexertoken.TOKEN_IRIREF, lexertoken.TOKEN_PREFIXED_NAME:
+		iri, err := p.parseIri()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.peek().Type != lexertoken.TOKEN_START_COLLECTION {
+			return TermExpr{Term: iri}, nil
+		}
+
+		args, err := p.parseArgList()
+
+		return CallExpr{Name: string(iri), Args: args}, err
+	case lexertoken.TOKEN_KEYWORD:
+		return p.parseBuiltInCall()
+	default:
+		return nil, p.unexpected(t, "expression")
+	}
+}
+
+// BrackettedExpression	::=	'(' Expression ')'
+func (p *queryParser) parseBrackettedExpression() (Expr, error) {
+	if _, err := p.expect(lexertoken.TOKEN_START_COLLECTION); err != nil {
+		return nil, err
+	}
+
+	e, err := p.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.expect(lexertoken.TOKEN_END_COLLECTION)
+
+	return e, err
+}
+
+// BuiltInCall, including EXISTS and NOT EXISTS
+func (p *queryParser) parseBuiltInCall() (Expr, error) {
+	t := p.next()
+
+	switch {
+	case t.Value == "EXISTS":
+		pattern, err := p.parseGroupGraphPattern()
+		return &ExistsExpr{Pattern: pattern}, err
+	case t.Value == "NOT" && p.isKeyword("EXISTS"):
+		p.next()
+
+		pattern, err := p.parseGroupGraphPattern()
+		return &ExistsExpr{Not: true, Pattern: pattern}, err
+	case aggregateNames[t.Value]:
+		return p.parseAggregate(t.Value)
+	case !isFunctionName(t.Value) || t.Value == "NOT":
+		return nil, p.unexpected(t, "function name")
+	}
+
+	args, err := p.parseArgList()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkArity(t.Value, len(args)); err != nil {
+		return nil, err
+	}
+
+	return CallExpr{Name: t.Value, Args: args}, nil
+}
+
+// Aggregate	::=	'COUNT' '(' 'DISTINCT'? ( '*' | Expression ) ')' | ... | 'GROUP_CONCAT' '(' 'DISTINCT'? Expression ( ';' 'SEPARATOR' '=' String )? ')'
+func (p *queryParser) parseAggregate(name string) (Expr, error) {
+	if !p.allowAggregates {
+		return nil, fmt.Errorf("sparql: %v can only be used in SELECT, HAVING or ORDER BY", name)
+	}
+
+	if _, err := p.expect(lexertoken.TOKEN_START_COLLECTION); err != nil {
+		return nil, err
+	}
+
+	a := AggregateExpr{Name: name, Distinct: p.acceptKeyword("DISTINCT"), Separator: " "}
+
+	if name != "COUNT" || !p.acceptOperator("*") {
+		// Aggregates don't nest
+		p.allowAggregates = false
+		arg, err := p.parseExpression()
+		p.allowAggregates = true
+
+		if err != nil {
+			return nil, err
+		}
+
+		a.Arg = arg
+	}
+
+	if name == "GROUP_CONCAT" && p.peek().Type == lexertoken.TOKEN_OBJECT_LIST {
+		p.next()
+
+		if err := p.expectKeyword("SEPARATOR"); err != nil {
+			return nil, err
+		}
+
+		if !p.acceptOperator("=") {
+			return nil, p.unexpected(p.peek(), "=")
+		}
+
+		sep, err := p.expect(lexertoken.TOKEN_LITERAL)
+		if err != nil {
+			return nil, err
+		}
+
+		a.Separator = sep.Value
+	}
+
+	_, err := p.expect(lexertoken.TOKEN_END_COLLECTION)
+
+	return a, err
+}
+
+// ArgList	::=	NIL | '(' 'DISTINCT'? Expression ( ',' Expression )* ')'
+func (p *queryParser) parseArgList() ([]Expr, error) {
+	if _, err := p.expect(lexertoken.TOKEN_START_COLLECTION); err != nil {
+		return nil, err
+	}
+
+	var args []Expr
+
+	for p.peek().Type != lexertoken.TOKEN_END_COLLECTION {
+		if len(args) > 0 {
+			if _, err := p.expect(lexertoken.TOKEN_OBJECT); err != nil {
+				return nil, err
+			}
+		}
+
+		e, err := p.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+
+		args = append(args, e)
+	}
+
+	p.next()
+
+	return args, nil
+}
+
+// ExpressionList	::=	NIL | '(' Expression ( ',' Expression )* ')'
+func (p *queryParser) parseExpressionList() ([]Expr, error) {
+	return p.parseArgList()
+}
+
+// join builds Join(a, b), where the empty BGP is the identity
+func join(a, b Pattern) Pattern {
+	if bgp, ok := a.(BGP); ok && len(bgp.Patterns) == 0 {
+		return b
+	}
+
+	if bgp, ok := b.(BGP); ok && len(bgp.Patterns) == 0 {
+		return a
+	}
+
+	// Neighbouring triple blocks are one BGP
+	if x, ok := a.(BGP); ok {
+		if y, ok := b.(BGP); ok {
+			return BGP{Patterns: append(append([]TriplePattern{}, x.Patterns...), y.Patterns...)}
+		}
+	}
+
+	return Join{Left: a, Right: b}
+}
+
+// conjunction collects the filters wrapped around an OPTIONAL into one
+// expression for the left join
+func conjunction(f Filter) Expr {
+	e := f.Expr
+
+	for inner, ok := f.Pattern.(Filter); ok; inner, ok = inner.Pattern.(Filter) {
+		e = BinaryExpr{Op: "&&", Left: inner.Expr, Right: e}
+		f = inner
+	}
+
+	return e
+}
+
+// visibleVars lists the variables a pattern can bind, in the order they
+// first appear, which is the column order for SELECT *
+func visibleVars(p Pattern) []rdf.Variable {
+	var vars []rdf.Variable
+	seen := make(map[rdf.Variable]bool)
+
+	add := func(vs ...rdf.Variable) {
+		for _, v := range vs {
+			if !seen[v] && !isHidden(v) {
+				seen[v] = true
+				vars = append(vars, v)
+			}
+		}
+	}
+
+	var walk func(Pattern)
+	walk = func(p Pattern) {
+		switch x := p.(type) {
+		case BGP:
+			for _, tp := range x.Patterns {
+				add(termVars(tp.Subject)...)
+				add(termVars(tp.Predicate)...)
+				add(termVars(tp.Object)...)
+			}
+		case Join:
+			walk(x.Left)
+			walk(x.Right)
+		case LeftJoin:
+			walk(x.Left)
+			walk(x.Right)
+		case Union:
+			walk(x.Left)
+			walk(x.Right)
+		case Minus:
+			walk(x.Left)
+		case Filter:
+			walk(x.Pattern)
+		case Extend:
+			walk(x.Pattern)
+			add(x.Var)
+		case Graph:
+			add(termVars(x.Name)...)
+			walk(x.Pattern)
+		case Values:
+			add(x.Vars...)
+		case PathPattern:
+			add(termVars(x.Subject)...)
+			add(termVars(x.Object)...)
+		case Group:
+			for _, k := range x.Keys {
+				switch key := k.(type) {
+				case TermExpr:
+					add(termVars(key.Term)...)
+				case groupAs:
+					add(key.Var)
+				}
+			}
+		case OrderBy:
+			walk(x.Pattern)
+		case Project:
+			add(x.Vars...)
+		case Distinct:
+			walk(x.Pattern)
+		case Reduced:
+			walk(x.Pattern)
+		case Slice:
+			walk(x.Pattern)
+		}
+	}
+
+	walk(p)
+
+	return vars
+}
+
+// termVars returns the variables in a term, looking inside quoted triples
+func termVars(t rdf.Term) []rdf.Variable {
+	switch x := t.(type) {
+	case rdf.Variable:
+		return []rdf.Variable{x}
+	case rdf.QuotedTriple:
+		return append(append(termVars(x.Subject), termVars(x.Predicate)...), termVars(x.Object)...)
+	}
+
+	return nil
+}
+
+// newBlankNode mints a hidden variable for an anonymous blank node
+func (p *queryParser) newBlankNode() rdf.Variable {
+	p.blankNodes++
+	return rdf.Variable("_:anon" + strconv.Itoa(p.blankNodes))
+}
+
+func (p *queryParser) resolve(ref string) rdf.IRI {
+	return rdf.ResolveIRI(p.base, ref)
+}
+
+func (p *queryParser) peek() lexertoken.Token {
+	return p.peekAt(0)
+}
+
+func (p *queryParser) peekAt(n int) lexertoken.Token {
+	if p.pos+n >= len(p.lexemes) {
+		return lexertoken.Token{Type: lexertoken.TOKEN_EOF}
+	}
+
+	return p.lexemes[p.pos+n]
+}
+
+func (p *queryParser) next() lexertoken.Token {
+	t := p.peek()
+
+	if p.pos < len(p.lexemes) {
+		p.pos++
+	}
+
+	return t
+}
+
+func (p *queryParser) isKeyword(keyword string) bool {
+	t := p.peek()
+	return t.Type == lexertoken.TOKEN_KEYWORD && t.Value == keyword
+}
+
+func (p *queryParser) acceptKeyword(keyword string) bool {
+	if p.isKeyword(keyword) {
+		p.next()
+		return true
+	}
+
+	return false
+}
+
+func (p *queryParser) expectKeyword(keyword string) error {
+	if !p.acceptKeyword(keyword) {
+		return p.unexpected(p.peek(), keyword)
+	}
+
+	return nil
+}
+
+func (p *queryParser) acceptOperator(op string) bool {
+	if t := p.peek(); t.Type == lexertoken.TOKEN_OPERATOR && t.Value == op {
+		p.next()
+		return true
+	}
+
+	return false
+}
+
+func (p *queryParser) expect(tokenType lexertoken.TokenType) (lexertoken.Token, error) {
+	t := p.next()
+
+	if t.Type != tokenType {
+		return t, p.unexpected(t, lexertoken.TokenMap[tokenType])
+	}
+
+	return t, nil
+}
+
+func (p *queryParser) unexpected(t lexertoken.Token, wanted string) error {
+	if t.Type == lexertoken.TOKEN_EOF {
+		return fmt.Errorf("sparql: expected %v, found end of query", wanted)
+	}
+
+	return fmt.Errorf("sparql: expected %v, found %v %q", wanted, lexertoken.TokenMap[t.Type], strings.TrimSpace(t.Value))
+}
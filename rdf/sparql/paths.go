@@ -0,0 +1,279 @@
+package sparql
+
+import "github.com/b1scuit/solid/rdf"
+
+// A Path is a property path expression, used in place of a predicate
+//
+// https://www.w3.org/TR/sparql11-query/#propertypaths
+type Path interface {
+	path()
+}
+
+// LinkPath is a single predicate
+type LinkPath struct {
+	IRI rdf.IRI
+}
+
+// InversePath is ^path, following the path from object to subject
+type InversePath struct {
+	Path Path
+}
+
+// SequencePath is path1/path2
+type SequencePath struct {
+	Left, Right Path
+}
+
+// AlternativePath is path1|path2
+type AlternativePath struct {
+	Left, Right Path
+}
+
+// ZeroOrOnePath is path?
+type ZeroOrOnePath struct {
+	Path Path
+}
+
+// ZeroOrMorePath is path*
+type ZeroOrMorePath struct {
+	Path Path
+}
+
+// OneOrMorePath is path+
+type OneOrMorePath struct {
+	Path Path
+}
+
+// NegatedPropertySet is !(iri|^iri), any predicate that isn't listed.
+// IRIs are excluded going forward and Inverse going backward
+type NegatedPropertySet struct {
+	IRIs    []rdf.IRI
+	Inverse []rdf.IRI
+}
+
+func (LinkPath) path()           {}
+func (InversePath) path()        {}
+func (SequencePath) path()       {}
+func (AlternativePath) path()    {}
+func (ZeroOrOnePath) path()      {}
+func (ZeroOrMorePath) path()     {}
+func (OneOrMorePath) path()      {}
+func (NegatedPropertySet) path() {}
+
+// PathPattern is a triple pattern with a path for its predicate. Paths
+// that are just predicates, inverses of predicates and sequences of those
+// are turned into plain triple patterns by the parser
+type PathPattern struct {
+	Subject rdf.Term
+	Path    Path
+	Object  rdf.Term
+}
+
+func (PathPattern) pattern() {}
+
+// evalPath extends a solution with every pair of nodes the path connects
+func (e *evaluator) evalPath(pp PathPattern, s Solution) []Solution {
+	if e.graph == nil {
+		return nil
+	}
+
+	subject, object := substitute(pp.Subject, s), substitute(pp.Object, s)
+
+	var out []Solution
+
+	for _, pair := range e.pathPairs(pp.Path, wildcard(subject), wildcard(object)) {
+		m, ok := unify(subject, pair[0], s)
+		if !ok {
+			continue
+		}
+
+		if m, ok = unify(object, pair[1], m); ok {
+			out = append(out, m)
+		}
+	}
+
+	return out
+}
+
+// pathPairs returns the start and end of every route along the path,
+// limited to those starting at s and ending at o when they aren't nil
+func (e *evaluator) pathPairs(p Path, s, o rdf.Term) [][2]rdf.Term {
+	var pairs [][2]rdf.Term
+
+	switch x := p.(type) {
+	case LinkPath:
+		for _, t := range e.graph.Match(s, x.IRI, o) {
+			pairs = append(pairs, [2]rdf.Term{t.Subject, t.Object})
+		}
+	case InversePath:
+		for _, pair := range e.pathPairs(x.Path, o, s) {
+			pairs = append(pairs, [2]rdf.Term{pair[1], pair[0]})
+		}
+	case SequencePath:
+		// Start from whichever end is known
+		if s == nil && o != nil {
+			for _, r := range e.pathPairs(x.Right, nil, o) {
+				for _, l := range e.pathPairs(x.Left, nil, r[0]) {
+					pairs = append(pairs, [2]rdf.Term{l[0], r[1]})
+				}
+			}
+		} else {
+			for _, l := range e.pathPairs(x.Left, s, nil) {
+				for _, r := range e.pathPairs(x.Right, l[1], o) {
+					pairs = append(pairs, [2]rdf.Term{l[0], r[1]})
+				}
+			}
+		}
+	case AlternativePath:
+		pairs = append(e.pathPairs(x.Left, s, o), e.pathPairs(x.Right, s, o)...)
+	case NegatedPropertySet:
+		if len(x.IRIs) > 0 || len(x.Inverse) == 0 {
+			for _, t := range e.graph.Match(s, nil, o) {
+				if !containsIRI(x.IRIs, t.Predicate) {
+					pairs = append(pairs, [2]rdf.Term{t.Subject, t.Object})
+				}
+			}
+		}
+
+		if len(x.Inverse) > 0 {
+			for _, t := range e.graph.Match(o, nil, s) {
+				if !containsIRI(x.Inverse, t.Predicate) {
+					pairs = append(pairs, [2]rdf.Term{t.Object, t.Subject})
+				}
+			}
+		}
+	case ZeroOrOnePath:
+		pairs = distinctPairs(append(e.zeroLength(s, o), e.pathPairs(x.Path, s, o)...))
+	case ZeroOrMorePath:
+		pairs = e.closure(x.Path, s, o, true)
+	case OneOrMorePath:
+		pairs = e.closure(x.Path, s, o, false)
+	}
+
+	return pairs
+}
+
+// zeroLength connects every node to itself. A fixed end is a node even if
+// the graph doesn't mention it
+func (e *evaluator) zeroLength(s, o rdf.Term) [][2]rdf.Term {
+	switch {
+	case s != nil && o != nil:
+		if rdf.Equal(s, o) {
+			return [][2]rdf.Term{{s, s}}
+		}
+
+		return nil
+	case s != nil:
+		return [][2]rdf.Term{{s, s}}
+	case o != nil:
+		return [][2]rdf.Term{{o, o}}
+	}
+
+	var pairs [][2]rdf.Term
+	for _, n := range e.nodes() {
+		pairs = append(pairs, [2]rdf.Term{n, n})
+	}
+
+	return pairs
+}
+
+// closure follows the path any number of times, each node is visited once
+// per start so cycles in the data end the walk
+func (e *evaluator) closure(p Path, s, o rdf.Term, zero bool) [][2]rdf.Term {
+	// Walk backwards from a known end
+	if s == nil && o != nil {
+		var pairs [][2]rdf.Term
+		for _, pair := range e.closure(InversePath{Path: p}, o, nil, zero) {
+			pairs = append(pairs, [2]rdf.Term{pair[1], pair[0]})
+		}
+
+		return pairs
+	}
+
+	starts := []rdf.Term{s}
+	if s == nil {
+		starts = e.nodes()
+	}
+
+	var pairs [][2]rdf.Term
+
+	for _, start := range starts {
+		for _, n := range e.reach(p, start, zero) {
+			if o == nil || rdf.Equal(n, o) {
+				pairs = append(pairs, [2]rdf.Term{start, n})
+			}
+		}
+	}
+
+	return pairs
+}
+
+// reach lists the nodes reachable from start by one or more steps of the
+// path, along with start itself when zero steps count
+func (e *evaluator) reach(p Path, start rdf.Term, zero bool) []rdf.Term {
+	var reached []rdf.Term
+	visited := make(map[string]bool)
+
+	if zero {
+		visited[start.String()] = true
+		reached = append(reached, start)
+	}
+
+	queue := []rdf.Term{start}
+
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+
+		for _, pair := range e.pathPairs(p, n, nil) {
+			if key := pair[1].String(); !visited[key] {
+				visited[key] = true
+				reached = append(reached, pair[1])
+				queue = append(queue, pair[1])
+			}
+		}
+	}
+
+	return reached
+}
+
+// nodes lists every subject and object in the active graph
+func (e *evaluator) nodes() []rdf.Term {
+	var nodes []rdf.Term
+	seen := make(map[string]bool)
+
+	for _, t := range e.graph.Triples() {
+		for _, n := range []rdf.Term{t.Subject, t.Object} {
+			if !seen[n.String()] {
+				seen[n.String()] = true
+				nodes = append(nodes, n)
+			}
+		}
+	}
+
+	return nodes
+}
+
+func distinctPairs(pairs [][2]rdf.Term) [][2]rdf.Term {
+	var out [][2]rdf.Term
+	seen := make(map[string]bool)
+
+	for _, pair := range pairs {
+		if key := pair[0].String() + " " + pair[1].String(); !seen[key] {
+			seen[key] = true
+			out = append(out, pair)
+		}
+	}
+
+	return out
+}
+
+func containsIRI(iris []rdf.IRI, t rdf.Term) bool {
+	for _, iri := range iris {
+		if rdf.Equal(iri, t) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,62 @@
+package sparql
+
+import "github.com/b1scuit/solid/rdf"
+
+type QueryForm int
+
+const (
+	SelectForm QueryForm = iota
+	AskForm
+	ConstructForm
+	DescribeForm
+)
+
+var queryForms = map[QueryForm]string{
+	SelectForm:    "SELECT",
+	AskForm:       "ASK",
+	ConstructForm: "CONSTRUCT",
+	DescribeForm:  "DESCRIBE",
+}
+
+func (f QueryForm) String() string {
+	return queryForms[f]
+}
+
+// A Query is a parsed query with its WHERE clause and solution modifiers
+// already translated into the algebra
+type Query struct {
+	Form     QueryForm
+	Base     string
+	Prefixes rdf.PrefixMap
+
+	// Variables are the projected variables of a SELECT, in order
+	Variables []rdf.Variable
+
+	// Template holds the triples a CONSTRUCT builds for each solution
+	Template []TriplePattern
+
+	// Describe holds the IRIs and variables a DESCRIBE asks about, empty
+	// for DESCRIBE *
+	Describe []rdf.Term
+
+	From      []rdf.IRI
+	FromNamed []rdf.IRI
+
+	Algebra Pattern
+}
+
+// A Result holds the answer to a query, which fields are set depends on
+// the form of the query
+type Result struct {
+	Form QueryForm
+
+	// SELECT
+	Variables []rdf.Variable
+	Solutions []Solution
+
+	// ASK
+	Boolean bool
+
+	// CONSTRUCT and DESCRIBE
+	Graph *rdf.Graph
+}
@@ -0,0 +1,703 @@
+package sparql
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/lexer/lexertoken"
+	"github.com/b1scuit/solid/rdf/serializer"
+	"github.com/olekukonko/tablewriter"
+)
+
+// ErrNotSolutions is returned when writing CONSTRUCT or DESCRIBE results
+// in a format for solutions, write their Graph with the serializer instead
+var ErrNotSolutions = errors.New("sparql: result is a graph, not solutions")
+
+// askColumn is the column CSV and TSV use for an ASK result, neither
+// format defines one so this follows what other stores write
+const askColumn = "_askResult"
+
+const resultsNamespace = "http://www.w3.org/2005/sparql-results#"
+
+// A ResultFormat is a way of writing results and reading them back
+type ResultFormat struct {
+	Name      string
+	MediaType string
+	Write     func(io.Writer, *Result) error
+	Read      func(io.Reader) (*Result, error)
+}
+
+// ResultFormats are the SPARQL 1.1 results formats, in order of preference
+// for content negotiation
+//
+// https://www.w3.org/TR/sparql11-results-json/
+// https://www.w3.org/TR/rdf-sparql-XMLres/
+// https://www.w3.org/TR/sparql11-results-csv-tsv/
+var ResultFormats = []ResultFormat{
+	{"json", "application/sparql-results+json", WriteJSON, ReadJSON},
+	{"xml", "application/sparql-results+xml", WriteXML, ReadXML},
+	{"csv", "text/csv", WriteCSV, ReadCSV},
+	{"tsv", "text/tab-separated-values", WriteTSV, ReadTSV},
+}
+
+// LookupResultFormat finds a format by its name or media type
+func LookupResultFormat(name string) (ResultFormat, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	for _, f := range ResultFormats {
+		if f.Name == name || f.MediaType == name {
+			return f, true
+		}
+	}
+
+	return ResultFormat{}, false
+}
+
+func checkSolutions(r *Result) error {
+	if r.Form == ConstructForm || r.Form == DescribeForm {
+		return ErrNotSolutions
+	}
+
+	return nil
+}
+
+type jsonResults struct {
+	Head    jsonHead      `json:"head"`
+	Results *jsonBindings `json:"results,omitempty"`
+	Boolean *bool         `json:"boolean,omitempty"`
+}
+
+type jsonHead struct {
+	Vars []string `json:"vars,omitempty"`
+}
+
+type jsonBindings struct {
+	Bindings []map[string]*jsonTerm `json:"bindings"`
+}
+
+// jsonTerm is one RDF term, Value is a string except for quoted triples
+// where it is the triple's terms
+type jsonTerm struct {
+	Type     string          `json:"type"`
+	Value    json.RawMessage `json:"value"`
+	Lang     string          `json:"xml:lang,omitempty"`
+	Datatype string          `json:"datatype,omitempty"`
+}
+
+type jsonTriple struct {
+	Subject   *jsonTerm `json:"subject"`
+	Predicate *jsonTerm `json:"predicate"`
+	Object    *jsonTerm `json:"object"`
+}
+
+// WriteJSON writes results in the SPARQL 1.1 Query Results JSON format,
+// quoted triples use the "triple" type from SPARQL-star
+func WriteJSON(w io.Writer, r *Result) error {
+	if err := checkSolutions(r); err != nil {
+		return err
+	}
+
+	var out jsonResults
+
+	if r.Form == AskForm {
+		out.Boolean = &r.Boolean
+	} else {
+		out.Head.Vars = varNames(r.Variables)
+		out.Results = &jsonBindings{Bindings: []map[string]*jsonTerm{}}
+
+		for _, s := range r.Solutions {
+			binding := make(map[string]*jsonTerm)
+
+			for _, v := range r.Variables {
+				if t, ok := s[v]; ok {
+					binding[string(v)] = toJSONTerm(t)
+				}
+			}
+
+			out.Results.Bindings = append(out.Results.Bindings, binding)
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+
+	return enc.Encode(out)
+}
+
+func toJSONTerm(t rdf.Term) *jsonTerm {
+	str := func(s string) json.RawMessage {
+		b, _ := json.Marshal(s)
+		return b
+	}
+
+	switch x := t.(type) {
+	case rdf.IRI:
+		return &jsonTerm{Type: "uri", Value: str(string(x))}
+	case rdf.BlankNode:
+		return &jsonTerm{Type: "bnode", Value: str(string(x))}
+	case rdf.Literal:
+		j := &jsonTerm{Type: "literal", Value: str(x.Lexical), Lang: x.Language}
+
+		if x.Language == "" && x.Datatype != "" && x.Datatype != rdf.XSDString {
+			j.Datatype = string(x.Datatype)
+		}
+
+		return j
+	case rdf.QuotedTriple:
+		b, _ := json.Marshal(jsonTriple{toJSONTerm(x.Subject), toJSONTerm(x.Predicate), toJSONTerm(x.Object)})
+		return &jsonTerm{Type: "triple", Value: b}
+	}
+
+	return nil
+}
+
+// ReadJSON reads results in the SPARQL 1.1 Query Results JSON format
+func ReadJSON(r io.Reader) (*Result, error) {
+	var in jsonResults
+
+	if err := json.NewDecoder(r).Decode(&in); err != nil {
+		return nil, fmt.Errorf("sparql: reading JSON results: %w", err)
+	}
+
+	if in.Boolean != nil {
+		return &Result{Form: AskForm, Boolean: *in.Boolean}, nil
+	}
+
+	res := &Result{Form: SelectForm, Variables: toVariables(in.Head.Vars)}
+
+	if in.Results == nil {
+		return nil, fmt.Errorf("sparql: JSON results have neither results nor boolean")
+	}
+
+	for _, binding := range in.Results.Bindings {
+		s := make(Solution)
+
+		for name, j := range binding {
+			t, err := fromJSONTerm(j)
+			if err != nil {
+				return nil, err
+			}
+
+			s[rdf.Variable(name)] = t
+		}
+
+		res.Solutions = append(res.Solutions, s)
+	}
+
+	return res, nil
+}
+
+func fromJSONTerm(j *jsonTerm) (rdf.Term, error) {
+	if j == nil {
+		return nil, fmt.Errorf("sparql: missing term in JSON results")
+	}
+
+	if j.Type == "triple" {
+		var t jsonTriple
+		if err := json.Unmarshal(j.Value, &t); err != nil {
+			return nil, fmt.Errorf("sparql: reading quoted triple: %w", err)
+		}
+
+		var terms [3]rdf.Term
+		for i, x := range []*jsonTerm{t.Subject, t.Predicate, t.Object} {
+			var err error
+			if terms[i], err = fromJSONTerm(x); err != nil {
+				return nil, err
+			}
+		}
+
+		return rdf.QuotedTriple{Subject: terms[0], Predicate: terms[1], Object: terms[2]}, nil
+	}
+
+	var value string
+	if err := json.Unmarshal(j.Value, &value); err != nil {
+		return nil, fmt.Errorf("sparql: reading %v value: %w", j.Type, err)
+	}
+
+	switch j.Type {
+	case "uri":
+		return rdf.IRI(value), nil
+	case "bnode":
+		return rdf.BlankNode(value), nil
+	case "literal", "typed-literal":
+		switch {
+		case j.Lang != "":
+			return rdf.NewLangLiteral(value, j.Lang), nil
+		case j.Datatype != "":
+			return rdf.NewTypedLiteral(value, rdf.IRI(j.Datatype)), nil
+		}
+
+		return rdf.NewLiteral(value), nil
+	}
+
+	return nil, fmt.Errorf("sparql: unknown term type %q", j.Type)
+}
+
+// WriteXML writes results in the SPARQL Query Results XML format
+func WriteXML(w io.Writer, r *Result) error {
+	if err := checkSolutions(r); err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+
+	bw.WriteString(xml.Header)
+	bw.WriteString(`<sparql xmlns="` + resultsNamespace + `">` + "\n")
+	bw.WriteString("  <head>\n")
+
+	for _, v := range r.Variables {
+		bw.WriteString(`    <variable name="` + escapeXML(string(v)) + `"/>` + "\n")
+	}
+
+	bw.WriteString("  </head>\n")
+
+	if r.Form == AskForm {
+		bw.WriteString("  <boolean>" + strconv.FormatBool(r.Boolean) + "</boolean>\n")
+	} else {
+		bw.WriteString("  <results>\n")
+
+		for _, s := range r.Solutions {
+			bw.WriteString("    <result>\n")
+
+			for _, v := range r.Variables {
+				if t, ok := s[v]; ok {
+					bw.WriteString(`      <binding name="` + escapeXML(string(v)) + `">` + xmlTerm(t) + "</binding>\n")
+				}
+			}
+
+			bw.WriteString("    </result>\n")
+		}
+
+		bw.WriteString("  </results>\n")
+	}
+
+	bw.WriteString("</sparql>\n")
+
+	return bw.Flush()
+}
+
+func xmlTerm(t rdf.Term) string {
+	switch x := t.(type) {
+	case rdf.IRI:
+		return "<uri>" + escapeXML(string(x)) + "</uri>"
+	case rdf.BlankNode:
+		return "<bnode>" + escapeXML(string(x)) + "</bnode>"
+	case rdf.Literal:
+		attrs := ""
+
+		switch {
+		case x.Language != "":
+			attrs = ` xml:lang="` + escapeXML(x.Language) + `"`
+		case x.Datatype != "" && x.Datatype != rdf.XSDString:
+			attrs = ` datatype="` + escapeXML(string(x.Datatype)) + `"`
+		}
+
+		return "<literal" + attrs + ">" + escapeXML(x.Lexical) + "</literal>"
+	case rdf.QuotedTriple:
+		return "<triple><subject>" + xmlTerm(x.Subject) + "</subject><predicate>" + xmlTerm(x.Predicate) +
+			"</predicate><object>" + xmlTerm(x.Object) + "</object></triple>"
+	}
+
+	return ""
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+
+	return b.String()
+}
+
+type xmlResults struct {
+	Variables []struct {
+		Name string `xml:"name,attr"`
+	} `xml:"head>variable"`
+	Boolean *bool `xml:"boolean"`
+	Results *struct {
+		Result []struct {
+			Binding []struct {
+				Name string `xml:"name,attr"`
+				xmlValue
+			} `xml:"binding"`
+		} `xml:"result"`
+	} `xml:"results"`
+}
+
+// xmlValue is whichever one of the term elements is present
+type xmlValue struct {
+	URI     *string `xml:"uri"`
+	BNode   *string `xml:"bnode"`
+	Literal *struct {
+		Value    string `xml:",chardata"`
+		Lang     string `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+		Datatype string `xml:"datatype,attr"`
+	} `xml:"literal"`
+	Triple *struct {
+		Subject   xmlValue `xml:"subject"`
+		Predicate xmlValue `xml:"predicate"`
+		Object    xmlValue `xml:"object"`
+	} `xml:"triple"`
+}
+
+func (x xmlValue) term() (rdf.Term, error) {
+	switch {
+	case x.URI != nil:
+		return rdf.IRI(strings.TrimSpace(*x.URI)), nil
+	case x.BNode != nil:
+		return rdf.BlankNode(strings.TrimSpace(*x.BNode)), nil
+	case x.Literal != nil:
+		switch {
+		case x.Literal.Lang != "":
+			return rdf.NewLangLiteral(x.Literal.Value, x.Literal.Lang), nil
+		case x.Literal.Datatype != "":
+			return rdf.NewTypedLiteral(x.Literal.Value, rdf.IRI(x.Literal.Datatype)), nil
+		}
+
+		return rdf.NewLiteral(x.Literal.Value), nil
+	case x.Triple != nil:
+		var terms [3]rdf.Term
+		for i, v := range []xmlValue{x.Triple.Subject, x.Triple.Predicate, x.Triple.Object} {
+			var err error
+			if terms[i], err = v.term(); err != nil {
+				return nil, err
+			}
+		}
+
+		return rdf.QuotedTriple{Subject: terms[0], Predicate: terms[1], Object: terms[2]}, nil
+	}
+
+	return nil, fmt.Errorf("sparql: binding without a term in XML results")
+}
+
+// ReadXML reads results in the SPARQL Query Results XML format
+func ReadXML(r io.Reader) (*Result, error) {
+	var in xmlResults
+
+	if err := xml.NewDecoder(r).Decode(&in); err != nil {
+		return nil, fmt.Errorf("sparql: reading XML results: %w", err)
+	}
+
+	if in.Boolean != nil {
+		return &Result{Form: AskForm, Boolean: *in.Boolean}, nil
+	}
+
+	if in.Results == nil {
+		return nil, fmt.Errorf("sparql: XML results have neither results nor boolean")
+	}
+
+	res := &Result{Form: SelectForm}
+
+	for _, v := range in.Variables {
+		res.Variables = append(res.Variables, rdf.Variable(v.Name))
+	}
+
+	for _, result := range in.Results.Result {
+		s := make(Solution)
+
+		for _, b := range result.Binding {
+			t, err := b.term()
+			if err != nil {
+				return nil, err
+			}
+
+			s[rdf.Variable(b.Name)] = t
+		}
+
+		res.Solutions = append(res.Solutions, s)
+	}
+
+	return res, nil
+}
+
+// WriteCSV writes results as CSV, which keeps only the lexical form of
+// literals and can't be read back exactly
+func WriteCSV(w io.Writer, r *Result) error {
+	if err := checkSolutions(r); err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	cw.UseCRLF = true
+
+	if r.Form == AskForm {
+		cw.Write([]string{askColumn})
+		cw.Write([]string{strconv.FormatBool(r.Boolean)})
+	} else {
+		cw.Write(varNames(r.Variables))
+
+		for _, s := range r.Solutions {
+			row := make([]string, len(r.Variables))
+
+			for i, v := range r.Variables {
+				row[i] = csvTerm(s[v])
+			}
+
+			cw.Write(row)
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+func csvTerm(t rdf.Term) string {
+	switch x := t.(type) {
+	case rdf.IRI:
+		return string(x)
+	case rdf.BlankNode:
+		return x.String()
+	case rdf.Literal:
+		return x.Lexical
+	case rdf.QuotedTriple:
+		return "<< " + csvTerm(x.Subject) + " " + csvTerm(x.Predicate) + " " + csvTerm(x.Object) + " >>"
+	}
+
+	return ""
+}
+
+// ReadCSV reads CSV results. CSV doesn't say what kind of term a value is
+// so _: values are blank nodes and everything else is a plain literal,
+// empty values are unbound
+func ReadCSV(r io.Reader) (*Result, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("sparql: reading CSV results: %w", err)
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("sparql: CSV results have no header")
+	}
+
+	if ask, ok := askResult(rows[0], rows[1:]); ok {
+		return ask, nil
+	}
+
+	res := &Result{Form: SelectForm, Variables: toVariables(rows[0])}
+
+	for _, row := range rows[1:] {
+		s := make(Solution)
+
+		for i, value := range row {
+			switch {
+			case i >= len(res.Variables), value == "":
+			case strings.HasPrefix(value, "_:"):
+				s[res.Variables[i]] = rdf.BlankNode(value[2:])
+			default:
+				s[res.Variables[i]] = rdf.NewLiteral(value)
+			}
+		}
+
+		res.Solutions = append(res.Solutions, s)
+	}
+
+	return res, nil
+}
+
+// WriteTSV writes results as TSV, where terms are in their SPARQL syntax
+func WriteTSV(w io.Writer, r *Result) error {
+	if err := checkSolutions(r); err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+
+	if r.Form == AskForm {
+		bw.WriteString("?" + askColumn + "\n" + strconv.FormatBool(r.Boolean) + "\n")
+		return bw.Flush()
+	}
+
+	header := make([]string, len(r.Variables))
+	for i, v := range r.Variables {
+		header[i] = v.String()
+	}
+
+	bw.WriteString(strings.Join(header, "\t") + "\n")
+
+	for _, s := range r.Solutions {
+		row := make([]string, len(r.Variables))
+
+		for i, v := range r.Variables {
+			if t, ok := s[v]; ok {
+				row[i] = tsvEscaper.Replace(t.String())
+			}
+		}
+
+		bw.WriteString(strings.Join(row, "\t") + "\n")
+	}
+
+	return bw.Flush()
+}
+
+// N-Triples escapes most of these already, but not inside IRIs
+var tsvEscaper = strings.NewReplacer("\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// ReadTSV reads TSV results, empty values are unbound
+func ReadTSV(r io.Reader) (*Result, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+
+	for _, line := range strings.Split(strings.TrimRight(string(b), "\r\n"), "\n") {
+		rows = append(rows, strings.Split(strings.TrimSuffix(line, "\r"), "\t"))
+	}
+
+	for i, name := range rows[0] {
+		rows[0][i] = strings.TrimPrefix(strings.TrimPrefix(name, "?"), "$")
+	}
+
+	if ask, ok := askResult(rows[0], rows[1:]); ok {
+		return ask, nil
+	}
+
+	res := &Result{Form: SelectForm, Variables: toVariables(rows[0])}
+
+	for _, row := range rows[1:] {
+		s := make(Solution)
+
+		for i, value := range row {
+			if i >= len(res.Variables) || strings.TrimSpace(value) == "" {
+				continue
+			}
+
+			t, err := ParseTerm(value)
+			if err != nil {
+				return nil, err
+			}
+
+			s[res.Variables[i]] = t
+		}
+
+		res.Solutions = append(res.Solutions, s)
+	}
+
+	return res, nil
+}
+
+func askResult(header []string, rows [][]string) (*Result, bool) {
+	if len(header) != 1 || header[0] != askColumn || len(rows) != 1 || len(rows[0]) != 1 {
+		return nil, false
+	}
+
+	b, err := strconv.ParseBool(strings.TrimSpace(rows[0][0]))
+	if err != nil {
+		return nil, false
+	}
+
+	return &Result{Form: AskForm, Boolean: b}, true
+}
+
+// ParseTerm reads a single term written in N-Triples or SPARQL syntax,
+// such as a TSV results value
+func ParseTerm(s string) (rdf.Term, error) {
+	p, err := newQueryParser(s, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var patterns []TriplePattern
+
+	t, err := p.parseNode(&patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	if next := p.peek(); next.Type != lexertoken.TOKEN_EOF || len(patterns) > 0 {
+		return nil, fmt.Errorf("sparql: %q is not a single term", s)
+	}
+
+	var ground func(rdf.Term) (rdf.Term, error)
+	ground = func(t rdf.Term) (rdf.Term, error) {
+		switch x := t.(type) {
+		case rdf.Variable:
+			if !isHidden(x) {
+				return nil, fmt.Errorf("sparql: %q is not a single term", s)
+			}
+
+			return rdf.BlankNode(x[2:]), nil
+		case rdf.QuotedTriple:
+			var err error
+			if x.Subject, err = ground(x.Subject); err != nil {
+				return nil, err
+			}
+
+			if x.Object, err = ground(x.Object); err != nil {
+				return nil, err
+			}
+
+			return x, nil
+		}
+
+		return t, nil
+	}
+
+	return ground(t)
+}
+
+// WriteTable writes results as a table for people to read, with IRIs
+// shortened using prefixes
+func WriteTable(w io.Writer, r *Result, prefixes rdf.PrefixMap) error {
+	if err := checkSolutions(r); err != nil {
+		return err
+	}
+
+	table := tablewriter.NewWriter(w)
+
+	if r.Form == AskForm {
+		table.SetHeader([]string{"Result"})
+		table.Append([]string{strconv.FormatBool(r.Boolean)})
+		table.Render()
+
+		return nil
+	}
+
+	s := serializer.MustNew(serializer.WithPrefixes(prefixes))
+
+	table.SetAutoFormatHeaders(false)
+	table.SetHeader(varNames(r.Variables))
+
+	for _, solution := range r.Solutions {
+		row := make([]string, len(r.Variables))
+
+		for i, v := range r.Variables {
+			if t, ok := solution[v]; ok {
+				row[i] = s.FormatTerm(t)
+			}
+		}
+
+		table.Append(row)
+	}
+
+	table.Render()
+
+	return nil
+}
+
+func varNames(vars []rdf.Variable) []string {
+	names := make([]string, len(vars))
+	for i, v := range vars {
+		names[i] = string(v)
+	}
+
+	return names
+}
+
+func toVariables(names []string) []rdf.Variable {
+	vars := make([]rdf.Variable, len(names))
+	for i, n := range names {
+		vars[i] = rdf.Variable(n)
+	}
+
+	return vars
+}
@@ -0,0 +1,97 @@
+package sparql
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/b1scuit/solid/rdf"
+)
+
+func testResult() *Result {
+	return &Result{
+		Form:      SelectForm,
+		Variables: []rdf.Variable{"s", "o"},
+		Solutions: []Solution{
+			{"s": rdf.IRI("http://example.org/alice"), "o": rdf.NewLangLiteral("Alice \"A\"\ttab", "en")},
+			{"s": rdf.BlankNode("b1"), "o": rdf.NewTypedLiteral("30", rdf.IRI("http://www.w3.org/2001/XMLSchema#integer"))},
+			{"s": rdf.QuotedTriple{Subject: rdf.IRI("http://example.org/a"), Predicate: rdf.IRI("http://example.org/b"), Object: rdf.NewLiteral("c<&>")}},
+		},
+	}
+}
+
+type resultFormatTest struct {
+	Name   string
+	Result *Result
+}
+
+var resultFormatTests = []resultFormatTest{
+	{"Select", testResult()},
+	{"Ask true", &Result{Form: AskForm, Boolean: true}},
+	{"Ask false", &Result{Form: AskForm}},
+	{"No solutions", &Result{Form: SelectForm, Variables: []rdf.Variable{"x"}}},
+}
+
+func TestResultRoundTrip(t *testing.T) {
+	for _, f := range []string{"json", "xml", "tsv"} {
+		format, ok := LookupResultFormat(f)
+		if !ok {
+			t.Fatalf("no %v format", f)
+		}
+
+		for _, tc := range resultFormatTests {
+			var buf bytes.Buffer
+
+			if err := format.Write(&buf, tc.Result); err != nil {
+				t.Errorf("%v %v test fail: writing %v", tc.Name, f, err)
+				continue
+			}
+
+			got, err := format.Read(&buf)
+			if err != nil {
+				t.Errorf("%v %v test fail: reading %v", tc.Name, f, err)
+				continue
+			}
+
+			if got.Form != tc.Result.Form || got.Boolean != tc.Result.Boolean || !sameSolutions(got, tc.Result) {
+				t.Errorf("%v %v test fail: got %v", tc.Name, f, got)
+			}
+		}
+	}
+}
+
+func sameSolutions(a, b *Result) bool {
+	if len(a.Solutions) != len(b.Solutions) || len(a.Variables) != len(b.Variables) {
+		return false
+	}
+
+	for i := range a.Solutions {
+		if a.Solutions[i].key(a.Variables) != b.Solutions[i].key(b.Variables) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestResultCSV(t *testing.T) {
+	var buf bytes.Buffer
+
+	if err := WriteCSV(&buf, testResult()); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "s,o\r\nhttp://example.org/alice,\"Alice \"\"A\"\"\ttab\"\r\n_:b1,30\r\n<< http://example.org/a http://example.org/b c<&> >>,\r\n"
+	if buf.String() != expected {
+		t.Errorf("csv test fail: got %q", buf.String())
+	}
+
+	r, err := ReadCSV(strings.NewReader(buf.String()))
+	if err != nil || len(r.Solutions) != 3 || !rdf.Equal(r.Solutions[1]["s"], rdf.BlankNode("b1")) || !rdf.Equal(r.Solutions[1]["o"], rdf.NewLiteral("30")) {
+		t.Errorf("csv read test fail: %v %v", r, err)
+	}
+
+	if err := WriteCSV(&buf, &Result{Form: ConstructForm}); err != ErrNotSolutions {
+		t.Errorf("csv construct test fail: %v", err)
+	}
+}
@@ -0,0 +1,289 @@
+package sparql
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/parser"
+)
+
+const testData = `
+@prefix ex: <http://example.org/> .
+@prefix foaf: <http://xmlns.com/foaf/0.1/> .
+
+ex:alice a foaf:Person ; foaf:name "Alice" ; foaf:age 30 ; foaf:knows ex:bob, ex:carol .
+ex:bob a foaf:Person ; foaf:name "Bob"@en ; foaf:age 25 ; foaf:knows ex:carol .
+ex:carol a foaf:Person ; foaf:name "Carol" ; foaf:mbox <mailto:carol@example.org> .
+ex:dave foaf:name "Dave" ; foaf:address [ ex:city "Paris" ] .
+`
+
+const testPrologue = "PREFIX ex: <http://example.org/>\nPREFIX foaf: <http://xmlns.com/foaf/0.1/>\n"
+
+func testDataset(t *testing.T) *rdf.Dataset {
+	p := parser.MustNew()
+
+	if err := p.Do(strings.NewReader(testData)); err != nil {
+		t.Fatalf("loading test data: %v", err)
+	}
+
+	ds := rdf.NewDataset(p.GetGraph())
+	ds.CreateGraph(rdf.IRI("http://example.org/g1")).Add(
+		rdf.NewTriple(rdf.IRI("http://example.org/alice"), rdf.IRI("http://example.org/likes"), rdf.IRI("http://example.org/tea")),
+	)
+
+	return ds
+}
+
+type selectTest struct {
+	Name     string
+	Query    string
+	Expected []string
+}
+
+// Each solution is written as the N-Triples form of its projected values
+// joined by spaces, with "-" for unbound
+var selectTests = []selectTest{
+	{"Basic graph pattern", "SELECT ?n WHERE { ?p a foaf:Person ; foaf:name ?n } ORDER BY ?n",
+		[]string{`"Alice"`, `"Bob"@en`, `"Carol"`}},
+	{"Optional", "SELECT ?n ?m WHERE { ?p foaf:name ?n OPTIONAL { ?p foaf:mbox ?m } } ORDER BY ?n",
+		[]string{`"Alice" -`, `"Bob"@en -`, `"Carol" <mailto:carol@example.org>`, `"Dave" -`}},
+	{"Filter", "SELECT ?p WHERE { ?p foaf:age ?a FILTER (?a > 26) }",
+		[]string{`<http://example.org/alice>`}},
+	{"Union", "SELECT ?x WHERE { { ex:alice foaf:knows ?x } UNION { ex:bob foaf:knows ?x } } ORDER BY ?x",
+		[]string{`<http://example.org/bob>`, `<http://example.org/carol>`, `<http://example.org/carol>`}},
+	{"Distinct", "SELECT DISTINCT ?x WHERE { ?y foaf:knows ?x } ORDER BY ?x",
+		[]string{`<http://example.org/bob>`, `<http://example.org/carol>`}},
+	{"Minus", "SELECT ?p WHERE { ?p a foaf:Person MINUS { ?p foaf:knows ex:carol } }",
+		[]string{`<http://example.org/carol>`}},
+	{"Not exists", "SELECT ?p WHERE { ?p a foaf:Person FILTER NOT EXISTS { ?p foaf:age ?a } }",
+		[]string{`<http://example.org/carol>`}},
+	{"Bind and arithmetic", "SELECT ?x WHERE { ex:alice foaf:age ?a BIND (?a / 4 + 1 AS ?x) }",
+		[]string{`"8.5"^^<http://www.w3.org/2001/XMLSchema#decimal>`}},
+	{"Select expression", "SELECT (UCASE(STR(?n)) AS ?u) WHERE { ex:bob foaf:name ?n }",
+		[]string{`"BOB"`}},
+	{"Order descending with limit and offset", "SELECT ?a WHERE { ?p foaf:age ?a } ORDER BY DESC(?a) LIMIT 1 OFFSET 1",
+		[]string{`"25"^^<http://www.w3.org/2001/XMLSchema#integer>`}},
+	{"Values", "SELECT ?p ?n WHERE { VALUES ?p { ex:alice ex:carol } ?p foaf:name ?n } ORDER BY ?n",
+		[]string{`<http://example.org/alice> "Alice"`, `<http://example.org/carol> "Carol"`}},
+	{"Blank node property list", "SELECT ?d WHERE { ?d foaf:address [ ex:city \"Paris\" ] }",
+		[]string{`<http://example.org/dave>`}},
+	{"Graph", "SELECT ?g ?o WHERE { GRAPH ?g { ex:alice ex:likes ?o } }",
+		[]string{`<http://example.org/g1> <http://example.org/tea>`}},
+	{"Regex and language", "SELECT ?n WHERE { ?p foaf:name ?n FILTER (REGEX(?n, \"^b\", \"i\") && LANGMATCHES(LANG(?n), \"en\")) }",
+		[]string{`"Bob"@en`}},
+	{"In", "SELECT ?a WHERE { ?p foaf:age ?a FILTER (?a IN (25, 40)) }",
+		[]string{`"25"^^<http://www.w3.org/2001/XMLSchema#integer>`}},
+	{"Sub select", "SELECT ?n WHERE { { SELECT ?p WHERE { ?p foaf:age ?a } ORDER BY ?a LIMIT 1 } ?p foaf:name ?n }",
+		[]string{`"Bob"@en`}},
+	{"Cast", "SELECT ?x WHERE { BIND (xsd:integer(\"042\") AS ?x) }",
+		[]string{`"42"^^<http://www.w3.org/2001/XMLSchema#integer>`}},
+	{"One or more path", "SELECT ?x WHERE { ex:alice foaf:knows+ ?x } ORDER BY ?x",
+		[]string{`<http://example.org/bob>`, `<http://example.org/carol>`}},
+	{"Zero or more path", "SELECT ?x WHERE { ex:bob foaf:knows* ?x } ORDER BY ?x",
+		[]string{`<http://example.org/bob>`, `<http://example.org/carol>`}},
+	{"Inverse sequence path", "SELECT ?n WHERE { ex:carol ^foaf:knows/foaf:name ?n } ORDER BY ?n",
+		[]string{`"Alice"`, `"Bob"@en`}},
+	{"Alternative path", "SELECT ?o WHERE { ex:carol (foaf:mbox|foaf:age) ?o }",
+		[]string{`<mailto:carol@example.org>`}},
+	{"Negated property set", "SELECT ?o WHERE { ex:carol !(a|foaf:name) ?o }",
+		[]string{`<mailto:carol@example.org>`}},
+	{"Count with group by", "SELECT ?p (COUNT(?x) AS ?n) WHERE { ?p foaf:knows ?x } GROUP BY ?p ORDER BY ?p",
+		[]string{`<http://example.org/alice> "2"^^<http://www.w3.org/2001/XMLSchema#integer>`, `<http://example.org/bob> "1"^^<http://www.w3.org/2001/XMLSchema#integer>`}},
+	{"Sum, average, min and max", "SELECT (SUM(?a) AS ?s) (AVG(?a) AS ?m) (MIN(?a) AS ?lo) (MAX(?a) AS ?hi) WHERE { ?p foaf:age ?a }",
+		[]string{`"55"^^<http://www.w3.org/2001/XMLSchema#integer> "27.5"^^<http://www.w3.org/2001/XMLSchema#decimal> "25"^^<http://www.w3.org/2001/XMLSchema#integer> "30"^^<http://www.w3.org/2001/XMLSchema#integer>`}},
+	{"Having", "SELECT ?p WHERE { ?p foaf:knows ?x } GROUP BY ?p HAVING (COUNT(*) > 1)",
+		[]string{`<http://example.org/alice>`}},
+	{"Count of nothing", "SELECT (COUNT(*) AS ?c) WHERE { ?p foaf:nothing ?x }",
+		[]string{`"0"^^<http://www.w3.org/2001/XMLSchema#integer>`}},
+	{"Projected group key", "SELECT ?n (COUNT(?x) AS ?c) (?c * 2 AS ?d) WHERE { ?p foaf:knows ?x } GROUP BY (STR(?p) AS ?n) HAVING (COUNT(?x) > 1)",
+		[]string{`"http://example.org/alice" "2"^^<http://www.w3.org/2001/XMLSchema#integer> "4"^^<http://www.w3.org/2001/XMLSchema#integer>`}},
+	{"Group concat", "SELECT (GROUP_CONCAT(DISTINCT ?c; SEPARATOR=\"|\") AS ?all) WHERE { ?d foaf:address/ex:city ?c }",
+		[]string{`"Paris"`}},
+}
+
+func TestSelect(t *testing.T) {
+	ds := testDataset(t)
+	c := MustNew(WithPrefixes(rdf.PrefixMap{"xsd": "http://www.w3.org/2001/XMLSchema#"}))
+
+	for _, tc := range selectTests {
+		r, err := c.Query(ds, testPrologue+tc.Query)
+		if err != nil {
+			t.Errorf("%v test fail: %v", tc.Name, err)
+			continue
+		}
+
+		var got []string
+		for _, s := range r.Solutions {
+			var row []string
+
+			for _, v := range r.Variables {
+				if term, ok := s[v]; ok {
+					row = append(row, term.String())
+				} else {
+					row = append(row, "-")
+				}
+			}
+
+			got = append(got, strings.Join(row, " "))
+		}
+
+		if strings.Join(got, "\n") != strings.Join(tc.Expected, "\n") {
+			t.Errorf("%v test fail: got %q", tc.Name, got)
+		}
+	}
+}
+
+func TestPathCycle(t *testing.T) {
+	a, b, c := rdf.IRI("http://example.org/a"), rdf.IRI("http://example.org/b"), rdf.IRI("http://example.org/c")
+	knows := rdf.IRI("http://example.org/knows")
+
+	ds := rdf.NewDataset(rdf.NewGraph(
+		rdf.NewTriple(a, knows, b),
+		rdf.NewTriple(b, knows, c),
+		rdf.NewTriple(c, knows, a),
+	))
+
+	r, err := MustNew().Query(ds, "SELECT ?x WHERE { <http://example.org/a> <http://example.org/knows>+ ?x }")
+	if err != nil || len(r.Solutions) != 3 {
+		t.Errorf("cycle test fail: %v %v", r, err)
+	}
+
+	r, err = MustNew().Query(ds, "SELECT * WHERE { ?x <http://example.org/knows>* ?y }")
+	if err != nil || len(r.Solutions) != 9 {
+		t.Errorf("cycle with both ends open test fail: %v %v", r, err)
+	}
+}
+
+func TestAskConstructDescribe(t *testing.T) {
+	ds := testDataset(t)
+	c := MustNew()
+
+	r, err := c.Query(ds, testPrologue+"ASK { ex:alice foaf:knows ex:bob }")
+	if err != nil || !r.Boolean {
+		t.Errorf("ask test fail: %v %v", r, err)
+	}
+
+	r, err = c.Query(ds, testPrologue+"CONSTRUCT { ?b ex:knownBy ?a ; ex:tag [ ex:by ?a ] } WHERE { ?a foaf:knows ?b }")
+	if err != nil || len(r.Graph.Match(nil, rdf.IRI("http://example.org/knownBy"), nil)) != 3 || len(r.Graph.BlankNodes()) != 3 {
+		t.Errorf("construct test fail: %v", err)
+	}
+
+	r, err = c.Query(ds, testPrologue+"DESCRIBE ex:dave")
+	if err != nil || r.Graph.Len() != 3 {
+		t.Errorf("describe test fail: %v", err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, query := range []string{
+		"SELECT WHERE { ?s ?p ?o }",
+		"SELECT ?s { ?s ?p }",
+		"SELECT ?s WHERE { ?s ?p ?o } LIMIT",
+		"SELECT ?s WHERE { ?s undeclared:p ?o }",
+		"SELECT ?s WHERE { ?s ?p ?o FILTER (STRLEN()) }",
+		"SELECT ?s WHERE { ?s ?p ?o FILTER (COUNT(?o) > 1) }",
+		"CONSTRUCT { ?s ?p+ ?o } WHERE { ?s ?p ?o }",
+		"SELECT ?y WHERE { VALUES ?x { 1 } } GROUP BY ?x",
+		"SELECT ?s (COUNT(?o) AS ?n) WHERE { ?s ?p ?o }",
+		"SELECT (?o + 1 AS ?n) WHERE { ?s ?p ?o } GROUP BY ?s",
+	} {
+		if _, err := MustNew().Parse(query); err == nil {
+			t.Errorf("expected error parsing %q", query)
+		}
+	}
+}
+
+type updateTest struct {
+	Name     string
+	Update   string
+	Query    string
+	Expected bool
+}
+
+// Each update runs against a fresh copy of the test data, then the ASK
+// query checks the result
+var updateTests = []updateTest{
+	{"Insert data", "INSERT DATA { ex:erin foaf:name \"Erin\" }", "ASK { ex:erin foaf:name \"Erin\" }", true},
+	{"Insert data into a graph", "INSERT DATA { GRAPH ex:g2 { ex:erin foaf:name \"Erin\" } }", "ASK { GRAPH ex:g2 { ex:erin foaf:name \"Erin\" } }", true},
+	{"Delete data", "DELETE DATA { ex:alice foaf:knows ex:bob }", "ASK { ex:alice foaf:knows ex:bob }", false},
+	{"Delete where", "DELETE WHERE { ?p foaf:age ?a }", "ASK { ?p foaf:age ?a }", false},
+	{"Delete insert where", "DELETE { ?p foaf:age ?a } INSERT { ?p ex:age ?a } WHERE { ?p foaf:age ?a FILTER (?a > 26) }",
+		"ASK { ex:alice ex:age 30 FILTER NOT EXISTS { ex:alice foaf:age ?a } ex:bob foaf:age 25 }", true},
+	{"With", "WITH ex:g1 DELETE { ?s ex:likes ?o } INSERT { ?s ex:loves ?o } WHERE { ?s ex:likes ?o }",
+		"ASK { GRAPH ex:g1 { ex:alice ex:loves ex:tea FILTER NOT EXISTS { ex:alice ex:likes ex:tea } } }", true},
+	{"Insert blank nodes", "INSERT { ?p ex:tag [ ex:by ?p ] } WHERE { ?p foaf:age ?a }",
+		"ASK { ex:alice ex:tag ?x . ex:bob ex:tag ?y FILTER (isBlank(?x) && ?x != ?y) }", true},
+	{"Clear default", "CLEAR DEFAULT", "ASK { ?s ?p ?o }", false},
+	{"Drop graph", "DROP GRAPH ex:g1", "ASK { GRAPH ?g { ?s ?p ?o } }", false},
+	{"Drop silent", "DROP SILENT GRAPH ex:missing", "ASK { GRAPH ex:g1 { ?s ?p ?o } }", true},
+	{"Copy", "COPY ex:g1 TO DEFAULT", "ASK { ex:alice ex:likes ex:tea FILTER NOT EXISTS { ?s foaf:name ?n } }", true},
+	{"Move", "MOVE GRAPH ex:g1 TO ex:g2", "ASK { GRAPH ex:g2 { ex:alice ex:likes ex:tea } FILTER NOT EXISTS { GRAPH ex:g1 { ?s ?p ?o } } }", true},
+	{"Add", "ADD DEFAULT TO ex:g1", "ASK { GRAPH ex:g1 { ex:alice ex:likes ex:tea . ex:alice foaf:name \"Alice\" } }", true},
+	{"Several operations", "CREATE GRAPH ex:g2 ; INSERT DATA { GRAPH ex:g2 { ex:a ex:b ex:c } } ; CLEAR GRAPH ex:g1",
+		"ASK { GRAPH ex:g2 { ex:a ex:b ex:c } FILTER NOT EXISTS { GRAPH ex:g1 { ?s ?p ?o } } }", true},
+}
+
+func TestUpdate(t *testing.T) {
+	c := MustNew()
+
+	for _, tc := range updateTests {
+		ds := testDataset(t)
+
+		if err := c.Update(ds, testPrologue+tc.Update); err != nil {
+			t.Errorf("%v test fail: %v", tc.Name, err)
+			continue
+		}
+
+		r, err := c.Query(ds, testPrologue+tc.Query)
+		if err != nil || r.Boolean != tc.Expected {
+			t.Errorf("%v test fail: got %v %v", tc.Name, r, err)
+		}
+	}
+}
+
+func TestUpdateAtomic(t *testing.T) {
+	ds := testDataset(t)
+	g1 := ds.Graph(rdf.IRI("http://example.org/g1"))
+
+	err := MustNew().Update(ds, testPrologue+"DROP GRAPH ex:g1 ; CREATE GRAPH ex:g3 ; CREATE GRAPH ex:g3")
+	if !errors.Is(err, ErrGraphExists) || ds.Graph(rdf.IRI("http://example.org/g1")) != g1 || ds.HasGraph(rdf.IRI("http://example.org/g3")) {
+		t.Errorf("failed update test fail: %v", err)
+	}
+
+	if err := MustNew().Update(ds, testPrologue+"INSERT DATA { GRAPH ex:g1 { ex:a ex:b ex:c } }"); err != nil || g1.Len() != 2 {
+		t.Errorf("update in place test fail: %v", err)
+	}
+
+	if err := MustNew().Update(ds, "LOAD <file:///nowhere.ttl>"); !errors.Is(err, ErrNoLoader) {
+		t.Errorf("load without a loader test fail: %v", err)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "data.ttl"), []byte("<#a> <#b> <#c> ."), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := MustNew(WithLoader(FileLoader(dir)))
+	if err := c.Update(ds, "LOAD <file://"+filepath.ToSlash(dir)+"/data.ttl> INTO GRAPH <http://example.org/g4>"); err != nil || ds.Graph(rdf.IRI("http://example.org/g4")).Len() != 1 {
+		t.Errorf("load test fail: %v", err)
+	}
+
+	if err := c.Update(ds, "LOAD <file:///etc/passwd>"); err == nil {
+		t.Errorf("load outside root test fail")
+	}
+
+	for _, update := range []string{
+		"INSERT DATA { ?s ?p ?o }",
+		"DELETE DATA { _:b <http://example.org/p> 1 }",
+		"DELETE { ?s ?p ?o } WHERE",
+		"CLEAR",
+	} {
+		if _, err := MustNew().ParseUpdate(update); err == nil {
+			t.Errorf("expected error parsing %q", update)
+		}
+	}
+}
@@ -0,0 +1,451 @@
+package sparql
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/parser"
+)
+
+var (
+	ErrGraphExists   = errors.New("sparql: graph already exists")
+	ErrGraphNotFound = errors.New("sparql: graph does not exist")
+	ErrNoLoader      = errors.New("sparql: no loader for LOAD")
+)
+
+// An Update is a parsed update request, a sequence of operations that
+// either all apply or none do
+type Update struct {
+	Base       string
+	Prefixes   rdf.PrefixMap
+	Operations []Operation
+}
+
+// An Operation is one part of an update request
+type Operation interface {
+	operation()
+}
+
+// A QuadPattern is a triple pattern in a graph, a nil Graph is the default
+// graph
+type QuadPattern struct {
+	TriplePattern
+	Graph rdf.Term
+}
+
+type InsertData struct {
+	Quads []QuadPattern
+}
+
+type DeleteData struct {
+	Quads []QuadPattern
+}
+
+// Modify is DELETE/INSERT ... WHERE, and DELETE WHERE with the pattern as
+// both the template and the WHERE clause. With names the graph used for
+// anything that doesn't say which graph it is in
+type Modify struct {
+	With       rdf.IRI
+	Delete     []QuadPattern
+	Insert     []QuadPattern
+	Using      []rdf.IRI
+	UsingNamed []rdf.IRI
+	Where      Pattern
+}
+
+// Load reads a document into a graph, Into is nil for the default graph
+type Load struct {
+	Silent bool
+	Source rdf.IRI
+	Into   rdf.Term
+}
+
+type TargetKind int
+
+const (
+	TargetGraph TargetKind = iota
+	TargetDefault
+	TargetNamed
+	TargetAll
+)
+
+// A GraphTarget is the graphs CLEAR and DROP act on, Graph is only set for
+// TargetGraph
+type GraphTarget struct {
+	Kind  TargetKind
+	Graph rdf.IRI
+}
+
+type Clear struct {
+	Silent bool
+	Target GraphTarget
+}
+
+type Drop struct {
+	Silent bool
+	Target GraphTarget
+}
+
+type Create struct {
+	Silent bool
+	Graph  rdf.IRI
+}
+
+// Transfer is ADD, MOVE or COPY from one graph to another, a nil graph is
+// the default graph
+type Transfer struct {
+	Op       string
+	Silent   bool
+	From, To rdf.Term
+}
+
+func (InsertData) operation() {}
+func (DeleteData) operation() {}
+func (Modify) operation()     {}
+func (Load) operation()       {}
+func (Clear) operation()      {}
+func (Drop) operation()       {}
+func (Create) operation()     {}
+func (Transfer) operation()   {}
+
+// A Loader fetches the graph LOAD names
+type Loader func(source rdf.IRI) (*rdf.Graph, error)
+
+// FileLoader loads Turtle and N-Triples from file: IRIs. When root isn't
+// empty only files inside it can be loaded
+func FileLoader(root string) Loader {
+	return func(source rdf.IRI) (*rdf.Graph, error) {
+		u, err := url.Parse(string(source))
+		if err != nil || u.Scheme != "file" {
+			return nil, fmt.Errorf("sparql: can only load file: IRIs, not %v", source)
+		}
+
+		path := filepath.Clean(filepath.FromSlash(u.Path))
+
+		if root != "" {
+			rel, err := filepath.Rel(root, path)
+			if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				return nil, fmt.Errorf("sparql: %v is outside %v", path, root)
+			}
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+
+		defer f.Close()
+
+		p, err := parser.New(parser.WithBase(string(source)))
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.Do(f); err != nil {
+			return nil, err
+		}
+
+		return p.GetGraph(), nil
+	}
+}
+
+// ParseUpdate reads an update request
+func (c *Client) ParseUpdate(update string) (*Update, error) {
+	p, err := newQueryParser(update, c.base, c.prefixes)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.parseUpdate()
+}
+
+// Update parses and applies an update request to a dataset
+func (c *Client) Update(ds *rdf.Dataset, update string) error {
+	u, err := c.ParseUpdate(update)
+	if err != nil {
+		return err
+	}
+
+	return c.ExecUpdate(ds, u)
+}
+
+// ExecUpdate applies the operations in order to a copy of the dataset,
+// which replaces the contents of ds only if they all succeed
+func (c *Client) ExecUpdate(ds *rdf.Dataset, u *Update) error {
+	x := &updater{
+		client: c,
+		ds:     ds.Clone(),
+		base:   u.Base,
+		mint:   newBlankNodeMinter(),
+	}
+
+	for _, q := range ds.Quads() {
+		x.mint.use(q.Subject, q.Object)
+	}
+
+	for _, op := range u.Operations {
+		if err := x.apply(op); err != nil {
+			return err
+		}
+	}
+
+	commit(ds, x.ds)
+
+	return nil
+}
+
+// commit copies the graphs of work into ds, graphs that exist in both are
+// overwritten in place so anything holding on to them sees the change
+func commit(ds, work *rdf.Dataset) {
+	*ds.Default = *work.Default
+
+	for _, n := range ds.Names() {
+		if !work.HasGraph(n) {
+			ds.RemoveGraph(n)
+		}
+	}
+
+	for _, n := range work.Names() {
+		if g := ds.Graph(n); g != nil {
+			*g = *work.Graph(n)
+		} else {
+			ds.SetGraph(n, work.Graph(n))
+		}
+	}
+}
+
+// updater applies operations to the working copy of a dataset
+type updater struct {
+	client *Client
+	ds     *rdf.Dataset
+	base   string
+	mint   *blankNodeMinter
+}
+
+// https://www.w3.org/TR/sparql11-update/#graphUpdate
+func (x *updater) apply(op Operation) error {
+	switch o := op.(type) {
+	case InsertData:
+		bnodes := make(map[rdf.Variable]rdf.BlankNode)
+
+		for _, q := range o.Quads {
+			if t, ok := instantiateTriple(q.TriplePattern, nil, bnodes, x.mint); ok {
+				x.ds.CreateGraph(q.Graph).Add(t)
+			}
+		}
+	case DeleteData:
+		for _, q := range o.Quads {
+			if g := x.ds.Graph(q.Graph); g != nil {
+				g.Remove(rdf.NewTriple(q.Subject, q.Predicate, q.Object))
+			}
+		}
+	case Modify:
+		return x.modify(o)
+	case Load:
+		return x.load(o)
+	case Clear:
+		return x.clear(o.Target, o.Silent, false)
+	case Drop:
+		return x.clear(o.Target, o.Silent, true)
+	case Create:
+		if x.ds.HasGraph(o.Graph) {
+			if o.Silent {
+				return nil
+			}
+
+			return fmt.Errorf("%w: %v", ErrGraphExists, o.Graph)
+		}
+
+		x.ds.CreateGraph(o.Graph)
+	case Transfer:
+		return x.transfer(o)
+	default:
+		return fmt.Errorf("sparql: can't apply %T", op)
+	}
+
+	return nil
+}
+
+// modify works out every triple to delete and insert from the WHERE
+// solutions before changing anything, then deletes before inserting
+func (x *updater) modify(o Modify) error {
+	e := newEvaluator(x.ds, &Query{From: o.Using, FromNamed: o.UsingNamed}, x.base, x.client.functions)
+
+	switch {
+	case len(o.Using) == 0 && len(o.UsingNamed) > 0:
+		e.graph = rdf.NewGraph()
+	case len(o.Using) == 0 && o.With != "":
+		if e.graph = x.ds.Graph(o.With); e.graph == nil {
+			e.graph = rdf.NewGraph()
+		}
+	}
+
+	solutions, err := e.eval(o.Where)
+	if err != nil {
+		return err
+	}
+
+	graph := func(q QuadPattern, s Solution) (rdf.Term, bool) {
+		if q.Graph == nil {
+			if o.With != "" {
+				return o.With, true
+			}
+
+			return nil, true
+		}
+
+		g := substitute(q.Graph, s)
+
+		return g, g.Kind() == rdf.TermIRI
+	}
+
+	var deletes, inserts []rdf.Quad
+
+	for _, s := range solutions {
+		for _, q := range o.Delete {
+			g, ok := graph(q, s)
+			if !ok {
+				continue
+			}
+
+			if t, ok := instantiateTriple(q.TriplePattern, s, nil, nil); ok {
+				deletes = append(deletes, rdf.Quad{Triple: t, Graph: g})
+			}
+		}
+
+		bnodes := make(map[rdf.Variable]rdf.BlankNode)
+
+		for _, q := range o.Insert {
+			g, ok := graph(q, s)
+			if !ok {
+				continue
+			}
+
+			if t, ok := instantiateTriple(q.TriplePattern, s, bnodes, x.mint); ok {
+				inserts = append(inserts, rdf.Quad{Triple: t, Graph: g})
+			}
+		}
+	}
+
+	for _, q := range deletes {
+		if g := x.ds.Graph(q.Graph); g != nil {
+			g.Remove(q.Triple)
+		}
+	}
+
+	for _, q := range inserts {
+		x.ds.CreateGraph(q.Graph).Add(q.Triple)
+	}
+
+	return nil
+}
+
+func (x *updater) load(o Load) error {
+	fail := func(err error) error {
+		if o.Silent {
+			return nil
+		}
+
+		return err
+	}
+
+	if x.client.loader == nil {
+		return fail(ErrNoLoader)
+	}
+
+	g, err := x.client.loader(o.Source)
+	if err != nil {
+		return fail(fmt.Errorf("sparql: loading %v: %w", o.Source, err))
+	}
+
+	x.ds.CreateGraph(o.Into).Add(g.Triples()...)
+
+	return nil
+}
+
+// clear empties the target graphs, drop removes them as well. The default
+// graph is only ever emptied
+func (x *updater) clear(target GraphTarget, silent, drop bool) error {
+	var names []rdf.Term
+	clearDefault := false
+
+	switch target.Kind {
+	case TargetGraph:
+		if !x.ds.HasGraph(target.Graph) {
+			if silent {
+				return nil
+			}
+
+			return fmt.Errorf("%w: %v", ErrGraphNotFound, target.Graph)
+		}
+
+		names = []rdf.Term{target.Graph}
+	case TargetDefault:
+		clearDefault = true
+	case TargetNamed:
+		names = x.ds.Names()
+	case TargetAll:
+		names = x.ds.Names()
+		clearDefault = true
+	}
+
+	if clearDefault {
+		emptyGraph(x.ds.Default)
+	}
+
+	for _, n := range names {
+		if drop {
+			x.ds.RemoveGraph(n)
+		} else {
+			emptyGraph(x.ds.Graph(n))
+		}
+	}
+
+	return nil
+}
+
+func emptyGraph(g *rdf.Graph) {
+	for _, t := range g.Triples() {
+		g.Remove(t)
+	}
+}
+
+// transfer is ADD, COPY and MOVE. COPY and MOVE replace what the target
+// held, MOVE then drops the source
+func (x *updater) transfer(o Transfer) error {
+	if rdf.Equal(o.From, o.To) {
+		return nil
+	}
+
+	src := x.ds.Graph(o.From)
+	if src == nil {
+		if o.Silent {
+			return nil
+		}
+
+		return fmt.Errorf("%w: %v", ErrGraphNotFound, o.From)
+	}
+
+	dst := x.ds.CreateGraph(o.To)
+
+	if o.Op != "ADD" {
+		emptyGraph(dst)
+	}
+
+	dst.Add(src.Triples()...)
+
+	if o.Op == "MOVE" {
+		if o.From == nil {
+			emptyGraph(src)
+		} else {
+			x.ds.RemoveGraph(o.From)
+		}
+	}
+
+	return nil
+}
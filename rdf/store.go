@@ -0,0 +1,246 @@
+package rdf
+
+import (
+	"sort"
+	"sync"
+)
+
+// DefaultGraph names the default graph in a quad pattern, where a nil
+// graph matches every graph. It is the name Jena uses for it
+const DefaultGraph IRI = "urn:x-arq:DefaultGraph"
+
+// A Store keeps quads, in memory like Memory or on disk. Changes are made
+// in transactions so a store is never left with half of one. A single
+// Graph has nowhere to keep the graph of a quad, so graphs are kept as
+// the default graph of a store rather than being stores themselves
+type Store interface {
+	// Match returns the quads matching the pattern ordered by their
+	// N-Quads form. Nil terms match anything, a DefaultGraph graph only
+	// matches the default graph. Quads in the default graph have a nil
+	// Graph
+	Match(s, p, o, g Term) ([]Quad, error)
+
+	Len() (int, error)
+
+	// Update runs fn in a transaction, its changes are kept if it returns
+	// nil and dropped otherwise
+	Update(fn func(tx *Tx) error) error
+
+	Close() error
+}
+
+// A Change adds or removes a quad
+type Change struct {
+	Quad
+	Remove bool
+}
+
+// A Tx collects the changes made in a transaction. Its Match sees the
+// store as it will be once they are applied
+type Tx struct {
+	match   func(s, p, o, g Term) ([]Quad, error)
+	changes []Change
+}
+
+// NewTx starts a transaction over what match reads, for implementing
+// stores
+func NewTx(match func(s, p, o, g Term) ([]Quad, error)) *Tx {
+	return &Tx{match: match}
+}
+
+func (tx *Tx) Add(quads ...Quad) {
+	for _, q := range quads {
+		tx.changes = append(tx.changes, Change{Quad: q.normalise()})
+	}
+}
+
+func (tx *Tx) Remove(quads ...Quad) {
+	for _, q := range quads {
+		tx.changes = append(tx.changes, Change{Quad: q.normalise(), Remove: true})
+	}
+}
+
+// Changes returns the changes in the order they were made
+func (tx *Tx) Changes() []Change {
+	return tx.changes
+}
+
+func (tx *Tx) Match(s, p, o, g Term) ([]Quad, error) {
+	base, err := tx.match(s, p, o, g)
+	if err != nil || len(tx.changes) == 0 {
+		return base, err
+	}
+
+	quads := make(map[string]Quad, len(base))
+	for _, q := range base {
+		quads[q.String()] = q
+	}
+
+	for _, c := range tx.changes {
+		if !c.Matches(s, p, o, g) {
+			continue
+		}
+
+		if c.Remove {
+			delete(quads, c.Quad.String())
+		} else {
+			quads[c.Quad.String()] = c.Quad
+		}
+	}
+
+	return sortQuads(quads), nil
+}
+
+// String returns the quad as an N-Quads line, without the trailing newline
+func (q Quad) String() string {
+	if q.Graph == nil {
+		return q.Triple.String()
+	}
+
+	s := q.Triple.String()
+
+	return s[:len(s)-1] + q.Graph.String() + " ."
+}
+
+// Matches checks the quad against a pattern as Store.Match does
+func (q Quad) Matches(s, p, o, g Term) bool {
+	if g != nil {
+		if Equal(g, DefaultGraph) {
+			if q.Graph != nil {
+				return false
+			}
+		} else if !Equal(g, q.Graph) {
+			return false
+		}
+	}
+
+	return (s == nil || Equal(s, q.Subject)) &&
+		(p == nil || Equal(p, q.Predicate)) &&
+		(o == nil || Equal(o, q.Object))
+}
+
+// normalise writes the default graph as nil however it was named
+func (q Quad) normalise() Quad {
+	if q.Graph != nil && Equal(q.Graph, DefaultGraph) {
+		q.Graph = nil
+	}
+
+	return q
+}
+
+func sortQuads(m map[string]Quad) []Quad {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	quads := make([]Quad, len(keys))
+	for i, k := range keys {
+		quads[i] = m[k]
+	}
+
+	return quads
+}
+
+// Memory is a Store keeping its quads in a Dataset of its own, safe for
+// use from several goroutines
+type Memory struct {
+	mu sync.RWMutex
+	ds *Dataset
+}
+
+var _ Store = (*Memory)(nil)
+
+// NewMemory makes a store holding a copy of d, or nothing if d is nil
+func NewMemory(d *Dataset) *Memory {
+	if d == nil {
+		d = NewDataset(nil)
+	}
+
+	return &Memory{ds: d.Clone()}
+}
+
+// Match is Store.Match over the graphs of the dataset
+func (m *Memory) Match(s, p, o, g Term) ([]Quad, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.ds.match(s, p, o, g), nil
+}
+
+func (d *Dataset) match(s, p, o, g Term) []Quad {
+	var names []Term
+
+	switch {
+	case g == nil:
+		names = append([]Term{nil}, d.Names()...)
+	case Equal(g, DefaultGraph):
+		names = []Term{nil}
+	default:
+		names = []Term{g}
+	}
+
+	quads := make(map[string]Quad)
+
+	for _, n := range names {
+		graph := d.Graph(n)
+		if graph == nil {
+			continue
+		}
+
+		for _, t := range graph.Match(s, p, o) {
+			q := Quad{Triple: t, Graph: n}
+			quads[q.String()] = q
+		}
+	}
+
+	return sortQuads(quads)
+}
+
+// Len counts the quads in every graph of the dataset
+func (m *Memory) Len() (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	n := m.ds.Default.Len()
+	for _, g := range m.ds.named {
+		n += g.Len()
+	}
+
+	return n, nil
+}
+
+// Update is Store.Update, the changes are applied once fn returns nil
+func (m *Memory) Update(fn func(tx *Tx) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tx := NewTx(func(s, p, o, g Term) ([]Quad, error) {
+		return m.ds.match(s, p, o, g), nil
+	})
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	for _, c := range tx.Changes() {
+		if c.Remove {
+			if graph := m.ds.Graph(c.Graph); graph != nil {
+				graph.Remove(c.Triple)
+			}
+
+			continue
+		}
+
+		m.ds.CreateGraph(c.Graph).Add(c.Triple)
+	}
+
+	return nil
+}
+
+// Close does nothing, a memory store has nothing to release
+func (m *Memory) Close() error {
+	return nil
+}
@@ -0,0 +1,266 @@
+package store
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"github.com/b1scuit/solid/rdf"
+)
+
+// The file starts with the magic, then holds records one after another.
+// A record is its type, the length of its payload as a uvarint, the
+// payload and a CRC-32 of the type and payload. Term records add to the
+// dictionary, each term's ID being one more than the last. Batch records
+// are the changes of one transaction, so a transaction is only in the file
+// once its batch record is whole
+const magic = "SOLIDQS\x01"
+
+const (
+	recordTerm  byte = 't'
+	recordBatch byte = 'b'
+)
+
+// Term kinds as written in term records
+const (
+	termIRI byte = iota + 1
+	termBlankNode
+	termLiteral
+	termTriple
+)
+
+const (
+	opAdd byte = iota
+	opRemove
+)
+
+// errTorn is a record cut short or with a bad checksum, what is left of a
+// write interrupted by a crash
+var errTorn = errors.New("store: torn record")
+
+func appendRecord(buf []byte, kind byte, payload []byte) []byte {
+	start := len(buf)
+
+	buf = append(buf, kind)
+	buf = binary.AppendUvarint(buf, uint64(len(payload)))
+	buf = append(buf, payload...)
+
+	sum := crc32.NewIEEE()
+	sum.Write(buf[start : start+1])
+	sum.Write(payload)
+
+	return binary.LittleEndian.AppendUint32(buf, sum.Sum32())
+}
+
+// readRecord reads the next record, returning io.EOF at a clean end and
+// errTorn for a partial or corrupt one. n is how many bytes it took, and
+// remaining how many are left in the file, which a payload can't be
+// longer than
+func readRecord(r *bufio.Reader, remaining int64) (kind byte, payload []byte, n int, err error) {
+	kind, err = r.ReadByte()
+	if err != nil {
+		return 0, nil, 0, err
+	}
+
+	counter := &countingReader{r: r}
+
+	size, err := binary.ReadUvarint(counter)
+	if err != nil || size > uint64(remaining) {
+		return 0, nil, 0, errTorn
+	}
+
+	payload = make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, 0, errTorn
+	}
+
+	var crc [4]byte
+	if _, err := io.ReadFull(r, crc[:]); err != nil {
+		return 0, nil, 0, errTorn
+	}
+
+	sum := crc32.NewIEEE()
+	sum.Write([]byte{kind})
+	sum.Write(payload)
+
+	if sum.Sum32() != binary.LittleEndian.Uint32(crc[:]) {
+		return 0, nil, 0, errTorn
+	}
+
+	return kind, payload, 1 + counter.n + len(payload) + len(crc), nil
+}
+
+type countingReader struct {
+	r *bufio.Reader
+	n int
+}
+
+func (c *countingReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.n++
+	}
+
+	return b, err
+}
+
+// encodeTerm writes a term, quoted triples by the IDs of their terms
+// which have to be in the dictionary already
+func encodeTerm(t rdf.Term, id func(rdf.Term) uint64) ([]byte, error) {
+	var buf []byte
+
+	switch v := t.(type) {
+	case rdf.IRI:
+		buf = appendString(append(buf, termIRI), string(v))
+	case rdf.BlankNode:
+		buf = appendString(append(buf, termBlankNode), string(v))
+	case rdf.Literal:
+		buf = appendString(append(buf, termLiteral), v.Lexical)
+		buf = appendString(buf, string(v.Datatype))
+		buf = appendString(buf, v.Language)
+	case rdf.QuotedTriple:
+		buf = append(buf, termTriple)
+		buf = binary.AppendUvarint(buf, id(v.Subject))
+		buf = binary.AppendUvarint(buf, id(v.Predicate))
+		buf = binary.AppendUvarint(buf, id(v.Object))
+	default:
+		return nil, fmt.Errorf("store: can't store %v", t)
+	}
+
+	return buf, nil
+}
+
+func decodeTerm(payload []byte, term func(uint64) (rdf.Term, bool)) (rdf.Term, error) {
+	d := decoder{buf: payload}
+
+	switch kind := d.byte(); kind {
+	case termIRI:
+		return rdf.IRI(d.string()), d.err
+	case termBlankNode:
+		return rdf.BlankNode(d.string()), d.err
+	case termLiteral:
+		l := rdf.Literal{Lexical: d.string(), Datatype: rdf.IRI(d.string()), Language: d.string()}
+		return l, d.err
+	case termTriple:
+		var terms [3]rdf.Term
+		for i := range terms {
+			t, ok := term(d.uvarint())
+			if !ok {
+				return nil, fmt.Errorf("store: quoted triple refers to an unknown term")
+			}
+
+			terms[i] = t
+		}
+
+		return rdf.QuotedTriple{Subject: terms[0], Predicate: terms[1], Object: terms[2]}, d.err
+	default:
+		return nil, fmt.Errorf("store: unknown term kind %v", kind)
+	}
+}
+
+type op struct {
+	remove bool
+	key    key
+}
+
+func encodeBatch(ops []op) []byte {
+	buf := binary.AppendUvarint(nil, uint64(len(ops)))
+
+	for _, o := range ops {
+		kind := opAdd
+		if o.remove {
+			kind = opRemove
+		}
+
+		buf = append(buf, kind)
+		for _, id := range o.key {
+			buf = binary.AppendUvarint(buf, id)
+		}
+	}
+
+	return buf
+}
+
+func decodeBatch(payload []byte) ([]op, error) {
+	d := decoder{buf: payload}
+
+	n := d.uvarint()
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	var ops []op
+
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		o := op{remove: d.byte() == opRemove}
+		for j := range o.key {
+			o.key[j] = d.uvarint()
+		}
+
+		ops = append(ops, o)
+	}
+
+	return ops, d.err
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// A decoder reads a payload, remembering the first error so it only has
+// to be checked at the end
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) byte() byte {
+	if d.err != nil || len(d.buf) == 0 {
+		d.fail()
+		return 0
+	}
+
+	b := d.buf[0]
+	d.buf = d.buf[1:]
+
+	return b
+}
+
+func (d *decoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+
+	d.buf = d.buf[n:]
+
+	return v
+}
+
+func (d *decoder) string() string {
+	n := d.uvarint()
+	if d.err != nil || uint64(len(d.buf)) < n {
+		d.fail()
+		return ""
+	}
+
+	s := string(d.buf[:n])
+	d.buf = d.buf[n:]
+
+	return s
+}
+
+func (d *decoder) fail() {
+	if d.err == nil {
+		d.err = errors.New("store: malformed record")
+	}
+}
@@ -0,0 +1,161 @@
+package store
+
+// A key is a quad as the IDs of its subject, predicate, object and graph,
+// graph 0 being the default graph
+type key [4]uint64
+
+const (
+	posSubject = iota
+	posPredicate
+	posObject
+	posGraph
+)
+
+// An index keeps the quads in one order of their positions, nested so that
+// a pattern binding a prefix of the order only visits what it matches
+type index struct {
+	order [4]int
+	tree  map[uint64]map[uint64]map[uint64]map[uint64]struct{}
+}
+
+// The permutations kept, between them every pattern has an index whose
+// order starts with at least one of its bound positions
+func newIndexes() []*index {
+	var indexes []*index
+
+	for _, order := range [][4]int{
+		{posSubject, posPredicate, posObject, posGraph},
+		{posPredicate, posObject, posSubject, posGraph},
+		{posObject, posSubject, posPredicate, posGraph},
+		{posGraph, posSubject, posPredicate, posObject},
+	} {
+		indexes = append(indexes, &index{order: order, tree: make(map[uint64]map[uint64]map[uint64]map[uint64]struct{})})
+	}
+
+	return indexes
+}
+
+func (x *index) add(k key) {
+	a, b, c, d := k[x.order[0]], k[x.order[1]], k[x.order[2]], k[x.order[3]]
+
+	l1, ok := x.tree[a]
+	if !ok {
+		l1 = make(map[uint64]map[uint64]map[uint64]struct{})
+		x.tree[a] = l1
+	}
+
+	l2, ok := l1[b]
+	if !ok {
+		l2 = make(map[uint64]map[uint64]struct{})
+		l1[b] = l2
+	}
+
+	l3, ok := l2[c]
+	if !ok {
+		l3 = make(map[uint64]struct{})
+		l2[c] = l3
+	}
+
+	l3[d] = struct{}{}
+}
+
+// remove deletes the key, dropping the maps it leaves empty
+func (x *index) remove(k key) {
+	a, b, c, d := k[x.order[0]], k[x.order[1]], k[x.order[2]], k[x.order[3]]
+
+	l1 := x.tree[a]
+	l2 := l1[b]
+	l3 := l2[c]
+
+	delete(l3, d)
+
+	if len(l3) == 0 {
+		delete(l2, c)
+	}
+
+	if len(l2) == 0 {
+		delete(l1, b)
+	}
+
+	if len(l1) == 0 {
+		delete(x.tree, a)
+	}
+}
+
+func (x *index) has(k key) bool {
+	_, ok := x.tree[k[x.order[0]]][k[x.order[1]]][k[x.order[2]]][k[x.order[3]]]
+	return ok
+}
+
+// prefix counts how many positions at the start of the order are bound
+func (x *index) prefix(bound [4]bool) int {
+	n := 0
+	for n < 4 && bound[x.order[n]] {
+		n++
+	}
+
+	return n
+}
+
+// scan calls fn with every key matching the pattern, positions that
+// aren't bound match anything
+func (x *index) scan(pattern key, bound [4]bool, fn func(key)) {
+	var k key
+
+	// Each level either looks up its bound ID or walks every child
+	level := func(i int, keys func() []uint64, visit func(uint64)) {
+		if bound[x.order[i]] {
+			visit(pattern[x.order[i]])
+			return
+		}
+
+		for _, id := range keys() {
+			visit(id)
+		}
+	}
+
+	level(0, func() []uint64 { return mapKeys(x.tree) }, func(a uint64) {
+		l1, ok := x.tree[a]
+		if !ok {
+			return
+		}
+
+		k[x.order[0]] = a
+
+		level(1, func() []uint64 { return mapKeys(l1) }, func(b uint64) {
+			l2, ok := l1[b]
+			if !ok {
+				return
+			}
+
+			k[x.order[1]] = b
+
+			level(2, func() []uint64 { return mapKeys(l2) }, func(c uint64) {
+				l3, ok := l2[c]
+				if !ok {
+					return
+				}
+
+				k[x.order[2]] = c
+
+				level(3, func() []uint64 { return mapKeys(l3) }, func(d uint64) {
+					if _, ok := l3[d]; !ok {
+						return
+					}
+
+					k[x.order[3]] = d
+					fn(k)
+				})
+			})
+		})
+	})
+}
+
+func mapKeys[V any](m map[uint64]V) []uint64 {
+	keys := make([]uint64, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
@@ -0,0 +1,542 @@
+// Package store keeps quads on disk, so a dataset survives restarts
+// without being parsed again
+//
+// Terms are encoded to integer IDs through a dictionary and quads are
+// indexed by those IDs in SPOG, POSG, OSPG and GSPO order. The file is an
+// append-only log of dictionary entries and transaction batches, each
+// record checksummed, so after a crash the log is read up to the last
+// whole transaction and the rest dropped. Compact rewrites the log with
+// just what is live
+package store
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/b1scuit/solid/rdf"
+)
+
+var ErrClosed = errors.New("store: closed")
+
+type StoreOption func(*Disk)
+
+// WithoutSync doesn't wait for each transaction to reach the disk before
+// Update returns. Loading is much faster, but a crash can lose the latest
+// transactions, never part of one
+func WithoutSync() StoreOption {
+	return func(d *Disk) {
+		d.sync = false
+	}
+}
+
+// A Disk is a store backed by a log file, which it keeps open. The quads
+// and dictionary are held in memory as IDs
+type Disk struct {
+	mu   sync.RWMutex
+	path string
+	f    *os.File
+	sync bool
+
+	// terms[id-1] is the term with the ID
+	terms []rdf.Term
+	ids   map[string]uint64
+
+	indexes []*index
+	n       int
+}
+
+var _ rdf.Store = (*Disk)(nil)
+
+// Open reads the store at path, creating it if it doesn't exist. A
+// transaction a crash left half written is dropped from the end of the
+// file
+func Open(path string, opts ...StoreOption) (*Disk, error) {
+	d := &Disk{path: path, sync: true}
+
+	for _, f := range opts {
+		f(d)
+	}
+
+	if err := d.load(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func MustOpen(path string, opts ...StoreOption) *Disk {
+	d, err := Open(path, opts...)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+func (d *Disk) load() error {
+	f, err := os.OpenFile(d.path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+
+	d.f = f
+	d.terms = nil
+	d.ids = make(map[string]uint64)
+	d.indexes = newIndexes()
+	d.n = 0
+
+	if err := d.replay(); err != nil {
+		f.Close()
+		return err
+	}
+
+	return nil
+}
+
+// replay reads the log into memory, truncating it after the last whole
+// batch. Terms after that batch are kept, they are only unused
+func (d *Disk) replay() error {
+	info, err := d.f.Stat()
+	if err != nil {
+		return err
+	}
+
+	size := info.Size()
+
+	if size == 0 {
+		if _, err := d.f.Write([]byte(magic)); err != nil {
+			return err
+		}
+
+		return d.syncFile()
+	}
+
+	r := bufio.NewReader(d.f)
+
+	header := make([]byte, len(magic))
+	if _, err := io.ReadFull(r, header); err != nil || string(header) != magic {
+		return fmt.Errorf("store: %v isn't a quad store", d.path)
+	}
+
+	offset := int64(len(magic))
+
+	for {
+		kind, payload, n, err := readRecord(r, size-offset)
+		if err == io.EOF {
+			break
+		}
+
+		if errors.Is(err, errTorn) {
+			slog.Warn("Dropping incomplete transaction", slog.String("store", d.path), slog.Int64("offset", offset), slog.Int64("size", size))
+
+			if err := d.f.Truncate(offset); err != nil {
+				return err
+			}
+
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		switch kind {
+		case recordTerm:
+			t, err := decodeTerm(payload, d.term)
+			if err != nil {
+				return err
+			}
+
+			d.intern(t)
+		case recordBatch:
+			ops, err := decodeBatch(payload)
+			if err != nil {
+				return err
+			}
+
+			d.apply(ops)
+		default:
+			return fmt.Errorf("store: unknown record type %q", kind)
+		}
+
+		offset += int64(n)
+	}
+
+	_, err = d.f.Seek(offset, io.SeekStart)
+
+	return err
+}
+
+func (d *Disk) term(id uint64) (rdf.Term, bool) {
+	if id == 0 || id > uint64(len(d.terms)) {
+		return nil, false
+	}
+
+	return d.terms[id-1], true
+}
+
+func (d *Disk) intern(t rdf.Term) uint64 {
+	d.terms = append(d.terms, t)
+	id := uint64(len(d.terms))
+	d.ids[t.String()] = id
+
+	return id
+}
+
+func (d *Disk) apply(ops []op) {
+	for _, o := range ops {
+		has := d.indexes[0].has(o.key)
+
+		switch {
+		case o.remove && has:
+			for _, x := range d.indexes {
+				x.remove(o.key)
+			}
+
+			d.n--
+		case !o.remove && !has:
+			for _, x := range d.indexes {
+				x.add(o.key)
+			}
+
+			d.n++
+		}
+	}
+}
+
+// Match is rdf.Store.Match over the quads in the store
+func (d *Disk) Match(s, p, o, g rdf.Term) ([]rdf.Quad, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.f == nil {
+		return nil, ErrClosed
+	}
+
+	return d.match(s, p, o, g), nil
+}
+
+func (d *Disk) match(s, p, o, g rdf.Term) []rdf.Quad {
+	var pattern key
+	var bound [4]bool
+
+	for i, t := range []rdf.Term{s, p, o, g} {
+		if t == nil {
+			continue
+		}
+
+		bound[i] = true
+
+		if i == posGraph && rdf.Equal(t, rdf.DefaultGraph) {
+			continue
+		}
+
+		id, ok := d.ids[t.String()]
+		if !ok {
+			return nil
+		}
+
+		pattern[i] = id
+	}
+
+	// The index with the longest bound prefix visits the fewest quads
+	best := d.indexes[0]
+	for _, x := range d.indexes[1:] {
+		if x.prefix(bound) > best.prefix(bound) {
+			best = x
+		}
+	}
+
+	var quads []rdf.Quad
+
+	best.scan(pattern, bound, func(k key) {
+		q := rdf.Quad{Triple: rdf.NewTriple(d.terms[k[posSubject]-1], d.terms[k[posPredicate]-1], d.terms[k[posObject]-1])}
+		if k[posGraph] != 0 {
+			q.Graph = d.terms[k[posGraph]-1]
+		}
+
+		quads = append(quads, q)
+	})
+
+	sort.Slice(quads, func(i, j int) bool { return quads[i].String() < quads[j].String() })
+
+	return quads
+}
+
+func (d *Disk) Len() (int, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.f == nil {
+		return 0, ErrClosed
+	}
+
+	return d.n, nil
+}
+
+// Update is rdf.Store.Update. The transaction is written as one batch,
+// after the dictionary entries for its new terms, and the store only
+// changes in memory once the write has succeeded
+func (d *Disk) Update(fn func(tx *rdf.Tx) error) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.f == nil {
+		return ErrClosed
+	}
+
+	tx := rdf.NewTx(func(s, p, o, g rdf.Term) ([]rdf.Quad, error) {
+		return d.match(s, p, o, g), nil
+	})
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if len(tx.Changes()) == 0 {
+		return nil
+	}
+
+	var buf []byte
+	var ops []op
+
+	known := len(d.terms)
+
+	// New terms are interned as they are written, and dropped again if
+	// the write fails
+	rollback := func() {
+		for _, t := range d.terms[known:] {
+			delete(d.ids, t.String())
+		}
+
+		d.terms = d.terms[:known]
+	}
+
+	var idOf func(t rdf.Term) (uint64, error)
+	idOf = func(t rdf.Term) (uint64, error) {
+		if id, ok := d.ids[t.String()]; ok {
+			return id, nil
+		}
+
+		if q, ok := t.(rdf.QuotedTriple); ok {
+			for _, part := range []rdf.Term{q.Subject, q.Predicate, q.Object} {
+				if _, err := idOf(part); err != nil {
+					return 0, err
+				}
+			}
+		}
+
+		payload, err := encodeTerm(t, func(t rdf.Term) uint64 { return d.ids[t.String()] })
+		if err != nil {
+			return 0, err
+		}
+
+		buf = appendRecord(buf, recordTerm, payload)
+
+		return d.intern(t), nil
+	}
+
+	for _, c := range tx.Changes() {
+		var k key
+		missing := false
+
+		for i, t := range []rdf.Term{c.Subject, c.Predicate, c.Object, c.Graph} {
+			if t == nil && i == posGraph {
+				continue
+			}
+
+			if t == nil {
+				rollback()
+				return fmt.Errorf("store: %v is missing a term", c.Quad)
+			}
+
+			if c.Remove {
+				// A quad with a term the store hasn't seen can't be in it
+				id, ok := d.ids[t.String()]
+				if !ok {
+					missing = true
+					break
+				}
+
+				k[i] = id
+
+				continue
+			}
+
+			id, err := idOf(t)
+			if err != nil {
+				rollback()
+				return err
+			}
+
+			k[i] = id
+		}
+
+		if !missing {
+			ops = append(ops, op{remove: c.Remove, key: k})
+		}
+	}
+
+	buf = appendRecord(buf, recordBatch, encodeBatch(ops))
+
+	if err := d.write(buf); err != nil {
+		rollback()
+		return err
+	}
+
+	d.apply(ops)
+
+	return nil
+}
+
+// write appends to the log, cutting it back to where it was if the write
+// or the sync fails. Term IDs follow the order of the records, so a term
+// record left in the log after the terms in memory are rolled back would
+// give the next terms the wrong IDs when it is replayed
+func (d *Disk) write(buf []byte) error {
+	offset, err := d.f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+
+	_, err = d.f.Write(buf)
+	if err == nil {
+		err = d.syncFile()
+	}
+
+	if err != nil {
+		d.f.Truncate(offset)
+		d.f.Seek(offset, io.SeekStart)
+
+		return err
+	}
+
+	return nil
+}
+
+func (d *Disk) syncFile() error {
+	if !d.sync {
+		return nil
+	}
+
+	return d.f.Sync()
+}
+
+// Compact rewrites the log with only the quads in the store and the terms
+// they use, dropping removed quads and unused terms. The new log replaces
+// the old one in a single rename, so a crash leaves one or the other
+func (d *Disk) Compact() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.f == nil {
+		return ErrClosed
+	}
+
+	tmp := d.path + ".compact"
+
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp)
+
+	buf := []byte(magic)
+
+	ids := make(map[uint64]uint64)
+
+	var renumber func(id uint64) uint64
+	renumber = func(id uint64) uint64 {
+		if id == 0 {
+			return 0
+		}
+
+		if n, ok := ids[id]; ok {
+			return n
+		}
+
+		t := d.terms[id-1]
+
+		if q, ok := t.(rdf.QuotedTriple); ok {
+			for _, part := range []rdf.Term{q.Subject, q.Predicate, q.Object} {
+				renumber(d.ids[part.String()])
+			}
+		}
+
+		payload, _ := encodeTerm(t, func(t rdf.Term) uint64 { return ids[d.ids[t.String()]] })
+		buf = appendRecord(buf, recordTerm, payload)
+
+		ids[id] = uint64(len(ids) + 1)
+
+		return ids[id]
+	}
+
+	var ops []op
+
+	d.indexes[0].scan(key{}, [4]bool{}, func(k key) {
+		var n key
+		for i, id := range k {
+			n[i] = renumber(id)
+		}
+
+		ops = append(ops, op{key: n})
+	})
+
+	buf = appendRecord(buf, recordBatch, encodeBatch(ops))
+
+	if _, err := f.Write(buf); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp, d.path); err != nil {
+		return err
+	}
+
+	syncDir(filepath.Dir(d.path))
+
+	d.f.Close()
+
+	return d.load()
+}
+
+// syncDir makes a rename in the directory durable, where the platform
+// allows it
+func syncDir(dir string) {
+	if f, err := os.Open(dir); err == nil {
+		f.Sync()
+		f.Close()
+	}
+}
+
+func (d *Disk) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.f == nil {
+		return ErrClosed
+	}
+
+	err := d.f.Close()
+	d.f = nil
+
+	return err
+}
@@ -0,0 +1,263 @@
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/b1scuit/solid/rdf"
+)
+
+var (
+	ex    = func(s string) rdf.IRI { return rdf.IRI("http://example.org/" + s) }
+	graph = ex("graph")
+
+	testQuads = []rdf.Quad{
+		{Triple: rdf.NewTriple(ex("alice"), ex("knows"), ex("bob"))},
+		{Triple: rdf.NewTriple(ex("alice"), ex("name"), rdf.NewLangLiteral("Alice", "en"))},
+		{Triple: rdf.NewTriple(ex("bob"), ex("knows"), ex("alice")), Graph: graph},
+		{Triple: rdf.NewTriple(rdf.BlankNode("b1"), ex("age"), rdf.NewTypedLiteral("30", rdf.XSDInteger)), Graph: graph},
+		{Triple: rdf.NewTriple(rdf.Quote(rdf.NewTriple(ex("alice"), ex("knows"), ex("bob"))), ex("since"), rdf.NewTypedLiteral("2020", rdf.XSDInteger))},
+	}
+)
+
+type matchTest struct {
+	Name       string
+	S, P, O, G rdf.Term
+	Expected   int
+}
+
+var matchTests = []matchTest{
+	{"Everything", nil, nil, nil, nil, 5},
+	{"Subject", ex("alice"), nil, nil, nil, 2},
+	{"Predicate in any graph", nil, ex("knows"), nil, nil, 2},
+	{"Predicate in the default graph", nil, ex("knows"), nil, rdf.DefaultGraph, 1},
+	{"Object and graph", nil, nil, ex("alice"), graph, 1},
+	{"Named graph", nil, nil, nil, graph, 2},
+	{"Literal object", nil, nil, rdf.NewTypedLiteral("30", rdf.XSDInteger), nil, 1},
+	{"Quoted triple subject", rdf.Quote(rdf.NewTriple(ex("alice"), ex("knows"), ex("bob"))), nil, nil, nil, 1},
+	{"Whole quad", ex("alice"), ex("knows"), ex("bob"), rdf.DefaultGraph, 1},
+	{"Unknown term", ex("carol"), nil, nil, nil, 0},
+	{"Wrong graph", ex("alice"), nil, nil, graph, 0},
+}
+
+func testStores(t *testing.T) map[string]rdf.Store {
+	return map[string]rdf.Store{
+		"memory": rdf.NewMemory(nil),
+		"disk":   MustOpen(filepath.Join(t.TempDir(), "quads")),
+	}
+}
+
+func TestMatch(t *testing.T) {
+	for name, s := range testStores(t) {
+		err := s.Update(func(tx *rdf.Tx) error {
+			tx.Add(testQuads...)
+			return nil
+		})
+
+		if err != nil {
+			t.Fatalf("%v store: %v", name, err)
+		}
+
+		for _, tc := range matchTests {
+			quads, err := s.Match(tc.S, tc.P, tc.O, tc.G)
+			if err != nil || len(quads) != tc.Expected {
+				t.Errorf("%v test fail: %v store got %v, %v", tc.Name, name, quads, err)
+			}
+		}
+
+		if n, _ := s.Len(); n != len(testQuads) {
+			t.Errorf("len test fail: %v store has %v quads", name, n)
+		}
+
+		s.Close()
+	}
+}
+
+func TestTransactions(t *testing.T) {
+	for name, s := range testStores(t) {
+		carol := rdf.Quad{Triple: rdf.NewTriple(ex("carol"), ex("knows"), ex("alice"))}
+
+		err := s.Update(func(tx *rdf.Tx) error {
+			tx.Add(testQuads...)
+			tx.Remove(testQuads[0])
+
+			// The transaction sees its own changes
+			if quads, _ := tx.Match(ex("alice"), ex("knows"), nil, nil); len(quads) != 0 {
+				t.Errorf("transactions test fail: %v store sees removed quad", name)
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			t.Fatalf("%v store: %v", name, err)
+		}
+
+		failed := errors.New("failed")
+
+		err = s.Update(func(tx *rdf.Tx) error {
+			tx.Add(carol)
+			tx.Remove(testQuads[1])
+
+			return failed
+		})
+
+		if !errors.Is(err, failed) {
+			t.Errorf("transactions test fail: %v store returned %v", name, err)
+		}
+
+		if n, _ := s.Len(); n != len(testQuads)-1 {
+			t.Errorf("transactions test fail: %v store has %v quads after a failed transaction", name, n)
+		}
+
+		if quads, _ := s.Match(ex("carol"), nil, nil, nil); len(quads) != 0 {
+			t.Errorf("transactions test fail: %v store kept a failed transaction's quad", name)
+		}
+
+		s.Close()
+	}
+}
+
+func TestReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quads")
+
+	s := MustOpen(path)
+	s.Update(func(tx *rdf.Tx) error {
+		tx.Add(testQuads...)
+		return nil
+	})
+	s.Update(func(tx *rdf.Tx) error {
+		tx.Remove(testQuads[1])
+		return nil
+	})
+	s.Close()
+
+	s = MustOpen(path)
+	defer s.Close()
+
+	for _, tc := range matchTests {
+		expected := tc.Expected
+		if tc.Name == "Everything" || tc.Name == "Subject" {
+			expected--
+		}
+
+		if quads, err := s.Match(tc.S, tc.P, tc.O, tc.G); err != nil || len(quads) != expected {
+			t.Errorf("reopen test fail: %v got %v, %v", tc.Name, quads, err)
+		}
+	}
+}
+
+// A crash part way through writing a transaction loses that transaction
+// and nothing before it
+func TestRecovery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quads")
+
+	s := MustOpen(path)
+	s.Update(func(tx *rdf.Tx) error {
+		tx.Add(testQuads[:2]...)
+		return nil
+	})
+
+	info, _ := os.Stat(path)
+	good := info.Size()
+
+	s.Update(func(tx *rdf.Tx) error {
+		tx.Add(testQuads[2:]...)
+		return nil
+	})
+	s.Close()
+
+	info, _ = os.Stat(path)
+
+	for _, size := range []int64{info.Size() - 1, good + 5} {
+		if err := os.Truncate(path, size); err != nil {
+			t.Fatal(err)
+		}
+
+		s, err := Open(path)
+		if err != nil {
+			t.Fatalf("recovery test fail: %v", err)
+		}
+
+		if n, _ := s.Len(); n != 2 {
+			t.Errorf("recovery test fail: %v quads after truncating to %v", n, size)
+		}
+
+		// The store can be written to after recovering
+		if err := s.Update(func(tx *rdf.Tx) error { tx.Add(testQuads[4]); return nil }); err != nil {
+			t.Errorf("recovery test fail: %v", err)
+		}
+
+		s.Close()
+
+		s = MustOpen(path)
+		if n, _ := s.Len(); n != 3 {
+			t.Errorf("recovery test fail: %v quads after writing to a recovered store", n)
+		}
+
+		s.Close()
+
+		// Back to only the first transaction for the next size
+		os.Truncate(path, good)
+	}
+
+	// A flipped bit is caught by the checksum
+	b, _ := os.ReadFile(path)
+	b[len(b)-6] ^= 0xff
+	os.WriteFile(path, b, 0o644)
+
+	s = MustOpen(path)
+	if n, _ := s.Len(); n != 0 {
+		t.Errorf("recovery test fail: corrupt transaction kept, %v quads", n)
+	}
+
+	s.Close()
+
+	os.WriteFile(path, []byte("not a store"), 0o644)
+	if _, err := Open(path); err == nil {
+		t.Errorf("recovery test fail: opened a file that isn't a store")
+	}
+}
+
+func TestCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quads")
+
+	s := MustOpen(path)
+
+	for i := 0; i < 10; i++ {
+		s.Update(func(tx *rdf.Tx) error {
+			tx.Add(testQuads...)
+			return nil
+		})
+		s.Update(func(tx *rdf.Tx) error {
+			tx.Remove(testQuads[:4]...)
+			return nil
+		})
+	}
+
+	before, _ := os.Stat(path)
+
+	if err := s.Compact(); err != nil {
+		t.Fatalf("compact test fail: %v", err)
+	}
+
+	after, _ := os.Stat(path)
+	if after.Size() >= before.Size() {
+		t.Errorf("compact test fail: %v bytes before, %v after", before.Size(), after.Size())
+	}
+
+	if quads, _ := s.Match(nil, nil, nil, nil); len(quads) != 1 || !quads[0].Triple.Equal(testQuads[4].Triple) {
+		t.Errorf("compact test fail: got %v", quads)
+	}
+
+	s.Close()
+
+	s = MustOpen(path)
+	if n, _ := s.Len(); n != 1 {
+		t.Errorf("compact test fail: %v quads after reopening", n)
+	}
+
+	s.Close()
+}
@@ -0,0 +1,196 @@
+package rdf
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	XSDString     IRI = "http://www.w3.org/2001/XMLSchema#string"
+	XSDBoolean    IRI = "http://www.w3.org/2001/XMLSchema#boolean"
+	XSDInteger    IRI = "http://www.w3.org/2001/XMLSchema#integer"
+	XSDDecimal    IRI = "http://www.w3.org/2001/XMLSchema#decimal"
+	XSDDouble     IRI = "http://www.w3.org/2001/XMLSchema#double"
+	RDFLangString IRI = "http://www.w3.org/1999/02/22-rdf-syntax-ns#langString"
+	RDFType       IRI = "http://www.w3.org/1999/02/22-rdf-syntax-ns#type"
+	RDFFirst      IRI = "http://www.w3.org/1999/02/22-rdf-syntax-ns#first"
+	RDFRest       IRI = "http://www.w3.org/1999/02/22-rdf-syntax-ns#rest"
+	RDFNil        IRI = "http://www.w3.org/1999/02/22-rdf-syntax-ns#nil"
+)
+
+type TermKind int
+
+const (
+	TermIRI TermKind = iota
+	TermBlankNode
+	TermLiteral
+	TermTriple
+	TermVariable
+	TermFormula
+)
+
+// A Term is anything that can sit in a position of a triple
+type Term interface {
+	Kind() TermKind
+
+	// String returns the term in N-Triples syntax, which doubles as
+	// the key the graph indexes it by
+	String() string
+}
+
+// An IRI identifies a resource, stored in its absolute form
+type IRI string
+
+func (i IRI) Kind() TermKind { return TermIRI }
+
+func (i IRI) String() string { return "<" + string(i) + ">" }
+
+// A BlankNode is a node local to the graph it appears in, the value is
+// the label without the leading _:
+type BlankNode string
+
+func (b BlankNode) Kind() TermKind { return TermBlankNode }
+
+func (b BlankNode) String() string { return "_:" + string(b) }
+
+// A Literal is a lexical value paired with a datatype or a language tag.
+// An empty Datatype is read as xsd:string
+type Literal struct {
+	Lexical  string
+	Datatype IRI
+	Language string
+}
+
+func NewLiteral(lexical string) Literal {
+	return Literal{Lexical: lexical, Datatype: XSDString}
+}
+
+func NewLangLiteral(lexical, lang string) Literal {
+	return Literal{Lexical: lexical, Datatype: RDFLangString, Language: strings.ToLower(lang)}
+}
+
+func NewTypedLiteral(lexical string, datatype IRI) Literal {
+	return Literal{Lexical: lexical, Datatype: datatype}
+}
+
+func (l Literal) Kind() TermKind { return TermLiteral }
+
+func (l Literal) String() string {
+	s := `"` + EscapeString(l.Lexical) + `"`
+
+	if l.Language != "" {
+		return s + "@" + l.Language
+	}
+
+	if l.Datatype == "" || l.Datatype == XSDString {
+		return s
+	}
+
+	return s + "^^" + l.Datatype.String()
+}
+
+// A QuotedTriple is a triple used as the subject or object of another
+// triple, as in RDF-star. Quoting a triple doesn't assert it
+type QuotedTriple struct {
+	Subject   Term
+	Predicate Term
+	Object    Term
+}
+
+func Quote(t Triple) QuotedTriple {
+	return QuotedTriple{Subject: t.Subject, Predicate: t.Predicate, Object: t.Object}
+}
+
+func (q QuotedTriple) Kind() TermKind { return TermTriple }
+
+func (q QuotedTriple) String() string {
+	return "<< " + termString(q.Subject) + " " + termString(q.Predicate) + " " + termString(q.Object) + " >>"
+}
+
+// Triple returns the quoted statement as a plain triple
+func (q QuotedTriple) Triple() Triple {
+	return NewTriple(q.Subject, q.Predicate, q.Object)
+}
+
+// A Variable stands in for a term in a query or rule pattern, the value is
+// the name without the leading ?
+type Variable string
+
+func (v Variable) Kind() TermKind { return TermVariable }
+
+func (v Variable) String() string { return "?" + string(v) }
+
+// A Formula is a quoted graph from Notation3, written in braces. Like a
+// quoted triple its triples aren't asserted
+//
+// https://w3c.github.io/N3/spec/#formulae
+type Formula []Triple
+
+func (f Formula) Kind() TermKind { return TermFormula }
+
+// String writes the triples sorted, so formulae with the same triples are
+// equal whatever order they were written in
+func (f Formula) String() string {
+	if len(f) == 0 {
+		return "{}"
+	}
+
+	lines := make([]string, len(f))
+	for i, t := range f {
+		lines[i] = t.String()
+	}
+
+	sort.Strings(lines)
+
+	return "{ " + strings.Join(lines, " ") + " }"
+}
+
+// Equal compares two terms by their N-Triples form, nil is only equal to nil
+func Equal(a, b Term) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	return a.Kind() == b.Kind() && a.String() == b.String()
+}
+
+// IsBlankNode is a shorthand for checking the kind of a possibly nil term
+func IsBlankNode(t Term) bool {
+	return t != nil && t.Kind() == TermBlankNode
+}
+
+// BlankNodesOf returns the blank nodes in a term, looking inside quoted
+// triples
+func BlankNodesOf(t Term) []BlankNode {
+	switch v := t.(type) {
+	case BlankNode:
+		return []BlankNode{v}
+	case QuotedTriple:
+		return append(append(BlankNodesOf(v.Subject), BlankNodesOf(v.Predicate)...), BlankNodesOf(v.Object)...)
+	case Formula:
+		var nodes []BlankNode
+		for _, t := range v {
+			nodes = append(nodes, BlankNodesOf(t.Subject)...)
+			nodes = append(nodes, BlankNodesOf(t.Predicate)...)
+			nodes = append(nodes, BlankNodesOf(t.Object)...)
+		}
+
+		return nodes
+	}
+
+	return nil
+}
+
+var stringEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\t", `\t`,
+)
+
+// EscapeString escapes a lexical value for use inside a double quoted
+// N-Triples or Turtle string
+func EscapeString(s string) string {
+	return stringEscaper.Replace(s)
+}
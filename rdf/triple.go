@@ -1,23 +1,35 @@
 package rdf
 
-import "net/url"
+// A Triple is a single statement in a graph. A nil position acts as a
+// wildcard when the triple is used as a pattern
+type Triple struct {
+	Subject   Term
+	Predicate Term
+	Object    Term
+}
+
+func NewTriple(s, p, o Term) Triple {
+	return Triple{Subject: s, Predicate: p, Object: o}
+}
 
-type IRI *url.URL
-type Literal any
-type BlankNode any // TODO figure out what this should actually be
+// String returns the triple as an N-Triples line, without the trailing newline
+func (t Triple) String() string {
+	return termString(t.Subject) + " " + termString(t.Predicate) + " " + termString(t.Object) + " ."
+}
+
+func (t Triple) Equal(o Triple) bool {
+	return Equal(t.Subject, o.Subject) && Equal(t.Predicate, o.Predicate) && Equal(t.Object, o.Object)
+}
 
-type Objector interface {
-	IRI | Literal | BlankNode
+// Terms returns the subject, predicate and object in order
+func (t Triple) Terms() [3]Term {
+	return [3]Term{t.Subject, t.Predicate, t.Object}
 }
 
-// SPARQL permits RDF Literals as the subject of RDF triples
-// So Subject implements the Objector interface here
-type Subject Objector
-type Predicate string
-type Object Objector
+func termString(t Term) string {
+	if t == nil {
+		return "?"
+	}
 
-type Triple struct {
-	Subject   Subject
-	Predicate Predicate
-	Object    Object
+	return t.String()
 }
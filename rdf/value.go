@@ -0,0 +1,817 @@
+package rdf
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	XSDFloat              IRI = "http://www.w3.org/2001/XMLSchema#float"
+	XSDDateTime           IRI = "http://www.w3.org/2001/XMLSchema#dateTime"
+	XSDDateTimeStamp      IRI = "http://www.w3.org/2001/XMLSchema#dateTimeStamp"
+	XSDDate               IRI = "http://www.w3.org/2001/XMLSchema#date"
+	XSDTime               IRI = "http://www.w3.org/2001/XMLSchema#time"
+	XSDDuration           IRI = "http://www.w3.org/2001/XMLSchema#duration"
+	XSDDayTimeDuration    IRI = "http://www.w3.org/2001/XMLSchema#dayTimeDuration"
+	XSDYearMonthDuration  IRI = "http://www.w3.org/2001/XMLSchema#yearMonthDuration"
+	XSDLong               IRI = "http://www.w3.org/2001/XMLSchema#long"
+	XSDInt                IRI = "http://www.w3.org/2001/XMLSchema#int"
+	XSDShort              IRI = "http://www.w3.org/2001/XMLSchema#short"
+	XSDByte               IRI = "http://www.w3.org/2001/XMLSchema#byte"
+	XSDNonNegativeInteger IRI = "http://www.w3.org/2001/XMLSchema#nonNegativeInteger"
+	XSDPositiveInteger    IRI = "http://www.w3.org/2001/XMLSchema#positiveInteger"
+	XSDNonPositiveInteger IRI = "http://www.w3.org/2001/XMLSchema#nonPositiveInteger"
+	XSDNegativeInteger    IRI = "http://www.w3.org/2001/XMLSchema#negativeInteger"
+	XSDUnsignedLong       IRI = "http://www.w3.org/2001/XMLSchema#unsignedLong"
+	XSDUnsignedInt        IRI = "http://www.w3.org/2001/XMLSchema#unsignedInt"
+	XSDUnsignedShort      IRI = "http://www.w3.org/2001/XMLSchema#unsignedShort"
+	XSDUnsignedByte       IRI = "http://www.w3.org/2001/XMLSchema#unsignedByte"
+	XSDNormalizedString   IRI = "http://www.w3.org/2001/XMLSchema#normalizedString"
+	XSDToken              IRI = "http://www.w3.org/2001/XMLSchema#token"
+	XSDLanguage           IRI = "http://www.w3.org/2001/XMLSchema#language"
+	XSDAnyURI             IRI = "http://www.w3.org/2001/XMLSchema#anyURI"
+)
+
+var (
+	ErrUnsupportedDatatype = errors.New("rdf: unsupported datatype")
+	ErrIncomparable        = errors.New("rdf: values are not comparable")
+)
+
+// An IllTypedError is returned when a literal's lexical form isn't valid
+// for its datatype, such as "abc"^^xsd:integer
+type IllTypedError struct {
+	Literal Literal
+}
+
+func (e *IllTypedError) Error() string {
+	return fmt.Sprintf("rdf: ill-typed literal %v", e.Literal)
+}
+
+// A LangString is the value of an rdf:langString literal
+type LangString struct {
+	Text     string
+	Language string
+}
+
+// A DateTime is the value of an xsd:dateTime, xsd:date or xsd:time literal.
+// Timezone is false when the lexical form didn't give one, Time is then in
+// UTC
+type DateTime struct {
+	Time     time.Time
+	Timezone bool
+}
+
+// A Duration is the value of an xsd:duration literal, kept as months and
+// seconds as the two don't convert into each other. Both have the same sign
+type Duration struct {
+	Months  int64
+	Seconds *big.Rat
+}
+
+var (
+	integerLexical  = regexp.MustCompile(`^[+-]?[0-9]+$`)
+	decimalLexical  = regexp.MustCompile(`^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)$`)
+	doubleLexical   = regexp.MustCompile(`^([+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][+-]?[0-9]+)?|[+-]?INF|NaN)$`)
+	dateTimeLexical = regexp.MustCompile(`^(-?[0-9]{4,})-([0-9]{2})-([0-9]{2})T([0-9]{2}):([0-9]{2}):([0-9]{2}(\.[0-9]+)?)(Z|[+-][0-9]{2}:[0-9]{2})?$`)
+	dateLexical     = regexp.MustCompile(`^(-?[0-9]{4,})-([0-9]{2})-([0-9]{2})(Z|[+-][0-9]{2}:[0-9]{2})?$`)
+	timeLexical     = regexp.MustCompile(`^([0-9]{2}):([0-9]{2}):([0-9]{2}(\.[0-9]+)?)(Z|[+-][0-9]{2}:[0-9]{2})?$`)
+	durationLexical = regexp.MustCompile(`^(-)?P(?:([0-9]+)Y)?(?:([0-9]+)M)?(?:([0-9]+)D)?(?:T(?:([0-9]+)H)?(?:([0-9]+)M)?(?:([0-9]+(?:\.[0-9]+)?)S)?)?$`)
+
+	// Ranges of the types derived from xsd:integer, nil means unbounded
+	integerRanges = map[IRI][2]*big.Int{
+		XSDInteger:            {nil, nil},
+		XSDLong:               {big.NewInt(math.MinInt64), big.NewInt(math.MaxInt64)},
+		XSDInt:                {big.NewInt(math.MinInt32), big.NewInt(math.MaxInt32)},
+		XSDShort:              {big.NewInt(math.MinInt16), big.NewInt(math.MaxInt16)},
+		XSDByte:               {big.NewInt(math.MinInt8), big.NewInt(math.MaxInt8)},
+		XSDNonNegativeInteger: {big.NewInt(0), nil},
+		XSDPositiveInteger:    {big.NewInt(1), nil},
+		XSDNonPositiveInteger: {nil, big.NewInt(0)},
+		XSDNegativeInteger:    {nil, big.NewInt(-1)},
+		XSDUnsignedLong:       {big.NewInt(0), new(big.Int).SetUint64(math.MaxUint64)},
+		XSDUnsignedInt:        {big.NewInt(0), big.NewInt(math.MaxUint32)},
+		XSDUnsignedShort:      {big.NewInt(0), big.NewInt(math.MaxUint16)},
+		XSDUnsignedByte:       {big.NewInt(0), big.NewInt(math.MaxUint8)},
+	}
+
+	stringTypes = map[IRI]bool{
+		"":                  true,
+		XSDString:           true,
+		XSDNormalizedString: true,
+		XSDToken:            true,
+		XSDLanguage:         true,
+		XSDAnyURI:           true,
+	}
+)
+
+// IsNumeric reports whether the datatype is one of the XSD numeric types
+func IsNumeric(datatype IRI) bool {
+	_, ok := integerRanges[datatype]
+	return ok || datatype == XSDDecimal || datatype == XSDDouble || datatype == XSDFloat
+}
+
+// Value parses the lexical form into a Go value for its datatype:
+//
+//	xsd:string and friends       string
+//	rdf:langString               LangString
+//	xsd:boolean                  bool
+//	xsd:integer and subtypes     *big.Int
+//	xsd:decimal                  *big.Rat
+//	xsd:double, xsd:float        float64
+//	xsd:dateTime, date, time     DateTime
+//	xsd:duration and subtypes    Duration
+//
+// An *IllTypedError is returned for lexical forms that aren't valid for
+// the datatype, and ErrUnsupportedDatatype for any other datatype
+func (l Literal) Value() (any, error) {
+	if l.Language != "" {
+		return LangString{Text: l.Lexical, Language: l.Language}, nil
+	}
+
+	if stringTypes[l.Datatype] {
+		return l.Lexical, nil
+	}
+
+	lex := strings.TrimSpace(l.Lexical)
+	illTyped := &IllTypedError{Literal: l}
+
+	if bounds, ok := integerRanges[l.Datatype]; ok {
+		if !integerLexical.MatchString(lex) {
+			return nil, illTyped
+		}
+
+		i, _ := new(big.Int).SetString(strings.TrimPrefix(lex, "+"), 10)
+
+		if bounds[0] != nil && i.Cmp(bounds[0]) < 0 || bounds[1] != nil && i.Cmp(bounds[1]) > 0 {
+			return nil, illTyped
+		}
+
+		return i, nil
+	}
+
+	switch l.Datatype {
+	case XSDBoolean:
+		switch lex {
+		case "true", "1":
+			return true, nil
+		case "false", "0":
+			return false, nil
+		}
+	case XSDDecimal:
+		if decimalLexical.MatchString(lex) {
+			r, _ := new(big.Rat).SetString(canonicalDecimal(lex))
+			return r, nil
+		}
+	case XSDDouble, XSDFloat:
+		if doubleLexical.MatchString(lex) {
+			return parseDouble(lex, l.Datatype == XSDFloat), nil
+		}
+	case XSDDateTime, XSDDateTimeStamp:
+		if dt, ok := parseDateTime(lex); ok && (dt.Timezone || l.Datatype == XSDDateTime) {
+			return dt, nil
+		}
+	case XSDDate:
+		if dt, ok := parseDate(lex); ok {
+			return dt, nil
+		}
+	case XSDTime:
+		if dt, ok := parseTime(lex); ok {
+			return dt, nil
+		}
+	case RDFLangString:
+		// Only valid with a language tag, handled above
+	case XSDDuration, XSDDayTimeDuration, XSDYearMonthDuration:
+		if d, ok := parseDuration(lex); ok &&
+			(l.Datatype != XSDDayTimeDuration || d.Months == 0) &&
+			(l.Datatype != XSDYearMonthDuration || d.Seconds.Sign() == 0) {
+			return d, nil
+		}
+	default:
+		return nil, ErrUnsupportedDatatype
+	}
+
+	return nil, illTyped
+}
+
+// Validate returns an *IllTypedError if the literal isn't valid for its
+// datatype. Literals of unsupported datatypes are assumed to be valid
+func (l Literal) Validate() error {
+	if _, err := l.Value(); err != nil && !errors.Is(err, ErrUnsupportedDatatype) {
+		return err
+	}
+
+	return nil
+}
+
+// Canonical returns the literal with its lexical form in the canonical
+// representation for the datatype, so "01"^^xsd:integer becomes "1"
+func (l Literal) Canonical() (Literal, error) {
+	v, err := l.Value()
+	if err != nil {
+		return l, err
+	}
+
+	c := l
+
+	switch val := v.(type) {
+	case *big.Int:
+		c.Lexical = val.String()
+	case *big.Rat:
+		c.Lexical = canonicalDecimal(strings.TrimSpace(l.Lexical))
+	case float64:
+		bitSize := 64
+		if l.Datatype == XSDFloat {
+			bitSize = 32
+		}
+
+		c.Lexical = canonicalDouble(val, bitSize)
+	case bool:
+		c.Lexical = strconv.FormatBool(val)
+	case DateTime:
+		c.Lexical = val.format(l.Datatype)
+	case Duration:
+		c.Lexical = val.String()
+	}
+
+	return c, nil
+}
+
+// LiteralOf converts a Go value into a literal with the matching XSD
+// datatype, the inverse of Value
+func LiteralOf(v any) (Literal, error) {
+	switch val := v.(type) {
+	case Literal:
+		return val, nil
+	case string:
+		return NewLiteral(val), nil
+	case LangString:
+		return NewLangLiteral(val.Text, val.Language), nil
+	case bool:
+		return NewTypedLiteral(strconv.FormatBool(val), XSDBoolean), nil
+	case int:
+		return NewTypedLiteral(strconv.Itoa(val), XSDInteger), nil
+	case int64:
+		return NewTypedLiteral(strconv.FormatInt(val, 10), XSDInteger), nil
+	case *big.Int:
+		return NewTypedLiteral(val.String(), XSDInteger), nil
+	case *big.Rat:
+		return NewTypedLiteral(formatRat(val), XSDDecimal), nil
+	case float64:
+		return NewTypedLiteral(canonicalDouble(val, 64), XSDDouble), nil
+	case float32:
+		return NewTypedLiteral(canonicalDouble(float64(val), 32), XSDFloat), nil
+	case time.Time:
+		return NewTypedLiteral(DateTime{Time: val, Timezone: true}.format(XSDDateTime), XSDDateTime), nil
+	case DateTime:
+		return NewTypedLiteral(val.format(XSDDateTime), XSDDateTime), nil
+	case Duration:
+		return NewTypedLiteral(val.String(), XSDDuration), nil
+	case time.Duration:
+		return NewTypedLiteral(Duration{Seconds: new(big.Rat).SetFrac64(int64(val), int64(time.Second))}.String(), XSDDayTimeDuration), nil
+	}
+
+	return Literal{}, fmt.Errorf("rdf: no literal type for %T", v)
+}
+
+// ValueEqual compares two terms by value, so "1"^^xsd:integer equals
+// "1.0"^^xsd:decimal. Terms that aren't literals are compared as terms.
+// ErrIncomparable is returned when two different literals can't be
+// compared, such as two literals of an unsupported datatype
+func ValueEqual(a, b Term) (bool, error) {
+	la, okA := a.(Literal)
+	lb, okB := b.(Literal)
+
+	if !okA || !okB {
+		return Equal(a, b), nil
+	}
+
+	va, errA := la.Value()
+	vb, errB := lb.Value()
+
+	// NaN isn't equal to anything, itself included
+	if isNaN(va) || isNaN(vb) {
+		return false, nil
+	}
+
+	if Equal(la, lb) {
+		return true, nil
+	}
+
+	if errA != nil || errB != nil {
+		return false, ErrIncomparable
+	}
+
+	if valueCategory(la.Datatype, va) != valueCategory(lb.Datatype, vb) {
+		return false, nil
+	}
+
+	if x, ok := va.(LangString); ok {
+		return x == vb.(LangString), nil
+	}
+
+	c, err := Compare(la, lb)
+	if err != nil {
+		return false, err
+	}
+
+	return c == 0, nil
+}
+
+// Compare orders two literals by value, returning -1, 0 or 1. Numbers
+// compare across the numeric types, strings, booleans, date/times and
+// durations compare within their own type. ErrIncomparable is returned for
+// anything else, including date/times too close to order when only one
+// has a timezone
+func Compare(a, b Literal) (int, error) {
+	va, err := a.Value()
+	if err != nil {
+		return 0, ErrIncomparable
+	}
+
+	vb, err := b.Value()
+	if err != nil {
+		return 0, ErrIncomparable
+	}
+
+	if valueCategory(a.Datatype, va) != valueCategory(b.Datatype, vb) {
+		return 0, ErrIncomparable
+	}
+
+	switch x := va.(type) {
+	case string:
+		return strings.Compare(x, vb.(string)), nil
+	case bool:
+		y := vb.(bool)
+		if x == y {
+			return 0, nil
+		}
+
+		if !x {
+			return -1, nil
+		}
+
+		return 1, nil
+	case DateTime:
+		return x.compare(vb.(DateTime))
+	case Duration:
+		return x.compare(vb.(Duration))
+	case *big.Int, *big.Rat, float64:
+		return compareNumbers(va, vb)
+	}
+
+	return 0, ErrIncomparable
+}
+
+// CompareTerms gives a total order over terms for sorting: unbound (nil),
+// then blank nodes, IRIs, quoted triples, literals and variables. Literals are
+// ordered by value where they can be, falling back to their lexical form
+// and datatype
+func CompareTerms(a, b Term) int {
+	rank := func(t Term) int {
+		if t == nil {
+			return 0
+		}
+
+		switch t.Kind() {
+		case TermBlankNode:
+			return 1
+		case TermIRI:
+			return 2
+		case TermTriple:
+			return 3
+		case TermVariable:
+			return 5
+		}
+
+		return 4
+	}
+
+	if ra, rb := rank(a), rank(b); ra != rb || ra == 0 {
+		return ra - rb
+	}
+
+	la, okA := a.(Literal)
+	lb, okB := b.(Literal)
+
+	if okA && okB {
+		if c, err := Compare(la, lb); err == nil && c != 0 {
+			return c
+		}
+
+		if c := strings.Compare(la.Lexical, lb.Lexical); c != 0 {
+			return c
+		}
+
+		if c := strings.Compare(string(la.Datatype), string(lb.Datatype)); c != 0 {
+			return c
+		}
+
+		return strings.Compare(la.Language, lb.Language)
+	}
+
+	return strings.Compare(a.String(), b.String())
+}
+
+// valueCategory groups datatypes whose values can be compared together
+func valueCategory(datatype IRI, v any) string {
+	switch v.(type) {
+	case *big.Int, *big.Rat, float64:
+		return "numeric"
+	case string:
+		return "string"
+	case LangString:
+		return "langString"
+	case bool:
+		return "boolean"
+	case Duration:
+		return "duration"
+	case DateTime:
+		if datatype == XSDDateTimeStamp {
+			return string(XSDDateTime)
+		}
+	}
+
+	return string(datatype)
+}
+
+func compareNumbers(a, b any) (int, error) {
+	fa, aFloat := a.(float64)
+	fb, bFloat := b.(float64)
+
+	if aFloat || bFloat {
+		if !aFloat {
+			fa = toFloat(a)
+		}
+
+		if !bFloat {
+			fb = toFloat(b)
+		}
+
+		if math.IsNaN(fa) || math.IsNaN(fb) {
+			return 0, ErrIncomparable
+		}
+
+		switch {
+		case fa < fb:
+			return -1, nil
+		case fa > fb:
+			return 1, nil
+		}
+
+		return 0, nil
+	}
+
+	return toRat(a).Cmp(toRat(b)), nil
+}
+
+func isNaN(v any) bool {
+	f, ok := v.(float64)
+	return ok && math.IsNaN(f)
+}
+
+func toRat(v any) *big.Rat {
+	switch x := v.(type) {
+	case *big.Int:
+		return new(big.Rat).SetInt(x)
+	case *big.Rat:
+		return x
+	}
+
+	return new(big.Rat)
+}
+
+func toFloat(v any) float64 {
+	f, _ := toRat(v).Float64()
+	return f
+}
+
+func parseDouble(lex string, single bool) float64 {
+	var f float64
+
+	switch strings.TrimPrefix(lex, "+") {
+	case "INF":
+		f = math.Inf(1)
+	case "-INF":
+		f = math.Inf(-1)
+	case "NaN":
+		f = math.NaN()
+	default:
+		f, _ = strconv.ParseFloat(lex, 64)
+	}
+
+	if single {
+		f = float64(float32(f))
+	}
+
+	return f
+}
+
+// canonicalDouble writes the XSD canonical form, a mantissa with a single
+// digit before the point and an exponent, like 1.5E2
+func canonicalDouble(f float64, bitSize int) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "INF"
+	case math.IsInf(f, -1):
+		return "-INF"
+	case f == 0:
+		if math.Signbit(f) {
+			return "-0.0E0"
+		}
+
+		return "0.0E0"
+	}
+
+	s := strconv.FormatFloat(f, 'E', -1, bitSize)
+	mantissa, exponent, _ := strings.Cut(s, "E")
+
+	if !strings.Contains(mantissa, ".") {
+		mantissa += ".0"
+	}
+
+	e, _ := strconv.Atoi(exponent)
+
+	return mantissa + "E" + strconv.Itoa(e)
+}
+
+// canonicalDecimal strips signs and zeros that don't change the value,
+// always leaving at least one digit either side of the point
+func canonicalDecimal(lex string) string {
+	negative := strings.HasPrefix(lex, "-")
+	lex = strings.TrimLeft(lex, "+-")
+
+	intPart, fracPart, _ := strings.Cut(lex, ".")
+	intPart = strings.TrimLeft(intPart, "0")
+	fracPart = strings.TrimRight(fracPart, "0")
+
+	if intPart == "" {
+		intPart = "0"
+	}
+
+	if fracPart == "" {
+		fracPart = "0"
+	}
+
+	if negative && (intPart != "0" || fracPart != "0") {
+		return "-" + intPart + "." + fracPart
+	}
+
+	return intPart + "." + fracPart
+}
+
+// formatRat writes a rational as a decimal, rationals that don't have a
+// finite decimal expansion are cut off at 18 places
+func formatRat(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String() + ".0"
+	}
+
+	return canonicalDecimal(r.FloatString(18))
+}
+
+func parseDateTime(lex string) (DateTime, bool) {
+	m := dateTimeLexical.FindStringSubmatch(lex)
+	if m == nil {
+		return DateTime{}, false
+	}
+
+	return buildDateTime(m[1], m[2], m[3], m[4], m[5], m[6], m[8])
+}
+
+func parseDate(lex string) (DateTime, bool) {
+	m := dateLexical.FindStringSubmatch(lex)
+	if m == nil {
+		return DateTime{}, false
+	}
+
+	return buildDateTime(m[1], m[2], m[3], "00", "00", "00", m[4])
+}
+
+// Times are given the date 1972-12-31 as XPath does when comparing them
+func parseTime(lex string) (DateTime, bool) {
+	m := timeLexical.FindStringSubmatch(lex)
+	if m == nil {
+		return DateTime{}, false
+	}
+
+	return buildDateTime("1972", "12", "31", m[1], m[2], m[3], m[5])
+}
+
+func buildDateTime(year, month, day, hour, minute, second, zone string) (DateTime, bool) {
+	y, _ := strconv.Atoi(year)
+	mo, _ := strconv.Atoi(month)
+	d, _ := strconv.Atoi(day)
+	h, _ := strconv.Atoi(hour)
+	mi, _ := strconv.Atoi(minute)
+	sec, _ := new(big.Rat).SetString(second)
+
+	if mo < 1 || mo > 12 || d < 1 || d > daysIn(y, mo) || mi > 59 || sec.Cmp(big.NewRat(60, 1)) >= 0 {
+		return DateTime{}, false
+	}
+
+	// 24:00:00 is the first instant of the next day
+	if h == 24 {
+		if mi != 0 || sec.Sign() != 0 {
+			return DateTime{}, false
+		}
+	} else if h > 23 {
+		return DateTime{}, false
+	}
+
+	nanos := new(big.Rat).Mul(sec, big.NewRat(int64(time.Second), 1))
+	ns, _ := nanos.Float64()
+
+	loc := time.UTC
+	if zone != "" && zone != "Z" {
+		zh, _ := strconv.Atoi(zone[1:3])
+		zm, _ := strconv.Atoi(zone[4:6])
+
+		if zh > 14 || zm > 59 || zh == 14 && zm != 0 {
+			return DateTime{}, false
+		}
+
+		offset := zh*3600 + zm*60
+		if zone[0] == '-' {
+			offset = -offset
+		}
+
+		loc = time.FixedZone("", offset)
+	}
+
+	t := time.Date(y, time.Month(mo), d, h, mi, 0, int(math.Round(ns)), loc)
+
+	return DateTime{Time: t, Timezone: zone != ""}, true
+}
+
+func daysIn(year, month int) int {
+	return time.Date(year, time.Month(month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func (d DateTime) format(datatype IRI) string {
+	layout := "2006-01-02T15:04:05.999999999"
+
+	switch datatype {
+	case XSDDate:
+		layout = "2006-01-02"
+	case XSDTime:
+		layout = "15:04:05.999999999"
+	}
+
+	// A zero offset is written as Z
+	if d.Timezone {
+		layout += "Z07:00"
+	}
+
+	return d.Time.Format(layout)
+}
+
+// compare follows XSD, an instant without a timezone could be anywhere
+// from -14:00 to +14:00 so it only orders against one with a timezone if
+// they are far enough apart
+func (d DateTime) compare(o DateTime) (int, error) {
+	a, b := d.Time, o.Time
+
+	if d.Timezone != o.Timezone {
+		const window = 14 * time.Hour
+		diff := a.Sub(b)
+
+		if diff > -window && diff < window {
+			return 0, ErrIncomparable
+		}
+	}
+
+	return a.Compare(b), nil
+}
+
+func parseDuration(lex string) (Duration, bool) {
+	m := durationLexical.FindStringSubmatch(lex)
+	if m == nil || lex == "P" || lex == "-P" || strings.HasSuffix(lex, "T") {
+		return Duration{}, false
+	}
+
+	atoi := func(s string) int64 {
+		i, _ := strconv.ParseInt(s, 10, 64)
+		return i
+	}
+
+	months := atoi(m[2])*12 + atoi(m[3])
+
+	seconds := new(big.Rat)
+	if m[7] != "" {
+		seconds.SetString(m[7])
+	}
+
+	seconds.Add(seconds, big.NewRat(atoi(m[4])*86400+atoi(m[5])*3600+atoi(m[6])*60, 1))
+
+	if m[1] == "-" {
+		months = -months
+		seconds.Neg(seconds)
+	}
+
+	return Duration{Months: months, Seconds: seconds}, true
+}
+
+// String writes the duration in its canonical form, like P1Y2MT3H
+func (d Duration) String() string {
+	months := d.Months
+	seconds := new(big.Rat)
+	if d.Seconds != nil {
+		seconds.Set(d.Seconds)
+	}
+
+	if months == 0 && seconds.Sign() == 0 {
+		return "PT0S"
+	}
+
+	var b strings.Builder
+
+	if months < 0 || seconds.Sign() < 0 {
+		b.WriteString("-")
+		months = -months
+		seconds.Neg(seconds)
+	}
+
+	b.WriteString("P")
+
+	if y := months / 12; y > 0 {
+		b.WriteString(strconv.FormatInt(y, 10) + "Y")
+	}
+
+	if mo := months % 12; mo > 0 {
+		b.WriteString(strconv.FormatInt(mo, 10) + "M")
+	}
+
+	whole := new(big.Int).Quo(seconds.Num(), seconds.Denom())
+	frac := new(big.Rat).Sub(seconds, new(big.Rat).SetInt(whole))
+	total := whole.Int64()
+
+	if days := total / 86400; days > 0 {
+		b.WriteString(strconv.FormatInt(days, 10) + "D")
+	}
+
+	h, mi, s := total%86400/3600, total%3600/60, total%60
+	if h == 0 && mi == 0 && s == 0 && frac.Sign() == 0 {
+		return b.String()
+	}
+
+	b.WriteString("T")
+
+	if h > 0 {
+		b.WriteString(strconv.FormatInt(h, 10) + "H")
+	}
+
+	if mi > 0 {
+		b.WriteString(strconv.FormatInt(mi, 10) + "M")
+	}
+
+	if s > 0 || frac.Sign() > 0 {
+		if frac.Sign() == 0 {
+			b.WriteString(strconv.FormatInt(s, 10) + "S")
+		} else {
+			b.WriteString(strings.TrimSuffix(canonicalDecimal(new(big.Rat).Add(frac, big.NewRat(s, 1)).FloatString(9)), ".0") + "S")
+		}
+	}
+
+	return b.String()
+}
+
+// compare orders durations when the answer doesn't depend on how long a
+// month is, which is when both parts agree or one part is equal
+func (d Duration) compare(o Duration) (int, error) {
+	// A duration made without seconds has none
+	x, y := new(big.Rat), new(big.Rat)
+	if d.Seconds != nil {
+		x.Set(d.Seconds)
+	}
+
+	if o.Seconds != nil {
+		y.Set(o.Seconds)
+	}
+
+	secs := x.Cmp(y)
+
+	months := 0
+	switch {
+	case d.Months < o.Months:
+		months = -1
+	case d.Months > o.Months:
+		months = 1
+	}
+
+	switch {
+	case months == 0:
+		return secs, nil
+	case secs == 0 || secs == months:
+		return months, nil
+	}
+
+	return 0, ErrIncomparable
+}
@@ -0,0 +1,126 @@
+package rdf
+
+import (
+	"errors"
+	"testing"
+)
+
+type canonicalTest struct {
+	Name     string
+	Input    Literal
+	Expected string
+}
+
+var canonicalTests = []canonicalTest{
+	{"Integer", NewTypedLiteral("+007", XSDInteger), "7"},
+	{"Decimal", NewTypedLiteral("-01.500", XSDDecimal), "-1.5"},
+	{"Whole decimal", NewTypedLiteral("3", XSDDecimal), "3.0"},
+	{"Negative zero decimal", NewTypedLiteral("-0.0", XSDDecimal), "0.0"},
+	{"Double", NewTypedLiteral("150", XSDDouble), "1.5E2"},
+	{"Small double", NewTypedLiteral(".001e0", XSDDouble), "1.0E-3"},
+	{"Float", NewTypedLiteral("0.1", XSDFloat), "1.0E-1"},
+	{"Boolean", NewTypedLiteral("1", XSDBoolean), "true"},
+	{"DateTime", NewTypedLiteral("2020-01-02T03:04:05.500+00:00", XSDDateTime), "2020-01-02T03:04:05.5Z"},
+	{"DateTime offset", NewTypedLiteral("2020-01-02T03:04:05-05:00", XSDDateTime), "2020-01-02T03:04:05-05:00"},
+	{"End of day", NewTypedLiteral("2020-12-31T24:00:00", XSDDateTime), "2021-01-01T00:00:00"},
+	{"Date", NewTypedLiteral("2020-02-29", XSDDate), "2020-02-29"},
+	{"Duration", NewTypedLiteral("P13MT90M", XSDDuration), "P1Y1MT1H30M"},
+	{"Fractional duration", NewTypedLiteral("-PT1.50S", XSDDuration), "-PT1.5S"},
+	{"Zero duration", NewTypedLiteral("P0D", XSDDuration), "PT0S"},
+}
+
+func TestCanonical(t *testing.T) {
+	for _, tc := range canonicalTests {
+		c, err := tc.Input.Canonical()
+		if err != nil {
+			t.Errorf("%v test fail: %v", tc.Name, err)
+			continue
+		}
+
+		if c.Lexical != tc.Expected {
+			t.Errorf("%v test fail: got %v, expected %v", tc.Name, c.Lexical, tc.Expected)
+		}
+	}
+}
+
+func TestIllTyped(t *testing.T) {
+	for _, l := range []Literal{
+		NewTypedLiteral("abc", XSDInteger),
+		NewTypedLiteral("300", XSDByte),
+		NewTypedLiteral("-1", XSDNonNegativeInteger),
+		NewTypedLiteral("1.2.3", XSDDecimal),
+		NewTypedLiteral("yes", XSDBoolean),
+		NewTypedLiteral("2021-02-29", XSDDate),
+		NewTypedLiteral("2021-01-01T10:00:00", XSDDateTimeStamp),
+		NewTypedLiteral("P1Y", XSDDayTimeDuration),
+		NewTypedLiteral("PT", XSDDuration),
+		NewTypedLiteral("hello", RDFLangString),
+	} {
+		var illTyped *IllTypedError
+		if err := l.Validate(); !errors.As(err, &illTyped) {
+			t.Errorf("%v not flagged as ill-typed: %v", l, err)
+		}
+	}
+
+	if err := NewTypedLiteral("anything", "http://example.org/custom").Validate(); err != nil {
+		t.Errorf("unsupported datatype flagged: %v", err)
+	}
+}
+
+type compareTest struct {
+	Name     string
+	A, B     Literal
+	Expected int
+	Err      bool
+}
+
+var compareTests = []compareTest{
+	{"Integer and decimal", NewTypedLiteral("1", XSDInteger), NewTypedLiteral("1.0", XSDDecimal), 0, false},
+	{"Decimal and double", NewTypedLiteral("0.5", XSDDecimal), NewTypedLiteral("1e0", XSDDouble), -1, false},
+	{"Derived integers", NewTypedLiteral("10", XSDByte), NewTypedLiteral("9", XSDLong), 1, false},
+	{"Strings", NewLiteral("a"), NewLiteral("b"), -1, false},
+	{"Booleans", NewTypedLiteral("false", XSDBoolean), NewTypedLiteral("1", XSDBoolean), -1, false},
+	{"DateTimes across zones", NewTypedLiteral("2020-01-01T12:00:00Z", XSDDateTime), NewTypedLiteral("2020-01-01T13:00:00+01:00", XSDDateTime), 0, false},
+	{"DateTime without zone", NewTypedLiteral("2020-01-01T12:00:00", XSDDateTime), NewTypedLiteral("2020-01-01T13:00:00Z", XSDDateTime), 0, true},
+	{"Durations", NewTypedLiteral("P1Y", XSDDuration), NewTypedLiteral("P13M", XSDDuration), -1, false},
+	{"Month against days", NewTypedLiteral("P1M", XSDDuration), NewTypedLiteral("P30D", XSDDuration), 0, true},
+	{"Number and string", NewTypedLiteral("1", XSDInteger), NewLiteral("1"), 0, true},
+}
+
+func TestCompare(t *testing.T) {
+	for _, tc := range compareTests {
+		c, err := Compare(tc.A, tc.B)
+
+		if (err != nil) != tc.Err {
+			t.Errorf("%v test fail: error %v", tc.Name, err)
+			continue
+		}
+
+		if err == nil && c != tc.Expected {
+			t.Errorf("%v test fail: got %v, expected %v", tc.Name, c, tc.Expected)
+		}
+	}
+
+	// Durations made without seconds have none
+	if c, err := (Duration{Months: 2}).compare(Duration{Months: 1}); c != 1 || err != nil {
+		t.Errorf("duration without seconds test fail: got %v, %v", c, err)
+	}
+}
+
+func TestValueEqual(t *testing.T) {
+	if eq, err := ValueEqual(NewTypedLiteral("01", XSDInteger), NewTypedLiteral("1.0", XSDDouble)); !eq || err != nil {
+		t.Errorf("integer and double not equal: %v", err)
+	}
+
+	if eq, _ := ValueEqual(NewLangLiteral("chat", "fr"), NewLangLiteral("chat", "en")); eq {
+		t.Error("different languages equal")
+	}
+
+	if eq, err := ValueEqual(NewTypedLiteral("NaN", XSDDouble), NewTypedLiteral("NaN", XSDDouble)); eq || err != nil {
+		t.Errorf("NaN equal to itself: %v", err)
+	}
+
+	if _, err := ValueEqual(NewTypedLiteral("a", "http://example.org/t"), NewTypedLiteral("b", "http://example.org/t")); err == nil {
+		t.Error("unsupported datatypes compared")
+	}
+}
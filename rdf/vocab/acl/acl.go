@@ -0,0 +1,105 @@
+// Code generated by vocabgen from acl.ttl. DO NOT EDIT.
+
+// Package acl holds the IRIs of the Web Access Control vocabulary, http://www.w3.org/ns/auth/acl#
+package acl
+
+import "github.com/b1scuit/solid/rdf"
+
+// Namespace is the IRI every term in the vocabulary starts with
+const Namespace rdf.IRI = "http://www.w3.org/ns/auth/acl#"
+
+// Term returns the IRI of a term in the vocabulary by its local name
+func Term(local string) rdf.IRI {
+	return Namespace + rdf.IRI(local)
+}
+
+const (
+	// Access: Any kind of access to a resource. Don't use this, use R W and
+	// RW.
+	Access rdf.IRI = "http://www.w3.org/ns/auth/acl#Access"
+
+	// AccessControl is accessControl (access control): The Access Control file
+	// for this information resource.
+	AccessControl rdf.IRI = "http://www.w3.org/ns/auth/acl#accessControl"
+
+	// AccessTo is accessTo (to): The information resource to which access is
+	// being granted.
+	AccessTo rdf.IRI = "http://www.w3.org/ns/auth/acl#accessTo"
+
+	// AccessToClass is accessToClass (to all in): A class of information
+	// resources to which access is being granted.
+	AccessToClass rdf.IRI = "http://www.w3.org/ns/auth/acl#accessToClass"
+
+	// Agent is agent: A person or social entity to being given the right.
+	Agent rdf.IRI = "http://www.w3.org/ns/auth/acl#agent"
+
+	// AgentClass is agentClass (agent class): A class of persons or social
+	// entities to being given the right.
+	AgentClass rdf.IRI = "http://www.w3.org/ns/auth/acl#agentClass"
+
+	// AgentGroup is agentGroup (agent group): A group of persons or social
+	// entities to being given the right. The right is given to any entity
+	// which is a vcard:member of the group, as defined by the document
+	// received when the Group is dereferenced.
+	AgentGroup rdf.IRI = "http://www.w3.org/ns/auth/acl#agentGroup"
+
+	// Append: Append accesses are specific write access which only add
+	// information, and do not remove information.
+	Append rdf.IRI = "http://www.w3.org/ns/auth/acl#Append"
+
+	// AuthenticatedAgent (Anyone authenticated): A class of agents who have
+	// been authenticated. In other words, anyone can access this resource, but
+	// not anonymously.
+	AuthenticatedAgent rdf.IRI = "http://www.w3.org/ns/auth/acl#AuthenticatedAgent"
+
+	// Authorization (access authorization): An element of access control,
+	// allowing agent to agents access of some kind to resources or classes of
+	// resources.
+	Authorization rdf.IRI = "http://www.w3.org/ns/auth/acl#Authorization"
+
+	// Control: Allows read/write access to the ACL for the resource(s).
+	Control rdf.IRI = "http://www.w3.org/ns/auth/acl#Control"
+
+	// Default is default (default access for things in this): If a resource
+	// has no ACL file (it is 404), then access to the resource is given by the
+	// ACL of the immediately containing directory, or failing that (404) the
+	// ACL of the recursively next containing directory which has an ACL file.
+	// Within that ACL file, any Authorization which has a default property
+	// whose value is that directory URI gives access to the resource.
+	Default rdf.IRI = "http://www.w3.org/ns/auth/acl#default"
+
+	// DefaultForNew is defaultForNew (default access for new things in the
+	// object): Deprecated, use acl:default instead.
+	DefaultForNew rdf.IRI = "http://www.w3.org/ns/auth/acl#defaultForNew"
+
+	// Delegates is delegates: Delegates a person or another agent to act on
+	// behalf of the agent.
+	Delegates rdf.IRI = "http://www.w3.org/ns/auth/acl#delegates"
+
+	// Mode is mode (access mode): A mode of access such as read or write.
+	Mode rdf.IRI = "http://www.w3.org/ns/auth/acl#mode"
+
+	// Origin: An Origin is basically a web site (Note WITHOUT the trailing
+	// slash after the domain name and port in its URI) and is the basis for
+	// controlling access to data by web apps in the Same Origin Model of web
+	// security.
+	Origin rdf.IRI = "http://www.w3.org/ns/auth/acl#Origin"
+
+	// OriginProperty is origin: A web application, identified by its Origin,
+	// such as <https://scripts.example.com>, being given the right.
+	OriginProperty rdf.IRI = "http://www.w3.org/ns/auth/acl#origin"
+
+	// Owner is owner: The person or other agent which owns this. For example,
+	// the owner of a file in a filesystem.
+	Owner rdf.IRI = "http://www.w3.org/ns/auth/acl#owner"
+
+	// Read: The class of read operations.
+	Read rdf.IRI = "http://www.w3.org/ns/auth/acl#Read"
+
+	// TrustedApp is trustedApp: An application that is trusted to act on
+	// behalf of the agent.
+	TrustedApp rdf.IRI = "http://www.w3.org/ns/auth/acl#trustedApp"
+
+	// Write: The class of write operations.
+	Write rdf.IRI = "http://www.w3.org/ns/auth/acl#Write"
+)
@@ -0,0 +1,123 @@
+// Code generated by vocabgen from acp.ttl. DO NOT EDIT.
+
+// Package acp holds the IRIs of the Access Control Policy vocabulary, http://www.w3.org/ns/solid/acp#
+package acp
+
+import "github.com/b1scuit/solid/rdf"
+
+// Namespace is the IRI every term in the vocabulary starts with
+const Namespace rdf.IRI = "http://www.w3.org/ns/solid/acp#"
+
+// Term returns the IRI of a term in the vocabulary by its local name
+func Term(local string) rdf.IRI {
+	return Namespace + rdf.IRI(local)
+}
+
+const (
+	// AccessControl (Access Control): An Access Control connects policies to
+	// resources.
+	AccessControl rdf.IRI = "http://www.w3.org/ns/solid/acp#AccessControl"
+
+	// AccessControlProperty is accessControl (access control): Connects an
+	// Access Control Resource to the access controls applying to its resource.
+	AccessControlProperty rdf.IRI = "http://www.w3.org/ns/solid/acp#accessControl"
+
+	// AccessControlResource (Access Control Resource): An Access Control
+	// Resource connects the resource it is linked to with the access controls
+	// that apply to it.
+	AccessControlResource rdf.IRI = "http://www.w3.org/ns/solid/acp#AccessControlResource"
+
+	// Agent is agent: An agent the matcher is satisfied by.
+	Agent rdf.IRI = "http://www.w3.org/ns/solid/acp#agent"
+
+	// AllOf is allOf (all of): A policy is satisfied only if every one of
+	// these matchers is satisfied.
+	AllOf rdf.IRI = "http://www.w3.org/ns/solid/acp#allOf"
+
+	// Allow is allow: An access mode granted by a policy when it is satisfied.
+	Allow rdf.IRI = "http://www.w3.org/ns/solid/acp#allow"
+
+	// AnyOf is anyOf (any of): A policy is satisfied only if at least one of
+	// these matchers is satisfied.
+	AnyOf rdf.IRI = "http://www.w3.org/ns/solid/acp#anyOf"
+
+	// Apply is apply: Connects an Access Control to the policies it applies.
+	Apply rdf.IRI = "http://www.w3.org/ns/solid/acp#apply"
+
+	// AuthenticatedAgent (authenticated agent): Matches any authenticated
+	// agent.
+	AuthenticatedAgent rdf.IRI = "http://www.w3.org/ns/solid/acp#AuthenticatedAgent"
+
+	// Client is client: A client application the matcher is satisfied by.
+	Client rdf.IRI = "http://www.w3.org/ns/solid/acp#client"
+
+	// Context: The context of an access request: the agent, client, issuer and
+	// credentials presented.
+	Context rdf.IRI = "http://www.w3.org/ns/solid/acp#Context"
+
+	// ContextProperty is context: The context an access grant was computed
+	// for.
+	ContextProperty rdf.IRI = "http://www.w3.org/ns/solid/acp#context"
+
+	// Creator is creator: The agent that created a resource.
+	Creator rdf.IRI = "http://www.w3.org/ns/solid/acp#creator"
+
+	// CreatorAgent (creator agent): Matches the agent that created the
+	// resource.
+	CreatorAgent rdf.IRI = "http://www.w3.org/ns/solid/acp#CreatorAgent"
+
+	// Deny is deny: An access mode denied by a policy when it is satisfied.
+	Deny rdf.IRI = "http://www.w3.org/ns/solid/acp#deny"
+
+	// Grant is grant: An access grant computed for a context.
+	Grant rdf.IRI = "http://www.w3.org/ns/solid/acp#grant"
+
+	// Issuer is issuer: An identity issuer the matcher is satisfied by.
+	Issuer rdf.IRI = "http://www.w3.org/ns/solid/acp#issuer"
+
+	// Matcher: A Matcher defines conditions that a context must satisfy.
+	Matcher rdf.IRI = "http://www.w3.org/ns/solid/acp#Matcher"
+
+	// MemberAccessControl is memberAccessControl (member access control):
+	// Connects an Access Control Resource to access controls applying to the
+	// members of its container resource.
+	MemberAccessControl rdf.IRI = "http://www.w3.org/ns/solid/acp#memberAccessControl"
+
+	// Mode is mode: An access mode requested in a context.
+	Mode rdf.IRI = "http://www.w3.org/ns/solid/acp#mode"
+
+	// NoneOf is noneOf (none of): A policy is satisfied only if none of these
+	// matchers is satisfied.
+	NoneOf rdf.IRI = "http://www.w3.org/ns/solid/acp#noneOf"
+
+	// Owner is owner: The owner of a storage.
+	Owner rdf.IRI = "http://www.w3.org/ns/solid/acp#owner"
+
+	// OwnerAgent (owner agent): Matches the owner of the storage the resource
+	// is in.
+	OwnerAgent rdf.IRI = "http://www.w3.org/ns/solid/acp#OwnerAgent"
+
+	// Policy: A Policy defines which access modes are allowed or denied given
+	// the matchers it uses.
+	Policy rdf.IRI = "http://www.w3.org/ns/solid/acp#Policy"
+
+	// PublicAgent (public agent): Matches any agent, authenticated or not.
+	PublicAgent rdf.IRI = "http://www.w3.org/ns/solid/acp#PublicAgent"
+
+	// PublicClient (public client): Matches any client application.
+	PublicClient rdf.IRI = "http://www.w3.org/ns/solid/acp#PublicClient"
+
+	// PublicIssuer (public issuer): Matches any identity issuer.
+	PublicIssuer rdf.IRI = "http://www.w3.org/ns/solid/acp#PublicIssuer"
+
+	// Resource is resource: The resource that an Access Control Resource
+	// controls access to.
+	Resource rdf.IRI = "http://www.w3.org/ns/solid/acp#resource"
+
+	// Target is target: The resource a context requests access to.
+	Target rdf.IRI = "http://www.w3.org/ns/solid/acp#target"
+
+	// Vc is vc (verifiable credential): A type of verifiable credential the
+	// matcher is satisfied by.
+	Vc rdf.IRI = "http://www.w3.org/ns/solid/acp#vc"
+)
@@ -0,0 +1,111 @@
+// Code generated by vocabgen from dcterms.ttl. DO NOT EDIT.
+
+// Package dcterms holds the IRIs of the DCMI Metadata Terms vocabulary, http://purl.org/dc/terms/
+package dcterms
+
+import "github.com/b1scuit/solid/rdf"
+
+// Namespace is the IRI every term in the vocabulary starts with
+const Namespace rdf.IRI = "http://purl.org/dc/terms/"
+
+// Term returns the IRI of a term in the vocabulary by its local name
+func Term(local string) rdf.IRI {
+	return Namespace + rdf.IRI(local)
+}
+
+const (
+	// Abstract is abstract: A summary of the resource.
+	Abstract rdf.IRI = "http://purl.org/dc/terms/abstract"
+
+	// Agent: A resource that acts or has the power to act.
+	Agent rdf.IRI = "http://purl.org/dc/terms/Agent"
+
+	// Contributor is contributor: An entity responsible for making
+	// contributions to the resource.
+	Contributor rdf.IRI = "http://purl.org/dc/terms/contributor"
+
+	// Created is created (Date Created): Date of creation of the resource.
+	Created rdf.IRI = "http://purl.org/dc/terms/created"
+
+	// Creator is creator: An entity responsible for making the resource.
+	Creator rdf.IRI = "http://purl.org/dc/terms/creator"
+
+	// Date is date: A point or period of time associated with an event in the
+	// lifecycle of the resource.
+	Date rdf.IRI = "http://purl.org/dc/terms/date"
+
+	// Description is description: An account of the resource.
+	Description rdf.IRI = "http://purl.org/dc/terms/description"
+
+	// Extent is extent: The size or duration of the resource.
+	Extent rdf.IRI = "http://purl.org/dc/terms/extent"
+
+	// FileFormat (File Format): A digital resource format.
+	FileFormat rdf.IRI = "http://purl.org/dc/terms/FileFormat"
+
+	// Format is format: The file format, physical medium, or dimensions of the
+	// resource.
+	Format rdf.IRI = "http://purl.org/dc/terms/format"
+
+	// HasPart is hasPart (Has Part): A related resource that is included
+	// either physically or logically in the described resource.
+	HasPart rdf.IRI = "http://purl.org/dc/terms/hasPart"
+
+	// Identifier is identifier: An unambiguous reference to the resource
+	// within a given context.
+	Identifier rdf.IRI = "http://purl.org/dc/terms/identifier"
+
+	// IsPartOf is isPartOf (Is Part Of): A related resource in which the
+	// described resource is physically or logically included.
+	IsPartOf rdf.IRI = "http://purl.org/dc/terms/isPartOf"
+
+	// Issued is issued (Date Issued): Date of formal issuance of the resource.
+	Issued rdf.IRI = "http://purl.org/dc/terms/issued"
+
+	// Language is language: A language of the resource.
+	Language rdf.IRI = "http://purl.org/dc/terms/language"
+
+	// License is license: A legal document giving official permission to do
+	// something with the resource.
+	License rdf.IRI = "http://purl.org/dc/terms/license"
+
+	// LicenseDocument (License Document): A legal document giving official
+	// permission to do something with a resource.
+	LicenseDocument rdf.IRI = "http://purl.org/dc/terms/LicenseDocument"
+
+	// Modified is modified (Date Modified): Date on which the resource was
+	// changed.
+	Modified rdf.IRI = "http://purl.org/dc/terms/modified"
+
+	// Publisher is publisher: An entity responsible for making the resource
+	// available.
+	Publisher rdf.IRI = "http://purl.org/dc/terms/publisher"
+
+	// References is references: A related resource that is referenced, cited,
+	// or otherwise pointed to by the described resource.
+	References rdf.IRI = "http://purl.org/dc/terms/references"
+
+	// Relation is relation: A related resource.
+	Relation rdf.IRI = "http://purl.org/dc/terms/relation"
+
+	// Rights is rights: Information about rights held in and over the
+	// resource.
+	Rights rdf.IRI = "http://purl.org/dc/terms/rights"
+
+	// Source is source: A related resource from which the described resource
+	// is derived.
+	Source rdf.IRI = "http://purl.org/dc/terms/source"
+
+	// Subject is subject: A topic of the resource.
+	Subject rdf.IRI = "http://purl.org/dc/terms/subject"
+
+	// Title is title: A name given to the resource.
+	Title rdf.IRI = "http://purl.org/dc/terms/title"
+
+	// Type is type: The nature or genre of the resource.
+	Type rdf.IRI = "http://purl.org/dc/terms/type"
+
+	// W3CDTF (W3C-DTF): The set of dates and times constructed according to
+	// the W3C Date and Time Formats Specification.
+	W3CDTF rdf.IRI = "http://purl.org/dc/terms/W3CDTF"
+)
@@ -0,0 +1,195 @@
+// Code generated by vocabgen from foaf.ttl. DO NOT EDIT.
+
+// Package foaf holds the IRIs of the Friend of a Friend vocabulary, http://xmlns.com/foaf/0.1/
+package foaf
+
+import "github.com/b1scuit/solid/rdf"
+
+// Namespace is the IRI every term in the vocabulary starts with
+const Namespace rdf.IRI = "http://xmlns.com/foaf/0.1/"
+
+// Term returns the IRI of a term in the vocabulary by its local name
+func Term(local string) rdf.IRI {
+	return Namespace + rdf.IRI(local)
+}
+
+const (
+	// Account is account: Indicates an account held by this agent.
+	Account rdf.IRI = "http://xmlns.com/foaf/0.1/account"
+
+	// AccountName is accountName (account name): Indicates the name
+	// (identifier) associated with this online account.
+	AccountName rdf.IRI = "http://xmlns.com/foaf/0.1/accountName"
+
+	// AccountServiceHomepage is accountServiceHomepage (account service
+	// homepage): Indicates a homepage of the service provide for this online
+	// account.
+	AccountServiceHomepage rdf.IRI = "http://xmlns.com/foaf/0.1/accountServiceHomepage"
+
+	// Age is age: The age in years of some agent.
+	Age rdf.IRI = "http://xmlns.com/foaf/0.1/age"
+
+	// Agent: An agent (eg. person, group, software or physical artifact).
+	Agent rdf.IRI = "http://xmlns.com/foaf/0.1/Agent"
+
+	// BasedNear is based_near (based near): A location that something is based
+	// near, for some broadly human notion of near.
+	BasedNear rdf.IRI = "http://xmlns.com/foaf/0.1/based_near"
+
+	// Birthday is birthday: The birthday of this Agent, represented in mm-dd
+	// string form, eg. '12-31'.
+	Birthday rdf.IRI = "http://xmlns.com/foaf/0.1/birthday"
+
+	// CurrentProject is currentProject (current project): A current project
+	// this person works on.
+	CurrentProject rdf.IRI = "http://xmlns.com/foaf/0.1/currentProject"
+
+	// Depiction is depiction: A depiction of some thing.
+	Depiction rdf.IRI = "http://xmlns.com/foaf/0.1/depiction"
+
+	// Depicts is depicts: A thing depicted in this representation.
+	Depicts rdf.IRI = "http://xmlns.com/foaf/0.1/depicts"
+
+	// Document: A document.
+	Document rdf.IRI = "http://xmlns.com/foaf/0.1/Document"
+
+	// FamilyName is familyName: The family name of some person.
+	FamilyName rdf.IRI = "http://xmlns.com/foaf/0.1/familyName"
+
+	// Focus is focus: The underlying or 'focal' entity associated with some
+	// SKOS-described concept.
+	Focus rdf.IRI = "http://xmlns.com/foaf/0.1/focus"
+
+	// Gender is gender: The gender of this Agent (typically but not
+	// necessarily 'male' or 'female').
+	Gender rdf.IRI = "http://xmlns.com/foaf/0.1/gender"
+
+	// GivenName is givenName (Given name): The given name of some person.
+	GivenName rdf.IRI = "http://xmlns.com/foaf/0.1/givenName"
+
+	// Group: A class of Agents.
+	Group rdf.IRI = "http://xmlns.com/foaf/0.1/Group"
+
+	// Homepage is homepage: A homepage for some thing.
+	Homepage rdf.IRI = "http://xmlns.com/foaf/0.1/homepage"
+
+	// Image: An image.
+	Image rdf.IRI = "http://xmlns.com/foaf/0.1/Image"
+
+	// Img is img (image): An image that can be used to represent some thing
+	// (ie. those depictions which are particularly representative of
+	// something, eg. one's photo on a homepage).
+	Img rdf.IRI = "http://xmlns.com/foaf/0.1/img"
+
+	// Interest is interest: A page about a topic of interest to this person.
+	Interest rdf.IRI = "http://xmlns.com/foaf/0.1/interest"
+
+	// IsPrimaryTopicOf is isPrimaryTopicOf (is primary topic of): A document
+	// that this thing is the primary topic of.
+	IsPrimaryTopicOf rdf.IRI = "http://xmlns.com/foaf/0.1/isPrimaryTopicOf"
+
+	// Knows is knows: A person known by this person (indicating some level of
+	// reciprocated interaction between the parties).
+	Knows rdf.IRI = "http://xmlns.com/foaf/0.1/knows"
+
+	// Logo is logo: A logo representing some thing.
+	Logo rdf.IRI = "http://xmlns.com/foaf/0.1/logo"
+
+	// Made is made: Something that was made by this agent.
+	Made rdf.IRI = "http://xmlns.com/foaf/0.1/made"
+
+	// Maker is maker: An agent that made this thing.
+	Maker rdf.IRI = "http://xmlns.com/foaf/0.1/maker"
+
+	// Mbox is mbox (personal mailbox): A personal mailbox, ie. an Internet
+	// mailbox associated with exactly one owner, the first owner of this
+	// mailbox.
+	Mbox rdf.IRI = "http://xmlns.com/foaf/0.1/mbox"
+
+	// MboxSha1sum is mbox_sha1sum (sha1sum of a personal mailbox URI name):
+	// The sha1sum of the URI of an Internet mailbox associated with exactly
+	// one owner, the first owner of the mailbox.
+	MboxSha1sum rdf.IRI = "http://xmlns.com/foaf/0.1/mbox_sha1sum"
+
+	// Member is member: Indicates a member of a Group
+	Member rdf.IRI = "http://xmlns.com/foaf/0.1/member"
+
+	// Name is name: A name for some thing.
+	Name rdf.IRI = "http://xmlns.com/foaf/0.1/name"
+
+	// Nick is nick (nickname): A short informal nickname characterising an
+	// agent (includes login identifiers, IRC and other chat nicknames).
+	Nick rdf.IRI = "http://xmlns.com/foaf/0.1/nick"
+
+	// OnlineAccount (Online Account): An online account.
+	OnlineAccount rdf.IRI = "http://xmlns.com/foaf/0.1/OnlineAccount"
+
+	// Openid is openid: An OpenID for an Agent.
+	Openid rdf.IRI = "http://xmlns.com/foaf/0.1/openid"
+
+	// Organization: An organization.
+	Organization rdf.IRI = "http://xmlns.com/foaf/0.1/Organization"
+
+	// Page is page: A page or document about this thing.
+	Page rdf.IRI = "http://xmlns.com/foaf/0.1/page"
+
+	// PastProject is pastProject (past project): A project this person has
+	// previously worked on.
+	PastProject rdf.IRI = "http://xmlns.com/foaf/0.1/pastProject"
+
+	// Person: A person.
+	Person rdf.IRI = "http://xmlns.com/foaf/0.1/Person"
+
+	// PersonalProfileDocument: A personal profile RDF document.
+	PersonalProfileDocument rdf.IRI = "http://xmlns.com/foaf/0.1/PersonalProfileDocument"
+
+	// Phone is phone: A phone, specified using fully qualified tel: URI scheme
+	// (refs: http://www.w3.org/Addressing/schemes.html#tel).
+	Phone rdf.IRI = "http://xmlns.com/foaf/0.1/phone"
+
+	// PrimaryTopic is primaryTopic (primary topic): The primary topic of some
+	// page or document.
+	PrimaryTopic rdf.IRI = "http://xmlns.com/foaf/0.1/primaryTopic"
+
+	// Project: A project (a collective endeavour of some kind).
+	Project rdf.IRI = "http://xmlns.com/foaf/0.1/Project"
+
+	// Publications is publications: A link to the publications of this person.
+	Publications rdf.IRI = "http://xmlns.com/foaf/0.1/publications"
+
+	// SchoolHomepage is schoolHomepage: A homepage of a school attended by the
+	// person.
+	SchoolHomepage rdf.IRI = "http://xmlns.com/foaf/0.1/schoolHomepage"
+
+	// Status is status: A string expressing what the user is happy for the
+	// general public (normally) to know about their current activity.
+	Status rdf.IRI = "http://xmlns.com/foaf/0.1/status"
+
+	// Thumbnail is thumbnail: A derived thumbnail image.
+	Thumbnail rdf.IRI = "http://xmlns.com/foaf/0.1/thumbnail"
+
+	// Tipjar is tipjar: A tipjar document for this agent, describing means for
+	// payment and reward.
+	Tipjar rdf.IRI = "http://xmlns.com/foaf/0.1/tipjar"
+
+	// Title is title: Title (Mr, Mrs, Ms, Dr. etc)
+	Title rdf.IRI = "http://xmlns.com/foaf/0.1/title"
+
+	// Topic is topic: A topic of some page or document.
+	Topic rdf.IRI = "http://xmlns.com/foaf/0.1/topic"
+
+	// TopicInterest is topic_interest: A thing of interest to this person.
+	TopicInterest rdf.IRI = "http://xmlns.com/foaf/0.1/topic_interest"
+
+	// Weblog is weblog: A weblog of some thing (whether person, group, company
+	// etc.).
+	Weblog rdf.IRI = "http://xmlns.com/foaf/0.1/weblog"
+
+	// WorkInfoHomepage is workInfoHomepage (work info homepage): A work info
+	// homepage of some person; a page about their work for some organization.
+	WorkInfoHomepage rdf.IRI = "http://xmlns.com/foaf/0.1/workInfoHomepage"
+
+	// WorkplaceHomepage is workplaceHomepage (workplace homepage): A workplace
+	// homepage of some person; the homepage of an organization they work for.
+	WorkplaceHomepage rdf.IRI = "http://xmlns.com/foaf/0.1/workplaceHomepage"
+)
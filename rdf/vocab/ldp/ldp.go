@@ -0,0 +1,106 @@
+// Code generated by vocabgen from ldp.ttl. DO NOT EDIT.
+
+// Package ldp holds the IRIs of the Linked Data Platform vocabulary, http://www.w3.org/ns/ldp#
+package ldp
+
+import "github.com/b1scuit/solid/rdf"
+
+// Namespace is the IRI every term in the vocabulary starts with
+const Namespace rdf.IRI = "http://www.w3.org/ns/ldp#"
+
+// Term returns the IRI of a term in the vocabulary by its local name
+func Term(local string) rdf.IRI {
+	return Namespace + rdf.IRI(local)
+}
+
+const (
+	// BasicContainer: An LDPC that uses a predefined predicate to simply link
+	// to its contained resources.
+	BasicContainer rdf.IRI = "http://www.w3.org/ns/ldp#BasicContainer"
+
+	// ConstrainedBy is constrainedBy: Links a resource with constraints that
+	// the server requires requests like creation and update to conform to.
+	ConstrainedBy rdf.IRI = "http://www.w3.org/ns/ldp#constrainedBy"
+
+	// Container: A Linked Data Platform RDF Source (LDP-RS) that also conforms
+	// to additional patterns and conventions for managing membership.
+	Container rdf.IRI = "http://www.w3.org/ns/ldp#Container"
+
+	// Contains is contains: Links a container with resources created through
+	// the container.
+	Contains rdf.IRI = "http://www.w3.org/ns/ldp#contains"
+
+	// DirectContainer: An LDPC that is similar to a LDP-DC but it allows an
+	// indirection with the ability to list as member a resource, such as a URI
+	// representing a real-world object, that is different from the resource
+	// that is created.
+	DirectContainer rdf.IRI = "http://www.w3.org/ns/ldp#DirectContainer"
+
+	// HasMemberRelation is hasMemberRelation: Indicates which predicate is
+	// used in membership triples, and that the membership triple pattern is <
+	// membership-constant-URI , object-of-hasMemberRelation, member-URI >.
+	HasMemberRelation rdf.IRI = "http://www.w3.org/ns/ldp#hasMemberRelation"
+
+	// Inbox is inbox: Links a resource to a container where notifications for
+	// the resource can be created and discovered.
+	Inbox rdf.IRI = "http://www.w3.org/ns/ldp#inbox"
+
+	// IndirectContainer: An LDPC that has the flexibility of choosing what
+	// form the membership triples take.
+	IndirectContainer rdf.IRI = "http://www.w3.org/ns/ldp#IndirectContainer"
+
+	// InsertedContentRelation is insertedContentRelation: Indicates which
+	// triple in a creation request should be used as the member-URI value in
+	// the membership triple added when the creation request is successful.
+	InsertedContentRelation rdf.IRI = "http://www.w3.org/ns/ldp#insertedContentRelation"
+
+	// IsMemberOfRelation is isMemberOfRelation: Indicates which predicate is
+	// used in membership triples, and that the membership triple pattern is <
+	// member-URI , object-of-isMemberOfRelation, membership-constant-URI >.
+	IsMemberOfRelation rdf.IRI = "http://www.w3.org/ns/ldp#isMemberOfRelation"
+
+	// Member is member: LDP servers should use this predicate as the
+	// membership predicate if there is no obvious predicate from an
+	// application vocabulary to use.
+	Member rdf.IRI = "http://www.w3.org/ns/ldp#member"
+
+	// MemberSubject: Used to indicate default and typical behavior for
+	// ldp:insertedContentRelation, where the member-URI value in the
+	// membership triple added when a creation request is successful is the URI
+	// assigned to the newly created resource.
+	MemberSubject rdf.IRI = "http://www.w3.org/ns/ldp#MemberSubject"
+
+	// MembershipResource is membershipResource: Indicates the
+	// membership-constant-URI of a container.
+	MembershipResource rdf.IRI = "http://www.w3.org/ns/ldp#membershipResource"
+
+	// NonRDFSource: A Linked Data Platform Resource (LDPR) whose state is NOT
+	// represented as RDF.
+	NonRDFSource rdf.IRI = "http://www.w3.org/ns/ldp#NonRDFSource"
+
+	// Page: URI signifying that the resource is an in-sequence page resource.
+	Page rdf.IRI = "http://www.w3.org/ns/ldp#Page"
+
+	// PageSequence is pageSequence: Link to a page sequence resource.
+	PageSequence rdf.IRI = "http://www.w3.org/ns/ldp#pageSequence"
+
+	// PreferContainment: URI identifying a LDPC's containment triples, for
+	// example to allow clients to express interest in receiving them.
+	PreferContainment rdf.IRI = "http://www.w3.org/ns/ldp#PreferContainment"
+
+	// PreferMembership: URI identifying a LDPC's membership triples, for
+	// example to allow clients to express interest in receiving them.
+	PreferMembership rdf.IRI = "http://www.w3.org/ns/ldp#PreferMembership"
+
+	// PreferMinimalContainer: URI identifying the subset of a LDPC's triples
+	// present for the server's implementation.
+	PreferMinimalContainer rdf.IRI = "http://www.w3.org/ns/ldp#PreferMinimalContainer"
+
+	// RDFSource: A Linked Data Platform Resource (LDPR) whose state is
+	// represented as RDF.
+	RDFSource rdf.IRI = "http://www.w3.org/ns/ldp#RDFSource"
+
+	// Resource: A HTTP-addressable resource whose lifecycle is managed by a
+	// LDP server.
+	Resource rdf.IRI = "http://www.w3.org/ns/ldp#Resource"
+)
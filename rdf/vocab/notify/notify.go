@@ -0,0 +1,78 @@
+// Code generated by vocabgen from notify.ttl. DO NOT EDIT.
+
+// Package notify holds the IRIs of the Solid Notifications vocabulary, http://www.w3.org/ns/solid/notifications#
+package notify
+
+import "github.com/b1scuit/solid/rdf"
+
+// Namespace is the IRI every term in the vocabulary starts with
+const Namespace rdf.IRI = "http://www.w3.org/ns/solid/notifications#"
+
+// Term returns the IRI of a term in the vocabulary by its local name
+func Term(local string) rdf.IRI {
+	return Namespace + rdf.IRI(local)
+}
+
+const (
+	// Accept is accept: The media type notifications are sent in.
+	Accept rdf.IRI = "http://www.w3.org/ns/solid/notifications#accept"
+
+	// ChannelType is channelType (channel type): The type of notification
+	// channel a subscription service provides.
+	ChannelType rdf.IRI = "http://www.w3.org/ns/solid/notifications#channelType"
+
+	// EndAt is endAt (end at): When the channel stops sending notifications.
+	EndAt rdf.IRI = "http://www.w3.org/ns/solid/notifications#endAt"
+
+	// Feature is feature: A feature a subscription service or channel
+	// supports.
+	Feature rdf.IRI = "http://www.w3.org/ns/solid/notifications#feature"
+
+	// NotificationChannel (notification channel): A channel notifications
+	// about a topic resource are sent over.
+	NotificationChannel rdf.IRI = "http://www.w3.org/ns/solid/notifications#NotificationChannel"
+
+	// Rate is rate: The least time between notifications.
+	Rate rdf.IRI = "http://www.w3.org/ns/solid/notifications#rate"
+
+	// ReceiveFrom is receiveFrom (receive from): Where the subscriber receives
+	// notifications from.
+	ReceiveFrom rdf.IRI = "http://www.w3.org/ns/solid/notifications#receiveFrom"
+
+	// SendTo is sendTo (send to): Where notifications are sent to.
+	SendTo rdf.IRI = "http://www.w3.org/ns/solid/notifications#sendTo"
+
+	// Sender is sender: The agent sending the notifications.
+	Sender rdf.IRI = "http://www.w3.org/ns/solid/notifications#sender"
+
+	// StartAt is startAt (start at): When notifications start being sent.
+	StartAt rdf.IRI = "http://www.w3.org/ns/solid/notifications#startAt"
+
+	// State is state: The state of the topic resource when a notification was
+	// sent, such as its ETag.
+	State rdf.IRI = "http://www.w3.org/ns/solid/notifications#state"
+
+	// StreamingHTTPChannel2023 (streaming HTTP channel 2023): A channel
+	// sending notifications in the body of a long lived HTTP response.
+	StreamingHTTPChannel2023 rdf.IRI = "http://www.w3.org/ns/solid/notifications#StreamingHTTPChannel2023"
+
+	// Subscription is subscription: Links a storage description to a
+	// subscription service.
+	Subscription rdf.IRI = "http://www.w3.org/ns/solid/notifications#subscription"
+
+	// SubscriptionService (subscription service): A resource notification
+	// channels are requested from.
+	SubscriptionService rdf.IRI = "http://www.w3.org/ns/solid/notifications#SubscriptionService"
+
+	// Topic is topic: The resource a notification channel sends notifications
+	// about.
+	Topic rdf.IRI = "http://www.w3.org/ns/solid/notifications#topic"
+
+	// WebSocketChannel2023 (WebSocket channel 2023): A channel sending
+	// notifications as messages over a WebSocket.
+	WebSocketChannel2023 rdf.IRI = "http://www.w3.org/ns/solid/notifications#WebSocketChannel2023"
+
+	// WebhookChannel2023 (webhook channel 2023): A channel sending
+	// notifications as HTTP POST requests to a URL the subscriber gives.
+	WebhookChannel2023 rdf.IRI = "http://www.w3.org/ns/solid/notifications#WebhookChannel2023"
+)
@@ -0,0 +1,186 @@
+// Code generated by vocabgen from owl.ttl. DO NOT EDIT.
+
+// Package owl holds the IRIs of the OWL vocabulary, http://www.w3.org/2002/07/owl#
+package owl
+
+import "github.com/b1scuit/solid/rdf"
+
+// Namespace is the IRI every term in the vocabulary starts with
+const Namespace rdf.IRI = "http://www.w3.org/2002/07/owl#"
+
+// Term returns the IRI of a term in the vocabulary by its local name
+func Term(local string) rdf.IRI {
+	return Namespace + rdf.IRI(local)
+}
+
+const (
+	// AllDifferent: The class of collections of pairwise different
+	// individuals.
+	AllDifferent rdf.IRI = "http://www.w3.org/2002/07/owl#AllDifferent"
+
+	// AllDisjointClasses: The class of collections of pairwise disjoint
+	// classes.
+	AllDisjointClasses rdf.IRI = "http://www.w3.org/2002/07/owl#AllDisjointClasses"
+
+	// AllValuesFrom is allValuesFrom: The property that determines the class
+	// that a universal property restriction refers to.
+	AllValuesFrom rdf.IRI = "http://www.w3.org/2002/07/owl#allValuesFrom"
+
+	// AnnotationProperty: The class of annotation properties.
+	AnnotationProperty rdf.IRI = "http://www.w3.org/2002/07/owl#AnnotationProperty"
+
+	// AsymmetricProperty: The class of asymmetric properties.
+	AsymmetricProperty rdf.IRI = "http://www.w3.org/2002/07/owl#AsymmetricProperty"
+
+	// Cardinality is cardinality: The property that determines the cardinality
+	// of an exact cardinality restriction.
+	Cardinality rdf.IRI = "http://www.w3.org/2002/07/owl#cardinality"
+
+	// Class: The class of OWL classes.
+	Class rdf.IRI = "http://www.w3.org/2002/07/owl#Class"
+
+	// ComplementOf is complementOf: The property that determines that a given
+	// class is the complement of another class.
+	ComplementOf rdf.IRI = "http://www.w3.org/2002/07/owl#complementOf"
+
+	// DatatypeProperty: The class of data properties.
+	DatatypeProperty rdf.IRI = "http://www.w3.org/2002/07/owl#DatatypeProperty"
+
+	// Deprecated is deprecated: The annotation property that indicates that a
+	// given entity has been deprecated.
+	Deprecated rdf.IRI = "http://www.w3.org/2002/07/owl#deprecated"
+
+	// DeprecatedClass: The class of deprecated classes.
+	DeprecatedClass rdf.IRI = "http://www.w3.org/2002/07/owl#DeprecatedClass"
+
+	// DeprecatedProperty: The class of deprecated properties.
+	DeprecatedProperty rdf.IRI = "http://www.w3.org/2002/07/owl#DeprecatedProperty"
+
+	// DifferentFrom is differentFrom: The property that determines that two
+	// given individuals are different.
+	DifferentFrom rdf.IRI = "http://www.w3.org/2002/07/owl#differentFrom"
+
+	// DisjointWith is disjointWith: The property that determines that two
+	// given classes are disjoint.
+	DisjointWith rdf.IRI = "http://www.w3.org/2002/07/owl#disjointWith"
+
+	// EquivalentClass is equivalentClass: The property that determines that
+	// two given classes are equivalent, and that is used to specify datatype
+	// definitions.
+	EquivalentClass rdf.IRI = "http://www.w3.org/2002/07/owl#equivalentClass"
+
+	// EquivalentProperty is equivalentProperty: The property that determines
+	// that two given properties are equivalent.
+	EquivalentProperty rdf.IRI = "http://www.w3.org/2002/07/owl#equivalentProperty"
+
+	// FunctionalProperty: The class of functional properties.
+	FunctionalProperty rdf.IRI = "http://www.w3.org/2002/07/owl#FunctionalProperty"
+
+	// HasKey is hasKey: The property that determines the collection of
+	// properties that jointly build a key.
+	HasKey rdf.IRI = "http://www.w3.org/2002/07/owl#hasKey"
+
+	// HasValue is hasValue: The property that determines the individual that a
+	// has-value restriction refers to.
+	HasValue rdf.IRI = "http://www.w3.org/2002/07/owl#hasValue"
+
+	// Imports is imports: The property that is used for importing other
+	// ontologies into a given ontology.
+	Imports rdf.IRI = "http://www.w3.org/2002/07/owl#imports"
+
+	// IntersectionOf is intersectionOf: The property that determines the
+	// collection of classes or data ranges that build an intersection.
+	IntersectionOf rdf.IRI = "http://www.w3.org/2002/07/owl#intersectionOf"
+
+	// InverseFunctionalProperty: The class of inverse-functional properties.
+	InverseFunctionalProperty rdf.IRI = "http://www.w3.org/2002/07/owl#InverseFunctionalProperty"
+
+	// InverseOf is inverseOf: The property that determines that two given
+	// properties are inverse.
+	InverseOf rdf.IRI = "http://www.w3.org/2002/07/owl#inverseOf"
+
+	// IrreflexiveProperty: The class of irreflexive properties.
+	IrreflexiveProperty rdf.IRI = "http://www.w3.org/2002/07/owl#IrreflexiveProperty"
+
+	// MaxCardinality is maxCardinality: The property that determines the
+	// cardinality of a maximum cardinality restriction.
+	MaxCardinality rdf.IRI = "http://www.w3.org/2002/07/owl#maxCardinality"
+
+	// Members is members: The property that determines the collection of
+	// members in either a owl:AllDifferent, owl:AllDisjointClasses or
+	// owl:AllDisjointProperties axiom.
+	Members rdf.IRI = "http://www.w3.org/2002/07/owl#members"
+
+	// MinCardinality is minCardinality: The property that determines the
+	// cardinality of a minimum cardinality restriction.
+	MinCardinality rdf.IRI = "http://www.w3.org/2002/07/owl#minCardinality"
+
+	// NamedIndividual: The class of named individuals.
+	NamedIndividual rdf.IRI = "http://www.w3.org/2002/07/owl#NamedIndividual"
+
+	// Nothing: This is the empty class.
+	Nothing rdf.IRI = "http://www.w3.org/2002/07/owl#Nothing"
+
+	// ObjectProperty: The class of object properties.
+	ObjectProperty rdf.IRI = "http://www.w3.org/2002/07/owl#ObjectProperty"
+
+	// OnClass is onClass: The property that determines the class that a
+	// qualified object cardinality restriction refers to.
+	OnClass rdf.IRI = "http://www.w3.org/2002/07/owl#onClass"
+
+	// OnProperty is onProperty: The property that determines the property that
+	// a property restriction refers to.
+	OnProperty rdf.IRI = "http://www.w3.org/2002/07/owl#onProperty"
+
+	// OneOf is oneOf: The property that determines the collection of
+	// individuals or data values that build an enumeration.
+	OneOf rdf.IRI = "http://www.w3.org/2002/07/owl#oneOf"
+
+	// Ontology: The class of ontologies.
+	Ontology rdf.IRI = "http://www.w3.org/2002/07/owl#Ontology"
+
+	// PropertyChainAxiom is propertyChainAxiom: The property that determines
+	// the n-tuple of properties that build a sub property chain of a given
+	// property.
+	PropertyChainAxiom rdf.IRI = "http://www.w3.org/2002/07/owl#propertyChainAxiom"
+
+	// QualifiedCardinality is qualifiedCardinality: The property that
+	// determines the cardinality of an exact qualified cardinality
+	// restriction.
+	QualifiedCardinality rdf.IRI = "http://www.w3.org/2002/07/owl#qualifiedCardinality"
+
+	// ReflexiveProperty: The class of reflexive properties.
+	ReflexiveProperty rdf.IRI = "http://www.w3.org/2002/07/owl#ReflexiveProperty"
+
+	// Restriction: The class of property restrictions.
+	Restriction rdf.IRI = "http://www.w3.org/2002/07/owl#Restriction"
+
+	// SameAs is sameAs: The property that determines that two given
+	// individuals are equal.
+	SameAs rdf.IRI = "http://www.w3.org/2002/07/owl#sameAs"
+
+	// SomeValuesFrom is someValuesFrom: The property that determines the class
+	// that an existential property restriction refers to.
+	SomeValuesFrom rdf.IRI = "http://www.w3.org/2002/07/owl#someValuesFrom"
+
+	// SymmetricProperty: The class of symmetric properties.
+	SymmetricProperty rdf.IRI = "http://www.w3.org/2002/07/owl#SymmetricProperty"
+
+	// Thing: The class of OWL individuals.
+	Thing rdf.IRI = "http://www.w3.org/2002/07/owl#Thing"
+
+	// TransitiveProperty: The class of transitive properties.
+	TransitiveProperty rdf.IRI = "http://www.w3.org/2002/07/owl#TransitiveProperty"
+
+	// UnionOf is unionOf: The property that determines the collection of
+	// classes or data ranges that build a union.
+	UnionOf rdf.IRI = "http://www.w3.org/2002/07/owl#unionOf"
+
+	// VersionIRI is versionIRI: The property that identifies the version IRI
+	// of an ontology.
+	VersionIRI rdf.IRI = "http://www.w3.org/2002/07/owl#versionIRI"
+
+	// VersionInfo is versionInfo: The annotation property that provides
+	// version information for an ontology or another OWL construct.
+	VersionInfo rdf.IRI = "http://www.w3.org/2002/07/owl#versionInfo"
+)
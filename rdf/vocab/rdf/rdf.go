@@ -0,0 +1,85 @@
+// Code generated by vocabgen from rdf.ttl. DO NOT EDIT.
+
+// Package rdf holds the IRIs of the RDF vocabulary, http://www.w3.org/1999/02/22-rdf-syntax-ns#
+package rdf
+
+import "github.com/b1scuit/solid/rdf"
+
+// Namespace is the IRI every term in the vocabulary starts with
+const Namespace rdf.IRI = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+
+// Term returns the IRI of a term in the vocabulary by its local name
+func Term(local string) rdf.IRI {
+	return Namespace + rdf.IRI(local)
+}
+
+const (
+	// Alt: The class of containers of alternatives.
+	Alt rdf.IRI = "http://www.w3.org/1999/02/22-rdf-syntax-ns#Alt"
+
+	// Bag: The class of unordered containers.
+	Bag rdf.IRI = "http://www.w3.org/1999/02/22-rdf-syntax-ns#Bag"
+
+	// CompoundLiteral: A class representing a compound literal.
+	CompoundLiteral rdf.IRI = "http://www.w3.org/1999/02/22-rdf-syntax-ns#CompoundLiteral"
+
+	// Direction is direction: The base direction component of a
+	// CompoundLiteral.
+	Direction rdf.IRI = "http://www.w3.org/1999/02/22-rdf-syntax-ns#direction"
+
+	// First is first: The first item in the subject RDF list.
+	First rdf.IRI = "http://www.w3.org/1999/02/22-rdf-syntax-ns#first"
+
+	// HTML: The datatype of RDF literals storing fragments of HTML content.
+	HTML rdf.IRI = "http://www.w3.org/1999/02/22-rdf-syntax-ns#HTML"
+
+	// JSON: The datatype of RDF literals storing JSON content.
+	JSON rdf.IRI = "http://www.w3.org/1999/02/22-rdf-syntax-ns#JSON"
+
+	// LangString is langString: The datatype of language-tagged string values.
+	LangString rdf.IRI = "http://www.w3.org/1999/02/22-rdf-syntax-ns#langString"
+
+	// Language is language: The language component of a CompoundLiteral.
+	Language rdf.IRI = "http://www.w3.org/1999/02/22-rdf-syntax-ns#language"
+
+	// List: The class of RDF Lists.
+	List rdf.IRI = "http://www.w3.org/1999/02/22-rdf-syntax-ns#List"
+
+	// Nil is nil: The empty list, with no items in it. If the rest of a list
+	// is nil then the list has no more items in it.
+	Nil rdf.IRI = "http://www.w3.org/1999/02/22-rdf-syntax-ns#nil"
+
+	// Object is object: The object of the subject RDF statement.
+	Object rdf.IRI = "http://www.w3.org/1999/02/22-rdf-syntax-ns#object"
+
+	// PlainLiteral: The class of plain (i.e. untyped) literal values, as used
+	// in RIF and OWL 2.
+	PlainLiteral rdf.IRI = "http://www.w3.org/1999/02/22-rdf-syntax-ns#PlainLiteral"
+
+	// Predicate is predicate: The predicate of the subject RDF statement.
+	Predicate rdf.IRI = "http://www.w3.org/1999/02/22-rdf-syntax-ns#predicate"
+
+	// Property: The class of RDF properties.
+	Property rdf.IRI = "http://www.w3.org/1999/02/22-rdf-syntax-ns#Property"
+
+	// Rest is rest: The rest of the subject RDF list after the first item.
+	Rest rdf.IRI = "http://www.w3.org/1999/02/22-rdf-syntax-ns#rest"
+
+	// Seq: The class of ordered containers.
+	Seq rdf.IRI = "http://www.w3.org/1999/02/22-rdf-syntax-ns#Seq"
+
+	// Statement: The class of RDF statements.
+	Statement rdf.IRI = "http://www.w3.org/1999/02/22-rdf-syntax-ns#Statement"
+
+	// Subject is subject: The subject of the subject RDF statement.
+	Subject rdf.IRI = "http://www.w3.org/1999/02/22-rdf-syntax-ns#subject"
+
+	// Type is type: The subject is an instance of a class.
+	Type rdf.IRI = "http://www.w3.org/1999/02/22-rdf-syntax-ns#type"
+
+	// Value is value: Idiomatic property used for structured values.
+	Value rdf.IRI = "http://www.w3.org/1999/02/22-rdf-syntax-ns#value"
+
+	// XMLLiteral: The datatype of XML literal values.
+	XMLLiteral rdf.IRI = "http://www.w3.org/1999/02/22-rdf-syntax-ns#XMLLiteral"
+)
@@ -0,0 +1,64 @@
+// Code generated by vocabgen from rdfs.ttl. DO NOT EDIT.
+
+// Package rdfs holds the IRIs of the RDF Schema vocabulary, http://www.w3.org/2000/01/rdf-schema#
+package rdfs
+
+import "github.com/b1scuit/solid/rdf"
+
+// Namespace is the IRI every term in the vocabulary starts with
+const Namespace rdf.IRI = "http://www.w3.org/2000/01/rdf-schema#"
+
+// Term returns the IRI of a term in the vocabulary by its local name
+func Term(local string) rdf.IRI {
+	return Namespace + rdf.IRI(local)
+}
+
+const (
+	// Class: The class of classes.
+	Class rdf.IRI = "http://www.w3.org/2000/01/rdf-schema#Class"
+
+	// Comment is comment: A description of the subject resource.
+	Comment rdf.IRI = "http://www.w3.org/2000/01/rdf-schema#comment"
+
+	// Container: The class of RDF containers.
+	Container rdf.IRI = "http://www.w3.org/2000/01/rdf-schema#Container"
+
+	// ContainerMembershipProperty: The class of container membership
+	// properties, rdf:_1, rdf:_2, ..., all of which are sub-properties of
+	// 'member'.
+	ContainerMembershipProperty rdf.IRI = "http://www.w3.org/2000/01/rdf-schema#ContainerMembershipProperty"
+
+	// Datatype: The class of RDF datatypes.
+	Datatype rdf.IRI = "http://www.w3.org/2000/01/rdf-schema#Datatype"
+
+	// Domain is domain: A domain of the subject property.
+	Domain rdf.IRI = "http://www.w3.org/2000/01/rdf-schema#domain"
+
+	// IsDefinedBy is isDefinedBy: The defininition of the subject resource.
+	IsDefinedBy rdf.IRI = "http://www.w3.org/2000/01/rdf-schema#isDefinedBy"
+
+	// Label is label: A human-readable name for the subject.
+	Label rdf.IRI = "http://www.w3.org/2000/01/rdf-schema#label"
+
+	// Literal: The class of literal values, eg. textual strings and integers.
+	Literal rdf.IRI = "http://www.w3.org/2000/01/rdf-schema#Literal"
+
+	// Member is member: A member of the subject resource.
+	Member rdf.IRI = "http://www.w3.org/2000/01/rdf-schema#member"
+
+	// Range is range: A range of the subject property.
+	Range rdf.IRI = "http://www.w3.org/2000/01/rdf-schema#range"
+
+	// Resource: The class resource, everything.
+	Resource rdf.IRI = "http://www.w3.org/2000/01/rdf-schema#Resource"
+
+	// SeeAlso is seeAlso: Further information about the subject resource.
+	SeeAlso rdf.IRI = "http://www.w3.org/2000/01/rdf-schema#seeAlso"
+
+	// SubClassOf is subClassOf: The subject is a subclass of a class.
+	SubClassOf rdf.IRI = "http://www.w3.org/2000/01/rdf-schema#subClassOf"
+
+	// SubPropertyOf is subPropertyOf: The subject is a subproperty of a
+	// property.
+	SubPropertyOf rdf.IRI = "http://www.w3.org/2000/01/rdf-schema#subPropertyOf"
+)
@@ -0,0 +1,155 @@
+// Code generated by vocabgen from schema.ttl. DO NOT EDIT.
+
+// Package schema holds the IRIs of the schema.org vocabulary, http://schema.org/
+package schema
+
+import "github.com/b1scuit/solid/rdf"
+
+// Namespace is the IRI every term in the vocabulary starts with
+const Namespace rdf.IRI = "http://schema.org/"
+
+// Term returns the IRI of a term in the vocabulary by its local name
+func Term(local string) rdf.IRI {
+	return Namespace + rdf.IRI(local)
+}
+
+const (
+	// Address is address: Physical address of the item.
+	Address rdf.IRI = "http://schema.org/address"
+
+	// AddressCountry is addressCountry: The country.
+	AddressCountry rdf.IRI = "http://schema.org/addressCountry"
+
+	// AddressLocality is addressLocality: The locality in which the street
+	// address is, and which is in the region.
+	AddressLocality rdf.IRI = "http://schema.org/addressLocality"
+
+	// AddressRegion is addressRegion: The region in which the locality is, and
+	// which is in the country.
+	AddressRegion rdf.IRI = "http://schema.org/addressRegion"
+
+	// Article: An article, such as a news article or piece of investigative
+	// report.
+	Article rdf.IRI = "http://schema.org/Article"
+
+	// Author is author: The author of this content or rating.
+	Author rdf.IRI = "http://schema.org/author"
+
+	// BirthDate is birthDate: Date of birth.
+	BirthDate rdf.IRI = "http://schema.org/birthDate"
+
+	// Book: A book.
+	Book rdf.IRI = "http://schema.org/Book"
+
+	// Brand: A brand is a name used by an organization or business person for
+	// labeling a product, product group, or similar.
+	Brand rdf.IRI = "http://schema.org/Brand"
+
+	// BrandProperty is brand: The brand(s) associated with a product or
+	// service, or the brand(s) maintained by an organization or business
+	// person.
+	BrandProperty rdf.IRI = "http://schema.org/brand"
+
+	// CreativeWork: The most generic kind of creative work, including books,
+	// movies, photographs, software programs, etc.
+	CreativeWork rdf.IRI = "http://schema.org/CreativeWork"
+
+	// DateCreated is dateCreated: The date on which the CreativeWork was
+	// created or the item was added to a DataFeed.
+	DateCreated rdf.IRI = "http://schema.org/dateCreated"
+
+	// DateModified is dateModified: The date on which the CreativeWork was
+	// most recently modified or when the item's entry was modified within a
+	// DataFeed.
+	DateModified rdf.IRI = "http://schema.org/dateModified"
+
+	// DatePublished is datePublished: Date of first publication or broadcast.
+	DatePublished rdf.IRI = "http://schema.org/datePublished"
+
+	// Description is description: A description of the item.
+	Description rdf.IRI = "http://schema.org/description"
+
+	// Email is email: Email address.
+	Email rdf.IRI = "http://schema.org/email"
+
+	// EndDate is endDate: The end date and time of the item.
+	EndDate rdf.IRI = "http://schema.org/endDate"
+
+	// Event: An event happening at a certain time and location, such as a
+	// concert, lecture, or festival.
+	Event rdf.IRI = "http://schema.org/Event"
+
+	// FamilyName is familyName: Family name. In the U.S., the last name of a
+	// Person.
+	FamilyName rdf.IRI = "http://schema.org/familyName"
+
+	// GivenName is givenName: Given name. In the U.S., the first name of a
+	// Person.
+	GivenName rdf.IRI = "http://schema.org/givenName"
+
+	// Identifier is identifier: The identifier property represents any kind of
+	// identifier for any kind of Thing.
+	Identifier rdf.IRI = "http://schema.org/identifier"
+
+	// Image is image: An image of the item.
+	Image rdf.IRI = "http://schema.org/image"
+
+	// ImageObject: An image file.
+	ImageObject rdf.IRI = "http://schema.org/ImageObject"
+
+	// JobTitle is jobTitle: The job title of the person (for example,
+	// Financial Manager).
+	JobTitle rdf.IRI = "http://schema.org/jobTitle"
+
+	// Keywords is keywords: Keywords or tags used to describe some item.
+	Keywords rdf.IRI = "http://schema.org/keywords"
+
+	// Knows is knows: The most generic bi-directional social/work relation.
+	Knows rdf.IRI = "http://schema.org/knows"
+
+	// Location is location: The location of, for example, where an event is
+	// happening, where an organization is located, or where an action takes
+	// place.
+	Location rdf.IRI = "http://schema.org/location"
+
+	// Name is name: The name of the item.
+	Name rdf.IRI = "http://schema.org/name"
+
+	// Organization: An organization such as a school, NGO, corporation, club,
+	// etc.
+	Organization rdf.IRI = "http://schema.org/Organization"
+
+	// Person: A person (alive, dead, undead, or fictional).
+	Person rdf.IRI = "http://schema.org/Person"
+
+	// Place: Entities that have a somewhat fixed, physical extension.
+	Place rdf.IRI = "http://schema.org/Place"
+
+	// PostalAddress: The mailing address.
+	PostalAddress rdf.IRI = "http://schema.org/PostalAddress"
+
+	// PostalCode is postalCode: The postal code.
+	PostalCode rdf.IRI = "http://schema.org/postalCode"
+
+	// SameAs is sameAs: URL of a reference Web page that unambiguously
+	// indicates the item's identity.
+	SameAs rdf.IRI = "http://schema.org/sameAs"
+
+	// StartDate is startDate: The start date and time of the item.
+	StartDate rdf.IRI = "http://schema.org/startDate"
+
+	// StreetAddress is streetAddress: The street address.
+	StreetAddress rdf.IRI = "http://schema.org/streetAddress"
+
+	// Telephone is telephone: The telephone number.
+	Telephone rdf.IRI = "http://schema.org/telephone"
+
+	// Thing: The most generic type of item.
+	Thing rdf.IRI = "http://schema.org/Thing"
+
+	// Url is url: URL of the item.
+	Url rdf.IRI = "http://schema.org/url"
+
+	// WorksFor is worksFor: Organizations that the person works for.
+	WorksFor rdf.IRI = "http://schema.org/worksFor"
+)
@@ -0,0 +1,458 @@
+// Code generated by vocabgen from sh.ttl. DO NOT EDIT.
+
+// Package sh holds the IRIs of the SHACL vocabulary, http://www.w3.org/ns/shacl#
+package sh
+
+import "github.com/b1scuit/solid/rdf"
+
+// Namespace is the IRI every term in the vocabulary starts with
+const Namespace rdf.IRI = "http://www.w3.org/ns/shacl#"
+
+// Term returns the IRI of a term in the vocabulary by its local name
+func Term(local string) rdf.IRI {
+	return Namespace + rdf.IRI(local)
+}
+
+const (
+	// AlternativePath is alternativePath (alternative path): The (single)
+	// value of this property must be a list of path elements, representing the
+	// elements of alternative paths.
+	AlternativePath rdf.IRI = "http://www.w3.org/ns/shacl#alternativePath"
+
+	// And is and: RDF list of shapes to validate the value nodes against, all
+	// of which must conform.
+	And rdf.IRI = "http://www.w3.org/ns/shacl#and"
+
+	// AndConstraintComponent (And constraint component): A constraint
+	// component that can be used to test the sh:and constraint.
+	AndConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#AndConstraintComponent"
+
+	// BlankNode: The node kind of all blank nodes.
+	BlankNode rdf.IRI = "http://www.w3.org/ns/shacl#BlankNode"
+
+	// BlankNodeOrIRI: The node kind of all blank nodes or IRIs.
+	BlankNodeOrIRI rdf.IRI = "http://www.w3.org/ns/shacl#BlankNodeOrIRI"
+
+	// BlankNodeOrLiteral: The node kind of all blank nodes or literals.
+	BlankNodeOrLiteral rdf.IRI = "http://www.w3.org/ns/shacl#BlankNodeOrLiteral"
+
+	// Class is class: The type that all value nodes must have.
+	Class rdf.IRI = "http://www.w3.org/ns/shacl#class"
+
+	// ClassConstraintComponent (Class constraint component): A constraint
+	// component that can be used to test the sh:class constraint.
+	ClassConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#ClassConstraintComponent"
+
+	// Closed is closed: If set to true then the shape is closed.
+	Closed rdf.IRI = "http://www.w3.org/ns/shacl#closed"
+
+	// ClosedConstraintComponent (Closed constraint component): A constraint
+	// component that can be used to test the sh:closed constraint.
+	ClosedConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#ClosedConstraintComponent"
+
+	// Conforms is conforms: True if the validation did not produce any
+	// validation results, and false otherwise.
+	Conforms rdf.IRI = "http://www.w3.org/ns/shacl#conforms"
+
+	// ConstraintComponent (Constraint component): The class of constraint
+	// components.
+	ConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#ConstraintComponent"
+
+	// Datatype is datatype: Specifies an RDF datatype that all value nodes
+	// must have.
+	Datatype rdf.IRI = "http://www.w3.org/ns/shacl#datatype"
+
+	// DatatypeConstraintComponent (Datatype constraint component): A
+	// constraint component that can be used to test the sh:datatype
+	// constraint.
+	DatatypeConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#DatatypeConstraintComponent"
+
+	// Deactivated is deactivated: If set to true then all nodes conform to
+	// this shape.
+	Deactivated rdf.IRI = "http://www.w3.org/ns/shacl#deactivated"
+
+	// DefaultValue is defaultValue (default value): A default value for a
+	// property.
+	DefaultValue rdf.IRI = "http://www.w3.org/ns/shacl#defaultValue"
+
+	// Description is description: Human-readable descriptions for the property
+	// in the context of the surrounding shape.
+	Description rdf.IRI = "http://www.w3.org/ns/shacl#description"
+
+	// Detail is detail: Links a result with other results that provide more
+	// details, for example to describe violations against nested shapes.
+	Detail rdf.IRI = "http://www.w3.org/ns/shacl#detail"
+
+	// Disjoint is disjoint: Specifies a property where the set of values must
+	// be disjoint with the value nodes.
+	Disjoint rdf.IRI = "http://www.w3.org/ns/shacl#disjoint"
+
+	// DisjointConstraintComponent (Disjoint constraint component): A
+	// constraint component that can be used to test the sh:disjoint
+	// constraint.
+	DisjointConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#DisjointConstraintComponent"
+
+	// Equals is equals: Specifies a property that must have the same values as
+	// the value nodes.
+	Equals rdf.IRI = "http://www.w3.org/ns/shacl#equals"
+
+	// EqualsConstraintComponent (Equals constraint component): A constraint
+	// component that can be used to test the sh:equals constraint.
+	EqualsConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#EqualsConstraintComponent"
+
+	// Flags is flags: An optional flag to be used with regular expression
+	// pattern matching.
+	Flags rdf.IRI = "http://www.w3.org/ns/shacl#flags"
+
+	// FocusNode is focusNode (focus node): The focus node that was validated
+	// when the result was produced.
+	FocusNode rdf.IRI = "http://www.w3.org/ns/shacl#focusNode"
+
+	// Group is group: Can be used to link to a property group to indicate that
+	// a property shape belongs to a group of related property shapes.
+	Group rdf.IRI = "http://www.w3.org/ns/shacl#group"
+
+	// HasValue is hasValue (has value): Specifies a value that must be among
+	// the value nodes.
+	HasValue rdf.IRI = "http://www.w3.org/ns/shacl#hasValue"
+
+	// HasValueConstraintComponent (Has value constraint component): A
+	// constraint component that can be used to test the sh:hasValue
+	// constraint.
+	HasValueConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#HasValueConstraintComponent"
+
+	// IRI: The node kind of all IRIs.
+	IRI rdf.IRI = "http://www.w3.org/ns/shacl#IRI"
+
+	// IRIOrLiteral: The node kind of all IRIs or literals.
+	IRIOrLiteral rdf.IRI = "http://www.w3.org/ns/shacl#IRIOrLiteral"
+
+	// IgnoredProperties is ignoredProperties (ignored properties): An optional
+	// RDF list of properties that are also permitted in addition to those
+	// explicitly enumerated via sh:property.
+	IgnoredProperties rdf.IRI = "http://www.w3.org/ns/shacl#ignoredProperties"
+
+	// In is in: Specifies a list of allowed values so that each value node
+	// must be among the members of the given list.
+	In rdf.IRI = "http://www.w3.org/ns/shacl#in"
+
+	// InConstraintComponent (In constraint component): A constraint component
+	// that can be used to test the sh:in constraint.
+	InConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#InConstraintComponent"
+
+	// Info: The severity for an informational validation result.
+	Info rdf.IRI = "http://www.w3.org/ns/shacl#Info"
+
+	// InversePath is inversePath (inverse path): The (single) value of this
+	// property represents an inverse path.
+	InversePath rdf.IRI = "http://www.w3.org/ns/shacl#inversePath"
+
+	// LanguageIn is languageIn (language in): Specifies a list of language
+	// tags that all value nodes must have.
+	LanguageIn rdf.IRI = "http://www.w3.org/ns/shacl#languageIn"
+
+	// LanguageInConstraintComponent (Language in constraint component): A
+	// constraint component that can be used to test the sh:languageIn
+	// constraint.
+	LanguageInConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#LanguageInConstraintComponent"
+
+	// LessThan is lessThan (less than): Specifies a property that must have
+	// smaller values than the value nodes.
+	LessThan rdf.IRI = "http://www.w3.org/ns/shacl#lessThan"
+
+	// LessThanConstraintComponent (Less than constraint component): A
+	// constraint component that can be used to test the sh:lessThan
+	// constraint.
+	LessThanConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#LessThanConstraintComponent"
+
+	// LessThanOrEquals is lessThanOrEquals (less than or equals): Specifies a
+	// property that must have smaller or equal values than the value nodes.
+	LessThanOrEquals rdf.IRI = "http://www.w3.org/ns/shacl#lessThanOrEquals"
+
+	// LessThanOrEqualsConstraintComponent (Less than or equals constraint
+	// component): A constraint component that can be used to test the
+	// sh:lessThanOrEquals constraint.
+	LessThanOrEqualsConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#LessThanOrEqualsConstraintComponent"
+
+	// Literal: The node kind of all literals.
+	Literal rdf.IRI = "http://www.w3.org/ns/shacl#Literal"
+
+	// MaxCount is maxCount (max count): Specifies the maximum number of values
+	// in the set of value nodes.
+	MaxCount rdf.IRI = "http://www.w3.org/ns/shacl#maxCount"
+
+	// MaxCountConstraintComponent (Max count constraint component): A
+	// constraint component that can be used to test the sh:maxCount
+	// constraint.
+	MaxCountConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#MaxCountConstraintComponent"
+
+	// MaxExclusive is maxExclusive (max exclusive): The maximum exclusive
+	// value that the values of this property must have.
+	MaxExclusive rdf.IRI = "http://www.w3.org/ns/shacl#maxExclusive"
+
+	// MaxExclusiveConstraintComponent (Max exclusive constraint component): A
+	// constraint component that can be used to test the sh:maxExclusive
+	// constraint.
+	MaxExclusiveConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#MaxExclusiveConstraintComponent"
+
+	// MaxInclusive is maxInclusive (max inclusive): The maximum inclusive
+	// value that the values of this property must have.
+	MaxInclusive rdf.IRI = "http://www.w3.org/ns/shacl#maxInclusive"
+
+	// MaxInclusiveConstraintComponent (Max inclusive constraint component): A
+	// constraint component that can be used to test the sh:maxInclusive
+	// constraint.
+	MaxInclusiveConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#MaxInclusiveConstraintComponent"
+
+	// MaxLength is maxLength (max length): Specifies the maximum string length
+	// of each value node.
+	MaxLength rdf.IRI = "http://www.w3.org/ns/shacl#maxLength"
+
+	// MaxLengthConstraintComponent (Max length constraint component): A
+	// constraint component that can be used to test the sh:maxLength
+	// constraint.
+	MaxLengthConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#MaxLengthConstraintComponent"
+
+	// Message is message: A human-readable message explaining the cause of the
+	// result.
+	Message rdf.IRI = "http://www.w3.org/ns/shacl#message"
+
+	// MinCount is minCount (min count): Specifies the minimum number of values
+	// in the set of value nodes.
+	MinCount rdf.IRI = "http://www.w3.org/ns/shacl#minCount"
+
+	// MinCountConstraintComponent (Min count constraint component): A
+	// constraint component that can be used to test the sh:minCount
+	// constraint.
+	MinCountConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#MinCountConstraintComponent"
+
+	// MinExclusive is minExclusive (min exclusive): The minimum exclusive
+	// value that the values of this property must have.
+	MinExclusive rdf.IRI = "http://www.w3.org/ns/shacl#minExclusive"
+
+	// MinExclusiveConstraintComponent (Min exclusive constraint component): A
+	// constraint component that can be used to test the sh:minExclusive
+	// constraint.
+	MinExclusiveConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#MinExclusiveConstraintComponent"
+
+	// MinInclusive is minInclusive (min inclusive): The minimum inclusive
+	// value that the values of this property must have.
+	MinInclusive rdf.IRI = "http://www.w3.org/ns/shacl#minInclusive"
+
+	// MinInclusiveConstraintComponent (Min inclusive constraint component): A
+	// constraint component that can be used to test the sh:minInclusive
+	// constraint.
+	MinInclusiveConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#MinInclusiveConstraintComponent"
+
+	// MinLength is minLength (min length): Specifies the minimum string length
+	// of each value node.
+	MinLength rdf.IRI = "http://www.w3.org/ns/shacl#minLength"
+
+	// MinLengthConstraintComponent (Min length constraint component): A
+	// constraint component that can be used to test the sh:minLength
+	// constraint.
+	MinLengthConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#MinLengthConstraintComponent"
+
+	// Name is name: Human-readable labels for the property in the context of
+	// the surrounding shape.
+	Name rdf.IRI = "http://www.w3.org/ns/shacl#name"
+
+	// Node is node: Specifies the node shape that all value nodes must conform
+	// to.
+	Node rdf.IRI = "http://www.w3.org/ns/shacl#node"
+
+	// NodeConstraintComponent (Node constraint component): A constraint
+	// component that can be used to test the sh:node constraint.
+	NodeConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#NodeConstraintComponent"
+
+	// NodeKind (Node kind): The class of all node kinds.
+	NodeKind rdf.IRI = "http://www.w3.org/ns/shacl#NodeKind"
+
+	// NodeKindConstraintComponent (Node kind constraint component): A
+	// constraint component that can be used to test the sh:nodeKind
+	// constraint.
+	NodeKindConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#NodeKindConstraintComponent"
+
+	// NodeKindProperty is nodeKind (node kind): Specifies the node kind that
+	// all value nodes must have.
+	NodeKindProperty rdf.IRI = "http://www.w3.org/ns/shacl#nodeKind"
+
+	// NodeShape (Node shape): A node shape is a shape that specifies
+	// constraints that need to be met by a focus node.
+	NodeShape rdf.IRI = "http://www.w3.org/ns/shacl#NodeShape"
+
+	// Not is not: Specifies a shape that the value nodes must not conform to.
+	Not rdf.IRI = "http://www.w3.org/ns/shacl#not"
+
+	// NotConstraintComponent (Not constraint component): A constraint
+	// component that can be used to test the sh:not constraint.
+	NotConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#NotConstraintComponent"
+
+	// OneOrMorePath is oneOrMorePath (one or more path): The (single) value of
+	// this property represents a path that is matched one or more times.
+	OneOrMorePath rdf.IRI = "http://www.w3.org/ns/shacl#oneOrMorePath"
+
+	// Or is or: Specifies a list of shapes so that the value nodes must
+	// conform to at least one of the shapes.
+	Or rdf.IRI = "http://www.w3.org/ns/shacl#or"
+
+	// OrConstraintComponent (Or constraint component): A constraint component
+	// that can be used to test the sh:or constraint.
+	OrConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#OrConstraintComponent"
+
+	// Order is order: Specifies the relative order of this compared to its
+	// siblings.
+	Order rdf.IRI = "http://www.w3.org/ns/shacl#order"
+
+	// Path is path: Specifies the property path of a property shape.
+	Path rdf.IRI = "http://www.w3.org/ns/shacl#path"
+
+	// Pattern is pattern: Specifies a regular expression pattern that the
+	// string representations of the value nodes must match.
+	Pattern rdf.IRI = "http://www.w3.org/ns/shacl#pattern"
+
+	// PatternConstraintComponent (Pattern constraint component): A constraint
+	// component that can be used to test the sh:pattern constraint.
+	PatternConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#PatternConstraintComponent"
+
+	// Property is property: Links a shape to its property shapes.
+	Property rdf.IRI = "http://www.w3.org/ns/shacl#property"
+
+	// PropertyConstraintComponent (Property constraint component): A
+	// constraint component that can be used to test the sh:property
+	// constraint.
+	PropertyConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#PropertyConstraintComponent"
+
+	// PropertyShape (Property shape): A property shape is a shape that
+	// specifies constraints on the values of a focus node for a given property
+	// or path.
+	PropertyShape rdf.IRI = "http://www.w3.org/ns/shacl#PropertyShape"
+
+	// QualifiedMaxCount is qualifiedMaxCount (qualified max count): The
+	// maximum number of value nodes that can conform to the qualified value
+	// shape.
+	QualifiedMaxCount rdf.IRI = "http://www.w3.org/ns/shacl#qualifiedMaxCount"
+
+	// QualifiedMaxCountConstraintComponent (Qualified max count constraint
+	// component): A constraint component that can be used to test the
+	// sh:qualifiedMaxCount constraint.
+	QualifiedMaxCountConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#QualifiedMaxCountConstraintComponent"
+
+	// QualifiedMinCount is qualifiedMinCount (qualified min count): The
+	// minimum number of value nodes that must conform to the qualified value
+	// shape.
+	QualifiedMinCount rdf.IRI = "http://www.w3.org/ns/shacl#qualifiedMinCount"
+
+	// QualifiedMinCountConstraintComponent (Qualified min count constraint
+	// component): A constraint component that can be used to test the
+	// sh:qualifiedMinCount constraint.
+	QualifiedMinCountConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#QualifiedMinCountConstraintComponent"
+
+	// QualifiedValueShape is qualifiedValueShape (qualified value shape): The
+	// shape that a specified number of values must conform to.
+	QualifiedValueShape rdf.IRI = "http://www.w3.org/ns/shacl#qualifiedValueShape"
+
+	// QualifiedValueShapesDisjoint is qualifiedValueShapesDisjoint (qualified
+	// value shapes disjoint): Can be used to mark the qualified value shape to
+	// be disjoint with its sibling shapes.
+	QualifiedValueShapesDisjoint rdf.IRI = "http://www.w3.org/ns/shacl#qualifiedValueShapesDisjoint"
+
+	// Result is result: The validation results contained in a validation
+	// report.
+	Result rdf.IRI = "http://www.w3.org/ns/shacl#result"
+
+	// ResultMessage is resultMessage (result message): Human-readable messages
+	// explaining the cause of the result.
+	ResultMessage rdf.IRI = "http://www.w3.org/ns/shacl#resultMessage"
+
+	// ResultPath is resultPath (result path): The path of a validation result,
+	// based on the path of the validated property shape.
+	ResultPath rdf.IRI = "http://www.w3.org/ns/shacl#resultPath"
+
+	// ResultSeverity is resultSeverity (result severity): The severity of the
+	// result, e.g. warning.
+	ResultSeverity rdf.IRI = "http://www.w3.org/ns/shacl#resultSeverity"
+
+	// Severity: The class of validation result severity levels.
+	Severity rdf.IRI = "http://www.w3.org/ns/shacl#Severity"
+
+	// SeverityProperty is severity: Defines the severity that validation
+	// results produced by a shape must have.
+	SeverityProperty rdf.IRI = "http://www.w3.org/ns/shacl#severity"
+
+	// Shape: A shape is a collection of constraints that may be targeted for
+	// certain nodes.
+	Shape rdf.IRI = "http://www.w3.org/ns/shacl#Shape"
+
+	// SourceConstraint is sourceConstraint (source constraint): The constraint
+	// that was validated when the result was produced.
+	SourceConstraint rdf.IRI = "http://www.w3.org/ns/shacl#sourceConstraint"
+
+	// SourceConstraintComponent is sourceConstraintComponent (source
+	// constraint component): The constraint component that is the source of
+	// the result.
+	SourceConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#sourceConstraintComponent"
+
+	// SourceShape is sourceShape (source shape): The shape that is was
+	// validated when the result was produced.
+	SourceShape rdf.IRI = "http://www.w3.org/ns/shacl#sourceShape"
+
+	// TargetClass is targetClass (target class): Links a shape to a class,
+	// indicating that all instances of the class must conform to the shape.
+	TargetClass rdf.IRI = "http://www.w3.org/ns/shacl#targetClass"
+
+	// TargetNode is targetNode (target node): Links a shape to individual
+	// nodes, indicating that these nodes must conform to the shape.
+	TargetNode rdf.IRI = "http://www.w3.org/ns/shacl#targetNode"
+
+	// TargetObjectsOf is targetObjectsOf (target objects of): Links a shape to
+	// a property, indicating that all objects of triples with the property
+	// must conform to the shape.
+	TargetObjectsOf rdf.IRI = "http://www.w3.org/ns/shacl#targetObjectsOf"
+
+	// TargetSubjectsOf is targetSubjectsOf (target subjects of): Links a shape
+	// to a property, indicating that all subjects of triples with the property
+	// must conform to the shape.
+	TargetSubjectsOf rdf.IRI = "http://www.w3.org/ns/shacl#targetSubjectsOf"
+
+	// UniqueLang is uniqueLang (unique lang): Specifies whether all node
+	// values must have a unique (or no) language tag.
+	UniqueLang rdf.IRI = "http://www.w3.org/ns/shacl#uniqueLang"
+
+	// UniqueLangConstraintComponent (Unique lang constraint component): A
+	// constraint component that can be used to test the sh:uniqueLang
+	// constraint.
+	UniqueLangConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#UniqueLangConstraintComponent"
+
+	// ValidationReport (Validation report): The class of SHACL validation
+	// reports.
+	ValidationReport rdf.IRI = "http://www.w3.org/ns/shacl#ValidationReport"
+
+	// ValidationResult (Validation result): The class of validation results.
+	ValidationResult rdf.IRI = "http://www.w3.org/ns/shacl#ValidationResult"
+
+	// Value is value: An RDF node that has caused the result.
+	Value rdf.IRI = "http://www.w3.org/ns/shacl#value"
+
+	// Violation: The severity for a violation validation result.
+	Violation rdf.IRI = "http://www.w3.org/ns/shacl#Violation"
+
+	// Warning: The severity for a warning validation result.
+	Warning rdf.IRI = "http://www.w3.org/ns/shacl#Warning"
+
+	// Xone is xone: Specifies a list of shapes so that the value nodes must
+	// conform to exactly one of the shapes.
+	Xone rdf.IRI = "http://www.w3.org/ns/shacl#xone"
+
+	// XoneConstraintComponent (Xone constraint component): A constraint
+	// component that can be used to test the sh:xone constraint.
+	XoneConstraintComponent rdf.IRI = "http://www.w3.org/ns/shacl#XoneConstraintComponent"
+
+	// ZeroOrMorePath is zeroOrMorePath (zero or more path): The (single) value
+	// of this property represents a path that is matched zero or more times.
+	ZeroOrMorePath rdf.IRI = "http://www.w3.org/ns/shacl#zeroOrMorePath"
+
+	// ZeroOrOnePath is zeroOrOnePath (zero or one path): The (single) value of
+	// this property represents a path that is matched zero or one times.
+	ZeroOrOnePath rdf.IRI = "http://www.w3.org/ns/shacl#zeroOrOnePath"
+)
@@ -0,0 +1,124 @@
+// Code generated by vocabgen from solid.ttl. DO NOT EDIT.
+
+// Package solid holds the IRIs of the Solid terms vocabulary, http://www.w3.org/ns/solid/terms#
+package solid
+
+import "github.com/b1scuit/solid/rdf"
+
+// Namespace is the IRI every term in the vocabulary starts with
+const Namespace rdf.IRI = "http://www.w3.org/ns/solid/terms#"
+
+// Term returns the IRI of a term in the vocabulary by its local name
+func Term(local string) rdf.IRI {
+	return Namespace + rdf.IRI(local)
+}
+
+const (
+	// Account (Solid account): A Solid account.
+	Account rdf.IRI = "http://www.w3.org/ns/solid/terms#Account"
+
+	// AccountProperty is account: A solid account belonging to an Agent.
+	AccountProperty rdf.IRI = "http://www.w3.org/ns/solid/terms#account"
+
+	// Deletes is deletes: The formula of triples to remove from the document.
+	Deletes rdf.IRI = "http://www.w3.org/ns/solid/terms#deletes"
+
+	// ForClass is forClass (registered type): The class of resources
+	// registered in a type registration.
+	ForClass rdf.IRI = "http://www.w3.org/ns/solid/terms#forClass"
+
+	// Inbox: A resource containing notifications.
+	Inbox rdf.IRI = "http://www.w3.org/ns/solid/terms#Inbox"
+
+	// InsertDeletePatch (insert/delete patch): A patch that inserts and/or
+	// deletes triples, optionally conditioned on a where clause.
+	InsertDeletePatch rdf.IRI = "http://www.w3.org/ns/solid/terms#InsertDeletePatch"
+
+	// Inserts is inserts: The formula of triples to add to the document.
+	Inserts rdf.IRI = "http://www.w3.org/ns/solid/terms#inserts"
+
+	// Instance is instance: A resource that is an instance of the registered
+	// type.
+	Instance rdf.IRI = "http://www.w3.org/ns/solid/terms#instance"
+
+	// InstanceContainer is instanceContainer (instance container): A container
+	// holding instances of the registered type.
+	InstanceContainer rdf.IRI = "http://www.w3.org/ns/solid/terms#instanceContainer"
+
+	// ListedDocument (listed document): A discoverable resource.
+	ListedDocument rdf.IRI = "http://www.w3.org/ns/solid/terms#ListedDocument"
+
+	// LoginEndpoint is loginEndpoint (login endpoint): The login URI of a
+	// given server.
+	LoginEndpoint rdf.IRI = "http://www.w3.org/ns/solid/terms#loginEndpoint"
+
+	// LogoutEndpoint is logoutEndpoint (logout endpoint): The logout URI of a
+	// given server.
+	LogoutEndpoint rdf.IRI = "http://www.w3.org/ns/solid/terms#logoutEndpoint"
+
+	// Notification: A notification resource.
+	Notification rdf.IRI = "http://www.w3.org/ns/solid/terms#Notification"
+
+	// NotificationProperty is notification: Links a resource to the
+	// notification channels it can be subscribed to with.
+	NotificationProperty rdf.IRI = "http://www.w3.org/ns/solid/terms#notification"
+
+	// OidcIssuer is oidcIssuer (OpenID Connect issuer): The preferred OpenID
+	// Connect issuer URI for a given Web ID.
+	OidcIssuer rdf.IRI = "http://www.w3.org/ns/solid/terms#oidcIssuer"
+
+	// Owner is owner: The owner of a storage.
+	Owner rdf.IRI = "http://www.w3.org/ns/solid/terms#owner"
+
+	// Patch: A patch expresses a change to an RDF document.
+	Patch rdf.IRI = "http://www.w3.org/ns/solid/terms#Patch"
+
+	// PrivateKey is privateKey (private key): A private key for an account.
+	PrivateKey rdf.IRI = "http://www.w3.org/ns/solid/terms#privateKey"
+
+	// PrivateTypeIndex is privateTypeIndex (private type index): The index of
+	// private resources by type.
+	PrivateTypeIndex rdf.IRI = "http://www.w3.org/ns/solid/terms#privateTypeIndex"
+
+	// PublicId is publicId (public id): A link to a public Web ID.
+	PublicId rdf.IRI = "http://www.w3.org/ns/solid/terms#publicId"
+
+	// PublicTypeIndex is publicTypeIndex (public type index): The index of
+	// public resources by type.
+	PublicTypeIndex rdf.IRI = "http://www.w3.org/ns/solid/terms#publicTypeIndex"
+
+	// Read is read: Indicates if a message has been read or not.
+	Read rdf.IRI = "http://www.w3.org/ns/solid/terms#read"
+
+	// StorageDescription is storageDescription (storage description): Links a
+	// storage to the resource describing it.
+	StorageDescription rdf.IRI = "http://www.w3.org/ns/solid/terms#storageDescription"
+
+	// StorageQuota is storageQuota (storage quota): The quota of storage space
+	// (in bytes) allocated to an account.
+	StorageQuota rdf.IRI = "http://www.w3.org/ns/solid/terms#storageQuota"
+
+	// StorageUsage is storageUsage (storage usage): The storage space (in
+	// bytes) used by an account.
+	StorageUsage rdf.IRI = "http://www.w3.org/ns/solid/terms#storageUsage"
+
+	// TypeIndex (index of types): A index of type registrations for resources.
+	TypeIndex rdf.IRI = "http://www.w3.org/ns/solid/terms#TypeIndex"
+
+	// TypeRegistration (type registration): A registration entry for a
+	// resource type.
+	TypeRegistration rdf.IRI = "http://www.w3.org/ns/solid/terms#TypeRegistration"
+
+	// UnlistedDocument (unlisted document): A resource that is not
+	// discoverable.
+	UnlistedDocument rdf.IRI = "http://www.w3.org/ns/solid/terms#UnlistedDocument"
+
+	// UpdatesViaStreamingHttp2023 is updatesViaStreamingHttp2023 (updates via
+	// streaming HTTP 2023): Links a resource to a StreamingHTTPChannel2023
+	// sending notifications about it.
+	UpdatesViaStreamingHttp2023 rdf.IRI = "http://www.w3.org/ns/solid/terms#updatesViaStreamingHttp2023"
+
+	// Where is where: The formula that must match for the patch to be applied,
+	// binding variables used in the inserts and deletes.
+	Where rdf.IRI = "http://www.w3.org/ns/solid/terms#where"
+)
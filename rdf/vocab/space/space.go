@@ -0,0 +1,60 @@
+// Code generated by vocabgen from space.ttl. DO NOT EDIT.
+
+// Package space holds the IRIs of the PIM workspace vocabulary, http://www.w3.org/ns/pim/space#
+package space
+
+import "github.com/b1scuit/solid/rdf"
+
+// Namespace is the IRI every term in the vocabulary starts with
+const Namespace rdf.IRI = "http://www.w3.org/ns/pim/space#"
+
+// Term returns the IRI of a term in the vocabulary by its local name
+func Term(local string) rdf.IRI {
+	return Namespace + rdf.IRI(local)
+}
+
+const (
+	// ConfigurationFile (configuration file): A file containing preferences or
+	// configuration.
+	ConfigurationFile rdf.IRI = "http://www.w3.org/ns/pim/space#ConfigurationFile"
+
+	// ControlledStorage (controlled storage): Storage whose access is
+	// controlled by a user or group.
+	ControlledStorage rdf.IRI = "http://www.w3.org/ns/pim/space#ControlledStorage"
+
+	// MasterWorkspace is masterWorkspace (master workspace): A workspace for
+	// storing the preferences and settings of an agent.
+	MasterWorkspace rdf.IRI = "http://www.w3.org/ns/pim/space#masterWorkspace"
+
+	// PersonalStorage (personal storage): Storage belonging to a single
+	// person.
+	PersonalStorage rdf.IRI = "http://www.w3.org/ns/pim/space#PersonalStorage"
+
+	// PreferencesFile is preferencesFile (preferences file): A file containing
+	// the preferences of an agent.
+	PreferencesFile rdf.IRI = "http://www.w3.org/ns/pim/space#preferencesFile"
+
+	// PrivateStorage (private storage): Storage readable only by its owner.
+	PrivateStorage rdf.IRI = "http://www.w3.org/ns/pim/space#PrivateStorage"
+
+	// PublicStorage (public storage): Storage that is readable by anyone.
+	PublicStorage rdf.IRI = "http://www.w3.org/ns/pim/space#PublicStorage"
+
+	// Storage: A storage is a space of URIs in which you have access to data.
+	Storage rdf.IRI = "http://www.w3.org/ns/pim/space#Storage"
+
+	// StorageProperty is storage: The storage in which this workspace is, or a
+	// storage belonging to an agent.
+	StorageProperty rdf.IRI = "http://www.w3.org/ns/pim/space#storage"
+
+	// UriPrefix is uriPrefix (URI prefix): URIs which start with this string
+	// are in this workspace or storage.
+	UriPrefix rdf.IRI = "http://www.w3.org/ns/pim/space#uriPrefix"
+
+	// Workspace: A workspace is a container for resources of similar access
+	// control and purpose.
+	Workspace rdf.IRI = "http://www.w3.org/ns/pim/space#Workspace"
+
+	// WorkspaceProperty is workspace: Workspace of an agent.
+	WorkspaceProperty rdf.IRI = "http://www.w3.org/ns/pim/space#workspace"
+)
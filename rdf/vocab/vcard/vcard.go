@@ -0,0 +1,159 @@
+// Code generated by vocabgen from vcard.ttl. DO NOT EDIT.
+
+// Package vcard holds the IRIs of the vCard vocabulary, http://www.w3.org/2006/vcard/ns#
+package vcard
+
+import "github.com/b1scuit/solid/rdf"
+
+// Namespace is the IRI every term in the vocabulary starts with
+const Namespace rdf.IRI = "http://www.w3.org/2006/vcard/ns#"
+
+// Term returns the IRI of a term in the vocabulary by its local name
+func Term(local string) rdf.IRI {
+	return Namespace + rdf.IRI(local)
+}
+
+const (
+	// Address: To specify the components of the delivery address for the
+	// object
+	Address rdf.IRI = "http://www.w3.org/2006/vcard/ns#Address"
+
+	// Bday is bday (birth date): To specify the birth date of the object
+	Bday rdf.IRI = "http://www.w3.org/2006/vcard/ns#bday"
+
+	// Cell: Also called mobile telephone
+	Cell rdf.IRI = "http://www.w3.org/2006/vcard/ns#Cell"
+
+	// CountryName is country-name (country name): The country name associated
+	// with the address of the object
+	CountryName rdf.IRI = "http://www.w3.org/2006/vcard/ns#country-name"
+
+	// Email: To specify the electronic mail address for communication with the
+	// object the vCard represents. Use the hasEmail object property.
+	Email rdf.IRI = "http://www.w3.org/2006/vcard/ns#Email"
+
+	// FamilyName is family-name (family name): The family name associated with
+	// the object
+	FamilyName rdf.IRI = "http://www.w3.org/2006/vcard/ns#family-name"
+
+	// Fax
+	Fax rdf.IRI = "http://www.w3.org/2006/vcard/ns#Fax"
+
+	// Fn is fn (formatted name): The formatted text corresponding to the name
+	// of the object
+	Fn rdf.IRI = "http://www.w3.org/2006/vcard/ns#fn"
+
+	// GivenName is given-name (given name): The given name associated with the
+	// object
+	GivenName rdf.IRI = "http://www.w3.org/2006/vcard/ns#given-name"
+
+	// Group: Object representing a group of persons or entities. A group
+	// object will usually contain hasMember properties to specify the members
+	// of the group.
+	Group rdf.IRI = "http://www.w3.org/2006/vcard/ns#Group"
+
+	// HasAddress is hasAddress (has address): To specify the components of the
+	// delivery address for the object
+	HasAddress rdf.IRI = "http://www.w3.org/2006/vcard/ns#hasAddress"
+
+	// HasEmail is hasEmail (has email): To specify the electronic mail address
+	// for communication with the object
+	HasEmail rdf.IRI = "http://www.w3.org/2006/vcard/ns#hasEmail"
+
+	// HasMember is hasMember (has member): To include a member in the group
+	// this object represents. (This property can only be used by Group
+	// individuals)
+	HasMember rdf.IRI = "http://www.w3.org/2006/vcard/ns#hasMember"
+
+	// HasName is hasName (has name): To specify the components of the name of
+	// the object
+	HasName rdf.IRI = "http://www.w3.org/2006/vcard/ns#hasName"
+
+	// HasNote is hasNote (has note): Used to support property parameters for
+	// the note data property
+	HasNote rdf.IRI = "http://www.w3.org/2006/vcard/ns#hasNote"
+
+	// HasPhoto is hasPhoto (has photo): To specify an image or photograph
+	// information that annotates some aspect of the object
+	HasPhoto rdf.IRI = "http://www.w3.org/2006/vcard/ns#hasPhoto"
+
+	// HasRelated is hasRelated (has related): To specify a relationship
+	// between another entity and the entity represented by this object
+	HasRelated rdf.IRI = "http://www.w3.org/2006/vcard/ns#hasRelated"
+
+	// HasTelephone is hasTelephone (has telephone): To specify the telephone
+	// number for telephony communication with the object
+	HasTelephone rdf.IRI = "http://www.w3.org/2006/vcard/ns#hasTelephone"
+
+	// HasUID is hasUID (has uid): To specify a value that represents a
+	// globally unique identifier corresponding to the individual or resource
+	// associated with the object
+	HasUID rdf.IRI = "http://www.w3.org/2006/vcard/ns#hasUID"
+
+	// HasURL is hasURL (has url): To specify a uniform resource locator
+	// associated with the object
+	HasURL rdf.IRI = "http://www.w3.org/2006/vcard/ns#hasURL"
+
+	// Home: This implies that the property is related to an individual's
+	// personal life
+	Home rdf.IRI = "http://www.w3.org/2006/vcard/ns#Home"
+
+	// Individual: An object representing a single person or entity
+	Individual rdf.IRI = "http://www.w3.org/2006/vcard/ns#Individual"
+
+	// Kind: The parent class for all objects
+	Kind rdf.IRI = "http://www.w3.org/2006/vcard/ns#Kind"
+
+	// Locality is locality: The locality (e.g. city or town) associated with
+	// the address of the object
+	Locality rdf.IRI = "http://www.w3.org/2006/vcard/ns#locality"
+
+	// Location: An object representing a named geographical place
+	Location rdf.IRI = "http://www.w3.org/2006/vcard/ns#Location"
+
+	// Nickname is nickname: The nick name associated with the object
+	Nickname rdf.IRI = "http://www.w3.org/2006/vcard/ns#nickname"
+
+	// Note is note: A note associated with the object
+	Note rdf.IRI = "http://www.w3.org/2006/vcard/ns#note"
+
+	// Organization: An object representing an organization. An organization is
+	// a single entity, and might represent a business or government, a
+	// department or division within a business or government, a club, an
+	// association, or the like.
+	Organization rdf.IRI = "http://www.w3.org/2006/vcard/ns#Organization"
+
+	// OrganizationName is organization-name (organization name): To specify
+	// the organizational name associated with the object
+	OrganizationName rdf.IRI = "http://www.w3.org/2006/vcard/ns#organization-name"
+
+	// PostalCode is postal-code (postal code): The postal code associated with
+	// the address of the object
+	PostalCode rdf.IRI = "http://www.w3.org/2006/vcard/ns#postal-code"
+
+	// Region is region: The region (e.g. state or province) associated with
+	// the address of the object
+	Region rdf.IRI = "http://www.w3.org/2006/vcard/ns#region"
+
+	// Role is role: To specify the function or part played in a particular
+	// situation by the object
+	Role rdf.IRI = "http://www.w3.org/2006/vcard/ns#role"
+
+	// StreetAddress is street-address (street address): The street address
+	// associated with the address of the object
+	StreetAddress rdf.IRI = "http://www.w3.org/2006/vcard/ns#street-address"
+
+	// Title is title: To specify the position or job of the object
+	Title rdf.IRI = "http://www.w3.org/2006/vcard/ns#title"
+
+	// Value is value: Used to indicate the resource value of an object
+	// property that requires property parameters
+	Value rdf.IRI = "http://www.w3.org/2006/vcard/ns#value"
+
+	// Voice
+	Voice rdf.IRI = "http://www.w3.org/2006/vcard/ns#Voice"
+
+	// Work: This implies that the property is related to an individual's work
+	// place
+	Work rdf.IRI = "http://www.w3.org/2006/vcard/ns#Work"
+)
@@ -0,0 +1,23 @@
+// Package vocab holds the ontologies the vocabulary packages are generated
+// from. Each subpackage is named after the prefix the ontology declares for
+// itself, so rdf/vocab/foaf gives foaf.Knows and so on
+//
+// To add a vocabulary drop its Turtle into ontologies/ and add a line below,
+// then run go generate ./rdf/vocab/
+package vocab
+
+//go:generate go run github.com/b1scuit/solid/cmd/vocabgen -in ontologies/rdf.ttl -out rdf/rdf.go -package rdf -title RDF
+//go:generate go run github.com/b1scuit/solid/cmd/vocabgen -in ontologies/rdfs.ttl -out rdfs/rdfs.go -package rdfs -title "RDF Schema"
+//go:generate go run github.com/b1scuit/solid/cmd/vocabgen -in ontologies/xsd.ttl -out xsd/xsd.go -package xsd -title "XML Schema datatypes"
+//go:generate go run github.com/b1scuit/solid/cmd/vocabgen -in ontologies/owl.ttl -out owl/owl.go -package owl -title OWL
+//go:generate go run github.com/b1scuit/solid/cmd/vocabgen -in ontologies/foaf.ttl -out foaf/foaf.go -package foaf -title "Friend of a Friend"
+//go:generate go run github.com/b1scuit/solid/cmd/vocabgen -in ontologies/vcard.ttl -out vcard/vcard.go -package vcard -title vCard
+//go:generate go run github.com/b1scuit/solid/cmd/vocabgen -in ontologies/ldp.ttl -out ldp/ldp.go -package ldp -title "Linked Data Platform"
+//go:generate go run github.com/b1scuit/solid/cmd/vocabgen -in ontologies/solid.ttl -out solid/solid.go -package solid -title "Solid terms"
+//go:generate go run github.com/b1scuit/solid/cmd/vocabgen -in ontologies/acl.ttl -out acl/acl.go -package acl -title "Web Access Control"
+//go:generate go run github.com/b1scuit/solid/cmd/vocabgen -in ontologies/acp.ttl -out acp/acp.go -package acp -title "Access Control Policy"
+//go:generate go run github.com/b1scuit/solid/cmd/vocabgen -in ontologies/dcterms.ttl -out dcterms/dcterms.go -package dcterms -title "DCMI Metadata Terms"
+//go:generate go run github.com/b1scuit/solid/cmd/vocabgen -in ontologies/schema.ttl -out schema/schema.go -package schema -title schema.org
+//go:generate go run github.com/b1scuit/solid/cmd/vocabgen -in ontologies/space.ttl -out space/space.go -package space -title "PIM workspace"
+//go:generate go run github.com/b1scuit/solid/cmd/vocabgen -in ontologies/sh.ttl -out sh/sh.go -package sh -title SHACL
+//go:generate go run github.com/b1scuit/solid/cmd/vocabgen -in ontologies/notify.ttl -out notify/notify.go -package notify -title "Solid Notifications"
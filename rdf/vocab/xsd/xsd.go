@@ -0,0 +1,135 @@
+// Code generated by vocabgen from xsd.ttl. DO NOT EDIT.
+
+// Package xsd holds the IRIs of the XML Schema datatypes vocabulary, http://www.w3.org/2001/XMLSchema#
+package xsd
+
+import "github.com/b1scuit/solid/rdf"
+
+// Namespace is the IRI every term in the vocabulary starts with
+const Namespace rdf.IRI = "http://www.w3.org/2001/XMLSchema#"
+
+// Term returns the IRI of a term in the vocabulary by its local name
+func Term(local string) rdf.IRI {
+	return Namespace + rdf.IRI(local)
+}
+
+const (
+	// AnyURI is anyURI: Absolute or relative URIs and IRIs.
+	AnyURI rdf.IRI = "http://www.w3.org/2001/XMLSchema#anyURI"
+
+	// Base64Binary is base64Binary: Base64-encoded binary data.
+	Base64Binary rdf.IRI = "http://www.w3.org/2001/XMLSchema#base64Binary"
+
+	// Boolean is boolean: true, false.
+	Boolean rdf.IRI = "http://www.w3.org/2001/XMLSchema#boolean"
+
+	// Byte is byte: -128…+127 (8 bit).
+	Byte rdf.IRI = "http://www.w3.org/2001/XMLSchema#byte"
+
+	// Date is date: Dates (yyyy-mm-dd) with or without timezone.
+	Date rdf.IRI = "http://www.w3.org/2001/XMLSchema#date"
+
+	// DateTime is dateTime: Date and time with or without timezone.
+	DateTime rdf.IRI = "http://www.w3.org/2001/XMLSchema#dateTime"
+
+	// DateTimeStamp is dateTimeStamp: Date and time with required timezone.
+	DateTimeStamp rdf.IRI = "http://www.w3.org/2001/XMLSchema#dateTimeStamp"
+
+	// DayTimeDuration is dayTimeDuration: Duration of time (days, hours,
+	// minutes, seconds only).
+	DayTimeDuration rdf.IRI = "http://www.w3.org/2001/XMLSchema#dayTimeDuration"
+
+	// Decimal is decimal: Arbitrary-precision decimal numbers.
+	Decimal rdf.IRI = "http://www.w3.org/2001/XMLSchema#decimal"
+
+	// Double is double: 64-bit floating point numbers incl. ±Inf, ±0, NaN.
+	Double rdf.IRI = "http://www.w3.org/2001/XMLSchema#double"
+
+	// Duration is duration: Duration of time.
+	Duration rdf.IRI = "http://www.w3.org/2001/XMLSchema#duration"
+
+	// Float is float: 32-bit floating point numbers incl. ±Inf, ±0, NaN.
+	Float rdf.IRI = "http://www.w3.org/2001/XMLSchema#float"
+
+	// GDay is gDay: Gregorian calendar day of the month.
+	GDay rdf.IRI = "http://www.w3.org/2001/XMLSchema#gDay"
+
+	// GMonth is gMonth: Gregorian calendar month.
+	GMonth rdf.IRI = "http://www.w3.org/2001/XMLSchema#gMonth"
+
+	// GMonthDay is gMonthDay: Gregorian calendar month and day.
+	GMonthDay rdf.IRI = "http://www.w3.org/2001/XMLSchema#gMonthDay"
+
+	// GYear is gYear: Gregorian calendar year.
+	GYear rdf.IRI = "http://www.w3.org/2001/XMLSchema#gYear"
+
+	// GYearMonth is gYearMonth: Gregorian calendar year and month.
+	GYearMonth rdf.IRI = "http://www.w3.org/2001/XMLSchema#gYearMonth"
+
+	// HexBinary is hexBinary: Hex-encoded binary data.
+	HexBinary rdf.IRI = "http://www.w3.org/2001/XMLSchema#hexBinary"
+
+	// Int is int: -2147483648…+2147483647 (32 bit).
+	Int rdf.IRI = "http://www.w3.org/2001/XMLSchema#int"
+
+	// Integer is integer: Arbitrary-size integer numbers.
+	Integer rdf.IRI = "http://www.w3.org/2001/XMLSchema#integer"
+
+	// Language is language: Language tags per BCP47.
+	Language rdf.IRI = "http://www.w3.org/2001/XMLSchema#language"
+
+	// Long is long: -9223372036854775808…+9223372036854775807 (64 bit).
+	Long rdf.IRI = "http://www.w3.org/2001/XMLSchema#long"
+
+	// NCName: XML NCNames.
+	NCName rdf.IRI = "http://www.w3.org/2001/XMLSchema#NCName"
+
+	// NMTOKEN: XML NMTOKENs.
+	NMTOKEN rdf.IRI = "http://www.w3.org/2001/XMLSchema#NMTOKEN"
+
+	// Name: XML Names.
+	Name rdf.IRI = "http://www.w3.org/2001/XMLSchema#Name"
+
+	// NegativeInteger is negativeInteger: Integer numbers <0.
+	NegativeInteger rdf.IRI = "http://www.w3.org/2001/XMLSchema#negativeInteger"
+
+	// NonNegativeInteger is nonNegativeInteger: Integer numbers ≥0.
+	NonNegativeInteger rdf.IRI = "http://www.w3.org/2001/XMLSchema#nonNegativeInteger"
+
+	// NonPositiveInteger is nonPositiveInteger: Integer numbers ≤0.
+	NonPositiveInteger rdf.IRI = "http://www.w3.org/2001/XMLSchema#nonPositiveInteger"
+
+	// NormalizedString is normalizedString: Whitespace-normalized strings.
+	NormalizedString rdf.IRI = "http://www.w3.org/2001/XMLSchema#normalizedString"
+
+	// PositiveInteger is positiveInteger: Integer numbers >0.
+	PositiveInteger rdf.IRI = "http://www.w3.org/2001/XMLSchema#positiveInteger"
+
+	// Short is short: -32768…+32767 (16 bit).
+	Short rdf.IRI = "http://www.w3.org/2001/XMLSchema#short"
+
+	// String is string: Character strings.
+	String rdf.IRI = "http://www.w3.org/2001/XMLSchema#string"
+
+	// Time is time: Times (hh:mm:ss.sss…) with or without timezone.
+	Time rdf.IRI = "http://www.w3.org/2001/XMLSchema#time"
+
+	// Token is token: Tokenized strings.
+	Token rdf.IRI = "http://www.w3.org/2001/XMLSchema#token"
+
+	// UnsignedByte is unsignedByte: 0…255 (8 bit).
+	UnsignedByte rdf.IRI = "http://www.w3.org/2001/XMLSchema#unsignedByte"
+
+	// UnsignedInt is unsignedInt: 0…4294967295 (32 bit).
+	UnsignedInt rdf.IRI = "http://www.w3.org/2001/XMLSchema#unsignedInt"
+
+	// UnsignedLong is unsignedLong: 0…18446744073709551615 (64 bit).
+	UnsignedLong rdf.IRI = "http://www.w3.org/2001/XMLSchema#unsignedLong"
+
+	// UnsignedShort is unsignedShort: 0…65535 (16 bit).
+	UnsignedShort rdf.IRI = "http://www.w3.org/2001/XMLSchema#unsignedShort"
+
+	// YearMonthDuration is yearMonthDuration: Duration of time (months and
+	// years only).
+	YearMonthDuration rdf.IRI = "http://www.w3.org/2001/XMLSchema#yearMonthDuration"
+)
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/b1scuit/solid/solid/server"
+)
+
+// runServe serves a directory as a Solid pod, for trying apps out
+// offline. Requests are authenticated with the -token access tokens
+func runServe(args []string) int {
+	tokens := map[string]string{}
+
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:3000", "Address to listen on")
+	base := fs.String("base", "", "URL of the pod, by default http:// and the address")
+	owner := fs.String("owner", "", "WebID given control of the pod when its root has no ACL")
+	fs.Func("token", "Accept an access token for a WebID as token=WebID, can be repeated", func(s string) error {
+		token, webID, ok := cutGraphFlag(s)
+		if !ok {
+			return fmt.Errorf("token should be token=WebID")
+		}
+
+		tokens[token] = webID
+
+		return nil
+	})
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: solid serve [-addr host:port] [-base URL] [-owner WebID] [-token token=WebID] [directory]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	root := "."
+	if fs.NArg() > 0 {
+		root = fs.Arg(0)
+	}
+
+	if *base == "" {
+		*base = "http://" + *addr
+	}
+
+	opts := []server.ServerOption{
+		server.WithBaseURL(*base),
+		server.WithAuthenticator(server.Tokens(tokens)),
+	}
+
+	if *owner != "" {
+		opts = append(opts, server.WithOwner(*owner))
+	}
+
+	s, err := server.New(root, opts...)
+	if err != nil {
+		slog.Error("Error opening pod", slog.String("directory", root), slog.Any("error", err))
+		return 2
+	}
+
+	slog.Info("Serving Solid pod", slog.String("address", *addr), slog.String("url", *base), slog.String("directory", root))
+
+	if err := http.ListenAndServe(*addr, s); err != nil {
+		slog.Error("Error serving", slog.Any("error", err))
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,241 @@
+// Package access reads, evaluates and edits who can do what to the
+// resources of a Solid pod, the same way whether the pod uses Web Access
+// Control or Access Control Policies
+package access
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/b1scuit/solid/rdf"
+	vocab "github.com/b1scuit/solid/rdf/vocab/acp"
+	"github.com/b1scuit/solid/solid"
+	"github.com/b1scuit/solid/solid/acp"
+	"github.com/b1scuit/solid/solid/wac"
+)
+
+// A System is the access control a pod uses
+type System string
+
+const (
+	WAC System = "WAC"
+	ACP System = "ACP"
+)
+
+// A Request is who is asking for access. Systems use what they understand
+// of it, WAC the agent, origin and groups and ACP the agent, client, issuer
+// and credentials
+type Request struct {
+	// Agent is the WebID of the agent, empty when they haven't signed in
+	Agent string
+
+	// Origin is the request's Origin header
+	Origin string
+
+	// Client is the client ID of the application making the request
+	Client string
+
+	// Issuer is the OIDC issuer the agent signed in with
+	Issuer string
+
+	// VCs are the types of the verifiable credentials presented
+	VCs []rdf.IRI
+
+	// Groups reads the members of WAC groups
+	Groups wac.GroupLoader
+}
+
+func (r Request) wac() wac.Request {
+	return wac.Request{Agent: r.Agent, Origin: r.Origin, Groups: r.Groups}
+}
+
+func (r Request) acp() acp.Context {
+	return acp.Context{Agent: r.Agent, Client: r.Client, Issuer: r.Issuer, VCs: r.VCs}
+}
+
+// Controls is a resource's own access control document, a WAC ACL or an
+// ACP Access Control Resource. Grants are named so they can be changed
+// and removed later, as authorizations or policies with the name as their
+// fragment
+type Controls interface {
+	// System is the access control the document is written for
+	System() System
+
+	// Document is the URL of the document
+	Document() string
+
+	// Modes returns the modes the document alone grants on the resource
+	// or, for a container, on something in it
+	Modes(ctx context.Context, resource string, req Request) ([]rdf.IRI, error)
+
+	// Grant gives the agent the modes, on the resource and what a
+	// container contains
+	Grant(name, webID string, modes ...rdf.IRI)
+
+	// Public gives anyone the modes
+	Public(name string, modes ...rdf.IRI)
+
+	// Remove drops the named grant, reporting whether it was there
+	Remove(name string) bool
+
+	Graph() *rdf.Graph
+	Turtle(w io.Writer) error
+}
+
+// FromACL makes controls of a WAC ACL, edits changing the ACL
+func FromACL(a *wac.ACL) Controls {
+	return wacControls{a}
+}
+
+// FromACR makes controls of an ACP Access Control Resource, edits changing
+// the ACR
+func FromACR(a *acp.ACR) Controls {
+	return acpControls{a}
+}
+
+type wacControls struct{ a *wac.ACL }
+
+func (w wacControls) System() System   { return WAC }
+func (w wacControls) Document() string { return w.a.URL }
+
+func (w wacControls) Modes(ctx context.Context, resource string, req Request) ([]rdf.IRI, error) {
+	return w.a.Modes(ctx, resource, req.wac())
+}
+
+func (w wacControls) Grant(name, webID string, modes ...rdf.IRI) { w.a.Grant(name, webID, modes...) }
+func (w wacControls) Public(name string, modes ...rdf.IRI)       { w.a.Public(name, modes...) }
+func (w wacControls) Remove(name string) bool                    { return w.a.Remove(rdf.IRI(w.a.URL + "#" + name)) }
+func (w wacControls) Graph() *rdf.Graph                          { return w.a.Graph() }
+func (w wacControls) Turtle(out io.Writer) error                 { return w.a.Turtle(out) }
+
+type acpControls struct{ a *acp.ACR }
+
+func (p acpControls) System() System   { return ACP }
+func (p acpControls) Document() string { return p.a.URL }
+
+func (p acpControls) Modes(ctx context.Context, resource string, req Request) ([]rdf.IRI, error) {
+	return p.a.Modes(resource, req.acp()), nil
+}
+
+func (p acpControls) Grant(name, webID string, modes ...rdf.IRI) { p.a.Grant(name, webID, modes...) }
+func (p acpControls) Public(name string, modes ...rdf.IRI)       { p.a.Public(name, modes...) }
+func (p acpControls) Remove(name string) bool                    { return p.a.Remove(rdf.IRI(p.a.URL + "#" + name)) }
+func (p acpControls) Graph() *rdf.Graph                          { return p.a.Graph() }
+func (p acpControls) Turtle(out io.Writer) error                 { return p.a.Turtle(out) }
+
+// Fetch reads a resource's own access control document, in whichever
+// system the pod uses. A document that doesn't exist yet is taken to be
+// an empty ACL, as ACP servers give every resource an ACR
+func Fetch(ctx context.Context, c *solid.Client, resource string) (Controls, error) {
+	link, doc, err := c.AccessControl(ctx, resource)
+	if err != nil {
+		return nil, err
+	}
+
+	if doc == nil {
+		return FromACL(&wac.ACL{URL: link, Resource: resource}), nil
+	}
+
+	if isACR(doc) {
+		return FromACR(acp.FromGraph(doc.Graph, link, resource)), nil
+	}
+
+	return FromACL(wac.FromGraph(doc.Graph, link, resource)), nil
+}
+
+// Save writes the controls to their document
+func Save(ctx context.Context, c *solid.Client, ctl Controls, opts ...solid.RequestOption) error {
+	_, err := c.Put(ctx, ctl.Document(), ctl.Graph(), opts...)
+	return err
+}
+
+// Detect finds the system governing a resource. A resource that doesn't
+// exist is governed by the system of the nearest container above it
+func Detect(ctx context.Context, c *solid.Client, resource string) (System, error) {
+	for r := resource; ; {
+		_, doc, err := c.AccessControl(ctx, r)
+
+		var e *solid.Error
+		if errors.As(err, &e) && e.StatusCode == http.StatusNotFound {
+			parent, ok := solid.ParentURL(r)
+			if !ok {
+				return "", err
+			}
+
+			r = parent
+			continue
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		if doc != nil && isACR(doc) {
+			return ACP, nil
+		}
+
+		return WAC, nil
+	}
+}
+
+// Modes returns the modes the request is granted on a resource, sorted,
+// following each system's inheritance: WAC's from the nearest ACL, ACP's
+// from the resource's ACR and its containers' member access controls
+func Modes(ctx context.Context, c *solid.Client, resource string, req Request) ([]rdf.IRI, error) {
+	system, err := Detect(ctx, c, resource)
+	if err != nil {
+		return nil, err
+	}
+
+	if system == ACP {
+		policies, err := acp.Effective(ctx, resource, acp.ClientLoader(c))
+		if err != nil {
+			return nil, err
+		}
+
+		return acp.Grants(policies, req.acp()), nil
+	}
+
+	a, err := wac.Effective(ctx, resource, wac.ClientLoader(c))
+	if err != nil {
+		return nil, err
+	}
+
+	return a.Modes(ctx, resource, req.wac())
+}
+
+// Allows checks the request has the mode on a resource
+func Allows(ctx context.Context, c *solid.Client, resource string, req Request, mode rdf.IRI) (bool, error) {
+	modes, err := Modes(ctx, c, resource, req)
+	if err != nil {
+		return false, err
+	}
+
+	for _, m := range modes {
+		if m == mode {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// isACR checks whether an access control document is an ACP Access
+// Control Resource, by the type it is linked to or what it says
+func isACR(doc *solid.Resource) bool {
+	for _, t := range doc.Types() {
+		if t == vocab.AccessControlResource {
+			return true
+		}
+	}
+
+	for _, p := range []rdf.Term{vocab.AccessControlProperty, vocab.MemberAccessControl} {
+		if len(doc.Graph.Match(nil, p, nil)) > 0 {
+			return true
+		}
+	}
+
+	return len(doc.Graph.Match(nil, rdf.RDFType, vocab.AccessControlResource)) > 0
+}
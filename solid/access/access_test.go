@@ -0,0 +1,170 @@
+package access
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/vocab/acl"
+	"github.com/b1scuit/solid/solid"
+)
+
+const (
+	alice = "https://alice.example/#me"
+	bob   = "https://bob.example/#me"
+)
+
+// pod serves resources linking to their access control documents, ending
+// in .acl for WAC or ?ext=acr for ACP, and stores documents that are put
+type pod struct {
+	system System
+
+	mu   sync.Mutex
+	docs map[string]string
+}
+
+func newPod(t *testing.T, system System, docs map[string]string) *httptest.Server {
+	p := &pod{system: system, docs: docs}
+
+	s := httptest.NewServer(p)
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (p *pod) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	target := r.URL.RequestURI()
+
+	if r.Method == http.MethodPut {
+		b, _ := io.ReadAll(r.Body)
+		p.docs[target] = string(b)
+		w.WriteHeader(http.StatusCreated)
+
+		return
+	}
+
+	doc, ok := p.docs[target]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case p.system == ACP && strings.HasSuffix(target, "?ext=acr"):
+		w.Header().Set("Link", `<http://www.w3.org/ns/solid/acp#AccessControlResource>; rel="type"`)
+	case p.system == ACP:
+		w.Header().Set("Link", fmt.Sprintf(`<%v?ext=acr>; rel="acl"`, r.URL.Path))
+	case !strings.HasSuffix(target, ".acl"):
+		w.Header().Set("Link", fmt.Sprintf(`<%v.acl>; rel="acl"`, r.URL.Path))
+	}
+
+	w.Header().Set("Content-Type", "text/turtle")
+	io.WriteString(w, doc)
+}
+
+var wacDocs = map[string]string{
+	"/":      "",
+	"/c/":    "",
+	"/c/doc": "",
+	"/.acl": `@prefix acl: <http://www.w3.org/ns/auth/acl#> .
+		<#owner> a acl:Authorization ; acl:agent <https://alice.example/#me> ;
+			acl:accessTo </> ; acl:default </> ; acl:mode acl:Read, acl:Write, acl:Control .`,
+}
+
+var acpDocs = map[string]string{
+	"/":              "",
+	"/c/":            "",
+	"/c/doc":         "",
+	"/c/?ext=acr":    "",
+	"/c/doc?ext=acr": "",
+	"/?ext=acr": `@prefix acp: <http://www.w3.org/ns/solid/acp#> .
+		@prefix acl: <http://www.w3.org/ns/auth/acl#> .
+		<> acp:resource </> ; acp:accessControl <#ac> ; acp:memberAccessControl <#ac> .
+		<#ac> acp:apply <#owner> .
+		<#owner> acp:allOf <#alice> ; acp:allow acl:Read, acl:Write, acl:Control .
+		<#alice> acp:agent <https://alice.example/#me> .`,
+}
+
+type systemTest struct {
+	Name     string
+	System   System
+	Docs     map[string]string
+	Document string
+}
+
+var systemTests = []systemTest{
+	{"WAC", WAC, wacDocs, "/c/.acl"},
+	{"ACP", ACP, acpDocs, "/c/?ext=acr"},
+}
+
+func TestAccess(t *testing.T) {
+	ctx := context.Background()
+	c := solid.MustNew()
+
+	for _, tc := range systemTests {
+		docs := map[string]string{}
+		for k, v := range tc.Docs {
+			docs[k] = v
+		}
+
+		s := newPod(t, tc.System, docs)
+		doc := s.URL + "/c/doc"
+
+		if system, err := Detect(ctx, c, s.URL+"/c/new"); system != tc.System || err != nil {
+			t.Errorf("%v test fail: detected %v, %v", tc.Name, system, err)
+		}
+
+		// The owner's access comes down from the root
+		if modes, err := Modes(ctx, c, doc, Request{Agent: alice}); err != nil || !reflect.DeepEqual(modes, []rdf.IRI{acl.Append, acl.Control, acl.Read, acl.Write}) {
+			t.Errorf("%v test fail: owner got %v, %v", tc.Name, modes, err)
+		}
+
+		if ok, err := Allows(ctx, c, doc, Request{Agent: bob}, acl.Read); ok || err != nil {
+			t.Errorf("%v test fail: bob allowed, %v", tc.Name, err)
+		}
+
+		ctl, err := Fetch(ctx, c, s.URL+"/c/")
+		if err != nil || ctl.System() != tc.System || ctl.Document() != s.URL+tc.Document {
+			t.Fatalf("%v test fail: fetched %v, %v", tc.Name, ctl, err)
+		}
+
+		ctl.Grant("owner", alice, acl.Read, acl.Write, acl.Control)
+		ctl.Grant("bob", bob, acl.Read)
+		ctl.Public("public", acl.Append)
+
+		if modes, _ := ctl.Modes(ctx, doc, Request{Agent: bob}); !reflect.DeepEqual(modes, []rdf.IRI{acl.Append, acl.Read}) {
+			t.Errorf("%v test fail: bob got %v", tc.Name, modes)
+		}
+
+		if err := Save(ctx, c, ctl); err != nil {
+			t.Fatalf("%v test fail: %v", tc.Name, err)
+		}
+
+		if ok, err := Allows(ctx, c, doc, Request{Agent: bob}, acl.Read); !ok || err != nil {
+			t.Errorf("%v test fail: bob refused after saving, %v", tc.Name, err)
+		}
+
+		if ok, err := Allows(ctx, c, doc, Request{}, acl.Append); !ok || err != nil {
+			t.Errorf("%v test fail: public refused after saving, %v", tc.Name, err)
+		}
+
+		ctl, _ = Fetch(ctx, c, s.URL+"/c/")
+		if !ctl.Remove("bob") || ctl.Remove("bob") || Save(ctx, c, ctl) != nil {
+			t.Fatalf("%v test fail: removing", tc.Name)
+		}
+
+		if ok, err := Allows(ctx, c, doc, Request{Agent: bob}, acl.Read); ok || err != nil {
+			t.Errorf("%v test fail: bob allowed after removing, %v", tc.Name, err)
+		}
+	}
+}
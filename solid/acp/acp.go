@@ -0,0 +1,402 @@
+// Package acp reads, evaluates and edits Access Control Policies, the
+// Access Control Resources some Solid servers use in place of WAC's .acl
+// documents
+//
+// https://solidproject.org/TR/acp
+package acp
+
+import (
+	"io"
+	"sort"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/parser"
+	"github.com/b1scuit/solid/rdf/serializer"
+	"github.com/b1scuit/solid/rdf/vocab/acl"
+	vocab "github.com/b1scuit/solid/rdf/vocab/acp"
+	"github.com/b1scuit/solid/solid"
+)
+
+// A Matcher is satisfied by a context that matches one of the values of
+// each attribute it has
+type Matcher struct {
+	ID rdf.Term
+
+	Agents  []rdf.IRI
+	Clients []rdf.IRI
+	Issuers []rdf.IRI
+	VCs     []rdf.IRI
+}
+
+// A Policy allows and denies modes when its matchers are satisfied
+type Policy struct {
+	ID rdf.Term
+
+	Allow []rdf.IRI
+	Deny  []rdf.IRI
+
+	AllOf  []*Matcher
+	AnyOf  []*Matcher
+	NoneOf []*Matcher
+}
+
+// An AccessControl applies policies
+type AccessControl struct {
+	ID    rdf.Term
+	Apply []*Policy
+}
+
+// An ACR is the Access Control Resource at URL for Resource.
+// AccessControls apply to the resource, MemberAccessControls to what it
+// contains when it is a container
+type ACR struct {
+	URL      string
+	Resource string
+
+	AccessControls       []*AccessControl
+	MemberAccessControls []*AccessControl
+}
+
+// Parse reads an Access Control Resource, relative IRIs resolved against
+// its URL
+func Parse(r io.Reader, url, resource string) (*ACR, error) {
+	p, err := parser.New(parser.WithBase(url))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.Do(r); err != nil {
+		return nil, err
+	}
+
+	return FromGraph(p.GetGraph(), url, resource), nil
+}
+
+// FromGraph reads the access controls in an ACR's graph, from the ACR
+// itself or whatever node says it is about the resource. Policies and
+// matchers only described in other documents are empty
+func FromGraph(g *rdf.Graph, url, resource string) *ACR {
+	a := &ACR{URL: url, Resource: resource}
+	r := reader{g: g, policies: map[string]*Policy{}, matchers: map[string]*Matcher{}}
+
+	subjects := []rdf.Term{rdf.IRI(url)}
+	for _, t := range g.Match(nil, vocab.Resource, rdf.IRI(resource)) {
+		if !rdf.Equal(t.Subject, rdf.IRI(url)) {
+			subjects = append(subjects, t.Subject)
+		}
+	}
+
+	for _, s := range subjects {
+		a.AccessControls = append(a.AccessControls, r.accessControls(s, vocab.AccessControlProperty)...)
+		a.MemberAccessControls = append(a.MemberAccessControls, r.accessControls(s, vocab.MemberAccessControl)...)
+	}
+
+	return a
+}
+
+// reader shares the policies and matchers several access controls use
+type reader struct {
+	g        *rdf.Graph
+	policies map[string]*Policy
+	matchers map[string]*Matcher
+}
+
+func (r *reader) accessControls(s rdf.Term, p rdf.IRI) []*AccessControl {
+	var acs []*AccessControl
+
+	for _, t := range r.g.Match(s, p, nil) {
+		ac := &AccessControl{ID: t.Object}
+
+		for _, apply := range r.g.Match(t.Object, vocab.Apply, nil) {
+			ac.Apply = append(ac.Apply, r.policy(apply.Object))
+		}
+
+		acs = append(acs, ac)
+	}
+
+	return acs
+}
+
+func (r *reader) policy(id rdf.Term) *Policy {
+	if p, ok := r.policies[id.String()]; ok {
+		return p
+	}
+
+	p := &Policy{ID: id}
+	r.policies[id.String()] = p
+
+	p.Allow = r.iris(id, vocab.Allow)
+	p.Deny = r.iris(id, vocab.Deny)
+
+	for _, f := range []struct {
+		predicate rdf.IRI
+		matchers  *[]*Matcher
+	}{
+		{vocab.AllOf, &p.AllOf},
+		{vocab.AnyOf, &p.AnyOf},
+		{vocab.NoneOf, &p.NoneOf},
+	} {
+		for _, t := range r.g.Match(id, f.predicate, nil) {
+			*f.matchers = append(*f.matchers, r.matcher(t.Object))
+		}
+	}
+
+	return p
+}
+
+func (r *reader) matcher(id rdf.Term) *Matcher {
+	if m, ok := r.matchers[id.String()]; ok {
+		return m
+	}
+
+	m := &Matcher{
+		ID:      id,
+		Agents:  r.iris(id, vocab.Agent),
+		Clients: r.iris(id, vocab.Client),
+		Issuers: r.iris(id, vocab.Issuer),
+		VCs:     r.iris(id, vocab.Vc),
+	}
+
+	r.matchers[id.String()] = m
+
+	return m
+}
+
+func (r *reader) iris(s rdf.Term, p rdf.IRI) []rdf.IRI {
+	var values []rdf.IRI
+	for _, t := range r.g.Match(s, p, nil) {
+		if iri, ok := t.Object.(rdf.IRI); ok {
+			values = addIRI(values, iri)
+		}
+	}
+
+	return values
+}
+
+// Graph writes the ACR as RDF
+func (a *ACR) Graph() *rdf.Graph {
+	g := rdf.NewGraph()
+	acr := rdf.IRI(a.URL)
+
+	g.Add(
+		rdf.NewTriple(acr, rdf.RDFType, vocab.AccessControlResource),
+		rdf.NewTriple(acr, vocab.Resource, rdf.IRI(a.Resource)),
+	)
+
+	for _, f := range []struct {
+		predicate rdf.IRI
+		acs       []*AccessControl
+	}{
+		{vocab.AccessControlProperty, a.AccessControls},
+		{vocab.MemberAccessControl, a.MemberAccessControls},
+	} {
+		for _, ac := range f.acs {
+			g.Add(
+				rdf.NewTriple(acr, f.predicate, ac.ID),
+				rdf.NewTriple(ac.ID, rdf.RDFType, vocab.AccessControl),
+			)
+
+			for _, p := range ac.Apply {
+				g.Add(rdf.NewTriple(ac.ID, vocab.Apply, p.ID))
+				p.write(g)
+			}
+		}
+	}
+
+	return g
+}
+
+func (p *Policy) write(g *rdf.Graph) {
+	g.Add(rdf.NewTriple(p.ID, rdf.RDFType, vocab.Policy))
+
+	for _, m := range p.Allow {
+		g.Add(rdf.NewTriple(p.ID, vocab.Allow, m))
+	}
+
+	for _, m := range p.Deny {
+		g.Add(rdf.NewTriple(p.ID, vocab.Deny, m))
+	}
+
+	for _, f := range []struct {
+		predicate rdf.IRI
+		matchers  []*Matcher
+	}{
+		{vocab.AllOf, p.AllOf},
+		{vocab.AnyOf, p.AnyOf},
+		{vocab.NoneOf, p.NoneOf},
+	} {
+		for _, m := range f.matchers {
+			g.Add(
+				rdf.NewTriple(p.ID, f.predicate, m.ID),
+				rdf.NewTriple(m.ID, rdf.RDFType, vocab.Matcher),
+			)
+
+			for _, v := range []struct {
+				predicate rdf.IRI
+				values    []rdf.IRI
+			}{
+				{vocab.Agent, m.Agents},
+				{vocab.Client, m.Clients},
+				{vocab.Issuer, m.Issuers},
+				{vocab.Vc, m.VCs},
+			} {
+				for _, iri := range v.values {
+					g.Add(rdf.NewTriple(m.ID, v.predicate, iri))
+				}
+			}
+		}
+	}
+}
+
+// Turtle writes the ACR as a Turtle document
+func (a *ACR) Turtle(w io.Writer) error {
+	prefixes := rdf.PrefixMap{"acp": vocab.Namespace, "acl": acl.Namespace}
+
+	return serializer.MustNew(serializer.WithPrefixes(prefixes)).Turtle(w, a.Graph())
+}
+
+// Policies returns every policy the ACR applies, each once
+func (a *ACR) Policies() []*Policy {
+	var policies []*Policy
+	seen := map[*Policy]bool{}
+
+	for _, ac := range append(append([]*AccessControl{}, a.AccessControls...), a.MemberAccessControls...) {
+		for _, p := range ac.Apply {
+			if !seen[p] {
+				seen[p] = true
+				policies = append(policies, p)
+			}
+		}
+	}
+
+	return policies
+}
+
+// Policy returns the policy with the fragment name in the document,
+// adding an empty one if there isn't one. New policies are applied to the
+// resource and, when it is a container, to what it contains
+func (a *ACR) Policy(name string) *Policy {
+	id := rdf.IRI(a.URL + "#" + name)
+
+	for _, p := range a.Policies() {
+		if rdf.Equal(p.ID, id) {
+			return p
+		}
+	}
+
+	p := &Policy{ID: id}
+
+	ac := a.accessControl(&a.AccessControls, "accessControl")
+	ac.Apply = append(ac.Apply, p)
+
+	if solid.IsContainerURL(a.Resource) {
+		ac := a.accessControl(&a.MemberAccessControls, "memberAccessControl")
+		ac.Apply = append(ac.Apply, p)
+	}
+
+	return p
+}
+
+// accessControl returns the access control with the fragment name, adding
+// it if there isn't one
+func (a *ACR) accessControl(acs *[]*AccessControl, name string) *AccessControl {
+	id := rdf.IRI(a.URL + "#" + name)
+
+	for _, ac := range *acs {
+		if rdf.Equal(ac.ID, id) {
+			return ac
+		}
+	}
+
+	ac := &AccessControl{ID: id}
+	*acs = append(*acs, ac)
+
+	return ac
+}
+
+// Remove stops applying the policy with the ID, reporting whether it was
+// applied
+func (a *ACR) Remove(id rdf.Term) bool {
+	removed := false
+
+	for _, ac := range append(append([]*AccessControl{}, a.AccessControls...), a.MemberAccessControls...) {
+		for i := 0; i < len(ac.Apply); i++ {
+			if rdf.Equal(ac.Apply[i].ID, id) {
+				ac.Apply = append(ac.Apply[:i], ac.Apply[i+1:]...)
+				removed = true
+				i--
+			}
+		}
+	}
+
+	return removed
+}
+
+// Owner makes an ACR giving the agent every mode on the resource and,
+// when it is a container, on what it contains
+func Owner(url, resource, webID string) *ACR {
+	a := &ACR{URL: url, Resource: resource}
+	a.Grant("owner", webID, acl.Read, acl.Write, acl.Control)
+
+	return a
+}
+
+// Grant allows the modes in the named policy for the agent. The agent is
+// added to the policy's matcher, named after it
+func (a *ACR) Grant(name, webID string, modes ...rdf.IRI) *Policy {
+	p := a.grant(name, modes)
+
+	m := p.allOf(rdf.IRI(a.URL + "#" + name + "Matcher"))
+	m.Agents = addIRI(m.Agents, rdf.IRI(webID))
+
+	return p
+}
+
+// Public allows the modes in the named policy for anyone, signed in or
+// not
+func (a *ACR) Public(name string, modes ...rdf.IRI) *Policy {
+	p := a.grant(name, modes)
+
+	m := p.allOf(rdf.IRI(a.URL + "#" + name + "Matcher"))
+	m.Agents = addIRI(m.Agents, vocab.PublicAgent)
+
+	return p
+}
+
+func (a *ACR) grant(name string, modes []rdf.IRI) *Policy {
+	p := a.Policy(name)
+
+	for _, m := range modes {
+		p.Allow = addIRI(p.Allow, m)
+	}
+
+	return p
+}
+
+// allOf returns the policy's all of matcher with the ID, adding it if
+// there isn't one
+func (p *Policy) allOf(id rdf.IRI) *Matcher {
+	for _, m := range p.AllOf {
+		if rdf.Equal(m.ID, id) {
+			return m
+		}
+	}
+
+	m := &Matcher{ID: id}
+	p.AllOf = append(p.AllOf, m)
+
+	return m
+}
+
+func addIRI(iris []rdf.IRI, iri rdf.IRI) []rdf.IRI {
+	for _, v := range iris {
+		if v == iri {
+			return iris
+		}
+	}
+
+	iris = append(iris, iri)
+	sort.Slice(iris, func(i, j int) bool { return iris[i] < iris[j] })
+
+	return iris
+}
@@ -0,0 +1,219 @@
+package acp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/vocab/acl"
+	vocab "github.com/b1scuit/solid/rdf/vocab/acp"
+)
+
+const testACR = `
+@prefix acp: <http://www.w3.org/ns/solid/acp#> .
+@prefix acl: <http://www.w3.org/ns/auth/acl#> .
+
+<> a acp:AccessControlResource ;
+	acp:resource <./> ;
+	acp:accessControl <#own>, <#ownEmpty> ;
+	acp:memberAccessControl <#members> .
+
+<#own> acp:apply <#owner> .
+<#ownEmpty> acp:apply <#nothing> .
+<#members> acp:apply <#owner>, <#app>, <#credential>, <#bob>, <#block> .
+
+<#owner> a acp:Policy ;
+	acp:allOf <#alice> ;
+	acp:allow acl:Read, acl:Write, acl:Control .
+
+<#app> a acp:Policy ;
+	acp:allOf <#signedIn> ;
+	acp:anyOf <#viaApp>, <#viaIssuer> ;
+	acp:allow acl:Read .
+
+<#credential> a acp:Policy ;
+	acp:allOf <#holder> ;
+	acp:allow acl:Append .
+
+<#bob> a acp:Policy ;
+	acp:allOf <#bobMatcher> ;
+	acp:allow acl:Write .
+
+<#block> a acp:Policy ;
+	acp:allOf <#anyone> ;
+	acp:noneOf <#alice> ;
+	acp:deny acl:Write .
+
+<#nothing> a acp:Policy ;
+	acp:allow acl:Read .
+
+<#alice> a acp:Matcher ; acp:agent <https://alice.example/#me> .
+<#bobMatcher> a acp:Matcher ; acp:agent <https://bob.example/#me> .
+<#signedIn> a acp:Matcher ; acp:agent acp:AuthenticatedAgent .
+<#anyone> a acp:Matcher ; acp:agent acp:PublicAgent .
+<#viaApp> a acp:Matcher ; acp:client <https://app.example/id> .
+<#viaIssuer> a acp:Matcher ; acp:issuer <https://idp.example> .
+<#holder> a acp:Matcher ; acp:vc <https://vc.example/Member> ; acp:issuer acp:PublicIssuer .
+`
+
+const (
+	alice = "https://alice.example/#me"
+	bob   = "https://bob.example/#me"
+	carol = "https://carol.example/#me"
+)
+
+type modesTest struct {
+	Name     string
+	Resource string
+	Context  Context
+	Expected []rdf.IRI
+}
+
+var modesTests = []modesTest{
+	{"Owner of the container", "https://pod.example/c/", Context{Agent: alice},
+		[]rdf.IRI{acl.Append, acl.Control, acl.Read, acl.Write}},
+	{"Owner inside the container", "https://pod.example/c/d/doc", Context{Agent: alice},
+		[]rdf.IRI{acl.Append, acl.Control, acl.Read, acl.Write}},
+	{"Anyone", "https://pod.example/c/doc", Context{},
+		nil},
+	{"Signed in without the app or issuer", "https://pod.example/c/doc", Context{Agent: carol, Client: "https://other.example/id"},
+		nil},
+	{"Signed in with the app", "https://pod.example/c/doc", Context{Agent: carol, Client: "https://app.example/id"},
+		[]rdf.IRI{acl.Read}},
+	{"Signed in with the issuer", "https://pod.example/c/doc", Context{Agent: carol, Issuer: "https://idp.example"},
+		[]rdf.IRI{acl.Read}},
+	{"App without signing in", "https://pod.example/c/doc", Context{Client: "https://app.example/id"},
+		nil},
+	{"Holding the credential", "https://pod.example/c/doc", Context{VCs: []rdf.IRI{"https://vc.example/Member"}},
+		[]rdf.IRI{acl.Append}},
+	{"Write denied", "https://pod.example/c/doc", Context{Agent: bob},
+		nil},
+	{"Members' policies on the container", "https://pod.example/c/", Context{Agent: carol, Client: "https://app.example/id"},
+		nil},
+	{"Outside the container", "https://pod.example/other", Context{Agent: alice},
+		nil},
+}
+
+func testACRFor(t *testing.T) *ACR {
+	a, err := Parse(strings.NewReader(testACR), "https://pod.example/c/?ext=acr", "https://pod.example/c/")
+	if err != nil {
+		t.Fatalf("parsing test ACR: %v", err)
+	}
+
+	return a
+}
+
+func TestModes(t *testing.T) {
+	a := testACRFor(t)
+
+	// The owner policy both access controls apply is read once
+	if len(a.AccessControls) != 2 || len(a.MemberAccessControls) != 1 || len(a.Policies()) != 6 {
+		t.Fatalf("parse test fail: %v, %v, %v", a.AccessControls, a.MemberAccessControls, a.Policies())
+	}
+
+	for _, tc := range modesTests {
+		if modes := a.Modes(tc.Resource, tc.Context); !reflect.DeepEqual(modes, tc.Expected) {
+			t.Errorf("%v test fail: got %v", tc.Name, modes)
+		}
+	}
+}
+
+type matcherTest struct {
+	Name     string
+	Matcher  Matcher
+	Context  Context
+	Expected bool
+}
+
+var matcherTests = []matcherTest{
+	{"Empty", Matcher{}, Context{Agent: alice}, false},
+	{"Creator", Matcher{Agents: []rdf.IRI{vocab.CreatorAgent}}, Context{Agent: alice, Creator: alice}, true},
+	{"Not the creator", Matcher{Agents: []rdf.IRI{vocab.CreatorAgent}}, Context{Agent: bob, Creator: alice}, false},
+	{"Anonymous creator", Matcher{Agents: []rdf.IRI{vocab.CreatorAgent}}, Context{}, false},
+	{"Owner", Matcher{Agents: []rdf.IRI{vocab.OwnerAgent}}, Context{Agent: bob, Owners: []string{alice, bob}}, true},
+	{"Every attribute", Matcher{Agents: []rdf.IRI{vocab.PublicAgent}, Clients: []rdf.IRI{vocab.PublicClient}, Issuers: []rdf.IRI{"https://idp.example"}},
+		Context{Issuer: "https://idp.example"}, true},
+	{"One attribute missed", Matcher{Agents: []rdf.IRI{vocab.PublicAgent}, Clients: []rdf.IRI{"https://app.example/id"}},
+		Context{}, false},
+}
+
+func TestMatchers(t *testing.T) {
+	for _, tc := range matcherTests {
+		if ok := tc.Matcher.Satisfied(tc.Context); ok != tc.Expected {
+			t.Errorf("%v test fail: got %v", tc.Name, ok)
+		}
+	}
+}
+
+func TestEffective(t *testing.T) {
+	root := Owner("https://pod.example/?ext=acr", "https://pod.example/", alice)
+	c := testACRFor(t)
+
+	doc := &ACR{URL: "https://pod.example/c/doc?ext=acr", Resource: "https://pod.example/c/doc"}
+	doc.Grant("carol", carol, acl.Read)
+
+	acrs := map[string]*ACR{root.Resource: root, c.Resource: c, doc.Resource: doc}
+
+	load := func(ctx context.Context, resource string) (*ACR, error) {
+		return acrs[resource], nil
+	}
+
+	// Everything above a resource adds to what it gets
+	for _, tc := range []modesTest{
+		{"Own policy", doc.Resource, Context{Agent: carol}, []rdf.IRI{acl.Read}},
+		{"Root's member policy", doc.Resource, Context{Agent: alice}, []rdf.IRI{acl.Append, acl.Control, acl.Read, acl.Write}},
+		{"Container's member policy", doc.Resource, Context{VCs: []rdf.IRI{"https://vc.example/Member"}}, []rdf.IRI{acl.Append}},
+		{"Resource without an ACR", "https://pod.example/c/new", Context{Agent: carol}, nil},
+	} {
+		policies, err := Effective(context.Background(), tc.Resource, load)
+		if modes := Grants(policies, tc.Context); err != nil || !reflect.DeepEqual(modes, tc.Expected) {
+			t.Errorf("%v test fail: got %v, %v", tc.Name, modes, err)
+		}
+	}
+
+	if _, err := Effective(context.Background(), "https://other.example/doc", load); !errors.Is(err, ErrNoACR) {
+		t.Errorf("effective test fail: got %v", err)
+	}
+}
+
+func TestEdit(t *testing.T) {
+	a := Owner("https://pod.example/c/?ext=acr", "https://pod.example/c/", alice)
+	a.Public("public", acl.Read)
+	a.Grant("bob", bob, acl.Read)
+	a.Grant("bob", bob, acl.Append)
+
+	if len(a.AccessControls) != 1 || len(a.MemberAccessControls) != 1 || len(a.Policies()) != 3 {
+		t.Fatalf("edit test fail: %v, %v", a.AccessControls, a.MemberAccessControls)
+	}
+
+	if modes := a.Modes("https://pod.example/c/doc", Context{Agent: bob}); !reflect.DeepEqual(modes, []rdf.IRI{acl.Append, acl.Read}) {
+		t.Errorf("edit test fail: got %v", modes)
+	}
+
+	var buf bytes.Buffer
+	if err := a.Turtle(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), "@prefix acp:") {
+		t.Errorf("edit test fail: wrote %v", buf.String())
+	}
+
+	// Written out and read back it is the same ACR
+	b, err := Parse(&buf, a.URL, a.Resource)
+	if err != nil || !rdf.Isomorphic(a.Graph(), b.Graph()) {
+		t.Errorf("edit test fail: read back %v, %v", b, err)
+	}
+
+	if !b.Remove(rdf.IRI(a.URL+"#bob")) || b.Remove(rdf.IRI(a.URL+"#bob")) {
+		t.Errorf("remove test fail")
+	}
+
+	if modes := b.Modes("https://pod.example/c/doc", Context{Agent: bob}); !reflect.DeepEqual(modes, []rdf.IRI{acl.Read}) {
+		t.Errorf("remove test fail: got %v", modes)
+	}
+}
@@ -0,0 +1,263 @@
+package acp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/vocab/acl"
+	vocab "github.com/b1scuit/solid/rdf/vocab/acp"
+	"github.com/b1scuit/solid/solid"
+)
+
+var ErrNoACR = errors.New("acp: no access control resource applies")
+
+// A Context is who is asking for access, and with what
+type Context struct {
+	// Agent is the WebID of the agent, empty when they haven't signed in
+	Agent string
+
+	// Client is the client ID of the application making the request
+	Client string
+
+	// Issuer is the OIDC issuer the agent signed in with
+	Issuer string
+
+	// VCs are the types of the verifiable credentials presented
+	VCs []rdf.IRI
+
+	// Creator is the agent that created the resource, and Owners those of
+	// the storage it is in, for acp:CreatorAgent and acp:OwnerAgent
+	Creator string
+	Owners  []string
+}
+
+// Satisfied checks the context matches one of the values of each of the
+// matcher's attributes. A matcher with no attributes is never satisfied
+func (m *Matcher) Satisfied(cx Context) bool {
+	if len(m.Agents)+len(m.Clients)+len(m.Issuers)+len(m.VCs) == 0 {
+		return false
+	}
+
+	if len(m.Agents) > 0 && !anyIRI(m.Agents, cx.agent) {
+		return false
+	}
+
+	if len(m.Clients) > 0 && !anyIRI(m.Clients, func(v rdf.IRI) bool {
+		return v == vocab.PublicClient || (cx.Client != "" && v == rdf.IRI(cx.Client))
+	}) {
+		return false
+	}
+
+	if len(m.Issuers) > 0 && !anyIRI(m.Issuers, func(v rdf.IRI) bool {
+		return v == vocab.PublicIssuer || (cx.Issuer != "" && v == rdf.IRI(cx.Issuer))
+	}) {
+		return false
+	}
+
+	if len(m.VCs) > 0 && !anyIRI(m.VCs, func(v rdf.IRI) bool { return hasIRI(cx.VCs, v) }) {
+		return false
+	}
+
+	return true
+}
+
+func (cx Context) agent(v rdf.IRI) bool {
+	switch {
+	case v == vocab.PublicAgent:
+		return true
+	case cx.Agent == "":
+		return false
+	case v == vocab.AuthenticatedAgent:
+		return true
+	case v == vocab.CreatorAgent:
+		return cx.Agent == cx.Creator
+	case v == vocab.OwnerAgent:
+		for _, o := range cx.Owners {
+			if o == cx.Agent {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return v == rdf.IRI(cx.Agent)
+}
+
+// Satisfied checks every all of matcher, at least one any of matcher and
+// none of the none of matchers are satisfied. A policy with neither all
+// of nor any of matchers is never satisfied
+func (p *Policy) Satisfied(cx Context) bool {
+	if len(p.AllOf) == 0 && len(p.AnyOf) == 0 {
+		return false
+	}
+
+	for _, m := range p.AllOf {
+		if !m.Satisfied(cx) {
+			return false
+		}
+	}
+
+	if len(p.AnyOf) > 0 {
+		ok := false
+		for _, m := range p.AnyOf {
+			ok = ok || m.Satisfied(cx)
+		}
+
+		if !ok {
+			return false
+		}
+	}
+
+	for _, m := range p.NoneOf {
+		if m.Satisfied(cx) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Grants returns the modes the policies grant the context, sorted: those
+// allowed by a satisfied policy that no satisfied policy denies. Write
+// that is granted grants Append as well
+func Grants(policies []*Policy, cx Context) []rdf.IRI {
+	var allowed, denied, modes []rdf.IRI
+
+	for _, p := range policies {
+		if p.Satisfied(cx) {
+			allowed = append(allowed, p.Allow...)
+			denied = append(denied, p.Deny...)
+		}
+	}
+
+	for _, m := range allowed {
+		if hasIRI(denied, m) {
+			continue
+		}
+
+		modes = addIRI(modes, m)
+
+		if m == acl.Write {
+			modes = addIRI(modes, acl.Append)
+		}
+	}
+
+	return modes
+}
+
+// Applied returns the policies the ACR applies to a resource: its access
+// controls' on its own resource, its member access controls' on anything
+// in its container
+func (a *ACR) Applied(resource string) []*Policy {
+	acs := a.AccessControls
+
+	if resource != a.Resource {
+		if !solid.IsContainerURL(a.Resource) || !strings.HasPrefix(resource, a.Resource) {
+			return nil
+		}
+
+		acs = a.MemberAccessControls
+	}
+
+	var policies []*Policy
+	for _, ac := range acs {
+		policies = append(policies, ac.Apply...)
+	}
+
+	return policies
+}
+
+// Modes returns the modes the ACR alone grants the context on the
+// resource
+func (a *ACR) Modes(resource string, cx Context) []rdf.IRI {
+	return Grants(a.Applied(resource), cx)
+}
+
+// A Loader reads a resource's ACR, or nil if it has none
+type Loader func(ctx context.Context, resource string) (*ACR, error)
+
+// Effective returns the policies governing a resource, those its own ACR
+// applies along with the member access controls of every container above
+// it
+func Effective(ctx context.Context, resource string, load Loader) ([]*Policy, error) {
+	var policies []*Policy
+	found := false
+
+	for r, ok := resource, true; ok; r, ok = solid.ParentURL(r) {
+		a, err := load(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+
+		if a != nil {
+			found = true
+			policies = append(policies, a.Applied(resource)...)
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("%w: %v", ErrNoACR, resource)
+	}
+
+	return policies, nil
+}
+
+// Fetch reads a resource's ACR from the document its Link header names.
+// When the document doesn't exist yet the ACR is empty, ready to be filled
+// in and saved
+func Fetch(ctx context.Context, c *solid.Client, resource string) (*ACR, error) {
+	link, doc, err := c.AccessControl(ctx, resource)
+	if err != nil {
+		return nil, err
+	}
+
+	if doc == nil {
+		return &ACR{URL: link, Resource: resource}, nil
+	}
+
+	return FromGraph(doc.Graph, link, resource), nil
+}
+
+// ClientLoader loads ACRs from a pod. A resource that doesn't exist has
+// no ACR, so only its containers' member access controls apply
+func ClientLoader(c *solid.Client) Loader {
+	return func(ctx context.Context, resource string) (*ACR, error) {
+		link, doc, err := c.AccessControl(ctx, resource)
+
+		var e *solid.Error
+		if errors.As(err, &e) && e.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+
+		if err != nil || doc == nil {
+			return nil, err
+		}
+
+		return FromGraph(doc.Graph, link, resource), nil
+	}
+}
+
+// Save writes the ACR to its document
+func Save(ctx context.Context, c *solid.Client, a *ACR, opts ...solid.RequestOption) error {
+	_, err := c.Put(ctx, a.URL, a.Graph(), opts...)
+	return err
+}
+
+func anyIRI(iris []rdf.IRI, f func(rdf.IRI) bool) bool {
+	for _, v := range iris {
+		if f(v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasIRI(iris []rdf.IRI, iri rdf.IRI) bool {
+	return anyIRI(iris, func(v rdf.IRI) bool { return v == iri })
+}
@@ -0,0 +1,411 @@
+// Package solid reads and writes the resources of a Solid pod over HTTP,
+// with RDF bodies parsed into and written from graphs
+//
+// https://solidproject.org/TR/protocol
+package solid
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/jsonld"
+	"github.com/b1scuit/solid/rdf/n3patch"
+	"github.com/b1scuit/solid/rdf/parser"
+	"github.com/b1scuit/solid/rdf/serializer"
+	"github.com/b1scuit/solid/rdf/vocab/ldp"
+)
+
+const (
+	mediaTypeTurtle       = "text/turtle"
+	mediaTypeNTriples     = "application/n-triples"
+	mediaTypeN3           = "text/n3"
+	mediaTypeJSONLD       = "application/ld+json"
+	mediaTypeSparqlUpdate = "application/sparql-update"
+
+	// Turtle is the format every Solid server has to offer, anything
+	// else comes back as bytes
+	acceptRDF = "text/turtle, application/n-triples;q=0.9, text/n3;q=0.8, application/ld+json;q=0.7, */*;q=0.1"
+
+	// How much of an error response is kept in the Error
+	maxErrorBody = 4 << 10
+)
+
+// An Error is a response outside 2xx
+type Error struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *Error) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("solid: %v %v: %v", e.Method, e.URL, e.Status)
+	}
+
+	return fmt.Sprintf("solid: %v %v: %v: %v", e.Method, e.URL, e.Status, e.Body)
+}
+
+type ClientOption func(*Client)
+
+// WithHTTPClient sets the client requests are made with, for timeouts or a
+// transport that authenticates them. It defaults to http.DefaultClient
+func WithHTTPClient(h *http.Client) ClientOption {
+	return func(c *Client) {
+		c.http = h
+	}
+}
+
+// WithPrefixes sets the prefixes used when writing Turtle
+func WithPrefixes(prefixes rdf.PrefixMap) ClientOption {
+	return func(c *Client) {
+		c.prefixes.Merge(prefixes)
+	}
+}
+
+// A RequestOption adds to a single request
+type RequestOption func(*http.Request)
+
+// IfMatch only makes the change if the resource still has the ETag, so
+// changes made since it was read aren't lost
+func IfMatch(etag string) RequestOption {
+	return func(r *http.Request) {
+		r.Header.Set("If-Match", etag)
+	}
+}
+
+// IfNoneMatch makes the change only if the resource doesn't have the
+// ETag, "*" only creates a resource that doesn't exist
+func IfNoneMatch(etag string) RequestOption {
+	return func(r *http.Request) {
+		r.Header.Set("If-None-Match", etag)
+	}
+}
+
+// Slug suggests the name of a resource made by Post
+func Slug(name string) RequestOption {
+	return func(r *http.Request) {
+		r.Header.Set("Slug", name)
+	}
+}
+
+// LinkType says what type of resource to create, such as
+// ldp:BasicContainer to Post a container
+func LinkType(t rdf.IRI) RequestOption {
+	return func(r *http.Request) {
+		r.Header.Add("Link", fmt.Sprintf("%v; rel=\"type\"", t))
+	}
+}
+
+// A Resource is what a response said about a resource. URL is where it
+// was found, after any redirects. RDF bodies are parsed into Graph and
+// others kept in Body
+type Resource struct {
+	URL         string
+	StatusCode  int
+	ContentType string
+	ETag        string
+	Links       []Link
+	Allow       WACAllow
+
+	Graph *rdf.Graph
+	Body  []byte
+}
+
+// Link returns the URLs linked with the relation
+func (r *Resource) Link(rel string) []string {
+	rel = relation(rel)
+
+	var urls []string
+	for _, l := range r.Links {
+		if l.Rel == rel {
+			urls = append(urls, l.URL)
+		}
+	}
+
+	return urls
+}
+
+// Types returns the types the server linked the resource to, such as
+// ldp:Resource and ldp:BasicContainer
+func (r *Resource) Types() []rdf.IRI {
+	var types []rdf.IRI
+	for _, u := range r.Link("type") {
+		types = append(types, rdf.IRI(u))
+	}
+
+	return types
+}
+
+// IsContainer checks whether the resource is an LDP container, by its type
+// or a URL ending in a slash
+func (r *Resource) IsContainer() bool {
+	for _, t := range r.Types() {
+		if t == ldp.Container || t == ldp.BasicContainer {
+			return true
+		}
+	}
+
+	return strings.HasSuffix(r.URL, "/")
+}
+
+// ACL returns the URL of the resource's access control document, or "" if
+// the server didn't link one
+func (r *Resource) ACL() string {
+	if urls := r.Link("acl"); len(urls) > 0 {
+		return urls[0]
+	}
+
+	return ""
+}
+
+// A Client makes requests to Solid pods
+type Client struct {
+	http     *http.Client
+	prefixes rdf.PrefixMap
+}
+
+func New(opts ...ClientOption) (*Client, error) {
+	c := &Client{
+		http:     http.DefaultClient,
+		prefixes: make(rdf.PrefixMap),
+	}
+
+	for _, f := range opts {
+		f(c)
+	}
+
+	return c, nil
+}
+
+func MustNew(opts ...ClientOption) *Client {
+	c, err := New(opts...)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
+
+// Get reads a resource, preferring RDF formats
+func (c *Client) Get(ctx context.Context, target string, opts ...RequestOption) (*Resource, error) {
+	return c.do(ctx, http.MethodGet, target, "", nil, opts, func(r *http.Request) {
+		r.Header.Set("Accept", acceptRDF)
+	})
+}
+
+// Head reads a resource's headers without its body
+func (c *Client) Head(ctx context.Context, target string, opts ...RequestOption) (*Resource, error) {
+	return c.do(ctx, http.MethodHead, target, "", nil, opts)
+}
+
+// Put replaces the resource with the graph written as Turtle, creating it
+// if it doesn't exist
+func (c *Client) Put(ctx context.Context, target string, g *rdf.Graph, opts ...RequestOption) (*Resource, error) {
+	body, err := c.turtle(g)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.do(ctx, http.MethodPut, target, mediaTypeTurtle, body, opts)
+}
+
+// PutBody replaces the resource with a body of any type
+func (c *Client) PutBody(ctx context.Context, target, contentType string, body io.Reader, opts ...RequestOption) (*Resource, error) {
+	return c.do(ctx, http.MethodPut, target, contentType, body, opts)
+}
+
+// Post creates a resource in the container from the graph written as
+// Turtle. The server names it, the returned Resource has its URL
+func (c *Client) Post(ctx context.Context, container string, g *rdf.Graph, opts ...RequestOption) (*Resource, error) {
+	body, err := c.turtle(g)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.do(ctx, http.MethodPost, container, mediaTypeTurtle, body, opts)
+}
+
+// PostBody creates a resource in the container with a body of any type
+func (c *Client) PostBody(ctx context.Context, container, contentType string, body io.Reader, opts ...RequestOption) (*Resource, error) {
+	return c.do(ctx, http.MethodPost, container, contentType, body, opts)
+}
+
+// Patch changes an RDF resource with an N3 Patch
+//
+// https://solidproject.org/TR/protocol#n3-patch
+func (c *Client) Patch(ctx context.Context, target string, p *n3patch.Patch, opts ...RequestOption) (*Resource, error) {
+	return c.do(ctx, http.MethodPatch, target, mediaTypeN3, strings.NewReader(p.String()), opts)
+}
+
+// PatchSparql changes an RDF resource with a SPARQL Update, which servers
+// may support alongside N3 Patch
+func (c *Client) PatchSparql(ctx context.Context, target, update string, opts ...RequestOption) (*Resource, error) {
+	return c.do(ctx, http.MethodPatch, target, mediaTypeSparqlUpdate, strings.NewReader(update), opts)
+}
+
+func (c *Client) Delete(ctx context.Context, target string, opts ...RequestOption) error {
+	_, err := c.do(ctx, http.MethodDelete, target, "", nil, opts)
+	return err
+}
+
+// AccessControl reads the access control document a resource links to, a
+// WAC ACL or an ACP Access Control Resource. It returns the document's URL
+// along with it, and a nil Resource when the document doesn't exist yet
+//
+// https://solidproject.org/TR/protocol#auxiliary-resources
+func (c *Client) AccessControl(ctx context.Context, target string) (string, *Resource, error) {
+	res, err := c.Head(ctx, target)
+	if err != nil {
+		return "", nil, err
+	}
+
+	link := res.ACL()
+	if link == "" {
+		return "", nil, fmt.Errorf("solid: %v doesn't link to access control", target)
+	}
+
+	doc, err := c.Get(ctx, link)
+
+	var e *Error
+	if errors.As(err, &e) && e.StatusCode == http.StatusNotFound {
+		return link, nil, nil
+	}
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	if doc.Graph == nil {
+		return "", nil, fmt.Errorf("solid: %v isn't RDF", link)
+	}
+
+	return link, doc, nil
+}
+
+func (c *Client) turtle(g *rdf.Graph) (io.Reader, error) {
+	var buf bytes.Buffer
+
+	ser := serializer.MustNew(serializer.WithPrefixes(c.prefixes), serializer.WithPrefixes(g.Prefixes))
+	if err := ser.Turtle(&buf, g); err != nil {
+		return nil, err
+	}
+
+	return &buf, nil
+}
+
+// do makes a request and reads the response into a Resource, or an Error
+// if it wasn't successful
+func (c *Client) do(ctx context.Context, method, target, contentType string, body io.Reader, opts []RequestOption, extra ...RequestOption) (*Resource, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	for _, f := range append(extra, opts...) {
+		f(req)
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		b, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBody))
+
+		return nil, &Error{
+			Method:     method,
+			URL:        target,
+			StatusCode: res.StatusCode,
+			Status:     res.Status,
+			Body:       strings.TrimSpace(string(b)),
+		}
+	}
+
+	// After a redirect the resource is where it ended up
+	found := target
+	if res.Request != nil && res.Request.URL.String() != req.URL.String() {
+		found = res.Request.URL.String()
+	}
+
+	r := &Resource{
+		URL:        found,
+		StatusCode: res.StatusCode,
+		ETag:       res.Header.Get("ETag"),
+		Links:      ParseLinks(res.Header.Values("Link"), found),
+		Allow:      ParseWACAllow(res.Header.Get("WAC-Allow")),
+	}
+
+	// A created resource is where the server says it put it
+	if location := res.Header.Get("Location"); location != "" {
+		if base, err := url.Parse(found); err == nil {
+			if ref, err := base.Parse(location); err == nil {
+				r.URL = ref.String()
+			}
+		}
+	}
+
+	r.ContentType, _, _ = mime.ParseMediaType(res.Header.Get("Content-Type"))
+
+	if method != http.MethodGet {
+		return r, nil
+	}
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch r.ContentType {
+	case mediaTypeTurtle, mediaTypeNTriples, mediaTypeN3:
+		popts := []parser.ClientOption{parser.WithBase(r.URL)}
+		if r.ContentType == mediaTypeN3 {
+			popts = append(popts, parser.WithN3())
+		}
+
+		p, err := parser.New(popts...)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.Do(bytes.NewReader(b)); err != nil {
+			return nil, fmt.Errorf("solid: parsing %v: %w", r.URL, err)
+		}
+
+		r.Graph = p.GetGraph()
+	case mediaTypeJSONLD:
+		// A document with a context that isn't bundled is kept as bytes,
+		// it may well be fine but can't be read without fetching it
+		g, err := jsonld.Parse(bytes.NewReader(b), r.URL)
+
+		switch {
+		case errors.Is(err, jsonld.ErrRemoteContext):
+			r.Body = b
+		case err != nil:
+			return nil, fmt.Errorf("solid: parsing %v: %w", r.URL, err)
+		default:
+			r.Graph = g
+		}
+	default:
+		r.Body = b
+	}
+
+	return r, nil
+}
@@ -0,0 +1,952 @@
+package solid
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/jsonld"
+	"github.com/b1scuit/solid/rdf/n3patch"
+	"github.com/b1scuit/solid/rdf/parser"
+	"github.com/b1scuit/solid/rdf/serializer"
+	"github.com/b1scuit/solid/rdf/sparql"
+	"github.com/b1scuit/solid/rdf/vocab/ldp"
+)
+
+type linksTest struct {
+	Name     string
+	Header   []string
+	Expected []Link
+}
+
+var linksTests = []linksTest{
+	{"Single", []string{`<http://example.org/a>; rel="acl"`},
+		[]Link{{URL: "http://example.org/a", Rel: "acl", Params: map[string]string{}}}},
+	{"Relative and several in one header", []string{`<.acl>; rel="acl", <http://www.w3.org/ns/ldp#Resource>; rel=type`},
+		[]Link{
+			{URL: "http://example.org/c/.acl", Rel: "acl", Params: map[string]string{}},
+			{URL: "http://www.w3.org/ns/ldp#Resource", Rel: "type", Params: map[string]string{}},
+		}},
+	{"Several relations", []string{`</meta>; rel="describedby Alternate"`},
+		[]Link{
+			{URL: "http://example.org/meta", Rel: "describedby", Params: map[string]string{}},
+			{URL: "http://example.org/meta", Rel: "alternate", Params: map[string]string{}},
+		}},
+	{"Quoted comma and other parameters", []string{`</a>; title="a, b"; rel=next; type="text/turtle", </b>; rel=prev`},
+		[]Link{
+			{URL: "http://example.org/a", Rel: "next", Params: map[string]string{"title": "a, b", "type": "text/turtle"}},
+			{URL: "http://example.org/b", Rel: "prev", Params: map[string]string{}},
+		}},
+	{"Several headers", []string{`</a>; rel=a`, `</b>; rel=b`},
+		[]Link{
+			{URL: "http://example.org/a", Rel: "a", Params: map[string]string{}},
+			{URL: "http://example.org/b", Rel: "b", Params: map[string]string{}},
+		}},
+	{"Malformed link skipped", []string{`nonsense; rel=x, </a>; rel=a`},
+		[]Link{{URL: "http://example.org/a", Rel: "a", Params: map[string]string{}}}},
+	{"Extension relation keeps its case", []string{`</.well-known/solid>; rel="http://www.w3.org/ns/solid/terms#storageDescription"`},
+		[]Link{{URL: "http://example.org/.well-known/solid", Rel: "http://www.w3.org/ns/solid/terms#storageDescription", Params: map[string]string{}}}},
+	{"No relation", []string{`</a>; title=x`}, nil},
+}
+
+func TestParseLinks(t *testing.T) {
+	for _, tc := range linksTests {
+		if links := ParseLinks(tc.Header, "http://example.org/c/doc"); !reflect.DeepEqual(links, tc.Expected) {
+			t.Errorf("%v test fail: got %v", tc.Name, links)
+		}
+	}
+}
+
+type wacAllowTest struct {
+	Name     string
+	Header   string
+	Expected WACAllow
+}
+
+var wacAllowTests = []wacAllowTest{
+	{"User and public", `user="read write", public="read"`,
+		WACAllow{"user": {"read", "write"}, "public": {"read"}}},
+	{"No modes", `user="", public="read"`,
+		WACAllow{"user": {}, "public": {"read"}}},
+	{"Spacing and case", ` User = " Read  Append " ,public="read"`,
+		WACAllow{"user": {"read", "append"}, "public": {"read"}}},
+	{"Unquoted", `user=read`,
+		WACAllow{"user": {"read"}}},
+	{"Malformed skipped", `nonsense, public="read"`,
+		WACAllow{"public": {"read"}}},
+	{"Empty", ``, WACAllow{}},
+}
+
+func TestParseWACAllow(t *testing.T) {
+	for _, tc := range wacAllowTests {
+		if allow := ParseWACAllow(tc.Header); !reflect.DeepEqual(allow, tc.Expected) {
+			t.Errorf("%v test fail: got %v", tc.Name, allow)
+		}
+	}
+}
+
+// pod is a stand-in Solid server keeping resources in memory. It has the
+// parts of the protocol the client needs, not the rules a real server
+// enforces
+type pod struct {
+	mu        sync.Mutex
+	url       string
+	resources map[string]*podResource
+	created   int
+
+	// Requests being handled, and the most there have been at once
+	inflight, maxInflight atomic.Int32
+}
+
+type podResource struct {
+	contentType string
+	body        []byte
+}
+
+func etag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return fmt.Sprintf(`"%x"`, sum[:8])
+}
+
+func newPod(t *testing.T) *pod {
+	p := &pod{resources: map[string]*podResource{"/": {contentType: mediaTypeTurtle}}}
+
+	s := httptest.NewServer(p)
+	t.Cleanup(s.Close)
+
+	p.url = s.URL
+
+	return p
+}
+
+func (p *pod) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := p.inflight.Add(1)
+	defer p.inflight.Add(-1)
+
+	for max := p.maxInflight.Load(); n > max && !p.maxInflight.CompareAndSwap(max, n); max = p.maxInflight.Load() {
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	path := r.URL.Path
+	res := p.resources[path]
+
+	if res != nil {
+		if m := r.Header.Get("If-Match"); m != "" && m != etag(p.body(path, res)) {
+			http.Error(w, "modified", http.StatusPreconditionFailed)
+			return
+		}
+	}
+
+	if m := r.Header.Get("If-None-Match"); m == "*" && res != nil {
+		http.Error(w, "exists", http.StatusPreconditionFailed)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		if res == nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		body := p.body(path, res)
+		p.headers(w, path, res.contentType, body)
+
+		if r.Method == http.MethodGet {
+			w.Write(body)
+		}
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+		p.create(path, &podResource{contentType: mediaType, body: body})
+
+		if res == nil {
+			w.WriteHeader(http.StatusCreated)
+		} else {
+			w.WriteHeader(http.StatusNoContent)
+		}
+	case http.MethodPost:
+		if res == nil || !strings.HasSuffix(path, "/") {
+			http.Error(w, "not a container", http.StatusMethodNotAllowed)
+			return
+		}
+
+		p.created++
+
+		name := r.Header.Get("Slug")
+		if name == "" || p.resources[path+name] != nil {
+			name = fmt.Sprintf("resource-%v", p.created)
+		}
+
+		for _, l := range ParseLinks(r.Header.Values("Link"), p.url+path) {
+			if l.Rel == "type" && rdf.IRI(l.URL) == ldp.BasicContainer {
+				name += "/"
+			}
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+		p.create(path+name, &podResource{contentType: mediaType, body: body})
+
+		w.Header().Set("Location", name)
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodPatch:
+		p.patch(w, r, path, res)
+	case http.MethodDelete:
+		if res == nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		if len(p.members(path)) > 0 {
+			http.Error(w, "container isn't empty", http.StatusConflict)
+			return
+		}
+
+		delete(p.resources, path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// create adds a resource along with any containers missing above it
+func (p *pod) create(path string, res *podResource) {
+	p.resources[path] = res
+
+	for i := strings.LastIndexByte(strings.TrimSuffix(path, "/"), '/'); i >= 0; i = strings.LastIndexByte(path[:i], '/') {
+		if p.resources[path[:i+1]] == nil {
+			p.resources[path[:i+1]] = &podResource{contentType: mediaTypeTurtle}
+		}
+	}
+}
+
+// members lists what a container directly contains
+func (p *pod) members(path string) []string {
+	var members []string
+
+	if !strings.HasSuffix(path, "/") {
+		return nil
+	}
+
+	for name := range p.resources {
+		rest, ok := strings.CutPrefix(name, path)
+		if ok && rest != "" && !strings.Contains(strings.TrimSuffix(rest, "/"), "/") {
+			members = append(members, name)
+		}
+	}
+
+	return members
+}
+
+// body is what a GET returns, the containment triples for containers
+func (p *pod) body(path string, res *podResource) []byte {
+	if !strings.HasSuffix(path, "/") {
+		return res.body
+	}
+
+	g := rdf.NewGraph(rdf.NewTriple(rdf.IRI(p.url+path), rdf.RDFType, ldp.BasicContainer))
+	for _, m := range p.members(path) {
+		g.Add(rdf.NewTriple(rdf.IRI(p.url+path), ldp.Contains, rdf.IRI(p.url+m)))
+	}
+
+	var buf bytes.Buffer
+	serializer.MustNew().Turtle(&buf, g)
+
+	return buf.Bytes()
+}
+
+func (p *pod) headers(w http.ResponseWriter, path, contentType string, body []byte) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", etag(body))
+	w.Header().Set("WAC-Allow", `user="read write append control", public="read"`)
+	w.Header().Add("Link", fmt.Sprintf(`<%v.acl>; rel="acl"`, path[strings.LastIndexByte(path, '/')+1:]))
+	w.Header().Add("Link", fmt.Sprintf(`<%v>; rel="type"`, ldp.Resource))
+
+	if strings.HasSuffix(path, "/") {
+		w.Header().Add("Link", fmt.Sprintf(`<%v>; rel="type"`, ldp.BasicContainer))
+	}
+}
+
+func (p *pod) patch(w http.ResponseWriter, r *http.Request, path string, res *podResource) {
+	base := p.url + path
+
+	g := rdf.NewGraph()
+
+	if res != nil {
+		doc := parser.MustNew(parser.WithBase(base))
+		if err := doc.Do(bytes.NewReader(res.body)); err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+
+		g = doc.GetGraph()
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	switch mediaType {
+	case mediaTypeN3:
+		patch, err := n3patch.Parse(r.Body, base)
+		if err == nil {
+			err = patch.Apply(g)
+		}
+
+		switch {
+		case errors.Is(err, n3patch.ErrInvalid):
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		case errors.Is(err, n3patch.ErrConflict):
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+	case mediaTypeSparqlUpdate:
+		b, _ := io.ReadAll(r.Body)
+
+		s := sparql.MustNew()
+
+		u, err := s.ParseUpdate(string(b))
+		if err == nil {
+			err = s.ExecUpdate(rdf.NewDataset(g), u)
+		}
+
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		http.Error(w, "unsupported patch", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var buf bytes.Buffer
+	serializer.MustNew().NTriples(&buf, g)
+
+	p.create(path, &podResource{contentType: mediaTypeNTriples, body: buf.Bytes()})
+
+	if res == nil {
+		w.WriteHeader(http.StatusCreated)
+	} else {
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func statusOf(err error) int {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.StatusCode
+	}
+
+	return 0
+}
+
+func turtleGraph(t *testing.T, base, doc string) *rdf.Graph {
+	p := parser.MustNew(parser.WithBase(base))
+
+	if err := p.Do(strings.NewReader(doc)); err != nil {
+		t.Fatalf("parsing test graph: %v", err)
+	}
+
+	return p.GetGraph()
+}
+
+func TestReadWrite(t *testing.T) {
+	p := newPod(t)
+	c := MustNew()
+	ctx := context.Background()
+
+	doc := p.url + "/profile"
+	g := turtleGraph(t, doc, `<#me> <http://xmlns.com/foaf/0.1/name> "Alice" .`)
+
+	if res, err := c.Put(ctx, doc, g); err != nil || res.StatusCode != http.StatusCreated {
+		t.Fatalf("put test fail: %v, %v", res, err)
+	}
+
+	res, err := c.Get(ctx, doc)
+	if err != nil {
+		t.Fatalf("get test fail: %v", err)
+	}
+
+	if res.Graph == nil || !rdf.Isomorphic(res.Graph, g) {
+		t.Errorf("get test fail: got %v", res.Graph)
+	}
+
+	if res.ETag == "" || res.ContentType != mediaTypeTurtle {
+		t.Errorf("get test fail: ETag %q, content type %q", res.ETag, res.ContentType)
+	}
+
+	if res.ACL() != p.url+"/profile.acl" || res.IsContainer() {
+		t.Errorf("links test fail: acl %v, types %v", res.ACL(), res.Types())
+	}
+
+	if !res.Allow.Allows("user", "write") || res.Allow.Allows("public", "write") {
+		t.Errorf("WAC-Allow test fail: got %v", res.Allow)
+	}
+
+	// Writes that depend on the resource as it was read
+	etag := res.ETag
+
+	if _, err := c.Put(ctx, doc, g, IfMatch(etag)); err != nil {
+		t.Errorf("if-match test fail: %v", err)
+	}
+
+	if _, err := c.Put(ctx, doc, g, IfMatch(`"stale"`)); statusOf(err) != http.StatusPreconditionFailed {
+		t.Errorf("if-match test fail: stale ETag gave %v", err)
+	}
+
+	if _, err := c.Put(ctx, doc, g, IfNoneMatch("*")); statusOf(err) != http.StatusPreconditionFailed {
+		t.Errorf("if-none-match test fail: overwrote with %v", err)
+	}
+
+	if head, err := c.Head(ctx, doc); err != nil || head.ETag != etag || head.Graph != nil || head.ContentType != "text/turtle" {
+		t.Errorf("head test fail: %v, %v", head, err)
+	}
+
+	// Bodies that aren't RDF are kept as they are
+	image := p.url + "/photo.png"
+
+	if _, err := c.PutBody(ctx, image, "image/png", strings.NewReader("\x89PNG")); err != nil {
+		t.Fatalf("put body test fail: %v", err)
+	}
+
+	if res, err := c.Get(ctx, image); err != nil || string(res.Body) != "\x89PNG" || res.Graph != nil || res.ContentType != "image/png" {
+		t.Errorf("get body test fail: %v, %v", res, err)
+	}
+
+	if head, err := c.Head(ctx, image); err != nil || head.Body != nil || head.ContentType != "image/png" {
+		t.Errorf("head body test fail: %v, %v", head, err)
+	}
+
+	if err := c.Delete(ctx, image); err != nil {
+		t.Errorf("delete test fail: %v", err)
+	}
+
+	if _, err := c.Get(ctx, image); statusOf(err) != http.StatusNotFound {
+		t.Errorf("delete test fail: still there, %v", err)
+	}
+
+	if err := c.Delete(ctx, image); statusOf(err) != http.StatusNotFound {
+		t.Errorf("delete test fail: deleting again gave %v", err)
+	}
+
+	// JSON-LD is read into a graph where it can be and kept as it is
+	// where it can't
+	note := p.url + "/note.jsonld"
+
+	if _, err := c.PutBody(ctx, note, mediaTypeJSONLD, strings.NewReader(`{"@id": "#it", "http://xmlns.com/foaf/0.1/name": "It"}`)); err != nil {
+		t.Fatalf("put json-ld test fail: %v", err)
+	}
+
+	if res, err := c.Get(ctx, note); err != nil || res.Graph == nil || res.Graph.Len() != 1 {
+		t.Errorf("get json-ld test fail: %v, %v", res, err)
+	}
+
+	remote := `{"@context": "https://example.org/context.jsonld", "name": "It"}`
+
+	if _, err := c.PutBody(ctx, note, mediaTypeJSONLD, strings.NewReader(remote)); err != nil {
+		t.Fatalf("put json-ld test fail: %v", err)
+	}
+
+	if res, err := c.Get(ctx, note); err != nil || res.Graph != nil || string(res.Body) != remote {
+		t.Errorf("get remote context test fail: %v, %v", res, err)
+	}
+
+	if _, err := c.PutBody(ctx, note, mediaTypeJSONLD, strings.NewReader(`{"@id": `)); err != nil {
+		t.Fatalf("put json-ld test fail: %v", err)
+	}
+
+	if _, err := c.Get(ctx, note); !errors.Is(err, jsonld.ErrInvalid) {
+		t.Errorf("get malformed json-ld test fail: got %v", err)
+	}
+}
+
+func TestPost(t *testing.T) {
+	p := newPod(t)
+	c := MustNew()
+	ctx := context.Background()
+
+	g := turtleGraph(t, "", `<http://example.org/a> <http://example.org/p> "A" .`)
+
+	res, err := c.Post(ctx, p.url+"/", g, Slug("note"))
+	if err != nil || res.URL != p.url+"/note" {
+		t.Fatalf("post test fail: %v, %v", res, err)
+	}
+
+	// A taken name is replaced by the server's own
+	if res, err := c.Post(ctx, p.url+"/", g, Slug("note")); err != nil || res.URL == p.url+"/note" {
+		t.Errorf("post test fail: taken slug gave %v, %v", res, err)
+	}
+
+	res, err = c.Post(ctx, p.url+"/", rdf.NewGraph(), Slug("notes"), LinkType(ldp.BasicContainer))
+	if err != nil || res.URL != p.url+"/notes/" {
+		t.Fatalf("post container test fail: %v, %v", res, err)
+	}
+
+	if res, err := c.Head(ctx, res.URL); err != nil || !res.IsContainer() {
+		t.Errorf("post container test fail: %v, %v", res, err)
+	}
+
+	if res, err := c.PostBody(ctx, p.url+"/notes/", "text/plain", strings.NewReader("hello")); err != nil || !strings.HasPrefix(res.URL, p.url+"/notes/") {
+		t.Errorf("post body test fail: %v, %v", res, err)
+	}
+}
+
+func TestPatch(t *testing.T) {
+	p := newPod(t)
+	c := MustNew()
+	ctx := context.Background()
+
+	doc := p.url + "/doc"
+	alice, name := rdf.IRI(doc+"#alice"), rdf.IRI("http://xmlns.com/foaf/0.1/name")
+
+	patch := &n3patch.Patch{
+		Inserts: rdf.Formula{rdf.NewTriple(alice, name, rdf.NewLiteral("Alice"))},
+	}
+
+	// Patching a missing resource creates it
+	if res, err := c.Patch(ctx, doc, patch); err != nil || res.StatusCode != http.StatusCreated {
+		t.Fatalf("patch test fail: %v, %v", res, err)
+	}
+
+	patch = &n3patch.Patch{
+		Where:   rdf.Formula{rdf.NewTriple(alice, name, rdf.Variable("name"))},
+		Deletes: rdf.Formula{rdf.NewTriple(alice, name, rdf.Variable("name"))},
+		Inserts: rdf.Formula{rdf.NewTriple(alice, name, rdf.NewLiteral("Alice Smith"))},
+	}
+
+	if _, err := c.Patch(ctx, doc, patch); err != nil {
+		t.Fatalf("patch test fail: %v", err)
+	}
+
+	res, err := c.Get(ctx, doc)
+	if err != nil || !res.Graph.Has(rdf.NewTriple(alice, name, rdf.NewLiteral("Alice Smith"))) || res.Graph.Len() != 1 {
+		t.Errorf("patch test fail: got %v, %v", res, err)
+	}
+
+	// Deleting what isn't there is a conflict and changes nothing
+	patch = &n3patch.Patch{
+		Deletes: rdf.Formula{rdf.NewTriple(alice, name, rdf.NewLiteral("Bob"))},
+	}
+
+	if _, err := c.Patch(ctx, doc, patch, IfMatch(res.ETag)); statusOf(err) != http.StatusConflict {
+		t.Errorf("patch conflict test fail: %v", err)
+	}
+
+	update := fmt.Sprintf(`INSERT DATA { %v <http://xmlns.com/foaf/0.1/nick> "al" }`, alice)
+
+	if _, err := c.PatchSparql(ctx, doc, update, IfMatch(res.ETag)); err != nil {
+		t.Errorf("sparql patch test fail: %v", err)
+	}
+
+	if res, err := c.Get(ctx, doc); err != nil || res.Graph.Len() != 2 {
+		t.Errorf("sparql patch test fail: got %v, %v", res, err)
+	}
+
+	if _, err := c.PatchSparql(ctx, doc, update, IfMatch(res.ETag)); statusOf(err) != http.StatusPreconditionFailed {
+		t.Errorf("sparql patch test fail: stale ETag gave %v", err)
+	}
+}
+
+func TestContainers(t *testing.T) {
+	p := newPod(t)
+	c := MustNew()
+	ctx := context.Background()
+
+	root := p.url + "/"
+	g := turtleGraph(t, "", `<http://example.org/a> <http://example.org/p> "A" .`)
+
+	notes, err := c.CreateContainer(ctx, root, "notes")
+	if err != nil || notes.URL != root+"notes/" {
+		t.Fatalf("create container test fail: %v, %v", notes, err)
+	}
+
+	if _, err := c.Post(ctx, notes.URL, g, Slug("one")); err != nil {
+		t.Fatalf("create test fail: %v", err)
+	}
+
+	// Putting a resource makes the containers above it
+	if _, err := c.Put(ctx, notes.URL+"2024/june/two", g); err != nil {
+		t.Fatalf("create test fail: %v", err)
+	}
+
+	if _, err := c.PutBody(ctx, root+"photo.png", "image/png", strings.NewReader("\x89PNG")); err != nil {
+		t.Fatalf("create test fail: %v", err)
+	}
+
+	container, err := c.Container(ctx, notes.URL)
+	if err != nil || !reflect.DeepEqual(container.Members, []string{notes.URL + "2024/", notes.URL + "one"}) {
+		t.Errorf("list test fail: %v, %v", container, err)
+	}
+
+	if _, err := c.Container(ctx, notes.URL+"one"); !errors.Is(err, ErrNotContainer) {
+		t.Errorf("list test fail: listed a document, %v", err)
+	}
+
+	if err := c.DeleteContainer(ctx, notes.URL); !errors.Is(err, ErrNotEmpty) {
+		t.Errorf("delete container test fail: deleted a full container, %v", err)
+	}
+
+	empty, _ := c.CreateContainer(ctx, notes.URL, "empty")
+
+	if err := c.DeleteContainer(ctx, empty.URL); err != nil {
+		t.Errorf("delete container test fail: %v", err)
+	}
+
+	if _, err := c.Head(ctx, empty.URL); statusOf(err) != http.StatusNotFound {
+		t.Errorf("delete container test fail: still there, %v", err)
+	}
+
+	all := []string{
+		root,
+		root + "notes/",
+		root + "notes/2024/",
+		root + "notes/2024/june/",
+		root + "notes/2024/june/two",
+		root + "notes/one",
+		root + "photo.png",
+	}
+
+	type walkTest struct {
+		Name     string
+		Fn       func(target string, container *Container) error
+		Expected []string
+		Err      error
+	}
+
+	failed := errors.New("failed")
+
+	walkTests := []walkTest{
+		{"Everything", func(string, *Container) error { return nil }, all, nil},
+		{"Skipping a container", func(target string, _ *Container) error {
+			if target == root+"notes/2024/" {
+				return SkipContainer
+			}
+
+			return nil
+		}, []string{all[0], all[1], all[2], all[5], all[6]}, nil},
+		{"Error stops the walk", func(target string, _ *Container) error {
+			if target == root+"notes/" {
+				return failed
+			}
+
+			return nil
+		}, nil, failed},
+	}
+
+	for _, tc := range walkTests {
+		var mu sync.Mutex
+		var visited []string
+
+		p.maxInflight.Store(0)
+
+		err := c.Walk(ctx, root, func(ctx context.Context, target string, container *Container) error {
+			if IsContainerURL(target) != (container != nil) {
+				t.Errorf("%v test fail: %v given container %v", tc.Name, target, container)
+			}
+
+			mu.Lock()
+			visited = append(visited, target)
+			mu.Unlock()
+
+			return tc.Fn(target, container)
+		}, WithConcurrency(2))
+
+		sort.Strings(visited)
+
+		if tc.Err != nil {
+			if !errors.Is(err, tc.Err) {
+				t.Errorf("%v test fail: expected %v, got %v", tc.Name, tc.Err, err)
+			}
+		} else if err != nil || !reflect.DeepEqual(visited, tc.Expected) {
+			t.Errorf("%v test fail: visited %v, %v", tc.Name, visited, err)
+		}
+
+		if n := p.maxInflight.Load(); n > 2 {
+			t.Errorf("%v test fail: %v requests at once", tc.Name, n)
+		}
+	}
+}
+
+// authorised adds a header to each request, standing in for a signed in
+// session
+type authorised string
+
+func (a authorised) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("Authorization", string(a))
+
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestFetchWebID(t *testing.T) {
+	docs := map[string]string{
+		"/alice/profile/card": `
+			@prefix foaf: <http://xmlns.com/foaf/0.1/> .
+			@prefix space: <http://www.w3.org/ns/pim/space#> .
+			@prefix solid: <http://www.w3.org/ns/solid/terms#> .
+			@prefix ldp: <http://www.w3.org/ns/ldp#> .
+			@prefix rdfs: <http://www.w3.org/2000/01/rdf-schema#> .
+
+			<#me> foaf:name "Alice" ;
+				space:storage </alice/> ;
+				solid:oidcIssuer <https://idp.example> ;
+				ldp:inbox </alice/inbox/> ;
+				space:preferencesFile </alice/settings/prefs.ttl> ;
+				solid:publicTypeIndex </alice/settings/publicTypeIndex.ttl> ;
+				rdfs:seeAlso </alice/extended>, </alice/missing>, </alice/broken> .`,
+		"/alice/extended": `
+			</alice/profile/card#me> <http://www.w3.org/ns/pim/space#storage> <https://other.example/> ;
+				<http://www.w3.org/ns/solid/terms#oidcIssuer> <https://evil.example/> ;
+				<http://www.w3.org/2000/01/rdf-schema#seeAlso> </alice/extended#it>, </alice/more> .`,
+		"/alice/more": `
+			</alice/profile/card#me> <http://www.w3.org/2006/vcard/ns#note> "Found" ;
+				<http://www.w3.org/2000/01/rdf-schema#seeAlso> </alice/extended> .`,
+		"/alice/settings/prefs.ttl": `
+			</alice/profile/card#me> <http://www.w3.org/ns/solid/terms#privateTypeIndex> </alice/settings/privateTypeIndex.ttl> .`,
+		"/alice/broken": `<#it> <http://xmlns.com/foaf/0.1/name> .`,
+		"/bob/profile": `
+			</bob> <http://www.w3.org/2006/vcard/ns#fn> "Bob" .`,
+		"/carol": `
+			<#someone-else> <http://xmlns.com/foaf/0.1/name> "Dave" .`,
+	}
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/bob":
+			http.Redirect(w, r, "/bob/profile", http.StatusSeeOther)
+			return
+		case r.URL.Path == "/alice/settings/prefs.ttl" && r.Header.Get("Authorization") != "alice":
+			http.Error(w, "sign in", http.StatusUnauthorized)
+			return
+		}
+
+		doc, ok := docs[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/turtle")
+		io.WriteString(w, doc)
+	}))
+	defer s.Close()
+
+	ctx := context.Background()
+	alice := s.URL + "/alice/profile/card#me"
+
+	p, err := FetchWebID(ctx, alice)
+	if err != nil {
+		t.Fatalf("webid test fail: %v", err)
+	}
+
+	expected := &Profile{
+		WebID:           alice,
+		Document:        s.URL + "/alice/profile/card",
+		Name:            "Alice",
+		Storage:         []string{s.URL + "/alice/"},
+		OIDCIssuers:     []string{"https://idp.example"},
+		Inbox:           s.URL + "/alice/inbox/",
+		PreferencesFile: s.URL + "/alice/settings/prefs.ttl",
+		PublicTypeIndex: s.URL + "/alice/settings/publicTypeIndex.ttl",
+		SeeAlso:         []string{s.URL + "/alice/broken", s.URL + "/alice/extended", s.URL + "/alice/extended#it", s.URL + "/alice/missing", s.URL + "/alice/more"},
+		Graph:           p.Graph,
+	}
+
+	if !reflect.DeepEqual(p, expected) {
+		t.Errorf("webid test fail: got %+v", p)
+	}
+
+	if p.Graph.Object(rdf.IRI(alice), rdf.IRI("http://www.w3.org/2006/vcard/ns#note")) == nil {
+		t.Errorf("webid test fail: extended profile not followed")
+	}
+
+	// Signed in the preferences file can be read too
+	p, err = MustNew(WithHTTPClient(&http.Client{Transport: authorised("alice")})).FetchWebID(ctx, alice)
+	if err != nil || p.PrivateTypeIndex != s.URL+"/alice/settings/privateTypeIndex.ttl" {
+		t.Errorf("webid test fail: private type index %q, %v", p.PrivateTypeIndex, err)
+	}
+
+	p, err = FetchWebID(ctx, s.URL+"/bob")
+	if err != nil || p.Name != "Bob" || p.Document != s.URL+"/bob/profile" {
+		t.Errorf("slash webid test fail: %+v, %v", p, err)
+	}
+
+	if _, err := FetchWebID(ctx, s.URL+"/carol#me"); !errors.Is(err, ErrNotWebID) {
+		t.Errorf("webid test fail: carol gave %v", err)
+	}
+
+	if _, err := FetchWebID(ctx, s.URL+"/nobody#me"); statusOf(err) != http.StatusNotFound {
+		t.Errorf("webid test fail: nobody gave %v", err)
+	}
+}
+
+type findTypeTest struct {
+	Name      string
+	Class     rdf.IRI
+	Locations []string
+}
+
+func TestTypeIndex(t *testing.T) {
+	p := newPod(t)
+	ctx := context.Background()
+
+	public := p.url + "/settings/publicTypeIndex.ttl"
+	private := p.url + "/settings/privateTypeIndex.ttl"
+
+	p.create("/settings/publicTypeIndex.ttl", &podResource{contentType: mediaTypeTurtle, body: []byte(`
+		@prefix solid: <http://www.w3.org/ns/solid/terms#> .
+		@prefix schema: <http://schema.org/> .
+
+		<> a solid:TypeIndex, solid:ListedDocument .
+
+		<#bookmarks> a solid:TypeRegistration ;
+			solid:forClass <http://www.w3.org/2002/01/bookmark#Bookmark> ;
+			solid:instance </bookmarks/mine.ttl> .
+
+		<#events> solid:forClass schema:Event, schema:Meeting ;
+			solid:instanceContainer </events/> ;
+			solid:instance </events/index.ttl> .`)})
+
+	c := MustNew()
+	profile := &Profile{PublicTypeIndex: public, PrivateTypeIndex: private}
+
+	// The private index doesn't exist yet, so only the public one is read
+	indexes, err := c.TypeIndexes(ctx, profile)
+	if err != nil || len(indexes) != 1 || !indexes[0].Public || indexes[0].ETag == "" {
+		t.Fatalf("type indexes test fail: %+v, %v", indexes, err)
+	}
+
+	tests := []findTypeTest{
+		{
+			Name:      "Instance",
+			Class:     "http://www.w3.org/2002/01/bookmark#Bookmark",
+			Locations: []string{p.url + "/bookmarks/mine.ttl"},
+		},
+		{
+			Name:      "Instances and containers",
+			Class:     "http://schema.org/Meeting",
+			Locations: []string{p.url + "/events/index.ttl", p.url + "/events/"},
+		},
+		{
+			Name:  "Unregistered",
+			Class: "http://schema.org/Person",
+		},
+	}
+
+	for _, tc := range tests {
+		var locations []string
+		for _, r := range FindType(tc.Class, indexes...) {
+			locations = append(locations, r.Locations()...)
+		}
+
+		if !reflect.DeepEqual(locations, tc.Locations) {
+			t.Errorf("%v test fail: got %v, expected %v", tc.Name, locations, tc.Locations)
+		}
+	}
+
+	// A new class gets a registration named after it
+	r, err := c.Register(ctx, public, true, "http://schema.org/Person", p.url+"/contacts/")
+	if err != nil || r.ID != public+"#Person" || !reflect.DeepEqual(r.InstanceContainers, []string{p.url + "/contacts/"}) {
+		t.Fatalf("register test fail: %+v, %v", r, err)
+	}
+
+	// Registering the same class again doesn't reuse the name, and an
+	// existing location isn't added twice
+	if r, err := c.Register(ctx, public, true, "http://example.org/other/Person", p.url+"/people.ttl"); err != nil || r.ID != public+"#Person2" {
+		t.Errorf("register test fail: %+v, %v", r, err)
+	}
+
+	if r, err := c.Register(ctx, public, true, "http://schema.org/Event", p.url+"/events/"); err != nil || r.ID != public+"#events" {
+		t.Errorf("register test fail: %+v, %v", r, err)
+	}
+
+	ti, err := c.FetchTypeIndex(ctx, public)
+	if err != nil || len(ti.Registrations) != 4 || len(ti.Find("http://schema.org/Event")) != 1 {
+		t.Fatalf("register test fail: %+v, %v", ti, err)
+	}
+
+	if found := ti.Find("http://schema.org/Person"); len(found) != 1 || found[0].ID != public+"#Person" {
+		t.Errorf("register test fail: found %+v", found)
+	}
+
+	// A missing index is created
+	if _, err := c.Register(ctx, private, false, "http://schema.org/Person", p.url+"/private/contacts.ttl"); err != nil {
+		t.Fatalf("register private test fail: %v", err)
+	}
+
+	indexes, err = c.TypeIndexes(ctx, profile)
+	if err != nil || len(indexes) != 2 || indexes[1].Public {
+		t.Fatalf("type indexes test fail: %+v, %v", indexes, err)
+	}
+
+	res, err := c.Get(ctx, private)
+	if err != nil || !res.Graph.Has(rdf.NewTriple(rdf.IRI(private), rdf.RDFType, rdf.IRI("http://www.w3.org/ns/solid/terms#TypeIndex"))) {
+		t.Errorf("register private test fail: %v, %v", res, err)
+	}
+
+	if res.Graph.Has(rdf.NewTriple(rdf.IRI(private), rdf.RDFType, rdf.IRI("http://www.w3.org/ns/solid/terms#ListedDocument"))) {
+		t.Errorf("register private test fail: listed")
+	}
+
+	// A new public index is listed
+	listed := p.url + "/settings/listedTypeIndex.ttl"
+
+	if _, err := c.Register(ctx, listed, true, "http://schema.org/Person", p.url+"/contacts/"); err != nil {
+		t.Fatalf("register public test fail: %v", err)
+	}
+
+	if ti, err := c.FetchTypeIndex(ctx, listed); err != nil || !ti.Public {
+		t.Errorf("register public test fail: %+v, %v", ti, err)
+	}
+
+	if found := FindType("http://schema.org/Person", indexes...); len(found) != 2 || found[1].Instances[0] != p.url+"/private/contacts.ttl" {
+		t.Errorf("find type test fail: %+v", found)
+	}
+
+	// A server that sends no ETag still gets the index patched, not created
+	bare := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.ServeHTTP(noETag{w}, r)
+	}))
+	defer bare.Close()
+
+	index := bare.URL + "/settings/publicTypeIndex.ttl"
+
+	if r, err := c.Register(ctx, index, true, "http://schema.org/Book", bare.URL+"/books/"); err != nil || r.ID != index+"#Book" {
+		t.Fatalf("register without etag test fail: %+v, %v", r, err)
+	}
+
+	res, err = c.Get(ctx, index)
+	if err != nil || res.Graph.Has(rdf.NewTriple(rdf.IRI(index), rdf.RDFType, rdf.IRI("http://www.w3.org/ns/solid/terms#TypeIndex"))) || len(TypeIndexFromGraph(res.Graph, index).Registrations) != 5 {
+		t.Errorf("register without etag test fail: %v, %v", res, err)
+	}
+}
+
+// noETag hides the ETag a response has
+type noETag struct {
+	http.ResponseWriter
+}
+
+func (w noETag) WriteHeader(code int) {
+	w.Header().Del("ETag")
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w noETag) Write(b []byte) (int, error) {
+	w.Header().Del("ETag")
+	return w.ResponseWriter.Write(b)
+}
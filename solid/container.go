@@ -0,0 +1,232 @@
+package solid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/vocab/ldp"
+)
+
+var (
+	ErrNotContainer = errors.New("solid: not a container")
+	ErrNotEmpty     = errors.New("solid: container isn't empty")
+
+	// SkipContainer is returned by a WalkFunc to not walk into a
+	// container, like filepath.SkipDir
+	SkipContainer = errors.New("solid: skip container")
+)
+
+// A Container is an LDP Basic Container, Members being the URLs of the
+// resources it contains, sorted
+//
+// https://solidproject.org/TR/protocol#resource-containment
+type Container struct {
+	*Resource
+	Members []string
+}
+
+// IsContainerURL checks whether the URL names a container, which in Solid
+// is one ending in a slash
+func IsContainerURL(u string) bool {
+	return strings.HasSuffix(u, "/")
+}
+
+// ParentURL returns the container a resource is in, or false for the root
+// of the storage
+func ParentURL(u string) (string, bool) {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return "", false
+	}
+
+	path := strings.TrimSuffix(parsed.Path, "/")
+	if path == "" {
+		return "", false
+	}
+
+	parsed.Path = path[:strings.LastIndexByte(path, '/')+1]
+	parsed.RawPath, parsed.RawQuery, parsed.Fragment = "", "", ""
+
+	return parsed.String(), true
+}
+
+// Container reads a container and what it contains
+func (c *Client) Container(ctx context.Context, target string) (*Container, error) {
+	res, err := c.Get(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+
+	if !res.IsContainer() || res.Graph == nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotContainer, target)
+	}
+
+	container := &Container{Resource: res}
+
+	for _, t := range res.Graph.Match(rdf.IRI(res.URL), ldp.Contains, nil) {
+		if iri, ok := t.Object.(rdf.IRI); ok {
+			container.Members = append(container.Members, string(iri))
+		}
+	}
+
+	sort.Strings(container.Members)
+
+	return container, nil
+}
+
+// CreateContainer makes an empty container in the parent, named after the
+// slug if the server agrees
+func (c *Client) CreateContainer(ctx context.Context, parent, slug string, opts ...RequestOption) (*Resource, error) {
+	opts = append([]RequestOption{Slug(slug), LinkType(ldp.BasicContainer)}, opts...)
+
+	return c.Post(ctx, parent, rdf.NewGraph(), opts...)
+}
+
+// DeleteContainer deletes a container if it is empty. The delete only
+// goes ahead if the container hasn't changed since it was found empty
+func (c *Client) DeleteContainer(ctx context.Context, target string, opts ...RequestOption) error {
+	container, err := c.Container(ctx, target)
+	if err != nil {
+		return err
+	}
+
+	if len(container.Members) > 0 {
+		return fmt.Errorf("%w: %v has %v members", ErrNotEmpty, target, len(container.Members))
+	}
+
+	if container.ETag != "" {
+		opts = append([]RequestOption{IfMatch(container.ETag)}, opts...)
+	}
+
+	return c.Delete(ctx, target, opts...)
+}
+
+// A WalkFunc is called for each resource found walking a container tree,
+// with the container when the resource is one. Returning SkipContainer
+// from a container doesn't walk into it, any other error stops the walk
+type WalkFunc func(ctx context.Context, target string, container *Container) error
+
+type WalkOption func(*walker)
+
+// WithConcurrency sets how many requests the walk makes at once, which
+// defaults to 4
+func WithConcurrency(n int) WalkOption {
+	return func(w *walker) {
+		if n > 0 {
+			w.concurrency = n
+		}
+	}
+}
+
+type walker struct {
+	c           *Client
+	fn          WalkFunc
+	concurrency int
+
+	slots chan struct{}
+	wg    sync.WaitGroup
+
+	mu      sync.Mutex
+	visited map[string]bool
+	err     error
+	cancel  context.CancelFunc
+}
+
+// Walk visits root and every resource under it, reading containers to find
+// their members. Containers are visited before what they contain but
+// otherwise the order isn't fixed, and fn is called from several
+// goroutines at once. Members outside the container they are listed in
+// aren't followed, so a misbehaving server can't lead the walk elsewhere
+func (c *Client) Walk(ctx context.Context, root string, fn WalkFunc, opts ...WalkOption) error {
+	w := &walker{c: c, fn: fn, concurrency: 4, visited: make(map[string]bool)}
+
+	for _, f := range opts {
+		f(w)
+	}
+
+	ctx, w.cancel = context.WithCancel(ctx)
+	defer w.cancel()
+
+	w.slots = make(chan struct{}, w.concurrency)
+
+	w.visit(ctx, root)
+	w.wg.Wait()
+
+	return w.err
+}
+
+// visit starts walking a resource unless it has been seen already
+func (w *walker) visit(ctx context.Context, target string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.visited[target] {
+		return
+	}
+
+	w.visited[target] = true
+	w.wg.Add(1)
+
+	go func() {
+		defer w.wg.Done()
+
+		if err := w.walk(ctx, target); err != nil {
+			w.fail(err)
+		}
+	}()
+}
+
+func (w *walker) walk(ctx context.Context, target string) error {
+	// A slot is held while reading the container and calling fn
+	select {
+	case w.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	var container *Container
+	var err error
+
+	if IsContainerURL(target) {
+		container, err = w.c.Container(ctx, target)
+	}
+
+	if err == nil {
+		err = w.fn(ctx, target, container)
+	}
+
+	<-w.slots
+
+	if errors.Is(err, SkipContainer) {
+		return nil
+	}
+
+	if err != nil || container == nil {
+		return err
+	}
+
+	for _, m := range container.Members {
+		if strings.HasPrefix(m, container.URL) && m != container.URL {
+			w.visit(ctx, m)
+		}
+	}
+
+	return nil
+}
+
+// fail keeps the first error and stops the rest of the walk
+func (w *walker) fail(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.err == nil {
+		w.err = err
+		w.cancel()
+	}
+}
@@ -0,0 +1,218 @@
+package solid
+
+import (
+	"net/url"
+	"strings"
+)
+
+// A Link is one target of a Link header with one relation, a target given
+// several relations appears once for each
+//
+// https://www.rfc-editor.org/rfc/rfc8288
+type Link struct {
+	URL    string
+	Rel    string
+	Params map[string]string
+}
+
+// ParseLinks reads Link header values, resolving targets against base
+func ParseLinks(values []string, base string) []Link {
+	baseURL, _ := url.Parse(base)
+
+	var links []Link
+
+	for _, v := range values {
+		for v != "" {
+			v = strings.TrimLeft(v, " \t,")
+			if v == "" {
+				break
+			}
+
+			if v[0] != '<' {
+				v = skipLink(v)
+				continue
+			}
+
+			end := strings.IndexByte(v, '>')
+			if end < 0 {
+				break
+			}
+
+			target := v[1:end]
+			v = v[end+1:]
+
+			var params map[string]string
+			params, v = parseLinkParams(v)
+
+			if baseURL != nil {
+				if ref, err := baseURL.Parse(target); err == nil {
+					target = ref.String()
+				}
+			}
+
+			rels := strings.Fields(params["rel"])
+			delete(params, "rel")
+
+			for _, rel := range rels {
+				links = append(links, Link{URL: target, Rel: relation(rel), Params: params})
+			}
+		}
+	}
+
+	return links
+}
+
+// relation normalises a link relation. Registered relations are compared
+// ignoring case, extension relations are URIs and compared exactly
+//
+// https://www.rfc-editor.org/rfc/rfc8288#section-2.1
+func relation(rel string) string {
+	if strings.Contains(rel, ":") {
+		return rel
+	}
+
+	return strings.ToLower(rel)
+}
+
+// parseLinkParams reads the ;name=value parameters after a link target,
+// returning what follows them
+func parseLinkParams(v string) (map[string]string, string) {
+	params := make(map[string]string)
+
+	for {
+		v = strings.TrimLeft(v, " \t")
+		if v == "" || v[0] != ';' {
+			return params, v
+		}
+
+		v = strings.TrimLeft(v[1:], " \t")
+
+		end := strings.IndexAny(v, "=;,")
+		if end < 0 {
+			end = len(v)
+		}
+
+		name := strings.ToLower(strings.TrimSpace(v[:end]))
+		v = v[end:]
+
+		value := ""
+
+		if v != "" && v[0] == '=' {
+			value, v = parseParamValue(strings.TrimLeft(v[1:], " \t"))
+		}
+
+		// The first of a repeated parameter counts
+		if _, ok := params[name]; !ok && name != "" {
+			params[name] = value
+		}
+	}
+}
+
+// parseParamValue reads a token or quoted string, returning what follows
+// it
+func parseParamValue(v string) (string, string) {
+	if v == "" || v[0] != '"' {
+		end := strings.IndexAny(v, ";, \t")
+		if end < 0 {
+			end = len(v)
+		}
+
+		return v[:end], v[end:]
+	}
+
+	var b strings.Builder
+
+	for i := 1; i < len(v); i++ {
+		switch v[i] {
+		case '\\':
+			if i+1 < len(v) {
+				i++
+				b.WriteByte(v[i])
+			}
+		case '"':
+			return b.String(), v[i+1:]
+		default:
+			b.WriteByte(v[i])
+		}
+	}
+
+	return b.String(), ""
+}
+
+// skipLink moves past a malformed link to the next one
+func skipLink(v string) string {
+	inQuotes := false
+
+	for i := 0; i < len(v); i++ {
+		switch v[i] {
+		case '"':
+			inQuotes = !inQuotes
+		case '\\':
+			i++
+		case ',':
+			if !inQuotes {
+				return v[i+1:]
+			}
+		}
+	}
+
+	return ""
+}
+
+// WACAllow is the WAC-Allow header, the access modes each permission
+// group has to a resource. The groups Solid servers give are "user", the
+// agent making the request, and "public", everyone
+//
+// https://solidproject.org/TR/wac#wac-allow
+type WACAllow map[string][]string
+
+// ParseWACAllow reads a WAC-Allow header value, skipping anything
+// malformed. Groups and modes are lower cased
+func ParseWACAllow(v string) WACAllow {
+	allow := make(WACAllow)
+
+	for v != "" {
+		v = strings.TrimLeft(v, " \t,")
+
+		end := strings.IndexAny(v, "=,")
+		if end < 0 {
+			break
+		}
+
+		group := strings.ToLower(strings.TrimSpace(v[:end]))
+
+		if v[end] == ',' {
+			v = v[end+1:]
+			continue
+		}
+
+		var modes string
+		modes, v = parseParamValue(strings.TrimLeft(v[end+1:], " \t"))
+
+		if group == "" {
+			continue
+		}
+
+		if _, ok := allow[group]; !ok {
+			allow[group] = []string{}
+		}
+
+		for _, m := range strings.Fields(modes) {
+			allow[group] = append(allow[group], strings.ToLower(m))
+		}
+	}
+
+	return allow
+}
+
+// Allows checks whether the group has the mode, such as "read", "write",
+// "append" or "control"
+func (a WACAllow) Allows(group, mode string) bool {
+	for _, m := range a[group] {
+		if m == mode {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,223 @@
+// Package ldn sends notifications to the inboxes resources advertise and
+// reads them back out, with Linked Data Notifications. A Receiver is an
+// inbox that keeps what it is sent in a Solid container
+//
+// https://www.w3.org/TR/ldn/
+package ldn
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/serializer"
+	"github.com/b1scuit/solid/rdf/vocab/ldp"
+	"github.com/b1scuit/solid/solid"
+)
+
+var (
+	ErrNoInbox             = errors.New("ldn: no inbox")
+	ErrInvalidNotification = errors.New("ldn: invalid notification")
+)
+
+const (
+	mediaTypeTurtle = "text/turtle"
+	mediaTypeJSONLD = "application/ld+json"
+
+	relInbox = string(ldp.Inbox)
+
+	maxNotificationSize = 1 << 20
+)
+
+type ClientOption func(*Client)
+
+// WithHTTPClient sets the client inboxes are reached with, one that
+// authenticates requests to read inboxes that aren't public. It defaults
+// to http.DefaultClient
+func WithHTTPClient(h *http.Client) ClientOption {
+	return func(c *Client) {
+		c.http = h
+	}
+}
+
+// A Client sends notifications and reads inboxes
+type Client struct {
+	http  *http.Client
+	solid *solid.Client
+}
+
+func New(opts ...ClientOption) (*Client, error) {
+	c := &Client{
+		http: http.DefaultClient,
+	}
+
+	for _, f := range opts {
+		f(c)
+	}
+
+	s, err := solid.New(solid.WithHTTPClient(c.http))
+	if err != nil {
+		return nil, err
+	}
+
+	c.solid = s
+
+	return c, nil
+}
+
+func MustNew(opts ...ClientOption) *Client {
+	c, err := New(opts...)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
+
+// A Notification is a notification read from an inbox
+type Notification struct {
+	URL   string
+	Graph *rdf.Graph
+}
+
+// Inbox finds the inbox of a resource, from a Link header with the
+// ldp:inbox relation or failing that an ldp:inbox the resource's own RDF
+// gives it. A target with a fragment, like a WebID, is looked up in its
+// document
+//
+// https://www.w3.org/TR/ldn/#discovery
+func (c *Client) Inbox(ctx context.Context, target string) (string, error) {
+	res, err := c.solid.Get(ctx, target)
+	if err != nil {
+		return "", err
+	}
+
+	if urls := res.Link(relInbox); len(urls) > 0 {
+		return urls[0], nil
+	}
+
+	if res.Graph != nil {
+		for _, s := range []string{target, res.URL} {
+			if o, ok := res.Graph.Object(rdf.IRI(s), ldp.Inbox).(rdf.IRI); ok {
+				return string(o), nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("%w: %v", ErrNoInbox, target)
+}
+
+// Send posts a notification to an inbox as JSON-LD, which every receiver
+// accepts, returning the URL the receiver put it at if it said
+func (c *Client) Send(ctx context.Context, inbox string, g *rdf.Graph) (string, error) {
+	if g.Len() == 0 {
+		return "", fmt.Errorf("%w: nothing to send", ErrInvalidNotification)
+	}
+
+	var buf bytes.Buffer
+
+	ser := serializer.MustNew(serializer.WithCommonPrefixes(), serializer.WithPrefixes(g.Prefixes))
+	if err := ser.JSONLD(&buf, g); err != nil {
+		return "", err
+	}
+
+	res, err := c.solid.PostBody(ctx, inbox, mediaTypeJSONLD, &buf)
+	if err != nil {
+		return "", err
+	}
+
+	if res.URL == inbox {
+		return "", nil
+	}
+
+	return res.URL, nil
+}
+
+// Notify sends a notification to the inbox of a resource
+func (c *Client) Notify(ctx context.Context, target string, g *rdf.Graph) (string, error) {
+	inbox, err := c.Inbox(ctx, target)
+	if err != nil {
+		return "", err
+	}
+
+	return c.Send(ctx, inbox, g)
+}
+
+// List returns the URLs of the notifications in an inbox, sorted, which
+// it lists with ldp:contains
+//
+// https://www.w3.org/TR/ldn/#consumer
+func (c *Client) List(ctx context.Context, inbox string) ([]string, error) {
+	res, err := c.solid.Get(ctx, inbox)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Graph == nil {
+		return nil, fmt.Errorf("ldn: %v isn't RDF", res.URL)
+	}
+
+	var urls []string
+
+	seen := map[string]bool{}
+
+	for _, s := range []string{inbox, res.URL} {
+		for _, t := range res.Graph.Match(rdf.IRI(s), ldp.Contains, nil) {
+			if iri, ok := t.Object.(rdf.IRI); ok && !seen[string(iri)] {
+				seen[string(iri)] = true
+				urls = append(urls, string(iri))
+			}
+		}
+	}
+
+	sort.Strings(urls)
+
+	return urls, nil
+}
+
+// Notification reads a notification
+func (c *Client) Notification(ctx context.Context, url string) (*Notification, error) {
+	res, err := c.solid.Get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Graph == nil {
+		return nil, fmt.Errorf("%w: %v isn't RDF", ErrInvalidNotification, res.URL)
+	}
+
+	return &Notification{URL: res.URL, Graph: res.Graph}, nil
+}
+
+// Notifications reads every notification in an inbox. Those that have
+// gone since the inbox was listed are left out
+func (c *Client) Notifications(ctx context.Context, inbox string) ([]*Notification, error) {
+	urls, err := c.List(ctx, inbox)
+	if err != nil {
+		return nil, err
+	}
+
+	var notifications []*Notification
+
+	for _, u := range urls {
+		n, err := c.Notification(ctx, u)
+
+		var e *solid.Error
+		if errors.As(err, &e) && e.StatusCode == http.StatusNotFound {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		notifications = append(notifications, n)
+	}
+
+	return notifications, nil
+}
@@ -0,0 +1,258 @@
+package ldn
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/solid"
+	"github.com/b1scuit/solid/solid/server"
+)
+
+const alice = "https://alice.example/#me"
+
+type bearer string
+
+func (b bearer) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("Authorization", "Bearer "+string(b))
+
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+// newInbox starts a pod whose /inbox/ container keeps what a receiver is
+// sent, and a site with the receiver at /inbox and resources advertising
+// it
+func newInbox(t *testing.T, fn func(context.Context, *Notification) error) (*httptest.Server, *httptest.Server, *http.Client) {
+	pod := httptest.NewServer(server.MustNew(t.TempDir(), server.WithOwner(alice), server.WithAuthenticator(server.Tokens(map[string]string{"alice": alice}))))
+	t.Cleanup(pod.Close)
+
+	h := &http.Client{Transport: bearer("alice")}
+
+	if _, err := solid.MustNew(solid.WithHTTPClient(h)).Put(context.Background(), pod.URL+"/inbox/", rdf.NewGraph()); err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/inbox", MustNewReceiver(pod.URL+"/inbox/", WithReceiverHTTPClient(h), WithHandler(fn)))
+	mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", `</inbox>; rel="http://www.w3.org/ns/ldp#inbox"`)
+		w.Header().Set("Content-Type", "text/html")
+		io.WriteString(w, "<p>Hello</p>")
+	})
+	mux.HandleFunc("/profile", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", mediaTypeTurtle)
+		io.WriteString(w, `<#me> <http://www.w3.org/ns/ldp#inbox> </inbox> .`)
+	})
+	mux.HandleFunc("/nothing", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", mediaTypeTurtle)
+		io.WriteString(w, `<#me> <http://xmlns.com/foaf/0.1/name> "Nobody" .`)
+	})
+
+	site := httptest.NewServer(mux)
+	t.Cleanup(site.Close)
+
+	return pod, site, h
+}
+
+type inboxTest struct {
+	Name   string
+	Target string
+	Inbox  string
+	Err    error
+}
+
+func TestInbox(t *testing.T) {
+	_, site, _ := newInbox(t, nil)
+
+	tests := []inboxTest{
+		{Name: "Link header", Target: "/article", Inbox: "/inbox"},
+		{Name: "Graph", Target: "/profile#me", Inbox: "/inbox"},
+		{Name: "No inbox", Target: "/nothing#me", Err: ErrNoInbox},
+	}
+
+	for _, tc := range tests {
+		inbox, err := MustNew().Inbox(context.Background(), site.URL+tc.Target)
+
+		if !errors.Is(err, tc.Err) {
+			t.Errorf("%v test fail: got error %v", tc.Name, err)
+			continue
+		}
+
+		if tc.Err == nil && inbox != site.URL+tc.Inbox {
+			t.Errorf("%v test fail: got %v", tc.Name, inbox)
+		}
+	}
+}
+
+type receiveTest struct {
+	Name        string
+	ContentType string
+	Body        string
+	Status      int
+}
+
+type listTest struct {
+	Name        string
+	Accept      string
+	Status      int
+	ContentType string
+}
+
+func TestReceiver(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []*Notification
+	)
+
+	pod, site, h := newInbox(t, func(ctx context.Context, n *Notification) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		received = append(received, n)
+
+		return nil
+	})
+
+	ctx := context.Background()
+	c := MustNew()
+
+	article := rdf.IRI(site.URL + "/article")
+	g := rdf.NewGraph(
+		rdf.NewTriple(rdf.BlankNode("n"), rdf.RDFType, rdf.IRI("https://www.w3.org/ns/activitystreams#Announce")),
+		rdf.NewTriple(rdf.BlankNode("n"), rdf.IRI("https://www.w3.org/ns/activitystreams#object"), article),
+		rdf.NewTriple(rdf.BlankNode("n"), rdf.IRI("https://www.w3.org/ns/activitystreams#summary"), rdf.NewLangLiteral("Read this", "en")),
+	)
+
+	sent, err := c.Notify(ctx, string(article), g)
+	if err != nil || !strings.HasPrefix(sent, pod.URL+"/inbox/") {
+		t.Fatalf("notify test fail: %v, %v", sent, err)
+	}
+
+	if len(received) != 1 || received[0].URL != sent || !rdf.Isomorphic(received[0].Graph, g) {
+		t.Errorf("handler test fail: got %+v", received)
+	}
+
+	tests := []receiveTest{
+		{
+			Name:        "Turtle",
+			ContentType: "text/turtle; charset=utf-8",
+			Body:        `<#it> <https://www.w3.org/ns/activitystreams#object> </article> .`,
+			Status:      http.StatusCreated,
+		},
+		{
+			Name:        "ActivityStreams",
+			ContentType: mediaTypeJSONLD,
+			Body: `{"@context": "https://www.w3.org/ns/activitystreams", "type": "Announce",
+				"actor": "https://bob.example/#me", "object": "/article", "summary": "Read this"}`,
+			Status: http.StatusCreated,
+		},
+		{
+			Name:        "Invalid Turtle",
+			ContentType: mediaTypeTurtle,
+			Body:        `<#it> <https://www.w3.org/ns/activitystreams#object> .`,
+			Status:      http.StatusBadRequest,
+		},
+		{
+			Name:        "Invalid JSON-LD",
+			ContentType: mediaTypeJSONLD,
+			Body:        `{"@id": `,
+			Status:      http.StatusBadRequest,
+		},
+		{
+			Name:        "Empty",
+			ContentType: mediaTypeJSONLD,
+			Body:        `{}`,
+			Status:      http.StatusBadRequest,
+		},
+		{
+			Name:        "Unsupported type",
+			ContentType: "text/plain",
+			Body:        `Hello`,
+			Status:      http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tc := range tests {
+		res, err := http.Post(site.URL+"/inbox", tc.ContentType, strings.NewReader(tc.Body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res.Body.Close()
+
+		if res.StatusCode != tc.Status {
+			t.Errorf("%v test fail: got %v, expected %v", tc.Name, res.StatusCode, tc.Status)
+		}
+
+		if res.Header.Get("Accept-Post") != acceptPost {
+			t.Errorf("%v test fail: Accept-Post %q", tc.Name, res.Header.Get("Accept-Post"))
+		}
+	}
+
+	actor := rdf.IRI("https://www.w3.org/ns/activitystreams#actor")
+	if len(received) != 3 || len(received[2].Graph.Match(nil, actor, rdf.IRI("https://bob.example/#me"))) != 1 {
+		t.Errorf("activitystreams test fail: got %+v", received)
+	}
+
+	if len(received) != 3 || !received[1].Graph.Has(rdf.NewTriple(rdf.IRI(site.URL+"/inbox#it"), rdf.IRI("https://www.w3.org/ns/activitystreams#object"), article)) {
+		t.Errorf("turtle test fail: relative IRIs not resolved against the inbox, got %+v", received)
+	}
+
+	// Anyone can list the inbox, the notifications are only readable by
+	// the pod's owner
+	urls, err := c.List(ctx, site.URL+"/inbox")
+	if err != nil || len(urls) != 3 || !slices.Contains(urls, sent) {
+		t.Fatalf("list test fail: %v, %v", urls, err)
+	}
+
+	if _, err := c.Notification(ctx, sent); err == nil {
+		t.Errorf("notification test fail: read without signing in")
+	}
+
+	notifications, err := MustNew(WithHTTPClient(h)).Notifications(ctx, site.URL+"/inbox")
+	if err != nil || len(notifications) != 3 {
+		t.Fatalf("notifications test fail: %+v, %v", notifications, err)
+	}
+
+	for _, n := range notifications {
+		if n.URL == sent && !rdf.Isomorphic(n.Graph, g) {
+			t.Errorf("notifications test fail: got %v", n.Graph.Triples())
+		}
+	}
+
+	for _, tc := range []listTest{
+		{"Default", "", http.StatusOK, mediaTypeJSONLD},
+		{"Turtle", mediaTypeTurtle, http.StatusOK, mediaTypeTurtle},
+		{"JSON-LD refused", "application/ld+json;q=0, text/turtle", http.StatusOK, mediaTypeTurtle},
+		{"Preferred", "text/turtle;q=0.5, application/ld+json", http.StatusOK, mediaTypeJSONLD},
+		{"Not acceptable", "image/png", http.StatusNotAcceptable, ""},
+	} {
+		req, _ := http.NewRequest(http.MethodGet, site.URL+"/inbox", nil)
+		req.Header.Set("Accept", tc.Accept)
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+
+		if res.StatusCode != tc.Status {
+			t.Errorf("%v test fail: got status %v", tc.Name, res.StatusCode)
+			continue
+		}
+
+		if tc.Status == http.StatusOK && (res.Header.Get("Content-Type") != tc.ContentType || !strings.Contains(string(b), "ldp:contains")) {
+			t.Errorf("%v test fail: %v %s", tc.Name, res.Header.Get("Content-Type"), b)
+		}
+	}
+}
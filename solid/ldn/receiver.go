@@ -0,0 +1,238 @@
+package ldn
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+
+	"github.com/b1scuit/solid/internal/conneg"
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/jsonld"
+	"github.com/b1scuit/solid/rdf/parser"
+	"github.com/b1scuit/solid/rdf/serializer"
+	"github.com/b1scuit/solid/rdf/vocab/ldp"
+	"github.com/b1scuit/solid/solid"
+)
+
+// The formats notifications can be posted in, JSON-LD being the one
+// senders are required to use
+const acceptPost = mediaTypeJSONLD + ", " + mediaTypeTurtle
+
+// The formats the inbox is listed in, JSON-LD first as it is the one
+// consumers have to be able to read
+var listTypes = []string{mediaTypeJSONLD, mediaTypeTurtle}
+
+type ReceiverOption func(*Receiver)
+
+// WithReceiverHTTPClient sets the client the container is reached with,
+// one that can append to it and read it. It defaults to
+// http.DefaultClient
+func WithReceiverHTTPClient(h *http.Client) ReceiverOption {
+	return func(r *Receiver) {
+		r.http = h
+	}
+}
+
+// WithHandler calls fn with each notification once it is stored, for
+// acting on them as they arrive. An error from fn fails the request,
+// though the notification stays in the inbox
+func WithHandler(fn func(context.Context, *Notification) error) ReceiverOption {
+	return func(r *Receiver) {
+		r.handler = fn
+	}
+}
+
+// A Receiver is an inbox, an http.Handler for the inbox's URL. Posted
+// notifications that parse as JSON-LD or Turtle are kept as resources in
+// a Solid container, and reading the inbox lists them with ldp:contains.
+// Relative IRIs in a notification are resolved against the inbox
+//
+// https://www.w3.org/TR/ldn/#receiver
+type Receiver struct {
+	container string
+	http      *http.Client
+	solid     *solid.Client
+	handler   func(context.Context, *Notification) error
+}
+
+// NewReceiver makes an inbox keeping notifications in a container
+func NewReceiver(container string, opts ...ReceiverOption) (*Receiver, error) {
+	if !solid.IsContainerURL(container) {
+		return nil, fmt.Errorf("%w: %v", solid.ErrNotContainer, container)
+	}
+
+	r := &Receiver{
+		container: container,
+		http:      http.DefaultClient,
+	}
+
+	for _, f := range opts {
+		f(r)
+	}
+
+	s, err := solid.New(solid.WithHTTPClient(r.http))
+	if err != nil {
+		return nil, err
+	}
+
+	r.solid = s
+
+	return r, nil
+}
+
+func MustNewReceiver(container string, opts ...ReceiverOption) *Receiver {
+	r, err := NewReceiver(container, opts...)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return r
+}
+
+func (rc *Receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Accept-Post", acceptPost)
+
+	switch r.Method {
+	case http.MethodPost:
+		rc.receive(w, r)
+	case http.MethodGet, http.MethodHead:
+		rc.list(w, r)
+	case http.MethodOptions:
+		w.Header().Set("Allow", "GET, HEAD, OPTIONS, POST")
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, HEAD, OPTIONS, POST")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// receive checks a posted notification parses and stores it
+func (rc *Receiver) receive(w http.ResponseWriter, r *http.Request) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != mediaTypeJSONLD && mediaType != mediaTypeTurtle {
+		http.Error(w, "Notifications are JSON-LD or Turtle", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	b, err := io.ReadAll(io.LimitReader(r.Body, maxNotificationSize+1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(b) > maxNotificationSize {
+		http.Error(w, "Notification too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	g, err := parse(b, mediaType, requestURL(r))
+	if err == nil && g.Len() == 0 {
+		err = fmt.Errorf("%w: no triples", ErrInvalidNotification)
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res, err := rc.solid.Post(r.Context(), rc.container, g)
+	if err != nil {
+		slog.Error("Error storing notification", slog.String("container", rc.container), slog.Any("error", err))
+		http.Error(w, "Notification couldn't be stored", http.StatusBadGateway)
+
+		return
+	}
+
+	if rc.handler != nil {
+		if err := rc.handler(r.Context(), &Notification{URL: res.URL, Graph: g}); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Location", res.URL)
+	w.WriteHeader(http.StatusCreated)
+}
+
+// list answers with the notifications in the container, as JSON-LD
+// unless Turtle is preferred
+func (rc *Receiver) list(w http.ResponseWriter, r *http.Request) {
+	container, err := rc.solid.Container(r.Context(), rc.container)
+	if err != nil {
+		slog.Error("Error reading inbox", slog.String("container", rc.container), slog.Any("error", err))
+		http.Error(w, "Inbox couldn't be read", http.StatusBadGateway)
+
+		return
+	}
+
+	inbox := rdf.IRI(requestURL(r))
+
+	g := rdf.NewGraph(rdf.NewTriple(inbox, rdf.RDFType, ldp.Container))
+	for _, m := range container.Members {
+		g.Add(rdf.NewTriple(inbox, ldp.Contains, rdf.IRI(m)))
+	}
+
+	var buf bytes.Buffer
+
+	ser := serializer.MustNew(serializer.WithCommonPrefixes())
+
+	w.Header().Add("Vary", "Accept")
+
+	switch conneg.Negotiate(r.Header.Get("Accept"), listTypes) {
+	case mediaTypeJSONLD:
+		w.Header().Set("Content-Type", mediaTypeJSONLD)
+		err = ser.JSONLD(&buf, g)
+	case mediaTypeTurtle:
+		w.Header().Set("Content-Type", mediaTypeTurtle)
+		err = ser.Turtle(&buf, g)
+	default:
+		http.Error(w, "The inbox is JSON-LD or Turtle", http.StatusNotAcceptable)
+		return
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		w.Write(buf.Bytes())
+	}
+}
+
+func parse(b []byte, mediaType, base string) (*rdf.Graph, error) {
+	if mediaType == mediaTypeJSONLD {
+		g, err := jsonld.Parse(bytes.NewReader(b), base)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidNotification, err)
+		}
+
+		return g, nil
+	}
+
+	p, err := parser.New(parser.WithBase(base))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.Do(bytes.NewReader(b)); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidNotification, err)
+	}
+
+	return p.GetGraph(), nil
+}
+
+// requestURL is the URL the inbox was reached at
+func requestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	return scheme + "://" + r.Host + r.URL.Path
+}
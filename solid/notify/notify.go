@@ -0,0 +1,432 @@
+// Package notify subscribes to changes of the resources on Solid pods
+// with the Solid Notifications Protocol, over WebSockets, streaming HTTP
+// responses or webhooks
+//
+// https://solidproject.org/TR/notifications-protocol
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/b1scuit/solid/rdf"
+	vocab "github.com/b1scuit/solid/rdf/vocab/notify"
+	terms "github.com/b1scuit/solid/rdf/vocab/solid"
+	"github.com/b1scuit/solid/solid"
+)
+
+var (
+	ErrNoChannel           = errors.New("notify: no channel of the types asked for")
+	ErrInvalidNotification = errors.New("notify: invalid notification")
+)
+
+const (
+	mediaTypeJSONLD = "application/ld+json"
+
+	// The JSON-LD context channels are compacted with
+	notificationContext = "https://www.w3.org/ns/solid/notification/v1"
+
+	relStorageDescription = string(terms.StorageDescription)
+	relStreamingHTTP      = string(terms.UpdatesViaStreamingHttp2023)
+
+	maxResponseSize = 1 << 20
+)
+
+type ClientOption func(*Client)
+
+// WithHTTPClient sets the client pods are reached with, one that
+// authenticates requests to read resources that aren't public. It
+// defaults to http.DefaultClient
+func WithHTTPClient(h *http.Client) ClientOption {
+	return func(c *Client) {
+		c.http = h
+	}
+}
+
+// WithBackoff sets how long Listen waits before reconnecting, doubling
+// from min up to max while reconnecting keeps failing. It defaults to a
+// second up to a minute
+func WithBackoff(min, max time.Duration) ClientOption {
+	return func(c *Client) {
+		c.minBackoff, c.maxBackoff = min, max
+	}
+}
+
+// A Client subscribes to notifications
+type Client struct {
+	http       *http.Client
+	solid      *solid.Client
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+func New(opts ...ClientOption) (*Client, error) {
+	c := &Client{
+		http:       http.DefaultClient,
+		minBackoff: time.Second,
+		maxBackoff: time.Minute,
+	}
+
+	for _, f := range opts {
+		f(c)
+	}
+
+	if c.minBackoff <= 0 || c.maxBackoff < c.minBackoff {
+		return nil, fmt.Errorf("notify: invalid backoff %v to %v", c.minBackoff, c.maxBackoff)
+	}
+
+	s, err := solid.New(solid.WithHTTPClient(c.http))
+	if err != nil {
+		return nil, err
+	}
+
+	c.solid = s
+
+	return c, nil
+}
+
+func MustNew(opts ...ClientOption) *Client {
+	c, err := New(opts...)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
+
+// A Service is where channels of a type are subscribed to, with the
+// features it supports such as notify:rate. StreamingHTTPChannel2023
+// needs no subscribing, its service URL is the channel to receive from
+//
+// https://solidproject.org/TR/notifications-protocol#discovery
+type Service struct {
+	URL         string
+	ChannelType rdf.IRI
+	Features    []rdf.IRI
+}
+
+// Services finds the channels notifications about a resource can be
+// received over, from the description of the storage it is in and the
+// streaming HTTP channel the resource links to
+func (c *Client) Services(ctx context.Context, topic string) ([]Service, error) {
+	res, err := c.solid.Head(ctx, topic)
+	if err != nil {
+		return nil, err
+	}
+
+	var services []Service
+
+	for _, u := range res.Link(relStreamingHTTP) {
+		services = append(services, Service{URL: u, ChannelType: vocab.StreamingHTTPChannel2023})
+	}
+
+	for _, u := range res.Link(relStorageDescription) {
+		desc, err := c.solid.Get(ctx, u)
+		if err != nil {
+			return nil, err
+		}
+
+		if desc.Graph == nil {
+			return nil, fmt.Errorf("notify: storage description %v isn't RDF", u)
+		}
+
+		for _, t := range desc.Graph.Match(nil, vocab.Subscription, nil) {
+			for _, ct := range desc.Graph.Match(t.Object, vocab.ChannelType, nil) {
+				typ, ok := ct.Object.(rdf.IRI)
+				iri, isIRI := t.Object.(rdf.IRI)
+
+				if !ok || !isIRI {
+					continue
+				}
+
+				s := Service{URL: string(iri), ChannelType: typ}
+
+				for _, f := range desc.Graph.Match(t.Object, vocab.Feature, nil) {
+					if feature, ok := f.Object.(rdf.IRI); ok {
+						s.Features = append(s.Features, feature)
+					}
+				}
+
+				sort.Slice(s.Features, func(i, j int) bool { return s.Features[i] < s.Features[j] })
+				services = append(services, s)
+			}
+		}
+	}
+
+	return services, nil
+}
+
+// Service finds the first service with one of the channel types, in the
+// order they are given
+func (c *Client) Service(ctx context.Context, topic string, types ...rdf.IRI) (Service, error) {
+	services, err := c.Services(ctx, topic)
+	if err != nil {
+		return Service{}, err
+	}
+
+	for _, t := range types {
+		for _, s := range services {
+			if s.ChannelType == t {
+				return s, nil
+			}
+		}
+	}
+
+	return Service{}, fmt.Errorf("%w: %v", ErrNoChannel, topic)
+}
+
+// A Channel is a subscription to notifications about Topic. They are
+// received from ReceiveFrom, or sent to SendTo for webhooks
+//
+// https://solidproject.org/TR/notifications-protocol#notification-channel-data-model
+type Channel struct {
+	ID          string
+	Type        rdf.IRI
+	Topic       string
+	ReceiveFrom string
+	SendTo      string
+	Sender      string
+	StartAt     time.Time
+	EndAt       time.Time
+
+	// Rate is the least time between notifications, an xsd:duration
+	// such as PT10S
+	Rate string
+}
+
+// A Subscription asks for a channel. SendTo is where a webhook channel
+// posts notifications, the other fields are optional features
+type Subscription struct {
+	Topic  string
+	SendTo string
+	State  string
+	Rate   string
+	Accept string
+}
+
+// Subscribe asks the service for a channel
+//
+// https://solidproject.org/TR/notifications-protocol#subscription-client
+func (c *Client) Subscribe(ctx context.Context, s Service, sub Subscription) (*Channel, error) {
+	if s.ChannelType == vocab.StreamingHTTPChannel2023 {
+		return &Channel{Type: s.ChannelType, Topic: sub.Topic, ReceiveFrom: s.URL}, nil
+	}
+
+	body := map[string]any{
+		"@context": []string{notificationContext},
+		"type":     compact(s.ChannelType),
+		"topic":    sub.Topic,
+	}
+
+	for k, v := range map[string]string{"sendTo": sub.SendTo, "state": sub.State, "rate": sub.Rate, "accept": sub.Accept} {
+		if v != "" {
+			body[k] = v
+		}
+	}
+
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", mediaTypeJSONLD)
+	req.Header.Set("Accept", mediaTypeJSONLD)
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	b, err = io.ReadAll(io.LimitReader(res.Body, maxResponseSize))
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, &solid.Error{Method: req.Method, URL: s.URL, StatusCode: res.StatusCode, Status: res.Status, Body: string(b)}
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type")); mediaType != mediaTypeJSONLD && mediaType != "application/json" {
+		return nil, fmt.Errorf("notify: %v answered with %v, not JSON-LD", s.URL, mediaType)
+	}
+
+	ch, err := ParseChannel(b)
+	if err != nil {
+		return nil, fmt.Errorf("notify: subscribing at %v: %w", s.URL, err)
+	}
+
+	return ch, nil
+}
+
+// ParseChannel reads a channel's description, compacted JSON-LD as
+// subscription services answer with
+func ParseChannel(b []byte) (*Channel, error) {
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+
+	ch := &Channel{
+		ID:          str(m, "id"),
+		Type:        expand(str(m, "type")),
+		Topic:       str(m, "topic"),
+		ReceiveFrom: str(m, "receiveFrom"),
+		SendTo:      str(m, "sendTo"),
+		Sender:      str(m, "sender"),
+		Rate:        str(m, "rate"),
+	}
+
+	var err error
+	if ch.StartAt, err = timestamp(m, "startAt"); err != nil {
+		return nil, err
+	}
+
+	if ch.EndAt, err = timestamp(m, "endAt"); err != nil {
+		return nil, err
+	}
+
+	if ch.Type == "" || ch.Topic == "" {
+		return nil, errors.New("notify: channel has no type or topic")
+	}
+
+	return ch, nil
+}
+
+// An Activity is what happened to a resource
+type Activity string
+
+const (
+	Create Activity = "Create"
+	Update Activity = "Update"
+	Delete Activity = "Delete"
+
+	// Add and Remove are sent about a container, Object being what was
+	// added to or removed from Target
+	Add    Activity = "Add"
+	Remove Activity = "Remove"
+)
+
+// A Notification is an Activity Streams activity telling of a change to
+// Object. State is its new state, such as an ETag
+//
+// https://solidproject.org/TR/notifications-protocol#notification-data-model
+type Notification struct {
+	ID        string
+	Type      Activity
+	Object    string
+	Target    string
+	State     string
+	Published time.Time
+}
+
+// ParseNotification reads a notification, compacted JSON-LD as channels
+// send them
+func ParseNotification(b []byte) (*Notification, error) {
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidNotification, err)
+	}
+
+	typ := str(m, "type")
+	typ = typ[strings.LastIndexAny(typ, "#:/")+1:]
+
+	n := &Notification{
+		ID:     str(m, "id"),
+		Type:   Activity(typ),
+		Object: str(m, "object"),
+		Target: str(m, "target"),
+		State:  str(m, "state"),
+	}
+
+	published, err := timestamp(m, "published")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidNotification, err)
+	}
+
+	n.Published = published
+
+	if n.Type == "" || n.Object == "" {
+		return nil, fmt.Errorf("%w: no type or object", ErrInvalidNotification)
+	}
+
+	return n, nil
+}
+
+// str reads a compacted JSON-LD value: a string, a node's id or the first
+// of several values. Keys are looked for with and without the @
+func str(m map[string]any, key string) string {
+	v, ok := m[key]
+	if !ok {
+		v = m["@"+key]
+	}
+
+	for {
+		switch value := v.(type) {
+		case string:
+			return value
+		case []any:
+			if len(value) == 0 {
+				return ""
+			}
+
+			v = value[0]
+		case map[string]any:
+			if id := str(value, "id"); id != "" {
+				return id
+			}
+
+			v = value["@value"]
+		default:
+			return ""
+		}
+	}
+}
+
+func timestamp(m map[string]any, key string) (time.Time, error) {
+	s := str(m, key)
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("notify: %v isn't a time: %v", key, err)
+	}
+
+	return t, nil
+}
+
+// compact gives the term the notifications context names a channel type
+// by
+func compact(t rdf.IRI) string {
+	return strings.TrimPrefix(string(t), string(vocab.Namespace))
+}
+
+// expand gives the IRI of a channel type named by the notifications
+// context, or with the notify prefix
+func expand(t string) rdf.IRI {
+	t = strings.TrimPrefix(t, "notify:")
+
+	if t == "" || strings.Contains(t, ":") {
+		return rdf.IRI(t)
+	}
+
+	return vocab.Term(t)
+}
@@ -0,0 +1,454 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/b1scuit/solid/rdf"
+	vocab "github.com/b1scuit/solid/rdf/vocab/notify"
+	"github.com/b1scuit/solid/solid"
+)
+
+type notificationTest struct {
+	Name     string
+	JSON     string
+	Expected *Notification
+	Err      error
+}
+
+var published = time.Date(2023, 2, 9, 15, 8, 12, 345000000, time.UTC)
+
+var notificationTests = []notificationTest{
+	{"Compacted", `{
+		"@context": ["https://www.w3.org/ns/activitystreams", "https://www.w3.org/ns/solid/notification/v1"],
+		"id": "urn:uuid:1", "type": "Update", "object": "https://pod.example/doc",
+		"state": "\"abc\"", "published": "2023-02-09T15:08:12.345Z"}`,
+		&Notification{ID: "urn:uuid:1", Type: Update, Object: "https://pod.example/doc", State: `"abc"`, Published: published}, nil},
+	{"Full IRIs and nodes", `{
+		"@id": "urn:uuid:2", "@type": ["https://www.w3.org/ns/activitystreams#Add"],
+		"object": {"id": "https://pod.example/c/doc"}, "target": {"@id": "https://pod.example/c/"},
+		"published": {"@value": "2023-02-09T15:08:12.345Z"}}`,
+		&Notification{ID: "urn:uuid:2", Type: Add, Object: "https://pod.example/c/doc", Target: "https://pod.example/c/", Published: published}, nil},
+	{"Prefixed type", `{"type": "as:Delete", "object": "https://pod.example/doc"}`,
+		&Notification{Type: Delete, Object: "https://pod.example/doc"}, nil},
+	{"No object", `{"type": "Update"}`, nil, ErrInvalidNotification},
+	{"Bad time", `{"type": "Update", "object": "https://pod.example/doc", "published": "yesterday"}`, nil, ErrInvalidNotification},
+	{"Not JSON", `Update`, nil, ErrInvalidNotification},
+}
+
+func TestParse(t *testing.T) {
+	for _, tc := range notificationTests {
+		n, err := ParseNotification([]byte(tc.JSON))
+		if !errors.Is(err, tc.Err) || !reflect.DeepEqual(n, tc.Expected) {
+			t.Errorf("%v test fail: got %+v, %v", tc.Name, n, err)
+		}
+	}
+
+	ch, err := ParseChannel([]byte(`{
+		"@context": "https://www.w3.org/ns/solid/notification/v1",
+		"id": "https://pod.example/channels/1", "type": "notify:WebSocketChannel2023",
+		"topic": "https://pod.example/doc", "receiveFrom": "wss://pod.example/ws/1",
+		"endAt": "2023-02-09T15:08:12.345Z", "rate": "PT10S"}`))
+
+	expected := &Channel{
+		ID:          "https://pod.example/channels/1",
+		Type:        vocab.WebSocketChannel2023,
+		Topic:       "https://pod.example/doc",
+		ReceiveFrom: "wss://pod.example/ws/1",
+		EndAt:       published,
+		Rate:        "PT10S",
+	}
+
+	if err != nil || !reflect.DeepEqual(ch, expected) {
+		t.Errorf("channel test fail: got %+v, %v", ch, err)
+	}
+
+	if _, err := ParseChannel([]byte(`{"id": "x"}`)); err == nil {
+		t.Errorf("channel test fail: no type or topic accepted")
+	}
+}
+
+// pod is a stand-in server for a resource at /doc, offering notifications
+// about it over every channel type
+type pod struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	sockets       map[*conn]bool
+	streams       map[chan []byte]bool
+	webhooks      []string
+	subscriptions int
+	refuse        int
+
+	// connected is sent to whenever a WebSocket or stream connects
+	connected chan struct{}
+}
+
+func newPod(t *testing.T) *pod {
+	p := &pod{
+		sockets:   map[*conn]bool{},
+		streams:   map[chan []byte]bool{},
+		connected: make(chan struct{}, 16),
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/doc", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Link", `</.well-known/solid>; rel="http://www.w3.org/ns/solid/terms#storageDescription"`)
+		w.Header().Add("Link", `</stream>; rel="http://www.w3.org/ns/solid/terms#updatesViaStreamingHttp2023"`)
+		w.Header().Set("Content-Type", "text/turtle")
+	})
+
+	mux.HandleFunc("/.well-known/solid", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/turtle")
+		fmt.Fprint(w, `@prefix notify: <http://www.w3.org/ns/solid/notifications#> .
+			</> notify:subscription </subscribe/websocket>, </subscribe/webhook> .
+			</subscribe/websocket> notify:channelType notify:WebSocketChannel2023 ; notify:feature notify:rate, notify:endAt .
+			</subscribe/webhook> notify:channelType notify:WebhookChannel2023 .`)
+	})
+
+	mux.HandleFunc("/subscribe/", p.subscribe)
+	mux.HandleFunc("/ws", p.socket)
+	mux.HandleFunc("/stream", p.stream)
+
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+
+	return p
+}
+
+func (p *pod) subscribe(w http.ResponseWriter, r *http.Request) {
+	var req map[string]any
+
+	if r.Method != http.MethodPost || r.Header.Get("Content-Type") != mediaTypeJSONLD || json.NewDecoder(r.Body).Decode(&req) != nil {
+		http.Error(w, "Expected a JSON-LD subscription", http.StatusBadRequest)
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.refuse != 0 {
+		http.Error(w, "Refused", p.refuse)
+		return
+	}
+
+	p.subscriptions++
+
+	ch := map[string]any{
+		"@context": notificationContext,
+		"id":       fmt.Sprintf("%v/channels/%v", p.URL, p.subscriptions),
+		"type":     req["type"],
+		"topic":    req["topic"],
+	}
+
+	switch {
+	case r.URL.Path == "/subscribe/websocket" && req["type"] == "WebSocketChannel2023":
+		ch["receiveFrom"] = "ws" + strings.TrimPrefix(p.URL, "http") + "/ws"
+	case r.URL.Path == "/subscribe/webhook" && req["type"] == "WebhookChannel2023" && req["sendTo"] != nil:
+		ch["sendTo"] = req["sendTo"]
+		p.webhooks = append(p.webhooks, req["sendTo"].(string))
+	default:
+		http.Error(w, "Unsupported channel", http.StatusUnprocessableEntity)
+		return
+	}
+
+	w.Header().Set("Content-Type", mediaTypeJSONLD)
+	json.NewEncoder(w).Encode(ch)
+}
+
+func (p *pod) socket(w http.ResponseWriter, r *http.Request) {
+	c, err := accept(w, r)
+	if err != nil {
+		return
+	}
+
+	p.mu.Lock()
+	p.sockets[c] = true
+	p.mu.Unlock()
+
+	p.connected <- struct{}{}
+
+	// Read until the client goes, answering its close
+	for {
+		if _, err := c.read(); err != nil {
+			break
+		}
+	}
+
+	p.mu.Lock()
+	delete(p.sockets, c)
+	p.mu.Unlock()
+
+	c.rwc.Close()
+}
+
+func (p *pod) stream(w http.ResponseWriter, r *http.Request) {
+	messages := make(chan []byte, 16)
+
+	p.mu.Lock()
+	p.streams[messages] = true
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		delete(p.streams, messages)
+		p.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", mediaTypeJSONLD)
+	w.WriteHeader(http.StatusOK)
+	w.(http.Flusher).Flush()
+
+	p.connected <- struct{}{}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case b := <-messages:
+			w.Write(b)
+			w.(http.Flusher).Flush()
+		}
+	}
+}
+
+// publish sends a notification over every channel
+func (p *pod) publish(t *testing.T, typ Activity, state string) {
+	b, _ := json.Marshal(map[string]any{
+		"@context":  []string{"https://www.w3.org/ns/activitystreams", notificationContext},
+		"id":        "urn:uuid:" + state,
+		"type":      typ,
+		"object":    p.URL + "/doc",
+		"state":     state,
+		"published": time.Now().UTC().Format(time.RFC3339),
+	})
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for c := range p.sockets {
+		if err := c.write(opText, b); err != nil {
+			t.Errorf("publishing: %v", err)
+		}
+	}
+
+	for s := range p.streams {
+		s <- b
+	}
+
+	for _, u := range p.webhooks {
+		res, err := http.Post(u, mediaTypeJSONLD, bytes.NewReader(b))
+		if err != nil || res.StatusCode != http.StatusNoContent {
+			t.Errorf("webhook to %v failed: %v, %v", u, res, err)
+		}
+	}
+}
+
+// refuseWith makes subscribing fail with the status
+func (p *pod) refuseWith(status int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.refuse = status
+}
+
+// drop closes every WebSocket
+func (p *pod) drop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for c := range p.sockets {
+		c.close()
+	}
+}
+
+func waitFor[T any](t *testing.T, c <-chan T) T {
+	select {
+	case v := <-c:
+		return v
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out")
+	}
+
+	var zero T
+	return zero
+}
+
+func TestServices(t *testing.T) {
+	p := newPod(t)
+	c := MustNew()
+
+	services, err := c.Services(context.Background(), p.URL+"/doc")
+
+	expected := []Service{
+		{p.URL + "/stream", vocab.StreamingHTTPChannel2023, nil},
+		{p.URL + "/subscribe/webhook", vocab.WebhookChannel2023, nil},
+		{p.URL + "/subscribe/websocket", vocab.WebSocketChannel2023, []rdf.IRI{vocab.EndAt, vocab.Rate}},
+	}
+
+	if err != nil || !reflect.DeepEqual(services, expected) {
+		t.Errorf("services test fail: got %v, %v", services, err)
+	}
+
+	if _, err := c.Service(context.Background(), p.URL+"/doc", "https://example.org/PigeonChannel"); !errors.Is(err, ErrNoChannel) {
+		t.Errorf("services test fail: got %v", err)
+	}
+}
+
+func TestWebSocket(t *testing.T) {
+	p := newPod(t)
+	c := MustNew(WithBackoff(10*time.Millisecond, 50*time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	received := make(chan *Notification)
+	done := make(chan error)
+
+	go func() {
+		done <- c.Listen(ctx, p.URL+"/doc", func(n *Notification) error {
+			received <- n
+			return nil
+		})
+	}()
+
+	waitFor(t, p.connected)
+	p.publish(t, Update, "1")
+
+	if n := waitFor(t, received); n.Type != Update || n.State != "1" || n.Object != p.URL+"/doc" {
+		t.Errorf("websocket test fail: got %+v", n)
+	}
+
+	// Dropped connections are subscribed to again
+	p.drop()
+	waitFor(t, p.connected)
+	p.publish(t, Delete, "2")
+
+	if n := waitFor(t, received); n.Type != Delete || n.State != "2" {
+		t.Errorf("websocket test fail: got %+v after reconnecting", n)
+	}
+
+	cancel()
+
+	if err := waitFor(t, done); !errors.Is(err, context.Canceled) {
+		t.Errorf("websocket test fail: listening ended with %v", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.subscriptions != 2 {
+		t.Errorf("websocket test fail: %v subscriptions", p.subscriptions)
+	}
+}
+
+func TestStreaming(t *testing.T) {
+	p := newPod(t)
+	c := MustNew()
+
+	var states []string
+	errStop := errors.New("stop")
+
+	done := make(chan error)
+
+	go func() {
+		done <- c.Listen(context.Background(), p.URL+"/doc", func(n *Notification) error {
+			if states = append(states, n.State); len(states) == 2 {
+				return errStop
+			}
+
+			return nil
+		}, vocab.StreamingHTTPChannel2023)
+	}()
+
+	waitFor(t, p.connected)
+	p.publish(t, Update, "1")
+	p.publish(t, Update, "2")
+
+	if err := waitFor(t, done); err != errStop || !reflect.DeepEqual(states, []string{"1", "2"}) {
+		t.Errorf("streaming test fail: got %v, %v", states, err)
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	p := newPod(t)
+	c := MustNew()
+	ctx := context.Background()
+
+	received := make(chan *Notification, 1)
+
+	app := httptest.NewServer(Handler(func(n *Notification) error {
+		received <- n
+		return nil
+	}))
+	defer app.Close()
+
+	s, err := c.Service(ctx, p.URL+"/doc", vocab.WebhookChannel2023)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ch, err := c.Subscribe(ctx, s, Subscription{Topic: p.URL + "/doc", SendTo: app.URL})
+	if err != nil || ch.Type != vocab.WebhookChannel2023 || ch.SendTo != app.URL || ch.Topic != p.URL+"/doc" {
+		t.Fatalf("webhook test fail: subscribed to %+v, %v", ch, err)
+	}
+
+	p.publish(t, Create, "1")
+
+	if n := waitFor(t, received); n.Type != Create || n.State != "1" {
+		t.Errorf("webhook test fail: got %+v", n)
+	}
+
+	for _, tc := range []struct {
+		Method, Body string
+		Status       int
+	}{
+		{http.MethodPost, `{"type": "Update"}`, http.StatusBadRequest},
+		{http.MethodGet, "", http.StatusMethodNotAllowed},
+	} {
+		req, _ := http.NewRequest(tc.Method, app.URL, strings.NewReader(tc.Body))
+
+		if res, err := http.DefaultClient.Do(req); err != nil || res.StatusCode != tc.Status {
+			t.Errorf("webhook test fail: %v gave %v, %v", tc.Method, res, err)
+		}
+	}
+}
+
+func TestListenErrors(t *testing.T) {
+	p := newPod(t)
+	c := MustNew(WithBackoff(time.Millisecond, time.Millisecond))
+	ctx := context.Background()
+
+	fn := func(*Notification) error { return nil }
+
+	if err := c.Listen(ctx, p.URL+"/doc", fn, vocab.WebhookChannel2023); err == nil {
+		t.Errorf("listen test fail: listened to a webhook")
+	}
+
+	p.refuseWith(http.StatusForbidden)
+
+	var e *solid.Error
+	if err := c.Listen(ctx, p.URL+"/doc", fn); !errors.As(err, &e) || e.StatusCode != http.StatusForbidden {
+		t.Errorf("listen test fail: refused subscription gave %v", err)
+	}
+
+	// Failures the pod may get over are retried until ctx is done
+	p.refuseWith(http.StatusServiceUnavailable)
+
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+
+	if err := c.Listen(ctx, p.URL+"/doc", fn); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("listen test fail: got %v", err)
+	}
+}
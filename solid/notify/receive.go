@@ -0,0 +1,248 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/b1scuit/solid/rdf"
+	vocab "github.com/b1scuit/solid/rdf/vocab/notify"
+	"github.com/b1scuit/solid/solid"
+)
+
+var ErrClosed = errors.New("notify: channel closed")
+
+// Receive reads notifications from a WebSocket or streaming HTTP channel,
+// calling fn with each, until the connection ends, ctx is done or fn
+// returns an error. Webhook channels are received with a Handler
+func (c *Client) Receive(ctx context.Context, ch *Channel, fn func(*Notification) error) error {
+	return c.receive(ctx, ch, fn, func() {})
+}
+
+// receive is Receive, calling connected once notifications can arrive
+func (c *Client) receive(ctx context.Context, ch *Channel, fn func(*Notification) error, connected func()) error {
+	switch ch.Type {
+	case vocab.WebSocketChannel2023:
+		return c.receiveWebSocket(ctx, ch, fn, connected)
+	case vocab.StreamingHTTPChannel2023:
+		return c.receiveStream(ctx, ch, fn, connected)
+	}
+
+	return fmt.Errorf("notify: can't receive from a %v", ch.Type)
+}
+
+func (c *Client) receiveWebSocket(ctx context.Context, ch *Channel, fn func(*Notification) error, connected func()) error {
+	ws, err := dial(ctx, c.http, ch.ReceiveFrom)
+	if err != nil {
+		return err
+	}
+
+	connected()
+
+	// Reading is interrupted by closing the connection
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+
+		ws.close()
+	}()
+
+	for {
+		b, err := ws.read()
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err != nil {
+			return err
+		}
+
+		n, err := ParseNotification(b)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(n); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) receiveStream(ctx context.Context, ch *Channel, fn func(*Notification) error, connected func()) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ch.ReceiveFrom, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", mediaTypeJSONLD)
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		b, _ := io.ReadAll(io.LimitReader(res.Body, maxResponseSize))
+		return &solid.Error{Method: req.Method, URL: ch.ReceiveFrom, StatusCode: res.StatusCode, Status: res.Status, Body: string(b)}
+	}
+
+	connected()
+
+	// Notifications follow each other in the body
+	dec := json.NewDecoder(res.Body)
+
+	for {
+		var b json.RawMessage
+
+		err := dec.Decode(&b)
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if errors.Is(err, io.EOF) {
+			return ErrClosed
+		}
+
+		if err != nil {
+			return err
+		}
+
+		n, err := ParseNotification(b)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(n); err != nil {
+			return err
+		}
+	}
+}
+
+// Listen receives notifications about the topic until ctx is done or fn
+// returns an error, over the first of the channel types the pod offers,
+// by default WebSocketChannel2023 then StreamingHTTPChannel2023. When the
+// connection drops it subscribes again, waiting longer each time in a row
+// it fails. Changes made while reconnecting aren't notified, a
+// notification's State tells whether a resource changed in between
+func (c *Client) Listen(ctx context.Context, topic string, fn func(*Notification) error, types ...rdf.IRI) error {
+	if len(types) == 0 {
+		types = []rdf.IRI{vocab.WebSocketChannel2023, vocab.StreamingHTTPChannel2023}
+	}
+
+	for _, t := range types {
+		if t != vocab.WebSocketChannel2023 && t != vocab.StreamingHTTPChannel2023 {
+			return fmt.Errorf("notify: can't listen over a %v", t)
+		}
+	}
+
+	backoff := c.minBackoff
+
+	for {
+		var fnErr error
+
+		err := c.listen(ctx, topic, types, func(n *Notification) error {
+			fnErr = fn(n)
+			return fnErr
+		}, func() {
+			backoff = c.minBackoff
+		})
+
+		switch {
+		case fnErr != nil:
+			return fnErr
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case !retryable(err):
+			return err
+		}
+
+		t := time.NewTimer(backoff)
+
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+
+		if backoff *= 2; backoff > c.maxBackoff {
+			backoff = c.maxBackoff
+		}
+	}
+}
+
+// listen subscribes once and receives until the channel ends
+func (c *Client) listen(ctx context.Context, topic string, types []rdf.IRI, fn func(*Notification) error, connected func()) error {
+	s, err := c.Service(ctx, topic, types...)
+	if err != nil {
+		return err
+	}
+
+	ch, err := c.Subscribe(ctx, s, Subscription{Topic: topic})
+	if err != nil {
+		return err
+	}
+
+	return c.receive(ctx, ch, fn, connected)
+}
+
+// retryable checks whether subscribing again might work, which it won't
+// when the pod refuses the subscription or offers no channel
+func retryable(err error) bool {
+	if errors.Is(err, ErrNoChannel) {
+		return false
+	}
+
+	var e *solid.Error
+	if errors.As(err, &e) {
+		return e.StatusCode >= 500 || e.StatusCode == http.StatusRequestTimeout || e.StatusCode == http.StatusTooManyRequests
+	}
+
+	return true
+}
+
+// Handler receives the notifications a webhook channel posts, calling fn
+// with each. Bodies that aren't notifications are refused, and an error
+// from fn fails the request so the sender can try again
+func Handler(fn func(*Notification) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "Notifications are posted", http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		b, err := io.ReadAll(io.LimitReader(r.Body, maxResponseSize))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		n, err := ParseNotification(b)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := fn(n); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
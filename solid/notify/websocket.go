@@ -0,0 +1,296 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Just enough of WebSockets to receive notifications: text messages, pings
+// and closing, without extensions
+//
+// https://www.rfc-editor.org/rfc/rfc6455
+
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xa
+
+	// The most a message can be, notifications being small
+	maxMessageSize = 1 << 20
+
+	websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+)
+
+// conn is a WebSocket connection, the client end masking what it sends
+type conn struct {
+	rwc    io.ReadWriteCloser
+	r      *bufio.Reader
+	client bool
+
+	mu sync.Mutex
+}
+
+// dial opens a WebSocket through the HTTP client, so the connection is
+// made with its transport, TLS settings and authentication
+func dial(ctx context.Context, h *http.Client, target string) (*conn, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "ws":
+		u.Scheme = "http"
+	case "wss":
+		u.Scheme = "https"
+	default:
+		return nil, fmt.Errorf("notify: %v isn't a WebSocket URL", target)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	nonce := base64.StdEncoding.EncodeToString(key)
+
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", nonce)
+
+	res, err := h.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	rwc, ok := res.Body.(io.ReadWriteCloser)
+	if res.StatusCode != http.StatusSwitchingProtocols || !ok {
+		res.Body.Close()
+		return nil, fmt.Errorf("notify: %v didn't upgrade to a WebSocket: %v", target, res.Status)
+	}
+
+	if res.Header.Get("Sec-WebSocket-Accept") != acceptKey(nonce) {
+		rwc.Close()
+		return nil, fmt.Errorf("notify: %v answered the WebSocket handshake wrongly", target)
+	}
+
+	return &conn{rwc: rwc, r: bufio.NewReader(rwc), client: true}, nil
+}
+
+// accept upgrades a request to a WebSocket, the server end of dial
+func accept(w http.ResponseWriter, r *http.Request) (*conn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+
+	if !headerHas(r.Header, "Connection", "upgrade") || !headerHas(r.Header, "Upgrade", "websocket") || key == "" {
+		http.Error(w, "Expected a WebSocket handshake", http.StatusBadRequest)
+		return nil, errors.New("notify: not a WebSocket handshake")
+	}
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "Can't upgrade the connection", http.StatusInternalServerError)
+		return nil, errors.New("notify: connection can't be hijacked")
+	}
+
+	c, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %v\r\n\r\n", acceptKey(key))
+
+	if err := rw.Flush(); err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	return &conn{rwc: c, r: rw.Reader}, nil
+}
+
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func headerHas(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// read returns the next text or binary message, answering pings on the
+// way. It returns ErrClosed when the other end closes the connection
+func (c *conn) read() ([]byte, error) {
+	var message []byte
+	started := false
+
+	for {
+		fin, op, payload, err := c.frame()
+		if err != nil {
+			return nil, err
+		}
+
+		switch op {
+		case opPing:
+			if err := c.write(opPong, payload); err != nil {
+				return nil, err
+			}
+
+			continue
+		case opPong:
+			continue
+		case opClose:
+			c.write(opClose, payload)
+			return nil, ErrClosed
+		case opText, opBinary:
+			if started {
+				return nil, errors.New("notify: websocket message interrupted")
+			}
+
+			started = true
+		case opContinuation:
+			if !started {
+				return nil, errors.New("notify: websocket continuation without a message")
+			}
+		default:
+			return nil, fmt.Errorf("notify: unknown websocket opcode %v", op)
+		}
+
+		if len(message)+len(payload) > maxMessageSize {
+			return nil, errors.New("notify: websocket message too large")
+		}
+
+		message = append(message, payload...)
+
+		if fin {
+			return message, nil
+		}
+	}
+}
+
+func (c *conn) frame() (bool, byte, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(c.r, head[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin, op := head[0]&0x80 != 0, head[0]&0x0f
+	masked, size := head[1]&0x80 != 0, uint64(head[1]&0x7f)
+
+	switch size {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+
+		size = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+
+		size = binary.BigEndian.Uint64(ext[:])
+	}
+
+	if size > maxMessageSize {
+		return false, 0, nil, errors.New("notify: websocket frame too large")
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.r, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return false, 0, nil, err
+	}
+
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+
+	return fin, op, payload, nil
+}
+
+// write sends a single frame, masked when sent by the client
+func (c *conn) write(op byte, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	frame := []byte{0x80 | op}
+
+	var maskBit byte
+	if c.client {
+		maskBit = 0x80
+	}
+
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, maskBit|byte(n))
+	case n <= 0xffff:
+		frame = append(frame, maskBit|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, maskBit|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+
+	if c.client {
+		var mask [4]byte
+		if _, err := rand.Read(mask[:]); err != nil {
+			return err
+		}
+
+		frame = append(frame, mask[:]...)
+
+		start := len(frame)
+		frame = append(frame, payload...)
+
+		for i := range frame[start:] {
+			frame[start+i] ^= mask[i%4]
+		}
+	} else {
+		frame = append(frame, payload...)
+	}
+
+	_, err := c.rwc.Write(frame)
+	return err
+}
+
+func (c *conn) close() error {
+	c.write(opClose, []byte{0x03, 0xe8}) // 1000, normal closure
+	return c.rwc.Close()
+}
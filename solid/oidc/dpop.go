@@ -0,0 +1,155 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// How far a proof's iat can be from the verifier's clock
+const maxProofSkew = 5 * time.Minute
+
+// A Key signs DPoP proofs, the tokens a provider issues are bound to it so
+// they are no use to anyone without it
+//
+// https://www.rfc-editor.org/rfc/rfc9449
+type Key struct {
+	priv *ecdsa.PrivateKey
+	jwk  JWK
+}
+
+// NewKey makes a P-256 key for ES256 proofs
+func NewKey() (*Key, error) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Key{priv: priv, jwk: ecJWK(&priv.PublicKey)}, nil
+}
+
+func (k *Key) JWK() JWK {
+	return k.jwk
+}
+
+func (k *Key) Thumbprint() string {
+	return k.jwk.Thumbprint()
+}
+
+// ProofClaims are the claims of a DPoP proof
+type ProofClaims struct {
+	JTI   string `json:"jti"`
+	HTM   string `json:"htm"`
+	HTU   string `json:"htu"`
+	IAT   int64  `json:"iat"`
+	ATH   string `json:"ath,omitempty"`
+	Nonce string `json:"nonce,omitempty"`
+}
+
+// Proof makes a DPoP proof for a request. The access token is given when
+// the request carries one and the nonce when the server asked for one,
+// both can be empty
+func (k *Key) Proof(method, target, accessToken, nonce string) (string, error) {
+	htu, err := proofURL(target)
+	if err != nil {
+		return "", err
+	}
+
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", err
+	}
+
+	claims := ProofClaims{
+		JTI:   hex.EncodeToString(jti),
+		HTM:   method,
+		HTU:   htu,
+		IAT:   time.Now().Unix(),
+		Nonce: nonce,
+	}
+
+	if accessToken != "" {
+		claims.ATH = tokenHash(accessToken)
+	}
+
+	jwk := k.jwk
+
+	return signJWT(k.priv, jwtHeader{Typ: "dpop+jwt", JWK: &jwk}, claims)
+}
+
+// A Proof is a DPoP proof that has been checked. Replayed JTIs and nonces
+// are left to the server to check, as only it knows what it has seen and
+// handed out
+type Proof struct {
+	ProofClaims
+	JWK JWK
+}
+
+// VerifyProof checks a DPoP proof was signed by the key it carries for
+// this request, and when an access token is given that it was made for
+// that token
+func VerifyProof(proof, method, target, accessToken string, now time.Time) (*Proof, error) {
+	var p Proof
+
+	h, err := parseJWT(proof, &p.ProofClaims, func(h jwtHeader) (crypto.PublicKey, error) {
+		if h.Typ != "dpop+jwt" || h.JWK == nil {
+			return nil, errors.New("not a DPoP proof")
+		}
+
+		return h.JWK.PublicKey()
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+
+	p.JWK = *h.JWK
+
+	htu, err := proofURL(target)
+	if err != nil {
+		return nil, err
+	}
+
+	iat := time.Unix(p.IAT, 0)
+
+	switch {
+	case p.JTI == "":
+		return nil, fmt.Errorf("%w: no jti", ErrInvalidProof)
+	case p.HTM != method:
+		return nil, fmt.Errorf("%w: made for %v not %v", ErrInvalidProof, p.HTM, method)
+	case p.HTU != htu:
+		return nil, fmt.Errorf("%w: made for %v not %v", ErrInvalidProof, p.HTU, htu)
+	case iat.Before(now.Add(-maxProofSkew)) || iat.After(now.Add(maxProofSkew)):
+		return nil, fmt.Errorf("%w: issued at %v", ErrInvalidProof, iat)
+	case accessToken != "" && p.ATH != tokenHash(accessToken):
+		return nil, fmt.Errorf("%w: made for another access token", ErrInvalidProof)
+	}
+
+	return &p, nil
+}
+
+// proofURL is the URL a proof names, without the query or fragment
+func proofURL(target string) (string, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", err
+	}
+
+	u.RawQuery, u.Fragment, u.RawFragment = "", "", ""
+	u.Scheme, u.Host = strings.ToLower(u.Scheme), strings.ToLower(u.Host)
+
+	return u.String(), nil
+}
+
+func tokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return b64.EncodeToString(sum[:])
+}
@@ -0,0 +1,254 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var b64 = base64.RawURLEncoding
+
+// A JWK is a public key as a JSON Web Key, EC P-256 or RSA
+//
+// https://www.rfc-editor.org/rfc/rfc7517
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Use string `json:"use,omitempty"`
+
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// D is only read to turn away private keys
+	D string `json:"d,omitempty"`
+}
+
+func ecJWK(pub *ecdsa.PublicKey) JWK {
+	size := (pub.Curve.Params().BitSize + 7) / 8
+
+	return JWK{
+		Kty: "EC",
+		Crv: "P-256",
+		X:   b64.EncodeToString(pub.X.FillBytes(make([]byte, size))),
+		Y:   b64.EncodeToString(pub.Y.FillBytes(make([]byte, size))),
+	}
+}
+
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	if k.D != "" {
+		return nil, errors.New("oidc: JWK is a private key")
+	}
+
+	switch k.Kty {
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("oidc: unsupported curve %q", k.Crv)
+		}
+
+		x, errX := b64.DecodeString(k.X)
+		y, errY := b64.DecodeString(k.Y)
+
+		if errX != nil || errY != nil {
+			return nil, errors.New("oidc: malformed EC key")
+		}
+
+		pub := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !pub.Curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, errors.New("oidc: EC key isn't on the curve")
+		}
+
+		return pub, nil
+	case "RSA":
+		n, errN := b64.DecodeString(k.N)
+		e, errE := b64.DecodeString(k.E)
+
+		if errN != nil || errE != nil || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("oidc: malformed RSA key")
+		}
+
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	default:
+		return nil, fmt.Errorf("oidc: unsupported key type %q", k.Kty)
+	}
+}
+
+// Thumbprint is the key's SHA-256 JWK thumbprint, what DPoP-bound tokens
+// carry to name the key
+//
+// https://www.rfc-editor.org/rfc/rfc7638
+func (k JWK) Thumbprint() string {
+	var members string
+
+	// The required members in lexical order, which is what gets hashed
+	switch k.Kty {
+	case "EC":
+		members = fmt.Sprintf(`{"crv":%q,"kty":"EC","x":%q,"y":%q}`, k.Crv, k.X, k.Y)
+	case "RSA":
+		members = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, k.E, k.N)
+	}
+
+	sum := sha256.Sum256([]byte(members))
+
+	return b64.EncodeToString(sum[:])
+}
+
+// A JWKS is a provider's set of keys
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// key picks the key with the ID, or the only key when no ID is given
+func (s JWKS) key(kid string) (crypto.PublicKey, error) {
+	for _, k := range s.Keys {
+		if k.Kid == kid || (kid == "" && len(s.Keys) == 1) {
+			return k.PublicKey()
+		}
+	}
+
+	return nil, fmt.Errorf("oidc: no key %q", kid)
+}
+
+type jwtHeader struct {
+	Typ string `json:"typ,omitempty"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	JWK *JWK   `json:"jwk,omitempty"`
+}
+
+// signJWT makes an ES256 JWT
+func signJWT(key *ecdsa.PrivateKey, h jwtHeader, claims any) (string, error) {
+	h.Alg = "ES256"
+
+	hb, err := json.Marshal(h)
+	if err != nil {
+		return "", err
+	}
+
+	cb, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signed := b64.EncodeToString(hb) + "." + b64.EncodeToString(cb)
+	sum := sha256.Sum256([]byte(signed))
+
+	r, s, err := ecdsa.Sign(rand.Reader, key, sum[:])
+	if err != nil {
+		return "", err
+	}
+
+	sig := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+
+	return signed + "." + b64.EncodeToString(sig), nil
+}
+
+// parseJWT checks a JWT's signature with the key keyFor picks from its
+// header and reads its claims
+func parseJWT(token string, claims any, keyFor func(h jwtHeader) (crypto.PublicKey, error)) (jwtHeader, error) {
+	var h jwtHeader
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return h, errors.New("oidc: malformed JWT")
+	}
+
+	hb, err := b64.DecodeString(parts[0])
+	if err != nil {
+		return h, errors.New("oidc: malformed JWT header")
+	}
+
+	if err := json.Unmarshal(hb, &h); err != nil {
+		return h, errors.New("oidc: malformed JWT header")
+	}
+
+	sig, err := b64.DecodeString(parts[2])
+	if err != nil {
+		return h, errors.New("oidc: malformed JWT signature")
+	}
+
+	key, err := keyFor(h)
+	if err != nil {
+		return h, err
+	}
+
+	if err := verifySignature(h.Alg, key, []byte(parts[0]+"."+parts[1]), sig); err != nil {
+		return h, err
+	}
+
+	cb, err := b64.DecodeString(parts[1])
+	if err != nil {
+		return h, errors.New("oidc: malformed JWT claims")
+	}
+
+	if err := json.Unmarshal(cb, claims); err != nil {
+		return h, fmt.Errorf("oidc: malformed JWT claims: %v", err)
+	}
+
+	return h, nil
+}
+
+func verifySignature(alg string, key crypto.PublicKey, signed, sig []byte) error {
+	sum := sha256.Sum256(signed)
+
+	switch alg {
+	case "ES256":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(sig) != 64 {
+			return errors.New("oidc: bad ES256 signature")
+		}
+
+		if !ecdsa.Verify(pub, sum[:], new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])) {
+			return errors.New("oidc: bad signature")
+		}
+	case "RS256":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("oidc: bad RS256 signature")
+		}
+
+		if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, sum[:], sig); err != nil {
+			return errors.New("oidc: bad signature")
+		}
+	default:
+		return fmt.Errorf("oidc: unsupported algorithm %q", alg)
+	}
+
+	return nil
+}
+
+// audience is the aud claim, which can be a string or an array
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*a = audience{s}
+		return nil
+	}
+
+	return json.Unmarshal(b, (*[]string)(a))
+}
+
+func (a audience) has(s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
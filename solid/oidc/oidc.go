@@ -0,0 +1,355 @@
+// Package oidc logs in to Solid pods with Solid-OIDC. The provider is
+// found from a WebID, the user signs in with the authorization code flow
+// and PKCE, and the access tokens it hands back are bound to a key with
+// DPoP, a fresh proof signed for every request
+//
+// https://solidproject.org/TR/oidc
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/b1scuit/solid/solid"
+)
+
+var (
+	ErrNoIssuer     = errors.New("oidc: WebID has no OIDC issuer")
+	ErrState        = errors.New("oidc: state doesn't match the login")
+	ErrInvalidToken = errors.New("oidc: invalid token")
+	ErrInvalidProof = errors.New("oidc: invalid DPoP proof")
+)
+
+// The scopes Solid-OIDC asks for, offline_access for a refresh token
+var defaultScopes = []string{"openid", "webid", "offline_access"}
+
+const maxResponseSize = 1 << 20
+
+type ClientOption func(*Client)
+
+// WithHTTPClient sets the client the provider and pods are reached with,
+// it defaults to http.DefaultClient
+func WithHTTPClient(h *http.Client) ClientOption {
+	return func(c *Client) {
+		c.http = h
+	}
+}
+
+// WithRedirectURL sets where the provider sends the user back to after
+// they sign in, it has to be given
+func WithRedirectURL(u string) ClientOption {
+	return func(c *Client) {
+		c.redirectURL = u
+	}
+}
+
+// WithClientID uses a client registered with the provider beforehand,
+// instead of registering one for each provider
+func WithClientID(id, secret string) ClientOption {
+	return func(c *Client) {
+		c.clientID, c.clientSecret = id, secret
+	}
+}
+
+// WithClientIDDocument identifies the client by the URL of its Client ID
+// Document, which providers read in place of registering it
+//
+// https://solidproject.org/TR/oidc#clientids-document
+func WithClientIDDocument(u string) ClientOption {
+	return func(c *Client) {
+		c.clientID, c.clientSecret = u, ""
+	}
+}
+
+// WithClientName sets the name given when registering dynamically, which
+// providers show to the user
+func WithClientName(name string) ClientOption {
+	return func(c *Client) {
+		c.clientName = name
+	}
+}
+
+// WithScopes replaces the scopes asked for
+func WithScopes(scopes ...string) ClientOption {
+	return func(c *Client) {
+		c.scopes = scopes
+	}
+}
+
+// WithKey sets the DPoP key, by default each client makes its own
+func WithKey(k *Key) ClientOption {
+	return func(c *Client) {
+		c.key = k
+	}
+}
+
+// A Client is an application logging users in to their pods
+type Client struct {
+	http         *http.Client
+	redirectURL  string
+	clientID     string
+	clientSecret string
+	clientName   string
+	scopes       []string
+	key          *Key
+}
+
+func New(opts ...ClientOption) (*Client, error) {
+	c := &Client{
+		http:   http.DefaultClient,
+		scopes: defaultScopes,
+	}
+
+	for _, f := range opts {
+		f(c)
+	}
+
+	if c.redirectURL == "" {
+		return nil, errors.New("oidc: no redirect URL")
+	}
+
+	if c.key == nil {
+		key, err := NewKey()
+		if err != nil {
+			return nil, err
+		}
+
+		c.key = key
+	}
+
+	return c, nil
+}
+
+func MustNew(opts ...ClientOption) *Client {
+	c, err := New(opts...)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
+
+// Issuers reads the OIDC issuers a WebID's profile trusts
+func (c *Client) Issuers(ctx context.Context, webID string) ([]string, error) {
+	p, err := solid.MustNew(solid.WithHTTPClient(c.http)).FetchWebID(ctx, webID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(p.OIDCIssuers) == 0 {
+		return nil, fmt.Errorf("%w: %v", ErrNoIssuer, webID)
+	}
+
+	return p.OIDCIssuers, nil
+}
+
+// DiscoverWebID finds the provider of the first issuer the WebID trusts
+func (c *Client) DiscoverWebID(ctx context.Context, webID string) (*Provider, error) {
+	issuers, err := c.Issuers(ctx, webID)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Discover(ctx, issuers[0])
+}
+
+// Metadata is what a provider publishes about itself
+//
+// https://openid.net/specs/openid-connect-discovery-1_0.html
+type Metadata struct {
+	Issuer                        string   `json:"issuer"`
+	AuthorizationEndpoint         string   `json:"authorization_endpoint"`
+	TokenEndpoint                 string   `json:"token_endpoint"`
+	RegistrationEndpoint          string   `json:"registration_endpoint,omitempty"`
+	JWKSURI                       string   `json:"jwks_uri"`
+	ScopesSupported               []string `json:"scopes_supported,omitempty"`
+	DPoPSigningAlgValuesSupported []string `json:"dpop_signing_alg_values_supported,omitempty"`
+}
+
+// A Provider is an OpenID provider the client has found and is registered
+// with
+type Provider struct {
+	Metadata
+	ClientID     string
+	ClientSecret string
+
+	c *Client
+
+	mu    sync.Mutex
+	nonce string
+	jwks  *JWKS
+}
+
+// Discover reads the issuer's metadata and registers the client with it
+// when it doesn't have a client ID of its own
+func (c *Client) Discover(ctx context.Context, issuer string) (*Provider, error) {
+	p := &Provider{c: c, ClientID: c.clientID, ClientSecret: c.clientSecret}
+
+	configURL := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+
+	if err := c.getJSON(ctx, configURL, &p.Metadata); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSuffix(p.Issuer, "/") != strings.TrimSuffix(issuer, "/") {
+		return nil, fmt.Errorf("oidc: %v says its issuer is %v", issuer, p.Issuer)
+	}
+
+	if p.AuthorizationEndpoint == "" || p.TokenEndpoint == "" || p.JWKSURI == "" {
+		return nil, fmt.Errorf("oidc: %v is missing endpoints", issuer)
+	}
+
+	if p.ClientID == "" {
+		if err := p.register(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// register registers the client dynamically
+//
+// https://openid.net/specs/openid-connect-registration-1_0.html
+func (p *Provider) register(ctx context.Context) error {
+	if p.RegistrationEndpoint == "" {
+		return fmt.Errorf("oidc: %v doesn't support registration, give a client ID", p.Issuer)
+	}
+
+	req := map[string]any{
+		"redirect_uris":              []string{p.c.redirectURL},
+		"grant_types":                []string{"authorization_code", "refresh_token"},
+		"response_types":             []string{"code"},
+		"token_endpoint_auth_method": "none",
+		"application_type":           "web",
+		"scope":                      strings.Join(p.c.scopes, " "),
+	}
+
+	if p.c.clientName != "" {
+		req["client_name"] = p.c.clientName
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, p.RegistrationEndpoint, strings.NewReader(string(body)))
+	if err != nil {
+		return err
+	}
+
+	r.Header.Set("Content-Type", "application/json")
+
+	var res struct {
+		ClientID     string `json:"client_id"`
+		ClientSecret string `json:"client_secret"`
+	}
+
+	if err := p.c.doJSON(r, &res); err != nil {
+		return err
+	}
+
+	if res.ClientID == "" {
+		return fmt.Errorf("oidc: %v registered no client ID", p.Issuer)
+	}
+
+	p.ClientID, p.ClientSecret = res.ClientID, res.ClientSecret
+
+	return nil
+}
+
+// keys returns the provider's signing keys, fetched once
+func (p *Provider) keys(ctx context.Context, refetch bool) (*JWKS, error) {
+	p.mu.Lock()
+	jwks := p.jwks
+	p.mu.Unlock()
+
+	if jwks != nil && !refetch {
+		return jwks, nil
+	}
+
+	jwks = &JWKS{}
+	if err := p.c.getJSON(ctx, p.JWKSURI, jwks); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.jwks = jwks
+	p.mu.Unlock()
+
+	return jwks, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, target string, v any) error {
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+
+	r.Header.Set("Accept", "application/json")
+
+	return c.doJSON(r, v)
+}
+
+// doJSON makes a request and reads a JSON response into v. OAuth errors
+// come back as an *Error
+func (c *Client) doJSON(r *http.Request, v any) error {
+	res, err := c.http.Do(r)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	b, err := io.ReadAll(io.LimitReader(res.Body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		e := &Error{StatusCode: res.StatusCode, Nonce: res.Header.Get("DPoP-Nonce")}
+
+		if mediaType != "application/json" || json.Unmarshal(b, e) != nil || e.Code == "" {
+			e.Code, e.Description = res.Status, strings.TrimSpace(string(b))
+		}
+
+		return e
+	}
+
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("oidc: reading %v: %v", r.URL, err)
+	}
+
+	return nil
+}
+
+// An Error is an error response from a provider
+//
+// https://www.rfc-editor.org/rfc/rfc6749#section-5.2
+type Error struct {
+	StatusCode  int    `json:"-"`
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+
+	// Nonce is the DPoP nonce the provider wants proofs to carry
+	Nonce string `json:"-"`
+}
+
+func (e *Error) Error() string {
+	if e.Description == "" {
+		return "oidc: " + e.Code
+	}
+
+	return "oidc: " + e.Code + ": " + e.Description
+}
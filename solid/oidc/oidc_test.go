@@ -0,0 +1,506 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/b1scuit/solid/solid"
+)
+
+// provider is a stand-in OpenID provider and pod. It hands out codes
+// without a sign in page, wants a DPoP nonce at both the token endpoint
+// and the resource, and checks the proofs and PKCE as a real one would
+type provider struct {
+	mu  sync.Mutex
+	url string
+	key *ecdsa.PrivateKey
+
+	// Registered clients and their secrets
+	clients map[string]string
+
+	codes         map[string]pendingCode
+	tokens        map[string]string // access token to key thumbprint
+	refreshTokens map[string]string // refresh token to key thumbprint
+	jtis          map[string]bool
+	expiresIn     int
+	refreshes     int
+}
+
+type pendingCode struct {
+	clientID, redirectURI, challenge, nonce string
+}
+
+const (
+	tokenNonce    = "token-nonce"
+	resourceNonce = "resource-nonce"
+	webIDPath     = "/profile/card"
+	resourcePath  = "/private"
+)
+
+func newProvider(t *testing.T) *provider {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	p := &provider{
+		key:           key,
+		clients:       map[string]string{"static": "s3cret"},
+		codes:         make(map[string]pendingCode),
+		tokens:        make(map[string]string),
+		refreshTokens: make(map[string]string),
+		jtis:          make(map[string]bool),
+		expiresIn:     3600,
+	}
+
+	s := httptest.NewServer(p)
+	t.Cleanup(s.Close)
+
+	p.url = s.URL
+
+	return p
+}
+
+func (p *provider) webID() string {
+	return p.url + webIDPath + "#me"
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func (p *provider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch r.URL.Path {
+	case webIDPath:
+		w.Header().Set("Content-Type", "text/turtle")
+		fmt.Fprintf(w, "<#me> <http://www.w3.org/ns/solid/terms#oidcIssuer> <%v> .\n", p.url)
+		fmt.Fprint(w, "<#nobody> <http://xmlns.com/foaf/0.1/name> \"Nobody\" .")
+	case "/.well-known/openid-configuration":
+		writeJSON(w, 200, Metadata{
+			Issuer:                        p.url,
+			AuthorizationEndpoint:         p.url + "/authorize",
+			TokenEndpoint:                 p.url + "/token",
+			RegistrationEndpoint:          p.url + "/register",
+			JWKSURI:                       p.url + "/jwks",
+			DPoPSigningAlgValuesSupported: []string{"ES256"},
+		})
+	case "/jwks":
+		jwk := ecJWK(&p.key.PublicKey)
+		jwk.Kid = "op"
+		writeJSON(w, 200, JWKS{Keys: []JWK{jwk}})
+	case "/register":
+		var req struct {
+			RedirectURIs []string `json:"redirect_uris"`
+		}
+
+		if json.NewDecoder(r.Body).Decode(&req) != nil || len(req.RedirectURIs) == 0 {
+			writeJSON(w, 400, Error{Code: "invalid_redirect_uri"})
+			return
+		}
+
+		id := fmt.Sprintf("dynamic-%v", len(p.clients))
+		p.clients[id] = ""
+
+		writeJSON(w, 201, map[string]string{"client_id": id})
+	case "/authorize":
+		p.authorize(w, r)
+	case "/token":
+		p.token(w, r)
+	case resourcePath:
+		p.resource(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (p *provider) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	clientID := q.Get("client_id")
+	_, registered := p.clients[clientID]
+
+	// A client ID that is a URL is a Client ID Document, which a real
+	// provider would fetch
+	if !registered && !strings.HasPrefix(clientID, "https://") {
+		http.Error(w, "unknown client", http.StatusBadRequest)
+		return
+	}
+
+	if q.Get("code_challenge_method") != "S256" || q.Get("response_type") != "code" {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+
+	code := randomString()
+	p.codes[code] = pendingCode{clientID, q.Get("redirect_uri"), q.Get("code_challenge"), q.Get("nonce")}
+
+	http.Redirect(w, r, q.Get("redirect_uri")+"?code="+code+"&state="+q.Get("state")+"&iss="+p.url, http.StatusFound)
+}
+
+func (p *provider) token(w http.ResponseWriter, r *http.Request) {
+	proof, err := VerifyProof(r.Header.Get("DPoP"), http.MethodPost, p.url+"/token", "", time.Now())
+	if err != nil {
+		writeJSON(w, 400, Error{Code: "invalid_dpop_proof", Description: err.Error()})
+		return
+	}
+
+	if p.jtis[proof.JTI] {
+		writeJSON(w, 400, Error{Code: "invalid_dpop_proof", Description: "replayed"})
+		return
+	}
+
+	p.jtis[proof.JTI] = true
+
+	if proof.Nonce != tokenNonce {
+		w.Header().Set("DPoP-Nonce", tokenNonce)
+		writeJSON(w, 400, Error{Code: "use_dpop_nonce"})
+		return
+	}
+
+	r.ParseForm()
+
+	clientID := r.PostForm.Get("client_id")
+	if id, secret, ok := r.BasicAuth(); ok {
+		if p.clients[id] != secret {
+			writeJSON(w, 401, Error{Code: "invalid_client"})
+			return
+		}
+
+		clientID = id
+	} else if p.clients[clientID] != "" {
+		writeJSON(w, 401, Error{Code: "invalid_client"})
+		return
+	}
+
+	jkt := proof.JWK.Thumbprint()
+	nonce := ""
+
+	switch r.PostForm.Get("grant_type") {
+	case "authorization_code":
+		code, ok := p.codes[r.PostForm.Get("code")]
+		delete(p.codes, r.PostForm.Get("code"))
+
+		challenge := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+
+		if !ok || code.clientID != clientID || code.redirectURI != r.PostForm.Get("redirect_uri") || code.challenge != b64.EncodeToString(challenge[:]) {
+			writeJSON(w, 400, Error{Code: "invalid_grant"})
+			return
+		}
+
+		nonce = code.nonce
+	case "refresh_token":
+		bound, ok := p.refreshTokens[r.PostForm.Get("refresh_token")]
+		if !ok || bound != jkt {
+			writeJSON(w, 400, Error{Code: "invalid_grant"})
+			return
+		}
+
+		delete(p.refreshTokens, r.PostForm.Get("refresh_token"))
+		p.refreshes++
+	default:
+		writeJSON(w, 400, Error{Code: "unsupported_grant_type"})
+		return
+	}
+
+	access, refresh := randomString(), randomString()
+	p.tokens[access] = jkt
+	p.refreshTokens[refresh] = jkt
+
+	idToken, _ := signJWT(p.key, jwtHeader{Kid: "op"}, map[string]any{
+		"iss":   p.url,
+		"sub":   "alice",
+		"aud":   []string{clientID, "solid"},
+		"azp":   clientID,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"iat":   time.Now().Unix(),
+		"nonce": nonce,
+		"webid": p.webID(),
+	})
+
+	writeJSON(w, 200, map[string]any{
+		"access_token":  access,
+		"token_type":    "DPoP",
+		"expires_in":    p.expiresIn,
+		"refresh_token": refresh,
+		"id_token":      idToken,
+	})
+}
+
+func (p *provider) resource(w http.ResponseWriter, r *http.Request) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "DPoP ")
+	if !ok || p.tokens[token] == "" {
+		http.Error(w, "no token", http.StatusUnauthorized)
+		return
+	}
+
+	proof, err := VerifyProof(r.Header.Get("DPoP"), r.Method, p.url+r.URL.Path, token, time.Now())
+	if err != nil || proof.JWK.Thumbprint() != p.tokens[token] {
+		http.Error(w, "bad proof", http.StatusUnauthorized)
+		return
+	}
+
+	if proof.Nonce != resourceNonce {
+		w.Header().Set("DPoP-Nonce", resourceNonce)
+		w.Header().Set("WWW-Authenticate", `DPoP error="use_dpop_nonce"`)
+		http.Error(w, "nonce", http.StatusUnauthorized)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+
+	w.Header().Set("Content-Type", "text/turtle")
+	w.Header().Set("WAC-Allow", `user="read write"`)
+	fmt.Fprintf(w, "<> <http://example.org/method> %q ; <http://example.org/body> %q .", r.Method, body)
+}
+
+// signIn follows the login to the provider and back, as the user's
+// browser would
+func signIn(t *testing.T, p *Provider) *Session {
+	l, err := p.Login()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	noRedirects := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+
+	res, err := noRedirects.Get(l.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusFound {
+		t.Fatalf("authorize: %v", res.Status)
+	}
+
+	s, err := p.Exchange(context.Background(), l, res.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("exchange: %v", err)
+	}
+
+	return s
+}
+
+type loginTest struct {
+	Name     string
+	Opts     []ClientOption
+	ClientID string
+}
+
+var loginTests = []loginTest{
+	{"Dynamic registration", nil, "dynamic-1"},
+	{"Static client", []ClientOption{WithClientID("static", "s3cret")}, "static"},
+	{"Client ID Document", []ClientOption{WithClientIDDocument("https://app.example/id")}, "https://app.example/id"},
+}
+
+func TestLogin(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tc := range loginTests {
+		op := newProvider(t)
+
+		c := MustNew(append([]ClientOption{WithRedirectURL("http://localhost:3000/callback")}, tc.Opts...)...)
+
+		p, err := c.DiscoverWebID(ctx, op.webID())
+		if err != nil {
+			t.Errorf("%v test fail: %v", tc.Name, err)
+			continue
+		}
+
+		if p.ClientID != tc.ClientID || p.TokenEndpoint != op.url+"/token" {
+			t.Errorf("%v test fail: discovered %+v", tc.Name, p)
+		}
+
+		s := signIn(t, p)
+
+		if s.WebID() != op.webID() {
+			t.Errorf("%v test fail: signed in as %v", tc.Name, s.WebID())
+		}
+
+		// Requests through the session are authenticated, including ones
+		// with a body sent again with the server's nonce
+		pod := solid.MustNew(solid.WithHTTPClient(s.Client()))
+
+		res, err := pod.Get(ctx, op.url+resourcePath)
+		if err != nil || res.Graph.Len() != 2 || !res.Allow.Allows("user", "write") {
+			t.Errorf("%v test fail: got %v, %v", tc.Name, res, err)
+		}
+
+		if _, err := pod.PutBody(ctx, op.url+resourcePath, "text/plain", strings.NewReader("hello")); err != nil {
+			t.Errorf("%v test fail: %v", tc.Name, err)
+		}
+
+		if err := s.Refresh(ctx); err != nil || op.refreshes != 1 {
+			t.Errorf("%v test fail: refreshing gave %v", tc.Name, err)
+		}
+
+		// The old refresh token was used up
+		if _, err := pod.Get(ctx, op.url+resourcePath); err != nil {
+			t.Errorf("%v test fail: after refreshing %v", tc.Name, err)
+		}
+	}
+}
+
+func TestLoginErrors(t *testing.T) {
+	ctx := context.Background()
+	op := newProvider(t)
+
+	if _, err := New(); err == nil {
+		t.Errorf("client test fail: no redirect URL accepted")
+	}
+
+	c := MustNew(WithRedirectURL("http://localhost:3000/callback"))
+
+	if _, err := c.DiscoverWebID(ctx, op.url+webIDPath+"#nobody"); !errors.Is(err, ErrNoIssuer) {
+		t.Errorf("issuer test fail: %v", err)
+	}
+
+	p, err := c.Discover(ctx, op.url)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, _ := p.Login()
+
+	if _, err := p.Exchange(ctx, l, "http://localhost:3000/callback?code=x&state=forged"); !errors.Is(err, ErrState) {
+		t.Errorf("state test fail: %v", err)
+	}
+
+	var e *Error
+
+	if _, err := p.Exchange(ctx, l, "http://localhost:3000/callback?error=access_denied&state="+l.State); !errors.As(err, &e) || e.Code != "access_denied" {
+		t.Errorf("error test fail: %v", err)
+	}
+
+	if _, err := p.Exchange(ctx, l, "http://localhost:3000/callback?code=made-up&state="+l.State); !errors.As(err, &e) || e.Code != "invalid_grant" {
+		t.Errorf("code test fail: %v", err)
+	}
+
+	if _, err := p.Exchange(ctx, l, "http://localhost:3000/callback?code=x&iss=http://evil.example&state="+l.State); err == nil {
+		t.Errorf("issuer mix-up test fail: accepted another issuer")
+	}
+
+	// A different key can't use the session's tokens
+	s := signIn(t, p)
+	s.p.c = MustNew(WithRedirectURL("http://localhost:3000/callback"))
+
+	if res, err := s.Client().Get(op.url + resourcePath); err != nil || res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("key binding test fail: %v, %v", res, err)
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	ctx := context.Background()
+	op := newProvider(t)
+
+	// Tokens that are always about to run out are refreshed before each use
+	op.expiresIn = 30
+
+	p, err := MustNew(WithRedirectURL("http://localhost:3000/callback")).Discover(ctx, op.url)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := signIn(t, p)
+
+	for i := 0; i < 3; i++ {
+		if _, err := s.AccessToken(ctx); err != nil {
+			t.Fatalf("expiry test fail: %v", err)
+		}
+	}
+
+	if op.refreshes != 3 {
+		t.Errorf("expiry test fail: %v refreshes", op.refreshes)
+	}
+}
+
+type proofTest struct {
+	Name   string
+	Method string
+	URL    string
+	Token  string
+	Now    time.Time
+	Valid  bool
+}
+
+func TestVerifyProof(t *testing.T) {
+	key, _ := NewKey()
+
+	proof, err := key.Proof("GET", "https://pod.example/doc?x=1#frag", "token", "n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+
+	proofTests := []proofTest{
+		{"Valid", "GET", "https://pod.example/doc", "token", now, true},
+		{"Query and fragment ignored", "GET", "https://POD.example/doc?y=2", "token", now, true},
+		{"Without a token", "GET", "https://pod.example/doc", "", now, true},
+		{"Wrong method", "POST", "https://pod.example/doc", "token", now, false},
+		{"Wrong URL", "GET", "https://pod.example/other", "token", now, false},
+		{"Wrong token", "GET", "https://pod.example/doc", "stolen", now, false},
+		{"Too old", "GET", "https://pod.example/doc", "token", now.Add(10 * time.Minute), false},
+		{"From the future", "GET", "https://pod.example/doc", "token", now.Add(-10 * time.Minute), false},
+	}
+
+	for _, tc := range proofTests {
+		p, err := VerifyProof(proof, tc.Method, tc.URL, tc.Token, tc.Now)
+
+		if tc.Valid && (err != nil || p.JWK.Thumbprint() != key.Thumbprint() || p.Nonce != "n") {
+			t.Errorf("%v test fail: %v", tc.Name, err)
+		}
+
+		if !tc.Valid && !errors.Is(err, ErrInvalidProof) {
+			t.Errorf("%v test fail: expected invalid, got %v", tc.Name, err)
+		}
+	}
+
+	// A tampered proof fails its signature
+	parts := strings.Split(proof, ".")
+	claims, _ := b64.DecodeString(parts[1])
+	parts[1] = b64.EncodeToString([]byte(strings.Replace(string(claims), `"GET"`, `"PUT"`, 1)))
+
+	if _, err := VerifyProof(strings.Join(parts, "."), "PUT", "https://pod.example/doc", "", now); !errors.Is(err, ErrInvalidProof) {
+		t.Errorf("tampered test fail: %v", err)
+	}
+
+	// As does one carrying a key that didn't sign it
+	other, _ := NewKey()
+	header, _ := json.Marshal(jwtHeader{Typ: "dpop+jwt", Alg: "ES256", JWK: &other.jwk})
+	parts = strings.Split(proof, ".")
+	parts[0] = b64.EncodeToString(header)
+
+	if _, err := VerifyProof(strings.Join(parts, "."), "GET", "https://pod.example/doc", "", now); !errors.Is(err, ErrInvalidProof) {
+		t.Errorf("swapped key test fail: %v", err)
+	}
+}
+
+// The thumbprint example from RFC 7638 section 3.1
+func TestThumbprint(t *testing.T) {
+	k := JWK{
+		Kty: "RSA",
+		E:   "AQAB",
+		N:   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+	}
+
+	if tp := k.Thumbprint(); tp != "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs" {
+		t.Errorf("thumbprint test fail: got %v", tp)
+	}
+}
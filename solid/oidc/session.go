@@ -0,0 +1,405 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tokens are refreshed this long before they expire, so one doesn't run
+// out on the way to the server
+const refreshMargin = time.Minute
+
+// A Login is a sign in waiting for the user to come back from the
+// provider. URL is where to send them
+type Login struct {
+	URL   string
+	State string
+
+	verifier string
+	nonce    string
+}
+
+// Login starts signing in with the authorization code flow, with PKCE so
+// the code is no use to anyone who intercepts it
+//
+// https://www.rfc-editor.org/rfc/rfc7636
+func (p *Provider) Login() (*Login, error) {
+	l := &Login{State: randomString(), verifier: randomString(), nonce: randomString()}
+
+	challenge := sha256.Sum256([]byte(l.verifier))
+
+	u, err := url.Parse(p.AuthorizationEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", p.ClientID)
+	q.Set("redirect_uri", p.c.redirectURL)
+	q.Set("scope", strings.Join(p.c.scopes, " "))
+	q.Set("state", l.State)
+	q.Set("nonce", l.nonce)
+	q.Set("code_challenge", b64.EncodeToString(challenge[:]))
+	q.Set("code_challenge_method", "S256")
+	q.Set("prompt", "consent")
+
+	u.RawQuery = q.Encode()
+	l.URL = u.String()
+
+	return l, nil
+}
+
+// Exchange finishes a login with the URL the provider sent the user back
+// to, trading the code in it for tokens
+func (p *Provider) Exchange(ctx context.Context, l *Login, callback string) (*Session, error) {
+	u, err := url.Parse(callback)
+	if err != nil {
+		return nil, err
+	}
+
+	q := u.Query()
+
+	if q.Get("state") != l.State {
+		return nil, ErrState
+	}
+
+	if code := q.Get("error"); code != "" {
+		return nil, &Error{Code: code, Description: q.Get("error_description")}
+	}
+
+	// A provider that says who it is has to be the one the login went to
+	//
+	// https://www.rfc-editor.org/rfc/rfc9207
+	if iss := q.Get("iss"); iss != "" && iss != p.Issuer {
+		return nil, fmt.Errorf("oidc: response from %v, not %v", iss, p.Issuer)
+	}
+
+	if q.Get("code") == "" {
+		return nil, errors.New("oidc: no code in the callback")
+	}
+
+	s := &Session{p: p, nonces: make(map[string]string)}
+
+	err = s.token(ctx, url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {q.Get("code")},
+		"redirect_uri":  {p.c.redirectURL},
+		"code_verifier": {l.verifier},
+	}, l.nonce)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// A Session is a signed in user. It is an http.RoundTripper adding their
+// access token and a DPoP proof to each request, refreshing the token as
+// it runs out
+type Session struct {
+	p *Provider
+
+	mu           sync.Mutex
+	webID        string
+	accessToken  string
+	refreshToken string
+	expiry       time.Time
+
+	// The DPoP nonce each server last asked for, by host
+	nonces map[string]string
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	IDToken      string `json:"id_token"`
+}
+
+type idTokenClaims struct {
+	Issuer   string   `json:"iss"`
+	Subject  string   `json:"sub"`
+	Audience audience `json:"aud"`
+	Expiry   int64    `json:"exp"`
+	Nonce    string   `json:"nonce"`
+	WebID    string   `json:"webid"`
+}
+
+// token makes a token request and keeps what comes back. The ID token has
+// to carry the nonce when one is given
+func (s *Session) token(ctx context.Context, form url.Values, nonce string) error {
+	p := s.p
+
+	var res tokenResponse
+
+	// One retry if the provider wants a nonce in the proof
+	for retried := false; ; retried = true {
+		p.mu.Lock()
+		proof, err := p.c.key.Proof(http.MethodPost, p.TokenEndpoint, "", p.nonce)
+		p.mu.Unlock()
+
+		if err != nil {
+			return err
+		}
+
+		r, err := http.NewRequestWithContext(ctx, http.MethodPost, p.TokenEndpoint, strings.NewReader(p.clientAuth(form).Encode()))
+		if err != nil {
+			return err
+		}
+
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.Header.Set("DPoP", proof)
+
+		if p.ClientSecret != "" {
+			r.SetBasicAuth(url.QueryEscape(p.ClientID), url.QueryEscape(p.ClientSecret))
+		}
+
+		err = p.c.doJSON(r, &res)
+
+		var e *Error
+		if errors.As(err, &e) && e.Code == "use_dpop_nonce" && e.Nonce != "" && !retried {
+			p.mu.Lock()
+			p.nonce = e.Nonce
+			p.mu.Unlock()
+
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		break
+	}
+
+	if !strings.EqualFold(res.TokenType, "DPoP") || res.AccessToken == "" {
+		return fmt.Errorf("%w: got a %q token, not DPoP", ErrInvalidToken, res.TokenType)
+	}
+
+	var webID string
+
+	if res.IDToken != "" {
+		claims, err := p.verifyIDToken(ctx, res.IDToken, nonce)
+		if err != nil {
+			return err
+		}
+
+		webID = claims.WebID
+	} else if nonce != "" {
+		return fmt.Errorf("%w: no ID token", ErrInvalidToken)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if webID != "" {
+		s.webID = webID
+	}
+
+	s.accessToken = res.AccessToken
+
+	if res.RefreshToken != "" {
+		s.refreshToken = res.RefreshToken
+	}
+
+	s.expiry = time.Time{}
+	if res.ExpiresIn > 0 {
+		s.expiry = time.Now().Add(time.Duration(res.ExpiresIn) * time.Second)
+	}
+
+	return nil
+}
+
+// clientAuth adds the client ID to a token request for clients without a
+// secret, those with one use basic authentication
+func (p *Provider) clientAuth(form url.Values) url.Values {
+	if p.ClientSecret != "" {
+		return form
+	}
+
+	f := url.Values{"client_id": {p.ClientID}}
+	for k, v := range form {
+		f[k] = v
+	}
+
+	return f
+}
+
+// verifyIDToken checks the ID token was signed by the provider for this
+// client, and names a WebID
+func (p *Provider) verifyIDToken(ctx context.Context, token, nonce string) (*idTokenClaims, error) {
+	var claims idTokenClaims
+
+	keyFor := func(refetch bool) func(h jwtHeader) (crypto.PublicKey, error) {
+		return func(h jwtHeader) (crypto.PublicKey, error) {
+			jwks, err := p.keys(ctx, refetch)
+			if err != nil {
+				return nil, err
+			}
+
+			return jwks.key(h.Kid)
+		}
+	}
+
+	// A key not seen before may be a new one, so the keys are fetched again
+	_, err := parseJWT(token, &claims, keyFor(false))
+	if err != nil {
+		_, err = parseJWT(token, &claims, keyFor(true))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	switch {
+	case claims.Issuer != p.Issuer:
+		return nil, fmt.Errorf("%w: issued by %v", ErrInvalidToken, claims.Issuer)
+	case !claims.Audience.has(p.ClientID):
+		return nil, fmt.Errorf("%w: issued to %v", ErrInvalidToken, claims.Audience)
+	case time.Unix(claims.Expiry, 0).Before(time.Now()):
+		return nil, fmt.Errorf("%w: expired", ErrInvalidToken)
+	case nonce != "" && claims.Nonce != nonce:
+		return nil, fmt.Errorf("%w: nonce doesn't match the login", ErrInvalidToken)
+	}
+
+	// Solid-OIDC gives the WebID in its own claim, some providers only use
+	// the subject
+	if claims.WebID == "" && (strings.HasPrefix(claims.Subject, "https://") || strings.HasPrefix(claims.Subject, "http://")) {
+		claims.WebID = claims.Subject
+	}
+
+	if claims.WebID == "" {
+		return nil, fmt.Errorf("%w: no WebID", ErrInvalidToken)
+	}
+
+	return &claims, nil
+}
+
+// WebID is who signed in
+func (s *Session) WebID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.webID
+}
+
+// Refresh trades the refresh token for a new access token
+func (s *Session) Refresh(ctx context.Context) error {
+	s.mu.Lock()
+	refreshToken := s.refreshToken
+	s.mu.Unlock()
+
+	if refreshToken == "" {
+		return fmt.Errorf("%w: no refresh token", ErrInvalidToken)
+	}
+
+	return s.token(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	}, "")
+}
+
+// AccessToken returns the access token, refreshing it first if it is about
+// to run out and can be
+func (s *Session) AccessToken(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	expiring := !s.expiry.IsZero() && time.Until(s.expiry) < refreshMargin && s.refreshToken != ""
+	token := s.accessToken
+	s.mu.Unlock()
+
+	if !expiring {
+		return token, nil
+	}
+
+	if err := s.Refresh(ctx); err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.accessToken, nil
+}
+
+// Client returns an http.Client making requests as the user, for
+// solid.WithHTTPClient
+func (s *Session) Client() *http.Client {
+	return &http.Client{Transport: s, Timeout: s.p.c.http.Timeout}
+}
+
+// RoundTrip sends the request with the access token and a DPoP proof. A
+// server asking for a DPoP nonce gets the request again with one, when the
+// body can be sent twice
+func (s *Session) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := s.AccessToken(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	transport := s.p.c.http.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	for retried := false; ; retried = true {
+		s.mu.Lock()
+		nonce := s.nonces[req.URL.Host]
+		s.mu.Unlock()
+
+		proof, err := s.p.c.key.Proof(req.Method, req.URL.String(), token, nonce)
+		if err != nil {
+			return nil, err
+		}
+
+		r := req.Clone(req.Context())
+		r.Header.Set("Authorization", "DPoP "+token)
+		r.Header.Set("DPoP", proof)
+
+		if retried && req.GetBody != nil {
+			if r.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+
+		res, err := transport.RoundTrip(r)
+		if err != nil {
+			return nil, err
+		}
+
+		next := res.Header.Get("DPoP-Nonce")
+		if next == "" {
+			return res, nil
+		}
+
+		s.mu.Lock()
+		s.nonces[req.URL.Host] = next
+		s.mu.Unlock()
+
+		canRetry := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+		if retried || res.StatusCode != http.StatusUnauthorized || next == nonce || !canRetry {
+			return res, nil
+		}
+
+		res.Body.Close()
+	}
+}
+
+func randomString() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
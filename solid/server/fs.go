@@ -0,0 +1,313 @@
+package server
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/jsonld"
+	"github.com/b1scuit/solid/rdf/parser"
+)
+
+// Resources are files under the root and containers are directories. A
+// file whose extension doesn't give its media type has one added after a
+// $, the way the Node Solid Server stores them, so an RDF document doc is
+// doc$.ttl. RDF is always stored as Turtle and a container's own triples
+// are kept in its $.ttl
+
+const (
+	descriptionFile = "$.ttl"
+	defaultType     = "application/octet-stream"
+)
+
+var extensionTypes = map[string]string{
+	".ttl":    mediaTypeTurtle,
+	".acl":    mediaTypeTurtle,
+	".meta":   mediaTypeTurtle,
+	".nt":     mediaTypeNTriples,
+	".jsonld": mediaTypeJSONLD,
+	".n3":     mediaTypeN3,
+}
+
+var (
+	errConflict = errors.New("server: a resource and a container can't share a name")
+	errNotFound = errors.New("server: not found")
+)
+
+// A file is where a resource is stored
+type file struct {
+	path      string
+	mediaType string
+	container bool
+	modTime   time.Time
+}
+
+// isRDF checks whether the file holds Turtle, which is served as any of
+// the RDF formats
+func (f file) isRDF() bool {
+	return f.container || f.mediaType == mediaTypeTurtle
+}
+
+func typeByExtension(ext string) string {
+	if t, ok := extensionTypes[ext]; ok {
+		return t
+	}
+
+	if t, _, err := mime.ParseMediaType(mime.TypeByExtension(ext)); err == nil && ext != "" {
+		return t
+	}
+
+	return defaultType
+}
+
+func extensionByType(mediaType string) string {
+	if mediaType == mediaTypeTurtle {
+		return ".ttl"
+	}
+
+	for ext, t := range extensionTypes {
+		if t == mediaType {
+			return ext
+		}
+	}
+
+	if exts, _ := mime.ExtensionsByType(mediaType); len(exts) > 0 {
+		return exts[0]
+	}
+
+	return ""
+}
+
+// filePath is where the resource at the URL path is kept, or its
+// directory for a container
+func (s *Server) filePath(p string) string {
+	return filepath.Join(s.root, filepath.FromSlash(path.Clean("/"+p)))
+}
+
+// locate finds the file a URL path is stored in
+func (s *Server) locate(p string) (file, error) {
+	fp := s.filePath(p)
+
+	if strings.HasSuffix(p, "/") {
+		info, err := os.Stat(fp)
+		if err != nil || !info.IsDir() {
+			return file{}, errNotFound
+		}
+
+		return file{path: fp, mediaType: mediaTypeTurtle, container: true, modTime: info.ModTime()}, nil
+	}
+
+	if info, err := os.Stat(fp); err == nil {
+		if info.IsDir() {
+			return file{}, errConflict
+		}
+
+		return file{path: fp, mediaType: typeByExtension(filepath.Ext(fp)), modTime: info.ModTime()}, nil
+	}
+
+	matches, _ := filepath.Glob(escapeGlob(fp) + "$.*")
+	if len(matches) == 0 {
+		return file{}, errNotFound
+	}
+
+	info, err := os.Stat(matches[0])
+	if err != nil {
+		return file{}, err
+	}
+
+	return file{path: matches[0], mediaType: typeByExtension(filepath.Ext(matches[0])), modTime: info.ModTime()}, nil
+}
+
+func escapeGlob(p string) string {
+	r := strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`)
+	return r.Replace(p)
+}
+
+// store writes a resource with its media type, replacing whatever it was
+// stored as before and making the containers it is in
+func (s *Server) store(p, mediaType string, b []byte) error {
+	fp := s.filePath(p)
+
+	if err := s.makeContainers(path.Dir(path.Clean("/" + p))); err != nil {
+		return err
+	}
+
+	if old, err := s.locate(p); err == nil {
+		if err := os.Remove(old.path); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, errNotFound) {
+		return err
+	}
+
+	if typeByExtension(filepath.Ext(fp)) != mediaType {
+		fp += "$" + extensionByType(mediaType)
+	}
+
+	return writeFile(fp, b)
+}
+
+// writeFile replaces a file in one go, so readers never see half of it
+func writeFile(fp string, b []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(fp), ".tmp-*")
+	if err != nil {
+		return err
+	}
+
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+
+		return err
+	}
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), fp)
+}
+
+// makeContainers makes the directory of a container path and those above
+// it, failing if a resource is in the way
+func (s *Server) makeContainers(dir string) error {
+	fp, p := s.root, "/"
+
+	for _, name := range strings.Split(strings.Trim(dir, "/"), "/") {
+		if name == "" {
+			continue
+		}
+
+		fp, p = filepath.Join(fp, name), p+name
+
+		info, err := os.Stat(fp)
+		if err == nil && !info.IsDir() {
+			return errConflict
+		}
+
+		if errors.Is(err, fs.ErrNotExist) {
+			if _, err := s.locate(p); err == nil {
+				return errConflict
+			}
+
+			if err := os.Mkdir(fp, 0o755); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+
+		p += "/"
+	}
+
+	return nil
+}
+
+// members lists the names of what a container holds, containers ending
+// in a slash. Auxiliary resources and the container's own files aren't
+// members
+func members(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+
+	for _, e := range entries {
+		name := e.Name()
+
+		if strings.HasPrefix(name, "$") || strings.HasPrefix(name, ".tmp-") {
+			continue
+		}
+
+		if e.IsDir() {
+			names = append(names, name+"/")
+			continue
+		}
+
+		if i := strings.Index(name, "$"); i >= 0 {
+			name = name[:i]
+		}
+
+		if isAuxiliary(name) {
+			continue
+		}
+
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// isAuxiliary checks whether a name is that of an ACL or description
+// resource, which belongs to the resource it is named after
+func isAuxiliary(name string) bool {
+	return strings.HasSuffix(name, aclSuffix) || strings.HasSuffix(name, metaSuffix)
+}
+
+// readGraph parses a stored Turtle file, relative IRIs being resolved
+// against the URL it is served at
+func readGraph(fp, base string) (*rdf.Graph, error) {
+	b, err := os.ReadFile(fp)
+	if errors.Is(err, fs.ErrNotExist) {
+		return rdf.NewGraph(), nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return parseRDF(bytes.NewReader(b), mediaTypeTurtle, base)
+}
+
+// parseRDF reads a body in one of the RDF formats that are accepted
+func parseRDF(r *bytes.Reader, mediaType, base string) (*rdf.Graph, error) {
+	if mediaType == mediaTypeJSONLD {
+		return jsonld.Parse(r, base)
+	}
+
+	p, err := parser.New(parser.WithBase(base))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.Do(r); err != nil {
+		return nil, err
+	}
+
+	return p.GetGraph(), nil
+}
+
+func etag(b []byte) string {
+	sum := sha256.Sum256(b)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+func randomName() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
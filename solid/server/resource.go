@@ -0,0 +1,716 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/b1scuit/solid/internal/conneg"
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/n3patch"
+	"github.com/b1scuit/solid/rdf/serializer"
+	"github.com/b1scuit/solid/rdf/sparql"
+	"github.com/b1scuit/solid/rdf/vocab/acl"
+	"github.com/b1scuit/solid/rdf/vocab/ldp"
+	"github.com/b1scuit/solid/rdf/vocab/space"
+	"github.com/b1scuit/solid/solid"
+)
+
+func isRDFType(mediaType string) bool {
+	for _, t := range rdfTypes {
+		if t == mediaType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// get serves a resource, RDF in the format the client likes most
+func (s *Server) get(w http.ResponseWriter, req *request) {
+	p := req.URL.Path
+
+	if !s.authorize(w, req, p, acl.Read) {
+		return
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	f, err := s.locate(p)
+	if err != nil {
+		code := httpStatus(err)
+		http.Error(w, http.StatusText(code), code)
+
+		return
+	}
+
+	tag, err := s.state(p, f)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h := w.Header()
+	s.headers(w, req, p, f)
+	h.Set("ETag", tag)
+	h.Set("Last-Modified", f.modTime.UTC().Format(http.TimeFormat))
+	h.Set("WAC-Allow", s.wacAllow(req, p))
+
+	if !conditions(w, req.Request, tag) {
+		return
+	}
+
+	var body []byte
+	mediaType := f.mediaType
+
+	if f.isRDF() {
+		h.Add("Vary", "Accept")
+
+		if mediaType = conneg.Negotiate(req.Header.Get("Accept"), rdfTypes); mediaType == "" {
+			http.Error(w, "RDF is served as "+strings.Join(rdfTypes, ", "), http.StatusNotAcceptable)
+			return
+		}
+
+		g, err := s.graph(req, p, f)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if body, err = s.encode(mediaType, g); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	} else if body, err = os.ReadFile(f.path); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.Set("Content-Type", mediaType)
+	h.Set("Content-Length", strconv.Itoa(len(body)))
+
+	if req.Method != http.MethodHead {
+		w.Write(body)
+	}
+}
+
+// headers writes what every response about a resource carries
+func (s *Server) headers(w http.ResponseWriter, req *request, p string, f file) {
+	h := w.Header()
+
+	s.links(w, req, p, f)
+	h.Set("Allow", allowed(p))
+	h.Set("Accept-Patch", mediaTypeN3+", "+mediaTypeSparqlUpdate)
+	h.Set("Accept-Put", "*/*")
+	h.Add("Vary", "Authorization")
+
+	if f.container {
+		h.Set("Accept-Post", "*/*")
+	}
+}
+
+// state is the ETag of what is stored for a resource
+func (s *Server) state(p string, f file) (string, error) {
+	if !f.container {
+		b, err := os.ReadFile(f.path)
+		return etag(b), err
+	}
+
+	b, err := os.ReadFile(filepath.Join(f.path, descriptionFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	names, err := members(f.path)
+
+	return etag(append(b, strings.Join(names, "\n")...)), err
+}
+
+// graph reads an RDF resource, a container being described with what it
+// contains
+func (s *Server) graph(req *request, p string, f file) (*rdf.Graph, error) {
+	if !f.container {
+		return readGraph(f.path, req.target)
+	}
+
+	g, err := readGraph(filepath.Join(f.path, descriptionFile), req.target)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := members(f.path)
+	if err != nil {
+		return nil, err
+	}
+
+	self := rdf.IRI(req.target)
+	g.Add(
+		rdf.NewTriple(self, rdf.RDFType, ldp.Resource),
+		rdf.NewTriple(self, rdf.RDFType, ldp.Container),
+		rdf.NewTriple(self, rdf.RDFType, ldp.BasicContainer),
+	)
+
+	if p == "/" {
+		g.Add(rdf.NewTriple(self, rdf.RDFType, space.Storage))
+	}
+
+	for _, name := range names {
+		member := (&url.URL{Path: strings.TrimSuffix(name, "/")}).EscapedPath()
+		if strings.HasSuffix(name, "/") {
+			member += "/"
+		}
+
+		g.Add(rdf.NewTriple(self, ldp.Contains, rdf.IRI(req.target+member)))
+	}
+
+	return g, nil
+}
+
+// encode writes a graph in one of the RDF formats
+func (s *Server) encode(mediaType string, g *rdf.Graph) ([]byte, error) {
+	ser := serializer.MustNew(serializer.WithPrefixes(s.prefixes), serializer.WithCommonPrefixes())
+
+	var buf bytes.Buffer
+	var err error
+
+	switch mediaType {
+	case mediaTypeNTriples:
+		err = ser.NTriples(&buf, g)
+	case mediaTypeJSONLD:
+		err = ser.JSONLD(&buf, g)
+	default:
+		err = ser.Turtle(&buf, g)
+	}
+
+	return buf.Bytes(), err
+}
+
+// body reads the request body and its media type, which an empty body
+// needn't have
+func body(w http.ResponseWriter, req *request) ([]byte, string, bool) {
+	b, err := io.ReadAll(http.MaxBytesReader(w, req.Body, maxRequestBodySize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return nil, "", false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil && len(b) > 0 {
+		http.Error(w, "A Content-Type is needed", http.StatusBadRequest)
+		return nil, "", false
+	}
+
+	return b, mediaType, true
+}
+
+// content turns a body into what is stored, RDF being parsed and written
+// as Turtle. Auxiliary resources have to be RDF
+func (s *Server) content(w http.ResponseWriter, req *request, p string, b []byte, mediaType string) ([]byte, string, bool) {
+	if !isRDFType(mediaType) {
+		if isAuxiliary(path.Base(p)) {
+			http.Error(w, "ACLs and descriptions are RDF", http.StatusUnsupportedMediaType)
+			return nil, "", false
+		}
+
+		if mediaType == "" {
+			http.Error(w, "A Content-Type is needed", http.StatusBadRequest)
+			return nil, "", false
+		}
+
+		return b, mediaType, true
+	}
+
+	g, err := parseRDF(bytes.NewReader(b), mediaType, req.base+(&url.URL{Path: p}).EscapedPath())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, "", false
+	}
+
+	if b, err = s.encode(mediaTypeTurtle, g); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, "", false
+	}
+
+	return b, mediaTypeTurtle, true
+}
+
+// description reads the triples given for a new container, which can't
+// say what it contains
+func (s *Server) description(w http.ResponseWriter, req *request, p string, b []byte, mediaType string) (*rdf.Graph, bool) {
+	if len(b) == 0 {
+		return rdf.NewGraph(), true
+	}
+
+	if !isRDFType(mediaType) {
+		http.Error(w, "Containers are described in RDF", http.StatusUnsupportedMediaType)
+		return nil, false
+	}
+
+	g, err := parseRDF(bytes.NewReader(b), mediaType, req.base+(&url.URL{Path: p}).EscapedPath())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+
+	return g, containment(w, g)
+}
+
+// containment turns away descriptions of containers that say what they
+// contain, which only the server does
+func containment(w http.ResponseWriter, g *rdf.Graph) bool {
+	if len(g.Match(nil, ldp.Contains, nil)) > 0 {
+		http.Error(w, "Containment triples are kept by the server", http.StatusConflict)
+		return false
+	}
+
+	return true
+}
+
+// saveDescription writes a container's own triples
+func (s *Server) saveDescription(p string, g *rdf.Graph) error {
+	if err := s.makeContainers(p); err != nil {
+		return err
+	}
+
+	fp := filepath.Join(s.filePath(p), descriptionFile)
+
+	if g.Len() == 0 {
+		if err := os.Remove(fp); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+
+		return nil
+	}
+
+	b, err := s.encode(mediaTypeTurtle, g)
+	if err != nil {
+		return err
+	}
+
+	return writeFile(fp, b)
+}
+
+// creating checks the request can make a resource that doesn't exist yet,
+// which needs Append on the container it goes in
+func (s *Server) creating(w http.ResponseWriter, req *request, p string) bool {
+	parent, ok := solid.ParentURL(p)
+
+	return !ok || s.authorize(w, req, parent, acl.Append)
+}
+
+// put creates or replaces a resource, or creates a container
+//
+// https://solidproject.org/TR/protocol#writing-resources
+func (s *Server) put(w http.ResponseWriter, req *request) {
+	p := req.URL.Path
+
+	if !s.authorize(w, req, p, acl.Write) {
+		return
+	}
+
+	b, mediaType, ok := body(w, req)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := s.locate(p)
+	if err != nil && !errors.Is(err, errNotFound) {
+		code := httpStatus(err)
+		http.Error(w, err.Error(), code)
+
+		return
+	}
+
+	exists := err == nil
+
+	var tag string
+	if exists {
+		if tag, err = s.state(p, f); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	} else if !s.creating(w, req, p) {
+		return
+	}
+
+	if !conditions(w, req.Request, tag) {
+		return
+	}
+
+	if strings.HasSuffix(p, "/") {
+		g, ok := s.description(w, req, p, b, mediaType)
+		if !ok {
+			return
+		}
+
+		err = s.saveDescription(p, g)
+	} else {
+		if b, mediaType, ok = s.content(w, req, p, b, mediaType); !ok {
+			return
+		}
+
+		err = s.store(p, mediaType, b)
+	}
+
+	s.written(w, req, p, exists, err)
+}
+
+// written answers a request that stored a resource
+func (s *Server) written(w http.ResponseWriter, req *request, p string, existed bool, err error) {
+	if err != nil {
+		http.Error(w, err.Error(), httpStatus(err))
+		return
+	}
+
+	if f, err := s.locate(p); err == nil {
+		if tag, err := s.state(p, f); err == nil {
+			w.Header().Set("ETag", tag)
+		}
+	}
+
+	if existed {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Location", req.base+(&url.URL{Path: p}).EscapedPath())
+	w.WriteHeader(http.StatusCreated)
+}
+
+// post creates a resource or container in a container, named after the
+// Slug header when it is free
+func (s *Server) post(w http.ResponseWriter, req *request) {
+	p := req.URL.Path
+
+	if !strings.HasSuffix(p, "/") {
+		w.Header().Set("Allow", allowed(p))
+		http.Error(w, "Only containers are posted to", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	if !s.authorize(w, req, p, acl.Append) {
+		return
+	}
+
+	b, mediaType, ok := body(w, req)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.locate(p); err != nil {
+		code := httpStatus(err)
+		http.Error(w, http.StatusText(code), code)
+
+		return
+	}
+
+	container := false
+	for _, l := range solid.ParseLinks(req.Header.Values("Link"), req.target) {
+		if l.Rel == "type" && (l.URL == string(ldp.BasicContainer) || l.URL == string(ldp.Container)) {
+			container = true
+		}
+	}
+
+	name := slug(req.Header.Get("Slug"))
+
+	for name == "" || isAuxiliary(name) || s.taken(p+name) {
+		name = randomName()
+	}
+
+	var err error
+
+	if container {
+		g, ok := s.description(w, req, p+name+"/", b, mediaType)
+		if !ok {
+			return
+		}
+
+		name += "/"
+		err = s.saveDescription(p+name, g)
+	} else {
+		if b, mediaType, ok = s.content(w, req, p+name, b, mediaType); !ok {
+			return
+		}
+
+		err = s.store(p+name, mediaType, b)
+	}
+
+	s.written(w, req, p+name, false, err)
+}
+
+// slug makes a Slug header safe to name a file with
+func slug(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+
+		return '-'
+	}, s)
+
+	return strings.TrimLeft(s, ".")
+}
+
+// taken checks whether a resource or container has the name already
+func (s *Server) taken(p string) bool {
+	_, err := s.locate(p)
+	_, errContainer := s.locate(p + "/")
+
+	return !errors.Is(err, errNotFound) || errContainer == nil
+}
+
+// patch applies an N3 Patch or SPARQL Update to an RDF resource, creating
+// it when it doesn't exist
+//
+// https://solidproject.org/TR/protocol#n3-patch
+func (s *Server) patch(w http.ResponseWriter, req *request) {
+	p := req.URL.Path
+
+	b, mediaType, ok := body(w, req)
+	if !ok {
+		return
+	}
+
+	var apply func(g *rdf.Graph) error
+	var modes []rdf.IRI
+
+	switch mediaType {
+	case mediaTypeN3:
+		patch, err := n3patch.Parse(bytes.NewReader(b), req.target)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		apply, modes = patch.Apply, n3Modes(patch)
+	case mediaTypeSparqlUpdate:
+		c := sparql.MustNew(sparql.WithBase(req.target))
+
+		u, err := c.ParseUpdate(string(b))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if modes, ok = updateModes(u); !ok {
+			http.Error(w, "Only INSERT DATA, DELETE DATA and DELETE/INSERT of the document are supported", http.StatusUnprocessableEntity)
+			return
+		}
+
+		apply = func(g *rdf.Graph) error {
+			ds := rdf.NewDataset(g)
+			return c.ExecUpdate(ds, u)
+		}
+	default:
+		w.Header().Set("Accept-Patch", mediaTypeN3+", "+mediaTypeSparqlUpdate)
+		http.Error(w, "Patches are N3 Patch or SPARQL Update", http.StatusUnsupportedMediaType)
+
+		return
+	}
+
+	if !s.authorize(w, req, p, modes...) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := s.locate(p)
+	if err != nil && !errors.Is(err, errNotFound) {
+		http.Error(w, err.Error(), httpStatus(err))
+		return
+	}
+
+	exists := err == nil
+
+	var tag string
+	if exists {
+		if !f.isRDF() {
+			http.Error(w, "Only RDF documents can be patched", http.StatusUnsupportedMediaType)
+			return
+		}
+
+		if tag, err = s.state(p, f); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	} else if !s.creating(w, req, p) {
+		return
+	}
+
+	if !conditions(w, req.Request, tag) {
+		return
+	}
+
+	fp := f.path
+	if f.container {
+		fp = filepath.Join(f.path, descriptionFile)
+	}
+
+	g, err := readGraph(fp, req.target)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := apply(g); err != nil {
+		code := http.StatusConflict
+		if errors.Is(err, n3patch.ErrInvalid) {
+			code = http.StatusUnprocessableEntity
+		}
+
+		http.Error(w, err.Error(), code)
+
+		return
+	}
+
+	if strings.HasSuffix(p, "/") {
+		if !containment(w, g) {
+			return
+		}
+
+		s.written(w, req, p, exists, s.saveDescription(p, g))
+
+		return
+	}
+
+	out, err := s.encode(mediaTypeTurtle, g)
+	if err == nil {
+		err = s.store(p, mediaTypeTurtle, out)
+	}
+
+	s.written(w, req, p, exists, err)
+}
+
+// n3Modes are the modes a patch needs: Append to insert, Read as well to
+// match a where, and Read and Write to delete
+func n3Modes(p *n3patch.Patch) []rdf.IRI {
+	switch {
+	case len(p.Deletes) > 0:
+		return []rdf.IRI{acl.Read, acl.Write}
+	case len(p.Where) > 0:
+		return []rdf.IRI{acl.Read, acl.Append}
+	}
+
+	return []rdf.IRI{acl.Append}
+}
+
+// updateModes are the modes an update needs, false when it does more
+// than change the document's own graph
+func updateModes(u *sparql.Update) ([]rdf.IRI, bool) {
+	modes := []rdf.IRI{acl.Append}
+
+	for _, op := range u.Operations {
+		var quads []sparql.QuadPattern
+
+		switch o := op.(type) {
+		case sparql.InsertData:
+			quads = o.Quads
+		case sparql.DeleteData:
+			quads, modes = o.Quads, []rdf.IRI{acl.Read, acl.Write}
+		case sparql.Modify:
+			if o.With != "" || len(o.Using) > 0 || len(o.UsingNamed) > 0 {
+				return nil, false
+			}
+
+			quads, modes = append(o.Delete, o.Insert...), []rdf.IRI{acl.Read, acl.Write}
+		default:
+			return nil, false
+		}
+
+		for _, q := range quads {
+			if q.Graph != nil {
+				return nil, false
+			}
+		}
+	}
+
+	return modes, true
+}
+
+// delete removes a resource with its ACL and description, or an empty
+// container
+func (s *Server) delete(w http.ResponseWriter, req *request) {
+	p := req.URL.Path
+
+	if p == "/" {
+		w.Header().Set("Allow", allowed(p))
+		http.Error(w, "The root of the pod can't be deleted", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	if !s.authorize(w, req, p, acl.Write) {
+		return
+	}
+
+	if parent, _ := solid.ParentURL(p); !isAuxiliary(path.Base(p)) && !s.authorize(w, req, parent, acl.Write) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := s.locate(p)
+	if err != nil {
+		code := httpStatus(err)
+		http.Error(w, http.StatusText(code), code)
+
+		return
+	}
+
+	tag, err := s.state(p, f)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if !conditions(w, req.Request, tag) {
+		return
+	}
+
+	if f.container {
+		if names, err := members(f.path); err != nil || len(names) > 0 {
+			http.Error(w, "The container isn't empty", http.StatusConflict)
+			return
+		}
+
+		for _, name := range []string{descriptionFile, aclSuffix, metaSuffix} {
+			os.Remove(filepath.Join(f.path, name))
+		}
+
+		err = os.Remove(f.path)
+	} else {
+		err = os.Remove(f.path)
+
+		for _, suffix := range []string{aclSuffix, metaSuffix} {
+			if aux, auxErr := s.locate(p + suffix); auxErr == nil && !isAuxiliary(path.Base(p)) {
+				os.Remove(aux.path)
+			}
+		}
+	}
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
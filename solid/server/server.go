@@ -0,0 +1,525 @@
+// Package server is a small Solid pod over a directory, for developing
+// and testing apps offline. It serves containers and resources with
+// content negotiation between Turtle, JSON-LD and N-Triples, conditional
+// requests, N3 Patch and SPARQL Update, and enforces Web Access Control
+// from the .acl documents stored alongside the resources
+//
+// https://solidproject.org/TR/protocol
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/vocab/acl"
+	"github.com/b1scuit/solid/rdf/vocab/ldp"
+	"github.com/b1scuit/solid/rdf/vocab/space"
+	"github.com/b1scuit/solid/rdf/vocab/vcard"
+	"github.com/b1scuit/solid/solid/oidc"
+	"github.com/b1scuit/solid/solid/wac"
+)
+
+const (
+	mediaTypeTurtle       = "text/turtle"
+	mediaTypeNTriples     = "application/n-triples"
+	mediaTypeJSONLD       = "application/ld+json"
+	mediaTypeN3           = "text/n3"
+	mediaTypeSparqlUpdate = "application/sparql-update"
+
+	aclSuffix  = ".acl"
+	metaSuffix = ".meta"
+
+	maxRequestBodySize = 10 << 20
+)
+
+// rdfTypes are the formats RDF is served in, in order of preference
+var rdfTypes = []string{mediaTypeTurtle, mediaTypeJSONLD, mediaTypeNTriples}
+
+// An Authenticator finds the WebID a request is made by, empty for anyone
+// who hasn't signed in. An error turns the request away as unauthorized.
+// target is the URL the request is for under the pod's base URL, which
+// behind a proxy isn't the address the request arrived at
+type Authenticator func(r *http.Request, target string) (string, error)
+
+// Tokens authenticates requests by the access token in their
+// Authorization header, Bearer or DPoP, looking up the WebID each token
+// stands for. DPoP proofs are checked against the request and the token
+func Tokens(webIDs map[string]string) Authenticator {
+	return func(r *http.Request, target string) (string, error) {
+		scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !ok {
+			return "", nil
+		}
+
+		switch {
+		case strings.EqualFold(scheme, "DPoP"):
+			if _, err := oidc.VerifyProof(r.Header.Get("DPoP"), r.Method, target, token, time.Now()); err != nil {
+				return "", err
+			}
+		case !strings.EqualFold(scheme, "Bearer"):
+			return "", nil
+		}
+
+		webID, ok := webIDs[token]
+		if !ok {
+			return "", errors.New("server: unknown access token")
+		}
+
+		return webID, nil
+	}
+}
+
+type ServerOption func(*Server)
+
+// WithBaseURL sets the URL of the root of the pod, which is otherwise
+// taken from each request's Host. Set it when the pod is reached under
+// several names, as the IRIs in the documents it stores are absolute
+func WithBaseURL(u string) ServerOption {
+	return func(s *Server) {
+		s.base = strings.TrimSuffix(u, "/")
+	}
+}
+
+// WithAuthenticator sets how requests are authenticated, without one
+// every request is made by the public
+func WithAuthenticator(a Authenticator) ServerOption {
+	return func(s *Server) {
+		s.authenticate = a
+	}
+}
+
+// WithOwner gives the WebID full control of the pod, writing an ACL at
+// its root when there is none yet. Without an ACL at the root nothing is
+// allowed
+func WithOwner(webID string) ServerOption {
+	return func(s *Server) {
+		s.owner = webID
+	}
+}
+
+// WithPrefixes sets prefixes used when writing Turtle and JSON-LD, on top
+// of those each document declares
+func WithPrefixes(prefixes rdf.PrefixMap) ServerOption {
+	return func(s *Server) {
+		s.prefixes.Merge(prefixes)
+	}
+}
+
+// A Server is a pod over a directory, an http.Handler
+type Server struct {
+	root         string
+	base         string
+	owner        string
+	prefixes     rdf.PrefixMap
+	authenticate Authenticator
+
+	// Held for reading while a request looks at the files and for
+	// writing while one changes them
+	mu sync.RWMutex
+}
+
+func New(root string, opts ...ServerOption) (*Server, error) {
+	s := &Server{
+		prefixes:     make(rdf.PrefixMap),
+		authenticate: func(*http.Request, string) (string, error) { return "", nil },
+	}
+
+	for _, f := range opts {
+		f(s)
+	}
+
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(abs, 0o755); err != nil {
+		return nil, err
+	}
+
+	s.root = abs
+
+	if s.owner == "" {
+		return s, nil
+	}
+
+	if _, err := os.Stat(filepath.Join(abs, aclSuffix)); !errors.Is(err, os.ErrNotExist) {
+		return s, err
+	}
+
+	// Relative IRIs keep the ACL right whatever URL the pod is reached at
+	owner := fmt.Sprintf(`@prefix acl: %v .
+
+<#owner>
+    a acl:Authorization ;
+    acl:agent %v ;
+    acl:accessTo <./> ;
+    acl:default <./> ;
+    acl:mode acl:Read, acl:Write, acl:Control .
+`, acl.Namespace, rdf.IRI(s.owner))
+
+	if err := writeFile(filepath.Join(abs, aclSuffix), []byte(owner)); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func MustNew(root string, opts ...ServerOption) *Server {
+	s, err := New(root, opts...)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+// A request is one being served, with who made it
+type request struct {
+	*http.Request
+
+	// base is the URL of the pod's root without the slash, target the
+	// URL of the resource asked for
+	base   string
+	target string
+	agent  string
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h := w.Header()
+
+	if origin := r.Header.Get("Origin"); origin != "" {
+		h.Set("Access-Control-Allow-Origin", origin)
+		h.Set("Access-Control-Allow-Credentials", "true")
+		h.Set("Access-Control-Expose-Headers", "Accept-Patch, Accept-Post, Accept-Put, Allow, ETag, Last-Modified, Link, Location, WAC-Allow")
+		h.Add("Vary", "Origin")
+	}
+
+	if r.Method == http.MethodOptions {
+		h.Set("Access-Control-Allow-Methods", allowed(r.URL.Path))
+		h.Set("Access-Control-Allow-Headers", r.Header.Get("Access-Control-Request-Headers"))
+		h.Set("Access-Control-Max-Age", "3600")
+		h.Set("Allow", allowed(r.URL.Path))
+		w.WriteHeader(http.StatusNoContent)
+
+		return
+	}
+
+	if !validPath(r.URL.Path) {
+		http.Error(w, "Invalid path", http.StatusBadRequest)
+		return
+	}
+
+	req := &request{Request: r, base: s.baseURL(r)}
+	req.target = req.base + (&url.URL{Path: r.URL.Path}).EscapedPath()
+
+	agent, err := s.authenticate(r, req.target)
+	if err != nil {
+		h.Set("WWW-Authenticate", `DPoP, Bearer`)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+
+		return
+	}
+
+	req.agent = agent
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		s.get(w, req)
+	case http.MethodPut:
+		s.put(w, req)
+	case http.MethodPost:
+		s.post(w, req)
+	case http.MethodPatch:
+		s.patch(w, req)
+	case http.MethodDelete:
+		s.delete(w, req)
+	default:
+		h.Set("Allow", allowed(r.URL.Path))
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) baseURL(r *http.Request) string {
+	if s.base != "" {
+		return s.base
+	}
+
+	if r.TLS != nil {
+		return "https://" + r.Host
+	}
+
+	return "http://" + r.Host
+}
+
+// validPath turns away paths that would escape the root or name the
+// files resources are stored in rather than resources
+func validPath(p string) bool {
+	if !strings.HasPrefix(p, "/") || strings.Contains(p, "//") {
+		return false
+	}
+
+	for _, name := range strings.Split(p, "/") {
+		if name == "." || name == ".." || strings.Contains(name, "$") || strings.HasPrefix(name, ".tmp-") || strings.ContainsRune(name, '\\') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func allowed(p string) string {
+	if strings.HasSuffix(p, "/") {
+		return "OPTIONS, HEAD, GET, POST, PUT, PATCH, DELETE"
+	}
+
+	return "OPTIONS, HEAD, GET, PUT, PATCH, DELETE"
+}
+
+// subject is the resource whose ACL governs a URL path, an auxiliary
+// resource being governed by the resource it belongs to
+func subject(p string) string {
+	for _, suffix := range []string{aclSuffix, metaSuffix} {
+		if s, ok := strings.CutSuffix(p, suffix); ok && !strings.HasSuffix(p, "/") {
+			return s
+		}
+	}
+
+	return p
+}
+
+// modes returns the modes the request has on the resource at the URL
+// path, and the public has
+func (s *Server) modes(ctx context.Context, req *request, p string) ([]rdf.IRI, []rdf.IRI, error) {
+	resource := req.base + (&url.URL{Path: p}).EscapedPath()
+
+	a, err := wac.Effective(ctx, resource, s.loader(req.base))
+	if errors.Is(err, wac.ErrNoACL) {
+		return nil, nil, nil
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	public, err := a.Modes(ctx, resource, wac.Request{Origin: req.Header.Get("Origin"), Groups: s.groups(req.base)})
+	if err != nil || req.agent == "" {
+		return public, public, err
+	}
+
+	user, err := a.Modes(ctx, resource, wac.Request{Agent: req.agent, Origin: req.Header.Get("Origin"), Groups: s.groups(req.base)})
+
+	return user, public, err
+}
+
+// authorize checks the request has each of the modes on the resource at
+// the URL path, answering 401 or 403 when it hasn't. An ACL needs Control
+// of its resource whatever is done to it
+func (s *Server) authorize(w http.ResponseWriter, req *request, p string, modes ...rdf.IRI) bool {
+	if sub := subject(p); sub != p && strings.HasSuffix(p, aclSuffix) {
+		p, modes = sub, []rdf.IRI{acl.Control}
+	} else {
+		p = sub
+	}
+
+	granted, _, err := s.modes(req.Context(), req, p)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+
+	for _, m := range modes {
+		if !hasMode(granted, m) {
+			if req.agent == "" {
+				w.Header().Set("WWW-Authenticate", `DPoP, Bearer`)
+				http.Error(w, "Sign in to do that", http.StatusUnauthorized)
+			} else {
+				http.Error(w, "Not allowed", http.StatusForbidden)
+			}
+
+			return false
+		}
+	}
+
+	return true
+}
+
+// wacAllow tells the client what it and the public can do to the
+// resource
+//
+// https://solidproject.org/TR/wac#wac-allow
+func (s *Server) wacAllow(req *request, p string) string {
+	user, public, err := s.modes(req.Context(), req, subject(p))
+	if err != nil {
+		return ""
+	}
+
+	names := func(modes []rdf.IRI) string {
+		var s []string
+		for _, m := range modes {
+			s = append(s, strings.ToLower(strings.TrimPrefix(string(m), string(acl.Namespace))))
+		}
+
+		return strings.Join(s, " ")
+	}
+
+	return fmt.Sprintf(`user="%v",public="%v"`, names(user), names(public))
+}
+
+func hasMode(modes []rdf.IRI, mode rdf.IRI) bool {
+	for _, m := range modes {
+		if m == mode {
+			return true
+		}
+	}
+
+	return false
+}
+
+// loader reads a resource's own ACL from the files
+func (s *Server) loader(base string) wac.Loader {
+	return func(ctx context.Context, resource string) (*wac.ACL, error) {
+		p := strings.TrimPrefix(resource, base)
+
+		if p, err := url.PathUnescape(p); err == nil {
+			f, err := s.locate(p + aclSuffix)
+			if err != nil {
+				return nil, nil
+			}
+
+			g, err := readGraph(f.path, resource+aclSuffix)
+			if err != nil {
+				return nil, err
+			}
+
+			return wac.FromGraph(g, resource+aclSuffix, resource), nil
+		}
+
+		return nil, nil
+	}
+}
+
+// groups reads the members of groups stored in the pod, other groups
+// have none
+func (s *Server) groups(base string) wac.GroupLoader {
+	return func(ctx context.Context, group string) ([]string, error) {
+		doc, _, _ := strings.Cut(group, "#")
+
+		p, err := url.PathUnescape(strings.TrimPrefix(doc, base))
+		if err != nil || !strings.HasPrefix(doc, base+"/") {
+			return nil, nil
+		}
+
+		f, err := s.locate(p)
+		if err != nil || !f.isRDF() || f.container {
+			return nil, nil
+		}
+
+		g, err := readGraph(f.path, doc)
+		if err != nil {
+			return nil, err
+		}
+
+		var members []string
+		for _, t := range g.Match(rdf.IRI(group), vcard.HasMember, nil) {
+			if iri, ok := t.Object.(rdf.IRI); ok {
+				members = append(members, string(iri))
+			}
+		}
+
+		return members, nil
+	}
+}
+
+// links writes the Link headers of a resource: its types, ACL and
+// description
+func (s *Server) links(w http.ResponseWriter, req *request, p string, f file) {
+	h := w.Header()
+	link := func(target, rel string) {
+		h.Add("Link", fmt.Sprintf(`<%v>; rel="%v"`, target, rel))
+	}
+
+	link(string(ldp.Resource), "type")
+
+	switch {
+	case f.container:
+		link(string(ldp.Container), "type")
+		link(string(ldp.BasicContainer), "type")
+	case f.isRDF():
+		link(string(ldp.RDFSource), "type")
+	default:
+		link(string(ldp.NonRDFSource), "type")
+	}
+
+	if p == "/" {
+		link(string(space.Storage), "type")
+	}
+
+	if !isAuxiliary(path.Base(p)) || p == "/" {
+		link(req.target+aclSuffix, "acl")
+		link(req.target+metaSuffix, "describedby")
+	}
+}
+
+// conditions checks If-Match and If-None-Match against the resource's
+// current ETag, empty when it doesn't exist, answering 412 or for reads
+// 304 when they fail. If-Match compares tags strongly, so a weak tag never
+// matches it, and If-None-Match weakly
+//
+// https://www.rfc-editor.org/rfc/rfc9110#section-13
+func conditions(w http.ResponseWriter, r *http.Request, tag string) bool {
+	matches := func(header string, weak bool) bool {
+		for _, v := range strings.Split(header, ",") {
+			v = strings.TrimSpace(v)
+			if weak {
+				v = strings.TrimPrefix(v, "W/")
+			}
+
+			if v == "*" && tag != "" || v == tag {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if v := r.Header.Get("If-Match"); v != "" && !matches(v, false) {
+		http.Error(w, "Precondition failed", http.StatusPreconditionFailed)
+		return false
+	}
+
+	if v := r.Header.Get("If-None-Match"); v != "" && matches(v, true) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusNotModified)
+		} else {
+			http.Error(w, "Precondition failed", http.StatusPreconditionFailed)
+		}
+
+		return false
+	}
+
+	return true
+}
+
+// httpStatus is the status an error from the files is answered with
+func httpStatus(err error) int {
+	switch {
+	case errors.Is(err, errNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, errConflict):
+		return http.StatusConflict
+	}
+
+	return http.StatusInternalServerError
+}
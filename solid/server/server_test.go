@@ -0,0 +1,346 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/n3patch"
+	"github.com/b1scuit/solid/rdf/vocab/acl"
+	"github.com/b1scuit/solid/rdf/vocab/foaf"
+	"github.com/b1scuit/solid/solid"
+	"github.com/b1scuit/solid/solid/oidc"
+	"github.com/b1scuit/solid/solid/wac"
+)
+
+const (
+	alice = "https://alice.example/#me"
+	bob   = "https://bob.example/#me"
+)
+
+var tokens = map[string]string{"alice": alice, "bob": bob}
+
+func newPod(t *testing.T) *httptest.Server {
+	s := httptest.NewServer(MustNew(t.TempDir(), WithOwner(alice), WithAuthenticator(Tokens(tokens))))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func do(t *testing.T, s *httptest.Server, method, path, token string, headers map[string]string, body string) (*http.Response, string) {
+	req, err := http.NewRequest(method, s.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer res.Body.Close()
+
+	b, _ := io.ReadAll(res.Body)
+
+	return res, string(b)
+}
+
+func turtle(ct string) map[string]string { return map[string]string{"Content-Type": ct} }
+
+type requestTest struct {
+	Name    string
+	Method  string
+	Path    string
+	Token   string
+	Headers map[string]string
+	Body    string
+
+	Status int
+
+	// Contains is looked for in the body, or in the header named before a
+	// colon
+	Contains []string
+}
+
+// requestTests run in order against one pod
+var requestTests = []requestTest{
+	{"Public can't read the root", "GET", "/", "", nil, "", http.StatusUnauthorized, nil},
+	{"Owner reads the root", "GET", "/", "alice", nil, "", http.StatusOK,
+		[]string{"ldp:BasicContainer", "space:Storage", `Link:<http://www.w3.org/ns/pim/space#Storage>; rel="type"`, `WAC-Allow:user="append control read write",public=""`}},
+	{"Put creates the containers above", "PUT", "/notes/a", "alice", turtle("text/turtle"),
+		`<#it> <http://xmlns.com/foaf/0.1/name> "A" .`, http.StatusCreated, []string{"Location:/notes/a"}},
+	{"Container lists what it contains", "GET", "/notes/", "alice", nil, "", http.StatusOK,
+		[]string{"ldp:contains </notes/a>", `Link:</notes/.acl>; rel="acl"`}},
+	{"Turtle by default", "GET", "/notes/a", "alice", nil, "", http.StatusOK,
+		[]string{"Content-Type:text/turtle", `foaf:name "A"`, `Link:</notes/a.meta>; rel="describedby"`, `Link:<http://www.w3.org/ns/ldp#RDFSource>; rel="type"`}},
+	{"JSON-LD", "GET", "/notes/a", "alice", map[string]string{"Accept": "application/ld+json"}, "", http.StatusOK,
+		[]string{"Content-Type:application/ld+json", `"foaf:name": [`}},
+	{"N-Triples", "GET", "/notes/a", "alice", map[string]string{"Accept": "text/html;q=0.9, application/n-triples"}, "", http.StatusOK,
+		[]string{"Content-Type:application/n-triples", `<http://xmlns.com/foaf/0.1/name> "A" .`}},
+	{"Nothing acceptable", "GET", "/notes/a", "alice", map[string]string{"Accept": "text/html"}, "", http.StatusNotAcceptable, nil},
+	{"Put JSON-LD", "PUT", "/notes/b", "alice", turtle("application/ld+json"),
+		`{"@context": {"foaf": "http://xmlns.com/foaf/0.1/"}, "@id": "#it", "foaf:name": "B"}`, http.StatusCreated, nil},
+	{"JSON-LD read back as Turtle", "GET", "/notes/b", "alice", nil, "", http.StatusOK, []string{`foaf:name "B"`}},
+	{"Replacing", "PUT", "/notes/b", "alice", turtle("text/turtle"), `<#it> <http://xmlns.com/foaf/0.1/name> "B2" .`, http.StatusNoContent, nil},
+	{"Invalid Turtle", "PUT", "/notes/c", "alice", turtle("text/turtle"), `<#it> <p>`, http.StatusBadRequest, nil},
+	{"No content type", "PUT", "/notes/c", "alice", nil, `hello`, http.StatusBadRequest, nil},
+	{"Create only", "PUT", "/notes/a", "alice", map[string]string{"Content-Type": "text/plain", "If-None-Match": "*"}, `x`, http.StatusPreconditionFailed, nil},
+	{"Resource in the way of a container", "PUT", "/notes/a/b", "alice", turtle("text/plain"), `x`, http.StatusConflict, nil},
+	{"N3 Patch", "PATCH", "/notes/a", "alice", turtle("text/n3"),
+		`@prefix solid: <http://www.w3.org/ns/solid/terms#> .
+		_:p a solid:InsertDeletePatch ; solid:where { <#it> <http://xmlns.com/foaf/0.1/name> ?n } ;
+			solid:deletes { <#it> <http://xmlns.com/foaf/0.1/name> ?n } ; solid:inserts { <#it> <http://xmlns.com/foaf/0.1/name> "A2" } .`,
+		http.StatusNoContent, nil},
+	{"SPARQL Update", "PATCH", "/notes/a", "alice", turtle("application/sparql-update"),
+		`INSERT DATA { <#it> <http://xmlns.com/foaf/0.1/nick> "a" }`, http.StatusNoContent, nil},
+	{"Patched", "GET", "/notes/a", "alice", nil, "", http.StatusOK, []string{`foaf:name "A2"`, `foaf:nick "a"`}},
+	{"Patch that doesn't apply", "PATCH", "/notes/a", "alice", turtle("text/n3"),
+		`@prefix solid: <http://www.w3.org/ns/solid/terms#> .
+		_:p a solid:InsertDeletePatch ; solid:deletes { <#it> <http://xmlns.com/foaf/0.1/name> "Z" } .`,
+		http.StatusConflict, nil},
+	{"Invalid patch", "PATCH", "/notes/a", "alice", turtle("text/n3"), `<#it> a <#Thing> .`, http.StatusUnprocessableEntity, nil},
+	{"Update of another graph", "PATCH", "/notes/a", "alice", turtle("application/sparql-update"),
+		`INSERT DATA { GRAPH <#g> { <#it> <#p> 1 } }`, http.StatusUnprocessableEntity, nil},
+	{"Patch creates", "PATCH", "/notes/d", "alice", turtle("application/sparql-update"),
+		`INSERT DATA { <#it> <http://xmlns.com/foaf/0.1/name> "D" }`, http.StatusCreated, nil},
+	{"Post with a slug", "POST", "/notes/", "alice", map[string]string{"Content-Type": "text/plain", "Slug": "hello world.txt"}, `hello`,
+		http.StatusCreated, []string{"Location:/notes/hello-world.txt"}},
+	{"Non-RDF read back", "GET", "/notes/hello-world.txt", "alice", map[string]string{"Accept": "text/turtle"}, "", http.StatusOK,
+		[]string{"Content-Type:text/plain", "hello", `Link:<http://www.w3.org/ns/ldp#NonRDFSource>; rel="type"`}},
+	{"Post a container", "POST", "/notes/", "alice", map[string]string{"Slug": "sub", "Link": `<http://www.w3.org/ns/ldp#BasicContainer>; rel="type"`}, ``,
+		http.StatusCreated, []string{"Location:/notes/sub/"}},
+	{"Post to a resource", "POST", "/notes/a", "alice", turtle("text/plain"), `x`, http.StatusMethodNotAllowed, nil},
+	{"Containment can't be written", "PUT", "/notes/", "alice", turtle("text/turtle"), `<> <http://www.w3.org/ns/ldp#contains> <x> .`, http.StatusConflict, nil},
+	{"Container description", "PUT", "/notes/", "alice", turtle("text/turtle"), `<> <http://purl.org/dc/terms/title> "Notes" .`, http.StatusNoContent, nil},
+	{"Described container", "GET", "/notes/", "alice", nil, "", http.StatusOK, []string{`"Notes"`, "ldp:contains </notes/a>, </notes/b>, </notes/d>, </notes/hello-world.txt>, </notes/sub/>"}},
+	{"Container that isn't empty", "DELETE", "/notes/", "alice", nil, "", http.StatusConflict, nil},
+	{"Others can't read", "GET", "/notes/a", "bob", nil, "", http.StatusForbidden, nil},
+	{"Unknown token", "GET", "/notes/a", "carol", nil, "", http.StatusUnauthorized, nil},
+	{"Sharing", "PUT", "/notes/.acl", "alice", turtle("text/turtle"),
+		`@prefix acl: <http://www.w3.org/ns/auth/acl#> .
+		<#owner> a acl:Authorization ; acl:agent <https://alice.example/#me> ; acl:accessTo <./> ; acl:default <./> ; acl:mode acl:Read, acl:Write, acl:Control .
+		<#bob> a acl:Authorization ; acl:agent <https://bob.example/#me> ; acl:default <./> ; acl:mode acl:Read .
+		<#public> a acl:Authorization ; acl:agentClass <http://xmlns.com/foaf/0.1/Agent> ; acl:accessTo <./> ; acl:mode acl:Read .`,
+		http.StatusCreated, nil},
+	{"Shared", "GET", "/notes/a", "bob", nil, "", http.StatusOK, []string{`WAC-Allow:user="read",public=""`}},
+	{"Read only", "PUT", "/notes/a", "bob", turtle("text/turtle"), ``, http.StatusForbidden, nil},
+	{"ACLs need control", "GET", "/notes/.acl", "bob", nil, "", http.StatusForbidden, nil},
+	{"Public reads the container", "HEAD", "/notes/", "", nil, "", http.StatusOK, nil},
+	{"Public reads nothing in it", "GET", "/notes/a", "", nil, "", http.StatusUnauthorized, nil},
+	{"Delete", "DELETE", "/notes/a", "alice", nil, "", http.StatusNoContent, nil},
+	{"Deleted", "GET", "/notes/a", "alice", nil, "", http.StatusNotFound, nil},
+	{"Delete an empty container", "DELETE", "/notes/sub/", "alice", nil, "", http.StatusNoContent, nil},
+	{"Root can't be deleted", "DELETE", "/", "alice", nil, "", http.StatusMethodNotAllowed, nil},
+	{"Storage files aren't resources", "GET", "/notes/b$.ttl", "alice", nil, "", http.StatusBadRequest, nil},
+	{"Preflight", "OPTIONS", "/notes/", "", map[string]string{"Origin": "https://app.example", "Access-Control-Request-Headers": "authorization"}, "",
+		http.StatusNoContent, []string{"Access-Control-Allow-Origin:https://app.example", "Access-Control-Allow-Methods:OPTIONS, HEAD, GET, POST, PUT, PATCH, DELETE"}},
+}
+
+func TestRequests(t *testing.T) {
+	s := newPod(t)
+
+	for _, tc := range requestTests {
+		res, body := do(t, s, tc.Method, tc.Path, tc.Token, tc.Headers, tc.Body)
+
+		if res.StatusCode != tc.Status {
+			t.Errorf("%v test fail: expected %v, got %v: %v", tc.Name, tc.Status, res.StatusCode, body)
+			continue
+		}
+
+		for _, c := range tc.Contains {
+			found := strings.Contains(body, strings.ReplaceAll(c, "</", "<"+s.URL+"/"))
+
+			if name, value, ok := strings.Cut(c, ":"); ok && name[0] >= 'A' && name[0] <= 'Z' && !strings.Contains(name, " ") {
+				value = strings.ReplaceAll(value, "</", "<"+s.URL+"/")
+				if name == "Location" {
+					value = s.URL + value
+				}
+
+				found = false
+				for _, v := range res.Header.Values(name) {
+					found = found || strings.Contains(v, value)
+				}
+			}
+
+			if !found {
+				t.Errorf("%v test fail: no %v in %v %v", tc.Name, c, res.Header, body)
+			}
+		}
+	}
+}
+
+func TestConditional(t *testing.T) {
+	s := newPod(t)
+
+	do(t, s, "PUT", "/doc", "alice", turtle("text/turtle"), `<#a> <#b> <#c> .`)
+
+	res, _ := do(t, s, "GET", "/doc", "alice", nil, "")
+	tag := res.Header.Get("ETag")
+
+	if tag == "" {
+		t.Fatalf("no ETag")
+	}
+
+	if res, _ := do(t, s, "GET", "/doc", "alice", map[string]string{"If-None-Match": tag}, ""); res.StatusCode != http.StatusNotModified {
+		t.Errorf("unchanged resource got %v", res.Status)
+	}
+
+	// The ETag is of the resource, not the format it is read in
+	if res, _ := do(t, s, "GET", "/doc", "alice", map[string]string{"Accept": "application/ld+json"}, ""); res.Header.Get("ETag") != tag {
+		t.Errorf("JSON-LD has ETag %v", res.Header.Get("ETag"))
+	}
+
+	if res, _ := do(t, s, "GET", "/doc", "alice", map[string]string{"If-None-Match": "W/" + tag}, ""); res.StatusCode != http.StatusNotModified {
+		t.Errorf("unchanged resource with a weak tag got %v", res.Status)
+	}
+
+	// If-Match compares strongly, a weak tag never matches
+	if res, _ := do(t, s, "PUT", "/doc", "alice", map[string]string{"Content-Type": "text/turtle", "If-Match": "W/" + tag}, `<#a> <#b> <#d> .`); res.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("weak put got %v", res.Status)
+	}
+
+	if res, _ := do(t, s, "PUT", "/doc", "alice", map[string]string{"Content-Type": "text/turtle", "If-Match": tag}, `<#a> <#b> <#d> .`); res.StatusCode != http.StatusNoContent || res.Header.Get("ETag") == tag {
+		t.Errorf("matching put got %v, %v", res.Status, res.Header.Get("ETag"))
+	}
+
+	// The tag is stale now
+	if res, _ := do(t, s, "PUT", "/doc", "alice", map[string]string{"Content-Type": "text/turtle", "If-Match": tag}, `<#a> <#b> <#e> .`); res.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("stale put got %v", res.Status)
+	}
+
+	if res, _ := do(t, s, "DELETE", "/doc", "alice", map[string]string{"If-Match": tag}, ""); res.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("stale delete got %v", res.Status)
+	}
+
+	// A container's ETag changes with what it contains
+	res, _ = do(t, s, "GET", "/", "alice", nil, "")
+	do(t, s, "PUT", "/other", "alice", turtle("text/plain"), "x")
+
+	if again, _ := do(t, s, "GET", "/", "alice", nil, ""); again.Header.Get("ETag") == res.Header.Get("ETag") {
+		t.Errorf("container ETag didn't change")
+	}
+}
+
+// authorized adds a bearer token to requests
+type authorized string
+
+func (a authorized) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("Authorization", "Bearer "+string(a))
+
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+// TestClient works with the pod through the client packages
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	s := newPod(t)
+	c := solid.MustNew(solid.WithHTTPClient(&http.Client{Transport: authorized("alice")}))
+
+	me := rdf.IRI(s.URL + "/profile/card#me")
+	g := rdf.NewGraph(rdf.NewTriple(me, foaf.Name, rdf.NewLiteral("Alice")))
+
+	if _, err := c.Put(ctx, s.URL+"/profile/card", g); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := c.Get(ctx, s.URL+"/profile/card")
+	if err != nil || !rdf.Isomorphic(res.Graph, g) {
+		t.Fatalf("got %v, %v", res, err)
+	}
+
+	if _, err := c.Patch(ctx, s.URL+"/profile/card", &n3patch.Patch{Inserts: rdf.Formula{rdf.NewTriple(me, foaf.Nick, rdf.NewLiteral("al"))}}); err != nil {
+		t.Fatal(err)
+	}
+
+	container, err := c.Container(ctx, s.URL+"/profile/")
+	if err != nil || !reflect.DeepEqual(container.Members, []string{s.URL + "/profile/card"}) {
+		t.Fatalf("container %v, %v", container, err)
+	}
+
+	// Sharing through the ACL the resource links to
+	a, err := wac.Fetch(ctx, c, s.URL+"/profile/card")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a.Grant("owner", alice, acl.Read, acl.Write, acl.Control)
+	a.Public("public", acl.Read)
+
+	if err := wac.Save(ctx, c, a); err != nil {
+		t.Fatal(err)
+	}
+
+	public := solid.MustNew()
+
+	res, err = public.Get(ctx, s.URL+"/profile/card")
+	if err != nil || res.Graph.Len() != 2 {
+		t.Fatalf("public got %v, %v", res, err)
+	}
+
+	if !res.Allow.Allows("public", "read") || res.Allow.Allows("public", "write") {
+		t.Errorf("WAC-Allow %v", res.Allow)
+	}
+
+	if err := public.Delete(ctx, s.URL+"/profile/card"); err == nil {
+		t.Errorf("public deleted the profile")
+	}
+
+	if err := c.Delete(ctx, s.URL+"/profile/card"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The ACL went with it
+	if res, _ := do(t, s, "GET", "/profile/card.acl", "alice", nil, ""); res.StatusCode != http.StatusNotFound {
+		t.Errorf("ACL left behind: %v", res.Status)
+	}
+}
+
+func TestTokens(t *testing.T) {
+	s := newPod(t)
+
+	// Behind a proxy proofs are made for the pod's public URL
+	proxied := httptest.NewServer(MustNew(t.TempDir(), WithBaseURL("https://pod.example"), WithOwner(alice), WithAuthenticator(Tokens(tokens))))
+	t.Cleanup(proxied.Close)
+
+	key, err := oidc.NewKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		Name   string
+		Server *httptest.Server
+		Method string
+		Target string
+		Status int
+	}{
+		{"Proof for the request", s, "GET", s.URL + "/", http.StatusOK},
+		{"Proof for another method", s, "DELETE", s.URL + "/", http.StatusUnauthorized},
+		{"Proof for the base URL", proxied, "GET", "https://pod.example/", http.StatusOK},
+		{"Proof for the address behind the proxy", proxied, "GET", proxied.URL + "/", http.StatusUnauthorized},
+	} {
+		proof, err := key.Proof(tc.Method, tc.Target, "alice", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		res, _ := do(t, tc.Server, "GET", "/", "", map[string]string{"Authorization": "DPoP alice", "DPoP": proof}, "")
+		if res.StatusCode != tc.Status {
+			t.Errorf("%v test fail: got %v", tc.Name, res.Status)
+		}
+	}
+}
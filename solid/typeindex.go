@@ -0,0 +1,256 @@
+package solid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/n3patch"
+	vocab "github.com/b1scuit/solid/rdf/vocab/solid"
+)
+
+// A TypeIndex says where an agent keeps resources of each class, so apps
+// can find and share data without agreeing on paths. The public index is
+// a listed document anyone can read, the private one is unlisted and
+// usually only linked from the preferences file
+//
+// https://solid.github.io/type-indexes/
+type TypeIndex struct {
+	URL           string
+	ETag          string
+	Public        bool
+	Registrations []*TypeRegistration
+}
+
+// A TypeRegistration maps classes to the resources holding instances of
+// them, either listed one by one or as containers that hold them all
+type TypeRegistration struct {
+	ID                 string
+	ForClass           []rdf.IRI
+	Instances          []string
+	InstanceContainers []string
+}
+
+// Locations returns where instances of the registered classes are, the
+// instances then the containers
+func (r *TypeRegistration) Locations() []string {
+	return append(append([]string{}, r.Instances...), r.InstanceContainers...)
+}
+
+func (r *TypeRegistration) isFor(class rdf.IRI) bool {
+	for _, c := range r.ForClass {
+		if c == class {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TypeIndexFromGraph reads the registrations in a type index document.
+// Anything described with a solid:forClass is taken as a registration,
+// as not every app types them solid:TypeRegistration
+func TypeIndexFromGraph(g *rdf.Graph, url string) *TypeIndex {
+	ti := &TypeIndex{
+		URL:    url,
+		Public: g.Has(rdf.NewTriple(rdf.IRI(url), rdf.RDFType, vocab.ListedDocument)),
+	}
+
+	seen := map[rdf.Term]bool{}
+
+	for _, t := range g.Match(nil, vocab.ForClass, nil) {
+		if seen[t.Subject] {
+			continue
+		}
+
+		seen[t.Subject] = true
+
+		r := &TypeRegistration{
+			Instances:          iris(g, t.Subject, vocab.Instance),
+			InstanceContainers: iris(g, t.Subject, vocab.InstanceContainer),
+		}
+
+		if iri, ok := t.Subject.(rdf.IRI); ok {
+			r.ID = string(iri)
+		}
+
+		for _, class := range iris(g, t.Subject, vocab.ForClass) {
+			r.ForClass = append(r.ForClass, rdf.IRI(class))
+		}
+
+		ti.Registrations = append(ti.Registrations, r)
+	}
+
+	return ti
+}
+
+// Find returns the registrations for a class
+func (ti *TypeIndex) Find(class rdf.IRI) []*TypeRegistration {
+	var found []*TypeRegistration
+	for _, r := range ti.Registrations {
+		if r.isFor(class) {
+			found = append(found, r)
+		}
+	}
+
+	return found
+}
+
+// FindType returns the registrations for a class across type indexes, in
+// the order of the indexes
+func FindType(class rdf.IRI, indexes ...*TypeIndex) []*TypeRegistration {
+	var found []*TypeRegistration
+	for _, ti := range indexes {
+		found = append(found, ti.Find(class)...)
+	}
+
+	return found
+}
+
+// FetchTypeIndex reads a type index document
+func (c *Client) FetchTypeIndex(ctx context.Context, url string) (*TypeIndex, error) {
+	res, err := c.Get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Graph == nil {
+		return nil, fmt.Errorf("solid: %v isn't RDF", res.URL)
+	}
+
+	ti := TypeIndexFromGraph(res.Graph, url)
+	ti.ETag = res.ETag
+
+	return ti, nil
+}
+
+// TypeIndexes reads the public and private type indexes a profile links
+// to. Those the client isn't allowed to read, or that don't exist yet,
+// are left out, so an anonymous client usually only gets the public one
+func (c *Client) TypeIndexes(ctx context.Context, p *Profile) ([]*TypeIndex, error) {
+	var indexes []*TypeIndex
+
+	for _, u := range []string{p.PublicTypeIndex, p.PrivateTypeIndex} {
+		if u == "" {
+			continue
+		}
+
+		ti, err := c.FetchTypeIndex(ctx, u)
+
+		var e *Error
+		if errors.As(err, &e) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		ti.Public = u == p.PublicTypeIndex
+		indexes = append(indexes, ti)
+	}
+
+	return indexes, nil
+}
+
+// Register records in a type index that instances of a class are at a
+// location, as a solid:instanceContainer if the location is a container
+// and a solid:instance otherwise. The index is patched rather than
+// rewritten, and when the server gave it an ETag only if it hasn't
+// changed since it was read, so other apps' registrations are kept. A
+// location already registered for the class isn't added again. An index
+// that doesn't exist yet is created as a solid:TypeIndex, a listed
+// document if public and an unlisted one if not
+func (c *Client) Register(ctx context.Context, index string, public bool, class rdf.IRI, location string) (*TypeRegistration, error) {
+	ti, err := c.FetchTypeIndex(ctx, index)
+	exists := err == nil
+
+	var e *Error
+	if errors.As(err, &e) && e.StatusCode == http.StatusNotFound {
+		ti, err = &TypeIndex{URL: index, Public: public}, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range ti.Find(class) {
+		for _, l := range r.Locations() {
+			if l == location {
+				return r, nil
+			}
+		}
+	}
+
+	r := &TypeRegistration{ID: ti.registrationID(class), ForClass: []rdf.IRI{class}}
+	id := rdf.IRI(r.ID)
+
+	predicate := vocab.Instance
+	if IsContainerURL(location) {
+		predicate = vocab.InstanceContainer
+		r.InstanceContainers = []string{location}
+	} else {
+		r.Instances = []string{location}
+	}
+
+	patch := &n3patch.Patch{Inserts: rdf.Formula{
+		rdf.NewTriple(id, rdf.RDFType, vocab.TypeRegistration),
+		rdf.NewTriple(id, vocab.ForClass, class),
+		rdf.NewTriple(id, predicate, rdf.IRI(location)),
+	}}
+
+	// Without an ETag an existing index is patched unconditionally
+	var opts []RequestOption
+
+	switch {
+	case !exists:
+		opts = append(opts, IfNoneMatch("*"))
+		listing := vocab.UnlistedDocument
+		if public {
+			listing = vocab.ListedDocument
+		}
+
+		patch.Inserts = append(patch.Inserts,
+			rdf.NewTriple(rdf.IRI(index), rdf.RDFType, vocab.TypeIndex),
+			rdf.NewTriple(rdf.IRI(index), rdf.RDFType, listing),
+		)
+	case ti.ETag != "":
+		opts = append(opts, IfMatch(ti.ETag))
+	}
+
+	if _, err := c.Patch(ctx, index, patch, opts...); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// registrationID names a new registration in the index after the class,
+// numbered if the name is taken
+func (ti *TypeIndex) registrationID(class rdf.IRI) string {
+	doc, _, _ := strings.Cut(ti.URL, "#")
+
+	name := string(class)
+	if i := strings.LastIndexAny(name, "#/"); i >= 0 {
+		name = name[i+1:]
+	}
+
+	if name == "" {
+		name = "registration"
+	}
+
+	taken := map[string]bool{}
+	for _, r := range ti.Registrations {
+		taken[r.ID] = true
+	}
+
+	id := doc + "#" + name
+	for n := 2; taken[id]; n++ {
+		id = fmt.Sprintf("%v#%v%v", doc, name, n)
+	}
+
+	return id
+}
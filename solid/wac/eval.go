@@ -0,0 +1,241 @@
+package wac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/vocab/acl"
+	"github.com/b1scuit/solid/rdf/vocab/foaf"
+	"github.com/b1scuit/solid/rdf/vocab/vcard"
+	"github.com/b1scuit/solid/solid"
+)
+
+var ErrNoACL = errors.New("wac: no ACL applies")
+
+// A Request is who is asking for access
+type Request struct {
+	// Agent is the WebID of the agent, empty when they haven't signed in
+	Agent string
+
+	// Origin is the request's Origin header, empty when it had none
+	Origin string
+
+	// Groups reads the members of groups named by acl:agentGroup, without
+	// it groups match no one
+	Groups GroupLoader
+}
+
+// A GroupLoader reads the WebIDs of a group's members
+type GroupLoader func(ctx context.Context, group string) ([]string, error)
+
+// Modes returns the modes the request is granted on the resource, sorted.
+// On the ACL's own resource the authorizations giving access to it count,
+// on anything under it that has no ACL of its own the ones with it as a
+// default. Write grants Append as well. Authorizations naming origins
+// only count for requests from one of them
+func (a *ACL) Modes(ctx context.Context, resource string, req Request) ([]rdf.IRI, error) {
+	var modes []rdf.IRI
+
+	// Only what is in the ACL's container inherits from it
+	inherited := resource != a.Resource
+	if inherited && (!isContainer(a.Resource) || !strings.HasPrefix(resource, a.Resource)) {
+		return nil, nil
+	}
+
+	for _, auth := range a.Authorizations {
+		targets := auth.AccessTo
+		if inherited {
+			targets = auth.Default
+		}
+
+		if !hasIRI(targets, rdf.IRI(a.Resource)) {
+			continue
+		}
+
+		if len(auth.Origins) > 0 && !hasIRI(auth.Origins, rdf.IRI(req.Origin)) {
+			continue
+		}
+
+		ok, err := auth.matches(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			continue
+		}
+
+		for _, m := range auth.Modes {
+			modes = addIRI(modes, m)
+
+			if m == acl.Write {
+				modes = addIRI(modes, acl.Append)
+			}
+		}
+	}
+
+	return modes, nil
+}
+
+// Allows checks the request has the mode on the resource
+func (a *ACL) Allows(ctx context.Context, resource string, req Request, mode rdf.IRI) (bool, error) {
+	modes, err := a.Modes(ctx, resource, req)
+	if err != nil {
+		return false, err
+	}
+
+	return hasIRI(modes, mode), nil
+}
+
+// matches checks the authorization covers the agent
+func (auth *Authorization) matches(ctx context.Context, req Request) (bool, error) {
+	for _, c := range auth.AgentClasses {
+		if c == foaf.Agent || (c == acl.AuthenticatedAgent && req.Agent != "") {
+			return true, nil
+		}
+	}
+
+	if req.Agent == "" {
+		return false, nil
+	}
+
+	if hasIRI(auth.Agents, rdf.IRI(req.Agent)) {
+		return true, nil
+	}
+
+	if req.Groups == nil {
+		return false, nil
+	}
+
+	for _, g := range auth.AgentGroups {
+		members, err := req.Groups(ctx, string(g))
+		if err != nil {
+			return false, err
+		}
+
+		for _, m := range members {
+			if m == req.Agent {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// A Loader reads the ACL a resource has of its own, or nil if it has none
+type Loader func(ctx context.Context, resource string) (*ACL, error)
+
+// Effective finds the ACL governing a resource, its own or else that of
+// the nearest container above it
+func Effective(ctx context.Context, resource string, load Loader) (*ACL, error) {
+	for r := resource; ; {
+		a, err := load(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+
+		if a != nil {
+			return a, nil
+		}
+
+		parent, ok := Parent(r)
+		if !ok {
+			return nil, fmt.Errorf("%w: %v", ErrNoACL, resource)
+		}
+
+		r = parent
+	}
+}
+
+// Parent returns the container a resource is in, or false for the root
+func Parent(resource string) (string, bool) {
+	return solid.ParentURL(resource)
+}
+
+// Fetch reads a resource's own ACL from the document its Link header
+// names. When the document doesn't exist yet the ACL is empty, ready to
+// be filled in and saved
+func Fetch(ctx context.Context, c *solid.Client, resource string) (*ACL, error) {
+	a, _, err := fetch(ctx, c, resource)
+	return a, err
+}
+
+func fetch(ctx context.Context, c *solid.Client, resource string) (*ACL, bool, error) {
+	link, doc, err := c.AccessControl(ctx, resource)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if doc == nil {
+		return &ACL{URL: link, Resource: resource}, false, nil
+	}
+
+	return FromGraph(doc.Graph, link, resource), true, nil
+}
+
+// ClientLoader loads ACLs from a pod. A resource that doesn't exist has
+// no ACL of its own, so what can be done to it comes from its container
+func ClientLoader(c *solid.Client) Loader {
+	return func(ctx context.Context, resource string) (*ACL, error) {
+		a, exists, err := fetch(ctx, c, resource)
+
+		var e *solid.Error
+		if errors.As(err, &e) && e.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+
+		if err != nil || !exists {
+			return nil, err
+		}
+
+		return a, nil
+	}
+}
+
+// ClientGroups reads groups from their documents' vcard:hasMember
+func ClientGroups(c *solid.Client) GroupLoader {
+	return func(ctx context.Context, group string) ([]string, error) {
+		res, err := c.Get(ctx, group)
+		if err != nil {
+			return nil, err
+		}
+
+		if res.Graph == nil {
+			return nil, fmt.Errorf("wac: group %v isn't RDF", group)
+		}
+
+		var members []string
+		for _, t := range res.Graph.Match(rdf.IRI(group), vcard.HasMember, nil) {
+			if iri, ok := t.Object.(rdf.IRI); ok {
+				members = append(members, string(iri))
+			}
+		}
+
+		return members, nil
+	}
+}
+
+// Save writes the ACL to its document
+func Save(ctx context.Context, c *solid.Client, a *ACL, opts ...solid.RequestOption) error {
+	_, err := c.Put(ctx, a.URL, a.Graph(), opts...)
+	return err
+}
+
+func hasIRI(iris []rdf.IRI, iri rdf.IRI) bool {
+	for _, v := range iris {
+		if v == iri {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isContainer(resource string) bool {
+	return strings.HasSuffix(resource, "/")
+}
@@ -0,0 +1,208 @@
+// Package wac reads, evaluates and edits Web Access Control lists, the
+// .acl documents saying who can do what to the resources of a Solid pod
+//
+// https://solidproject.org/TR/wac
+package wac
+
+import (
+	"io"
+	"sort"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/parser"
+	"github.com/b1scuit/solid/rdf/serializer"
+	"github.com/b1scuit/solid/rdf/vocab/acl"
+	"github.com/b1scuit/solid/rdf/vocab/foaf"
+)
+
+// An Authorization grants modes to agents, on the resources it gives
+// access to and, through Default, on everything in the containers it
+// names that has no ACL of its own
+type Authorization struct {
+	ID rdf.Term
+
+	Agents       []rdf.IRI
+	AgentClasses []rdf.IRI
+	AgentGroups  []rdf.IRI
+	Origins      []rdf.IRI
+
+	AccessTo []rdf.IRI
+	Default  []rdf.IRI
+
+	Modes []rdf.IRI
+}
+
+// An ACL is the access control document at URL for Resource
+type ACL struct {
+	URL      string
+	Resource string
+
+	Authorizations []*Authorization
+}
+
+// Parse reads an ACL document, relative IRIs resolved against its URL
+func Parse(r io.Reader, url, resource string) (*ACL, error) {
+	p, err := parser.New(parser.WithBase(url))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.Do(r); err != nil {
+		return nil, err
+	}
+
+	return FromGraph(p.GetGraph(), url, resource), nil
+}
+
+// FromGraph reads the authorizations in an ACL document's graph. Triples
+// that aren't about an acl:Authorization are ignored
+func FromGraph(g *rdf.Graph, url, resource string) *ACL {
+	a := &ACL{URL: url, Resource: resource}
+
+	for _, t := range g.Match(nil, rdf.RDFType, acl.Authorization) {
+		auth := &Authorization{ID: t.Subject}
+
+		for _, f := range []struct {
+			predicate rdf.IRI
+			values    *[]rdf.IRI
+		}{
+			{acl.Agent, &auth.Agents},
+			{acl.AgentClass, &auth.AgentClasses},
+			{acl.AgentGroup, &auth.AgentGroups},
+			{acl.OriginProperty, &auth.Origins},
+			{acl.AccessTo, &auth.AccessTo},
+			{acl.Default, &auth.Default},
+			{acl.Mode, &auth.Modes},
+		} {
+			for _, v := range g.Match(t.Subject, f.predicate, nil) {
+				if iri, ok := v.Object.(rdf.IRI); ok {
+					*f.values = append(*f.values, iri)
+				}
+			}
+		}
+
+		a.Authorizations = append(a.Authorizations, auth)
+	}
+
+	return a
+}
+
+// Graph writes the ACL as RDF
+func (a *ACL) Graph() *rdf.Graph {
+	g := rdf.NewGraph()
+
+	for _, auth := range a.Authorizations {
+		g.Add(rdf.NewTriple(auth.ID, rdf.RDFType, acl.Authorization))
+
+		for _, f := range []struct {
+			predicate rdf.IRI
+			values    []rdf.IRI
+		}{
+			{acl.Agent, auth.Agents},
+			{acl.AgentClass, auth.AgentClasses},
+			{acl.AgentGroup, auth.AgentGroups},
+			{acl.OriginProperty, auth.Origins},
+			{acl.AccessTo, auth.AccessTo},
+			{acl.Default, auth.Default},
+			{acl.Mode, auth.Modes},
+		} {
+			for _, v := range f.values {
+				g.Add(rdf.NewTriple(auth.ID, f.predicate, v))
+			}
+		}
+	}
+
+	return g
+}
+
+// Turtle writes the ACL as a Turtle document
+func (a *ACL) Turtle(w io.Writer) error {
+	prefixes := rdf.PrefixMap{"acl": acl.Namespace, "foaf": foaf.Namespace}
+
+	return serializer.MustNew(serializer.WithPrefixes(prefixes)).Turtle(w, a.Graph())
+}
+
+// Authorization returns the authorization with the fragment name in the
+// document, adding an empty one if there isn't one
+func (a *ACL) Authorization(name string) *Authorization {
+	id := rdf.IRI(a.URL + "#" + name)
+
+	for _, auth := range a.Authorizations {
+		if rdf.Equal(auth.ID, id) {
+			return auth
+		}
+	}
+
+	auth := &Authorization{ID: id}
+	a.Authorizations = append(a.Authorizations, auth)
+
+	return auth
+}
+
+// Remove drops the authorization with the ID, reporting whether it was
+// there
+func (a *ACL) Remove(id rdf.Term) bool {
+	for i, auth := range a.Authorizations {
+		if rdf.Equal(auth.ID, id) {
+			a.Authorizations = append(a.Authorizations[:i], a.Authorizations[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// Owner makes an ACL giving the agent every mode on the resource and,
+// when it is a container, on what it contains
+func Owner(url, resource, webID string) *ACL {
+	a := &ACL{URL: url, Resource: resource}
+	a.Grant("owner", webID, acl.Read, acl.Write, acl.Control)
+
+	return a
+}
+
+// Grant adds the modes to the named authorization for the agent, on the
+// resource and what a container contains
+func (a *ACL) Grant(name, webID string, modes ...rdf.IRI) *Authorization {
+	auth := a.grant(name, modes)
+	auth.Agents = addIRI(auth.Agents, rdf.IRI(webID))
+
+	return auth
+}
+
+// Public adds the modes to the named authorization for anyone, signed in
+// or not
+func (a *ACL) Public(name string, modes ...rdf.IRI) *Authorization {
+	auth := a.grant(name, modes)
+	auth.AgentClasses = addIRI(auth.AgentClasses, foaf.Agent)
+
+	return auth
+}
+
+func (a *ACL) grant(name string, modes []rdf.IRI) *Authorization {
+	auth := a.Authorization(name)
+	auth.AccessTo = addIRI(auth.AccessTo, rdf.IRI(a.Resource))
+
+	if isContainer(a.Resource) {
+		auth.Default = addIRI(auth.Default, rdf.IRI(a.Resource))
+	}
+
+	for _, m := range modes {
+		auth.Modes = addIRI(auth.Modes, m)
+	}
+
+	return auth
+}
+
+func addIRI(iris []rdf.IRI, iri rdf.IRI) []rdf.IRI {
+	for _, v := range iris {
+		if v == iri {
+			return iris
+		}
+	}
+
+	iris = append(iris, iri)
+	sort.Slice(iris, func(i, j int) bool { return iris[i] < iris[j] })
+
+	return iris
+}
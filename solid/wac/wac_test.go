@@ -0,0 +1,293 @@
+package wac
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/vocab/acl"
+	"github.com/b1scuit/solid/solid"
+)
+
+const testACL = `
+@prefix acl: <http://www.w3.org/ns/auth/acl#> .
+@prefix foaf: <http://xmlns.com/foaf/0.1/> .
+
+<#owner> a acl:Authorization ;
+	acl:agent <https://alice.example/#me> ;
+	acl:accessTo <./> ;
+	acl:default <./> ;
+	acl:mode acl:Read, acl:Write, acl:Control .
+
+<#public> a acl:Authorization ;
+	acl:agentClass foaf:Agent ;
+	acl:default <./> ;
+	acl:mode acl:Read .
+
+<#signedIn> a acl:Authorization ;
+	acl:agentClass acl:AuthenticatedAgent ;
+	acl:accessTo <./> ;
+	acl:mode acl:Append .
+
+<#friends> a acl:Authorization ;
+	acl:agentGroup <https://alice.example/groups#friends> ;
+	acl:origin <https://app.example> ;
+	acl:default <./> ;
+	acl:mode acl:Write .
+
+<#unrelated> acl:agent <https://carol.example/#me> ; acl:mode acl:Control .
+`
+
+const (
+	alice = "https://alice.example/#me"
+	bob   = "https://bob.example/#me"
+)
+
+func testGroups(ctx context.Context, group string) ([]string, error) {
+	if group == "https://alice.example/groups#friends" {
+		return []string{bob}, nil
+	}
+
+	return nil, fmt.Errorf("no group %v", group)
+}
+
+type modesTest struct {
+	Name     string
+	Resource string
+	Request  Request
+	Expected []rdf.IRI
+}
+
+var modesTests = []modesTest{
+	{"Owner of the container", "https://pod.example/c/", Request{Agent: alice},
+		[]rdf.IRI{acl.Append, acl.Control, acl.Read, acl.Write}},
+	{"Owner inside the container", "https://pod.example/c/d/doc", Request{Agent: alice},
+		[]rdf.IRI{acl.Append, acl.Control, acl.Read, acl.Write}},
+	{"Anyone on the container", "https://pod.example/c/", Request{},
+		nil},
+	{"Anyone inside the container", "https://pod.example/c/doc", Request{},
+		[]rdf.IRI{acl.Read}},
+	{"Signed in on the container", "https://pod.example/c/", Request{Agent: bob},
+		[]rdf.IRI{acl.Append}},
+	{"Group member from the wrong origin", "https://pod.example/c/doc", Request{Agent: bob, Origin: "https://evil.example", Groups: testGroups},
+		[]rdf.IRI{acl.Read}},
+	{"Group member from the right origin", "https://pod.example/c/doc", Request{Agent: bob, Origin: "https://app.example", Groups: testGroups},
+		[]rdf.IRI{acl.Append, acl.Read, acl.Write}},
+	{"Groups not loaded", "https://pod.example/c/doc", Request{Agent: bob, Origin: "https://app.example"},
+		[]rdf.IRI{acl.Read}},
+	{"Outside the container", "https://pod.example/other", Request{Agent: alice},
+		nil},
+	{"Sibling with the same prefix", "https://pod.example/c-other/doc", Request{Agent: alice},
+		nil},
+}
+
+func testACLFor(t *testing.T) *ACL {
+	a, err := Parse(strings.NewReader(testACL), "https://pod.example/c/.acl", "https://pod.example/c/")
+	if err != nil {
+		t.Fatalf("parsing test ACL: %v", err)
+	}
+
+	return a
+}
+
+func TestModes(t *testing.T) {
+	a := testACLFor(t)
+
+	if len(a.Authorizations) != 4 {
+		t.Fatalf("parse test fail: %v authorizations", len(a.Authorizations))
+	}
+
+	for _, tc := range modesTests {
+		modes, err := a.Modes(context.Background(), tc.Resource, tc.Request)
+		if err != nil || !reflect.DeepEqual(modes, tc.Expected) {
+			t.Errorf("%v test fail: got %v, %v", tc.Name, modes, err)
+		}
+	}
+
+	// Groups that can't be read are an error, not a refusal
+	req := Request{Agent: bob, Origin: "https://app.example", Groups: func(context.Context, string) ([]string, error) {
+		return nil, errors.New("unreachable")
+	}}
+
+	if _, err := a.Allows(context.Background(), "https://pod.example/c/doc", req, acl.Write); err == nil {
+		t.Errorf("group error test fail: no error")
+	}
+}
+
+type parentTest struct {
+	Name     string
+	Resource string
+	Parent   string
+}
+
+var parentTests = []parentTest{
+	{"Document", "https://pod.example/a/b/doc", "https://pod.example/a/b/"},
+	{"Container", "https://pod.example/a/b/", "https://pod.example/a/"},
+	{"Top level", "https://pod.example/doc", "https://pod.example/"},
+	{"Root", "https://pod.example/", ""},
+	{"Query dropped", "https://pod.example/a/doc?x=1", "https://pod.example/a/"},
+}
+
+func TestEffective(t *testing.T) {
+	for _, tc := range parentTests {
+		if parent, _ := Parent(tc.Resource); parent != tc.Parent {
+			t.Errorf("%v test fail: got %v", tc.Name, parent)
+		}
+	}
+
+	root := Owner("https://pod.example/.acl", "https://pod.example/", alice)
+	c := testACLFor(t)
+
+	acls := map[string]*ACL{root.Resource: root, c.Resource: c}
+
+	var loaded []string
+
+	load := func(ctx context.Context, resource string) (*ACL, error) {
+		loaded = append(loaded, resource)
+		return acls[resource], nil
+	}
+
+	a, err := Effective(context.Background(), "https://pod.example/c/d/doc", load)
+	if a != c || err != nil || !reflect.DeepEqual(loaded, []string{"https://pod.example/c/d/doc", "https://pod.example/c/d/", "https://pod.example/c/"}) {
+		t.Errorf("effective test fail: got %v from %v, %v", a, loaded, err)
+	}
+
+	if a, _ := Effective(context.Background(), "https://pod.example/x/doc", load); a != root {
+		t.Errorf("effective test fail: got %v", a)
+	}
+
+	delete(acls, root.Resource)
+
+	if _, err := Effective(context.Background(), "https://pod.example/x/doc", load); !errors.Is(err, ErrNoACL) {
+		t.Errorf("effective test fail: got %v", err)
+	}
+}
+
+func TestEdit(t *testing.T) {
+	ctx := context.Background()
+
+	a := Owner("https://pod.example/c/.acl", "https://pod.example/c/", alice)
+	a.Public("public", acl.Read)
+	a.Grant("bob", bob, acl.Read, acl.Append)
+	a.Grant("bob", bob, acl.Append)
+
+	if ok, _ := a.Allows(ctx, "https://pod.example/c/doc", Request{Agent: bob}, acl.Append); !ok {
+		t.Errorf("edit test fail: grant not applied")
+	}
+
+	var buf bytes.Buffer
+	if err := a.Turtle(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), "@prefix acl:") {
+		t.Errorf("edit test fail: wrote %v", buf.String())
+	}
+
+	// Written out and read back it is the same ACL
+	b, err := Parse(&buf, a.URL, a.Resource)
+	if err != nil || !rdf.Isomorphic(a.Graph(), b.Graph()) {
+		t.Errorf("edit test fail: read back %v, %v", b, err)
+	}
+
+	if !b.Remove(rdf.IRI(a.URL+"#bob")) || b.Remove(rdf.IRI(a.URL+"#bob")) {
+		t.Errorf("remove test fail")
+	}
+
+	if ok, _ := b.Allows(ctx, "https://pod.example/c/doc", Request{Agent: bob}, acl.Append); ok {
+		t.Errorf("remove test fail: grant still applied")
+	}
+}
+
+// pod serves resources linking to their ACLs, storing ACLs that are put
+func pod(t *testing.T) *httptest.Server {
+	var mu sync.Mutex
+
+	docs := map[string]string{
+		"/":           "",
+		"/c/":         "",
+		"/c/doc":      "",
+		"/groups":     `<#friends> <http://www.w3.org/2006/vcard/ns#hasMember> <https://bob.example/#me> .`,
+		"/.acl":       `<#owner> a <http://www.w3.org/ns/auth/acl#Authorization> ; <http://www.w3.org/ns/auth/acl#agent> <https://alice.example/#me> ; <http://www.w3.org/ns/auth/acl#default> </> ; <http://www.w3.org/ns/auth/acl#mode> <http://www.w3.org/ns/auth/acl#Read> .`,
+		"/groups.acl": "",
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.Method {
+		case http.MethodPut:
+			b, _ := io.ReadAll(r.Body)
+			docs[r.URL.Path] = string(b)
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodGet, http.MethodHead:
+			doc, ok := docs[r.URL.Path]
+			if !ok || (strings.HasSuffix(r.URL.Path, ".acl") && doc == "") {
+				http.NotFound(w, r)
+				return
+			}
+
+			if !strings.HasSuffix(r.URL.Path, ".acl") {
+				w.Header().Set("Link", fmt.Sprintf(`<%v.acl>; rel="acl"`, r.URL.Path))
+			}
+
+			w.Header().Set("Content-Type", "text/turtle")
+			io.WriteString(w, doc)
+		}
+	}))
+}
+
+func TestClient(t *testing.T) {
+	s := pod(t)
+	defer s.Close()
+
+	ctx := context.Background()
+	c := solid.MustNew()
+
+	doc := s.URL + "/c/doc"
+
+	// Nothing below the root has an ACL yet
+	a, err := Effective(ctx, doc, ClientLoader(c))
+	if err != nil || a.URL != s.URL+"/.acl" || a.Resource != s.URL+"/" {
+		t.Fatalf("client test fail: effective %v, %v", a, err)
+	}
+
+	if ok, _ := a.Allows(ctx, doc, Request{Agent: alice}, acl.Read); !ok {
+		t.Errorf("client test fail: root ACL not inherited")
+	}
+
+	own, err := Fetch(ctx, c, s.URL+"/c/")
+	if err != nil || own.URL != s.URL+"/c/.acl" || len(own.Authorizations) != 0 {
+		t.Fatalf("client test fail: fetched %v, %v", own, err)
+	}
+
+	own.Grant("friends", alice, acl.Read).AgentGroups = []rdf.IRI{rdf.IRI(s.URL + "/groups#friends")}
+
+	if err := Save(ctx, c, own); err != nil {
+		t.Fatalf("client test fail: %v", err)
+	}
+
+	a, err = Effective(ctx, doc, ClientLoader(c))
+	if err != nil || a.URL != s.URL+"/c/.acl" {
+		t.Fatalf("client test fail: effective after saving %v, %v", a, err)
+	}
+
+	if ok, err := a.Allows(ctx, doc, Request{Agent: bob, Groups: ClientGroups(c)}, acl.Read); !ok || err != nil {
+		t.Errorf("client test fail: group member refused, %v", err)
+	}
+
+	// A resource that doesn't exist yet gets its container's ACL
+	if a, err := Effective(ctx, s.URL+"/c/new", ClientLoader(c)); err != nil || a.URL != s.URL+"/c/.acl" {
+		t.Errorf("client test fail: new resource got %v, %v", a, err)
+	}
+}
@@ -0,0 +1,158 @@
+package solid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/b1scuit/solid/rdf"
+	"github.com/b1scuit/solid/rdf/vocab/foaf"
+	"github.com/b1scuit/solid/rdf/vocab/ldp"
+	"github.com/b1scuit/solid/rdf/vocab/rdfs"
+	vocab "github.com/b1scuit/solid/rdf/vocab/solid"
+	"github.com/b1scuit/solid/rdf/vocab/space"
+	"github.com/b1scuit/solid/rdf/vocab/vcard"
+)
+
+var ErrNotWebID = errors.New("solid: profile says nothing about the WebID")
+
+// How many extended profile documents are read, so a profile can't keep a
+// client fetching forever
+const maxProfileDocuments = 16
+
+// A Profile is what a WebID's profile documents say about its agent.
+// Graph has the profile document along with the extended profile and
+// preferences file merged in
+//
+// https://solid.github.io/webid-profile/
+type Profile struct {
+	WebID    string
+	Document string
+
+	Name             string
+	Storage          []string
+	OIDCIssuers      []string
+	Inbox            string
+	PreferencesFile  string
+	PublicTypeIndex  string
+	PrivateTypeIndex string
+	SeeAlso          []string
+
+	Graph *rdf.Graph
+}
+
+// FetchWebID reads a WebID's profile with a default client, anonymously
+func FetchWebID(ctx context.Context, webID string) (*Profile, error) {
+	return MustNew().FetchWebID(ctx, webID)
+}
+
+// FetchWebID reads a WebID's profile. A hash WebID's document is the
+// WebID without the fragment, a slash WebID is followed to its document
+// through the server's 303 redirect. Extended profile documents linked
+// with rdfs:seeAlso, and the preferences file, are merged into the
+// profile when they can be read; a client signed in as the agent can
+// read the preferences file where the private type index usually is.
+// Storage and OIDCIssuers only come from the profile document itself, so
+// a linked document can't add an issuer trusted to sign in as the WebID
+func (c *Client) FetchWebID(ctx context.Context, webID string) (*Profile, error) {
+	doc, _, _ := strings.Cut(webID, "#")
+
+	res, err := c.Get(ctx, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.Graph == nil {
+		return nil, fmt.Errorf("solid: %v isn't RDF", res.URL)
+	}
+
+	me := rdf.IRI(webID)
+
+	if len(res.Graph.Match(me, nil, nil)) == 0 {
+		return nil, fmt.Errorf("%w: %v", ErrNotWebID, webID)
+	}
+
+	p := &Profile{WebID: webID, Document: res.URL, Graph: res.Graph.Clone()}
+
+	// Extended documents are read breadth first, each once
+	read := map[string]bool{res.URL: true}
+	queue := append(iris(p.Graph, me, rdfs.SeeAlso), iris(p.Graph, me, space.PreferencesFile)...)
+
+	for len(queue) > 0 && len(read) < maxProfileDocuments {
+		u, _, _ := strings.Cut(queue[0], "#")
+		queue = queue[1:]
+
+		if read[u] {
+			continue
+		}
+
+		read[u] = true
+
+		// An extended document that can't be read or parsed is left out,
+		// they are often on other hosts
+		ext, err := c.Get(ctx, u)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		if err != nil || ext.Graph == nil {
+			continue
+		}
+
+		p.Graph.Add(ext.Graph.Triples()...)
+		queue = append(queue, iris(ext.Graph, me, rdfs.SeeAlso)...)
+	}
+
+	// Where the agent signs in and keeps its data are only taken from the
+	// profile document, which only the agent can write
+	p.Storage = iris(res.Graph, me, space.StorageProperty)
+	p.OIDCIssuers = iris(res.Graph, me, vocab.OidcIssuer)
+
+	p.Name = literal(p.Graph, me, foaf.Name, vcard.Fn, rdfs.Label)
+	p.Inbox = first(iris(p.Graph, me, ldp.Inbox))
+	p.PreferencesFile = first(iris(p.Graph, me, space.PreferencesFile))
+	p.PublicTypeIndex = first(iris(p.Graph, me, vocab.PublicTypeIndex))
+	p.PrivateTypeIndex = first(iris(p.Graph, me, vocab.PrivateTypeIndex))
+	p.SeeAlso = iris(p.Graph, me, rdfs.SeeAlso)
+
+	return p, nil
+}
+
+// iris returns the IRI objects of the subject's triples with the
+// predicate, sorted
+func iris(g *rdf.Graph, s, p rdf.Term) []string {
+	var values []string
+	for _, t := range g.Match(s, p, nil) {
+		if iri, ok := t.Object.(rdf.IRI); ok {
+			values = append(values, string(iri))
+		}
+	}
+
+	sort.Strings(values)
+
+	return values
+}
+
+// literal returns the first literal found with the predicates, tried in
+// order
+func literal(g *rdf.Graph, s rdf.Term, predicates ...rdf.IRI) string {
+	for _, p := range predicates {
+		for _, t := range g.Match(s, p, nil) {
+			if l, ok := t.Object.(rdf.Literal); ok {
+				return l.Lexical
+			}
+		}
+	}
+
+	return ""
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}